@@ -0,0 +1,39 @@
+// Command backfill fills in, in place, the fields that records written by older versions lack,
+// using the backend selected by DB_BACKEND. It only writes records that need it, so it is safe
+// to run again and while the server is running.
+//
+//	go run ./cmd/backfill -dry-run
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "count the records that need a backfill without writing them")
+	batchSize := flag.Int("batch", 500, "documents read per page")
+	flag.Parse()
+
+	if err := persistence.InitDatabase(); err != nil {
+		log.Fatalf("Error initializing database: %v", err)
+	}
+
+	reports, err := persistence.Backfill(persistence.NewBackend(), persistence.BackfillOptions{
+		BatchSize: *batchSize,
+		DryRun:    *dryRun,
+	})
+	verb := "patched"
+	if *dryRun {
+		verb = "to patch"
+	}
+	for _, r := range reports {
+		fmt.Printf("%-25s %-15s checked=%d %s=%d\n", r.Name, r.Collection, r.Checked, verb, r.Patched)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,135 @@
+// Command migrate copies every collection from one storage backend to another and verifies the result.
+//
+//	go run ./cmd/migrate -from firebase -to postgres -to-url postgres://localhost/studywithme
+//
+// Backends are "firebase" (configured by FIREBASE_CREDENTIALS_PATH and FIREBASE_DATABASE_URL),
+// "postgres" (url is the connection string, DATABASE_URL by default) and "memory" (url is the JSON file path).
+// Progress is checkpointed to -state after every batch; running the same command again resumes from there.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+)
+
+func main() {
+	from := flag.String("from", config.DBBackendFirebase, "source backend: firebase, postgres or memory")
+	fromURL := flag.String("from-url", "", "source postgres connection string or memory JSON file")
+	to := flag.String("to", config.DBBackendPostgres, "target backend: firebase, postgres or memory")
+	toURL := flag.String("to-url", "", "target postgres connection string or memory JSON file")
+	collections := flag.String("collections", "", "comma separated collections to copy (default all: "+strings.Join(persistence.MigratedCollections(), ",")+")")
+	batchSize := flag.Int("batch", 500, "documents per batch")
+	statePath := flag.String("state", "migrate_state.json", "checkpoint file used to resume an interrupted run, empty to disable")
+	reset := flag.Bool("reset", false, "ignore the checkpoint file and start from the beginning")
+	dryRun := flag.Bool("dry-run", false, "read and check everything without writing to the target")
+	verifyOnly := flag.Bool("verify", false, "only compare record counts and checksums, copy nothing")
+	flag.Parse()
+
+	config.LoadEnv()
+
+	if *from == *to && *fromURL == *toURL {
+		log.Fatal("source and target are the same backend")
+	}
+	if *reset && *statePath != "" {
+		if err := os.Remove(*statePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Fatalf("Error removing %s: %v", *statePath, err)
+		}
+	}
+
+	source, err := openBackend(*from, *fromURL)
+	if err != nil {
+		log.Fatalf("Error opening source: %v", err)
+	}
+	target, err := openBackend(*to, *toURL)
+	if err != nil {
+		log.Fatalf("Error opening target: %v", err)
+	}
+
+	options := persistence.MigrationOptions{
+		BatchSize: *batchSize,
+		DryRun:    *dryRun,
+		StatePath: *statePath,
+	}
+	if *dryRun {
+		options.StatePath = ""
+	}
+	if *collections != "" {
+		options.Collections = strings.Split(*collections, ",")
+	}
+
+	migrator, err := persistence.NewMigrator(source, target, options)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var reports []persistence.MigrationReport
+	if *verifyOnly {
+		reports, err = migrator.Verify()
+	} else {
+		reports, err = migrator.Run()
+	}
+	printReports(reports, *dryRun)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// openBackend connects one side of the migration. Only one Firebase database can be open at a time.
+func openBackend(kind, url string) (*persistence.Backend, error) {
+	switch kind {
+	case config.DBBackendFirebase:
+		config.InitFirebase()
+		return persistence.NewFirebaseBackend(), nil
+	case config.DBBackendPostgres:
+		if url == "" {
+			url = os.Getenv("DATABASE_URL")
+		}
+		db, err := persistence.OpenPostgres(url)
+		if err != nil {
+			return nil, err
+		}
+		return persistence.NewPostgresBackend(db), nil
+	case config.DBBackendMemory:
+		if url == "" {
+			return nil, errors.New("the memory backend needs a JSON file path")
+		}
+		store, err := persistence.NewLocalStore(url)
+		if err != nil {
+			return nil, err
+		}
+		return persistence.NewLocalBackend(store), nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q", kind)
+	}
+}
+
+func printReports(reports []persistence.MigrationReport, dryRun bool) {
+	action := "copied"
+	if dryRun {
+		action = "to copy"
+	}
+	for _, r := range reports {
+		status := "ok"
+		if !r.Verified {
+			status = "MISMATCH"
+		}
+		fmt.Printf("%-15s %s=%d skipped=%d source=%d/%s target=%d/%s %s\n",
+			r.Collection, action, r.Copied, r.Skipped,
+			r.Source.Count, shortChecksum(r.Source.Checksum),
+			r.Target.Count, shortChecksum(r.Target.Checksum), status)
+	}
+}
+
+func shortChecksum(checksum string) string {
+	if len(checksum) > 12 {
+		return checksum[:12]
+	}
+	return checksum
+}
@@ -0,0 +1,36 @@
+// Command repair-membership fixes drift between the team lists of users and the member lists of teams,
+// using the backend selected by DB_BACKEND. The server can also run the same repair periodically,
+// see MEMBERSHIP_REPAIR_INTERVAL.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report the drift without fixing it")
+	flag.Parse()
+
+	if err := persistence.InitDatabase(); err != nil {
+		log.Fatalf("Error initializing database: %v", err)
+	}
+
+	report, err := service.NewMembershipRepairService().Repair(*dryRun)
+	if err != nil {
+		log.Fatalf("Error repairing memberships: %v", err)
+	}
+
+	for _, issue := range report.Issues {
+		fmt.Println(issue)
+	}
+	verb := "fixed"
+	if report.DryRun {
+		verb = "would fix"
+	}
+	fmt.Printf("%d issues, %s %d users and %d teams\n", len(report.Issues), verb, report.UsersChanged, report.TeamsChanged)
+}
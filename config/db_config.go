@@ -11,12 +11,40 @@ import (
 	"google.golang.org/api/option"
 )
 
+const (
+	DBBackendFirebase = "firebase"
+	DBBackendMemory   = "memory"
+	DBBackendPostgres = "postgres"
+)
+
 var FirebaseDB *db.Client
 
-func InitFirebase() {
+// DBConfig selects the storage backend. DB_BACKEND is "firebase" (default), "memory" or "postgres";
+// the memory backend mirrors its data to DB_FILE_PATH when set, and keeps it only in RAM otherwise.
+// The postgres backend connects to DATABASE_URL.
+type DBConfig struct {
+	Backend     string
+	FilePath    string
+	DatabaseURL string
+}
+
+func GetDBConfig() DBConfig {
+	return DBConfig{
+		Backend:     getEnvOrDefault("DB_BACKEND", DBBackendFirebase),
+		FilePath:    os.Getenv("DB_FILE_PATH"),
+		DatabaseURL: os.Getenv("DATABASE_URL"),
+	}
+}
+
+// LoadEnv reads the .env file into the environment, if there is one
+func LoadEnv() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found")
 	}
+}
+
+func InitFirebase() {
+	LoadEnv()
 
 	ctx := context.Background()
 
@@ -0,0 +1,26 @@
+package config
+
+import "os"
+
+const (
+	HubBackplaneLocal = "local"
+	HubBackplaneRedis = "redis"
+)
+
+// HubConfig selects how the WebSocket hubs reach users connected to other API instances.
+// HUB_BACKPLANE is "local" (default, a single instance) or "redis", which connects to REDIS_URL.
+// HUB_NODE_ID names this instance and must be unique per instance; it defaults to the host name.
+type HubConfig struct {
+	Backplane string
+	RedisURL  string
+	NodeID    string
+}
+
+func GetHubConfig() HubConfig {
+	hostname, _ := os.Hostname()
+	return HubConfig{
+		Backplane: getEnvOrDefault("HUB_BACKPLANE", HubBackplaneLocal),
+		RedisURL:  getEnvOrDefault("REDIS_URL", "redis://localhost:6379/0"),
+		NodeID:    getEnvOrDefault("HUB_NODE_ID", hostname),
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import "os"
+
+const (
+	MailDriverSMTP = "smtp"
+	MailDriverFile = "file"
+	MailDriverLog  = "log"
+
+	defaultMailFrom     = "no-reply@studywithme.local"
+	defaultMailFilePath = "mail_outbox.log"
+	defaultSMTPPort     = "587"
+	defaultAppURL       = "http://localhost:3000"
+)
+
+// MailConfig describes how outgoing mail is delivered. MAIL_DRIVER selects the
+// implementation ("smtp", "file" or "log"); it defaults to "log" so development
+// setups never need an SMTP server.
+type MailConfig struct {
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	FilePath     string
+}
+
+func GetMailConfig() MailConfig {
+	return MailConfig{
+		Driver:       getEnvOrDefault("MAIL_DRIVER", MailDriverLog),
+		From:         getEnvOrDefault("MAIL_FROM", defaultMailFrom),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     getEnvOrDefault("SMTP_PORT", defaultSMTPPort),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		FilePath:     getEnvOrDefault("MAIL_FILE_PATH", defaultMailFilePath),
+	}
+}
+
+// GetAppURL returns the public URL of the frontend, used to build links sent by email
+func GetAppURL() string {
+	return getEnvOrDefault("APP_URL", defaultAppURL)
+}
+
+func getEnvOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
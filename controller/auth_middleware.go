@@ -2,12 +2,45 @@ package controller
 
 import (
 	"net/http"
+	"sync"
 
 	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const ImpersonationError = "user id does not match the authenticated user"
+
+// SessionValidatorInterface checks that a signature-valid token was not revoked server-side
+type SessionValidatorInterface interface {
+	ValidateAccessToken(claims jwt.MapClaims) error
+}
+
+var (
+	sessionValidatorMu sync.Mutex
+	sessionValidator   SessionValidatorInterface
+)
+
+// SetSessionValidator overrides the validator used by the JWTAuthMiddleware handlers created after it
+func SetSessionValidator(validator SessionValidatorInterface) {
+	sessionValidatorMu.Lock()
+	defer sessionValidatorMu.Unlock()
+	sessionValidator = validator
+}
+
+// currentSessionValidator returns the validator set by SetSessionValidator, creating the session service the
+// first time it is needed. Handlers keep the validator they were created with, so requests never write it.
+func currentSessionValidator() SessionValidatorInterface {
+	sessionValidatorMu.Lock()
+	defer sessionValidatorMu.Unlock()
+	if sessionValidator == nil {
+		sessionValidator = service.NewSessionService()
+	}
+	return sessionValidator
+}
+
 // JWTAuthMiddleware verifies the Authorization header and stores claims in context
 //
 //	@Summary		JWT Authentication Middleware
@@ -19,6 +52,7 @@ import (
 //	@Failure		401				{object}	map[string]string	"Unauthorized"
 //	@Router			/auth/middleware [post]
 func JWTAuthMiddleware() gin.HandlerFunc {
+	validator := currentSessionValidator()
 	return func(c *gin.Context) {
 		// expected: "Bearer <token>" (HTTP) or "?token=<token>" (WebSocket)
 		var tokenString string
@@ -49,6 +83,12 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if err := validator.ValidateAccessToken(claims); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+			c.Abort()
+			return
+		}
+
 		c.Set("userClaims", claims)
 
 		c.Next()
@@ -103,3 +143,20 @@ func RequireOwner(paramName string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// actingUserID returns the authenticated user's ID. A non-empty claimedID coming from the body, path
+// or query must match it; otherwise the request is rejected with 403 and ok is false.
+func actingUserID(c *gin.Context, claimedID string) (string, bool) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil || userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return "", false
+	}
+
+	if claimedID != "" && claimedID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": ImpersonationError})
+		return "", false
+	}
+
+	return userID, true
+}
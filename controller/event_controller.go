@@ -1,10 +1,12 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,13 +16,11 @@ const (
 
 type EventController struct {
 	eventService service.EventServiceInterface
-	teamService  TeamServiceInterface
 }
 
 func NewEventController() *EventController {
 	return &EventController{
 		eventService: service.NewEventService(),
-		teamService:  service.NewTeamService(),
 	}
 }
 
@@ -28,21 +28,20 @@ func (ec *EventController) SetEventService(service service.EventServiceInterface
 	ec.eventService = service
 }
 
-func (ec *EventController) SetTeamService(service TeamServiceInterface) {
-	ec.teamService = service
-}
-
 // NewEvent
 //
-//	@Summary	Create new event
-//	@Security	Bearer
-//	@Accept		json
-//	@Produce	json
-//	@Param		request	body		dto.CreateEventRequest	true	"Create event request"
-//	@Success	201		{object}	dto.EventDTO
-//	@Failure	400		{object}	map[string]interface{}	"Bad Request"
-//	@Failure	500		{object}	map[string]interface{}	"Internal Server Error"
-//	@Router		/events [post]
+//	@Summary		Create new event
+//	@Description	The initiator is the authenticated user; a different initiatorId is rejected. Only members of the team may create events in it.
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.CreateEventRequest	true	"Create event request"
+//	@Success		201		{object}	dto.EventDTO
+//	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		403		{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404		{object}	map[string]interface{}	"Team not found"
+//	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/events [post]
 func (ec *EventController) NewEvent(c *gin.Context) {
 	var request dto.CreateEventRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -50,9 +49,15 @@ func (ec *EventController) NewEvent(c *gin.Context) {
 		return
 	}
 
+	initiatorID, ok := actingUserID(c, request.InitiatorID)
+	if !ok {
+		return
+	}
+	request.InitiatorID = initiatorID
+
 	resp, err := ec.eventService.CreateEvent(&request)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeTeamError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -61,21 +66,26 @@ func (ec *EventController) NewEvent(c *gin.Context) {
 
 // GetEvent
 //
-//	@Summary	Get event by id
-//	@Security	Bearer
-//	@Accept		json
-//	@Produce	json
-//	@Param		id	path		string	true	"Event ID"
-//	@Success	200	{object}	dto.EventDTO
-//	@Failure	400	{object}	map[string]interface{}	"Bad Request"
-//	@Failure	409	{object}	map[string]interface{}	"this status option is already set"
-//	@Failure	500	{object}	map[string]interface{}	"Internal Server Error"
-//	@Router		/events/{id} [get]
+//	@Summary		Get event by id
+//	@Description	Only members of the event's team may read it.
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Event ID"
+//	@Success		200	{object}	dto.EventDTO
+//	@Failure		403	{object}	map[string]interface{}	"Not a member of the team"
+//	@Failure		404	{object}	map[string]interface{}	"Event not found"
+//	@Failure		500	{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/events/{id} [get]
 func (ec *EventController) GetEvent(c *gin.Context) {
-	id := c.Param("id")
-	event, err := ec.eventService.GetEventById(id)
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	event, err := ec.eventService.GetEventById(userID, c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeTeamError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -84,15 +94,18 @@ func (ec *EventController) GetEvent(c *gin.Context) {
 
 // GetEvents
 //
-//	@Summary	Get events by team id
-//	@Security	Bearer
-//	@Accept		json
-//	@Produce	json
-//	@Param		teamId	query		string	true	"Team ID"
-//	@Success	200		{object}	[]dto.EventDTO
-//	@Failure	400		{object}	map[string]interface{}	"Bad Request"
-//	@Failure	500		{object}	map[string]interface{}	"Internal Server Error"
-//	@Router		/events [get]
+//	@Summary		Get events by team id
+//	@Description	Only members of the team may list its events.
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			teamId	query		string	true	"Team ID"
+//	@Success		200		{object}	[]dto.EventDTO
+//	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		403		{object}	map[string]interface{}	"Not a member of the team"
+//	@Failure		404		{object}	map[string]interface{}	"Team not found"
+//	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/events [get]
 func (ec *EventController) GetEvents(c *gin.Context) {
 	teamId := c.Query("teamId")
 
@@ -100,14 +113,14 @@ func (ec *EventController) GetEvents(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": MissingParameter})
 		return
 	}
-	if _, err := ec.teamService.GetTeamById(teamId); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	userID, ok := actingUserID(c, "")
+	if !ok {
 		return
 	}
 
-	events, err := ec.eventService.GetEventsByTeamId(teamId)
+	events, err := ec.eventService.GetEventsByTeamId(userID, teamId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeTeamError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -117,7 +130,7 @@ func (ec *EventController) GetEvents(c *gin.Context) {
 // UpdateEventDetails
 //
 //	@Summary		Update event details
-//	@Description	Update event name, description, start time and/or duration
+//	@Description	Update event name, description, start time and/or duration. Only the initiator or an admin of the team may update it.
 //	@Security		Bearer
 //	@Accept			json
 //	@Produce		json
@@ -125,6 +138,8 @@ func (ec *EventController) GetEvents(c *gin.Context) {
 //	@Param			request	body		dto.UpdateEventRequest	true	"Update event request"
 //	@Success		200		{object}	dto.EventDTO
 //	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		401		{object}	map[string]interface{}	"Unauthorized"
+//	@Failure		403		{object}	map[string]interface{}	"Forbidden"
 //	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
 //	@Router			/events/{id} [patch]
 func (ec *EventController) UpdateEventDetails(c *gin.Context) {
@@ -135,9 +150,14 @@ func (ec *EventController) UpdateEventDetails(c *gin.Context) {
 		return
 	}
 
-	resp, err := ec.eventService.UpdateEventDetails(id, &request)
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	resp, err := ec.eventService.UpdateEventDetails(userID, id, &request)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeTeamError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -146,16 +166,19 @@ func (ec *EventController) UpdateEventDetails(c *gin.Context) {
 
 // UpdateUserStatus
 //
-//	@Summary	Update user status for event
-//	@Security	Bearer
-//	@Accept		json
-//	@Produce	json
-//	@Param		id		path		string							true	"Event ID"
-//	@Param		request	body		dto.UpdateEventStatusRequest	true	"Update event status request"
-//	@Success	200		{object}	dto.EventDTO
-//	@Failure	400		{object}	map[string]interface{}	"Bad Request"
-//	@Failure	500		{object}	map[string]interface{}	"Internal Server Error"
-//	@Router		/events/{id}/status [patch]
+//	@Summary		Update user status for event
+//	@Description	Sets the authenticated user's status; a different userId is rejected. Only members of the event's team may answer it.
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"Event ID"
+//	@Param			request	body		dto.UpdateEventStatusRequest	true	"Update event status request"
+//	@Success		200		{object}	dto.EventDTO
+//	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		403		{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404		{object}	map[string]interface{}	"Team not found"
+//	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/events/{id}/status [patch]
 func (ec *EventController) UpdateUserStatus(c *gin.Context) {
 	id := c.Param("id")
 	var req dto.UpdateEventStatusRequest
@@ -165,9 +188,15 @@ func (ec *EventController) UpdateUserStatus(c *gin.Context) {
 		return
 	}
 
+	userID, ok := actingUserID(c, req.UserID)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
 	event, err := ec.eventService.UpdateUserStatus(id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeTeamError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -182,12 +211,24 @@ func (ec *EventController) UpdateUserStatus(c *gin.Context) {
 //	@Produce	json
 //	@Param		id	path		string					true	"Event ID"
 //	@Success	200	{object}	map[string]interface{}	"Event deleted successfully"
+//	@Failure	401	{object}	map[string]interface{}	"Unauthorized"
+//	@Failure	403	{object}	map[string]interface{}	"Forbidden"
 //	@Failure	500	{object}	map[string]interface{}	"Internal Server Error"
 //	@Router		/events/{id} [delete]
 func (ec *EventController) DeleteEvent(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	id := c.Param("id")
 
-	if err := ec.eventService.DeleteEvent(id); err != nil {
+	if err := ec.eventService.DeleteEvent(id, userID); err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
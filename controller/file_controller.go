@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -28,7 +29,10 @@ type FileServiceInterface interface {
 	CreateFile(request *dto.FileUploadRequest, userID string) (*dto.FileUploadResponse, error)
 	GetFileByID(id, userID string) (*entity.File, error)
 	GetFilesByTeam(teamID, userID string, page, limit int) (*dto.FileListResponse, error)
+	GetFilesByConversation(otherUserID, userID string, page, limit int) (*dto.FileListResponse, error)
 	DeleteFile(id, userID string) error
+	GetConversationFile(otherUserID, id, userID string) (*entity.File, error)
+	DeleteConversationFile(otherUserID, id, userID string) error
 }
 
 // UploadFile
@@ -72,19 +76,67 @@ func (fc *FileController) UploadFile(c *gin.Context) {
 	}
 
 	// Set context from URL path
-	req.ContextType = "team"
+	req.ContextType = entity.FileContextTeam
 	req.ContextID = teamID
 
-	resp, err := fc.fileService.CreateFile(&req, userID)
+	fc.createFile(c, &req, userID)
+}
+
+// UploadChatFile
+//
+//	@Summary		Upload a file to a direct conversation (base64 content)
+//	@Description	The file is shared between the authenticated user and userId, who are the only ones that can download it. Send its id in attachmentIds of a direct message to attach it.
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			userId	path		string					true	"The other user of the conversation"
+//	@Param			request	body		dto.FileUploadRequest	true	"File upload request"
+//	@Success		201		{object}	dto.FileUploadResponse
+//	@Failure		400		{object}	map[string]string
+//	@Failure		403		{object}	map[string]string
+//	@Failure		404		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Router			/conversations/{userId}/files [post]
+func (fc *FileController) UploadChatFile(c *gin.Context) {
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	var req dto.FileUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.ContextType = entity.FileContextChat
+	req.ContextID = entity.GetConversationKey(userID, c.Param("userId"))
+
+	fc.createFile(c, &req, userID)
+}
+
+// createFile stores an upload whose context was set from the URL
+func (fc *FileController) createFile(c *gin.Context, req *dto.FileUploadRequest, userID string) {
+	if req.OwnerID != "" && req.OwnerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": ImpersonationError})
+		return
+	}
+	req.OwnerID = userID
+
+	resp, err := fc.fileService.CreateFile(req, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "validation") {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		if strings.Contains(err.Error(), "not a member") {
+		if strings.Contains(err.Error(), "not a member") || errors.Is(err, service.ErrForbidden) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, service.ErrResourceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -126,7 +178,7 @@ func (fc *FileController) GetFile(c *gin.Context) {
 	fileID := c.Param("fileId")
 	file, err := fc.fileService.GetFileByID(fileID, userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not a member") {
+		if strings.Contains(err.Error(), "not a member") || errors.Is(err, service.ErrForbidden) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
@@ -229,7 +281,7 @@ func (fc *FileController) DeleteFile(c *gin.Context) {
 
 	fileID := c.Param("fileId")
 	if err := fc.fileService.DeleteFile(fileID, userID); err != nil {
-		if strings.Contains(err.Error(), "not a member") {
+		if strings.Contains(err.Error(), "not a member") || errors.Is(err, service.ErrForbidden) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
@@ -242,3 +294,103 @@ func (fc *FileController) DeleteFile(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "file deleted"})
 }
+
+// GetChatFile
+//
+//	@Summary	Get a file shared in a direct conversation (with content)
+//	@Security	Bearer
+//	@Produce	json
+//	@Param		userId	path		string	true	"The other user of the conversation"
+//	@Param		fileId	path		string	true	"File ID"
+//	@Success	200		{object}	entity.File
+//	@Failure	403		{object}	map[string]string
+//	@Failure	404		{object}	map[string]string
+//	@Failure	500		{object}	map[string]string
+//	@Router		/conversations/{userId}/files/{fileId} [get]
+func (fc *FileController) GetChatFile(c *gin.Context) {
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	file, err := fc.fileService.GetConversationFile(c.Param("userId"), c.Param("fileId"), userID)
+	if err != nil {
+		writeChatFileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, file)
+}
+
+// GetFilesByConversation
+//
+//	@Summary	Get the files shared in a direct conversation (metadata only, paginated)
+//	@Security	Bearer
+//	@Produce	json
+//	@Param		userId	path		string	true	"The other user of the conversation"
+//	@Param		page	query		int		false	"Page number (default 1)"
+//	@Param		limit	query		int		false	"Items per page (default 10, max 100)"
+//	@Success	200		{object}	dto.FileListResponse
+//	@Failure	500		{object}	map[string]string
+//	@Router		/conversations/{userId}/files [get]
+func (fc *FileController) GetFilesByConversation(c *gin.Context) {
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	page := 1
+	limit := 10
+	if p := c.Query("page"); p != "" {
+		if val, err := strconv.Atoi(p); err == nil {
+			page = val
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if val, err := strconv.Atoi(l); err == nil {
+			limit = val
+		}
+	}
+
+	resp, err := fc.fileService.GetFilesByConversation(c.Param("userId"), userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteChatFile
+//
+//	@Summary	Delete a file shared in a direct conversation
+//	@Security	Bearer
+//	@Param		userId	path		string	true	"The other user of the conversation"
+//	@Param		fileId	path		string	true	"File ID"
+//	@Success	200		{object}	map[string]string
+//	@Failure	403		{object}	map[string]string	"Not the uploader"
+//	@Failure	404		{object}	map[string]string
+//	@Failure	500		{object}	map[string]string
+//	@Router		/conversations/{userId}/files/{fileId} [delete]
+func (fc *FileController) DeleteChatFile(c *gin.Context) {
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	if err := fc.fileService.DeleteConversationFile(c.Param("userId"), c.Param("fileId"), userID); err != nil {
+		writeChatFileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "file deleted"})
+}
+
+// writeChatFileError answers 404 for a file of another conversation, as for one that does not exist
+func writeChatFileError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrResourceNotFound) || strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -22,12 +22,13 @@ func (fc *FriendRequestController) SetFriendRequestService(service service.Frien
 }
 
 // @Summary		Send a friend request
-// @Description	Send a friend request from one user to another
+// @Description	Send a friend request from one user to another. The sender must be the authenticated user.
 // @Security		Bearer
 // @Param			fromUserId	path		string	true	"Sender User ID"
 // @Param			toUserId	path		string	true	"Recipient User ID"
 // @Success		201			{object}	nil
 // @Failure		400			{object}	map[string]string
+// @Failure		403			{object}	map[string]string
 // @Failure		500			{object}	map[string]string
 // @Router			/friend-requests/{fromUserId}/{toUserId} [post]
 func (fc *FriendRequestController) SendFriendRequest(c *gin.Context) {
@@ -39,6 +40,10 @@ func (fc *FriendRequestController) SendFriendRequest(c *gin.Context) {
 		return
 	}
 
+	if _, ok := actingUserID(c, fromUserID); !ok {
+		return
+	}
+
 	err := fc.friendRequestService.SendFriendRequest(fromUserID, toUserID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -49,13 +54,14 @@ func (fc *FriendRequestController) SendFriendRequest(c *gin.Context) {
 }
 
 // @Summary		Respond to a friend request
-// @Description	Accept or deny a friend request
+// @Description	Accept or deny a friend request. The recipient must be the authenticated user.
 // @Security		Bearer
 // @Param			fromUserId	path		string							true	"Sender User ID"
 // @Param			toUserId	path		string							true	"Recipient User ID"
 // @Param			body		body		dto.RespondFriendRequestRequest	true	"Accept or deny"
 // @Success		200			{object}	nil
 // @Failure		400			{object}	map[string]string
+// @Failure		403			{object}	map[string]string
 // @Failure		404			{object}	map[string]string
 // @Failure		500			{object}	map[string]string
 // @Router			/friend-requests/{fromUserId}/{toUserId} [put]
@@ -68,6 +74,11 @@ func (fc *FriendRequestController) RespondToFriendRequest(c *gin.Context) {
 		return
 	}
 
+	// Only the recipient can answer a friend request
+	if _, ok := actingUserID(c, toUserID); !ok {
+		return
+	}
+
 	var request struct {
 		Accept bool `json:"accept"`
 	}
@@ -87,14 +98,18 @@ func (fc *FriendRequestController) RespondToFriendRequest(c *gin.Context) {
 }
 
 // @Summary		Get pending friend requests
-// @Description	Get pending friend requests for a user
+// @Description	Get pending friend requests for the authenticated user
 // @Security		Bearer
 // @Param			userId	path		string	true	"User ID"
 // @Success		200		{object}	dto.FriendRequestListResponse
+// @Failure		403		{object}	map[string]string
 // @Failure		500		{object}	map[string]string
 // @Router			/friend-requests/{userId} [get]
 func (fc *FriendRequestController) GetPendingRequests(c *gin.Context) {
-	userID := c.Param("userId")
+	userID, ok := actingUserID(c, c.Param("userId"))
+	if !ok {
+		return
+	}
 
 	requests, err := fc.friendRequestService.GetPendingRequests(userID)
 	if err != nil {
@@ -1,11 +1,13 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/SerbanEduard/ProiectColectivBackEnd/hub"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
-	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/utils"
 	"github.com/gin-gonic/gin"
@@ -15,6 +17,7 @@ const (
 	BadMessageTypeError  = "message type must be direct or team"
 	MessageNotFoundError = "message not found"
 	MissingParameter     = "missing parameter(s)"
+	InvalidSinceError    = "since must be a non-negative sequence number"
 )
 
 type MessageRequestUnion struct {
@@ -23,34 +26,60 @@ type MessageRequestUnion struct {
 }
 
 type MessageController struct {
-	messageService service.MessageServiceInterface
-	teamService    TeamServiceInterface
-	hub            *hub.Hub[hub.Message]
+	messageService  service.MessageServiceInterface
+	teamService     TeamServiceInterface
+	presenceService service.PresenceServiceInterface
+	hub             *hub.Hub[hub.Message]
 }
 
 func NewMessageController() *MessageController {
-	return &MessageController{
-		messageService: service.NewMessageService(),
-		teamService:    service.NewTeamService(),
-		hub:            hub.NewHub[hub.Message](),
+	mc := &MessageController{
+		messageService:  service.NewMessageService(),
+		teamService:     service.NewTeamService(),
+		presenceService: service.NewPresenceService(),
+		hub:             hub.Messages(),
 	}
+	mc.hub.SetInboundHandler(mc.handleInbound)
+	mc.hub.SetPresenceHandler(mc.handlePresence)
+	mc.hub.SetJournal(hub.NewMessageJournal(persistence.NewHubEventRepository()))
+	return mc
 }
 
 func NewMessageControllerWithService(messageService service.MessageServiceInterface) *MessageController {
-	return &MessageController{
+	mc := &MessageController{
 		messageService: messageService,
+		hub:            hub.NewHub[hub.Message](),
 	}
+	mc.hub.SetInboundHandler(mc.handleInbound)
+	mc.hub.SetPresenceHandler(mc.handlePresence)
+	return mc
+}
+
+func (mc *MessageController) SetTeamService(teamService TeamServiceInterface) {
+	mc.teamService = teamService
+}
+
+func (mc *MessageController) SetPresenceService(presenceService service.PresenceServiceInterface) {
+	mc.presenceService = presenceService
+}
+
+// SetJournal makes the hub number and keep every event, so Connect can replay them with since
+func (mc *MessageController) SetJournal(journal hub.Journal[hub.Message]) {
+	mc.hub.SetJournal(journal)
 }
 
 // Connect
 //
-//	@Summary	Connect the user to the message WebSocket
-//	@Security	Bearer
-//	@Success	101	{string}	string					"Switching Protocols - WebSocket connection established"
-//	@Failure	400	{object}	map[string]interface{}	"Bad Request"
-//	@Failure	401	{object}	map[string]string		"Unauthorized"
-//	@Failure	500	{object}	map[string]interface{}	"Internal Server Error"
-//	@Router		/messages/connect [get]
+//	@Summary		Connect the user to the message WebSocket
+//	@Description	Besides receiving events, clients can send frames of the form {"requestId", "type", "payload"} with type send_direct_message, send_team_message, typing, read, react or presence. Every frame is answered with an ack or error frame carrying its requestId.
+//	@Description	Events carry a per-user seq. Reconnecting with since set to the last seq received first replays every event after it. A connection closed with code 4000 should reconnect with since; code 4001 means the missed events expired, so the client reloads over HTTP and reconnects without since.
+//	@Security		Bearer
+//	@Param			since	query		int						false	"Seq of the last event received; the events after it are replayed"
+//	@Success		101		{string}	string					"Switching Protocols - WebSocket connection established"
+//	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		401		{object}	map[string]string		"Unauthorized"
+//	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/messages/connect [get]
 func (mc *MessageController) Connect(c *gin.Context) {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
@@ -59,6 +88,15 @@ func (mc *MessageController) Connect(c *gin.Context) {
 		return
 	}
 
+	var since int64 = -1
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		since, err = strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil || since < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": InvalidSinceError})
+			return
+		}
+	}
+
 	conn, err := hub.AcceptConnection(c)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -66,13 +104,19 @@ func (mc *MessageController) Connect(c *gin.Context) {
 	}
 
 	client := hub.NewClient[hub.Message](userID, conn)
-	mc.hub.Register(client)
+	if since < 0 {
+		mc.hub.Register(client)
+		return
+	}
+	mc.hub.RegisterSince(client, since)
 }
 
 // NewMessage
 //
 //	@Summary		Create and send a message
-//	@Description	Create and send a message either to another user or to a team
+//	@Description	Create and send a message either to another user or to a team. The sender is the authenticated user; a different senderId is rejected.
+//	@Description	Set replyToId to quote a message of the same conversation or team, and parentId to post into the thread under one. Thread replies are listed by GET /messages/{id}/replies, and the parent is sent again as a message_edited event with its new replyCount.
+//	@Description	Set attachmentIds to attach up to 10 files uploaded to the same conversation (/conversations/{userId}/files) or team (/teams/{id}/files).
 //	@Security		Bearer
 //	@Accept			json
 //	@Produce		json
@@ -80,6 +124,9 @@ func (mc *MessageController) Connect(c *gin.Context) {
 //	@Param			request	body		MessageRequestUnion	true	"The message request (this is only for documentation purposes, the actual request should be either DirectMessageRequest or TeamMessageRequest)"
 //	@Success		201		{object}	dto.MessageDTO
 //	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		403		{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404		{object}	map[string]interface{}	"Quoted or parent message, or attached file not found"
+//	@Failure		409		{object}	map[string]interface{}	"Quoted or parent message deleted"
 //	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
 //	@Router			/messages [post]
 func (mc *MessageController) NewMessage(c *gin.Context) {
@@ -93,17 +140,20 @@ func (mc *MessageController) NewMessage(c *gin.Context) {
 			return
 		}
 
+		senderID, ok := actingUserID(c, request.SenderID)
+		if !ok {
+			return
+		}
+		request.SenderID = senderID
+
 		resp, err := mc.messageService.CreateDirectMessage(&request)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(messageErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 			return
 		}
 
 		c.JSON(http.StatusCreated, resp)
-
-		msg := hub.NewMessage(hub.DirectMessage, resp)
-		mc.hub.Send(request.ReceiverID, *msg)
-		mc.hub.Send(request.SenderID, *msg)
+		mc.broadcastDirectMessage(&request, resp)
 
 	case "team":
 		var request dto.TeamMessageRequest
@@ -112,45 +162,82 @@ func (mc *MessageController) NewMessage(c *gin.Context) {
 			return
 		}
 
+		senderID, ok := actingUserID(c, request.SenderID)
+		if !ok {
+			return
+		}
+		request.SenderID = senderID
+
 		resp, err := mc.messageService.CreateTeamMessage(&request)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(messageErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 			return
 		}
 
 		c.JSON(http.StatusCreated, resp)
-
-		// Send to team members via WebSocket
-		team, err := mc.teamService.GetTeamById(request.TeamId)
-		if err == nil && team != nil {
-			mc.hub.SendMany(team.UsersIds, *hub.NewMessage(hub.TeamBroadcast, resp))
-		}
+		mc.broadcastTeamMessage(&request, resp)
 
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": BadMessageTypeError})
 	}
 }
 
+func (mc *MessageController) broadcastDirectMessage(request *dto.DirectMessageRequest, resp *dto.MessageDTO) {
+	msg := hub.NewMessage(hub.DirectMessage, resp)
+	mc.hub.Send(request.ReceiverID, *msg)
+	mc.hub.Send(request.SenderID, *msg)
+	mc.broadcastParent(request.SenderID, resp, []string{request.SenderID, request.ReceiverID})
+}
+
+// broadcastTeamMessage sends the message to the team members via WebSocket
+func (mc *MessageController) broadcastTeamMessage(request *dto.TeamMessageRequest, resp *dto.MessageDTO) {
+	team, err := mc.teamService.GetTeamById(request.TeamId)
+	if err == nil && team != nil {
+		mc.hub.SendMany(team.UsersIds, *hub.NewMessage(hub.TeamBroadcast, resp))
+		mc.broadcastParent(request.SenderID, resp, team.UsersIds)
+	}
+}
+
+// broadcastParent sends the parent of a thread reply again, so clients update its reply count
+func (mc *MessageController) broadcastParent(senderID string, resp *dto.MessageDTO, recipients []string) {
+	if resp.ParentID == "" {
+		return
+	}
+	parent, err := mc.messageService.GetMessageByID(senderID, resp.ParentID)
+	if err == nil {
+		mc.hub.SendMany(recipients, *hub.NewMessage(hub.MessageEdited, parent))
+	}
+}
+
 // GetMessage
 //
-//	@Summary	Get a message by ID
-//	@Security	Bearer
-//	@Accept		json
-//	@Produce	json
-//	@Param		id	path		string	true	"The message ID"
-//	@Success	200	{object}	dto.MessageDTO
-//	@Failure	404	{object}	map[string]interface{}
-//	@Failure	500	{object}	map[string]interface{}
-//	@Router		/messages/{id} [get]
+//	@Summary		Get a message by ID
+//	@Description	Direct messages can be read by their 2 users, team messages by the members of the team.
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"The message ID"
+//	@Success		200	{object}	dto.MessageDTO
+//	@Failure		403	{object}	map[string]interface{}	"Not part of the conversation or team"
+//	@Failure		404	{object}	map[string]interface{}
+//	@Failure		500	{object}	map[string]interface{}
+//	@Router			/messages/{id} [get]
 func (mc *MessageController) GetMessage(c *gin.Context) {
-	id := c.Param("id")
-	message, err := mc.messageService.GetMessageByID(id)
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	message, err := mc.messageService.GetMessageByID(userID, c.Param("id"))
 	if err != nil {
-		if err.Error() == entity.BadConversationKey {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrResourceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": MessageNotFoundError})
+		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
 		}
-		c.JSON(http.StatusNotFound, gin.H{"error": MessageNotFoundError})
 		return
 	}
 
@@ -159,8 +246,9 @@ func (mc *MessageController) GetMessage(c *gin.Context) {
 
 // GetMessages
 //
-//	@Summary		Get all messages
-//	@Description	Get messages between 2 users or within a team
+//	@Summary		Get messages
+//	@Description	Get a page of messages between 2 users or within a team, oldest first. For direct messages the authenticated user must be one of the 2 users; for team messages a member of the team.
+//	@Description	Without cursors the newest messages are returned. Pass nextCursor as before to page back in history, or a cursor as after to page forward.
 //	@Security		Bearer
 //	@Accept			json
 //	@Produce		json
@@ -168,12 +256,21 @@ func (mc *MessageController) GetMessage(c *gin.Context) {
 //	@Param			user1Id	query		string	false	"User1 ID (direct message)"
 //	@Param			user2Id	query		string	false	"User2 ID (direct message)"
 //	@Param			teamId	query		string	false	"Team ID (team message)"
-//	@Success		200		{array}		dto.MessageDTO
+//	@Param			before	query		string	false	"Return messages older than this cursor"
+//	@Param			after	query		string	false	"Return messages newer than this cursor"
+//	@Param			limit	query		int		false	"Page size (default 50, max 100)"
+//	@Success		200		{object}	dto.MessagePageDTO
 //	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		403		{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404		{object}	map[string]interface{}	"Team not found"
 //	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
 //	@Router			/messages [get]
 func (mc *MessageController) GetMessages(c *gin.Context) {
 	message_type := c.Query("type")
+	page, ok := messagePageRequest(c)
+	if !ok {
+		return
+	}
 
 	switch message_type {
 	case "direct":
@@ -185,9 +282,18 @@ func (mc *MessageController) GetMessages(c *gin.Context) {
 			return
 		}
 
-		resp, err := mc.messageService.GetDirectMessages(user1Id, user2Id)
+		userID, ok := actingUserID(c, "")
+		if !ok {
+			return
+		}
+		if userID != user1Id && userID != user2Id {
+			c.JSON(http.StatusForbidden, gin.H{"error": ImpersonationError})
+			return
+		}
+
+		resp, err := mc.messageService.GetDirectMessages(user1Id, user2Id, page)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(messageErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 			return
 		}
 
@@ -199,9 +305,14 @@ func (mc *MessageController) GetMessages(c *gin.Context) {
 			return
 		}
 
-		resp, err := mc.messageService.GetTeamMessages(teamId)
+		userID, ok := actingUserID(c, "")
+		if !ok {
+			return
+		}
+
+		resp, err := mc.messageService.GetTeamMessages(userID, teamId, page)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(messageErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 			return
 		}
 
@@ -211,10 +322,231 @@ func (mc *MessageController) GetMessages(c *gin.Context) {
 	}
 }
 
+// messagePageRequest reads the paging query parameters, answering 400 when limit is not a number
+func messagePageRequest(c *gin.Context) (dto.MessagePageRequest, bool) {
+	page := dto.MessagePageRequest{Before: c.Query("before"), After: c.Query("after")}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": LimitMustBeANumberError})
+			return page, false
+		}
+		page.Limit = limit
+	}
+	return page, true
+}
+
+// messageErrorStatus maps message service errors to HTTP status codes
+func messageErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidCursor), errors.Is(err, service.ErrInvalidPageLimit),
+		errors.Is(err, service.ErrInvalidReply), errors.Is(err, service.ErrNestedThread),
+		errors.Is(err, service.ErrInvalidAttachment):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrResourceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrMessageDeleted), errors.Is(err, service.ErrTooManyReactions):
+		return http.StatusConflict
+	default:
+		return fallback
+	}
+}
+
+// EditMessage
+//
+//	@Summary		Edit a message
+//	@Description	Replace the text of a message. Allowed for the sender, and for team admins on team messages. The previous text is kept in the edit history and connected clients receive a message_edited event.
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string					true	"The message ID"
+//	@Param			request	body		dto.EditMessageRequest	true	"The new text"
+//	@Success		200		{object}	dto.MessageDTO
+//	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		403		{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404		{object}	map[string]interface{}	"Not Found"
+//	@Failure		409		{object}	map[string]interface{}	"Message already deleted"
+//	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/messages/{id} [patch]
 func (mc *MessageController) EditMessage(c *gin.Context) {
-	// TODO: implement
+	var request dto.EditMessageRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	resp, recipients, err := mc.messageService.EditMessage(userID, c.Param("id"), &request)
+	if err != nil {
+		c.JSON(messageErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+	mc.hub.SendMany(recipients, *hub.NewMessage(hub.MessageEdited, resp))
 }
 
+// DeleteMessage
+//
+//	@Summary		Delete a message
+//	@Description	Delete a message, leaving a tombstone in its place. Allowed for the sender, and for team admins on team messages. Connected clients receive a message_deleted event.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id	path		string					true	"The message ID"
+//	@Success		200	{object}	dto.MessageDTO			"The tombstone"
+//	@Failure		403	{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404	{object}	map[string]interface{}	"Not Found"
+//	@Failure		409	{object}	map[string]interface{}	"Message already deleted"
+//	@Failure		500	{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/messages/{id} [delete]
 func (mc *MessageController) DeleteMessage(c *gin.Context) {
-	// TODO: implement
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	resp, recipients, err := mc.messageService.DeleteMessage(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(messageErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+	mc.hub.SendMany(recipients, *hub.NewMessage(hub.MessageDeleted, resp))
+}
+
+// MarkRead
+//
+//	@Summary		Mark a message as read
+//	@Description	Move the user's read cursor of the message's conversation or team up to the message. Cursors only move forward.
+//	@Description	When the cursor moves, the other participants receive a read event with the receipt.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id	path		string	true	"The message ID"
+//	@Success		200	{object}	dto.ReadReceiptDTO
+//	@Failure		403	{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404	{object}	map[string]interface{}	"Not Found"
+//	@Failure		500	{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/messages/{id}/read [post]
+func (mc *MessageController) MarkRead(c *gin.Context) {
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	receipt, recipients, err := mc.messageService.MarkRead(userID, &dto.ReadMessageRequest{MessageID: c.Param("id")})
+	if err != nil {
+		c.JSON(messageErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+	mc.hub.SendMany(recipients, *hub.NewMessage(hub.ReadReceipt, receipt))
+}
+
+// GetUnreadCounts
+//
+//	@Summary		Get unread message counts
+//	@Description	Count the messages from others after the user's read cursor, for each direct conversation and each team of the user.
+//	@Description	Counting stops at 99; hasMore is set when there are more.
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	dto.UnreadCountsDTO
+//	@Failure		404	{object}	map[string]interface{}	"Not Found"
+//	@Failure		500	{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/messages/unread [get]
+func (mc *MessageController) GetUnreadCounts(c *gin.Context) {
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	counts, err := mc.messageService.GetUnreadCounts(userID)
+	if err != nil {
+		c.JSON(messageErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, counts)
+}
+
+// ToggleReaction
+//
+//	@Summary		Toggle a reaction on a message
+//	@Description	Add the user's reaction with the emoji, or remove it when the user already reacted with it. Allowed for everyone who can see the message.
+//	@Description	Connected clients receive the updated message as a message_edited event.
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"The message ID"
+//	@Param			request	body		dto.ReactionRequest	true	"The emoji"
+//	@Success		200		{object}	dto.MessageDTO
+//	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		403		{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404		{object}	map[string]interface{}	"Not Found"
+//	@Failure		409		{object}	map[string]interface{}	"Message deleted, or too many different reactions"
+//	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/messages/{id}/reactions [post]
+func (mc *MessageController) ToggleReaction(c *gin.Context) {
+	var request dto.ReactionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	request.MessageID = c.Param("id")
+
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	resp, recipients, err := mc.messageService.ToggleReaction(userID, &request)
+	if err != nil {
+		c.JSON(messageErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+	mc.hub.SendMany(recipients, *hub.NewMessage(hub.MessageEdited, resp))
+}
+
+// GetReplies
+//
+//	@Summary		Get the thread replies of a message
+//	@Description	Get a page of the replies posted into the thread under a message, oldest first. Paging works as for GET /messages.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id		path		string	true	"The parent message ID"
+//	@Param			before	query		string	false	"Return replies older than this cursor"
+//	@Param			after	query		string	false	"Return replies newer than this cursor"
+//	@Param			limit	query		int		false	"Page size (default 50, max 100)"
+//	@Success		200		{object}	dto.MessagePageDTO
+//	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		403		{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404		{object}	map[string]interface{}	"Not Found"
+//	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/messages/{id}/replies [get]
+func (mc *MessageController) GetReplies(c *gin.Context) {
+	page, ok := messagePageRequest(c)
+	if !ok {
+		return
+	}
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	resp, err := mc.messageService.GetReplies(userID, c.Param("id"), page)
+	if err != nil {
+		c.JSON(messageErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
 }
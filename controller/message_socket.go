@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/hub"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+const (
+	InvalidFrameError     = "frame must be a JSON object with requestId, type and payload"
+	MissingRequestIDError = "requestId is required"
+	MissingPayloadError   = "payload is required"
+	UnknownFrameTypeError = "unknown frame type"
+	PresenceUnavailable   = "presence is not available"
+)
+
+// handleInbound answers every frame a client sends over /messages/connect with an ack carrying
+// the result, or an error frame. Both echo the frame's requestId.
+func (mc *MessageController) handleInbound(client *hub.Client[hub.Message], data []byte) {
+	var inbound hub.InboundMessage
+	if err := json.Unmarshal(data, &inbound); err != nil {
+		mc.hub.Reply(client, *hub.NewError("", errors.New(InvalidFrameError)))
+		return
+	}
+	if inbound.RequestID == "" {
+		mc.hub.Reply(client, *hub.NewError("", errors.New(MissingRequestIDError)))
+		return
+	}
+
+	result, err := mc.dispatchInbound(client.ClientID, &inbound)
+	if err != nil {
+		mc.hub.Reply(client, *hub.NewError(inbound.RequestID, err))
+		return
+	}
+	mc.hub.Reply(client, *hub.NewAck(inbound.RequestID, result))
+}
+
+// dispatchInbound runs the frame as the connection's user and relays the outcome to the other clients involved
+func (mc *MessageController) dispatchInbound(userID string, inbound *hub.InboundMessage) (interface{}, error) {
+	switch inbound.Type {
+	case hub.SendDirectMessage:
+		var request dto.DirectMessageRequest
+		if err := decodePayload(inbound.Payload, &request); err != nil {
+			return nil, err
+		}
+		if request.SenderID != "" && request.SenderID != userID {
+			return nil, errors.New(ImpersonationError)
+		}
+		request.SenderID = userID
+
+		resp, err := mc.messageService.CreateDirectMessage(&request)
+		if err != nil {
+			return nil, err
+		}
+		mc.broadcastDirectMessage(&request, resp)
+		return resp, nil
+
+	case hub.SendTeamMessage:
+		var request dto.TeamMessageRequest
+		if err := decodePayload(inbound.Payload, &request); err != nil {
+			return nil, err
+		}
+		if request.SenderID != "" && request.SenderID != userID {
+			return nil, errors.New(ImpersonationError)
+		}
+		request.SenderID = userID
+
+		resp, err := mc.messageService.CreateTeamMessage(&request)
+		if err != nil {
+			return nil, err
+		}
+		mc.broadcastTeamMessage(&request, resp)
+		return resp, nil
+
+	case hub.SendTyping:
+		var request dto.TypingRequest
+		if err := decodePayload(inbound.Payload, &request); err != nil {
+			return nil, err
+		}
+
+		typing, recipients, err := mc.messageService.Typing(userID, &request)
+		if err != nil {
+			return nil, err
+		}
+		mc.hub.SendMany(recipients, *hub.NewMessage(hub.Typing, typing))
+		return typing, nil
+
+	case hub.SendRead:
+		var request dto.ReadMessageRequest
+		if err := decodePayload(inbound.Payload, &request); err != nil {
+			return nil, err
+		}
+
+		receipt, recipients, err := mc.messageService.MarkRead(userID, &request)
+		if err != nil {
+			return nil, err
+		}
+		mc.hub.SendMany(recipients, *hub.NewMessage(hub.ReadReceipt, receipt))
+		return receipt, nil
+
+	case hub.SendReaction:
+		var request dto.ReactionRequest
+		if err := decodePayload(inbound.Payload, &request); err != nil {
+			return nil, err
+		}
+
+		message, recipients, err := mc.messageService.ToggleReaction(userID, &request)
+		if err != nil {
+			return nil, err
+		}
+		mc.hub.SendMany(recipients, *hub.NewMessage(hub.MessageEdited, message))
+		return message, nil
+
+	case hub.SetPresence:
+		var request dto.SetPresenceRequest
+		if err := decodePayload(inbound.Payload, &request); err != nil {
+			return nil, err
+		}
+		if mc.presenceService == nil {
+			return nil, errors.New(PresenceUnavailable)
+		}
+
+		presence, recipients, err := mc.presenceService.UpdatePresence(userID, &request)
+		if err != nil {
+			return nil, err
+		}
+		mc.hub.SendMany(recipients, *hub.NewMessage(hub.PresenceChanged, presence))
+		return presence, nil
+
+	default:
+		return nil, errors.New(UnknownFrameTypeError)
+	}
+}
+
+// handlePresence records a user coming online or going offline and tells their friends and teammates.
+// It goes by the hub's current state rather than online, since a quick reconnect may be reported out of order.
+func (mc *MessageController) handlePresence(userID string, _ bool) {
+	if mc.presenceService == nil {
+		return
+	}
+	status := entity.PresenceOffline
+	if mc.hub.IsOnline(userID) {
+		status = entity.PresenceOnline
+	}
+
+	presence, recipients, err := mc.presenceService.SetStatus(userID, status)
+	if err != nil {
+		log.Printf("recording presence of %s failed: %v", userID, err)
+		return
+	}
+	mc.hub.SendMany(recipients, *hub.NewMessage(hub.PresenceChanged, presence))
+}
+
+func decodePayload(payload json.RawMessage, target interface{}) error {
+	if len(payload) == 0 || string(payload) == "null" {
+		return errors.New(MissingPayloadError)
+	}
+	return json.Unmarshal(payload, target)
+}
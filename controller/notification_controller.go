@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	notificationService service.NotificationServiceInterface
+}
+
+func NewNotificationController() *NotificationController {
+	return &NotificationController{
+		notificationService: service.NewNotificationService(),
+	}
+}
+
+func NewNotificationControllerWithService(notificationService service.NotificationServiceInterface) *NotificationController {
+	return &NotificationController{notificationService: notificationService}
+}
+
+// GetNotifications
+//
+//	@Summary		Get the user's notifications
+//	@Description	Notifications of the authenticated user, newest first, with the number still unread. New notifications also arrive over the message WebSocket as notification events, and reads made on another connection as notifications_read events.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			unread	query		bool	false	"Only unread notifications"
+//	@Param			page	query		int		false	"Page number (default 1)"
+//	@Param			limit	query		int		false	"Items per page (default 20, max 100)"
+//	@Success		200		{object}	dto.NotificationsPageDTO
+//	@Failure		401		{object}	map[string]interface{}	"Unauthorized"
+//	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/notifications [get]
+func (nc *NotificationController) GetNotifications(c *gin.Context) {
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	page := 1
+	limit := 0
+	if p := c.Query("page"); p != "" {
+		if val, err := strconv.Atoi(p); err == nil {
+			page = val
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if val, err := strconv.Atoi(l); err == nil {
+			limit = val
+		}
+	}
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+
+	notifications, err := nc.notificationService.GetNotifications(userID, unreadOnly, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, notifications)
+}
+
+// GetUnreadCounts
+//
+//	@Summary		Count unread notifications
+//	@Description	Number of unread notifications of the authenticated user, in total and by type
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	dto.UnreadNotificationsDTO
+//	@Failure		401	{object}	map[string]interface{}	"Unauthorized"
+//	@Failure		500	{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/notifications/unread [get]
+func (nc *NotificationController) GetUnreadCounts(c *gin.Context) {
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	counts, err := nc.notificationService.GetUnreadCounts(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, counts)
+}
+
+// MarkRead
+//
+//	@Summary		Mark a notification as read
+//	@Description	Marks one notification of the authenticated user as read. Marking a read notification again changes nothing.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id	path		string	true	"Notification ID"
+//	@Success		200	{object}	entity.Notification
+//	@Failure		401	{object}	map[string]interface{}	"Unauthorized"
+//	@Failure		404	{object}	map[string]interface{}	"Notification not found"
+//	@Failure		500	{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/notifications/{id}/read [post]
+func (nc *NotificationController) MarkRead(c *gin.Context) {
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	notification, err := nc.notificationService.MarkRead(userID, c.Param("id"))
+	if err != nil {
+		writeTeamError(c, err, http.StatusInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, notification)
+}
+
+// MarkAllRead
+//
+//	@Summary		Mark every notification as read
+//	@Description	Marks every unread notification of the authenticated user as read and returns how many were marked
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	dto.NotificationsReadDTO
+//	@Failure		401	{object}	map[string]interface{}	"Unauthorized"
+//	@Failure		500	{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/notifications/read [post]
+func (nc *NotificationController) MarkAllRead(c *gin.Context) {
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	read, err := nc.notificationService.MarkAllRead(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, read)
+}
+
+// GetPreferences
+//
+//	@Summary		Get the user's notification settings
+//	@Description	Whether each notification type goes out in-app, by email and by push, the muted teams and the quiet hours. Users who never saved settings get the defaults: in-app and push on, email off.
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	dto.NotificationPreferencesDTO
+//	@Failure		401	{object}	map[string]interface{}	"Unauthorized"
+//	@Failure		500	{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/notifications/preferences [get]
+func (nc *NotificationController) GetPreferences(c *gin.Context) {
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	preferences, err := nc.notificationService.GetPreferences(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences
+//
+//	@Summary		Replace the user's notification settings
+//	@Description	Channels are set by type; types and channels left out keep their default. Notifications about a muted team are not delivered at all. During quiet hours, read in the given timezone, nothing is sent to sockets, by email or by push; in-app notifications are still kept and listed. Only teams the user is a member of can be muted. Emails go to verified addresses only.
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.NotificationPreferencesRequest	true	"Notification settings"
+//	@Success		200		{object}	dto.NotificationPreferencesDTO
+//	@Failure		400		{object}	map[string]interface{}	"Invalid type, channel, quiet hours or timezone"
+//	@Failure		401		{object}	map[string]interface{}	"Unauthorized"
+//	@Failure		403		{object}	map[string]interface{}	"Not a member of a muted team"
+//	@Failure		404		{object}	map[string]interface{}	"Muted team not found"
+//	@Router			/notifications/preferences [put]
+func (nc *NotificationController) UpdatePreferences(c *gin.Context) {
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	var request dto.NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preferences, err := nc.notificationService.UpdatePreferences(userID, &request)
+	if err != nil {
+		writeTeamError(c, err, http.StatusBadRequest)
+		return
+	}
+	c.JSON(http.StatusOK, preferences)
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/gin-gonic/gin"
+)
+
+type PresenceController struct {
+	presenceService service.PresenceServiceInterface
+}
+
+func NewPresenceController() *PresenceController {
+	return &PresenceController{
+		presenceService: service.NewPresenceService(),
+	}
+}
+
+func NewPresenceControllerWithService(presenceService service.PresenceServiceInterface) *PresenceController {
+	return &PresenceController{presenceService: presenceService}
+}
+
+// GetUserPresence
+//
+//	@Summary		Get a user's presence
+//	@Description	Whether the user is online, idle or offline, and when their status last changed. Visible to the user, their friends and their teammates.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id	path		string	true	"User ID"
+//	@Success		200	{object}	dto.PresenceDTO
+//	@Failure		401	{object}	map[string]interface{}	"Unauthorized"
+//	@Failure		403	{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404	{object}	map[string]interface{}	"User not found"
+//	@Failure		500	{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/users/{id}/presence [get]
+func (pc *PresenceController) GetUserPresence(c *gin.Context) {
+	requesterID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	presence, err := pc.presenceService.GetPresence(requesterID, c.Param("id"))
+	if err != nil {
+		writeTeamError(c, err, http.StatusInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, presence)
+}
+
+// GetTeamPresence
+//
+//	@Summary		Get the presence of a team's members
+//	@Description	The presence of every member of the team, in member order. Only members may ask.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id	path		string	true	"Team ID"
+//	@Success		200	{array}		dto.PresenceDTO
+//	@Failure		401	{object}	map[string]interface{}	"Unauthorized"
+//	@Failure		403	{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404	{object}	map[string]interface{}	"Team not found"
+//	@Failure		500	{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/teams/{id}/presence [get]
+func (pc *PresenceController) GetTeamPresence(c *gin.Context) {
+	requesterID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	roster, err := pc.presenceService.GetTeamPresence(requesterID, c.Param("id"))
+	if err != nil {
+		writeTeamError(c, err, http.StatusInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, roster)
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/gin-gonic/gin"
+)
+
+type SearchController struct {
+	searchService service.SearchServiceInterface
+}
+
+func NewSearchController() *SearchController {
+	return &SearchController{
+		searchService: service.NewSearchService(),
+	}
+}
+
+func NewSearchControllerWithService(searchService service.SearchServiceInterface) *SearchController {
+	return &SearchController{searchService: searchService}
+}
+
+// Search
+//
+//	@Summary		Search messages, files, quizzes, events and teams
+//	@Description	Case-insensitive search of message text, file names, quiz names and questions, event names and descriptions, and team names and descriptions. Every word of q must match a whole word or the start of one.
+//	@Description	Results are limited to what the user can see: the records of their teams and direct conversations, and every team. Best matches come first, newest first among equal scores.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			q		query		string	true	"Words to search for"
+//	@Param			types	query		string	false	"Comma-separated kinds to search: message, file, quiz, event, team (default all)"
+//	@Param			teamId	query		string	false	"Only search the records of this team"
+//	@Param			page	query		int		false	"Page number (default 1)"
+//	@Param			limit	query		int		false	"Items per page (default 20, max 100)"
+//	@Success		200		{object}	dto.SearchResponse
+//	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		401		{object}	map[string]interface{}	"Unauthorized"
+//	@Failure		404		{object}	map[string]interface{}	"User not found"
+//	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/search [get]
+func (sc *SearchController) Search(c *gin.Context) {
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	request := dto.SearchRequest{
+		Query:  c.Query("q"),
+		TeamID: c.Query("teamId"),
+	}
+	if types := c.Query("types"); types != "" {
+		for _, kind := range strings.Split(types, ",") {
+			request.Types = append(request.Types, strings.TrimSpace(kind))
+		}
+	}
+	if p := c.Query("page"); p != "" {
+		if val, err := strconv.Atoi(p); err == nil {
+			request.Page = val
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if val, err := strconv.Atoi(l); err == nil {
+			request.Limit = val
+		}
+	}
+
+	resp, err := sc.searchService.Search(userID, &request)
+	if err != nil {
+		if strings.Contains(err.Error(), "validation") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		writeTeamError(c, err, http.StatusInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
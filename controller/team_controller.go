@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -43,36 +45,67 @@ type TeamServiceInterface interface {
 	CreateTeam(request *dto.TeamRequest) (*entity.Team, error)
 	AddUserToTeam(idUser string, idTeam string) (*entity.User, *entity.Team, error)
 	DeleteUserFromTeam(idUser string, idTeam string) (*entity.User, *entity.Team, error)
-	GetUsersByTeam(idTeam string) ([]*dto.UserResponse, error)
+	GetUsersByTeam(actorID, idTeam string) ([]*dto.UserResponse, error)
 	GetTeamById(id string) (*entity.Team, error)
-	GetXTeamsByPrefix(prefix string, x int) ([]*entity.Team, error)
-	GetTeamsByName(name string) ([]*entity.Team, error)
-	GetAll() ([]*entity.Team, error)
+	GetVisibleTeam(actorID, id string) (*entity.Team, error)
+	GetVisibleTeams(actorID string) ([]*entity.Team, error)
+	GetVisibleTeamsByName(actorID, name string) ([]*entity.Team, error)
+	GetXVisibleTeamsByPrefix(actorID, prefix string, x int) ([]*entity.Team, error)
 	Update(team *entity.Team) error
 	Delete(id string) error
+	AddMember(actorID, idUser, idTeam string) (*entity.User, *entity.Team, error)
+	RemoveMember(actorID, idUser, idTeam string) (*entity.User, *entity.Team, error)
+	UpdateTeam(actorID, idTeam string, changes *dto.UpdateTeamRequest) (*entity.Team, error)
+	DeleteTeam(actorID, idTeam string) error
+	SetMemberRole(actorID, idTeam, idUser string, role entity.TeamRole) (*entity.Team, error)
+	TransferOwnership(actorID, idTeam, newOwnerID string) (*entity.Team, error)
+}
+
+// writeTeamError maps team service errors to HTTP status codes
+func writeTeamError(c *gin.Context, err error, fallback int) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrResourceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(fallback, gin.H{"error": err.Error()})
+	}
 }
 
 // NewTeam
 //
 //	@Summary		Create a new team
-//	@Description	Create a new team with the provided details
+//	@Description	Create a new team with the provided details. The authenticated user becomes its owner.
 //	@Security		Bearer
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		dto.TeamRequest	true	"Team details"
 //	@Success		201		{object}	entity.Team
 //	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		403		{object}	map[string]interface{}	"Email not verified"
 //	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
 //	@Router			/teams [post]
 func (tc *TeamController) NewTeam(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	var request dto.TeamRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	request.UserId = userID
 
 	resp, err := tc.teamService.CreateTeam(&request)
 	if err != nil {
+		if errors.Is(err, service.ErrEmailNotVerified) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -82,7 +115,7 @@ func (tc *TeamController) NewTeam(c *gin.Context) {
 // GetTeam
 //
 //	@Summary		Get a team by ID
-//	@Description	Get team details by ID
+//	@Description	Get team details by ID. Private teams are only found by their members.
 //	@Security		Bearer
 //	@Produce		json
 //	@Param			id	path		string	true	"Team ID"
@@ -90,8 +123,13 @@ func (tc *TeamController) NewTeam(c *gin.Context) {
 //	@Failure		404	{object}	map[string]interface{}	"Team not found"
 //	@Router			/teams/{id} [get]
 func (tc *TeamController) GetTeam(c *gin.Context) {
+	actorID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
 	id := c.Param("id")
-	team, err := tc.teamService.GetTeamById(id)
+	team, err := tc.teamService.GetVisibleTeam(actorID, id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": TeamNotFoundError})
 		return
@@ -103,7 +141,7 @@ func (tc *TeamController) GetTeam(c *gin.Context) {
 // GetAllTeams
 //
 //	@Summary		Get teams with optional filtering
-//	@Description	Get teams - all teams, by name, or by prefix with limit
+//	@Description	Get teams - all teams, by name, or by prefix with limit. Private teams are only listed for their members.
 //	@Security		Bearer
 //	@Produce		json
 //	@Param			name	query		string	false	"Filter by exact name"
@@ -114,13 +152,18 @@ func (tc *TeamController) GetTeam(c *gin.Context) {
 //	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
 //	@Router			/teams [get]
 func (tc *TeamController) GetAllTeams(c *gin.Context) {
+	actorID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
 	name := c.Query("name")
 	prefix := c.Query("prefix")
 	limitStr := c.Query("limit")
 
 	// Filter by exact name
 	if name != "" {
-		teams, err := tc.teamService.GetTeamsByName(name)
+		teams, err := tc.teamService.GetVisibleTeamsByName(actorID, name)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -140,7 +183,7 @@ func (tc *TeamController) GetAllTeams(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": LimitMustBeANumberError})
 			return
 		}
-		teams, err := tc.teamService.GetXTeamsByPrefix(prefix, limit)
+		teams, err := tc.teamService.GetXVisibleTeamsByPrefix(actorID, prefix, limit)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -150,7 +193,7 @@ func (tc *TeamController) GetAllTeams(c *gin.Context) {
 	}
 
 	// Get all teams (no filters)
-	teams, err := tc.teamService.GetAll()
+	teams, err := tc.teamService.GetVisibleTeams(actorID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -162,7 +205,7 @@ func (tc *TeamController) GetAllTeams(c *gin.Context) {
 // AddUserToTeam
 //
 //	@Summary		Add a user to a team
-//	@Description	Adds a user to a team by providing user ID and team ID
+//	@Description	Adds a user to a team by providing user ID and team ID. Users may add themselves to public teams; private teams are joined through a join request or an invitation. Adding someone else requires the admin or owner role.
 //
 //	@Security		Bearer
 //
@@ -171,16 +214,24 @@ func (tc *TeamController) GetAllTeams(c *gin.Context) {
 //	@Param			request	body		dto.UserToTeamRequest	true	"User ID and Team ID"
 //	@Success		200		{object}	dto.AddUserToTeamResponse
 //	@Failure		400		{object}	map[string]string	"Invalid request body or error"
+//	@Failure		403		{object}	map[string]string	"Forbidden"
+//	@Failure		404		{object}	map[string]string	"Team not found"
 //	@Router			/teams/users [put]
 func (tc *TeamController) AddUserToTeam(c *gin.Context) {
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	var req dto.UserToTeamRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": InvalidRequestBodyError})
 		return
 	}
-	user, team, err := tc.teamService.AddUserToTeam(req.UserID, req.TeamID)
+	user, team, err := tc.teamService.AddMember(actorID, req.UserID, req.TeamID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeTeamError(c, err, http.StatusBadRequest)
 		return
 	}
 	resp := dto.NewAddUserToTeamResponse(*user, *team)
@@ -190,7 +241,7 @@ func (tc *TeamController) AddUserToTeam(c *gin.Context) {
 // DeleteUserFromTeam
 //
 //	@Summary		Delete a user from a team
-//	@Description	Deletes a user from a team by providing team ID
+//	@Description	Deletes a user from a team by providing team ID. Members may remove themselves; removing others requires the admin role, and removing an admin requires the owner.
 //
 //	@Security		Bearer
 //
@@ -199,8 +250,15 @@ func (tc *TeamController) AddUserToTeam(c *gin.Context) {
 //	@Param			request	body		dto.UserToTeamRequest		true	"User ID and Team ID"
 //	@Success		200		{object}	dto.AddUserToTeamResponse	"User removed from team"
 //	@Failure		400		{object}	map[string]string			"Invalid request body or error"
+//	@Failure		403		{object}	map[string]string			"Forbidden"
 //	@Router			/teams/users [delete]
 func (tc *TeamController) DeleteUserFromTeam(c *gin.Context) {
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	var req dto.UserToTeamRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": InvalidRequestBodyError})
@@ -210,9 +268,9 @@ func (tc *TeamController) DeleteUserFromTeam(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": EmptyParametersError})
 		return
 	}
-	user, team, err := tc.teamService.DeleteUserFromTeam(req.UserID, req.TeamID)
+	user, team, err := tc.teamService.RemoveMember(actorID, req.UserID, req.TeamID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeTeamError(c, err, http.StatusBadRequest)
 		return
 	}
 	resp := dto.NewAddUserToTeamResponse(*user, *team)
@@ -222,12 +280,13 @@ func (tc *TeamController) DeleteUserFromTeam(c *gin.Context) {
 // GetUsersByTeam
 //
 //	@Summary		Get users by team ID
-//	@Description	Get all users that are members of a specific team
+//	@Description	Get all users that are members of a specific team. Only members of the team may list them.
 //	@Security		Bearer
 //	@Accept			json
 //	@Param			id	path		string	true	"Team ID"
 //	@Success		200	{array}		dto.UserResponse
 //	@Failure		400	{object}	map[string]interface{}	"Bad Request - Invalid team ID"
+//	@Failure		403	{object}	map[string]interface{}	"Not a member of the team"
 //	@Failure		404	{object}	map[string]interface{}	"Team not found"
 //	@Failure		500	{object}	map[string]interface{}	"Internal Server Error"
 //	@Router			/teams/{id}/users [get]
@@ -238,10 +297,16 @@ func (tc *TeamController) GetUsersByTeam(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Team ID is required"})
 		return
 	}
+	actorID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
 
-	users, err := tc.teamService.GetUsersByTeam(teamID)
+	users, err := tc.teamService.GetUsersByTeam(actorID, teamID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "team not found") {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "team not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		} else if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "required") {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -257,25 +322,34 @@ func (tc *TeamController) GetUsersByTeam(c *gin.Context) {
 // UpdateTeam
 //
 //	@Summary		Update a team
-//	@Description	Update team details by providing team ID and updated details
+//	@Description	Update team details by providing team ID and the details to change; fields left out keep their value. Requires the admin or owner role.
 //	@Security		Bearer
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		path		string		true	"Team ID"
-//	@Param			team	body		entity.Team	true	"Updated team details"
+//	@Param			id		path		string					true	"Team ID"
+//	@Param			team	body		dto.UpdateTeamRequest	true	"Team details to change"
 //	@Success		200		{object}	entity.Team
 //	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		403		{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404		{object}	map[string]interface{}	"Team not found"
 //	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
 //	@Router			/teams/{id} [put]
 func (tc *TeamController) UpdateTeam(c *gin.Context) {
-	var team entity.Team
-	if err := c.ShouldBindJSON(&team); err != nil {
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var changes dto.UpdateTeamRequest
+	if err := c.ShouldBindJSON(&changes); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := tc.teamService.Update(&team); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	team, err := tc.teamService.UpdateTeam(actorID, c.Param("id"), &changes)
+	if err != nil {
+		writeTeamError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -285,25 +359,106 @@ func (tc *TeamController) UpdateTeam(c *gin.Context) {
 // DeleteTeam
 //
 //	@Summary		Delete a team
-//	@Description	Delete a team by providing team ID
+//	@Description	Delete a team by providing team ID. Only the owner can delete a team.
 //	@Security		Bearer
 //	@Produce		json
 //	@Param			id	path		string					true	"Team ID"
 //	@Success		200	{object}	map[string]interface{}	"Team deleted"
 //	@Failure		400	{object}	map[string]interface{}	"Bad Request: Missing team ID"
+//	@Failure		403	{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404	{object}	map[string]interface{}	"Team not found"
 //	@Failure		500	{object}	map[string]interface{}	"Internal Server Error"
 //	@Router			/teams/{id} [delete]
 func (tc *TeamController) DeleteTeam(c *gin.Context) {
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	id := c.Param("id")
 	if id == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": MissingTeamIDError})
 		return
 	}
 
-	if err := tc.teamService.Delete(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := tc.teamService.DeleteTeam(actorID, id); err != nil {
+		writeTeamError(c, err, http.StatusInternalServerError)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": TeamDeletedMessage})
 }
+
+// UpdateMemberRole
+//
+//	@Summary		Change the role of a team member
+//	@Description	Promotes a member to admin or demotes an admin to member. Only the owner can change roles.
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"Team ID"
+//	@Param			userId	path		string						true	"Member ID"
+//	@Param			request	body		dto.UpdateMemberRoleRequest	true	"The new role (admin or member)"
+//	@Success		200		{object}	entity.Team
+//	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		403		{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404		{object}	map[string]interface{}	"Team not found"
+//	@Router			/teams/{id}/members/{userId}/role [put]
+func (tc *TeamController) UpdateMemberRole(c *gin.Context) {
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.UpdateMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": InvalidRequestBodyError})
+		return
+	}
+
+	team, err := tc.teamService.SetMemberRole(actorID, c.Param("id"), c.Param("userId"), req.Role)
+	if err != nil {
+		writeTeamError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+// TransferOwnership
+//
+//	@Summary		Transfer team ownership
+//	@Description	Makes another member the owner of the team. The previous owner becomes an admin.
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"Team ID"
+//	@Param			request	body		dto.TransferOwnershipRequest	true	"The new owner"
+//	@Success		200		{object}	entity.Team
+//	@Failure		400		{object}	map[string]interface{}	"Bad Request"
+//	@Failure		403		{object}	map[string]interface{}	"Forbidden"
+//	@Failure		404		{object}	map[string]interface{}	"Team not found"
+//	@Router			/teams/{id}/owner [put]
+func (tc *TeamController) TransferOwnership(c *gin.Context) {
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": InvalidRequestBodyError})
+		return
+	}
+
+	team, err := tc.teamService.TransferOwnership(actorID, c.Param("id"), req.NewOwnerID)
+	if err != nil {
+		writeTeamError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
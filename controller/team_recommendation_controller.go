@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/gin-gonic/gin"
+)
+
+type TeamRecommendationController struct {
+	recommendationService service.TeamRecommendationServiceInterface
+}
+
+func NewTeamRecommendationController() *TeamRecommendationController {
+	return &TeamRecommendationController{
+		recommendationService: service.NewTeamRecommendationService(),
+	}
+}
+
+func NewTeamRecommendationControllerWithService(recommendationService service.TeamRecommendationServiceInterface) *TeamRecommendationController {
+	return &TeamRecommendationController{recommendationService: recommendationService}
+}
+
+// GetRecommendedTeams
+//
+//	@Summary		Get recommended teams
+//	@Description	Public teams the user is not in and has no pending request for, best first. Teams rank higher when their topic is one of the user's topics of interest, when the user's friends are members, when messages were sent in them in the last two weeks, and the more members they have.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			page	query		int	false	"Page number (default 1)"
+//	@Param			limit	query		int	false	"Items per page (default 10, max 50)"
+//	@Success		200		{object}	dto.TeamRecommendationsDTO
+//	@Failure		401		{object}	map[string]interface{}	"Unauthorized"
+//	@Failure		404		{object}	map[string]interface{}	"User not found"
+//	@Failure		500		{object}	map[string]interface{}	"Internal Server Error"
+//	@Router			/teams/recommended [get]
+func (rc *TeamRecommendationController) GetRecommendedTeams(c *gin.Context) {
+	userID, ok := actingUserID(c, "")
+	if !ok {
+		return
+	}
+
+	page := 1
+	limit := 0
+	if p := c.Query("page"); p != "" {
+		if val, err := strconv.Atoi(p); err == nil {
+			page = val
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if val, err := strconv.Atoi(l); err == nil {
+			limit = val
+		}
+	}
+
+	recommendations, err := rc.recommendationService.GetRecommendedTeams(userID, page, limit)
+	if err != nil {
+		writeTeamError(c, err, http.StatusInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, recommendations)
+}
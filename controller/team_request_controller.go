@@ -1,11 +1,14 @@
 package controller
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -27,22 +30,33 @@ func NewTeamRequestControllerWithService(service TeamRequestServiceInterface) *T
 
 type TeamRequestServiceInterface interface {
 	CreateTeamRequest(req *dto.TeamRequestCreateDTO) (*entity.TeamRequest, error)
-	AcceptTeamRequest(id string) (*entity.User, *entity.Team, error)
-	RejectTeamRequest(id string) error
-	GetAll() ([]*entity.TeamRequest, error)
+	AcceptTeamRequest(id, reviewerID string) (*entity.User, *entity.Team, error)
+	RejectTeamRequest(id, reviewerID string) error
+	WithdrawTeamRequest(id, userID string) (*entity.TeamRequest, error)
+	GetTeamRequests(actorID, teamID string, status entity.TeamRequestStatus) ([]*entity.TeamRequest, error)
+	GetAll(actorID string) ([]*entity.TeamRequest, error)
 	GetByUserId(userId string) ([]*entity.TeamRequest, error)
+	InviteToTeam(inviterID, teamID string, request *dto.TeamInviteRequest) (*entity.TeamRequest, error)
+	GetInvitations(userID string) ([]*entity.TeamRequest, error)
+	AcceptInvitation(id, userID string) (*entity.User, *entity.Team, error)
+	DeclineInvitation(id, userID string) error
+	CreateInviteLink(actorID, teamID string, request *dto.TeamInviteLinkRequest) (*entity.TeamInviteLink, error)
+	GetInviteLinks(actorID, teamID string) ([]*entity.TeamInviteLink, error)
+	RevokeInviteLink(actorID, teamID, token string) error
+	JoinByInviteLink(userID, token string) (*entity.User, *entity.Team, error)
 }
 
 // CreateTeamRequest
 //
 //	@Summary		Create a new team request
-//	@Description	Creates a join request for a user to join a team.
+//	@Description	Creates a join request for the authenticated user to join a team, with an optional message of up to 500 characters for the team admins. Requests expire after 30 days without an answer.
 //	@Security		Bearer
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		dto.TeamRequestCreateDTO	true	"Team and User IDs"
 //	@Success		201		{object}	dto.TeamRequestItemDTO
 //	@Failure		400		{object}	map[string]string	"Invalid request or validation error"
+//	@Failure		403		{object}	map[string]string	"Forbidden"
 //	@Failure		500		{object}	map[string]string	"Internal Server Error"
 //	@Router			/teamRequests [post]
 func (tc *TeamRequestController) CreateTeamRequest(c *gin.Context) {
@@ -52,6 +66,12 @@ func (tc *TeamRequestController) CreateTeamRequest(c *gin.Context) {
 		return
 	}
 
+	userID, ok := actingUserID(c, req.UserID)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
 	created, err := tc.teamRequestService.CreateTeamRequest(&req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -64,19 +84,26 @@ func (tc *TeamRequestController) CreateTeamRequest(c *gin.Context) {
 // AcceptTeamRequest
 //
 //	@Summary		Accept a team request
-//	@Description	Accepts a pending team request, adds the user to the team, and deletes the request.
+//	@Description	Accepts a pending team request and adds the user to the team. The request is kept with status accepted. Requires the admin or owner role in the team. Invitations cannot be accepted here, only by the invited user.
 //	@Security		Bearer
 //	@Produce		json
 //	@Param			id	path		string	true	"Team Request ID"
 //	@Success		200	{object}	dto.AddUserToTeamResponse
 //	@Failure		400	{object}	map[string]string	"Bad Request or Not Found"
+//	@Failure		403	{object}	map[string]string	"Forbidden"
 //	@Failure		500	{object}	map[string]string	"Internal Server Error"
 //	@Router			/teamRequests/{id}/accept [put]
 func (tc *TeamRequestController) AcceptTeamRequest(c *gin.Context) {
+	reviewerID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	id := c.Param("id")
-	user, team, err := tc.teamRequestService.AcceptTeamRequest(id)
+	user, team, err := tc.teamRequestService.AcceptTeamRequest(id, reviewerID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeTeamError(c, err, http.StatusBadRequest)
 		return
 	}
 	c.JSON(http.StatusOK, dto.NewAddUserToTeamResponse(*user, *team))
@@ -85,33 +112,102 @@ func (tc *TeamRequestController) AcceptTeamRequest(c *gin.Context) {
 // RejectTeamRequest
 //
 //	@Summary		Reject a team request
-//	@Description	Rejects and deletes a pending team request.
+//	@Description	Rejects a pending team request, or takes back an invitation, which then has status withdrawn. Requires the admin or owner role in the team.
 //	@Security		Bearer
 //	@Produce		json
 //	@Param			id	path		string				true	"Team Request ID"
 //	@Success		200	{object}	map[string]string	"Team request rejected"
 //	@Failure		400	{object}	map[string]string	"Bad Request or Not Found"
+//	@Failure		403	{object}	map[string]string	"Forbidden"
 //	@Router			/teamRequests/{id}/reject [delete]
 func (tc *TeamRequestController) RejectTeamRequest(c *gin.Context) {
+	reviewerID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	id := c.Param("id")
-	if err := tc.teamRequestService.RejectTeamRequest(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := tc.teamRequestService.RejectTeamRequest(id, reviewerID); err != nil {
+		writeTeamError(c, err, http.StatusBadRequest)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Team request rejected"})
 }
 
+// WithdrawTeamRequest
+//
+//	@Summary		Withdraw a team request
+//	@Description	Takes back a pending join request of the authenticated user. The request is kept with status withdrawn.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id	path		string	true	"Team Request ID"
+//	@Success		200	{object}	dto.TeamRequestItemDTO
+//	@Failure		400	{object}	map[string]string	"Not found or no longer pending"
+//	@Failure		403	{object}	map[string]string	"Not the requester"
+//	@Router			/teamRequests/{id}/withdraw [put]
+func (tc *TeamRequestController) WithdrawTeamRequest(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	req, err := tc.teamRequestService.WithdrawTeamRequest(c.Param("id"), userID)
+	if err != nil {
+		writeTeamError(c, err, http.StatusBadRequest)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTeamRequestItemDTO(req))
+}
+
+// GetTeamRequests
+//
+//	@Summary		Get the requests of a team
+//	@Description	Lists the join requests and invitations of a team, newest first. Requires the admin or owner role in the team.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id		path		string	true	"Team ID"
+//	@Param			status	query		string	false	"Only requests with this status: pending, accepted, rejected, withdrawn or expired"
+//	@Success		200		{object}	dto.TeamRequestsResponseDTO
+//	@Failure		400		{object}	map[string]string	"Invalid status"
+//	@Failure		403		{object}	map[string]string	"Forbidden"
+//	@Failure		404		{object}	map[string]string	"Team not found"
+//	@Router			/teams/{id}/requests [get]
+func (tc *TeamRequestController) GetTeamRequests(c *gin.Context) {
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := entity.TeamRequestStatus(c.Query("status"))
+	reqs, err := tc.teamRequestService.GetTeamRequests(actorID, c.Param("id"), status)
+	if err != nil {
+		writeTeamError(c, err, http.StatusBadRequest)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTeamRequestsResponseDTO(reqs))
+}
+
 // GetAllTeamRequests
 //
 //	@Summary		Get all team requests
-//	@Description	Fetches all pending team requests in the system.
+//	@Description	Fetches the team requests the caller may see, newest first and answered ones included: their own join requests and invitations, and the requests of the teams where they are an admin or the owner.
 //	@Security		Bearer
 //	@Produce		json
 //	@Success		200	{object}	dto.TeamRequestsResponseDTO
+//	@Failure		401	{object}	map[string]string	"Unauthorized"
 //	@Failure		500	{object}	map[string]string	"Internal Server Error"
 //	@Router			/teamRequests [get]
 func (tc *TeamRequestController) GetAllTeamRequests(c *gin.Context) {
-	reqs, err := tc.teamRequestService.GetAll()
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	reqs, err := tc.teamRequestService.GetAll(actorID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -122,21 +218,26 @@ func (tc *TeamRequestController) GetAllTeamRequests(c *gin.Context) {
 // GetTeamRequestsByUser
 //
 //	@Summary		Get all team requests for a specific user
-//	@Description	Fetches all pending team requests created by a given user.
+//	@Description	Fetches all team requests and invitations of a given user, answered ones included. Users can only list their own requests.
 //	@Security		Bearer
 //	@Produce		json
 //	@Param			userId	path		string	true	"User ID"
 //	@Success		200		{object}	dto.TeamRequestsResponseDTO
 //	@Failure		400		{object}	map[string]string	"Bad Request"
+//	@Failure		403		{object}	map[string]string	"Forbidden"
 //	@Failure		500		{object}	map[string]string	"Internal Server Error"
 //	@Router			/teamRequests/user/{userId} [get]
 func (tc *TeamRequestController) GetTeamRequestsByUser(c *gin.Context) {
-	userId := c.Param("userId")
-	if userId == "" {
+	if c.Param("userId") == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing userId"})
 		return
 	}
 
+	userId, ok := actingUserID(c, c.Param("userId"))
+	if !ok {
+		return
+	}
+
 	reqs, err := tc.teamRequestService.GetByUserId(userId)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -145,3 +246,232 @@ func (tc *TeamRequestController) GetTeamRequestsByUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dto.NewTeamRequestsResponseDTO(reqs))
 }
+
+// InviteToTeam
+//
+//	@Summary		Invite a user to a team
+//	@Description	Invites a user, named by exactly one of userId, username or email, to join the team. The invitation shows up in the user's invitations until they accept or decline it. Requires the admin or owner role in the team.
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string					true	"Team ID"
+//	@Param			request	body		dto.TeamInviteRequest	true	"The user to invite"
+//	@Success		201		{object}	dto.TeamRequestItemDTO
+//	@Failure		400		{object}	map[string]string	"Invalid request, already a member or already invited"
+//	@Failure		403		{object}	map[string]string	"Forbidden"
+//	@Failure		404		{object}	map[string]string	"Team or user not found"
+//	@Router			/teams/{id}/invitations [post]
+func (tc *TeamRequestController) InviteToTeam(c *gin.Context) {
+	inviterID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.TeamInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invite, err := tc.teamRequestService.InviteToTeam(inviterID, c.Param("id"), &req)
+	if err != nil {
+		writeTeamError(c, err, http.StatusBadRequest)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.NewTeamRequestItemDTO(invite))
+}
+
+// GetInvitations
+//
+//	@Summary		Get the user's team invitations
+//	@Description	Fetches the invitations to join a team that wait for the authenticated user to accept or decline them.
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	dto.TeamRequestsResponseDTO
+//	@Failure		500	{object}	map[string]string	"Internal Server Error"
+//	@Router			/teamInvitations [get]
+func (tc *TeamRequestController) GetInvitations(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitations, err := tc.teamRequestService.GetInvitations(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTeamRequestsResponseDTO(invitations))
+}
+
+// AcceptInvitation
+//
+//	@Summary		Accept a team invitation
+//	@Description	Adds the authenticated user to the team they were invited to. The invitation is kept with status accepted.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id	path		string	true	"Invitation ID"
+//	@Success		200	{object}	dto.AddUserToTeamResponse
+//	@Failure		400	{object}	map[string]string	"Bad Request"
+//	@Failure		403	{object}	map[string]string	"Not the invited user"
+//	@Failure		404	{object}	map[string]string	"Invitation not found"
+//	@Router			/teamInvitations/{id}/accept [put]
+func (tc *TeamRequestController) AcceptInvitation(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, team, err := tc.teamRequestService.AcceptInvitation(c.Param("id"), userID)
+	if err != nil {
+		writeTeamError(c, err, http.StatusBadRequest)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewAddUserToTeamResponse(*user, *team))
+}
+
+// DeclineInvitation
+//
+//	@Summary		Decline a team invitation
+//	@Description	Declines an invitation of the authenticated user without joining the team. The invitation is kept with status rejected.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id	path		string				true	"Invitation ID"
+//	@Success		200	{object}	map[string]string	"Invitation declined"
+//	@Failure		403	{object}	map[string]string	"Not the invited user"
+//	@Failure		404	{object}	map[string]string	"Invitation not found"
+//	@Router			/teamInvitations/{id}/decline [delete]
+func (tc *TeamRequestController) DeclineInvitation(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tc.teamRequestService.DeclineInvitation(c.Param("id"), userID); err != nil {
+		writeTeamError(c, err, http.StatusBadRequest)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
+
+// CreateInviteLink
+//
+//	@Summary		Create a team invite link
+//	@Description	Creates a link anyone can join the team through until it expires or reaches maxUses. Links expire after a week by default and at most after 30 days; maxUses 0 means no limit. Requires the admin or owner role in the team.
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"Team ID"
+//	@Param			request	body		dto.TeamInviteLinkRequest	false	"Expiry and usage limit"
+//	@Success		201		{object}	dto.TeamInviteLinkDTO
+//	@Failure		400		{object}	map[string]string	"Invalid request"
+//	@Failure		403		{object}	map[string]string	"Forbidden"
+//	@Failure		404		{object}	map[string]string	"Team not found"
+//	@Router			/teams/{id}/inviteLinks [post]
+func (tc *TeamRequestController) CreateInviteLink(c *gin.Context) {
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.TeamInviteLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, err := tc.teamRequestService.CreateInviteLink(actorID, c.Param("id"), &req)
+	if err != nil {
+		writeTeamError(c, err, http.StatusBadRequest)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.NewTeamInviteLinkDTO(link, service.InviteLinkURL(link.ID)))
+}
+
+// GetInviteLinks
+//
+//	@Summary		Get the invite links of a team
+//	@Description	Lists the invite links of the team, newest first, including expired and used up ones. Requires the admin or owner role in the team.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id	path		string	true	"Team ID"
+//	@Success		200	{object}	dto.TeamInviteLinksResponseDTO
+//	@Failure		403	{object}	map[string]string	"Forbidden"
+//	@Failure		404	{object}	map[string]string	"Team not found"
+//	@Failure		500	{object}	map[string]string	"Internal Server Error"
+//	@Router			/teams/{id}/inviteLinks [get]
+func (tc *TeamRequestController) GetInviteLinks(c *gin.Context) {
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	links, err := tc.teamRequestService.GetInviteLinks(actorID, c.Param("id"))
+	if err != nil {
+		writeTeamError(c, err, http.StatusInternalServerError)
+		return
+	}
+	items := make([]dto.TeamInviteLinkDTO, 0, len(links))
+	for _, link := range links {
+		items = append(items, *dto.NewTeamInviteLinkDTO(link, service.InviteLinkURL(link.ID)))
+	}
+	c.JSON(http.StatusOK, dto.TeamInviteLinksResponseDTO{Links: items})
+}
+
+// RevokeInviteLink
+//
+//	@Summary		Revoke a team invite link
+//	@Description	Deletes an invite link so nobody can join through it anymore. Requires the admin or owner role in the team.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			id		path		string				true	"Team ID"
+//	@Param			token	path		string				true	"Invite link token"
+//	@Success		200		{object}	map[string]string	"Invite link revoked"
+//	@Failure		403		{object}	map[string]string	"Forbidden"
+//	@Failure		404		{object}	map[string]string	"Team or invite link not found"
+//	@Router			/teams/{id}/inviteLinks/{token} [delete]
+func (tc *TeamRequestController) RevokeInviteLink(c *gin.Context) {
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tc.teamRequestService.RevokeInviteLink(actorID, c.Param("id"), c.Param("token")); err != nil {
+		writeTeamError(c, err, http.StatusInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invite link revoked"})
+}
+
+// JoinByInviteLink
+//
+//	@Summary		Join a team through an invite link
+//	@Description	Adds the authenticated user to the team of the invite link and counts one use of it.
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			token	path		string	true	"Invite link token"
+//	@Success		200		{object}	dto.AddUserToTeamResponse
+//	@Failure		400		{object}	map[string]string	"Link expired or used up, or already a member"
+//	@Failure		404		{object}	map[string]string	"Invite link not found"
+//	@Router			/inviteLinks/{token}/join [post]
+func (tc *TeamRequestController) JoinByInviteLink(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, team, err := tc.teamRequestService.JoinByInviteLink(userID, c.Param("token"))
+	if err != nil {
+		writeTeamError(c, err, http.StatusBadRequest)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewAddUserToTeamResponse(*user, *team))
+}
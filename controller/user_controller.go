@@ -10,6 +10,7 @@ import (
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,17 +18,26 @@ const (
 	userNotFoundError       = "User not found"
 	userDeletedSuccessfully = "User deleted successfully"
 	invalidCredentials      = "invalid email or password"
+	loggedOutSuccessfully   = "Logged out successfully"
+	emailVerified           = "Email verified successfully"
+	verificationEmailSent   = "Verification email sent"
+	passwordResetEmailSent  = "If an account with that email exists, a password reset link has been sent"
+	passwordResetSuccess    = "Password reset successfully"
 )
 
 type UserController struct {
 	userService          UserServiceInterface
 	friendRequestService service.FriendRequestServiceInterface
+	sessionService       service.SessionServiceInterface
+	verificationService  service.VerificationServiceInterface
 }
 
 func NewUserController() *UserController {
 	return &UserController{
 		userService:          service.NewUserService(),
 		friendRequestService: service.NewFriendRequestService(),
+		sessionService:       service.NewSessionService(),
+		verificationService:  service.NewVerificationService(),
 	}
 }
 
@@ -41,6 +51,14 @@ func (uc *UserController) SetFriendRequestService(svc service.FriendRequestServi
 	uc.friendRequestService = svc
 }
 
+func (uc *UserController) SetSessionService(svc service.SessionServiceInterface) {
+	uc.sessionService = svc
+}
+
+func (uc *UserController) SetVerificationService(svc service.VerificationServiceInterface) {
+	uc.verificationService = svc
+}
+
 type UserServiceInterface interface {
 	SignUp(request *dto.SignUpUserRequest) (*dto.SignUpUserResponse, error)
 	GetUserByID(id string) (*entity.User, error)
@@ -49,7 +67,7 @@ type UserServiceInterface interface {
 	Login(request *dto.LoginRequest) (*dto.LoginResponse, error)
 	UpdateUser(user *entity.User) error
 	UpdateUserProfile(userID string, req *dto.UserUpdateRequestDTO) (*dto.UserUpdateResponseDTO, error)
-	UpdateUserPassword(userID string, req *dto.UserPasswordRequestDTO) error
+	UpdateUserPassword(userID, sessionID string, req *dto.UserPasswordRequestDTO) error
 	DeleteUser(id string) error
 	GetAllUsers() ([]*entity.User, error)
 	GetUserStatistics(id string) (*dto.StatisticsResponse, error)
@@ -172,16 +190,17 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 
 // UpdateUserPassword
 //
-//	@Summary	Update user password
-//	@Security	Bearer
-//	@Accept		json
-//	@Produce	json
-//	@Param		id		path		string						true	"The user's ID"
-//	@Param		request	body		dto.UserPasswordRequestDTO	true	"The password update request"
-//	@Success	200		{object}	map[string]string
-//	@Failure	400		{object}	map[string]string
-//	@Failure	500		{object}	map[string]string
-//	@Router		/users/{id}/password [put]
+//	@Summary		Update user password
+//	@Description	Changes the password and logs the user out of every other session.
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"The user's ID"
+//	@Param			request	body		dto.UserPasswordRequestDTO	true	"The password update request"
+//	@Success		200		{object}	map[string]string
+//	@Failure		400		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Router			/users/{id}/password [put]
 func (uc *UserController) UpdateUserPassword(c *gin.Context) {
 	id := c.Param("id")
 
@@ -191,8 +210,14 @@ func (uc *UserController) UpdateUserPassword(c *gin.Context) {
 		return
 	}
 
+	// the session the password is changed from stays logged in
+	sessionID := ""
+	if claims, err := utils.GetClaimsFromContext(c); err == nil {
+		sessionID, _ = claims["sid"].(string)
+	}
+
 	req.ID = id
-	if err := uc.userService.UpdateUserPassword(id, &req); err != nil {
+	if err := uc.userService.UpdateUserPassword(id, sessionID, &req); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -208,6 +233,7 @@ func (uc *UserController) UpdateUserPassword(c *gin.Context) {
 //	@Produce	json
 //	@Param		id	path		string	true	"The user's ID"
 //	@Success	200	{object}	map[string]string
+//	@Failure	403	{object}	map[string]string	"The user owns a team"
 //	@Failure	404	{object}	map[string]string
 //	@Failure	500	{object}	map[string]string
 //	@Router		/users/{id} [delete]
@@ -215,6 +241,10 @@ func (uc *UserController) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uc.userService.DeleteUser(id); err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -330,6 +360,210 @@ func (uc *UserController) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// RefreshToken
+//
+//	@Summary		Exchange a refresh token for a new token pair
+//	@Description	The refresh token is rotated on every call; reusing an old one revokes the whole session.
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.RefreshTokenRequest	true	"The refresh token"
+//	@Success		200		{object}	dto.LoginResponse
+//	@Failure		400		{object}	map[string]string
+//	@Failure		401		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Router			/users/refresh [post]
+func (uc *UserController) RefreshToken(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := uc.sessionService.RefreshSession(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Logout
+//
+//	@Summary		Log out of the current session
+//	@Description	Revokes the session of the presented access token, together with its refresh token.
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	map[string]string
+//	@Failure		401	{object}	map[string]string
+//	@Failure		500	{object}	map[string]string
+//	@Router			/users/logout [post]
+func (uc *UserController) Logout(c *gin.Context) {
+	claims, err := utils.GetClaimsFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := uc.sessionService.Logout(claims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": loggedOutSuccessfully})
+}
+
+// LogoutAll
+//
+//	@Summary		Log out of every session
+//	@Description	Revokes all sessions of the authenticated user on every device.
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	map[string]string
+//	@Failure		401	{object}	map[string]string
+//	@Failure		500	{object}	map[string]string
+//	@Router			/users/logout-all [post]
+func (uc *UserController) LogoutAll(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := uc.sessionService.LogoutAll(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": loggedOutSuccessfully})
+}
+
+// VerifyEmail
+//
+//	@Summary		Confirm an email address
+//	@Description	Consumes the single-use token that was mailed to the user on sign-up or email change.
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.VerifyEmailRequest	true	"The verification token"
+//	@Success		200		{object}	map[string]string
+//	@Failure		400		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Router			/users/verify-email [post]
+func (uc *UserController) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := uc.verificationService.VerifyEmail(req.Token); err != nil {
+		if errors.Is(err, service.ErrInvalidUserToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": emailVerified})
+}
+
+// ResendVerificationEmail
+//
+//	@Summary		Resend the email verification link
+//	@Description	Sends a new verification link to the authenticated user's email.
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	map[string]string
+//	@Failure		401	{object}	map[string]string
+//	@Failure		409	{object}	map[string]string
+//	@Failure		500	{object}	map[string]string
+//	@Router			/users/verify-email/resend [post]
+func (uc *UserController) ResendVerificationEmail(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := uc.verificationService.ResendEmailVerification(userID); err != nil {
+		if errors.Is(err, service.ErrEmailAlreadyVerified) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": verificationEmailSent})
+}
+
+// ForgotPassword
+//
+//	@Summary		Request a password reset link
+//	@Description	Always answers the same way, whether or not an account uses the email.
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.ForgotPasswordRequest	true	"The account email"
+//	@Success		200		{object}	map[string]string
+//	@Failure		400		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Router			/users/password/forgot [post]
+func (uc *UserController) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := uc.verificationService.RequestPasswordReset(req.Email); err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": passwordResetEmailSent})
+}
+
+// ResetPassword
+//
+//	@Summary		Reset the password with a mailed token
+//	@Description	Sets a new password and revokes every session of the user.
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.ResetPasswordRequest	true	"The reset token and the new password"
+//	@Success		200		{object}	map[string]string
+//	@Failure		400		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Router			/users/password/reset [post]
+func (uc *UserController) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := uc.verificationService.ResetPassword(&req); err != nil {
+		if errors.Is(err, service.ErrInvalidUserToken) ||
+			strings.Contains(err.Error(), "required") ||
+			strings.Contains(err.Error(), "at least") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": passwordResetSuccess})
+}
+
 // GetFriends
 //
 //	@Summary		Get friends for a user
@@ -41,7 +41,7 @@ var upgrader = websocket.Upgrader{
 }
 
 type RoomResponse struct {
-	entity.VoiceRoom
+	*entity.VoiceRoom
 	UserCount int `json:"userCount" example:"2"`
 }
 
@@ -70,19 +70,23 @@ func NewVoiceController() *VoiceController {
 //	@Accept			json
 //	@Produce		json
 //	@Security		Bearer
-//	@Param			callerId	query		string	true	"ID of the user initiating the call"
+//	@Param			callerId	query		string	false	"ID of the user initiating the call, must match the authenticated user"
 //	@Param			targetId	query		string	true	"ID of the user being called"
 //	@Param			teamId		query		string	false	"Team ID for context"
 //	@Success		201			{object}	entity.VoiceRoom
 //	@Failure		400			{object}	map[string]string
+//	@Failure		403			{object}	map[string]string
 //	@Router			/voice/private/call [post]
 func (vc *VoiceController) StartPrivateCall(c *gin.Context) {
-	callerId := c.Query("callerId")
+	callerId, ok := actingUserID(c, c.Query("callerId"))
+	if !ok {
+		return
+	}
 	targetId := c.Query("targetId")
 	teamId := c.Query("teamId")
 
-	if callerId == "" || targetId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Both callerId and targetId are required"})
+	if targetId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "targetId is required"})
 		return
 	}
 
@@ -121,15 +125,13 @@ func (vc *VoiceController) StartPrivateCall(c *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Security		Bearer
-//	@Param			userId	query		string	true	"User ID of the client"
+//	@Param			userId	query		string	false	"User ID of the client, must match the authenticated user"
 //	@Success		200		{array}		controller.RoomResponse
-//	@Failure		400		{object}	map[string]string
+//	@Failure		403		{object}	map[string]string
 //	@Router			/voice/joinable [get]
 func (vc *VoiceController) GetJoinableRooms(c *gin.Context) {
-	userId := c.Query("userId")
-
-	if userId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "userId query parameter is required"})
+	userId, ok := actingUserID(c, c.Query("userId"))
+	if !ok {
 		return
 	}
 
@@ -161,7 +163,7 @@ func (vc *VoiceController) GetJoinableRooms(c *gin.Context) {
 
 		if isJoinable {
 			responseList = append(responseList, RoomResponse{
-				VoiceRoom: *room,
+				VoiceRoom: room,
 				UserCount: userCount,
 			})
 		}
@@ -182,14 +184,18 @@ func (vc *VoiceController) GetJoinableRooms(c *gin.Context) {
 //	@Produce		json
 //	@Security		Bearer
 //	@Param			teamId	path		string	true	"Team ID"
-//	@Param			userId	query		string	true	"User ID of the creator"
+//	@Param			userId	query		string	false	"User ID of the creator, must match the authenticated user"
 //	@Param			name	query		string	false	"Room name (optional)"
 //	@Success		201		{object}	entity.VoiceRoom
+//	@Failure		403		{object}	map[string]string
 //	@Failure		409		{object}	map[string]string
 //	@Router			/voice/rooms/{teamId} [post]
 func (vc *VoiceController) CreateVoiceRoom(c *gin.Context) {
 	teamId := c.Param("teamId")
-	userId := c.Query("userId")
+	userId, ok := actingUserID(c, c.Query("userId"))
+	if !ok {
+		return
+	}
 	roomName := c.Query("name")
 
 	if roomName == "" {
@@ -252,7 +258,7 @@ func (vc *VoiceController) GetActiveRooms(c *gin.Context) {
 			room.Mutex.RUnlock()
 
 			responseList = append(responseList, RoomResponse{
-				VoiceRoom: *room,
+				VoiceRoom: room,
 				UserCount: count,
 			})
 		}
@@ -271,7 +277,7 @@ func (vc *VoiceController) GetActiveRooms(c *gin.Context) {
 //	@Description	Establishes a WebSocket connection for voice communication in a room
 //	@Security		Bearer
 //	@Param			roomId	path		string	true	"Room ID to join"
-//	@Param			userId	query		string	true	"User ID joining the room"
+//	@Param			userId	query		string	false	"User ID joining the room, must match the authenticated user"
 //	@Success		101		{string}	string	"Switching Protocols"
 //	@Failure		400		{object}	map[string]string
 //	@Failure		403		{object}	map[string]string
@@ -279,7 +285,10 @@ func (vc *VoiceController) GetActiveRooms(c *gin.Context) {
 //	@Router			/voice/join/{roomId} [get]
 func (vc *VoiceController) JoinVoiceRoom(c *gin.Context) {
 	roomId := c.Param("roomId")
-	userId := c.Query("userId")
+	userId, ok := actingUserID(c, c.Query("userId"))
+	if !ok {
+		return
+	}
 
 	log.Printf("[voice] JoinVoiceRoom: request roomId=%s userId=%s", roomId, userId)
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
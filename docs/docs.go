@@ -94,7 +94,2073 @@ const docTemplate = `{
                 }
             }
         },
+        "/conversations/{userId}/files": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the files shared in a direct conversation (metadata only, paginated)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The other user of the conversation",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 10, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FileListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "The file is shared between the authenticated user and userId, who are the only ones that can download it. Send its id in attachmentIds of a direct message to attach it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Upload a file to a direct conversation (base64 content)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The other user of the conversation",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "File upload request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.FileUploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FileUploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/conversations/{userId}/files/{fileId}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a file shared in a direct conversation (with content)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The other user of the conversation",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "fileId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.File"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            },
+            "delete": {
+                "summary": "Delete a file shared in a direct conversation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The other user of the conversation",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "fileId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not the uploader",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
         "/events": {
+            "get": {
+                "description": "Only members of the team may list its events.",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get events by team id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Team ID",
+                        "name": "teamId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.EventDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not a member of the team",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The initiator is the authenticated user; a different initiatorId is rejected. Only members of the team may create events in it.",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create new event",
+                "parameters": [
+                    {
+                        "description": "Create event request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateEventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{id}": {
+            "get": {
+                "description": "Only members of the event's team may read it.",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get event by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventDTO"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a member of the team",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete event by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event deleted successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update event name, description, start time and/or duration. Only the initiator or an admin of the team may update it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update event details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update event request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateEventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{id}/status": {
+            "patch": {
+                "description": "Sets the authenticated user's status; a different userId is rejected. Only members of the event's team may answer it.",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update user status for event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update event status request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateEventStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/friend-requests/{fromUserId}/{toUserId}": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Accept or deny a friend request. The recipient must be the authenticated user.",
+                "summary": "Respond to a friend request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sender User ID",
+                        "name": "fromUserId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recipient User ID",
+                        "name": "toUserId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Accept or deny",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RespondFriendRequestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Send a friend request from one user to another. The sender must be the authenticated user.",
+                "summary": "Send a friend request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sender User ID",
+                        "name": "fromUserId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recipient User ID",
+                        "name": "toUserId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/friend-requests/{userId}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get pending friend requests for the authenticated user",
+                "summary": "Get pending friend requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FriendRequestListResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/inviteLinks/{token}/join": {
+            "post": {
+                "description": "Adds the authenticated user to the team of the invite link and counts one use of it.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Join a team through an invite link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invite link token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AddUserToTeamResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Link expired or used up, or already a member",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Invite link not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/messages": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get a page of messages between 2 users or within a team, oldest first. For direct messages the authenticated user must be one of the 2 users; for team messages a member of the team.\nWithout cursors the newest messages are returned. Pass nextCursor as before to page back in history, or a cursor as after to page forward.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Messages type (direct/team)",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User1 ID (direct message)",
+                        "name": "user1Id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User2 ID (direct message)",
+                        "name": "user2Id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Team ID (team message)",
+                        "name": "teamId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return messages older than this cursor",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return messages newer than this cursor",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessagePageDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create and send a message either to another user or to a team. The sender is the authenticated user; a different senderId is rejected.\nSet replyToId to quote a message of the same conversation or team, and parentId to post into the thread under one. Thread replies are listed by GET /messages/{id}/replies, and the parent is sent again as a message_edited event with its new replyCount.\nSet attachmentIds to attach up to 10 files uploaded to the same conversation (/conversations/{userId}/files) or team (/teams/{id}/files).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create and send a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message type (direct/team)",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "The message request (this is only for documentation purposes, the actual request should be either DirectMessageRequest or TeamMessageRequest)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.MessageRequestUnion"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Quoted or parent message, or attached file not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Quoted or parent message deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/connect": {
+            "get": {
+                "description": "Besides receiving events, clients can send frames of the form {\"requestId\", \"type\", \"payload\"} with type send_direct_message, send_team_message, typing, read, react or presence. Every frame is answered with an ack or error frame carrying its requestId.\nEvents carry a per-user seq. Reconnecting with since set to the last seq received first replays every event after it. A connection closed with code 4000 should reconnect with since; code 4001 means the missed events expired, so the client reloads over HTTP and reconnects without since.",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "summary": "Connect the user to the message WebSocket",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Seq of the last event received; the events after it are replayed",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols - WebSocket connection established",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/unread": {
+            "get": {
+                "description": "Count the messages from others after the user's read cursor, for each direct conversation and each team of the user.\nCounting stops at 99; hasMore is set when there are more.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get unread message counts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UnreadCountsDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/messages/{id}": {
+            "get": {
+                "description": "Direct messages can be read by their 2 users, team messages by the members of the team.",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a message by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageDTO"
+                        }
+                    },
+                    "403": {
+                        "description": "Not part of the conversation or team",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a message, leaving a tombstone in its place. Allowed for the sender, and for team admins on team messages. Connected clients receive a message_deleted event.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The tombstone",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageDTO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Message already deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Replace the text of a message. Allowed for the sender, and for team admins on team messages. The previous text is kept in the edit history and connected clients receive a message_edited event.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Edit a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The new text",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.EditMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Message already deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/messages/{id}/reactions": {
+            "post": {
+                "description": "Add the user's reaction with the emoji, or remove it when the user already reacted with it. Allowed for everyone who can see the message.\nConnected clients receive the updated message as a message_edited event.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Toggle a reaction on a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The emoji",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Message deleted, or too many different reactions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/messages/{id}/read": {
+            "post": {
+                "description": "Move the user's read cursor of the message's conversation or team up to the message. Cursors only move forward.\nWhen the cursor moves, the other participants receive a read event with the receipt.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Mark a message as read",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReadReceiptDTO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/messages/{id}/replies": {
+            "get": {
+                "description": "Get a page of the replies posted into the thread under a message, oldest first. Paging works as for GET /messages.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the thread replies of a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The parent message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return replies older than this cursor",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return replies newer than this cursor",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessagePageDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/notifications": {
+            "get": {
+                "description": "Notifications of the authenticated user, newest first, with the number still unread. New notifications also arrive over the message WebSocket as notification events, and reads made on another connection as notifications_read events.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the user's notifications",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only unread notifications",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationsPageDTO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/notifications/preferences": {
+            "get": {
+                "description": "Whether each notification type goes out in-app, by email and by push, the muted teams and the quiet hours. Users who never saved settings get the defaults: in-app and push on, email off.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the user's notification settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationPreferencesDTO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            },
+            "put": {
+                "description": "Channels are set by type; types and channels left out keep their default. Notifications about a muted team are not delivered at all. During quiet hours, read in the given timezone, nothing is sent to sockets, by email or by push; in-app notifications are still kept and listed. Only teams the user is a member of can be muted. Emails go to verified addresses only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Replace the user's notification settings",
+                "parameters": [
+                    {
+                        "description": "Notification settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationPreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationPreferencesDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid type, channel, quiet hours or timezone",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not a member of a muted team",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Muted team not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/notifications/read": {
+            "post": {
+                "description": "Marks every unread notification of the authenticated user as read and returns how many were marked",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Mark every notification as read",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationsReadDTO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/notifications/unread": {
+            "get": {
+                "description": "Number of unread notifications of the authenticated user, in total and by type",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Count unread notifications",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UnreadNotificationsDTO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/notifications/{id}/read": {
+            "post": {
+                "description": "Marks one notification of the authenticated user as read. Marking a read notification again changes nothing.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Mark a notification as read",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Notification"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Notification not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/quizzes": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create a new quiz",
+                "parameters": [
+                    {
+                        "description": "The create quiz request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Quiz"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateQuizResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/quizzes/team/{teamId}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get quizzes by team with pagination",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Team ID",
+                        "name": "teamId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 10)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last key for pagination",
+                        "name": "lastKey",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/quizzes/user/{userId}/team/{teamId}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get quizzes by user with pagination",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Team ID",
+                        "name": "teamId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 10)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last key for pagination",
+                        "name": "lastKey",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/quizzes/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a quiz with answers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The id for quiz",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Quiz"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/quizzes/{id}/test": {
             "get": {
                 "security": [
                     {
@@ -107,13 +2173,13 @@ const docTemplate = `{
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get events by team id",
+                "summary": "Get a quiz without answers",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Team ID",
-                        "name": "teamId",
-                        "in": "query",
+                        "description": "The id for quiz",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -121,24 +2187,25 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.EventDTO"
-                            }
+                            "$ref": "#/definitions/dto.ReadQuizResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
@@ -155,23 +2222,107 @@ const docTemplate = `{
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create new event",
+                "summary": "Solve a quiz",
                 "parameters": [
                     {
-                        "description": "Create event request",
+                        "description": "The solve quiz request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CreateEventRequest"
+                            "$ref": "#/definitions/dto.SolveQuizRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The id for quiz",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.EventDTO"
+                            "$ref": "#/definitions/dto.SolveQuizResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "description": "Case-insensitive search of message text, file names, quiz names and questions, event names and descriptions, and team names and descriptions. Every word of q must match a whole word or the start of one.\nResults are limited to what the user can see: the records of their teams and direct conversations, and every team. Best matches come first, newest first among equal scores.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Search messages, files, quizzes, events and teams",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Words to search for",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated kinds to search: message, file, quiz, event, team (default all)",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only search the records of this team",
+                        "name": "teamId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SearchResponse"
                         }
                     },
                     "400": {
@@ -181,6 +2332,20 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -188,27 +2353,56 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
             }
         },
-        "/events/{id}": {
+        "/teamInvitations": {
             "get": {
+                "description": "Fetches the invitations to join a team that wait for the authenticated user to accept or decline them.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the user's team invitations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamRequestsResponseDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
                 "security": [
                     {
                         "Bearer": []
                     }
-                ],
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/teamInvitations/{id}/accept": {
+            "put": {
+                "description": "Adds the authenticated user to the team they were invited to. The invitation is kept with status accepted.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get event by id",
+                "summary": "Accept a team invitation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Event ID",
+                        "description": "Invitation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -218,223 +2412,285 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.EventDTO"
+                            "$ref": "#/definitions/dto.AddUserToTeamResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "409": {
-                        "description": "this status option is already set",
+                    "403": {
+                        "description": "Not the invited user",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Invitation not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/teamInvitations/{id}/decline": {
+            "delete": {
+                "description": "Declines an invitation of the authenticated user without joining the team. The invitation is kept with status rejected.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Decline a team invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invitation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Invitation declined",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not the invited user",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Invitation not found",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "Bearer": []
                     }
-                ],
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/teamRequests": {
+            "get": {
+                "description": "Fetches the team requests the caller may see, newest first and answered ones included: their own join requests and invitations, and the requests of the teams where they are an admin or the owner.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Delete event by id",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Event ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "Get all team requests",
                 "responses": {
                     "200": {
-                        "description": "Event deleted successfully",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamRequestsResponseDTO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Update event name, description, start time and/or duration",
+                "description": "Creates a join request for the authenticated user to join a team, with an optional message of up to 500 characters for the team admins. Requests expire after 30 days without an answer.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Update event details",
+                "summary": "Create a new team request",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Event ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Update event request",
+                        "description": "Team and User IDs",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdateEventRequest"
+                            "$ref": "#/definitions/dto.TeamRequestCreateDTO"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.EventDTO"
+                            "$ref": "#/definitions/dto.TeamRequestItemDTO"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid request or validation error",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/events/{id}/status": {
-            "patch": {
+        "/teamRequests/user/{userId}": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Fetches all team requests and invitations of a given user, answered ones included. Users can only list their own requests.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Update user status for event",
+                "summary": "Get all team requests for a specific user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Event ID",
-                        "name": "id",
+                        "description": "User ID",
+                        "name": "userId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Update event status request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.UpdateEventStatusRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.EventDTO"
+                            "$ref": "#/definitions/dto.TeamRequestsResponseDTO"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/friend-requests/{fromUserId}/{toUserId}": {
+        "/teamRequests/{id}/accept": {
             "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Accept or deny a friend request",
-                "summary": "Respond to a friend request",
+                "description": "Accepts a pending team request and adds the user to the team. The request is kept with status accepted. Requires the admin or owner role in the team. Invitations cannot be accepted here, only by the invited user.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Accept a team request",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Sender User ID",
-                        "name": "fromUserId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Recipient User ID",
-                        "name": "toUserId",
+                        "description": "Team Request ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Accept or deny",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.RespondFriendRequestRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AddUserToTeamResponse"
+                        }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Bad Request or Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -442,8 +2698,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -461,37 +2717,41 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/teamRequests/{id}/reject": {
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Send a friend request from one user to another",
-                "summary": "Send a friend request",
+                "description": "Rejects a pending team request, or takes back an invitation, which then has status withdrawn. Requires the admin or owner role in the team.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Reject a team request",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Sender User ID",
-                        "name": "fromUserId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Recipient User ID",
-                        "name": "toUserId",
+                        "description": "Team Request ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created"
+                    "200": {
+                        "description": "Team request rejected",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Bad Request or Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -499,8 +2759,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -511,20 +2771,18 @@ const docTemplate = `{
                 }
             }
         },
-        "/friend-requests/{userId}": {
-            "get": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
+        "/teamRequests/{id}/withdraw": {
+            "put": {
+                "description": "Takes back a pending join request of the authenticated user. The request is kept with status withdrawn.",
+                "produces": [
+                    "application/json"
                 ],
-                "description": "Get pending friend requests for a user",
-                "summary": "Get pending friend requests",
+                "summary": "Withdraw a team request",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
-                        "name": "userId",
+                        "description": "Team Request ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -533,11 +2791,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.FriendRequestListResponse"
+                            "$ref": "#/definitions/dto.TeamRequestItemDTO"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Not found or no longer pending",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not the requester",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -545,48 +2812,43 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
             }
         },
-        "/messages": {
+        "/teams": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Get messages between 2 users or within a team",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get teams - all teams, by name, or by prefix with limit. Private teams are only listed for their members.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get all messages",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Messages type (direct/team)",
-                        "name": "type",
-                        "in": "query",
-                        "required": true
-                    },
+                "summary": "Get teams with optional filtering",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "User1 ID (direct message)",
-                        "name": "user1Id",
+                        "description": "Filter by exact name",
+                        "name": "name",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "User2 ID (direct message)",
-                        "name": "user2Id",
+                        "description": "Filter by name prefix",
+                        "name": "prefix",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "Team ID (team message)",
-                        "name": "teamId",
+                        "type": "integer",
+                        "description": "Limit results (required with prefix)",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
@@ -596,7 +2858,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/dto.MessageDTO"
+                                "$ref": "#/definitions/entity.Team"
                             }
                         }
                     },
@@ -622,29 +2884,22 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "Create and send a message either to another user or to a team",
+                "description": "Create a new team with the provided details. The authenticated user becomes its owner.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create and send a message",
+                "summary": "Create a new team",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Message type (direct/team)",
-                        "name": "type",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "description": "The message request (this is only for documentation purposes, the actual request should be either DirectMessageRequest or TeamMessageRequest)",
+                        "description": "Team details",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.MessageRequestUnion"
+                            "$ref": "#/definitions/dto.TeamRequest"
                         }
                     }
                 ],
@@ -652,7 +2907,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.MessageDTO"
+                            "$ref": "#/definitions/entity.Team"
                         }
                     },
                     "400": {
@@ -662,6 +2917,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "Email not verified",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -672,35 +2934,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/messages/connect": {
+        "/teams/recommended": {
             "get": {
-                "security": [
+                "description": "Public teams the user is not in and has no pending request for, best first. Teams rank higher when their topic is one of the user's topics of interest, when the user's friends are members, when messages were sent in them in the last two weeks, and the more members they have.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get recommended teams",
+                "parameters": [
                     {
-                        "Bearer": []
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 10, max 50)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
-                "summary": "Connect the user to the message WebSocket",
                 "responses": {
-                    "101": {
-                        "description": "Switching Protocols - WebSocket connection established",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.TeamRecommendationsDTO"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "User not found",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
                     "500": {
@@ -710,90 +2983,110 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
             }
         },
-        "/messages/{id}": {
-            "get": {
+        "/teams/users": {
+            "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
+                "description": "Adds a user to a team by providing user ID and team ID. Users may add themselves to public teams; private teams are joined through a join request or an invitation. Adding someone else requires the admin or owner role.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get a message by ID",
+                "summary": "Add a user to a team",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "The message ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "User ID and Team ID",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserToTeamRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.MessageDTO"
+                            "$ref": "#/definitions/dto.AddUserToTeamResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Invalid request body or error",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/quizzes": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
+                "description": "Deletes a user from a team by providing team ID. Members may remove themselves; removing others requires the admin role, and removing an admin requires the owner.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create a new quiz",
+                "summary": "Delete a user from a team",
                 "parameters": [
                     {
-                        "description": "The create quiz request",
+                        "description": "User ID and Team ID",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Quiz"
+                            "$ref": "#/definitions/dto.UserToTeamRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "User removed from team",
                         "schema": {
-                            "$ref": "#/definitions/dto.CreateQuizResponse"
+                            "$ref": "#/definitions/dto.AddUserToTeamResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid request body or error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -809,140 +3102,204 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    }
+                }
+            }
+        },
+        "/teams/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get team details by ID. Private teams are only found by their members.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a team by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Team"
+                        }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Team not found",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     }
                 }
-            }
-        },
-        "/quizzes/team/{teamId}": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
+                "description": "Update team details by providing team ID and the details to change; fields left out keep their value. Requires the admin or owner role.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get quizzes by team with pagination",
+                "summary": "Update a team",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "Team ID",
-                        "name": "teamId",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Page size (default 10)",
-                        "name": "pageSize",
-                        "in": "query"
+                        "description": "Team details to change",
+                        "name": "team",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateTeamRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete a team by providing team ID. Only the owner can delete a team.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete a team",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Last key for pagination",
-                        "name": "lastKey",
-                        "in": "query"
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Team deleted",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Bad Request: Missing team ID",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Team not found",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     }
                 }
             }
         },
-        "/quizzes/user/{userId}/team/{teamId}": {
+        "/teams/{id}/files": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get quizzes by user with pagination",
+                "summary": "Get all files for a team (metadata only, paginated)",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "userId",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "description": "Team ID",
-                        "name": "teamId",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Page size (default 10)",
-                        "name": "pageSize",
+                        "description": "Page number (default 1)",
+                        "name": "page",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "Last key for pagination",
-                        "name": "lastKey",
+                        "type": "integer",
+                        "description": "Items per page (default 10, max 100)",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
@@ -950,21 +3307,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.FileListResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -982,10 +3329,8 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/quizzes/{id}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "Bearer": []
@@ -997,25 +3342,43 @@ const docTemplate = `{
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get a quiz with answers",
+                "summary": "Upload a file to a team (base64 content)",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "The id for quiz",
+                        "description": "Team ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "File upload request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.FileUploadRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/entity.Quiz"
+                            "$ref": "#/definitions/dto.FileUploadResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1035,34 +3398,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/quizzes/{id}/test": {
+        "/teams/{id}/files/{fileId}": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get a quiz without answers",
+                "summary": "Get file by id (with content)",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "The id for quiz",
+                        "description": "Team ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "fileId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.ReadQuizResponse"
+                            "$ref": "#/definitions/entity.File"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "404": {
@@ -1085,33 +3461,25 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "summary": "Solve a quiz",
+                "summary": "Delete a file",
                 "parameters": [
                     {
-                        "description": "The solve quiz request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.SolveQuizRequest"
-                        }
+                        "type": "string",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "The id for quiz",
-                        "name": "id",
+                        "description": "File ID",
+                        "name": "fileId",
                         "in": "path",
                         "required": true
                     }
@@ -1120,7 +3488,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SolveQuizResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "403": {
@@ -1153,58 +3524,31 @@ const docTemplate = `{
                 }
             }
         },
-        "/teamRequests": {
-            "get": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Fetches all pending team requests in the system.",
-                "produces": [
-                    "application/json"
-                ],
-                "summary": "Get all team requests",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/dto.TeamRequestsResponseDTO"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    }
-                }
-            },
+        "/teams/{id}/invitations": {
             "post": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Creates a join request for a user to join a team.",
+                "description": "Invites a user, named by exactly one of userId, username or email, to join the team. The invitation shows up in the user's invitations until they accept or decline it. Requires the admin or owner role in the team.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create a new team request",
+                "summary": "Invite a user to a team",
                 "parameters": [
                     {
-                        "description": "Team and User IDs",
+                        "type": "string",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The user to invite",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.TeamRequestCreateDTO"
+                            "$ref": "#/definitions/dto.TeamInviteRequest"
                         }
                     }
                 ],
@@ -1216,56 +3560,16 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request or validation error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                        "description": "Invalid request, already a member or already invited",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/teamRequests/user/{userId}": {
-            "get": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Fetches all pending team requests created by a given user.",
-                "produces": [
-                    "application/json"
-                ],
-                "summary": "Get all team requests for a specific user",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "userId",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/dto.TeamRequestsResponseDTO"
-                        }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1273,8 +3577,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Team or user not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1282,25 +3586,25 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
-            }
-        },
-        "/teamRequests/{id}/accept": {
-            "put": {
+                },
                 "security": [
                     {
                         "Bearer": []
                     }
-                ],
-                "description": "Accepts a pending team request, adds the user to the team, and deletes the request.",
+                ]
+            }
+        },
+        "/teams/{id}/inviteLinks": {
+            "get": {
+                "description": "Lists the invite links of the team, newest first, including expired and used up ones. Requires the admin or owner role in the team.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Accept a team request",
+                "summary": "Get the invite links of a team",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Team Request ID",
+                        "description": "Team ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1310,11 +3614,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.AddUserToTeamResponse"
+                            "$ref": "#/definitions/dto.TeamInviteLinksResponseDTO"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request or Not Found",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1331,33 +3644,48 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
-            }
-        },
-        "/teamRequests/{id}/reject": {
-            "delete": {
+                },
                 "security": [
                     {
                         "Bearer": []
                     }
+                ]
+            },
+            "post": {
+                "description": "Creates a link anyone can join the team through until it expires or reaches maxUses. Links expire after a week by default and at most after 30 days; maxUses 0 means no limit. Requires the admin or owner role in the team.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Rejects and deletes a pending team request.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Reject a team request",
+                "summary": "Create a team invite link",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Team Request ID",
+                        "description": "Team ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Expiry and usage limit",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamInviteLinkRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Team request rejected",
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamInviteLinkDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1365,8 +3693,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request or Not Found",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1374,95 +3711,111 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
-            }
-        },
-        "/teams": {
-            "get": {
+                },
                 "security": [
                     {
                         "Bearer": []
                     }
-                ],
-                "description": "Get teams - all teams, by name, or by prefix with limit",
+                ]
+            }
+        },
+        "/teams/{id}/inviteLinks/{token}": {
+            "delete": {
+                "description": "Deletes an invite link so nobody can join through it anymore. Requires the admin or owner role in the team.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get teams with optional filtering",
+                "summary": "Revoke a team invite link",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter by exact name",
-                        "name": "name",
-                        "in": "query"
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Filter by name prefix",
-                        "name": "prefix",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit results (required with prefix)",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Invite link token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Invite link revoked",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entity.Team"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Team or invite link not found",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "Bearer": []
                     }
-                ],
-                "description": "Create a new team with the provided details",
+                ]
+            }
+        },
+        "/teams/{id}/members/{userId}/role": {
+            "put": {
+                "description": "Promotes a member to admin or demotes an admin to member. Only the owner can change roles.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create a new team",
+                "summary": "Change the role of a team member",
                 "parameters": [
                     {
-                        "description": "Team details",
+                        "type": "string",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The new role (admin or member)",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.TeamRequest"
+                            "$ref": "#/definitions/dto.UpdateMemberRoleRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/entity.Team"
                         }
@@ -1474,39 +3827,53 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/teams/users": {
-            "put": {
+                },
                 "security": [
                     {
                         "Bearer": []
                     }
-                ],
-                "description": "Adds a user to a team by providing user ID and team ID",
+                ]
+            }
+        },
+        "/teams/{id}/owner": {
+            "put": {
+                "description": "Makes another member the owner of the team. The previous owner becomes an admin.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Add a user to a team",
+                "summary": "Transfer team ownership",
                 "parameters": [
                     {
-                        "description": "User ID and Team ID",
+                        "type": "string",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The new owner",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UserToTeamRequest"
+                            "$ref": "#/definitions/dto.TransferOwnershipRequest"
                         }
                     }
                 ],
@@ -1514,76 +3881,45 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.AddUserToTeamResponse"
+                            "$ref": "#/definitions/entity.Team"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body or error",
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    }
-                }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Deletes a user from a team by providing team ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "summary": "Delete a user from a team",
-                "parameters": [
-                    {
-                        "description": "User ID and Team ID",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.UserToTeamRequest"
+                            "additionalProperties": true
                         }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "User removed from team",
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/dto.AddUserToTeamResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body or error",
+                    "404": {
+                        "description": "Team not found",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/teams/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "Bearer": []
                     }
-                ],
-                "description": "Get team details by ID",
+                ]
+            }
+        },
+        "/teams/{id}/presence": {
+            "get": {
+                "description": "The presence of every member of the team, in member order. Only members may ask.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get a team by ID",
+                "summary": "Get the presence of a team's members",
                 "parameters": [
                     {
                         "type": "string",
@@ -1597,7 +3933,24 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Team"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PresenceDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "404": {
@@ -1606,23 +3959,29 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
-                }
-            },
-            "put": {
+                },
                 "security": [
                     {
                         "Bearer": []
                     }
-                ],
-                "description": "Update team details by providing team ID and updated details",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/teams/{id}/requests": {
+            "get": {
+                "description": "Lists the join requests and invitations of a team, newest first. Requires the admin or owner role in the team.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Update a team",
+                "summary": "Get the requests of a team",
                 "parameters": [
                     {
                         "type": "string",
@@ -1632,49 +3991,66 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Updated team details",
-                        "name": "team",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.Team"
-                        }
+                        "type": "string",
+                        "description": "Only requests with this status: pending, accepted, rejected, withdrawn or expired",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Team"
+                            "$ref": "#/definitions/dto.TeamRequestsResponseDTO"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid status",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
-                }
-            },
-            "delete": {
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/teams/{id}/users": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Delete a team by providing team ID",
-                "produces": [
+                "description": "Get all users that are members of a specific team. Only members of the team may list them.",
+                "consumes": [
                     "application/json"
                 ],
-                "summary": "Delete a team",
+                "summary": "Get users by team ID",
                 "parameters": [
                     {
                         "type": "string",
@@ -1686,14 +4062,30 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Team deleted",
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.UserResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid team ID",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Bad Request: Missing team ID",
+                    "403": {
+                        "description": "Not a member of the team",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1709,51 +4101,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/teams/{id}/files": {
+        "/users": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "produces": [
+                "consumes": [
                     "application/json"
                 ],
-                "summary": "Get all files for a team (metadata only, paginated)",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Team ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number (default 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per page (default 10, max 100)",
-                        "name": "limit",
-                        "in": "query"
-                    }
+                "produces": [
+                    "application/json"
                 ],
+                "summary": "Get all users",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.FileListResponse"
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.User"
                             }
                         }
                     },
@@ -1767,43 +4135,34 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/users/login": {
             "post": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
+                "description": "Accepts either ` + "`" + `email` + "`" + ` or ` + "`" + `username` + "`" + ` along with ` + "`" + `password` + "`" + `. Returns an access token and the full user (without password).",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Upload a file to a team (base64 content)",
+                "summary": "Login user by email or username and return JWT",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Team ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "File upload request",
+                        "description": "The login request (email or username + password)",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.FileUploadRequest"
+                            "$ref": "#/definitions/dto.LoginRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.FileUploadResponse"
+                            "$ref": "#/definitions/dto.LoginResponse"
                         }
                     },
                     "400": {
@@ -1815,8 +4174,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1836,42 +4195,59 @@ const docTemplate = `{
                 }
             }
         },
-        "/teams/{id}/files/{fileId}": {
-            "get": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
+        "/users/logout": {
+            "post": {
+                "description": "Revokes the session of the presented access token, together with its refresh token.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get file by id (with content)",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Team ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                "summary": "Log out of the current session",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
                     {
-                        "type": "string",
-                        "description": "File ID",
-                        "name": "fileId",
-                        "in": "path",
-                        "required": true
+                        "Bearer": []
                     }
+                ]
+            }
+        },
+        "/users/logout-all": {
+            "post": {
+                "description": "Revokes all sessions of the authenticated user on every device.",
+                "produces": [
+                    "application/json"
                 ],
+                "summary": "Log out of every session",
                 "responses": {
                     "200": {
                         "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/entity.File"
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1879,8 +4255,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1897,29 +4273,33 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "Bearer": []
                     }
+                ]
+            }
+        },
+        "/users/password/forgot": {
+            "post": {
+                "description": "Always answers the same way, whether or not an account uses the email.",
+                "consumes": [
+                    "application/json"
                 ],
-                "summary": "Delete a file",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Request a password reset link",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Team ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "File ID",
-                        "name": "fileId",
-                        "in": "path",
-                        "required": true
+                        "description": "The account email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ForgotPasswordRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -1932,17 +4312,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1962,82 +4333,101 @@ const docTemplate = `{
                 }
             }
         },
-        "/teams/{id}/users": {
-            "get": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Get all users that are members of a specific team",
+        "/users/password/reset": {
+            "post": {
+                "description": "Sets a new password and revokes every session of the user.",
                 "consumes": [
                     "application/json"
                 ],
-                "summary": "Get users by team ID",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Reset the password with a mailed token",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Team ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "The reset token and the new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ResetPasswordRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.UserResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
                     "400": {
-                        "description": "Bad Request - Invalid team ID",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "404": {
-                        "description": "Team not found",
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/users": {
-            "get": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
+        "/users/refresh": {
+            "post": {
+                "description": "The refresh token is rotated on every call; reusing an old one revokes the whole session.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get all users",
+                "summary": "Exchange a refresh token for a new token pair",
+                "parameters": [
+                    {
+                        "description": "The refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RefreshTokenRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entity.User"
+                            "$ref": "#/definitions/dto.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -2053,32 +4443,31 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/login": {
+        "/users/signup": {
             "post": {
-                "description": "Accepts either ` + "`" + `email` + "`" + ` or ` + "`" + `username` + "`" + ` along with ` + "`" + `password` + "`" + `. Returns an access token and the full user (without password).",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Login user by email or username and return JWT",
+                "summary": "Register a new user",
                 "parameters": [
                     {
-                        "description": "The login request (email or username + password)",
+                        "description": "The sign-up request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.LoginRequest"
+                            "$ref": "#/definitions/dto.SignUpUserRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.LoginResponse"
+                            "$ref": "#/definitions/dto.SignUpUserResponse"
                         }
                     },
                     "400": {
@@ -2090,8 +4479,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2111,31 +4500,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/signup": {
+        "/users/verify-email": {
             "post": {
+                "description": "Consumes the single-use token that was mailed to the user on sign-up or email change.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Register a new user",
+                "summary": "Confirm an email address",
                 "parameters": [
                     {
-                        "description": "The sign-up request",
+                        "description": "The verification token",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.SignUpUserRequest"
+                            "$ref": "#/definitions/dto.VerifyEmailRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SignUpUserResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "400": {
@@ -2147,6 +4540,44 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/verify-email/resend": {
+            "post": {
+                "description": "Sends a new verification link to the authenticated user's email.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Resend the email verification link",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "409": {
                         "description": "Conflict",
                         "schema": {
@@ -2165,7 +4596,12 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
             }
         },
         "/users/{id}": {
@@ -2241,6 +4677,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "403": {
+                        "description": "The user owns a team",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2436,6 +4881,7 @@ const docTemplate = `{
         },
         "/users/{id}/password": {
             "put": {
+                "description": "Changes the password and logs the user out of every other session.",
                 "security": [
                     {
                         "Bearer": []
@@ -2497,6 +4943,65 @@ const docTemplate = `{
                 }
             }
         },
+        "/users/{id}/presence": {
+            "get": {
+                "description": "Whether the user is online, idle or offline, and when their status last changed. Visible to the user, their friends and their teammates.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a user's presence",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PresenceDTO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
         "/users/{id}/statistics": {
             "get": {
                 "security": [
@@ -2640,10 +5145,9 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "User ID joining the room",
+                        "description": "User ID joining the room, must match the authenticated user",
                         "name": "userId",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -2701,10 +5205,9 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID of the client",
+                        "description": "User ID of the client, must match the authenticated user",
                         "name": "userId",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -2717,8 +5220,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2747,10 +5250,9 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID of the user initiating the call",
+                        "description": "ID of the user initiating the call, must match the authenticated user",
                         "name": "callerId",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     },
                     {
                         "type": "string",
@@ -2773,8 +5275,17 @@ const docTemplate = `{
                             "$ref": "#/definitions/entity.VoiceRoom"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2845,10 +5356,9 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "User ID of the creator",
+                        "description": "User ID of the creator, must match the authenticated user",
                         "name": "userId",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     },
                     {
                         "type": "string",
@@ -2864,6 +5374,15 @@ const docTemplate = `{
                             "$ref": "#/definitions/entity.VoiceRoom"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "409": {
                         "description": "Conflict",
                         "schema": {
@@ -2927,6 +5446,26 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.AttachmentDTO": {
+            "type": "object",
+            "properties": {
+                "extension": {
+                    "type": "string"
+                },
+                "fileId": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
         "dto.CreateEventRequest": {
             "type": "object",
             "properties": {
@@ -2937,6 +5476,7 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "initiatorId": {
+                    "description": "Optional, filled from the token",
                     "type": "string"
                 },
                 "name": {
@@ -2961,10 +5501,23 @@ const docTemplate = `{
         "dto.DirectMessageRequest": {
             "type": "object",
             "properties": {
+                "attachmentIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "parentId": {
+                    "type": "string"
+                },
                 "receiverId": {
                     "type": "string"
                 },
+                "replyToId": {
+                    "type": "string"
+                },
                 "senderId": {
+                    "description": "Optional, filled from the token",
                     "type": "string"
                 },
                 "textContent": {
@@ -2972,6 +5525,14 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.EditMessageRequest": {
+            "type": "object",
+            "properties": {
+                "textContent": {
+                    "type": "string"
+                }
+            }
+        },
         "dto.EventDTO": {
             "type": "object",
             "properties": {
@@ -3036,7 +5597,6 @@ const docTemplate = `{
                 "content",
                 "extension",
                 "name",
-                "ownerId",
                 "size",
                 "type"
             ],
@@ -3059,129 +5619,379 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "ownerId": {
+                    "description": "Optional, set from the token",
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FileUploadResponse": {
+            "type": "object",
+            "properties": {
+                "contextId": {
+                    "type": "string"
+                },
+                "contextType": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "integer"
+                },
+                "extension": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ownerId": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FriendRequestListResponse": {
+            "type": "object",
+            "properties": {
+                "requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FriendRequestResponse"
+                    }
+                }
+            }
+        },
+        "dto.FriendRequestResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "fromUserId": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "toUserId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "expiresIn": {
+                    "type": "string"
+                },
+                "refreshExpiresIn": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                },
+                "tokenType": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/dto.UserResponse"
+                }
+            }
+        },
+        "dto.MessageDTO": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AttachmentDTO"
+                    }
+                },
+                "deleted": {
+                    "type": "boolean"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "editHistory": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.MessageEditDTO"
+                    }
+                },
+                "editedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastReplyAt": {
+                    "type": "string"
+                },
+                "parentId": {
+                    "type": "string"
+                },
+                "reactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ReactionDTO"
+                    }
+                },
+                "receiverId": {
+                    "type": "string"
+                },
+                "replyCount": {
+                    "type": "integer"
+                },
+                "replyTo": {
+                    "$ref": "#/definitions/dto.MessageQuoteDTO"
+                },
+                "sender": {
+                    "$ref": "#/definitions/dto.SenderDTO"
+                },
+                "sentAt": {
+                    "type": "string"
+                },
+                "teamId": {
+                    "type": "string"
+                },
+                "textContent": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MessageEditDTO": {
+            "type": "object",
+            "properties": {
+                "editedAt": {
+                    "type": "string"
+                },
+                "editedBy": {
+                    "type": "string"
+                },
+                "textContent": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MessagePageDTO": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.MessageDTO"
+                    }
+                },
+                "nextCursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MessageQuoteDTO": {
+            "type": "object",
+            "properties": {
+                "messageId": {
+                    "type": "string"
+                },
+                "preview": {
+                    "type": "string"
+                },
+                "senderId": {
                     "type": "string"
+                }
+            }
+        },
+        "dto.NotificationPreferencesDTO": {
+            "type": "object",
+            "properties": {
+                "channels": {
+                    "description": "Channels has every type and channel, defaults included",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "object",
+                        "additionalProperties": {
+                            "type": "boolean"
+                        }
+                    }
+                },
+                "inQuietHours": {
+                    "description": "InQuietHours is whether the user is in quiet hours right now, so nothing is sent to their sockets, mailed or pushed.\nWhat is suppressed then is not sent later; in-app notifications are still kept.",
+                    "type": "boolean"
+                },
+                "mutedTeamIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "size": {
-                    "type": "integer"
+                "quietHours": {
+                    "$ref": "#/definitions/entity.QuietHours"
                 },
-                "type": {
+                "timezone": {
                     "type": "string"
                 }
             }
         },
-        "dto.FileUploadResponse": {
+        "dto.NotificationPreferencesRequest": {
             "type": "object",
             "properties": {
-                "contextId": {
-                    "type": "string"
-                },
-                "contextType": {
-                    "type": "string"
+                "channels": {
+                    "description": "Channels turns channels on or off by type, e.g. {\"mention\": {\"email\": true}}; the rest keep their default",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "object",
+                        "additionalProperties": {
+                            "type": "boolean"
+                        }
+                    }
                 },
-                "createdAt": {
-                    "type": "integer"
+                "mutedTeamIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "extension": {
-                    "type": "string"
+                "quietHours": {
+                    "$ref": "#/definitions/entity.QuietHours"
                 },
-                "id": {
+                "timezone": {
+                    "description": "Timezone is an IANA name such as \"Europe/Bucharest\"; empty means UTC",
                     "type": "string"
+                }
+            }
+        },
+        "dto.NotificationsPageDTO": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
                 },
-                "name": {
-                    "type": "string"
+                "notifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Notification"
+                    }
                 },
-                "ownerId": {
-                    "type": "string"
+                "page": {
+                    "type": "integer"
                 },
-                "size": {
+                "totalCount": {
                     "type": "integer"
                 },
-                "type": {
-                    "type": "string"
+                "totalPages": {
+                    "type": "integer"
                 },
-                "updatedAt": {
+                "unreadCount": {
                     "type": "integer"
                 }
             }
         },
-        "dto.FriendRequestListResponse": {
+        "dto.NotificationsReadDTO": {
             "type": "object",
             "properties": {
-                "requests": {
+                "all": {
+                    "type": "boolean"
+                },
+                "ids": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.FriendRequestResponse"
+                        "type": "string"
                     }
-                }
-            }
-        },
-        "dto.FriendRequestResponse": {
-            "type": "object",
-            "properties": {
-                "createdAt": {
-                    "type": "string"
-                },
-                "fromUserId": {
-                    "type": "string"
                 },
-                "status": {
-                    "type": "string"
+                "marked": {
+                    "type": "integer"
                 },
-                "toUserId": {
-                    "type": "string"
+                "unreadCount": {
+                    "type": "integer"
                 }
             }
         },
-        "dto.LoginRequest": {
+        "dto.PresenceDTO": {
             "type": "object",
             "properties": {
-                "email": {
+                "lastSeenAt": {
+                    "description": "LastSeenAt is empty for users that never connected",
                     "type": "string"
                 },
-                "password": {
-                    "type": "string"
+                "status": {
+                    "$ref": "#/definitions/entity.PresenceStatus"
                 },
-                "username": {
+                "userId": {
                     "type": "string"
                 }
             }
         },
-        "dto.LoginResponse": {
+        "dto.ReactionDTO": {
             "type": "object",
             "properties": {
-                "accessToken": {
-                    "type": "string"
-                },
-                "expiresIn": {
-                    "type": "string"
+                "count": {
+                    "type": "integer"
                 },
-                "tokenType": {
+                "emoji": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/dto.UserResponse"
+                "userIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "dto.MessageDTO": {
+        "dto.ReactionRequest": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "string"
-                },
-                "receiverId": {
+                "emoji": {
                     "type": "string"
                 },
-                "sender": {
-                    "$ref": "#/definitions/dto.SenderDTO"
-                },
-                "sentAt": {
-                    "type": "string"
-                },
-                "teamId": {
-                    "type": "string"
-                },
-                "textContent": {
+                "messageId": {
                     "type": "string"
                 }
             }
@@ -3220,6 +6030,49 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.ReadReceiptDTO": {
+            "type": "object",
+            "properties": {
+                "messageId": {
+                    "type": "string"
+                },
+                "readAt": {
+                    "type": "string"
+                },
+                "teamId": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refreshToken"
+            ],
+            "properties": {
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "newPassword",
+                "token"
+            ],
+            "properties": {
+                "newPassword": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
         "dto.RespondFriendRequestRequest": {
             "type": "object",
             "properties": {
@@ -3228,6 +6081,62 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.SearchResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SearchResultDTO"
+                    }
+                },
+                "totalCount": {
+                    "type": "integer"
+                },
+                "totalPages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SearchResultDTO": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "snippet": {
+                    "type": "string"
+                },
+                "teamId": {
+                    "description": "TeamID is set for team records, UserIDs for the two users of a direct conversation",
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/search.Kind"
+                },
+                "userIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "dto.SenderDTO": {
             "type": "object",
             "properties": {
@@ -3320,65 +6229,197 @@ const docTemplate = `{
                 "is_correct": {
                     "type": "boolean"
                 },
-                "quiz_question_id": {
-                    "type": "string"
+                "quiz_question_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SolveQuizRequest": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SolveQuestionRequest"
+                    }
+                }
+            }
+        },
+        "dto.SolveQuizResponse": {
+            "type": "object",
+            "properties": {
+                "is_correct": {
+                    "type": "boolean"
+                },
+                "questions_answers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SolveQuestionResponse"
+                    }
+                }
+            }
+        },
+        "dto.StatisticsResponse": {
+            "type": "object",
+            "properties": {
+                "timeSpentOnTeams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TimeSpentOnTeam"
+                    }
+                },
+                "totalTimeSpentOnApp": {
+                    "type": "integer",
+                    "example": 7200000
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TeamInviteLinkDTO": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "maxUses": {
+                    "type": "integer"
+                },
+                "teamId": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "uses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.TeamInviteLinkRequest": {
+            "type": "object",
+            "properties": {
+                "expiresInHours": {
+                    "description": "ExpiresInHours defaults to 168 (one week)",
+                    "type": "integer"
+                },
+                "maxUses": {
+                    "description": "MaxUses of 0 means no limit",
+                    "type": "integer"
                 }
             }
         },
-        "dto.SolveQuizRequest": {
+        "dto.TeamInviteLinksResponseDTO": {
             "type": "object",
             "properties": {
-                "attempts": {
+                "links": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.SolveQuestionRequest"
+                        "$ref": "#/definitions/dto.TeamInviteLinkDTO"
                     }
                 }
             }
         },
-        "dto.SolveQuizResponse": {
+        "dto.TeamInviteRequest": {
             "type": "object",
             "properties": {
-                "is_correct": {
-                    "type": "boolean"
+                "email": {
+                    "type": "string"
                 },
-                "questions_answers": {
+                "userId": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TeamMessageRequest": {
+            "type": "object",
+            "properties": {
+                "attachmentIds": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.SolveQuestionResponse"
+                        "type": "string"
                     }
+                },
+                "parentId": {
+                    "type": "string"
+                },
+                "replyToId": {
+                    "type": "string"
+                },
+                "senderId": {
+                    "description": "Optional, filled from the token",
+                    "type": "string"
+                },
+                "teamId": {
+                    "type": "string"
+                },
+                "textContent": {
+                    "type": "string"
                 }
             }
         },
-        "dto.StatisticsResponse": {
+        "dto.TeamRecommendationDTO": {
             "type": "object",
             "properties": {
-                "timeSpentOnTeams": {
+                "friendIds": {
+                    "description": "FriendIDs are the user's friends who are members",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.TimeSpentOnTeam"
+                        "type": "string"
                     }
                 },
-                "totalTimeSpentOnApp": {
-                    "type": "integer",
-                    "example": 7200000
+                "matchesTopic": {
+                    "description": "MatchesTopic is set when the team's topic is one of the user's topics of interest",
+                    "type": "boolean"
                 },
-                "userId": {
-                    "type": "string"
+                "memberCount": {
+                    "type": "integer"
+                },
+                "recentMessages": {
+                    "description": "RecentMessages counts the team messages of the last two weeks, up to 50",
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "team": {
+                    "$ref": "#/definitions/entity.Team"
                 }
             }
         },
-        "dto.TeamMessageRequest": {
+        "dto.TeamRecommendationsDTO": {
             "type": "object",
             "properties": {
-                "senderId": {
-                    "type": "string"
+                "limit": {
+                    "type": "integer"
                 },
-                "teamId": {
-                    "type": "string"
+                "page": {
+                    "type": "integer"
                 },
-                "textContent": {
-                    "type": "string"
+                "teams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TeamRecommendationDTO"
+                    }
+                },
+                "totalCount": {
+                    "type": "integer"
+                },
+                "totalPages": {
+                    "type": "integer"
                 }
             }
         },
@@ -3405,14 +6446,17 @@ const docTemplate = `{
         "dto.TeamRequestCreateDTO": {
             "type": "object",
             "required": [
-                "teamId",
-                "userId"
+                "teamId"
             ],
             "properties": {
+                "message": {
+                    "type": "string"
+                },
                 "teamId": {
                     "type": "string"
                 },
                 "userId": {
+                    "description": "Optional, filled from the token",
                     "type": "string"
                 }
             }
@@ -3420,12 +6464,37 @@ const docTemplate = `{
         "dto.TeamRequestItemDTO": {
             "type": "object",
             "properties": {
+                "createdAt": {
+                    "description": "CreatedAt and UpdatedAt are left out for requests stored before they existed",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
+                "invitedBy": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "reviewerId": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/entity.TeamRequestStatus"
+                },
                 "teamId": {
                     "type": "string"
                 },
+                "type": {
+                    "$ref": "#/definitions/entity.TeamRequestType"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
                 "userId": {
                     "type": "string"
                 }
@@ -3442,6 +6511,68 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.TransferOwnershipRequest": {
+            "type": "object",
+            "required": [
+                "newOwnerId"
+            ],
+            "properties": {
+                "newOwnerId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UnreadCountDTO": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "hasMore": {
+                    "type": "boolean"
+                },
+                "lastReadMessageId": {
+                    "type": "string"
+                },
+                "teamId": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UnreadCountsDTO": {
+            "type": "object",
+            "properties": {
+                "conversations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.UnreadCountDTO"
+                    }
+                },
+                "teams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.UnreadCountDTO"
+                    }
+                }
+            }
+        },
+        "dto.UnreadNotificationsDTO": {
+            "type": "object",
+            "properties": {
+                "byType": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
         "dto.UpdateEventRequest": {
             "type": "object",
             "properties": {
@@ -3466,10 +6597,22 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "userId": {
+                    "description": "Optional, filled from the token",
                     "type": "string"
                 }
             }
         },
+        "dto.UpdateMemberRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "$ref": "#/definitions/entity.TeamRole"
+                }
+            }
+        },
         "dto.UpdateStatisticsRequest": {
             "type": "object",
             "properties": {
@@ -3487,6 +6630,23 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.UpdateTeamRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "ispublic": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "teamtopic": {
+                    "$ref": "#/definitions/model.TopicOfInterest"
+                }
+            }
+        },
         "dto.UserPasswordRequestDTO": {
             "type": "object",
             "properties": {
@@ -3507,6 +6667,9 @@ const docTemplate = `{
                 "email": {
                     "type": "string"
                 },
+                "emailVerified": {
+                    "type": "boolean"
+                },
                 "firstname": {
                     "type": "string"
                 },
@@ -3609,6 +6772,17 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.VerifyEmailRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
         "entity.File": {
             "type": "object",
             "properties": {
@@ -3649,6 +6823,79 @@ const docTemplate = `{
                 }
             }
         },
+        "entity.Notification": {
+            "type": "object",
+            "properties": {
+                "actorId": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "readAt": {
+                    "description": "ReadAt is nil while the notification is unread",
+                    "type": "string"
+                },
+                "resourceId": {
+                    "type": "string"
+                },
+                "teamId": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/entity.NotificationType"
+                },
+                "unreadBy": {
+                    "description": "UnreadBy is the UserID while the notification is unread, so stores that cannot count can query only unread ones",
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.NotificationType": {
+            "type": "string",
+            "enum": [
+                "friend_request",
+                "friend_request_accepted",
+                "team_request",
+                "team_request_accepted",
+                "team_invite",
+                "event_invite",
+                "event_rescheduled",
+                "mention"
+            ],
+            "x-enum-varnames": [
+                "NotificationFriendRequest",
+                "NotificationFriendRequestAccepted",
+                "NotificationTeamRequest",
+                "NotificationTeamRequestAccepted",
+                "NotificationTeamInvite",
+                "NotificationEventInvite",
+                "NotificationEventRescheduled",
+                "NotificationMention"
+            ]
+        },
+        "entity.PresenceStatus": {
+            "type": "string",
+            "enum": [
+                "online",
+                "idle",
+                "offline"
+            ],
+            "x-enum-varnames": [
+                "PresenceOnline",
+                "PresenceIdle",
+                "PresenceOffline"
+            ]
+        },
         "entity.Question": {
             "type": "object",
             "properties": {
@@ -3675,6 +6922,17 @@ const docTemplate = `{
                 }
             }
         },
+        "entity.QuietHours": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
         "entity.Quiz": {
             "type": "object",
             "properties": {
@@ -3716,6 +6974,12 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "roles": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/entity.TeamRole"
+                    }
+                },
                 "teamtopic": {
                     "$ref": "#/definitions/model.TopicOfInterest"
                 },
@@ -3727,12 +6991,60 @@ const docTemplate = `{
                 }
             }
         },
+        "entity.TeamRequestStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "accepted",
+                "rejected",
+                "withdrawn",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "TeamRequestPending",
+                "TeamRequestAccepted",
+                "TeamRequestRejected",
+                "TeamRequestWithdrawn",
+                "TeamRequestExpired"
+            ]
+        },
+        "entity.TeamRequestType": {
+            "type": "string",
+            "enum": [
+                "join",
+                "invite"
+            ],
+            "x-enum-varnames": [
+                "TeamRequestJoin",
+                "TeamRequestInvite"
+            ]
+        },
+        "entity.TeamRole": {
+            "type": "string",
+            "enum": [
+                "owner",
+                "admin",
+                "member"
+            ],
+            "x-enum-varnames": [
+                "TeamRoleOwner",
+                "TeamRoleAdmin",
+                "TeamRoleMember"
+            ]
+        },
         "entity.User": {
             "type": "object",
             "properties": {
                 "email": {
                     "type": "string"
                 },
+                "emailVerificationRequired": {
+                    "description": "EmailVerificationRequired is set for users who signed up once emails were verified and for users who\nchanged their email since; the others signed up before and are verified by cmd/backfill",
+                    "type": "boolean"
+                },
+                "emailVerified": {
+                    "type": "boolean"
+                },
                 "firstname": {
                     "type": "string"
                 },
@@ -3848,6 +7160,23 @@ const docTemplate = `{
                 "Photography",
                 "Language"
             ]
+        },
+        "search.Kind": {
+            "type": "string",
+            "enum": [
+                "message",
+                "file",
+                "quiz",
+                "event",
+                "team"
+            ],
+            "x-enum-varnames": [
+                "KindMessage",
+                "KindFile",
+                "KindQuiz",
+                "KindEvent",
+                "KindTeam"
+            ]
         }
     },
     "securityDefinitions": {
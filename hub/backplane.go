@@ -0,0 +1,100 @@
+package hub
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+)
+
+// Delivery is a message forwarded to another node for some of its clients
+type Delivery struct {
+	ClientIDs []string        `json:"clientIds"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// Backplane connects the hubs of several API instances. Each hub joins under its own node ID and
+// records which clients it holds connections for, so a Send only goes to the nodes that can deliver it.
+type Backplane interface {
+	// Join starts passing deliveries addressed to node to handler
+	Join(node string, handler func(Delivery)) error
+	// Leave stops deliveries to node and forgets its routes
+	Leave(node string) error
+	// Publish forwards a delivery to node
+	Publish(node string, delivery Delivery) error
+	// SetRoute records whether clientID currently has connections on node
+	SetRoute(clientID, node string, connected bool) error
+	// Routes returns, for each client that is connected anywhere, the nodes holding its connections
+	Routes(clientIDs []string) (map[string][]string, error)
+}
+
+// backplane and nodeID are set by InitBackplane; NewClusterHub joins hubs to them
+var (
+	backplane Backplane
+	nodeID    string
+)
+
+// InitBackplane connects the backplane selected by HUB_BACKPLANE. It must run before the hubs are created.
+func InitBackplane() error {
+	hubConfig := config.GetHubConfig()
+
+	switch hubConfig.Backplane {
+	case config.HubBackplaneLocal:
+		UseBackplane(nil, "")
+	case config.HubBackplaneRedis:
+		b, err := NewRedisBackplaneFromURL(hubConfig.RedisURL)
+		if err != nil {
+			return fmt.Errorf("connect redis backplane: %w", err)
+		}
+		node := hubConfig.NodeID + "-" + randomSuffix()
+		UseBackplane(b, node)
+		log.Printf("Hub backplane: redis, node %s", node)
+	default:
+		return fmt.Errorf("unknown HUB_BACKPLANE %q", hubConfig.Backplane)
+	}
+	return nil
+}
+
+// UseBackplane makes hubs created from now on by NewClusterHub join b as node. Passing nil keeps them local.
+func UseBackplane(b Backplane, node string) {
+	backplane = b
+	nodeID = node
+}
+
+// NewClusterHub returns a hub joined to the configured backplane. name tells apart the hubs of one instance,
+// so each only receives its own deliveries. Without a backplane it is the same as NewHub.
+func NewClusterHub[T any](name string) *Hub[T] {
+	h := NewHub[T]()
+	if backplane == nil {
+		return h
+	}
+	if err := h.SetBackplane(backplane, nodeID+"/"+name); err != nil {
+		log.Printf("hub %s: joining backplane failed, delivering locally only: %v", name, err)
+	}
+	return h
+}
+
+var (
+	messagesHub     *Hub[Message]
+	messagesHubOnce sync.Once
+)
+
+// Messages returns the cluster hub user sockets connect to, shared by every service that pushes events
+// to users. It is created on first use, which must come after InitBackplane.
+func Messages() *Hub[Message] {
+	messagesHubOnce.Do(func() {
+		messagesHub = NewClusterHub[Message]("messages")
+	})
+	return messagesHub
+}
+
+// randomSuffix keeps node IDs unique when several instances share a host name, or one restarts
+func randomSuffix() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -2,6 +2,8 @@ package hub
 
 import (
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -11,6 +13,17 @@ const (
 	clientOutboundBufferSize = 16
 	webSocketReadBufferSize  = 1024
 	webSocketWriteBufferSize = 1024
+
+	// closeWait bounds how long a slow client's close frame may wait behind a write in progress
+	closeWait = time.Second
+)
+
+// Close codes the hub ends a connection with. Clients keep the seq of the last event they received:
+// after CloseResync they reconnect with since=<seq> and are sent what they missed; after
+// CloseBacklogExpired those events are gone, so they reload over HTTP and reconnect without since.
+const (
+	CloseResync         = 4000
+	CloseBacklogExpired = 4001
 )
 
 type Client[T any] struct {
@@ -19,6 +32,14 @@ type Client[T any] struct {
 
 	// Channel for sending messages to the client
 	outbound chan T
+
+	mu sync.Mutex
+	// While holding, messages are kept in held instead of outbound, so a replay can go first
+	holding bool
+	held    []T
+	// replay is written by the write pump before anything from outbound
+	replay     []T
+	behindOnce sync.Once
 }
 
 func NewClient[T any](clientID string, conn *websocket.Conn) *Client[T] {
@@ -29,6 +50,79 @@ func NewClient[T any](clientID string, conn *websocket.Conn) *Client[T] {
 	}
 }
 
+// enqueue queues a message without blocking. A client that is not keeping up is disconnected
+// with CloseResync rather than losing the message silently.
+func (c *Client[T]) enqueue(msg T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.holding {
+		if len(c.held) < maxReplayMessages {
+			c.held = append(c.held, msg)
+			return
+		}
+		c.fallBehind()
+		return
+	}
+	select {
+	case c.outbound <- msg:
+		// Sent to outbound channel
+	default:
+		// Channel is full
+		c.fallBehind()
+	}
+}
+
+// fallBehind closes the connection of a client whose buffer is full
+func (c *Client[T]) fallBehind() {
+	c.behindOnce.Do(func() {
+		go c.closeWith(CloseResync, "outbound buffer full, reconnect with since")
+	})
+}
+
+// closeWith sends a close frame with code and closes the connection. The read and write pumps
+// then fail and unregister the client.
+func (c *Client[T]) closeWith(code int, text string) {
+	_ = c.Conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(closeWait))
+	_ = c.Conn.Close()
+}
+
+// hold starts keeping messages back until release
+func (c *Client[T]) hold() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.holding = true
+}
+
+// release queues the replay followed by the messages held meanwhile. Held messages that are
+// part of the replay, because they were journaled before it was read, are skipped.
+func (c *Client[T]) release(replay []T, seq func(T) int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var last int64
+	if len(replay) > 0 {
+		last = seq(replay[len(replay)-1])
+	}
+	c.replay = replay
+	for _, msg := range c.held {
+		if s := seq(msg); s == 0 || s > last {
+			c.replay = append(c.replay, msg)
+		}
+	}
+	c.holding = false
+	c.held = nil
+}
+
+// takeReplay returns the replay once; the write pump sends it before reading outbound
+func (c *Client[T]) takeReplay() []T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	replay := c.replay
+	c.replay = nil
+	return replay
+}
+
 func AcceptConnection(c *gin.Context) (*websocket.Conn, error) {
 	upgrader := websocket.Upgrader{
 		ReadBufferSize:  webSocketReadBufferSize,
@@ -1,6 +1,8 @@
 package hub
 
 import (
+	"encoding/json"
+	"log"
 	"sync"
 	"time"
 
@@ -13,68 +15,296 @@ const (
 
 	// MUST be LESS than readWait
 	pingFrequency = (readWait * 9) / 10 // pingFrequency = 90% * readWait
+
+	// maxInboundFrameSize is the largest frame a client may send, bigger frames close the connection
+	maxInboundFrameSize = 64 * 1024
+
+	// maxReplayMessages is the most messages a reconnecting client is sent; missing more means a reload
+	maxReplayMessages = 1000
 )
 
+// InboundHandler is called from the client's read pump for every frame the client sends
+type InboundHandler[T any] func(client *Client[T], data []byte)
+
+// PresenceHandler is called when a user's first connection opens (online) and when their last one closes.
+// It runs outside the hub's lock, so a quick reconnect may be reported out of order; use IsOnline for the current state.
+type PresenceHandler func(clientID string, online bool)
+
 type Hub[T any] struct {
-	// The clients connected to this hub
-	clients map[string]*Client[T]
+	// The connections of each client; one user may be connected from several tabs or devices
+	clients map[string]map[*Client[T]]struct{}
 	mu      sync.RWMutex
+
+	onInbound  InboundHandler[T]
+	onPresence PresenceHandler
+
+	// journal numbers and keeps what is sent, nil when missed messages cannot be replayed
+	journal Journal[T]
+
+	// backplane forwards messages for clients connected to other nodes, nil for a single instance
+	backplane Backplane
+	node      string
+	// routeMu orders route updates, so the backplane always ends with a client's latest local state
+	routeMu sync.Mutex
 }
 
 func NewHub[T any]() *Hub[T] {
 	return &Hub[T]{
-		clients: make(map[string]*Client[T]),
+		clients: make(map[string]map[*Client[T]]struct{}),
+	}
+}
+
+// SetInboundHandler makes the hub pass inbound frames to handler instead of discarding them.
+// Set it before registering clients.
+func (h *Hub[T]) SetInboundHandler(handler InboundHandler[T]) {
+	h.onInbound = handler
+}
+
+// SetPresenceHandler registers a handler for users coming online and going offline.
+// Set it before registering clients.
+func (h *Hub[T]) SetPresenceHandler(handler PresenceHandler) {
+	h.onPresence = handler
+}
+
+// SetBackplane joins the hub to b as node. From then on Send and SendMany also reach
+// clients connected to other nodes, and this hub delivers what other nodes forward to it.
+func (h *Hub[T]) SetBackplane(b Backplane, node string) error {
+	if err := b.Join(node, h.deliverForwarded); err != nil {
+		return err
 	}
+	h.backplane = b
+	h.node = node
+	return nil
 }
 
+// SetJournal makes the hub number every message per client and keep it, so RegisterSince can replay it.
+// Set it before sending.
+func (h *Hub[T]) SetJournal(journal Journal[T]) {
+	h.journal = journal
+}
+
+// Register adds a connection next to any the client already has
 func (h *Hub[T]) Register(client *Client[T]) {
+	h.add(client)
+	h.start(client)
+}
+
+// RegisterSince adds a connection like Register, first sending the client's journaled messages
+// numbered above since. When some of those are no longer kept, the connection is closed with CloseBacklogExpired.
+func (h *Hub[T]) RegisterSince(client *Client[T], since int64) {
+	if h.journal == nil {
+		h.Register(client)
+		return
+	}
+
+	// Messages sent from now on are held, then either found in the journal or queued after the replay
+	client.hold()
+	h.add(client)
+
+	replay, err := h.journal.Since(client.ClientID, since, maxReplayMessages+1)
+	if err != nil {
+		log.Printf("hub %s: reading the journal of %s failed: %v", h.node, client.ClientID, err)
+		h.expire(client)
+		return
+	}
+	if len(replay) > maxReplayMessages || (len(replay) > 0 && h.journal.Seq(replay[0]) > since+1) {
+		h.expire(client)
+		return
+	}
+
+	client.release(replay, h.journal.Seq)
+	h.start(client)
+}
+
+// expire closes a connection whose missed messages cannot be replayed
+func (h *Hub[T]) expire(client *Client[T]) {
+	_ = client.Conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(CloseBacklogExpired, "missed events expired, reload and reconnect without since"),
+		time.Now().Add(writeWait))
+	h.Unregister(client)
+}
+
+// add records the connection, reporting the client online if it is the first one
+func (h *Hub[T]) add(client *Client[T]) {
 	h.mu.Lock()
-	h.clients[client.ClientID] = client
+	connections, ok := h.clients[client.ClientID]
+	if !ok {
+		connections = make(map[*Client[T]]struct{})
+		h.clients[client.ClientID] = connections
+	}
+	connections[client] = struct{}{}
 	h.mu.Unlock()
 
-	// Start the read and write pump
+	if !ok {
+		h.syncRoute(client.ClientID)
+		if h.onPresence != nil {
+			h.onPresence(client.ClientID, true)
+		}
+	}
+}
+
+// start runs the read and write pumps of a connection
+func (h *Hub[T]) start(client *Client[T]) {
 	go h.readPump(client)
 	go h.writePump(client)
 }
 
+// Unregister closes one connection; the client's other connections stay open
 func (h *Hub[T]) Unregister(client *Client[T]) {
 	h.mu.Lock()
-	_, ok := h.clients[client.ClientID]
+	connections := h.clients[client.ClientID]
+	_, ok := connections[client]
+	wentOffline := false
 	if ok {
-		delete(h.clients, client.ClientID)
+		delete(connections, client)
+		if len(connections) == 0 {
+			delete(h.clients, client.ClientID)
+			wentOffline = true
+		}
+		// closed under the lock, so senders holding the read lock never write to a closed channel
+		close(client.outbound)
 	}
 	h.mu.Unlock()
 
-	if ok {
-		close(client.outbound)
-		err := client.Conn.Close()
-		if err != nil {
-			return
+	if !ok {
+		return
+	}
+	if wentOffline {
+		h.syncRoute(client.ClientID)
+		if h.onPresence != nil {
+			h.onPresence(client.ClientID, false)
 		}
 	}
+	err := client.Conn.Close()
+	if err != nil {
+		return
+	}
 }
 
+// Send delivers the message to every connection of the client, on any node
 func (h *Hub[T]) Send(clientID string, msg T) {
-	h.mu.Lock()
-	client, ok := h.clients[clientID]
-	h.mu.Unlock()
+	h.SendMany([]string{clientID}, msg)
+}
 
-	if !ok {
-		// Client is offline
+// deliverLocal enqueues the message on this node's connections of the clients
+func (h *Hub[T]) deliverLocal(clientIDs []string, msg T) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, clientID := range clientIDs {
+		// Nothing to do when the client is offline
+		for client := range h.clients[clientID] {
+			client.enqueue(msg)
+		}
+	}
+}
+
+// Reply sends a message to one connection, such as the answer to a frame it sent
+func (h *Hub[T]) Reply(client *Client[T], msg T) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if _, ok := h.clients[client.ClientID][client]; !ok {
+		// Connection already closed
 		return
 	}
+	client.enqueue(msg)
+}
 
-	select {
-	case client.outbound <- msg:
-		// Sent to outbound channel
-	default:
-		// Channel is full
+// IsOnline reports whether the client has at least one open connection, on this node or, with a backplane, any other
+func (h *Hub[T]) IsOnline(clientID string) bool {
+	if h.ConnectionCount(clientID) > 0 {
+		return true
 	}
+	if h.backplane == nil {
+		return false
+	}
+	routes, err := h.backplane.Routes([]string{clientID})
+	return err == nil && len(routes[clientID]) > 0
 }
 
+// ConnectionCount returns the number of connections the client has on this node
+func (h *Hub[T]) ConnectionCount(clientID string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients[clientID])
+}
+
+// SendMany delivers the message to every connection of the clients. With a backplane, the clients
+// connected to other nodes are grouped by node and each node gets one forwarded copy.
+// With a journal, each client gets its own numbered copy, kept even when the client is offline.
 func (h *Hub[T]) SendMany(clientIDs []string, msg T) {
-	for _, id := range clientIDs {
-		h.Send(id, msg)
+	if h.journal == nil {
+		h.deliverLocal(clientIDs, msg)
+		h.forward(clientIDs, msg)
+		return
+	}
+	for _, clientID := range clientIDs {
+		stamped, err := h.journal.Append(clientID, msg)
+		if err != nil {
+			// Still delivered live, but a reconnecting client will not get it again
+			log.Printf("hub %s: journaling a message for %s failed: %v", h.node, clientID, err)
+		}
+		h.deliverLocal([]string{clientID}, stamped)
+		h.forward([]string{clientID}, stamped)
+	}
+}
+
+// forward publishes the message to the other nodes holding connections of the clients
+func (h *Hub[T]) forward(clientIDs []string, msg T) {
+	if h.backplane == nil || len(clientIDs) == 0 {
+		return
+	}
+
+	routes, err := h.backplane.Routes(clientIDs)
+	if err != nil {
+		log.Printf("hub %s: looking up routes failed: %v", h.node, err)
+		return
+	}
+	byNode := make(map[string][]string)
+	for clientID, nodes := range routes {
+		for _, node := range nodes {
+			if node != h.node {
+				byNode[node] = append(byNode[node], clientID)
+			}
+		}
+	}
+	if len(byNode) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("hub %s: encoding message failed: %v", h.node, err)
+		return
+	}
+	for node, ids := range byNode {
+		if err := h.backplane.Publish(node, Delivery{ClientIDs: ids, Message: data}); err != nil {
+			log.Printf("hub %s: forwarding to %s failed: %v", h.node, node, err)
+		}
+	}
+}
+
+// deliverForwarded hands a message forwarded by another node to the local connections
+func (h *Hub[T]) deliverForwarded(delivery Delivery) {
+	var msg T
+	if err := json.Unmarshal(delivery.Message, &msg); err != nil {
+		log.Printf("hub %s: decoding forwarded message failed: %v", h.node, err)
+		return
+	}
+	h.deliverLocal(delivery.ClientIDs, msg)
+}
+
+// syncRoute records on the backplane whether the client still has connections on this node
+func (h *Hub[T]) syncRoute(clientID string) {
+	if h.backplane == nil {
+		return
+	}
+	h.routeMu.Lock()
+	defer h.routeMu.Unlock()
+
+	if err := h.backplane.SetRoute(clientID, h.node, h.ConnectionCount(clientID) > 0); err != nil {
+		log.Printf("hub %s: updating route of %s failed: %v", h.node, clientID, err)
 	}
 }
 
@@ -86,15 +316,23 @@ func (h *Hub[T]) writePump(client *Client[T]) {
 		h.Unregister(client)
 	}()
 
+	// Messages the client missed go before anything sent live
+	for _, msg := range client.takeReplay() {
+		if err := client.Conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+			return
+		}
+		if err := client.Conn.WriteJSON(msg); err != nil {
+			return
+		}
+	}
+
 	for {
 		select {
 		case msg, ok := <-client.outbound:
 			if !ok {
 				// The hub closed the channel
-				err := client.Conn.WriteControl(websocket.CloseMessage, []byte{}, time.Now().Add(writeWait))
-				if err != nil {
-					return
-				}
+				_ = client.Conn.WriteControl(websocket.CloseMessage, []byte{}, time.Now().Add(writeWait))
+				return
 			}
 
 			// Send the message as JSON
@@ -116,7 +354,7 @@ func (h *Hub[T]) writePump(client *Client[T]) {
 	}
 }
 
-// readPump continuously checks for disconnection
+// readPump continuously checks for disconnection and hands inbound frames to the inbound handler
 func (h *Hub[T]) readPump(client *Client[T]) {
 	defer func() {
 		h.Unregister(client)
@@ -132,11 +370,15 @@ func (h *Hub[T]) readPump(client *Client[T]) {
 		return client.Conn.SetReadDeadline(time.Now().Add(readWait))
 	})
 
+	client.Conn.SetReadLimit(maxInboundFrameSize)
 	for {
-		_, _, err := client.Conn.ReadMessage()
+		_, data, err := client.Conn.ReadMessage()
 		if err != nil {
 			// WebSocket sent a disconnect message
 			return
 		}
+		if h.onInbound != nil {
+			h.onInbound(client, data)
+		}
 	}
 }
@@ -0,0 +1,80 @@
+package hub
+
+import (
+	"encoding/json"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// Journal numbers the messages sent to each client and keeps them, so a client that reconnects
+// can be sent everything it missed
+type Journal[T any] interface {
+	// Append stamps msg with the client's next sequence number and stores it.
+	// Messages not worth replaying come back unchanged, with sequence number 0, as does msg on error.
+	Append(clientID string, msg T) (T, error)
+	// Since returns up to limit stored messages numbered above seq, oldest first
+	Since(clientID string, seq int64, limit int) ([]T, error)
+	// Seq returns the sequence number stamped on msg, 0 when it has none
+	Seq(msg T) int64
+}
+
+// EventStore keeps the journaled messages of each client; persistence.HubEventRepositoryInterface implements it
+type EventStore interface {
+	Append(clientID string, data json.RawMessage) (int64, error)
+	ListSince(clientID string, seq int64, limit int) ([]*entity.HubEvent, error)
+}
+
+// MessageJournal is the Journal of the message hub
+type MessageJournal struct {
+	store EventStore
+}
+
+func NewMessageJournal(store EventStore) *MessageJournal {
+	return &MessageJournal{store: store}
+}
+
+// ephemeral reports whether a message only matters at the moment it is sent
+func ephemeral(msgType MessageType) bool {
+	switch msgType {
+	case Typing, PresenceChanged, Ack, Error:
+		return true
+	}
+	return false
+}
+
+func (j *MessageJournal) Append(clientID string, msg Message) (Message, error) {
+	if ephemeral(msg.Type) {
+		return msg, nil
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return msg, err
+	}
+	seq, err := j.store.Append(clientID, data)
+	if err != nil {
+		return msg, err
+	}
+	msg.Seq = seq
+	return msg, nil
+}
+
+func (j *MessageJournal) Since(clientID string, seq int64, limit int) ([]Message, error) {
+	events, err := j.store.ListSince(clientID, seq, limit)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]Message, 0, len(events))
+	for _, event := range events {
+		var msg Message
+		if err := json.Unmarshal(event.Data, &msg); err != nil {
+			return nil, err
+		}
+		msg.Seq = event.Seq
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+func (j *MessageJournal) Seq(msg Message) int64 {
+	return msg.Seq
+}
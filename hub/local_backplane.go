@@ -0,0 +1,82 @@
+package hub
+
+import "sync"
+
+// LocalBackplane connects hubs of the same process. It delivers synchronously and is mostly useful in tests;
+// a single instance does not need a backplane at all.
+type LocalBackplane struct {
+	mu       sync.RWMutex
+	handlers map[string]func(Delivery)
+	routes   map[string]map[string]struct{}
+}
+
+func NewLocalBackplane() *LocalBackplane {
+	return &LocalBackplane{
+		handlers: make(map[string]func(Delivery)),
+		routes:   make(map[string]map[string]struct{}),
+	}
+}
+
+func (b *LocalBackplane) Join(node string, handler func(Delivery)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[node] = handler
+	return nil
+}
+
+func (b *LocalBackplane) Leave(node string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.handlers, node)
+	for clientID, nodes := range b.routes {
+		delete(nodes, node)
+		if len(nodes) == 0 {
+			delete(b.routes, clientID)
+		}
+	}
+	return nil
+}
+
+func (b *LocalBackplane) Publish(node string, delivery Delivery) error {
+	b.mu.RLock()
+	handler, ok := b.handlers[node]
+	b.mu.RUnlock()
+
+	if ok {
+		handler(delivery)
+	}
+	return nil
+}
+
+func (b *LocalBackplane) SetRoute(clientID, node string, connected bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	nodes, ok := b.routes[clientID]
+	if connected {
+		if !ok {
+			nodes = make(map[string]struct{})
+			b.routes[clientID] = nodes
+		}
+		nodes[node] = struct{}{}
+		return nil
+	}
+	delete(nodes, node)
+	if ok && len(nodes) == 0 {
+		delete(b.routes, clientID)
+	}
+	return nil
+}
+
+func (b *LocalBackplane) Routes(clientIDs []string) (map[string][]string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	routes := make(map[string][]string)
+	for _, clientID := range clientIDs {
+		for node := range b.routes[clientID] {
+			routes[clientID] = append(routes[clientID], node)
+		}
+	}
+	return routes, nil
+}
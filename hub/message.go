@@ -1,15 +1,60 @@
 package hub
 
+import "encoding/json"
+
 type MessageType string
 
 const (
 	DirectMessage MessageType = "direct_message"
 	TeamBroadcast MessageType = "team_message"
+	// MessageEdited and MessageDeleted carry the updated message, or its tombstone, as payload.
+	// MessageEdited also reports changed reactions and the new reply count of a thread's parent.
+	MessageEdited  MessageType = "message_edited"
+	MessageDeleted MessageType = "message_deleted"
+	Typing         MessageType = "typing"
+	ReadReceipt    MessageType = "message_read"
+	// PresenceChanged tells friends and teammates that a user went online, idle or offline
+	PresenceChanged MessageType = "presence"
+	// NotificationReceived carries a new notification with the recipient's unread count; NotificationsRead
+	// tells the user's other connections which notifications were read
+	NotificationReceived MessageType = "notification"
+	NotificationsRead    MessageType = "notifications_read"
+	// Ack and Error answer an inbound frame, carrying its requestId
+	Ack   MessageType = "ack"
+	Error MessageType = "error"
 )
 
 type Message struct {
-	Type    MessageType `json:"type"`
-	Payload interface{} `json:"payload"`
+	// Seq numbers the events of one user, see Journal; it is 0 on acks, errors and typing events
+	Seq       int64       `json:"seq,omitempty"`
+	Type      MessageType `json:"type"`
+	RequestID string      `json:"requestId,omitempty"`
+	Payload   interface{} `json:"payload"`
+}
+
+// InboundType is the kind of frame a client sends over the socket
+type InboundType string
+
+const (
+	SendDirectMessage InboundType = "send_direct_message"
+	SendTeamMessage   InboundType = "send_team_message"
+	SendTyping        InboundType = "typing"
+	SendRead          InboundType = "read"
+	SendReaction      InboundType = "react"
+	SetPresence       InboundType = "presence"
+)
+
+// InboundMessage is the envelope of every frame a client sends. RequestID is chosen by the
+// client and echoed in the ack or error frame; Payload is decoded according to Type.
+type InboundMessage struct {
+	RequestID string          `json:"requestId"`
+	Type      InboundType     `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// ErrorPayload is the payload of an Error frame
+type ErrorPayload struct {
+	Error string `json:"error"`
 }
 
 func NewMessage(msgType MessageType, payload interface{}) *Message {
@@ -18,3 +63,11 @@ func NewMessage(msgType MessageType, payload interface{}) *Message {
 		Payload: payload,
 	}
 }
+
+func NewAck(requestID string, payload interface{}) *Message {
+	return &Message{Type: Ack, RequestID: requestID, Payload: payload}
+}
+
+func NewError(requestID string, err error) *Message {
+	return &Message{Type: Error, RequestID: requestID, Payload: ErrorPayload{Error: err.Error()}}
+}
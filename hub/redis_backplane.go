@@ -0,0 +1,231 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// a node that stops refreshing its liveness key for redisNodeTTL is treated as gone
+	redisNodeTTL       = 30 * time.Second
+	redisHeartbeatRate = redisNodeTTL / 3
+)
+
+// RedisBackplane connects hubs through Redis pub/sub. Every node subscribes to its own channel,
+// and routes are kept in one set of nodes per client, so a message is published only to the nodes
+// holding connections for its recipients. Nodes that crash without leaving are skipped once their
+// liveness key expires, and their routes are cleaned up on the next lookup.
+type RedisBackplane struct {
+	client *redis.Client
+	mu     sync.Mutex
+	nodes  map[string]*redisNode
+}
+
+type redisNode struct {
+	pubsub *redis.PubSub
+	stop   chan struct{}
+}
+
+func NewRedisBackplane(client *redis.Client) *RedisBackplane {
+	return &RedisBackplane{client: client, nodes: make(map[string]*redisNode)}
+}
+
+// NewRedisBackplaneFromURL connects to url, such as redis://localhost:6379/0, and checks the connection
+func NewRedisBackplaneFromURL(url string) (*RedisBackplane, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(options)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return NewRedisBackplane(client), nil
+}
+
+// Redis keys: a channel per node, a liveness key per node, the set of clients on each node
+// and the set of nodes each client is connected to
+func nodeChannel(node string) string         { return "hub:node:" + node }
+func nodeAliveKey(node string) string        { return "hub:alive:" + node }
+func nodeClientsKey(node string) string      { return "hub:clients:" + node }
+func clientRoutesKey(clientID string) string { return "hub:routes:" + clientID }
+
+func (b *RedisBackplane) Join(node string, handler func(Delivery)) error {
+	ctx := context.Background()
+	pubsub := b.client.Subscribe(ctx, nodeChannel(node))
+	// wait for the subscription, so nothing published after Join returns is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return err
+	}
+	if err := b.client.Set(ctx, nodeAliveKey(node), time.Now().Unix(), redisNodeTTL).Err(); err != nil {
+		pubsub.Close()
+		return err
+	}
+
+	n := &redisNode{pubsub: pubsub, stop: make(chan struct{})}
+	b.mu.Lock()
+	b.nodes[node] = n
+	b.mu.Unlock()
+
+	go b.run(node, n, handler)
+	return nil
+}
+
+// run passes the node's messages to handler and keeps its liveness key fresh until Leave
+func (b *RedisBackplane) run(node string, n *redisNode, handler func(Delivery)) {
+	ticker := time.NewTicker(redisHeartbeatRate)
+	defer ticker.Stop()
+	messages := n.pubsub.Channel()
+
+	for {
+		select {
+		case <-n.stop:
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			var delivery Delivery
+			if err := json.Unmarshal([]byte(msg.Payload), &delivery); err != nil {
+				log.Printf("backplane: dropping malformed delivery for %s: %v", node, err)
+				continue
+			}
+			handler(delivery)
+		case <-ticker.C:
+			if err := b.client.Set(context.Background(), nodeAliveKey(node), time.Now().Unix(), redisNodeTTL).Err(); err != nil {
+				log.Printf("backplane: heartbeat for %s failed: %v", node, err)
+			}
+		}
+	}
+}
+
+func (b *RedisBackplane) Leave(node string) error {
+	b.mu.Lock()
+	n, ok := b.nodes[node]
+	delete(b.nodes, node)
+	b.mu.Unlock()
+	if ok {
+		close(n.stop)
+		n.pubsub.Close()
+	}
+
+	ctx := context.Background()
+	clientIDs, err := b.client.SMembers(ctx, nodeClientsKey(node)).Result()
+	if err != nil {
+		return err
+	}
+	_, err = b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, clientID := range clientIDs {
+			pipe.SRem(ctx, clientRoutesKey(clientID), node)
+		}
+		pipe.Del(ctx, nodeClientsKey(node), nodeAliveKey(node))
+		return nil
+	})
+	return err
+}
+
+func (b *RedisBackplane) Publish(node string, delivery Delivery) error {
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(context.Background(), nodeChannel(node), data).Err()
+}
+
+func (b *RedisBackplane) SetRoute(clientID, node string, connected bool) error {
+	ctx := context.Background()
+	_, err := b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if connected {
+			pipe.SAdd(ctx, clientRoutesKey(clientID), node)
+			pipe.SAdd(ctx, nodeClientsKey(node), clientID)
+		} else {
+			pipe.SRem(ctx, clientRoutesKey(clientID), node)
+			pipe.SRem(ctx, nodeClientsKey(node), clientID)
+		}
+		return nil
+	})
+	return err
+}
+
+func (b *RedisBackplane) Routes(clientIDs []string) (map[string][]string, error) {
+	ctx := context.Background()
+	members := make([]*redis.StringSliceCmd, len(clientIDs))
+	if _, err := b.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, clientID := range clientIDs {
+			members[i] = pipe.SMembers(ctx, clientRoutesKey(clientID))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	alive, err := b.aliveNodes(members)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make(map[string][]string)
+	stale := make(map[string][]string)
+	for i, clientID := range clientIDs {
+		for _, node := range members[i].Val() {
+			if alive[node] {
+				routes[clientID] = append(routes[clientID], node)
+			} else {
+				stale[clientID] = append(stale[clientID], node)
+			}
+		}
+	}
+	b.pruneRoutes(stale)
+	return routes, nil
+}
+
+// aliveNodes checks the liveness key of every node found in the route sets
+func (b *RedisBackplane) aliveNodes(members []*redis.StringSliceCmd) (map[string]bool, error) {
+	ctx := context.Background()
+	checks := make(map[string]*redis.IntCmd)
+	_, err := b.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, cmd := range members {
+			for _, node := range cmd.Val() {
+				if _, ok := checks[node]; !ok {
+					checks[node] = pipe.Exists(ctx, nodeAliveKey(node))
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	alive := make(map[string]bool, len(checks))
+	for node, cmd := range checks {
+		alive[node] = cmd.Val() > 0
+	}
+	return alive, nil
+}
+
+// pruneRoutes drops routes to nodes that stopped without leaving
+func (b *RedisBackplane) pruneRoutes(stale map[string][]string) {
+	if len(stale) == 0 {
+		return
+	}
+	ctx := context.Background()
+	_, err := b.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for clientID, nodes := range stale {
+			for _, node := range nodes {
+				pipe.SRem(ctx, clientRoutesKey(clientID), node)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("backplane: pruning stale routes failed: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileMailer appends every message to a local file instead of delivering it.
+// Useful for development and for inspecting the exact content of sent mail.
+type FileMailer struct {
+	path string
+	from string
+	mu   sync.Mutex
+}
+
+func NewFileMailer(path, from string) *FileMailer {
+	return &FileMailer{
+		path: path,
+		from: from,
+	}
+}
+
+func (m *FileMailer) Send(to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	header := fmt.Sprintf("--- %s ---\r\n", time.Now().UTC().Format(time.RFC3339))
+	if _, err := f.WriteString(header); err != nil {
+		return err
+	}
+	_, err = f.Write(append(buildMessage(m.from, to, subject, body), "\r\n\r\n"...))
+	return err
+}
+
+// LogMailer writes every message to the application log
+type LogMailer struct {
+	from string
+}
+
+func NewLogMailer(from string) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+func (m *LogMailer) Send(to, subject, body string) error {
+	log.Printf("mail from=%s to=%s subject=%q\n%s", m.from, to, subject, body)
+	return nil
+}
@@ -0,0 +1,24 @@
+package mailer
+
+import (
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+)
+
+// Mailer sends plain-text emails
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NewMailer builds the Mailer selected by the mail configuration
+func NewMailer() Mailer {
+	cfg := config.GetMailConfig()
+
+	switch cfg.Driver {
+	case config.MailDriverSMTP:
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From)
+	case config.MailDriverFile:
+		return NewFileMailer(cfg.FilePath, cfg.From)
+	default:
+		return NewLogMailer(cfg.From)
+	}
+}
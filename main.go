@@ -3,10 +3,13 @@ package main
 import (
 	"log"
 	"os"
+	"time"
 
-	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/docs"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/hub"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/routes"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -32,7 +35,30 @@ func main() {
 	log.Println("Starting StudyWithMe API server...")
 	log.Printf("Gin mode: %s", gin.Mode())
 
-	config.InitFirebase()
+	if err := persistence.InitDatabase(); err != nil {
+		log.Fatalf("Error initializing database: %v", err)
+	}
+	if err := hub.InitBackplane(); err != nil {
+		log.Fatalf("Error initializing hub backplane: %v", err)
+	}
+
+	if interval := os.Getenv("MEMBERSHIP_REPAIR_INTERVAL"); interval != "" {
+		repairInterval, err := time.ParseDuration(interval)
+		if err != nil {
+			log.Fatalf("Invalid MEMBERSHIP_REPAIR_INTERVAL: %v", err)
+		}
+		service.NewMembershipRepairService().StartJob(repairInterval)
+		log.Printf("Membership repair runs every %s", repairInterval)
+	}
+
+	if interval := os.Getenv("SEARCH_REBUILD_INTERVAL"); interval != "" {
+		rebuildInterval, err := time.ParseDuration(interval)
+		if err != nil {
+			log.Fatalf("Invalid SEARCH_REBUILD_INTERVAL: %v", err)
+		}
+		service.NewSearchService().StartRebuildJob(rebuildInterval)
+		log.Printf("Search index is rebuilt every %s", rebuildInterval)
+	}
 
 	r := routes.SetupRoutes()
 
@@ -7,7 +7,7 @@ import (
 )
 
 type CreateEventRequest struct {
-	InitiatorID string `json:"initiatorId"`
+	InitiatorID string `json:"initiatorId"` // Optional, filled from the token
 	TeamID      string `json:"teamId"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
@@ -43,7 +43,7 @@ func NewUpdateEventRequest(name, description, startsAt string, duration int64) *
 }
 
 type UpdateEventStatusRequest struct {
-	UserID string `json:"userId"`
+	UserID string `json:"userId"` // Optional, filled from the token
 	Status string `json:"status"`
 }
 
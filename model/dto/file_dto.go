@@ -5,7 +5,7 @@ type FileUploadRequest struct {
 	Type        string `json:"type" binding:"required"`
 	Extension   string `json:"extension" binding:"required"`
 	Content     string `json:"content" binding:"required"`
-	OwnerID     string `json:"ownerId" binding:"required"`
+	OwnerID     string `json:"ownerId"` // Optional, set from the token
 	Size        int64  `json:"size" binding:"required"`
 	ContextType string `json:"contextType"` // Set automatically from URL ("team" or "chat")
 	ContextID   string `json:"contextId"`   // Set automatically from URL (teamId or chatId)
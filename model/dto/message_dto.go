@@ -1,17 +1,30 @@
 package dto
 
-import "time"
+import (
+	"sort"
+	"time"
 
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// DirectMessageRequest and TeamMessageRequest may quote a message of the same conversation or team
+// with ReplyToID, post into the thread of one with ParentID, and attach files uploaded to it with AttachmentIDs
 type DirectMessageRequest struct {
-	SenderID    string `json:"senderId"`
-	ReceiverID  string `json:"receiverId"`
-	TextContent string `json:"textContent"`
+	SenderID      string   `json:"senderId"` // Optional, filled from the token
+	ReceiverID    string   `json:"receiverId"`
+	TextContent   string   `json:"textContent"`
+	ReplyToID     string   `json:"replyToId,omitempty"`
+	ParentID      string   `json:"parentId,omitempty"`
+	AttachmentIDs []string `json:"attachmentIds,omitempty"`
 }
 
 type TeamMessageRequest struct {
-	SenderID    string `json:"senderId"`
-	TeamId      string `json:"teamId"`
-	TextContent string `json:"textContent"`
+	SenderID      string   `json:"senderId"` // Optional, filled from the token
+	TeamId        string   `json:"teamId"`
+	TextContent   string   `json:"textContent"`
+	ReplyToID     string   `json:"replyToId,omitempty"`
+	ParentID      string   `json:"parentId,omitempty"`
+	AttachmentIDs []string `json:"attachmentIds,omitempty"`
 }
 
 func NewDirectMessageRequest(senderId, receiverId, textContent string) *DirectMessageRequest {
@@ -30,13 +43,61 @@ func NewTeamMessageRequest(senderId, teamId, textContent string) *TeamMessageReq
 	}
 }
 
+type EditMessageRequest struct {
+	TextContent string `json:"textContent"`
+}
+
+type MessageEditDTO struct {
+	TextContent string `json:"textContent"`
+	EditedAt    string `json:"editedAt"`
+	EditedBy    string `json:"editedBy"`
+}
+
 type MessageDTO struct {
-	ID          string    `json:"id"`
-	Sender      SenderDTO `json:"sender"`
-	SentAt      string    `json:"sentAt"`
-	ReceiverID  string    `json:"receiverId,omitempty"`
-	TeamID      string    `json:"teamId,omitempty"`
-	TextContent string    `json:"textContent"`
+	ID          string           `json:"id"`
+	Sender      SenderDTO        `json:"sender"`
+	SentAt      string           `json:"sentAt"`
+	ReceiverID  string           `json:"receiverId,omitempty"`
+	TeamID      string           `json:"teamId,omitempty"`
+	TextContent string           `json:"textContent"`
+	EditedAt    string           `json:"editedAt,omitempty"`
+	EditHistory []MessageEditDTO `json:"editHistory,omitempty"`
+	Deleted     bool             `json:"deleted,omitempty"`
+	DeletedAt   string           `json:"deletedAt,omitempty"`
+	ParentID    string           `json:"parentId,omitempty"`
+	ReplyTo     *MessageQuoteDTO `json:"replyTo,omitempty"`
+	Reactions   []ReactionDTO    `json:"reactions,omitempty"`
+	ReplyCount  int              `json:"replyCount,omitempty"`
+	LastReplyAt string           `json:"lastReplyAt,omitempty"`
+	Attachments []AttachmentDTO  `json:"attachments,omitempty"`
+}
+
+// AttachmentDTO describes an attached file; the content is downloaded from the team or conversation files
+type AttachmentDTO struct {
+	FileID    string `json:"fileId"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Extension string `json:"extension"`
+	Size      int64  `json:"size"`
+}
+
+type MessageQuoteDTO struct {
+	MessageID string `json:"messageId"`
+	SenderID  string `json:"senderId"`
+	Preview   string `json:"preview"`
+}
+
+// ReactionDTO is one emoji on a message with the users who reacted with it, earliest first
+type ReactionDTO struct {
+	Emoji   string   `json:"emoji"`
+	Count   int      `json:"count"`
+	UserIDs []string `json:"userIds"`
+}
+
+// ReactionRequest toggles the user's reaction with Emoji on a message. Over HTTP the message ID comes from the path.
+type ReactionRequest struct {
+	MessageID string `json:"messageId,omitempty"`
+	Emoji     string `json:"emoji"`
 }
 
 func NewMessageDTO(id, receiverId, teamId, textContent string, sentAt time.Time, sender SenderDTO) *MessageDTO {
@@ -49,3 +110,145 @@ func NewMessageDTO(id, receiverId, teamId, textContent string, sentAt time.Time,
 		TextContent: textContent,
 	}
 }
+
+// NewMessageDTOFromEntity also carries the edit and delete state of the message
+func NewMessageDTOFromEntity(message *entity.Message, receiverId string, sender SenderDTO) *MessageDTO {
+	messageDTO := NewMessageDTO(message.ID, receiverId, message.TeamID, message.TextContent, message.SentAt, sender)
+	if message.EditedAt != nil {
+		messageDTO.EditedAt = message.EditedAt.Format(time.RFC3339)
+	}
+	for _, edit := range message.EditHistory {
+		messageDTO.EditHistory = append(messageDTO.EditHistory, MessageEditDTO{
+			TextContent: edit.TextContent,
+			EditedAt:    edit.EditedAt.Format(time.RFC3339),
+			EditedBy:    edit.EditedBy,
+		})
+	}
+	if message.IsDeleted() {
+		messageDTO.Deleted = true
+		messageDTO.DeletedAt = message.DeletedAt.Format(time.RFC3339)
+	}
+	messageDTO.ParentID = message.ParentID
+	if message.ReplyTo != nil {
+		messageDTO.ReplyTo = &MessageQuoteDTO{
+			MessageID: message.ReplyTo.MessageID,
+			SenderID:  message.ReplyTo.SenderID,
+			Preview:   message.ReplyTo.Preview,
+		}
+	}
+	messageDTO.Reactions = newReactionDTOs(message.Reactions)
+	for _, attachment := range message.Attachments {
+		messageDTO.Attachments = append(messageDTO.Attachments, AttachmentDTO{
+			FileID:    attachment.FileID,
+			Name:      attachment.Name,
+			Type:      attachment.Type,
+			Extension: attachment.Extension,
+			Size:      attachment.Size,
+		})
+	}
+	messageDTO.ReplyCount = len(message.Replies)
+	if lastReplyAt := message.LastReplyAt(); lastReplyAt != nil {
+		messageDTO.LastReplyAt = lastReplyAt.Format(time.RFC3339)
+	}
+	return messageDTO
+}
+
+// newReactionDTOs orders the emojis by their first reaction, and the users of each by when they reacted
+func newReactionDTOs(reactions map[string]map[string]time.Time) []ReactionDTO {
+	type reacted struct {
+		userID string
+		at     time.Time
+	}
+	dtos := make([]ReactionDTO, 0, len(reactions))
+	firstAt := make(map[string]time.Time, len(reactions))
+	for emoji, users := range reactions {
+		if len(users) == 0 {
+			continue
+		}
+		ordered := make([]reacted, 0, len(users))
+		for userID, at := range users {
+			ordered = append(ordered, reacted{userID: userID, at: at})
+		}
+		sort.Slice(ordered, func(i, j int) bool {
+			if !ordered[i].at.Equal(ordered[j].at) {
+				return ordered[i].at.Before(ordered[j].at)
+			}
+			return ordered[i].userID < ordered[j].userID
+		})
+
+		reaction := ReactionDTO{Emoji: emoji, Count: len(ordered), UserIDs: make([]string, 0, len(ordered))}
+		for _, r := range ordered {
+			reaction.UserIDs = append(reaction.UserIDs, r.userID)
+		}
+		firstAt[emoji] = ordered[0].at
+		dtos = append(dtos, reaction)
+	}
+	sort.Slice(dtos, func(i, j int) bool {
+		if !firstAt[dtos[i].Emoji].Equal(firstAt[dtos[j].Emoji]) {
+			return firstAt[dtos[i].Emoji].Before(firstAt[dtos[j].Emoji])
+		}
+		return dtos[i].Emoji < dtos[j].Emoji
+	})
+	if len(dtos) == 0 {
+		return nil
+	}
+	return dtos
+}
+
+// MessagePageRequest holds the paging query parameters of GET /messages.
+// Before and After are cursors from a previous page; only one of them may be set.
+type MessagePageRequest struct {
+	Before string
+	After  string
+	Limit  int
+}
+
+// MessagePageDTO is one page of a conversation or team, oldest message first.
+// NextCursor continues in the same direction: pass it as before when paging back, as after when paging forward.
+type MessagePageDTO struct {
+	Messages   []*MessageDTO `json:"messages"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+	HasMore    bool          `json:"hasMore"`
+}
+
+// TypingRequest tells the other side of a conversation, or the rest of a team, that the user is typing.
+// Exactly one of ReceiverID and TeamID is set.
+type TypingRequest struct {
+	ReceiverID string `json:"receiverId,omitempty"`
+	TeamID     string `json:"teamId,omitempty"`
+}
+
+type TypingDTO struct {
+	UserID     string `json:"userId"`
+	ReceiverID string `json:"receiverId,omitempty"`
+	TeamID     string `json:"teamId,omitempty"`
+}
+
+type ReadMessageRequest struct {
+	MessageID string `json:"messageId"`
+}
+
+// ReadReceiptDTO tells the other participants how far a user has read. TeamID is set for team messages;
+// for a direct message the conversation is the one with UserID.
+type ReadReceiptDTO struct {
+	MessageID string `json:"messageId"`
+	UserID    string `json:"userId"`
+	TeamID    string `json:"teamId,omitempty"`
+	ReadAt    string `json:"readAt"`
+}
+
+// UnreadCountDTO is the number of messages from others after the user's read cursor in one conversation or team.
+// Counting stops early: HasMore means there are more unread messages than Count.
+type UnreadCountDTO struct {
+	UserID            string `json:"userId,omitempty"`
+	TeamID            string `json:"teamId,omitempty"`
+	Count             int    `json:"count"`
+	HasMore           bool   `json:"hasMore"`
+	LastReadMessageID string `json:"lastReadMessageId,omitempty"`
+}
+
+// UnreadCountsDTO holds the unread counts of the user's direct conversations and of every team they are in
+type UnreadCountsDTO struct {
+	Conversations []*UnreadCountDTO `json:"conversations"`
+	Teams         []*UnreadCountDTO `json:"teams"`
+}
@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// NotificationsPageDTO is a page of a user's notifications, newest first
+type NotificationsPageDTO struct {
+	Notifications []*entity.Notification `json:"notifications"`
+	UnreadCount   int                    `json:"unreadCount"`
+	Page          int                    `json:"page"`
+	Limit         int                    `json:"limit"`
+	TotalCount    int                    `json:"totalCount"`
+	TotalPages    int                    `json:"totalPages"`
+}
+
+type UnreadNotificationsDTO struct {
+	Total  int                             `json:"total"`
+	ByType map[entity.NotificationType]int `json:"byType"`
+}
+
+// NotificationEventDTO is the payload of the notification socket event
+type NotificationEventDTO struct {
+	Notification *entity.Notification `json:"notification"`
+	UnreadCount  int                  `json:"unreadCount"`
+}
+
+// NotificationsReadDTO says which notifications were just marked read: the ones in IDs, or every one when All is set
+type NotificationsReadDTO struct {
+	IDs         []string `json:"ids,omitempty"`
+	All         bool     `json:"all,omitempty"`
+	Marked      int      `json:"marked"`
+	UnreadCount int      `json:"unreadCount"`
+}
+
+// NotificationPreferencesRequest replaces all of the user's notification settings
+type NotificationPreferencesRequest struct {
+	// Channels turns channels on or off by type, e.g. {"mention": {"email": true}}; the rest keep their default
+	Channels     map[entity.NotificationType]map[entity.NotificationChannel]bool `json:"channels"`
+	MutedTeamIDs []string                                                        `json:"mutedTeamIds"`
+	QuietHours   *entity.QuietHours                                              `json:"quietHours"`
+	// Timezone is an IANA name such as "Europe/Bucharest"; empty means UTC
+	Timezone string `json:"timezone"`
+}
+
+type NotificationPreferencesDTO struct {
+	// Channels has every type and channel, defaults included
+	Channels     map[entity.NotificationType]map[entity.NotificationChannel]bool `json:"channels"`
+	MutedTeamIDs []string                                                        `json:"mutedTeamIds"`
+	QuietHours   *entity.QuietHours                                              `json:"quietHours,omitempty"`
+	Timezone     string                                                          `json:"timezone"`
+	// InQuietHours is whether the user is in quiet hours right now, so nothing is sent to their sockets, mailed or pushed.
+	// What is suppressed then is not sent later; in-app notifications are still kept.
+	InQuietHours bool `json:"inQuietHours"`
+}
+
+func NewNotificationPreferencesDTO(preferences *entity.NotificationPreferences, now time.Time) *NotificationPreferencesDTO {
+	channels := make(map[entity.NotificationType]map[entity.NotificationChannel]bool, len(entity.NotificationTypes))
+	for _, notificationType := range entity.NotificationTypes {
+		channels[notificationType] = make(map[entity.NotificationChannel]bool, len(entity.NotificationChannels))
+		for _, channel := range entity.NotificationChannels {
+			channels[notificationType][channel] = preferences.Enabled(notificationType, channel)
+		}
+	}
+	mutedTeamIDs := preferences.MutedTeamIDs
+	if mutedTeamIDs == nil {
+		mutedTeamIDs = []string{}
+	}
+	timezone := preferences.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	return &NotificationPreferencesDTO{
+		Channels:     channels,
+		MutedTeamIDs: mutedTeamIDs,
+		QuietHours:   preferences.QuietHours,
+		Timezone:     timezone,
+		InQuietHours: preferences.InQuietHours(now),
+	}
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// SetPresenceRequest is sent over the message socket when the user goes idle or becomes active again
+type SetPresenceRequest struct {
+	Status entity.PresenceStatus `json:"status"`
+}
+
+type PresenceDTO struct {
+	UserID string                `json:"userId"`
+	Status entity.PresenceStatus `json:"status"`
+	// LastSeenAt is empty for users that never connected
+	LastSeenAt string `json:"lastSeenAt,omitempty"`
+}
+
+func NewPresenceDTO(presence *entity.Presence) *PresenceDTO {
+	return &PresenceDTO{
+		UserID:     presence.UserID,
+		Status:     presence.Status,
+		LastSeenAt: presence.LastSeenAt.Format(time.RFC3339),
+	}
+}
+
+// NewOfflinePresenceDTO describes a user with no presence recorded
+func NewOfflinePresenceDTO(userID string) *PresenceDTO {
+	return &PresenceDTO{UserID: userID, Status: entity.PresenceOffline}
+}
@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/search"
+)
+
+// SearchSnippetLength is how many characters of the matching text a result shows
+const SearchSnippetLength = 150
+
+// SearchRequest is read from the query string of GET /search
+type SearchRequest struct {
+	Query string
+	// Types limits the results to these kinds (message, file, quiz, event, team); empty means all
+	Types  []string
+	TeamID string
+	Page   int
+	Limit  int
+}
+
+type SearchResultDTO struct {
+	Type    search.Kind `json:"type"`
+	ID      string      `json:"id"`
+	Title   string      `json:"title,omitempty"`
+	Snippet string      `json:"snippet,omitempty"`
+	// TeamID is set for team records, UserIDs for the two users of a direct conversation
+	TeamID  string   `json:"teamId,omitempty"`
+	UserIDs []string `json:"userIds,omitempty"`
+	Score   float64  `json:"score"`
+	Time    string   `json:"time,omitempty"`
+}
+
+type SearchResponse struct {
+	Results    []*SearchResultDTO `json:"results"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	TotalCount int                `json:"totalCount"`
+	TotalPages int                `json:"totalPages"`
+}
+
+func NewSearchResultDTO(hit search.Hit) *SearchResultDTO {
+	doc := hit.Document
+	result := &SearchResultDTO{
+		Type:    doc.Kind,
+		ID:      doc.ID,
+		Title:   doc.Title,
+		Snippet: doc.Text,
+		TeamID:  doc.Scope.TeamID,
+		UserIDs: doc.Scope.UserIDs,
+		Score:   hit.Score,
+	}
+	if runes := []rune(doc.Text); len(runes) > SearchSnippetLength {
+		result.Snippet = string(runes[:SearchSnippetLength]) + "…"
+	}
+	if !doc.Time.IsZero() {
+		result.Time = doc.Time.Format(time.RFC3339)
+	}
+	return result
+}
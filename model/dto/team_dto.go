@@ -41,6 +41,15 @@ func NewTeamResponse(name, desc string, isPublic bool, userid string, teamtopic
 	}
 }
 
+// UpdateTeamRequest changes the details of a team. Fields left out or null keep their value,
+// and so do an empty name and topic.
+type UpdateTeamRequest struct {
+	Name        *string                `json:"name"`
+	Description *string                `json:"description"`
+	IsPublic    *bool                  `json:"ispublic"`
+	TeamTopic   *model.TopicOfInterest `json:"teamtopic"`
+}
+
 type UserToTeamRequest struct {
 	UserID string `json:"userId" binding:"required"`
 	TeamID string `json:"teamId" binding:"required"`
@@ -64,3 +73,34 @@ func NewAddUserToTeamResponse(user entity.User, team entity.Team) *AddUserToTeam
 		Team: team,
 	}
 }
+
+// UpdateMemberRoleRequest changes the role of a team member (admin or member)
+type UpdateMemberRoleRequest struct {
+	Role entity.TeamRole `json:"role" binding:"required"`
+}
+
+// TransferOwnershipRequest hands a team over to another member
+type TransferOwnershipRequest struct {
+	NewOwnerID string `json:"newOwnerId" binding:"required"`
+}
+
+// TeamRecommendationDTO is a team the user could join, with what its score is made of
+type TeamRecommendationDTO struct {
+	Team  *entity.Team `json:"team"`
+	Score float64      `json:"score"`
+	// MatchesTopic is set when the team's topic is one of the user's topics of interest
+	MatchesTopic bool `json:"matchesTopic"`
+	// FriendIDs are the user's friends who are members
+	FriendIDs []string `json:"friendIds"`
+	// RecentMessages counts the team messages of the last two weeks, up to 50
+	RecentMessages int `json:"recentMessages"`
+	MemberCount    int `json:"memberCount"`
+}
+
+type TeamRecommendationsDTO struct {
+	Teams      []*TeamRecommendationDTO `json:"teams"`
+	Page       int                      `json:"page"`
+	Limit      int                      `json:"limit"`
+	TotalCount int                      `json:"totalCount"`
+	TotalPages int                      `json:"totalPages"`
+}
@@ -1,16 +1,30 @@
 package dto
 
-import "github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+import (
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
 
 type TeamRequestCreateDTO struct {
-	UserID string `json:"userId" binding:"required"`
-	TeamID string `json:"teamId" binding:"required"`
+	UserID  string `json:"userId"` // Optional, filled from the token
+	TeamID  string `json:"teamId" binding:"required"`
+	Message string `json:"message,omitempty"`
 }
 
 type TeamRequestItemDTO struct {
-	ID     string `json:"id"`
-	UserID string `json:"userId"`
-	TeamID string `json:"teamId"`
+	ID         string                   `json:"id"`
+	UserID     string                   `json:"userId"`
+	TeamID     string                   `json:"teamId"`
+	Type       entity.TeamRequestType   `json:"type"`
+	InvitedBy  string                   `json:"invitedBy,omitempty"`
+	Message    string                   `json:"message,omitempty"`
+	Status     entity.TeamRequestStatus `json:"status"`
+	ReviewerID string                   `json:"reviewerId,omitempty"`
+	// CreatedAt and UpdatedAt are left out for requests stored before they existed
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	ExpiresAt time.Time  `json:"expiresAt"`
 }
 
 type TeamRequestsResponseDTO struct {
@@ -18,11 +32,30 @@ type TeamRequestsResponseDTO struct {
 }
 
 func NewTeamRequestItemDTO(req *entity.TeamRequest) *TeamRequestItemDTO {
+	requestType := req.Type
+	if requestType == "" {
+		requestType = entity.TeamRequestJoin
+	}
 	return &TeamRequestItemDTO{
-		ID:     req.Id,
-		UserID: req.UserID,
-		TeamID: req.TeamID,
+		ID:         req.Id,
+		UserID:     req.UserID,
+		TeamID:     req.TeamID,
+		Type:       requestType,
+		InvitedBy:  req.InvitedBy,
+		Message:    req.Message,
+		Status:     req.CurrentStatus(time.Now().UTC()),
+		ReviewerID: req.ReviewerID,
+		CreatedAt:  optionalTime(req.CreatedAt),
+		UpdatedAt:  optionalTime(req.UpdatedAt),
+		ExpiresAt:  req.ExpiresAt,
+	}
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
 	}
+	return &t
 }
 
 func NewTeamRequestsResponseDTO(reqs []*entity.TeamRequest) *TeamRequestsResponseDTO {
@@ -32,3 +65,46 @@ func NewTeamRequestsResponseDTO(reqs []*entity.TeamRequest) *TeamRequestsRespons
 	}
 	return &TeamRequestsResponseDTO{Requests: items}
 }
+
+// TeamInviteRequest names the user to invite by exactly one of their ID, username or email
+type TeamInviteRequest struct {
+	UserID   string `json:"userId,omitempty"`
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+}
+
+// TeamInviteLinkRequest sets how long an invite link works and how many users may join through it
+type TeamInviteLinkRequest struct {
+	// ExpiresInHours defaults to 168 (one week)
+	ExpiresInHours int `json:"expiresInHours,omitempty"`
+	// MaxUses of 0 means no limit
+	MaxUses int `json:"maxUses,omitempty"`
+}
+
+type TeamInviteLinkDTO struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	TeamID    string    `json:"teamId"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	MaxUses   int       `json:"maxUses"`
+	Uses      int       `json:"uses"`
+}
+
+type TeamInviteLinksResponseDTO struct {
+	Links []TeamInviteLinkDTO `json:"links"`
+}
+
+func NewTeamInviteLinkDTO(link *entity.TeamInviteLink, url string) *TeamInviteLinkDTO {
+	return &TeamInviteLinkDTO{
+		Token:     link.ID,
+		URL:       url,
+		TeamID:    link.TeamID,
+		CreatedBy: link.CreatedBy,
+		CreatedAt: link.CreatedAt,
+		ExpiresAt: link.ExpiresAt,
+		MaxUses:   link.MaxUses,
+		Uses:      link.Uses,
+	}
+}
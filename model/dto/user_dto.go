@@ -59,10 +59,17 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	AccessToken string       `json:"accessToken"`
-	TokenType   string       `json:"tokenType"`
-	ExpiresIn   string       `json:"expiresIn"`
-	User        UserResponse `json:"user"`
+	AccessToken      string       `json:"accessToken"`
+	TokenType        string       `json:"tokenType"`
+	ExpiresIn        string       `json:"expiresIn"`
+	RefreshToken     string       `json:"refreshToken,omitempty"`
+	RefreshExpiresIn string       `json:"refreshExpiresIn,omitempty"`
+	User             UserResponse `json:"user"`
+}
+
+// RefreshTokenRequest exchanges a refresh token for a new access/refresh token pair
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
 }
 
 // UserResponse is a safe representation of the user returned to clients (no password).
@@ -72,6 +79,7 @@ type UserResponse struct {
 	LastName         string                   `json:"lastname"`
 	Username         string                   `json:"username"`
 	Email            string                   `json:"email"`
+	EmailVerified    bool                     `json:"emailVerified"`
 	TopicsOfInterest *[]model.TopicOfInterest `json:"topicsOfInterest,omitempty"`
 	TeamsIds         *[]string                `json:"teams,omitempty"`
 	Statistics       *model.Statistics        `json:"statistics,omitempty"`
@@ -80,11 +88,12 @@ type UserResponse struct {
 // NewUserResponse converts an entity.User to a safe UserResponse (omits password).
 func NewUserResponse(u *entity.User) UserResponse {
 	resp := UserResponse{
-		ID:        u.ID,
-		FirstName: u.FirstName,
-		LastName:  u.LastName,
-		Username:  u.Username,
-		Email:     u.Email,
+		ID:            u.ID,
+		FirstName:     u.FirstName,
+		LastName:      u.LastName,
+		Username:      u.Username,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerified,
 	}
 	if u.TopicsOfInterest != nil {
 		resp.TopicsOfInterest = u.TopicsOfInterest
@@ -163,6 +172,22 @@ type UserPasswordRequestDTO struct {
 	NewPassword string `json:"newPassword"`
 }
 
+// VerifyEmailRequest confirms an email address with the token that was mailed to it
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPasswordRequest asks for a password reset link to be sent to the given email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// ResetPasswordRequest sets a new password using a mailed password reset token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
 type SenderDTO struct {
 	ID               string                   `json:"id"`
 	FirstName        string                   `json:"firstname,omitempty"`
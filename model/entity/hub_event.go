@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// HubEvent is one real-time event sent to a user. Seq numbers the user's events from 1 without gaps,
+// so a client that reconnects can ask for everything after the last one it received.
+type HubEvent struct {
+	UserID    string          `json:"userId"`
+	Seq       int64           `json:"seq"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+func NewHubEvent(userID string, seq int64, data json.RawMessage) *HubEvent {
+	return &HubEvent{
+		UserID:    userID,
+		Seq:       seq,
+		Data:      data,
+		CreatedAt: time.Now().UTC(),
+	}
+}
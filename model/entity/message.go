@@ -1,13 +1,18 @@
 package entity
 
 import (
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const (
 	BadConversationKey = "bad conversation key"
+	BadMessageCursor   = "bad message cursor"
 )
 
 type Message struct {
@@ -17,10 +22,89 @@ type Message struct {
 	ConversationKey string    `json:"convKey,omitempty"`
 	TeamID          string    `json:"teamId,omitempty"`
 	TextContent     string    `json:"textContent"`
+	// ThreadKey orders the message within its conversation or team, see MessageThreadKey
+	ThreadKey string `json:"threadKey,omitempty"`
+	// EditHistory holds the previous versions of the text, oldest first
+	EditHistory []MessageEdit `json:"editHistory,omitempty"`
+	EditedAt    *time.Time    `json:"editedAt,omitempty"`
+	// DeletedAt marks a tombstone: the text and history are gone, the message keeps its place in the thread
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	DeletedBy string     `json:"deletedBy,omitempty"`
+	// ParentID is set on thread replies, which are listed under their parent instead of in the conversation or team
+	ParentID string `json:"parentId,omitempty"`
+	// ReplyTo quotes the message this one answers, as it read when the answer was sent
+	ReplyTo *MessageQuote `json:"replyTo,omitempty"`
+	// Reactions maps each emoji to the users who reacted with it and when.
+	// Both levels are maps so a reaction is set or removed with a single path update.
+	Reactions map[string]map[string]time.Time `json:"reactions,omitempty"`
+	// Replies maps the IDs of the thread replies to when they were sent, see ParentID
+	Replies map[string]time.Time `json:"replies,omitempty"`
+	// Attachments describe files shared in the message's conversation or team; the content stays in the File
+	Attachments []MessageAttachment `json:"attachments,omitempty"`
+}
+
+// MessageAttachment is the metadata of an attached File, copied when the message is sent
+type MessageAttachment struct {
+	FileID    string `json:"fileId"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Extension string `json:"extension"`
+	Size      int64  `json:"size"`
+}
+
+func NewMessageAttachment(file *File) MessageAttachment {
+	return MessageAttachment{
+		FileID:    file.ID,
+		Name:      file.Name,
+		Type:      file.Type,
+		Extension: file.Extension,
+		Size:      file.Size,
+	}
+}
+
+// FileContext returns the file context type and ID that files attached to the message must have
+func (m *Message) FileContext() (string, string) {
+	if m.TeamID != "" {
+		return FileContextTeam, m.TeamID
+	}
+	return FileContextChat, m.ConversationKey
+}
+
+// MessageQuotePreviewLength is how many characters of the quoted text a MessageQuote keeps
+const MessageQuotePreviewLength = 100
+
+// MessageQuote is the preview of a message that another one replies to
+type MessageQuote struct {
+	MessageID string `json:"messageId"`
+	SenderID  string `json:"senderId"`
+	Preview   string `json:"preview"`
+}
+
+func NewMessageQuote(message *Message) *MessageQuote {
+	preview := []rune(message.TextContent)
+	if len(preview) > MessageQuotePreviewLength {
+		preview = preview[:MessageQuotePreviewLength]
+	}
+	return &MessageQuote{
+		MessageID: message.ID,
+		SenderID:  message.SenderID,
+		Preview:   string(preview),
+	}
+}
+
+// MessageEdit is a replaced version of a message's text
+type MessageEdit struct {
+	TextContent string    `json:"textContent"`
+	EditedAt    time.Time `json:"editedAt"`
+	EditedBy    string    `json:"editedBy"`
+}
+
+func (m *Message) IsDeleted() bool {
+	return m.DeletedAt != nil
 }
 
 func NewMessage(id, senderId, convKey, teamId, textContent string) *Message {
-	return &Message{
+	message := &Message{
 		ID:              id,
 		SenderID:        senderId,
 		SentAt:          time.Now().UTC(),
@@ -28,6 +112,113 @@ func NewMessage(id, senderId, convKey, teamId, textContent string) *Message {
 		TeamID:          teamId,
 		TextContent:     textContent,
 	}
+	message.ThreadKey = MessageThreadKey(message.Thread(), message.Cursor())
+	return message
+}
+
+// NewReply creates a thread reply under parent, in the parent's conversation or team
+func NewReply(id, senderId string, parent *Message, textContent string) *Message {
+	message := NewMessage(id, senderId, parent.ConversationKey, parent.TeamID, textContent)
+	message.ParentID = parent.ID
+	message.ThreadKey = MessageThreadKey(message.Thread(), message.Cursor())
+	return message
+}
+
+// HasReacted reports whether the user reacted to the message with emoji
+func (m *Message) HasReacted(userID, emoji string) bool {
+	_, ok := m.Reactions[emoji][userID]
+	return ok
+}
+
+// ReactionEmojiCount returns how many different emojis the message has reactions with
+func (m *Message) ReactionEmojiCount() int {
+	count := 0
+	for _, users := range m.Reactions {
+		if len(users) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// LastReplyAt returns when the latest thread reply was sent, nil without replies
+func (m *Message) LastReplyAt() *time.Time {
+	var last *time.Time
+	for _, sentAt := range m.Replies {
+		if last == nil || sentAt.After(*last) {
+			last = &sentAt
+		}
+	}
+	return last
+}
+
+// Thread names the conversation or team the message belongs to, or the parent's thread for a reply
+func (m *Message) Thread() string {
+	if m.ParentID != "" {
+		return ReplyThread(m.ParentID)
+	}
+	if m.TeamID != "" {
+		return TeamThread(m.TeamID)
+	}
+	return ConversationThread(m.ConversationKey)
+}
+
+func ConversationThread(convKey string) string {
+	return "c:" + convKey
+}
+
+func TeamThread(teamId string) string {
+	return "t:" + teamId
+}
+
+func ReplyThread(parentId string) string {
+	return "r:" + parentId
+}
+
+// Cursor returns the position of the message in its thread
+func (m *Message) Cursor() MessageCursor {
+	return MessageCursor{SentAt: m.SentAt, ID: m.ID}
+}
+
+// MessageThreadKey builds a key that sorts messages of one thread by SentAt, then ID, as plain strings.
+// Firebase can only order by a single child, so range queries over a thread use this key.
+func MessageThreadKey(thread string, cursor MessageCursor) string {
+	return fmt.Sprintf("%s|%020d|%s", thread, cursor.SentAt.UnixNano(), cursor.ID)
+}
+
+// MessageCursor is a position in a thread. Messages are ordered by SentAt, then by ID.
+type MessageCursor struct {
+	SentAt time.Time
+	ID     string
+}
+
+// Less reports whether c comes before other
+func (c MessageCursor) Less(other MessageCursor) bool {
+	if !c.SentAt.Equal(other.SentAt) {
+		return c.SentAt.Before(other.SentAt)
+	}
+	return c.ID < other.ID
+}
+
+// Encode returns the opaque form of the cursor handed to API clients
+func (c MessageCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.SentAt.UnixNano(), 10) + ":" + c.ID))
+}
+
+func ParseMessageCursor(encoded string) (*MessageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New(BadMessageCursor)
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return nil, errors.New(BadMessageCursor)
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, errors.New(BadMessageCursor)
+	}
+	return &MessageCursor{SentAt: time.Unix(0, unixNano).UTC(), ID: id}, nil
 }
 
 func GetConversationKey(user1Id, user2Id string) string {
@@ -37,6 +228,20 @@ func GetConversationKey(user1Id, user2Id string) string {
 	return user2Id + "_" + user1Id
 }
 
+// InConversation reports whether the user is one of the two users of the conversation key
+func InConversation(conversationKey, userID string) bool {
+	return slices.Contains(ConversationUserIDs(conversationKey), userID)
+}
+
+// ConversationUserIDs returns the two users of a conversation key, or nil when the key is malformed
+func ConversationUserIDs(conversationKey string) []string {
+	parts := strings.Split(conversationKey, "_")
+	if len(parts) != 2 {
+		return nil
+	}
+	return parts
+}
+
 func GetReceiverIdFromKey(senderId, conversationKey string) (string, error) {
 	parts := strings.Split(conversationKey, "_")
 	if len(parts) != 2 {
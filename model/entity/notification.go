@@ -0,0 +1,68 @@
+package entity
+
+import "time"
+
+// NotificationType tells apart the things a user is notified about
+type NotificationType string
+
+const (
+	NotificationFriendRequest         NotificationType = "friend_request"
+	NotificationFriendRequestAccepted NotificationType = "friend_request_accepted"
+	NotificationTeamRequest           NotificationType = "team_request"
+	NotificationTeamRequestAccepted   NotificationType = "team_request_accepted"
+	NotificationTeamInvite            NotificationType = "team_invite"
+	NotificationEventInvite           NotificationType = "event_invite"
+	NotificationEventRescheduled      NotificationType = "event_rescheduled"
+	NotificationMention               NotificationType = "mention"
+)
+
+func (t NotificationType) IsValid() bool {
+	switch t {
+	case NotificationFriendRequest, NotificationFriendRequestAccepted, NotificationTeamRequest,
+		NotificationTeamRequestAccepted, NotificationTeamInvite, NotificationEventInvite,
+		NotificationEventRescheduled, NotificationMention:
+		return true
+	}
+	return false
+}
+
+// Notification tells one user that something happened. ActorID is the user who caused it, TeamID the team
+// it happened in, if any, and ResourceID the request, event or message it is about.
+type Notification struct {
+	ID         string           `json:"id"`
+	UserID     string           `json:"userId"`
+	Type       NotificationType `json:"type"`
+	ActorID    string           `json:"actorId,omitempty"`
+	TeamID     string           `json:"teamId,omitempty"`
+	ResourceID string           `json:"resourceId,omitempty"`
+	Text       string           `json:"text"`
+	CreatedAt  time.Time        `json:"createdAt"`
+	// ReadAt is nil while the notification is unread
+	ReadAt *time.Time `json:"readAt,omitempty"`
+	// UnreadBy is the UserID while the notification is unread, so stores that cannot count can query only unread ones
+	UnreadBy string `json:"unreadBy,omitempty"`
+}
+
+func NewNotification(id, userID string, template Notification) *Notification {
+	notification := template
+	notification.ID = id
+	notification.UserID = userID
+	notification.CreatedAt = time.Now().UTC()
+	notification.ReadAt = nil
+	notification.UnreadBy = userID
+	return &notification
+}
+
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// MarkRead records when the notification was read and reports whether it was unread before
+func (n *Notification) MarkRead(now time.Time) bool {
+	if n.IsRead() {
+		return false
+	}
+	n.ReadAt = &now
+	n.UnreadBy = ""
+	return true
+}
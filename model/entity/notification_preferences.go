@@ -0,0 +1,112 @@
+package entity
+
+import (
+	"slices"
+	"time"
+
+	// quiet hours are read in the user's timezone, which must load on hosts without a zoneinfo database
+	_ "time/tzdata"
+)
+
+// NotificationChannel is a way a notification reaches the user
+type NotificationChannel string
+
+const (
+	// ChannelInApp keeps the notification in the user's list and pushes it to their open sockets
+	ChannelInApp NotificationChannel = "in_app"
+	ChannelEmail NotificationChannel = "email"
+	ChannelPush  NotificationChannel = "push"
+)
+
+func (c NotificationChannel) IsValid() bool {
+	switch c {
+	case ChannelInApp, ChannelEmail, ChannelPush:
+		return true
+	}
+	return false
+}
+
+// NotificationTypes and NotificationChannels list every type and channel, in the order settings are shown
+var (
+	NotificationTypes = []NotificationType{
+		NotificationFriendRequest, NotificationFriendRequestAccepted, NotificationTeamRequest,
+		NotificationTeamRequestAccepted, NotificationTeamInvite, NotificationEventInvite,
+		NotificationEventRescheduled, NotificationMention,
+	}
+	NotificationChannels = []NotificationChannel{ChannelInApp, ChannelEmail, ChannelPush}
+)
+
+// DefaultChannelEnabled is whether a channel is on for the types the user did not set: in-app and push are,
+// email is not
+func DefaultChannelEnabled(channel NotificationChannel) bool {
+	return channel != ChannelEmail
+}
+
+// QuietHours is a daily window, in "15:04" form, during which nothing is pushed or mailed to the user,
+// then or afterwards. A window whose end comes before its start runs past midnight.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// NotificationPreferences are the notification settings of one user. Users who never saved any have the defaults.
+type NotificationPreferences struct {
+	UserID string `json:"userId"`
+	// Channels overrides the default of a channel for a type; types and channels left out keep their default
+	Channels     map[NotificationType]map[NotificationChannel]bool `json:"channels,omitempty"`
+	MutedTeamIDs []string                                          `json:"mutedTeamIds,omitempty"`
+	QuietHours   *QuietHours                                       `json:"quietHours,omitempty"`
+	// Timezone is the IANA name quiet hours are read in; empty means UTC
+	Timezone  string    `json:"timezone,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func NewNotificationPreferences(userID string) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:   userID,
+		Channels: map[NotificationType]map[NotificationChannel]bool{},
+	}
+}
+
+// Enabled reports whether notifications of a type go out through a channel
+func (p *NotificationPreferences) Enabled(notificationType NotificationType, channel NotificationChannel) bool {
+	if enabled, ok := p.Channels[notificationType][channel]; ok {
+		return enabled
+	}
+	return DefaultChannelEnabled(channel)
+}
+
+func (p *NotificationPreferences) IsTeamMuted(teamID string) bool {
+	return teamID != "" && slices.Contains(p.MutedTeamIDs, teamID)
+}
+
+// InQuietHours reports whether now falls in the user's quiet hours, read in their timezone
+func (p *NotificationPreferences) InQuietHours(now time.Time) bool {
+	if p.QuietHours == nil {
+		return false
+	}
+	start, errStart := time.Parse("15:04", p.QuietHours.Start)
+	end, errEnd := time.Parse("15:04", p.QuietHours.End)
+	location, errLocation := p.location()
+	if errStart != nil || errEnd != nil || errLocation != nil {
+		return false
+	}
+
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from <= to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// location loads the user's timezone. Empty means UTC, and so does "Local", which was accepted at first
+// but would be the timezone of the server.
+func (p *NotificationPreferences) location() (*time.Location, error) {
+	if p.Timezone == "Local" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(p.Timezone)
+}
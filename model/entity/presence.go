@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+type PresenceStatus string
+
+const (
+	PresenceOnline  PresenceStatus = "online"
+	PresenceIdle    PresenceStatus = "idle"
+	PresenceOffline PresenceStatus = "offline"
+)
+
+// Presence is whether a user is connected to the real-time hub. LastSeenAt is the last time
+// their status changed, so for an offline user it is when their last connection closed.
+type Presence struct {
+	UserID     string         `json:"userId"`
+	Status     PresenceStatus `json:"status"`
+	LastSeenAt time.Time      `json:"lastSeenAt"`
+}
+
+func NewPresence(userID string, status PresenceStatus) *Presence {
+	return &Presence{
+		UserID:     userID,
+		Status:     status,
+		LastSeenAt: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// ReadCursor is how far a user has read one conversation or team thread, see Message.Thread.
+// A cursor without a message means the user takes part in the thread but has read nothing yet.
+type ReadCursor struct {
+	UserID            string    `json:"userId"`
+	Thread            string    `json:"thread"`
+	LastReadMessageID string    `json:"lastReadMessageId,omitempty"`
+	LastReadSentAt    time.Time `json:"lastReadSentAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+func NewReadCursor(userID, thread string) *ReadCursor {
+	return &ReadCursor{
+		UserID:    userID,
+		Thread:    thread,
+		UpdatedAt: time.Now().UTC(),
+	}
+}
+
+// Position returns the last message read, nil when nothing was read yet
+func (r *ReadCursor) Position() *MessageCursor {
+	if r.LastReadMessageID == "" {
+		return nil
+	}
+	return &MessageCursor{SentAt: r.LastReadSentAt, ID: r.LastReadMessageID}
+}
+
+// Advance moves the cursor to message and reports whether it moved; reading an older message changes nothing
+func (r *ReadCursor) Advance(message *Message) bool {
+	if position := r.Position(); position != nil && !position.Less(message.Cursor()) {
+		return false
+	}
+	r.LastReadMessageID = message.ID
+	r.LastReadSentAt = message.SentAt
+	r.UpdatedAt = time.Now().UTC()
+	return true
+}
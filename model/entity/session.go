@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// Session is a server-side login session. The refresh token handed to the client
+// is never stored, only its hash, and it is rotated on every refresh.
+type Session struct {
+	ID               string    `json:"id"`
+	UserID           string    `json:"userId"`
+	RefreshTokenHash string    `json:"refreshTokenHash"`
+	CreatedAt        time.Time `json:"createdAt"`
+	LastUsedAt       time.Time `json:"lastUsedAt"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	Revoked          bool      `json:"revoked"`
+}
+
+func NewSession(id, userId, refreshTokenHash string, expiresAt time.Time) *Session {
+	now := time.Now().UTC()
+	return &Session{
+		ID:               id,
+		UserID:           userId,
+		RefreshTokenHash: refreshTokenHash,
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		ExpiresAt:        expiresAt,
+		Revoked:          false,
+	}
+}
+
+func (s *Session) IsActive() bool {
+	return !s.Revoked && time.Now().UTC().Before(s.ExpiresAt)
+}
+
+// RevokedToken marks a single access token (by its jti claim) as no longer valid.
+type RevokedToken struct {
+	JTI       string    `json:"jti"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func NewRevokedToken(jti string, expiresAt time.Time) *RevokedToken {
+	return &RevokedToken{
+		JTI:       jti,
+		ExpiresAt: expiresAt,
+	}
+}
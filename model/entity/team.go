@@ -2,12 +2,29 @@ package entity
 
 import "github.com/SerbanEduard/ProiectColectivBackEnd/model"
 
+type TeamRole string
+
+const (
+	TeamRoleOwner  TeamRole = "owner"
+	TeamRoleAdmin  TeamRole = "admin"
+	TeamRoleMember TeamRole = "member"
+)
+
+func (r TeamRole) IsValid() bool {
+	switch r {
+	case TeamRoleOwner, TeamRoleAdmin, TeamRoleMember:
+		return true
+	}
+	return false
+}
+
 type Team struct {
 	Id          string                `json:"id"`
 	Name        string                `json:"name"`
 	Description string                `json:"description"`
 	IsPublic    bool                  `json:"ispublic"`
 	UsersIds    []string              `json:"users"`
+	Roles       map[string]TeamRole   `json:"roles,omitempty"`
 	TeamTopic   model.TopicOfInterest `json:"teamtopic"`
 }
 
@@ -23,6 +40,56 @@ func NewTeam(id, name, desc string, isPublic bool, Users []string, topic model.T
 			}
 			return Users
 		}(),
+		Roles:     map[string]TeamRole{},
 		TeamTopic: topic,
 	}
 }
+
+// IsMember reports whether the user is part of the team
+func (t *Team) IsMember(userID string) bool {
+	for _, id := range t.UsersIds {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// VisibleTo reports whether the user may see the team: public teams are visible to everyone,
+// private ones only to their members
+func (t *Team) VisibleTo(userID string) bool {
+	return t.IsPublic || t.IsMember(userID)
+}
+
+// RoleOf returns the role of a member, or an empty role for non-members.
+// Members without an explicit role are plain members. Teams created before roles
+// existed have no owner recorded, so their first member (the creator) is the owner.
+func (t *Team) RoleOf(userID string) TeamRole {
+	if !t.IsMember(userID) {
+		return ""
+	}
+	if role, ok := t.Roles[userID]; ok {
+		return role
+	}
+	if t.OwnerID() == "" && len(t.UsersIds) > 0 && t.UsersIds[0] == userID {
+		return TeamRoleOwner
+	}
+	return TeamRoleMember
+}
+
+// OwnerID returns the member recorded as owner, if any
+func (t *Team) OwnerID() string {
+	for userID, role := range t.Roles {
+		if role == TeamRoleOwner {
+			return userID
+		}
+	}
+	return ""
+}
+
+func (t *Team) SetRole(userID string, role TeamRole) {
+	if t.Roles == nil {
+		t.Roles = map[string]TeamRole{}
+	}
+	t.Roles[userID] = role
+}
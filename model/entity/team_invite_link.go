@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// TeamInviteLink lets anyone holding its ID join a team until it expires or runs out of uses.
+// The ID is random and long enough to act as the secret in the shared link.
+type TeamInviteLink struct {
+	ID        string    `json:"id"`
+	TeamID    string    `json:"teamId"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	// MaxUses is how many users may join through the link; 0 means no limit
+	MaxUses int `json:"maxUses"`
+	Uses    int `json:"uses"`
+}
+
+func NewTeamInviteLink(id, teamId, createdBy string, expiresAt time.Time, maxUses int) *TeamInviteLink {
+	return &TeamInviteLink{
+		ID:        id,
+		TeamID:    teamId,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: expiresAt,
+		MaxUses:   maxUses,
+		Uses:      0,
+	}
+}
+
+func (l *TeamInviteLink) IsUsable(now time.Time) bool {
+	return now.Before(l.ExpiresAt) && (l.MaxUses == 0 || l.Uses < l.MaxUses)
+}
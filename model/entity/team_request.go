@@ -1,15 +1,112 @@
 package entity
 
+import "time"
+
+// TeamRequestType tells apart a user asking to join a team from a team inviting a user
+type TeamRequestType string
+
+const (
+	TeamRequestJoin   TeamRequestType = "join"
+	TeamRequestInvite TeamRequestType = "invite"
+)
+
+type TeamRequestStatus string
+
+const (
+	TeamRequestPending   TeamRequestStatus = "pending"
+	TeamRequestAccepted  TeamRequestStatus = "accepted"
+	TeamRequestRejected  TeamRequestStatus = "rejected"
+	TeamRequestWithdrawn TeamRequestStatus = "withdrawn"
+	TeamRequestExpired   TeamRequestStatus = "expired"
+)
+
+func (s TeamRequestStatus) IsValid() bool {
+	switch s {
+	case TeamRequestPending, TeamRequestAccepted, TeamRequestRejected, TeamRequestWithdrawn, TeamRequestExpired:
+		return true
+	}
+	return false
+}
+
+// TeamRequest links a user to a team they may join. Requests stored before invitations existed have no type
+// and are join requests; those stored before statuses existed have no status and are pending, since answered
+// requests used to be deleted.
 type TeamRequest struct {
-	Id     string `json:"id"`
-	UserID string `json:"userid"`
-	TeamID string `json:"teamid"`
+	Id        string          `json:"id"`
+	UserID    string          `json:"userid"`
+	TeamID    string          `json:"teamid"`
+	Type      TeamRequestType `json:"type,omitempty"`
+	InvitedBy string          `json:"invitedby,omitempty"`
+	// Message is an optional note from the requester to the team admins
+	Message string            `json:"message,omitempty"`
+	Status  TeamRequestStatus `json:"status,omitempty"`
+	// ReviewerID is the user who accepted, rejected or withdrew the request
+	ReviewerID string    `json:"reviewerid,omitempty"`
+	CreatedAt  time.Time `json:"createdat"`
+	UpdatedAt  time.Time `json:"updatedat"`
+	// ExpiresAt is when a request still pending stops being valid; zero for requests stored before it existed
+	// until cmd/backfill stamps them, and those do not expire meanwhile
+	ExpiresAt time.Time `json:"expiresat"`
 }
 
-func NewTeamRequest(id, userId, teamId string) *TeamRequest {
+// TeamRequestTTL is how long a join request or invitation waits for an answer before it expires
+const TeamRequestTTL = 30 * 24 * time.Hour
+
+func NewTeamRequest(id, userId, teamId, message string, expiresAt time.Time) *TeamRequest {
+	now := time.Now().UTC()
 	return &TeamRequest{
-		Id:     id,
-		UserID: userId,
-		TeamID: teamId,
+		Id:        id,
+		UserID:    userId,
+		TeamID:    teamId,
+		Type:      TeamRequestJoin,
+		Message:   message,
+		Status:    TeamRequestPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+}
+
+func NewTeamInvite(id, userId, teamId, invitedBy string, expiresAt time.Time) *TeamRequest {
+	invite := NewTeamRequest(id, userId, teamId, "", expiresAt)
+	invite.Type = TeamRequestInvite
+	invite.InvitedBy = invitedBy
+	return invite
+}
+
+func (r *TeamRequest) IsInvite() bool {
+	return r.Type == TeamRequestInvite
+}
+
+// CurrentStatus is the status of the request at now, counting a pending request past ExpiresAt as expired
+func (r *TeamRequest) CurrentStatus(now time.Time) TeamRequestStatus {
+	status := r.Status
+	if status == "" {
+		status = TeamRequestPending
+	}
+	if status == TeamRequestPending && !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt) {
+		return TeamRequestExpired
+	}
+	return status
+}
+
+func (r *TeamRequest) IsPending(now time.Time) bool {
+	return r.CurrentStatus(now) == TeamRequestPending
+}
+
+// Expire stores the expired status of a pending request past ExpiresAt and reports whether it changed
+func (r *TeamRequest) Expire(now time.Time) bool {
+	if r.Status == TeamRequestExpired || r.CurrentStatus(now) != TeamRequestExpired {
+		return false
 	}
+	r.Status = TeamRequestExpired
+	r.UpdatedAt = now
+	return true
+}
+
+// Resolve moves the request to its final status, recording who made the decision
+func (r *TeamRequest) Resolve(status TeamRequestStatus, reviewerID string, now time.Time) {
+	r.Status = status
+	r.ReviewerID = reviewerID
+	r.UpdatedAt = now
 }
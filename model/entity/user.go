@@ -9,9 +9,13 @@ type User struct {
 	Username         string                   `json:"username"`
 	Email            string                   `json:"email"`
 	Password         string                   `json:"password"`
+	EmailVerified    bool                     `json:"emailVerified"`
 	TopicsOfInterest *[]model.TopicOfInterest `json:"topicsOfInterest,omitempty"`
 	TeamsIds         *[]string                `json:"teams,omitempty"`
 	Statistics       *model.Statistics        `json:"statistics,omitempty"`
+	// EmailVerificationRequired is set for users who signed up once emails were verified and for users who
+	// changed their email since; the others signed up before and are verified by cmd/backfill
+	EmailVerificationRequired bool `json:"emailVerificationRequired,omitempty"`
 }
 
 func NewUser(id, firstName, lastName, username, email, password string, topicsOfInterest *[]model.TopicOfInterest) *User {
@@ -23,5 +27,7 @@ func NewUser(id, firstName, lastName, username, email, password string, topicsOf
 		Email:            email,
 		Password:         password,
 		TopicsOfInterest: topicsOfInterest,
+
+		EmailVerificationRequired: true,
 	}
 }
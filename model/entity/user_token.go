@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+type TokenPurpose string
+
+const (
+	PurposeEmailVerification TokenPurpose = "email_verification"
+	PurposePasswordReset     TokenPurpose = "password_reset"
+)
+
+// UserToken is a single-use token mailed to a user. Only the hash of the token is stored,
+// and it doubles as the ID, so a token can be looked up without ever persisting it.
+type UserToken struct {
+	ID     string `json:"id"`
+	UserID string `json:"userId"`
+	// Email is the address the token was mailed to; it only works while the user still has that address
+	Email     string       `json:"email"`
+	Purpose   TokenPurpose `json:"purpose"`
+	CreatedAt time.Time    `json:"createdAt"`
+	ExpiresAt time.Time    `json:"expiresAt"`
+	Used      bool         `json:"used"`
+}
+
+func NewUserToken(tokenHash, userId, email string, purpose TokenPurpose, expiresAt time.Time) *UserToken {
+	return &UserToken{
+		ID:        tokenHash,
+		UserID:    userId,
+		Email:     email,
+		Purpose:   purpose,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: expiresAt,
+		Used:      false,
+	}
+}
+
+// IsFor reports whether the token was mailed to the user's current email
+func (t *UserToken) IsFor(user *User) bool {
+	return t.UserID == user.ID && t.Email != "" && strings.EqualFold(t.Email, user.Email)
+}
+
+func (t *UserToken) IsUsable(purpose TokenPurpose) bool {
+	return !t.Used && t.Purpose == purpose && time.Now().UTC().Before(t.ExpiresAt)
+}
@@ -0,0 +1,58 @@
+package persistence
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+)
+
+// localStore is set when the memory backend is active; the repository constructors then
+// return local implementations instead of the Firebase ones
+var localStore *LocalStore
+
+// InitDatabase connects the backend selected by DB_BACKEND. It must run before any repository is created.
+func InitDatabase() error {
+	config.LoadEnv()
+	dbConfig := config.GetDBConfig()
+
+	switch dbConfig.Backend {
+	case config.DBBackendFirebase:
+		config.InitFirebase()
+		UseLocalStore(nil)
+	case config.DBBackendMemory:
+		store, err := NewLocalStore(dbConfig.FilePath)
+		if err != nil {
+			return fmt.Errorf("open local store: %w", err)
+		}
+		UseLocalStore(store)
+		if dbConfig.FilePath != "" {
+			log.Printf("Using in-memory database persisted to %s", dbConfig.FilePath)
+		} else {
+			log.Println("Using in-memory database, data is lost on restart")
+		}
+	case config.DBBackendPostgres:
+		db, err := OpenPostgres(dbConfig.DatabaseURL)
+		if err != nil {
+			return fmt.Errorf("open postgres: %w", err)
+		}
+		UsePostgres(db)
+		log.Println("Using PostgreSQL database")
+	default:
+		return fmt.Errorf("unknown DB_BACKEND %q", dbConfig.Backend)
+	}
+	return nil
+}
+
+// UseLocalStore makes repositories created from now on use store. Passing nil switches back to Firebase.
+func UseLocalStore(store *LocalStore) {
+	sqlDB = nil
+	localStore = store
+}
+
+// UsePostgres makes repositories created from now on use db. Passing nil switches back to Firebase.
+func UsePostgres(db *sql.DB) {
+	localStore = nil
+	sqlDB = db
+}
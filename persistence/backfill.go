@@ -0,0 +1,346 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"firebase.google.com/go/v4/db"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+const defaultBackfillBatchSize = 500
+
+// errNothingToPatch aborts a patch whose document is gone or already has the fields
+var errNothingToPatch = errors.New("nothing to patch")
+
+// PatchFunc returns the top level fields a stored document lacks, or nil when it is up to date
+type PatchFunc func(data json.RawMessage) (map[string]interface{}, error)
+
+// DocumentPatcher sets the fields returned by patch on one stored document. patch runs again on the document
+// as stored at write time, so a change made since it was read is neither overwritten nor patched twice.
+// Patched fields are never ones a backend copies into its own columns.
+type DocumentPatcher interface {
+	Patch(collection, key string, patch PatchFunc) (bool, error)
+}
+
+// backfill fills in a field that documents written by older versions lack. Its patch depends only on the
+// document, so it gives the same result on every backend and in every run, apart from the expiry of legacy
+// team requests, which counts from when they are patched.
+type backfill struct {
+	collection string
+	name       string
+	patch      PatchFunc
+}
+
+// backfills lists every backfill, in the order they run
+var backfills = []backfill{
+	{messagesCollection, "message thread keys", patchMessageThreadKey},
+	{usersCollection, "verified legacy emails", patchLegacyEmailVerified},
+	{notificationsCollection, "unread notification keys", patchNotificationUnreadBy},
+	{teamRequestsCollection, "team request expiry", patchTeamRequestExpiresAt},
+}
+
+// patchMessageThreadKey adds the threadKey that history pages are queried on to messages sent before it existed
+func patchMessageThreadKey(data json.RawMessage) (map[string]interface{}, error) {
+	var message entity.Message
+	if err := json.Unmarshal(data, &message); err != nil {
+		return nil, err
+	}
+	if message.ID == "" || message.ThreadKey != "" {
+		return nil, nil
+	}
+	return map[string]interface{}{threadKeyField: entity.MessageThreadKey(message.Thread(), message.Cursor())}, nil
+}
+
+// patchLegacyEmailVerified marks users who signed up before emails were verified as verified, so they keep
+// the access they had. They are told apart by the missing emailVerificationRequired marker, which signing up
+// and changing the email set, and not by emailVerified, which saving such a user stores as false.
+func patchLegacyEmailVerified(data json.RawMessage) (map[string]interface{}, error) {
+	var user entity.User
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == "" || user.EmailVerified || user.EmailVerificationRequired {
+		return nil, nil
+	}
+	return map[string]interface{}{"emailVerified": true}, nil
+}
+
+// patchNotificationUnreadBy adds the unreadBy key that unread counts are queried on to unread notifications
+// stored before it existed
+func patchNotificationUnreadBy(data json.RawMessage) (map[string]interface{}, error) {
+	var notification entity.Notification
+	if err := json.Unmarshal(data, &notification); err != nil {
+		return nil, err
+	}
+	if notification.ID == "" || notification.IsRead() || notification.UnreadBy != "" {
+		return nil, nil
+	}
+	return map[string]interface{}{"unreadBy": notification.UserID}, nil
+}
+
+// patchTeamRequestExpiresAt gives pending team requests stored before expiry existed the time a new request
+// gets to be answered, counted from now since they have no creation time either
+func patchTeamRequestExpiresAt(data json.RawMessage) (map[string]interface{}, error) {
+	var req entity.TeamRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	if req.Id == "" || !req.ExpiresAt.IsZero() || (req.Status != "" && req.Status != entity.TeamRequestPending) {
+		return nil, nil
+	}
+	return map[string]interface{}{"expiresat": time.Now().UTC().Add(entity.TeamRequestTTL)}, nil
+}
+
+// backfillDocument applies the backfills of a collection to one document, so copies made by the Migrator
+// are complete whether or not the source was backfilled
+func backfillDocument(collection string, data json.RawMessage) (json.RawMessage, error) {
+	for _, b := range backfills {
+		if b.collection != collection {
+			continue
+		}
+		fields, err := b.patch(data)
+		if err != nil || fields == nil {
+			continue
+		}
+		doc := make(map[string]interface{})
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		for field, value := range fields {
+			doc[field] = value
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+type BackfillOptions struct {
+	// BatchSize is the number of documents read per page
+	BatchSize int
+	// DryRun counts the documents that need a backfill without writing anything
+	DryRun bool
+}
+
+type BackfillReport struct {
+	Collection string `json:"collection"`
+	Name       string `json:"name"`
+	Checked    int    `json:"checked"`
+	Patched    int    `json:"patched"`
+}
+
+// Backfill runs every backfill in place on backend. Documents that are up to date are left untouched,
+// so it can run again at any time, also while the server is running.
+func Backfill(backend *Backend, options BackfillOptions) ([]BackfillReport, error) {
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBackfillBatchSize
+	}
+
+	reports := make([]BackfillReport, 0, len(backfills))
+	for _, b := range backfills {
+		report := BackfillReport{Collection: b.collection, Name: b.name}
+		afterKey := ""
+		for {
+			docs, err := backend.Reader.Page(b.collection, afterKey, options.BatchSize)
+			if err != nil {
+				return reports, fmt.Errorf("backfill %s: %w", b.name, err)
+			}
+			for _, doc := range docs {
+				report.Checked++
+				fields, err := b.patch(doc.Data)
+				if err != nil || fields == nil {
+					continue
+				}
+				if options.DryRun {
+					report.Patched++
+					continue
+				}
+				patched, err := backend.Patcher.Patch(b.collection, doc.Key, b.patch)
+				if err != nil {
+					return reports, fmt.Errorf("backfill %s/%s: %w", b.collection, doc.Key, err)
+				}
+				if patched {
+					report.Patched++
+				}
+			}
+			if len(docs) < options.BatchSize {
+				break
+			}
+			afterKey = docs[len(docs)-1].Key
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// NewDocumentPatcher returns the patcher of the active backend
+func NewDocumentPatcher() DocumentPatcher {
+	if sqlDB != nil {
+		return &PostgresPatcher{db: sqlDB}
+	}
+	if localStore != nil {
+		return &LocalPatcher{store: localStore}
+	}
+	return &FirebasePatcher{}
+}
+
+// FirebasePatcher patches in a transaction on the document, which RTDB retries if the document changes meanwhile
+type FirebasePatcher struct{}
+
+func (fp *FirebasePatcher) Patch(collection, key string, patch PatchFunc) (bool, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(collection + "/" + key)
+
+	err := ref.Transaction(ctx, func(node db.TransactionNode) (interface{}, error) {
+		var doc map[string]interface{}
+		if err := node.Unmarshal(&doc); err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			return nil, errNothingToPatch
+		}
+		fields, err := patchDocument(doc, patch)
+		if err != nil {
+			return nil, err
+		}
+		if fields == nil {
+			return nil, errNothingToPatch
+		}
+		return doc, nil
+	})
+	if errors.Is(err, errNothingToPatch) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+type LocalPatcher struct {
+	store *LocalStore
+}
+
+func (lp *LocalPatcher) Patch(collection, key string, patch PatchFunc) (bool, error) {
+	return lp.store.Patch(collection, key, patch)
+}
+
+// PostgresPatcher patches with the row locked, so concurrent writes wait for it
+type PostgresPatcher struct {
+	db *sql.DB
+}
+
+func (pp *PostgresPatcher) Patch(collection, key string, patch PatchFunc) (bool, error) {
+	mapping, ok := postgresCollections[collection]
+	if !ok {
+		return false, fmt.Errorf("no table for collection %q", collection)
+	}
+
+	patched := false
+	err := inTx(pp.db, func(tx *sql.Tx) error {
+		var data []byte
+		err := tx.QueryRow(`SELECT data FROM `+mapping.table+` WHERE `+mapping.key+` = $1 FOR UPDATE`, key).Scan(&data)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		doc := make(map[string]interface{})
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		fields, err := patchDocument(doc, patch)
+		if err != nil || fields == nil {
+			return err
+		}
+		merged, err := toJSON(doc)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE `+mapping.table+` SET data = $2 WHERE `+mapping.key+` = $1`, key, merged); err != nil {
+			return err
+		}
+		patched = true
+		return nil
+	})
+	return patched, err
+}
+
+// RepositoryPatcher patches users and teams by reading and updating them through the given repositories.
+// It is not atomic; it exists for services built on injected repositories, such as in tests.
+type RepositoryPatcher struct {
+	users UserRepositoryInterface
+	teams TeamRepositoryInterface
+}
+
+func NewRepositoryPatcher(users UserRepositoryInterface, teams TeamRepositoryInterface) *RepositoryPatcher {
+	return &RepositoryPatcher{users: users, teams: teams}
+}
+
+func (rp *RepositoryPatcher) Patch(collection, key string, patch PatchFunc) (bool, error) {
+	switch collection {
+	case usersCollection:
+		user, err := rp.users.GetByID(key)
+		if err != nil {
+			return false, err
+		}
+		var patched entity.User
+		if ok, err := patchInto(user, &patched, patch); !ok || err != nil {
+			return false, err
+		}
+		return true, rp.users.Update(&patched)
+	case teamsCollection:
+		team, err := rp.teams.GetTeamById(key)
+		if err != nil {
+			return false, err
+		}
+		var patched entity.Team
+		if ok, err := patchInto(team, &patched, patch); !ok || err != nil {
+			return false, err
+		}
+		return true, rp.teams.Update(&patched)
+	}
+	return false, fmt.Errorf("no repository for collection %q", collection)
+}
+
+// patchInto decodes current with the fields returned by patch into patched, reporting whether patch returned any
+func patchInto(current, patched interface{}, patch PatchFunc) (bool, error) {
+	doc := make(map[string]interface{})
+	data, err := json.Marshal(current)
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return false, err
+	}
+	fields, err := patchDocument(doc, patch)
+	if err != nil || fields == nil {
+		return false, err
+	}
+	if data, err = json.Marshal(doc); err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, patched)
+}
+
+// patchDocument runs patch on doc and sets the fields it returns on doc
+func patchDocument(doc map[string]interface{}, patch PatchFunc) (map[string]interface{}, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := patch(data)
+	if err != nil {
+		return nil, err
+	}
+	for field, value := range fields {
+		doc[field] = value
+	}
+	return fields, nil
+}
@@ -0,0 +1,185 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+)
+
+// Document is one stored record in the JSON form every backend keeps it in
+type Document struct {
+	Key  string
+	Data json.RawMessage
+}
+
+// CollectionReader pages through a whole collection in key order. Each backend uses its own key order,
+// which is stable between calls, so afterKey can be the last key of the previous page.
+type CollectionReader interface {
+	Page(collection, afterKey string, limit int) ([]Document, error)
+}
+
+// Collection names for code outside the package that reads or patches whole collections, such as the search index
+const (
+	UsersCollection    = usersCollection
+	MessagesCollection = messagesCollection
+	FilesCollection    = filesCollection
+	QuizzesCollection  = quizCollection
+	EventsCollection   = eventsCollection
+	TeamsCollection    = teamsCollection
+)
+
+// NewCollectionReader returns the reader of the active backend
+func NewCollectionReader() CollectionReader {
+	if sqlDB != nil {
+		return NewPostgresCollectionReader(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalCollectionReader(localStore)
+	}
+	return NewFirebaseCollectionReader()
+}
+
+// ReadCollection decodes every document of a collection into T and passes it to visit, batchSize documents per page
+func ReadCollection[T any](reader CollectionReader, collection string, batchSize int, visit func(*T)) error {
+	afterKey := ""
+	for {
+		docs, err := reader.Page(collection, afterKey, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			item := new(T)
+			if err := json.Unmarshal(doc.Data, item); err != nil {
+				return fmt.Errorf("decode %s/%s: %w", collection, doc.Key, err)
+			}
+			visit(item)
+		}
+		if len(docs) < batchSize {
+			return nil
+		}
+		afterKey = docs[len(docs)-1].Key
+	}
+}
+
+type FirebaseCollectionReader struct{}
+
+func NewFirebaseCollectionReader() *FirebaseCollectionReader {
+	return &FirebaseCollectionReader{}
+}
+
+func (fr *FirebaseCollectionReader) Page(collection, afterKey string, limit int) ([]Document, error) {
+	ctx := context.Background()
+	query := config.FirebaseDB.NewRef(collection).OrderByKey()
+	if afterKey != "" {
+		// StartAt is inclusive, so ask for one more and drop afterKey itself
+		query = query.StartAt(afterKey).LimitToFirst(limit + 1)
+	} else {
+		query = query.LimitToFirst(limit)
+	}
+
+	results, err := query.GetOrdered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]Document, 0, len(results))
+	for _, r := range results {
+		if r.Key() == afterKey {
+			continue
+		}
+		var data json.RawMessage
+		if err := r.Unmarshal(&data); err != nil {
+			return nil, err
+		}
+		docs = append(docs, Document{Key: r.Key(), Data: data})
+	}
+	if len(docs) > limit {
+		docs = docs[:limit]
+	}
+	return docs, nil
+}
+
+type LocalCollectionReader struct {
+	store *LocalStore
+}
+
+func NewLocalCollectionReader(store *LocalStore) *LocalCollectionReader {
+	return &LocalCollectionReader{store: store}
+}
+
+func (lr *LocalCollectionReader) Page(collection, afterKey string, limit int) ([]Document, error) {
+	docs := make([]Document, 0, limit)
+	for _, key := range lr.store.Keys(collection) {
+		if key <= afterKey {
+			continue
+		}
+		if len(docs) == limit {
+			break
+		}
+		var data json.RawMessage
+		found, err := lr.store.Get(collection, key, &data)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			docs = append(docs, Document{Key: key, Data: data})
+		}
+	}
+	return docs, nil
+}
+
+// postgresCollections maps each Firebase collection to its table and to the SQL expression giving its Firebase key
+var postgresCollections = map[string]struct{ table, key string }{
+	usersCollection:                   {"users", "id"},
+	teamsCollection:                   {"teams", "id"},
+	teamRequestsCollection:            {"team_requests", "id"},
+	teamInviteLinksCollection:         {"team_invite_links", "id"},
+	friendRequestsPath:                {"friend_requests", "from_user_id || ':' || to_user_id"},
+	messagesCollection:                {"messages", "id"},
+	quizCollection:                    {"quizzes", "id"},
+	eventsCollection:                  {"events", "id"},
+	filesCollection:                   {"files", "id"},
+	sessionsCollection:                {"sessions", "id"},
+	revokedTokensCollection:           {"revoked_tokens", "jti"},
+	userTokensCollection:              {"user_tokens", "id"},
+	presenceCollection:                {"presence", "user_id"},
+	notificationsCollection:           {"notifications", "id"},
+	notificationPreferencesCollection: {"notification_preferences", "user_id"},
+}
+
+type PostgresCollectionReader struct {
+	db *sql.DB
+}
+
+func NewPostgresCollectionReader(db *sql.DB) *PostgresCollectionReader {
+	return &PostgresCollectionReader{db: db}
+}
+
+func (pr *PostgresCollectionReader) Page(collection, afterKey string, limit int) ([]Document, error) {
+	mapping, ok := postgresCollections[collection]
+	if !ok {
+		return nil, fmt.Errorf("no table for collection %q", collection)
+	}
+
+	rows, err := pr.db.Query(`SELECT `+mapping.key+`, data FROM `+mapping.table+`
+		WHERE `+mapping.key+` > $1 ORDER BY `+mapping.key+` LIMIT $2`, afterKey, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := make([]Document, 0, limit)
+	for rows.Next() {
+		var doc Document
+		var data []byte
+		if err := rows.Scan(&doc.Key, &data); err != nil {
+			return nil, err
+		}
+		doc.Data = data
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
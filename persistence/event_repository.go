@@ -25,6 +25,12 @@ type EventRepository struct {
 }
 
 func NewEventRepository() EventRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresEventRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalEventRepository(localStore)
+	}
 	return &EventRepository{}
 }
 
@@ -3,6 +3,7 @@ package persistence
 import (
 	"context"
 	"errors"
+	"sort"
 
 	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
@@ -20,11 +21,18 @@ type FileRepositoryInterface interface {
 	GetByID(id string) (*entity.File, error)
 	GetAll() ([]*entity.File, error)
 	GetByContextID(contextType, contextID string) ([]*entity.File, error)
+	GetPageByContextID(contextType, contextID string, offset, limit int) ([]*entity.File, int, error)
 	Update(file *entity.File) error
 	Delete(id string) error
 }
 
-func NewFileRepository() *FileRepository {
+func NewFileRepository() FileRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresFileRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalFileRepository(localStore)
+	}
 	return &FileRepository{}
 }
 
@@ -82,6 +90,14 @@ func (fr *FileRepository) GetByContextID(contextType, contextID string) ([]*enti
 	return files, nil
 }
 
+func (fr *FileRepository) GetPageByContextID(contextType, contextID string, offset, limit int) ([]*entity.File, int, error) {
+	files, err := fr.GetByContextID(contextType, contextID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pageFiles(files, offset, limit), len(files), nil
+}
+
 func (fr *FileRepository) Update(file *entity.File) error {
 	ctx := context.Background()
 	ref := config.FirebaseDB.NewRef(filesCollection + "/" + file.ID)
@@ -93,3 +109,17 @@ func (fr *FileRepository) Delete(id string) error {
 	ref := config.FirebaseDB.NewRef(filesCollection + "/" + id)
 	return ref.Delete(ctx)
 }
+
+// pageFiles orders files oldest first, like the SQL backend, and returns the requested slice of them
+func pageFiles(files []*entity.File, offset, limit int) []*entity.File {
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].CreatedAt != files[j].CreatedAt {
+			return files[i].CreatedAt < files[j].CreatedAt
+		}
+		return files[i].ID < files[j].ID
+	})
+
+	start := min(offset, len(files))
+	end := min(start+limit, len(files))
+	return files[start:end]
+}
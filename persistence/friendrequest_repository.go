@@ -15,9 +15,23 @@ const friendRequestsPath = "friendRequests"
 
 var ErrFriendRequestNotFound = errors.New("friend request not found")
 
+type FriendRequestRepositoryInterface interface {
+	Create(request *entity.FriendRequest) error
+	GetByUsers(fromUserID, toUserID string) (*entity.FriendRequest, error)
+	Update(request *entity.FriendRequest) error
+	GetPendingRequestsForUser(userID string) ([]*entity.FriendRequest, error)
+	GetFriendsForUser(userID string) ([]string, error)
+}
+
 type FriendRequestRepository struct{}
 
-func NewFriendRequestRepository() *FriendRequestRepository {
+func NewFriendRequestRepository() FriendRequestRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresFriendRequestRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalFriendRequestRepository(localStore)
+	}
 	return &FriendRequestRepository{}
 }
 
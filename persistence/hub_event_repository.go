@@ -0,0 +1,168 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"firebase.google.com/go/v4/db"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+const (
+	hubEventsCollection = "hubEvents"
+	// hubEventSeqsCollection held the last sequence number of each user before it was taken from their latest event
+	hubEventSeqsCollection = "hubEventSeqs"
+	hubEventSeqField       = "seq"
+	// hubEventAppendAttempts bounds how often Append retries when concurrent appends take the next sequence number
+	hubEventAppendAttempts = 20
+
+	// HubEventRetention is how many of a user's latest events are kept for replay
+	HubEventRetention = 1000
+	// hubEventTrimInterval spreads the trimming out, so not every append pays for it
+	hubEventTrimInterval = 100
+)
+
+// HubEventRepositoryInterface keeps the real-time events sent to each user, numbered per user
+type HubEventRepositoryInterface interface {
+	// Append stores data as the user's next event and returns its sequence number
+	Append(userID string, data json.RawMessage) (int64, error)
+	// ListSince returns up to limit of the user's events with a sequence number above seq, oldest first
+	ListSince(userID string, seq int64, limit int) ([]*entity.HubEvent, error)
+}
+
+type HubEventRepository struct{}
+
+func NewHubEventRepository() HubEventRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresHubEventRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalHubEventRepository(localStore)
+	}
+	return &HubEventRepository{}
+}
+
+// errHubEventSeqTaken aborts the write of an event whose sequence number a concurrent append took first
+var errHubEventSeqTaken = errors.New("hub event sequence number taken")
+
+// Append writes the event in a transaction on the slot after the user's latest event, which fails if another
+// append filled it first; it then retries on the next slot. Taking the number and writing the event are one
+// write, so no number is left without its event and no event is written before the one preceding it.
+func (her *HubEventRepository) Append(userID string, data json.RawMessage) (int64, error) {
+	ctx := context.Background()
+
+	var seq int64
+	for attempt := 0; ; attempt++ {
+		last, err := her.lastSeq(userID)
+		if err != nil {
+			return 0, err
+		}
+		seq = last + 1
+		event := entity.NewHubEvent(userID, seq, data)
+		ref := config.FirebaseDB.NewRef(hubEventsCollection + "/" + userID + "/" + hubEventKey(seq))
+		err = ref.Transaction(ctx, func(node db.TransactionNode) (interface{}, error) {
+			var current entity.HubEvent
+			if err := node.Unmarshal(&current); err != nil {
+				return nil, err
+			}
+			if current.Seq != 0 {
+				return nil, errHubEventSeqTaken
+			}
+			return event, nil
+		})
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errHubEventSeqTaken) || attempt == hubEventAppendAttempts-1 {
+			return 0, err
+		}
+	}
+
+	if cutoff, ok := hubEventTrimCutoff(seq); ok {
+		if err := her.trim(userID, cutoff); err != nil {
+			log.Printf("trimming hub events of %s failed: %v", userID, err)
+		}
+	}
+	return seq, nil
+}
+
+func (her *HubEventRepository) ListSince(userID string, seq int64, limit int) ([]*entity.HubEvent, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(hubEventsCollection + "/" + userID)
+
+	results, err := ref.OrderByChild(hubEventSeqField).StartAt(seq + 1).LimitToFirst(limit).GetOrdered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*entity.HubEvent, 0, len(results))
+	for _, r := range results {
+		var event entity.HubEvent
+		if err := r.Unmarshal(&event); err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+	return events, nil
+}
+
+// lastSeq is the sequence number of the user's latest event. Users without events continue from
+// the counter kept before, so sequence numbers clients already saw are not handed out again.
+func (her *HubEventRepository) lastSeq(userID string) (int64, error) {
+	ctx := context.Background()
+
+	ref := config.FirebaseDB.NewRef(hubEventsCollection + "/" + userID)
+	results, err := ref.OrderByKey().LimitToLast(1).GetOrdered(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, r := range results {
+		var event entity.HubEvent
+		if err := r.Unmarshal(&event); err != nil {
+			return 0, err
+		}
+		return event.Seq, nil
+	}
+
+	var legacy int64
+	if err := config.FirebaseDB.NewRef(hubEventSeqsCollection+"/"+userID).Get(ctx, &legacy); err != nil {
+		return 0, err
+	}
+	return legacy, nil
+}
+
+// trim deletes the user's events up to and including cutoff
+func (her *HubEventRepository) trim(userID string, cutoff int64) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(hubEventsCollection + "/" + userID)
+
+	var old map[string]json.RawMessage
+	if err := ref.OrderByChild(hubEventSeqField).EndAt(cutoff).Get(ctx, &old); err != nil {
+		return err
+	}
+	if len(old) == 0 {
+		return nil
+	}
+	updates := make(map[string]interface{}, len(old))
+	for key := range old {
+		updates[key] = nil
+	}
+	return ref.Update(ctx, updates)
+}
+
+// hubEventKey zero pads seq, so the keys of a user's events sort in sequence order
+func hubEventKey(seq int64) string {
+	return fmt.Sprintf("%020d", seq)
+}
+
+// hubEventTrimCutoff reports whether appending seq should trim the log, and up to which sequence number
+func hubEventTrimCutoff(seq int64) (int64, bool) {
+	if seq%hubEventTrimInterval != 0 || seq <= HubEventRetention {
+		return 0, false
+	}
+	return seq - HubEventRetention, true
+}
@@ -0,0 +1,46 @@
+package persistence
+
+import (
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// LocalEventRepository is the LocalStore implementation of EventRepositoryInterface
+type LocalEventRepository struct {
+	store *LocalStore
+}
+
+func NewLocalEventRepository(store *LocalStore) *LocalEventRepository {
+	return &LocalEventRepository{store: store}
+}
+
+func (er *LocalEventRepository) Create(event *entity.Event) error {
+	return er.store.Put(eventsCollection, event.ID, event)
+}
+
+func (er *LocalEventRepository) GetByID(id string) (*entity.Event, error) {
+	var event entity.Event
+	found, err := er.store.Get(eventsCollection, id, &event)
+	if err != nil {
+		return nil, err
+	}
+	if !found || event.ID == "" {
+		return nil, errors.New(EventNotFound)
+	}
+	return &event, nil
+}
+
+func (er *LocalEventRepository) GetByTeamID(teamId string) ([]*entity.Event, error) {
+	return listLocal(er.store, eventsCollection, func(event *entity.Event) bool {
+		return event.TeamID == teamId
+	})
+}
+
+func (er *LocalEventRepository) Update(id string, updates map[string]interface{}) error {
+	return er.store.Update(eventsCollection, id, updates)
+}
+
+func (er *LocalEventRepository) Delete(id string) error {
+	return er.store.Delete(eventsCollection, id)
+}
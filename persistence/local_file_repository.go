@@ -0,0 +1,58 @@
+package persistence
+
+import (
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// LocalFileRepository is the LocalStore implementation of FileRepositoryInterface
+type LocalFileRepository struct {
+	store *LocalStore
+}
+
+func NewLocalFileRepository(store *LocalStore) *LocalFileRepository {
+	return &LocalFileRepository{store: store}
+}
+
+func (fr *LocalFileRepository) Create(file *entity.File) error {
+	return fr.store.Put(filesCollection, file.ID, file)
+}
+
+func (fr *LocalFileRepository) GetByID(id string) (*entity.File, error) {
+	var file entity.File
+	found, err := fr.store.Get(filesCollection, id, &file)
+	if err != nil {
+		return nil, err
+	}
+	if !found || file.ID == "" {
+		return nil, errors.New(fileNotFound)
+	}
+	return &file, nil
+}
+
+func (fr *LocalFileRepository) GetAll() ([]*entity.File, error) {
+	return listLocal[entity.File](fr.store, filesCollection, nil)
+}
+
+func (fr *LocalFileRepository) GetByContextID(contextType, contextID string) ([]*entity.File, error) {
+	return listLocal(fr.store, filesCollection, func(file *entity.File) bool {
+		return file.ContextType == contextType && file.ContextID == contextID
+	})
+}
+
+func (fr *LocalFileRepository) GetPageByContextID(contextType, contextID string, offset, limit int) ([]*entity.File, int, error) {
+	files, err := fr.GetByContextID(contextType, contextID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pageFiles(files, offset, limit), len(files), nil
+}
+
+func (fr *LocalFileRepository) Update(file *entity.File) error {
+	return fr.store.Put(filesCollection, file.ID, file)
+}
+
+func (fr *LocalFileRepository) Delete(id string) error {
+	return fr.store.Delete(filesCollection, id)
+}
@@ -0,0 +1,74 @@
+package persistence
+
+import (
+	"fmt"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// LocalFriendRequestRepository is the LocalStore implementation of FriendRequestRepositoryInterface
+type LocalFriendRequestRepository struct {
+	store *LocalStore
+}
+
+func NewLocalFriendRequestRepository(store *LocalStore) *LocalFriendRequestRepository {
+	return &LocalFriendRequestRepository{store: store}
+}
+
+func (fr *LocalFriendRequestRepository) Create(request *entity.FriendRequest) error {
+	if err := fr.store.Put(friendRequestsPath, request.Key(), request); err != nil {
+		return fmt.Errorf("create friend request: %w", err)
+	}
+	return nil
+}
+
+func (fr *LocalFriendRequestRepository) GetByUsers(fromUserID, toUserID string) (*entity.FriendRequest, error) {
+	key := fromUserID + ":" + toUserID
+
+	var request entity.FriendRequest
+	found, err := fr.store.Get(friendRequestsPath, key, &request)
+	if err != nil {
+		return nil, fmt.Errorf("get friend request %s: %w", key, err)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w", ErrFriendRequestNotFound)
+	}
+	return &request, nil
+}
+
+func (fr *LocalFriendRequestRepository) Update(request *entity.FriendRequest) error {
+	if err := fr.store.Put(friendRequestsPath, request.Key(), request); err != nil {
+		return fmt.Errorf("update friend request: %w", err)
+	}
+	return nil
+}
+
+func (fr *LocalFriendRequestRepository) GetPendingRequestsForUser(userID string) ([]*entity.FriendRequest, error) {
+	return listLocal(fr.store, friendRequestsPath, func(request *entity.FriendRequest) bool {
+		return request.ToUserID == userID && request.Status == entity.PENDING
+	})
+}
+
+func (fr *LocalFriendRequestRepository) GetFriendsForUser(userID string) ([]string, error) {
+	accepted, err := listLocal(fr.store, friendRequestsPath, func(request *entity.FriendRequest) bool {
+		return request.Status == entity.ACCEPTED && (request.FromUserID == userID || request.ToUserID == userID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get friend requests map: %w", err)
+	}
+
+	friendSet := make(map[string]struct{})
+	friends := make([]string, 0, len(accepted))
+	for _, request := range accepted {
+		friendID := request.FromUserID
+		if friendID == userID {
+			friendID = request.ToUserID
+		}
+		if _, seen := friendSet[friendID]; seen {
+			continue
+		}
+		friendSet[friendID] = struct{}{}
+		friends = append(friends, friendID)
+	}
+	return friends, nil
+}
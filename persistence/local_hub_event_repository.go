@@ -0,0 +1,84 @@
+package persistence
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// localHubEventSeqMu serializes sequence allocation across every LocalHubEventRepository sharing a store
+var localHubEventSeqMu sync.Mutex
+
+// LocalHubEventRepository is the LocalStore implementation of HubEventRepositoryInterface.
+// Events are stored under userID/seq, so a user's events are one key range in sequence order.
+type LocalHubEventRepository struct {
+	store *LocalStore
+}
+
+func NewLocalHubEventRepository(store *LocalStore) *LocalHubEventRepository {
+	return &LocalHubEventRepository{store: store}
+}
+
+func (her *LocalHubEventRepository) Append(userID string, data json.RawMessage) (int64, error) {
+	localHubEventSeqMu.Lock()
+	defer localHubEventSeqMu.Unlock()
+
+	var current int64
+	if _, err := her.store.Get(hubEventSeqsCollection, userID, &current); err != nil {
+		return 0, err
+	}
+	seq := current + 1
+
+	writes := []LocalWrite{
+		{Collection: hubEventSeqsCollection, ID: userID, Value: seq},
+		{Collection: hubEventsCollection, ID: userID + "/" + hubEventKey(seq), Value: entity.NewHubEvent(userID, seq, data)},
+	}
+	if cutoff, ok := hubEventTrimCutoff(seq); ok {
+		for _, key := range her.keys(userID) {
+			if key > userID+"/"+hubEventKey(cutoff) {
+				break
+			}
+			writes = append(writes, LocalWrite{Collection: hubEventsCollection, ID: key})
+		}
+	}
+	if err := her.store.Batch(writes); err != nil {
+		return 0, err
+	}
+	return seq, nil
+}
+
+func (her *LocalHubEventRepository) ListSince(userID string, seq int64, limit int) ([]*entity.HubEvent, error) {
+	after := userID + "/" + hubEventKey(seq)
+	events := make([]*entity.HubEvent, 0)
+	for _, key := range her.keys(userID) {
+		if len(events) == limit {
+			break
+		}
+		if key <= after {
+			continue
+		}
+		var event entity.HubEvent
+		found, err := her.store.Get(hubEventsCollection, key, &event)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			events = append(events, &event)
+		}
+	}
+	return events, nil
+}
+
+// keys returns the keys of the user's events in sequence order
+func (her *LocalHubEventRepository) keys(userID string) []string {
+	prefix := userID + "/"
+	keys := make([]string, 0)
+	for _, key := range her.store.Keys(hubEventsCollection) {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
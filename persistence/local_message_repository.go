@@ -0,0 +1,114 @@
+package persistence
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// LocalMessageRepository is the LocalStore implementation of MessageRepositoryInterface
+type LocalMessageRepository struct {
+	store *LocalStore
+}
+
+func NewLocalMessageRepository(store *LocalStore) *LocalMessageRepository {
+	return &LocalMessageRepository{store: store}
+}
+
+func (mr *LocalMessageRepository) Create(message *entity.Message) error {
+	return mr.store.Put(messagesCollection, message.ID, message)
+}
+
+func (mr *LocalMessageRepository) GetByID(id string) (*entity.Message, error) {
+	var message entity.Message
+	found, err := mr.store.Get(messagesCollection, id, &message)
+	if err != nil {
+		return nil, err
+	}
+	if !found || message.ID == "" {
+		return nil, errors.New(MessageNotFound)
+	}
+	return &message, nil
+}
+
+func (mr *LocalMessageRepository) GetByConversation(user1Id, user2Id string) ([]*entity.Message, error) {
+	key := entity.GetConversationKey(user1Id, user2Id)
+	return mr.listSorted(func(message *entity.Message) bool { return message.ConversationKey == key })
+}
+
+func (mr *LocalMessageRepository) GetByTeamID(teamId string) ([]*entity.Message, error) {
+	return mr.listSorted(func(message *entity.Message) bool { return message.TeamID == teamId })
+}
+
+func (mr *LocalMessageRepository) GetConversationPage(user1Id, user2Id string, query MessagePageQuery) ([]*entity.Message, error) {
+	thread := entity.ConversationThread(entity.GetConversationKey(user1Id, user2Id))
+	return mr.page(func(message *entity.Message) bool { return message.Thread() == thread }, query)
+}
+
+func (mr *LocalMessageRepository) GetTeamPage(teamId string, query MessagePageQuery) ([]*entity.Message, error) {
+	thread := entity.TeamThread(teamId)
+	return mr.page(func(message *entity.Message) bool { return message.Thread() == thread }, query)
+}
+
+func (mr *LocalMessageRepository) GetRepliesPage(parentId string, query MessagePageQuery) ([]*entity.Message, error) {
+	return mr.page(func(message *entity.Message) bool { return message.ParentID == parentId }, query)
+}
+
+func (mr *LocalMessageRepository) page(inThread func(*entity.Message) bool, query MessagePageQuery) ([]*entity.Message, error) {
+	messages, err := mr.listSorted(func(message *entity.Message) bool {
+		if !inThread(message) {
+			return false
+		}
+		cursor := message.Cursor()
+		if query.After != nil && !query.After.Less(cursor) {
+			return false
+		}
+		return query.Before == nil || cursor.Less(*query.Before)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trimPage(messages, query), nil
+}
+
+func (mr *LocalMessageRepository) CountConversation(user1Id, user2Id string, query MessageCountQuery) (int, error) {
+	thread := entity.ConversationThread(entity.GetConversationKey(user1Id, user2Id))
+	return mr.count(func(message *entity.Message) bool { return message.Thread() == thread }, query)
+}
+
+func (mr *LocalMessageRepository) CountTeam(teamId string, query MessageCountQuery) (int, error) {
+	thread := entity.TeamThread(teamId)
+	return mr.count(func(message *entity.Message) bool { return message.Thread() == thread }, query)
+}
+
+func (mr *LocalMessageRepository) count(inThread func(*entity.Message) bool, query MessageCountQuery) (int, error) {
+	messages, err := listLocal(mr.store, messagesCollection, func(message *entity.Message) bool {
+		if !inThread(message) || !query.counts(message) {
+			return false
+		}
+		return query.After == nil || query.After.Less(message.Cursor())
+	})
+	if err != nil {
+		return 0, err
+	}
+	return min(len(messages), query.Limit), nil
+}
+
+func (mr *LocalMessageRepository) Update(id string, updates map[string]interface{}) error {
+	return mr.store.Update(messagesCollection, id, updates)
+}
+
+func (mr *LocalMessageRepository) Delete(id string) error {
+	return mr.store.Delete(messagesCollection, id)
+}
+
+// listSorted returns the matching messages oldest first
+func (mr *LocalMessageRepository) listSorted(keep func(*entity.Message) bool) ([]*entity.Message, error) {
+	messages, err := listLocal(mr.store, messagesCollection, keep)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(messages, func(i, j int) bool { return messages[i].Cursor().Less(messages[j].Cursor()) })
+	return messages, nil
+}
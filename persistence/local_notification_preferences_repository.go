@@ -0,0 +1,32 @@
+package persistence
+
+import (
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// LocalNotificationPreferencesRepository is the LocalStore implementation of NotificationPreferencesRepositoryInterface
+type LocalNotificationPreferencesRepository struct {
+	store *LocalStore
+}
+
+func NewLocalNotificationPreferencesRepository(store *LocalStore) *LocalNotificationPreferencesRepository {
+	return &LocalNotificationPreferencesRepository{store: store}
+}
+
+func (pr *LocalNotificationPreferencesRepository) Save(preferences *entity.NotificationPreferences) error {
+	return pr.store.Put(notificationPreferencesCollection, preferences.UserID, preferences)
+}
+
+func (pr *LocalNotificationPreferencesRepository) GetByUserID(userID string) (*entity.NotificationPreferences, error) {
+	var preferences entity.NotificationPreferences
+	found, err := pr.store.Get(notificationPreferencesCollection, userID, &preferences)
+	if err != nil {
+		return nil, err
+	}
+	if !found || preferences.UserID == "" {
+		return nil, errors.New(NotificationPreferencesNotFound)
+	}
+	return &preferences, nil
+}
@@ -0,0 +1,76 @@
+package persistence
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// LocalNotificationRepository is the LocalStore implementation of NotificationRepositoryInterface
+type LocalNotificationRepository struct {
+	store *LocalStore
+}
+
+func NewLocalNotificationRepository(store *LocalStore) *LocalNotificationRepository {
+	return &LocalNotificationRepository{store: store}
+}
+
+func (nr *LocalNotificationRepository) Create(notification *entity.Notification) error {
+	return nr.store.Put(notificationsCollection, notification.ID, notification)
+}
+
+func (nr *LocalNotificationRepository) GetByID(id string) (*entity.Notification, error) {
+	var notification entity.Notification
+	found, err := nr.store.Get(notificationsCollection, id, &notification)
+	if err != nil {
+		return nil, err
+	}
+	if !found || notification.ID == "" {
+		return nil, errors.New(NotificationNotFound)
+	}
+	return &notification, nil
+}
+
+func (nr *LocalNotificationRepository) GetByUserID(userID string) ([]*entity.Notification, error) {
+	notifications, err := listLocal(nr.store, notificationsCollection, func(notification *entity.Notification) bool {
+		return notification.UserID == userID
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortNotifications(notifications)
+	return notifications, nil
+}
+
+func (nr *LocalNotificationRepository) Update(notification *entity.Notification) error {
+	return nr.Create(notification)
+}
+
+func (nr *LocalNotificationRepository) CountUnread(userID string) (map[entity.NotificationType]int, error) {
+	unread, err := listLocal(nr.store, notificationsCollection, func(notification *entity.Notification) bool {
+		return notification.UserID == userID && !notification.IsRead()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return countByType(unread), nil
+}
+
+func (nr *LocalNotificationRepository) MarkAllRead(userID string, readAt time.Time) (int, error) {
+	notifications, err := nr.GetByUserID(userID)
+	if err != nil {
+		return 0, err
+	}
+	marked := 0
+	for _, notification := range notifications {
+		if !notification.MarkRead(readAt) {
+			continue
+		}
+		if err := nr.store.Put(notificationsCollection, notification.ID, notification); err != nil {
+			return marked, err
+		}
+		marked++
+	}
+	return marked, nil
+}
@@ -0,0 +1,47 @@
+package persistence
+
+import (
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// LocalPresenceRepository is the LocalStore implementation of PresenceRepositoryInterface
+type LocalPresenceRepository struct {
+	store *LocalStore
+}
+
+func NewLocalPresenceRepository(store *LocalStore) *LocalPresenceRepository {
+	return &LocalPresenceRepository{store: store}
+}
+
+func (pr *LocalPresenceRepository) Save(presence *entity.Presence) error {
+	return pr.store.Put(presenceCollection, presence.UserID, presence)
+}
+
+func (pr *LocalPresenceRepository) GetByUserID(userID string) (*entity.Presence, error) {
+	var presence entity.Presence
+	found, err := pr.store.Get(presenceCollection, userID, &presence)
+	if err != nil {
+		return nil, err
+	}
+	if !found || presence.UserID == "" {
+		return nil, errors.New(PresenceNotFound)
+	}
+	return &presence, nil
+}
+
+func (pr *LocalPresenceRepository) GetByUserIDs(userIDs []string) ([]*entity.Presence, error) {
+	presences := make([]*entity.Presence, 0, len(userIDs))
+	for _, userID := range uniqueIDs(userIDs) {
+		presence, err := pr.GetByUserID(userID)
+		if err != nil {
+			if err.Error() == PresenceNotFound {
+				continue
+			}
+			return nil, err
+		}
+		presences = append(presences, presence)
+	}
+	return presences, nil
+}
@@ -0,0 +1,75 @@
+package persistence
+
+import (
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// LocalQuizRepository is the LocalStore implementation of QuizRepositoryInterface
+type LocalQuizRepository struct {
+	store *LocalStore
+}
+
+func NewLocalQuizRepository(store *LocalStore) *LocalQuizRepository {
+	return &LocalQuizRepository{store: store}
+}
+
+func (qr *LocalQuizRepository) Create(quiz entity.Quiz) error {
+	return qr.store.Put(quizCollection, quiz.ID, quiz)
+}
+
+func (qr *LocalQuizRepository) Update(quiz entity.Quiz) error {
+	return qr.store.Put(quizCollection, quiz.ID, quiz)
+}
+
+func (qr *LocalQuizRepository) GetById(id string) (entity.Quiz, error) {
+	var quiz entity.Quiz
+	found, err := qr.store.Get(quizCollection, id, &quiz)
+	if err != nil {
+		return entity.Quiz{}, err
+	}
+	if !found || quiz.ID == "" {
+		return entity.Quiz{}, errors.New(quizNotFoundError)
+	}
+	return quiz, nil
+}
+
+func (qr *LocalQuizRepository) GetByUser(id string, pageSize int, lastKey string) ([]entity.Quiz, string, error) {
+	return qr.page(func(quiz *entity.Quiz) bool { return quiz.UserID == id }, pageSize, lastKey)
+}
+
+func (qr *LocalQuizRepository) GetByTeam(id string, pageSize int, lastKey string) ([]entity.Quiz, string, error) {
+	return qr.page(func(quiz *entity.Quiz) bool { return quiz.TeamID == id }, pageSize, lastKey)
+}
+
+func (qr *LocalQuizRepository) GetByUserAndTeam(userId string, teamId string, pageSize int, lastKey string) ([]entity.Quiz, string, error) {
+	combinedId := userId + "_" + teamId
+	return qr.page(func(quiz *entity.Quiz) bool { return quiz.UserTeamId == combinedId }, pageSize, lastKey)
+}
+
+// page returns up to pageSize matching quizzes with an ID after lastKey, in ID order,
+// and the key to pass for the next page
+func (qr *LocalQuizRepository) page(keep func(*entity.Quiz) bool, pageSize int, lastKey string) ([]entity.Quiz, string, error) {
+	matching, err := listLocal(qr.store, quizCollection, func(quiz *entity.Quiz) bool {
+		return quiz.ID > lastKey && keep(quiz)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if pageSize > 0 && len(matching) > pageSize {
+		matching = matching[:pageSize]
+	}
+
+	quizzes := make([]entity.Quiz, 0, len(matching))
+	for _, quiz := range matching {
+		quizzes = append(quizzes, *quiz)
+	}
+
+	var newLastKey string
+	if len(quizzes) > 0 {
+		newLastKey = quizzes[len(quizzes)-1].ID
+	}
+	return quizzes, newLastKey, nil
+}
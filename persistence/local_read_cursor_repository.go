@@ -0,0 +1,53 @@
+package persistence
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// LocalReadCursorRepository is the LocalStore implementation of ReadCursorRepositoryInterface.
+// Cursors are stored under userID/thread, so a user's cursors are one key range.
+type LocalReadCursorRepository struct {
+	store *LocalStore
+}
+
+func NewLocalReadCursorRepository(store *LocalStore) *LocalReadCursorRepository {
+	return &LocalReadCursorRepository{store: store}
+}
+
+func (rr *LocalReadCursorRepository) Save(cursor *entity.ReadCursor) error {
+	return rr.store.Put(readCursorsCollection, cursor.UserID+"/"+cursor.Thread, cursor)
+}
+
+func (rr *LocalReadCursorRepository) Get(userID, thread string) (*entity.ReadCursor, error) {
+	var cursor entity.ReadCursor
+	found, err := rr.store.Get(readCursorsCollection, userID+"/"+thread, &cursor)
+	if err != nil {
+		return nil, err
+	}
+	if !found || cursor.UserID == "" {
+		return nil, errors.New(ReadCursorNotFound)
+	}
+	return &cursor, nil
+}
+
+func (rr *LocalReadCursorRepository) GetByUser(userID string) ([]*entity.ReadCursor, error) {
+	prefix := userID + "/"
+	cursors := make([]*entity.ReadCursor, 0)
+	for _, key := range rr.store.Keys(readCursorsCollection) {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		var cursor entity.ReadCursor
+		found, err := rr.store.Get(readCursorsCollection, key, &cursor)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			cursors = append(cursors, &cursor)
+		}
+	}
+	return cursors, nil
+}
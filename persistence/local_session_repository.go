@@ -0,0 +1,55 @@
+package persistence
+
+import (
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// LocalSessionRepository is the LocalStore implementation of SessionRepositoryInterface
+type LocalSessionRepository struct {
+	store *LocalStore
+}
+
+func NewLocalSessionRepository(store *LocalStore) *LocalSessionRepository {
+	return &LocalSessionRepository{store: store}
+}
+
+func (sr *LocalSessionRepository) Create(session *entity.Session) error {
+	return sr.store.Put(sessionsCollection, session.ID, session)
+}
+
+func (sr *LocalSessionRepository) GetByID(id string) (*entity.Session, error) {
+	var session entity.Session
+	found, err := sr.store.Get(sessionsCollection, id, &session)
+	if err != nil {
+		return nil, err
+	}
+	if !found || session.ID == "" {
+		return nil, errors.New(SessionNotFound)
+	}
+	return &session, nil
+}
+
+func (sr *LocalSessionRepository) GetByUserID(userId string) ([]*entity.Session, error) {
+	return listLocal(sr.store, sessionsCollection, func(session *entity.Session) bool {
+		return session.UserID == userId
+	})
+}
+
+func (sr *LocalSessionRepository) Update(session *entity.Session) error {
+	return sr.store.Put(sessionsCollection, session.ID, session)
+}
+
+func (sr *LocalSessionRepository) RevokeToken(token *entity.RevokedToken) error {
+	return sr.store.Put(revokedTokensCollection, token.JTI, token)
+}
+
+func (sr *LocalSessionRepository) IsTokenRevoked(jti string) (bool, error) {
+	var token entity.RevokedToken
+	found, err := sr.store.Get(revokedTokensCollection, jti, &token)
+	if err != nil {
+		return false, err
+	}
+	return found && token.JTI != "", nil
+}
@@ -0,0 +1,365 @@
+package persistence
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LocalStore keeps every collection in memory as JSON documents, the same shape Firebase stores them in.
+// Values are always copied in and out through JSON, so callers never share state with the store.
+// When a file path is given, the whole store is loaded from it at startup and rewritten after every change.
+type LocalStore struct {
+	mu          sync.RWMutex
+	path        string
+	collections map[string]map[string]json.RawMessage
+}
+
+// NewLocalStore creates a store, loading path if it already exists. An empty path keeps data in memory only.
+func NewLocalStore(path string) (*LocalStore, error) {
+	store := &LocalStore{
+		path:        path,
+		collections: make(map[string]map[string]json.RawMessage),
+	}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &store.collections); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// Put stores value under collection/id, replacing whatever was there
+func (ls *LocalStore) Put(collection, id string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	docs, ok := ls.collections[collection]
+	if !ok {
+		docs = make(map[string]json.RawMessage)
+		ls.collections[collection] = docs
+	}
+	docs[id] = data
+	return ls.flush()
+}
+
+// Get decodes collection/id into value and reports whether the document exists
+func (ls *LocalStore) Get(collection, id string, value interface{}) (bool, error) {
+	ls.mu.RLock()
+	data, ok := ls.collections[collection][id]
+	ls.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, value)
+}
+
+// Update merges fields into an existing document like a Firebase ref update.
+// Keys may be slash separated paths into nested objects.
+func (ls *LocalStore) Update(collection, id string, updates map[string]interface{}) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.merge(collection, id, updates)
+}
+
+// Patch merges the fields returned by patch into an existing document, holding the lock from the read
+// to the write so no other change lands in between. It reports whether the document was changed.
+func (ls *LocalStore) Patch(collection, id string, patch func(json.RawMessage) (map[string]interface{}, error)) (bool, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	data, ok := ls.collections[collection][id]
+	if !ok {
+		return false, nil
+	}
+	updates, err := patch(data)
+	if err != nil || updates == nil {
+		return false, err
+	}
+	return true, ls.merge(collection, id, updates)
+}
+
+// merge applies updates to collection/id and flushes. Callers must hold the write lock.
+func (ls *LocalStore) merge(collection, id string, updates map[string]interface{}) error {
+	doc := make(map[string]interface{})
+	if data, ok := ls.collections[collection][id]; ok {
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+	}
+
+	if err := applyUpdates(doc, updates); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if _, ok := ls.collections[collection]; !ok {
+		ls.collections[collection] = make(map[string]json.RawMessage)
+	}
+	ls.collections[collection][id] = data
+	return ls.flush()
+}
+
+// Delete removes collection/id. Deleting a missing document is not an error, as in Firebase.
+func (ls *LocalStore) Delete(collection, id string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if _, ok := ls.collections[collection][id]; !ok {
+		return nil
+	}
+	delete(ls.collections[collection], id)
+	return ls.flush()
+}
+
+// LocalWrite is one change of a LocalStore batch; a nil Value deletes the document
+type LocalWrite struct {
+	Collection string
+	ID         string
+	Value      interface{}
+}
+
+// Batch applies all writes together: readers see either none or all of them,
+// and if the file cannot be written the store is left as it was
+func (ls *LocalStore) Batch(writes []LocalWrite) error {
+	encoded, err := encodeWrites(writes)
+	if err != nil {
+		return err
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.apply(writes, encoded)
+}
+
+// LocalTx reads documents and stages writes inside LocalStore.Transact. Reads see the writes staged before them.
+type LocalTx struct {
+	store  *LocalStore
+	writes []LocalWrite
+}
+
+// Get decodes collection/id into value and reports whether the document exists
+func (tx *LocalTx) Get(collection, id string, value interface{}) (bool, error) {
+	for i := len(tx.writes) - 1; i >= 0; i-- {
+		write := tx.writes[i]
+		if write.Collection != collection || write.ID != id {
+			continue
+		}
+		if write.Value == nil {
+			return false, nil
+		}
+		data, err := json.Marshal(write.Value)
+		if err != nil {
+			return false, err
+		}
+		return true, json.Unmarshal(data, value)
+	}
+
+	data, ok := tx.store.collections[collection][id]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, value)
+}
+
+// Put stages value under collection/id
+func (tx *LocalTx) Put(collection, id string, value interface{}) {
+	tx.writes = append(tx.writes, LocalWrite{Collection: collection, ID: id, Value: value})
+}
+
+// Delete stages the removal of collection/id
+func (tx *LocalTx) Delete(collection, id string) {
+	tx.writes = append(tx.writes, LocalWrite{Collection: collection, ID: id})
+}
+
+// Transact runs fn holding the write lock and then applies the writes it staged as one batch,
+// so nothing fn read can change before its writes land. Nothing is written if fn fails.
+func (ls *LocalStore) Transact(fn func(tx *LocalTx) error) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	tx := &LocalTx{store: ls}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	encoded, err := encodeWrites(tx.writes)
+	if err != nil {
+		return err
+	}
+	return ls.apply(tx.writes, encoded)
+}
+
+func encodeWrites(writes []LocalWrite) ([]json.RawMessage, error) {
+	encoded := make([]json.RawMessage, len(writes))
+	for i, write := range writes {
+		if write.Value == nil {
+			continue
+		}
+		data, err := json.Marshal(write.Value)
+		if err != nil {
+			return nil, err
+		}
+		encoded[i] = data
+	}
+	return encoded, nil
+}
+
+// apply writes a batch and flushes, undoing it if the flush fails. Callers must hold the write lock.
+func (ls *LocalStore) apply(writes []LocalWrite, encoded []json.RawMessage) error {
+	type previous struct {
+		data    json.RawMessage
+		existed bool
+	}
+	undo := make([]previous, len(writes))
+	for i, write := range writes {
+		docs, ok := ls.collections[write.Collection]
+		if !ok {
+			docs = make(map[string]json.RawMessage)
+			ls.collections[write.Collection] = docs
+		}
+		undo[i].data, undo[i].existed = docs[write.ID]
+		if encoded[i] == nil {
+			delete(docs, write.ID)
+		} else {
+			docs[write.ID] = encoded[i]
+		}
+	}
+
+	if err := ls.flush(); err != nil {
+		for i := len(writes) - 1; i >= 0; i-- {
+			docs := ls.collections[writes[i].Collection]
+			if undo[i].existed {
+				docs[writes[i].ID] = undo[i].data
+			} else {
+				delete(docs, writes[i].ID)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// Keys returns the document IDs of a collection in ascending order
+func (ls *LocalStore) Keys(collection string) []string {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	keys := make([]string, 0, len(ls.collections[collection]))
+	for key := range ls.collections[collection] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Collections returns the names of all non-empty collections in ascending order
+func (ls *LocalStore) Collections() []string {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	names := make([]string, 0, len(ls.collections))
+	for name, docs := range ls.collections {
+		if len(docs) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// listLocal decodes every document of a collection, in key order, keeping those accepted by keep
+func listLocal[T any](ls *LocalStore, collection string, keep func(*T) bool) ([]*T, error) {
+	items := make([]*T, 0)
+	for _, key := range ls.Keys(collection) {
+		item := new(T)
+		found, err := ls.Get(collection, key, item)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		if keep == nil || keep(item) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// flush rewrites the backing file. Callers must hold the write lock.
+func (ls *LocalStore) flush() error {
+	if ls.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(ls.collections, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(ls.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	// write then rename, so a crash mid-write never leaves a truncated file behind
+	tmp := ls.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, ls.path)
+}
+
+// applyUpdates merges updates into doc the way a Firebase ref update does.
+// Keys may be slash separated paths into nested objects and nil values delete the key.
+func applyUpdates(doc map[string]interface{}, updates map[string]interface{}) error {
+	for key, value := range updates {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		var plain interface{}
+		if err := json.Unmarshal(encoded, &plain); err != nil {
+			return err
+		}
+		setPath(doc, strings.Split(key, "/"), plain)
+	}
+	return nil
+}
+
+func setPath(doc map[string]interface{}, path []string, value interface{}) {
+	for _, part := range path[:len(path)-1] {
+		child, ok := doc[part].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			doc[part] = child
+		}
+		doc = child
+	}
+
+	last := path[len(path)-1]
+	if value == nil {
+		delete(doc, last)
+		return
+	}
+	doc[last] = value
+}
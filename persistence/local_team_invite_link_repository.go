@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// localInviteLinkRedeemMu serializes redeems across every LocalTeamInviteLinkRepository sharing a store
+var localInviteLinkRedeemMu sync.Mutex
+
+// LocalTeamInviteLinkRepository is the LocalStore implementation of TeamInviteLinkRepositoryInterface
+type LocalTeamInviteLinkRepository struct {
+	store *LocalStore
+}
+
+func NewLocalTeamInviteLinkRepository(store *LocalStore) *LocalTeamInviteLinkRepository {
+	return &LocalTeamInviteLinkRepository{store: store}
+}
+
+func (lr *LocalTeamInviteLinkRepository) Create(link *entity.TeamInviteLink) error {
+	return lr.store.Put(teamInviteLinksCollection, link.ID, link)
+}
+
+func (lr *LocalTeamInviteLinkRepository) GetByID(id string) (*entity.TeamInviteLink, error) {
+	var link entity.TeamInviteLink
+	found, err := lr.store.Get(teamInviteLinksCollection, id, &link)
+	if err != nil {
+		return nil, err
+	}
+	if !found || link.ID == "" {
+		return nil, errors.New(TeamInviteLinkNotFound)
+	}
+	return &link, nil
+}
+
+func (lr *LocalTeamInviteLinkRepository) GetByTeamID(teamId string) ([]*entity.TeamInviteLink, error) {
+	return listLocal(lr.store, teamInviteLinksCollection, func(link *entity.TeamInviteLink) bool {
+		return link.TeamID == teamId
+	})
+}
+
+func (lr *LocalTeamInviteLinkRepository) Redeem(id string, now time.Time) (*entity.TeamInviteLink, error) {
+	localInviteLinkRedeemMu.Lock()
+	defer localInviteLinkRedeemMu.Unlock()
+
+	link, err := lr.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !link.IsUsable(now) {
+		return nil, errors.New(TeamInviteLinkUnusable)
+	}
+	link.Uses++
+	if err := lr.store.Put(teamInviteLinksCollection, link.ID, link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (lr *LocalTeamInviteLinkRepository) Release(id string) error {
+	localInviteLinkRedeemMu.Lock()
+	defer localInviteLinkRedeemMu.Unlock()
+
+	link, err := lr.GetByID(id)
+	if err != nil {
+		if err.Error() == TeamInviteLinkNotFound {
+			return nil
+		}
+		return err
+	}
+	if link.Uses == 0 {
+		return nil
+	}
+	link.Uses--
+	return lr.store.Put(teamInviteLinksCollection, link.ID, link)
+}
+
+func (lr *LocalTeamInviteLinkRepository) Delete(id string) error {
+	return lr.store.Delete(teamInviteLinksCollection, id)
+}
@@ -0,0 +1,68 @@
+package persistence
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// LocalTeamRepository is the LocalStore implementation of TeamRepositoryInterface
+type LocalTeamRepository struct {
+	store *LocalStore
+}
+
+func NewLocalTeamRepository(store *LocalStore) *LocalTeamRepository {
+	return &LocalTeamRepository{store: store}
+}
+
+func (tr *LocalTeamRepository) Create(team *entity.Team) error {
+	return tr.store.Put(teamsCollection, team.Id, team)
+}
+
+func (tr *LocalTeamRepository) GetTeamById(id string) (*entity.Team, error) {
+	var team entity.Team
+	found, err := tr.store.Get(teamsCollection, id, &team)
+	if err != nil {
+		return nil, err
+	}
+	if !found || team.Id == "" {
+		return nil, errors.New(teamNotFound)
+	}
+	return &team, nil
+}
+
+// GetXTeamsByPrefix returns the first x teams by name whose name starts with prefix, like the Firebase range query
+func (tr *LocalTeamRepository) GetXTeamsByPrefix(prefix string, x int) ([]*entity.Team, error) {
+	teams, err := listLocal(tr.store, teamsCollection, func(team *entity.Team) bool {
+		return strings.HasPrefix(team.Name, prefix)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	if len(teams) > x {
+		teams = teams[:x]
+	}
+	return teams, nil
+}
+
+func (tr *LocalTeamRepository) GetTeamsByName(name string) ([]*entity.Team, error) {
+	return listLocal(tr.store, teamsCollection, func(team *entity.Team) bool {
+		return team.Name == name
+	})
+}
+
+func (tr *LocalTeamRepository) GetAll() ([]*entity.Team, error) {
+	return listLocal[entity.Team](tr.store, teamsCollection, nil)
+}
+
+func (tr *LocalTeamRepository) Update(team *entity.Team) error {
+	return tr.store.Put(teamsCollection, team.Id, team)
+}
+
+func (tr *LocalTeamRepository) Delete(id string) error {
+	return tr.store.Delete(teamsCollection, id)
+}
@@ -0,0 +1,71 @@
+package persistence
+
+import (
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// LocalTeamRequestRepository is the LocalStore implementation of TeamRequestRepositoryInterface
+type LocalTeamRequestRepository struct {
+	store *LocalStore
+}
+
+func NewLocalTeamRequestRepository(store *LocalStore) *LocalTeamRequestRepository {
+	return &LocalTeamRequestRepository{store: store}
+}
+
+func (tr *LocalTeamRequestRepository) Create(req *entity.TeamRequest) error {
+	return tr.store.Put(teamRequestsCollection, req.Id, req)
+}
+
+func (tr *LocalTeamRequestRepository) GetById(id string) (*entity.TeamRequest, error) {
+	var req entity.TeamRequest
+	found, err := tr.store.Get(teamRequestsCollection, id, &req)
+	if err != nil {
+		return nil, err
+	}
+	if !found || req.Id == "" {
+		return nil, errors.New(requestNotFound)
+	}
+	return &req, nil
+}
+
+func (tr *LocalTeamRequestRepository) Update(req *entity.TeamRequest) error {
+	return tr.store.Put(teamRequestsCollection, req.Id, req)
+}
+
+func (tr *LocalTeamRequestRepository) UpdateIfStatus(req *entity.TeamRequest, status entity.TeamRequestStatus) (bool, error) {
+	updated := false
+	err := tr.store.Transact(func(tx *LocalTx) error {
+		var stored entity.TeamRequest
+		found, err := tx.Get(teamRequestsCollection, req.Id, &stored)
+		if err != nil || !found || storedTeamRequestStatus(&stored) != status {
+			return err
+		}
+		tx.Put(teamRequestsCollection, req.Id, req)
+		updated = true
+		return nil
+	})
+	return updated, err
+}
+
+func (tr *LocalTeamRequestRepository) Delete(id string) error {
+	return tr.store.Delete(teamRequestsCollection, id)
+}
+
+func (tr *LocalTeamRequestRepository) GetAll() ([]*entity.TeamRequest, error) {
+	return listLocal[entity.TeamRequest](tr.store, teamRequestsCollection, nil)
+}
+
+func (tr *LocalTeamRequestRepository) GetByUserId(userId string) ([]*entity.TeamRequest, error) {
+	return listLocal(tr.store, teamRequestsCollection, func(req *entity.TeamRequest) bool {
+		return req.UserID == userId
+	})
+}
+
+func (tr *LocalTeamRequestRepository) GetByTeamId(teamId string) ([]*entity.TeamRequest, error) {
+	return listLocal(tr.store, teamRequestsCollection, func(req *entity.TeamRequest) bool {
+		return req.TeamID == teamId
+	})
+}
@@ -0,0 +1,78 @@
+package persistence
+
+import (
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// LocalUserRepository is the LocalStore implementation of UserRepositoryInterface
+type LocalUserRepository struct {
+	store *LocalStore
+}
+
+func NewLocalUserRepository(store *LocalStore) *LocalUserRepository {
+	return &LocalUserRepository{store: store}
+}
+
+func (ur *LocalUserRepository) Create(user *entity.User) error {
+	return ur.store.Put(usersCollection, user.ID, user)
+}
+
+func (ur *LocalUserRepository) GetByID(id string) (*entity.User, error) {
+	var user entity.User
+	found, err := ur.store.Get(usersCollection, id, &user)
+	if err != nil {
+		return nil, err
+	}
+	if !found || user.ID == "" {
+		return nil, errors.New(userNotFound)
+	}
+	return &user, nil
+}
+
+func (ur *LocalUserRepository) GetByEmail(email string) (*entity.User, error) {
+	return ur.findOne(func(user *entity.User) bool { return user.Email == email })
+}
+
+func (ur *LocalUserRepository) GetByUsername(username string) (*entity.User, error) {
+	return ur.findOne(func(user *entity.User) bool { return user.Username == username })
+}
+
+func (ur *LocalUserRepository) Update(user *entity.User) error {
+	return ur.store.Put(usersCollection, user.ID, user)
+}
+
+func (ur *LocalUserRepository) Delete(id string) error {
+	return ur.store.Delete(usersCollection, id)
+}
+
+func (ur *LocalUserRepository) GetAll() ([]*entity.User, error) {
+	return listLocal[entity.User](ur.store, usersCollection, nil)
+}
+
+func (ur *LocalUserRepository) GetByIDs(ids []string) ([]*entity.User, error) {
+	users := make([]*entity.User, 0, len(ids))
+	for _, id := range uniqueIDs(ids) {
+		user, err := ur.GetByID(id)
+		if err != nil {
+			if err.Error() == userNotFound {
+				continue
+			}
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (ur *LocalUserRepository) findOne(match func(*entity.User) bool) (*entity.User, error) {
+	users, err := listLocal(ur.store, usersCollection, match)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, errors.New(userNotFound)
+	}
+	return users[0], nil
+}
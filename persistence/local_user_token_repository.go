@@ -0,0 +1,68 @@
+package persistence
+
+import (
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// LocalUserTokenRepository is the LocalStore implementation of UserTokenRepositoryInterface
+type LocalUserTokenRepository struct {
+	store *LocalStore
+}
+
+func NewLocalUserTokenRepository(store *LocalStore) *LocalUserTokenRepository {
+	return &LocalUserTokenRepository{store: store}
+}
+
+func (utr *LocalUserTokenRepository) Create(token *entity.UserToken) error {
+	return utr.store.Put(userTokensCollection, token.ID, token)
+}
+
+func (utr *LocalUserTokenRepository) GetByID(id string) (*entity.UserToken, error) {
+	var token entity.UserToken
+	found, err := utr.store.Get(userTokensCollection, id, &token)
+	if err != nil {
+		return nil, err
+	}
+	if !found || token.ID == "" {
+		return nil, errors.New(UserTokenNotFound)
+	}
+	return &token, nil
+}
+
+func (utr *LocalUserTokenRepository) Update(token *entity.UserToken) error {
+	return utr.store.Put(userTokensCollection, token.ID, token)
+}
+
+func (utr *LocalUserTokenRepository) MarkUsed(id string) (bool, error) {
+	marked := false
+	err := utr.store.Transact(func(tx *LocalTx) error {
+		var token entity.UserToken
+		found, err := tx.Get(userTokensCollection, id, &token)
+		if err != nil || !found || token.Used {
+			return err
+		}
+		token.Used = true
+		tx.Put(userTokensCollection, id, &token)
+		marked = true
+		return nil
+	})
+	return marked, err
+}
+
+func (utr *LocalUserTokenRepository) InvalidateByUserID(userID string) error {
+	tokens, err := listLocal(utr.store, userTokensCollection, func(t *entity.UserToken) bool {
+		return t.UserID == userID && !t.Used
+	})
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		token.Used = true
+		if err := utr.store.Put(userTokensCollection, token.ID, token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
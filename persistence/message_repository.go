@@ -11,21 +11,61 @@ import (
 const (
 	messagesCollection = "messages"
 	convKeyField       = "convKey"
+	threadKeyField     = "threadKey"
 	MessageNotFound    = "message not found"
 )
 
+// MessagePageQuery selects one page of a thread. With After set the page starts right after it;
+// otherwise it ends right before Before, or at the newest message when Before is nil too.
+// Both bounds are exclusive and pages are always returned oldest first.
+type MessagePageQuery struct {
+	Before *entity.MessageCursor
+	After  *entity.MessageCursor
+	Limit  int
+}
+
+// forward reports whether the page is anchored at After and grows towards newer messages
+func (q MessagePageQuery) forward() bool {
+	return q.After != nil
+}
+
+// MessageCountQuery counts the messages of a thread after a position, leaving out deleted messages
+// and those sent by ExcludeSender. Counting stops at Limit, so a long backlog is not read in full.
+type MessageCountQuery struct {
+	After         *entity.MessageCursor
+	ExcludeSender string
+	Limit         int
+}
+
+// counts reports whether the message is one the query counts
+func (q MessageCountQuery) counts(message *entity.Message) bool {
+	return !message.IsDeleted() && message.SenderID != q.ExcludeSender
+}
+
 type MessageRepositoryInterface interface {
 	Create(message *entity.Message) error
 	GetByID(id string) (*entity.Message, error)
 	GetByConversation(user1Id, user2Id string) ([]*entity.Message, error)
 	GetByTeamID(teamId string) ([]*entity.Message, error)
+	GetConversationPage(user1Id, user2Id string, query MessagePageQuery) ([]*entity.Message, error)
+	GetTeamPage(teamId string, query MessagePageQuery) ([]*entity.Message, error)
+	// GetRepliesPage pages the thread replies under a message. The other pages leave replies out.
+	GetRepliesPage(parentId string, query MessagePageQuery) ([]*entity.Message, error)
+	CountConversation(user1Id, user2Id string, query MessageCountQuery) (int, error)
+	CountTeam(teamId string, query MessageCountQuery) (int, error)
 	Update(id string, updates map[string]interface{}) error
 	Delete(id string) error
 }
 
 type MessageRepository struct{}
 
-func NewMessageRepository() *MessageRepository {
+func NewMessageRepository() MessageRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresMessageRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalMessageRepository(localStore)
+	}
 	return &MessageRepository{}
 }
 
@@ -93,6 +133,104 @@ func (mr *MessageRepository) GetByTeamID(teamId string) ([]*entity.Message, erro
 	return messages, nil
 }
 
+func (mr *MessageRepository) GetConversationPage(user1Id, user2Id string, query MessagePageQuery) ([]*entity.Message, error) {
+	return mr.getPage(entity.ConversationThread(entity.GetConversationKey(user1Id, user2Id)), query)
+}
+
+func (mr *MessageRepository) GetTeamPage(teamId string, query MessagePageQuery) ([]*entity.Message, error) {
+	return mr.getPage(entity.TeamThread(teamId), query)
+}
+
+func (mr *MessageRepository) GetRepliesPage(parentId string, query MessagePageQuery) ([]*entity.Message, error) {
+	return mr.getPage(entity.ReplyThread(parentId), query)
+}
+
+// getPage runs a range query on threadKey, which sorts a thread by time within a single child,
+// so only the requested page is downloaded. Requires ".indexOn": "threadKey" on messages.
+func (mr *MessageRepository) getPage(thread string, query MessagePageQuery) ([]*entity.Message, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(messagesCollection)
+
+	start, end := thread+"|", thread+"|\uf8ff"
+	if query.After != nil {
+		start = entity.MessageThreadKey(thread, *query.After)
+	}
+	if query.Before != nil {
+		end = entity.MessageThreadKey(thread, *query.Before)
+	}
+
+	// the bounds are inclusive in Firebase, so fetch one extra for each bound that may come back
+	q := ref.OrderByChild(threadKeyField).StartAt(start).EndAt(end)
+	if query.forward() {
+		q = q.LimitToFirst(query.Limit + 2)
+	} else {
+		q = q.LimitToLast(query.Limit + 2)
+	}
+	results, err := q.GetOrdered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*entity.Message, 0, len(results))
+	for _, r := range results {
+		var message entity.Message
+		if err := r.Unmarshal(&message); err != nil {
+			return nil, err
+		}
+		if message.ThreadKey == start || message.ThreadKey == end {
+			continue
+		}
+		messages = append(messages, &message)
+	}
+	return trimPage(messages, query), nil
+}
+
+func (mr *MessageRepository) CountConversation(user1Id, user2Id string, query MessageCountQuery) (int, error) {
+	return mr.count(entity.ConversationThread(entity.GetConversationKey(user1Id, user2Id)), query)
+}
+
+func (mr *MessageRepository) CountTeam(teamId string, query MessageCountQuery) (int, error) {
+	return mr.count(entity.TeamThread(teamId), query)
+}
+
+// count pages forward through the thread on threadKey, since Firebase cannot filter on a second child
+func (mr *MessageRepository) count(thread string, query MessageCountQuery) (int, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(messagesCollection)
+
+	start, end := thread+"|", thread+"|\uf8ff"
+	if query.After != nil {
+		start = entity.MessageThreadKey(thread, *query.After)
+	}
+
+	count := 0
+	for count < query.Limit {
+		// the start bound is inclusive in Firebase, so each page repeats the last message of the previous one
+		results, err := ref.OrderByChild(threadKeyField).StartAt(start).EndAt(end).LimitToFirst(query.Limit + 1).GetOrdered(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, r := range results {
+			var message entity.Message
+			if err := r.Unmarshal(&message); err != nil {
+				return 0, err
+			}
+			if message.ThreadKey != start && query.counts(&message) {
+				count++
+			}
+		}
+		if len(results) <= query.Limit {
+			break
+		}
+		var last entity.Message
+		if err := results[len(results)-1].Unmarshal(&last); err != nil {
+			return 0, err
+		}
+		start = last.ThreadKey
+	}
+	return min(count, query.Limit), nil
+}
+
 func (mr *MessageRepository) Update(id string, updates map[string]interface{}) error {
 	ctx := context.Background()
 	ref := config.FirebaseDB.NewRef(messagesCollection + "/" + id)
@@ -104,3 +242,14 @@ func (mr *MessageRepository) Delete(id string) error {
 	ref := config.FirebaseDB.NewRef(messagesCollection + "/" + id)
 	return ref.Delete(ctx)
 }
+
+// trimPage keeps Limit messages of an oldest first slice, from the side the query is anchored to
+func trimPage(messages []*entity.Message, query MessagePageQuery) []*entity.Message {
+	if query.Limit <= 0 || len(messages) <= query.Limit {
+		return messages
+	}
+	if query.forward() {
+		return messages[:query.Limit]
+	}
+	return messages[len(messages)-query.Limit:]
+}
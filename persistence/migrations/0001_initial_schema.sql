@@ -0,0 +1,94 @@
+-- Every table keeps the full entity as JSON in data, the same document Firebase stores.
+-- The columns next to it are copies of the fields the repositories filter and sort on, so they can be indexed.
+
+CREATE TABLE users (
+    id       TEXT PRIMARY KEY,
+    email    TEXT NOT NULL,
+    username TEXT NOT NULL,
+    data     JSONB NOT NULL
+);
+CREATE INDEX users_email_idx ON users (email);
+CREATE INDEX users_username_idx ON users (username);
+
+CREATE TABLE teams (
+    id   TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    data JSONB NOT NULL
+);
+-- byte order collation, so prefix searches can use the index and sort like Firebase does
+CREATE INDEX teams_name_idx ON teams (name COLLATE "C");
+
+CREATE TABLE team_requests (
+    id      TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    team_id TEXT NOT NULL,
+    data    JSONB NOT NULL
+);
+CREATE INDEX team_requests_user_id_idx ON team_requests (user_id);
+CREATE INDEX team_requests_team_id_idx ON team_requests (team_id);
+
+CREATE TABLE friend_requests (
+    from_user_id TEXT NOT NULL,
+    to_user_id   TEXT NOT NULL,
+    status       TEXT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL,
+    data         JSONB NOT NULL,
+    PRIMARY KEY (from_user_id, to_user_id)
+);
+CREATE INDEX friend_requests_to_status_idx ON friend_requests (to_user_id, status);
+CREATE INDEX friend_requests_from_status_idx ON friend_requests (from_user_id, status);
+
+CREATE TABLE messages (
+    id       TEXT PRIMARY KEY,
+    conv_key TEXT NOT NULL DEFAULT '',
+    team_id  TEXT NOT NULL DEFAULT '',
+    sent_at  TIMESTAMPTZ NOT NULL,
+    data     JSONB NOT NULL
+);
+CREATE INDEX messages_conv_key_sent_at_idx ON messages (conv_key, sent_at) WHERE conv_key <> '';
+CREATE INDEX messages_team_id_sent_at_idx ON messages (team_id, sent_at) WHERE team_id <> '';
+
+CREATE TABLE quizzes (
+    id           TEXT PRIMARY KEY,
+    user_id      TEXT NOT NULL,
+    team_id      TEXT NOT NULL,
+    user_team_id TEXT NOT NULL,
+    data         JSONB NOT NULL
+);
+CREATE INDEX quizzes_user_id_idx ON quizzes (user_id, id);
+CREATE INDEX quizzes_team_id_idx ON quizzes (team_id, id);
+CREATE INDEX quizzes_user_team_id_idx ON quizzes (user_team_id, id);
+
+CREATE TABLE events (
+    id      TEXT PRIMARY KEY,
+    team_id TEXT NOT NULL,
+    data    JSONB NOT NULL
+);
+CREATE INDEX events_team_id_idx ON events (team_id);
+
+CREATE TABLE files (
+    id           TEXT PRIMARY KEY,
+    context_type TEXT NOT NULL,
+    context_id   TEXT NOT NULL,
+    created_at   BIGINT NOT NULL DEFAULT 0,
+    data         JSONB NOT NULL
+);
+CREATE INDEX files_context_idx ON files (context_type, context_id, created_at, id);
+
+CREATE TABLE sessions (
+    id      TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    data    JSONB NOT NULL
+);
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+
+CREATE TABLE revoked_tokens (
+    jti        TEXT PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL,
+    data       JSONB NOT NULL
+);
+
+CREATE TABLE user_tokens (
+    id   TEXT PRIMARY KEY,
+    data JSONB NOT NULL
+);
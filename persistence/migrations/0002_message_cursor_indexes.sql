@@ -0,0 +1,6 @@
+-- Message history is paged by (sent_at, id), so the thread indexes carry the id as a tie breaker.
+DROP INDEX messages_conv_key_sent_at_idx;
+DROP INDEX messages_team_id_sent_at_idx;
+
+CREATE INDEX messages_conv_key_sent_at_id_idx ON messages (conv_key, sent_at, id COLLATE "C") WHERE conv_key <> '';
+CREATE INDEX messages_team_id_sent_at_id_idx ON messages (team_id, sent_at, id COLLATE "C") WHERE team_id <> '';
@@ -0,0 +1,13 @@
+-- Real-time events kept for replay. hub_event_seqs holds the last sequence number handed out to each user,
+-- so numbers keep growing after old events are trimmed.
+CREATE TABLE hub_event_seqs (
+    user_id TEXT PRIMARY KEY,
+    seq     BIGINT NOT NULL
+);
+
+CREATE TABLE hub_events (
+    user_id TEXT NOT NULL,
+    seq     BIGINT NOT NULL,
+    data    JSONB NOT NULL,
+    PRIMARY KEY (user_id, seq)
+);
@@ -0,0 +1,4 @@
+CREATE TABLE presence (
+    user_id TEXT PRIMARY KEY,
+    data    JSONB NOT NULL
+);
@@ -0,0 +1,6 @@
+CREATE TABLE read_cursors (
+    user_id TEXT NOT NULL,
+    thread  TEXT NOT NULL,
+    data    JSONB NOT NULL,
+    PRIMARY KEY (user_id, thread)
+);
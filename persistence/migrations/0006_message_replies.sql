@@ -0,0 +1,4 @@
+-- Thread replies are paged under their parent and left out of the conversation and team pages.
+ALTER TABLE messages ADD COLUMN parent_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX messages_parent_id_sent_at_id_idx ON messages (parent_id, sent_at, id COLLATE "C") WHERE parent_id <> '';
@@ -0,0 +1,8 @@
+-- Shareable links that let users join a team until they expire or run out of uses.
+CREATE TABLE team_invite_links (
+    id      TEXT PRIMARY KEY,
+    team_id TEXT NOT NULL,
+    data    JSONB NOT NULL
+);
+
+CREATE INDEX team_invite_links_team_id_idx ON team_invite_links (team_id);
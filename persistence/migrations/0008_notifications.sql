@@ -0,0 +1,8 @@
+-- What users are told about: friend and team requests, event invites, mentions and the like.
+CREATE TABLE notifications (
+    id      TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    data    JSONB NOT NULL
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id);
@@ -0,0 +1,5 @@
+-- Per-user notification settings: channels by type, muted teams and quiet hours.
+CREATE TABLE notification_preferences (
+    user_id TEXT PRIMARY KEY,
+    data    JSONB NOT NULL
+);
@@ -0,0 +1,2 @@
+-- Outstanding tokens are invalidated by user when their email changes
+CREATE INDEX user_tokens_user_id_idx ON user_tokens ((data->>'userId'));
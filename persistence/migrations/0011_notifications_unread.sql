@@ -0,0 +1,2 @@
+-- Unread notifications are counted by user every time one is delivered
+CREATE INDEX notifications_unread_idx ON notifications (user_id) WHERE data->>'readAt' IS NULL;
@@ -0,0 +1,9 @@
+-- Sign-up and profile updates check for a taken email or username before saving, which two requests can both pass.
+-- Fails while duplicates exist; they have to be resolved by hand first. Users without an email or username are left out.
+DROP INDEX users_email_idx;
+DROP INDEX users_username_idx;
+CREATE UNIQUE INDEX users_email_idx ON users (email) WHERE email <> '';
+CREATE UNIQUE INDEX users_username_idx ON users (username) WHERE username <> '';
+
+-- Revoked tokens are deleted once they expire
+CREATE INDEX revoked_tokens_expires_at_idx ON revoked_tokens (expires_at);
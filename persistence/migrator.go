@@ -0,0 +1,510 @@
+package persistence
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+const defaultMigrationBatchSize = 500
+
+var ErrVerificationFailed = errors.New("verification failed")
+
+// Backend bundles the reader and the repositories of one storage backend, so the Migrator
+// can read raw documents from one and write entities to another
+type Backend struct {
+	Reader         CollectionReader
+	Patcher        DocumentPatcher
+	Users          UserRepositoryInterface
+	Teams          TeamRepositoryInterface
+	TeamRequests   TeamRequestRepositoryInterface
+	InviteLinks    TeamInviteLinkRepositoryInterface
+	FriendRequests FriendRequestRepositoryInterface
+	Messages       MessageRepositoryInterface
+	Quizzes        QuizRepositoryInterface
+	Events         EventRepositoryInterface
+	Files          FileRepositoryInterface
+	Sessions       SessionRepositoryInterface
+	UserTokens     UserTokenRepositoryInterface
+	Presence       PresenceRepositoryInterface
+	Notifications  NotificationRepositoryInterface
+	Preferences    NotificationPreferencesRepositoryInterface
+}
+
+// NewBackend returns the active backend
+func NewBackend() *Backend {
+	if sqlDB != nil {
+		return NewPostgresBackend(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalBackend(localStore)
+	}
+	return NewFirebaseBackend()
+}
+
+// NewFirebaseBackend uses config.FirebaseDB, which must already be initialized
+func NewFirebaseBackend() *Backend {
+	return &Backend{
+		Reader:         NewFirebaseCollectionReader(),
+		Patcher:        &FirebasePatcher{},
+		Users:          &UserRepository{},
+		Teams:          &TeamRepository{},
+		TeamRequests:   &TeamRequestRepository{},
+		InviteLinks:    &TeamInviteLinkRepository{},
+		FriendRequests: &FriendRequestRepository{},
+		Messages:       &MessageRepository{},
+		Quizzes:        &QuizRepository{},
+		Events:         &EventRepository{},
+		Files:          &FileRepository{},
+		Sessions:       &SessionRepository{},
+		UserTokens:     &UserTokenRepository{},
+		Presence:       &PresenceRepository{},
+		Notifications:  &NotificationRepository{},
+		Preferences:    &NotificationPreferencesRepository{},
+	}
+}
+
+func NewLocalBackend(store *LocalStore) *Backend {
+	return &Backend{
+		Reader:         NewLocalCollectionReader(store),
+		Patcher:        &LocalPatcher{store: store},
+		Users:          NewLocalUserRepository(store),
+		Teams:          NewLocalTeamRepository(store),
+		TeamRequests:   NewLocalTeamRequestRepository(store),
+		InviteLinks:    NewLocalTeamInviteLinkRepository(store),
+		FriendRequests: NewLocalFriendRequestRepository(store),
+		Messages:       NewLocalMessageRepository(store),
+		Quizzes:        NewLocalQuizRepository(store),
+		Events:         NewLocalEventRepository(store),
+		Files:          NewLocalFileRepository(store),
+		Sessions:       NewLocalSessionRepository(store),
+		UserTokens:     NewLocalUserTokenRepository(store),
+		Presence:       NewLocalPresenceRepository(store),
+		Notifications:  NewLocalNotificationRepository(store),
+		Preferences:    NewLocalNotificationPreferencesRepository(store),
+	}
+}
+
+func NewPostgresBackend(db *sql.DB) *Backend {
+	return &Backend{
+		Reader:         NewPostgresCollectionReader(db),
+		Patcher:        &PostgresPatcher{db: db},
+		Users:          NewPostgresUserRepository(db),
+		Teams:          NewPostgresTeamRepository(db),
+		TeamRequests:   NewPostgresTeamRequestRepository(db),
+		InviteLinks:    NewPostgresTeamInviteLinkRepository(db),
+		FriendRequests: NewPostgresFriendRequestRepository(db),
+		Messages:       NewPostgresMessageRepository(db),
+		Quizzes:        NewPostgresQuizRepository(db),
+		Events:         NewPostgresEventRepository(db),
+		Files:          NewPostgresFileRepository(db),
+		Sessions:       NewPostgresSessionRepository(db),
+		UserTokens:     NewPostgresUserTokenRepository(db),
+		Presence:       NewPostgresPresenceRepository(db),
+		Notifications:  NewPostgresNotificationRepository(db),
+		Preferences:    NewPostgresNotificationPreferencesRepository(db),
+	}
+}
+
+// migratedCollection knows how to turn a raw document into its entity and how to store that entity.
+// Going through the entity types keeps the field mapping the same as in the repositories.
+type migratedCollection struct {
+	name   string
+	decode func(data json.RawMessage) (key string, value interface{}, err error)
+	write  func(target *Backend, value interface{}) error
+}
+
+// collection builds a migratedCollection for entity type T. key returns the document key,
+// or "" for documents that are not valid records and should be skipped. Documents are backfilled before decoding.
+func collection[T any](name string, key func(*T) string, normalize func(*T), write func(*Backend, *T) error) migratedCollection {
+	return migratedCollection{
+		name: name,
+		decode: func(data json.RawMessage) (string, interface{}, error) {
+			data, err := backfillDocument(name, data)
+			if err != nil {
+				return "", nil, err
+			}
+			item := new(T)
+			if err := json.Unmarshal(data, item); err != nil {
+				return "", nil, err
+			}
+			if normalize != nil {
+				normalize(item)
+			}
+			return key(item), item, nil
+		},
+		write: func(target *Backend, value interface{}) error {
+			return write(target, value.(*T))
+		},
+	}
+}
+
+// migratedCollections lists every collection in the order they are copied
+var migratedCollections = []migratedCollection{
+	collection(usersCollection,
+		func(u *entity.User) string { return u.ID }, nil,
+		func(b *Backend, u *entity.User) error { return b.Users.Create(u) }),
+	collection(teamsCollection,
+		func(t *entity.Team) string { return t.Id }, nil,
+		func(b *Backend, t *entity.Team) error { return b.Teams.Create(t) }),
+	collection(teamRequestsCollection,
+		func(r *entity.TeamRequest) string { return r.Id }, nil,
+		func(b *Backend, r *entity.TeamRequest) error { return b.TeamRequests.Create(r) }),
+	collection(teamInviteLinksCollection,
+		func(l *entity.TeamInviteLink) string { return l.ID }, nil,
+		func(b *Backend, l *entity.TeamInviteLink) error { return b.InviteLinks.Create(l) }),
+	collection(friendRequestsPath,
+		func(r *entity.FriendRequest) string {
+			if r.FromUserID == "" || r.ToUserID == "" {
+				return ""
+			}
+			return r.Key()
+		}, nil,
+		func(b *Backend, r *entity.FriendRequest) error { return b.FriendRequests.Create(r) }),
+	collection(messagesCollection,
+		func(m *entity.Message) string { return m.ID }, nil,
+		func(b *Backend, m *entity.Message) error { return b.Messages.Create(m) }),
+	collection(quizCollection,
+		func(q *entity.Quiz) string { return q.ID },
+		func(q *entity.Quiz) {
+			// older quizzes were written before the composite key existed
+			if q.UserTeamId == "" && q.UserID != "" && q.TeamID != "" {
+				q.UserTeamId = q.UserID + "_" + q.TeamID
+			}
+		},
+		func(b *Backend, q *entity.Quiz) error { return b.Quizzes.Create(*q) }),
+	collection(eventsCollection,
+		func(e *entity.Event) string { return e.ID }, nil,
+		func(b *Backend, e *entity.Event) error { return b.Events.Create(e) }),
+	collection(filesCollection,
+		func(f *entity.File) string { return f.ID }, nil,
+		func(b *Backend, f *entity.File) error { return b.Files.Create(f) }),
+	collection(sessionsCollection,
+		func(s *entity.Session) string { return s.ID }, nil,
+		func(b *Backend, s *entity.Session) error { return b.Sessions.Create(s) }),
+	collection(revokedTokensCollection,
+		func(t *entity.RevokedToken) string { return t.JTI }, nil,
+		func(b *Backend, t *entity.RevokedToken) error { return b.Sessions.RevokeToken(t) }),
+	collection(userTokensCollection,
+		func(t *entity.UserToken) string { return t.ID }, nil,
+		func(b *Backend, t *entity.UserToken) error { return b.UserTokens.Create(t) }),
+	collection(presenceCollection,
+		func(p *entity.Presence) string { return p.UserID }, nil,
+		func(b *Backend, p *entity.Presence) error { return b.Presence.Save(p) }),
+	collection(notificationsCollection,
+		func(n *entity.Notification) string { return n.ID }, nil,
+		func(b *Backend, n *entity.Notification) error { return b.Notifications.Create(n) }),
+	collection(notificationPreferencesCollection,
+		func(p *entity.NotificationPreferences) string { return p.UserID }, nil,
+		func(b *Backend, p *entity.NotificationPreferences) error { return b.Preferences.Save(p) }),
+}
+
+// MigratedCollections returns the names of the collections the Migrator copies, in copy order
+func MigratedCollections() []string {
+	names := make([]string, 0, len(migratedCollections))
+	for _, c := range migratedCollections {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+type MigrationOptions struct {
+	// BatchSize is the number of documents read and written per step; the checkpoint is saved after each batch
+	BatchSize int
+	// DryRun reads and checks everything but writes nothing, neither data nor checkpoint
+	DryRun bool
+	// StatePath is where the checkpoint is kept between runs; empty disables resuming
+	StatePath string
+	// Collections limits the run to these collections; empty means all of them
+	Collections []string
+}
+
+// MigrationCheckpoint records how far the copy of one collection got
+type MigrationCheckpoint struct {
+	LastKey string `json:"lastKey"`
+	Copied  int    `json:"copied"`
+	Skipped int    `json:"skipped"`
+	Done    bool   `json:"done"`
+}
+
+type MigrationState struct {
+	Collections map[string]*MigrationCheckpoint `json:"collections"`
+}
+
+// CollectionSummary is the record count and an order independent checksum of one side of a collection
+type CollectionSummary struct {
+	Count    int    `json:"count"`
+	Checksum string `json:"checksum"`
+}
+
+type MigrationReport struct {
+	Collection string            `json:"collection"`
+	Copied     int               `json:"copied"`
+	Skipped    int               `json:"skipped"`
+	Source     CollectionSummary `json:"source"`
+	Target     CollectionSummary `json:"target"`
+	Verified   bool              `json:"verified"`
+}
+
+// Migrator copies every collection from one backend to another. Writes are upserts, so a run
+// interrupted mid-batch can simply be started again; with a StatePath it continues after the last finished batch.
+type Migrator struct {
+	source  *Backend
+	target  *Backend
+	options MigrationOptions
+	state   *MigrationState
+}
+
+func NewMigrator(source, target *Backend, options MigrationOptions) (*Migrator, error) {
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultMigrationBatchSize
+	}
+	for _, name := range options.Collections {
+		if findMigratedCollection(name) == nil {
+			return nil, fmt.Errorf("unknown collection %q", name)
+		}
+	}
+
+	state, err := loadMigrationState(options.StatePath)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{source: source, target: target, options: options, state: state}, nil
+}
+
+// Run copies the selected collections and verifies each one after copying it.
+// It returns ErrVerificationFailed, after going through every collection, if any of them did not match.
+func (m *Migrator) Run() ([]MigrationReport, error) {
+	reports := make([]MigrationReport, 0, len(migratedCollections))
+	failed := false
+
+	for _, c := range m.selected() {
+		report, err := m.copyCollection(c)
+		if err != nil {
+			return reports, fmt.Errorf("copy %s: %w", c.name, err)
+		}
+
+		report.Source, report.Target, err = m.summarize(c)
+		if err != nil {
+			return reports, fmt.Errorf("verify %s: %w", c.name, err)
+		}
+		report.Verified = report.Source == report.Target
+		// nothing is written in a dry run, so a mismatch there only shows what would change
+		if !report.Verified && !m.options.DryRun {
+			failed = true
+		}
+		reports = append(reports, report)
+	}
+
+	if failed {
+		return reports, ErrVerificationFailed
+	}
+	return reports, nil
+}
+
+// Verify compares the selected collections between source and target without copying anything
+func (m *Migrator) Verify() ([]MigrationReport, error) {
+	reports := make([]MigrationReport, 0, len(migratedCollections))
+	failed := false
+
+	for _, c := range m.selected() {
+		source, target, err := m.summarize(c)
+		if err != nil {
+			return reports, fmt.Errorf("verify %s: %w", c.name, err)
+		}
+		report := MigrationReport{Collection: c.name, Source: source, Target: target, Verified: source == target}
+		failed = failed || !report.Verified
+		reports = append(reports, report)
+	}
+
+	if failed {
+		return reports, ErrVerificationFailed
+	}
+	return reports, nil
+}
+
+func (m *Migrator) copyCollection(c migratedCollection) (MigrationReport, error) {
+	checkpoint := m.checkpoint(c.name)
+	report := MigrationReport{Collection: c.name}
+	if checkpoint.Done {
+		report.Copied, report.Skipped = checkpoint.Copied, checkpoint.Skipped
+		return report, nil
+	}
+
+	lastKey, copied, skipped := checkpoint.LastKey, checkpoint.Copied, checkpoint.Skipped
+	for {
+		docs, err := m.source.Reader.Page(c.name, lastKey, m.options.BatchSize)
+		if err != nil {
+			return report, err
+		}
+		if len(docs) == 0 {
+			break
+		}
+
+		for _, doc := range docs {
+			key, value, err := c.decode(doc.Data)
+			if err != nil || key == "" {
+				log.Printf("migrate: skipping %s/%s: not a valid record", c.name, doc.Key)
+				skipped++
+				continue
+			}
+			if !m.options.DryRun {
+				if err := c.write(m.target, value); err != nil {
+					return report, fmt.Errorf("write %s/%s: %w", c.name, key, err)
+				}
+			}
+			copied++
+		}
+		lastKey = docs[len(docs)-1].Key
+
+		if !m.options.DryRun {
+			checkpoint.LastKey, checkpoint.Copied, checkpoint.Skipped = lastKey, copied, skipped
+			if err := m.saveState(); err != nil {
+				return report, err
+			}
+		}
+		if len(docs) < m.options.BatchSize {
+			break
+		}
+	}
+
+	if !m.options.DryRun {
+		checkpoint.Done = true
+		if err := m.saveState(); err != nil {
+			return report, err
+		}
+	}
+	report.Copied, report.Skipped = copied, skipped
+	return report, nil
+}
+
+func (m *Migrator) summarize(c migratedCollection) (CollectionSummary, CollectionSummary, error) {
+	source, err := summarizeCollection(m.source.Reader, c, m.options.BatchSize)
+	if err != nil {
+		return CollectionSummary{}, CollectionSummary{}, fmt.Errorf("source: %w", err)
+	}
+	target, err := summarizeCollection(m.target.Reader, c, m.options.BatchSize)
+	if err != nil {
+		return CollectionSummary{}, CollectionSummary{}, fmt.Errorf("target: %w", err)
+	}
+	return source, target, nil
+}
+
+// summarizeCollection counts the valid records of a collection and XORs the hashes of their
+// key and re-encoded entity, so both sides compare equal whatever order their backend keeps them in
+func summarizeCollection(reader CollectionReader, c migratedCollection, batchSize int) (CollectionSummary, error) {
+	var sum [sha256.Size]byte
+	count := 0
+	lastKey := ""
+
+	for {
+		docs, err := reader.Page(c.name, lastKey, batchSize)
+		if err != nil {
+			return CollectionSummary{}, err
+		}
+		for _, doc := range docs {
+			key, value, err := c.decode(doc.Data)
+			if err != nil || key == "" {
+				continue
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return CollectionSummary{}, err
+			}
+			hash := sha256.Sum256(append([]byte(key+"\x00"), encoded...))
+			for i := range sum {
+				sum[i] ^= hash[i]
+			}
+			count++
+		}
+		if len(docs) < batchSize {
+			break
+		}
+		lastKey = docs[len(docs)-1].Key
+	}
+
+	return CollectionSummary{Count: count, Checksum: hex.EncodeToString(sum[:])}, nil
+}
+
+func (m *Migrator) selected() []migratedCollection {
+	if len(m.options.Collections) == 0 {
+		return migratedCollections
+	}
+	selected := make([]migratedCollection, 0, len(m.options.Collections))
+	for _, c := range migratedCollections {
+		for _, name := range m.options.Collections {
+			if c.name == name {
+				selected = append(selected, c)
+			}
+		}
+	}
+	return selected
+}
+
+func (m *Migrator) checkpoint(name string) *MigrationCheckpoint {
+	checkpoint, ok := m.state.Collections[name]
+	if !ok {
+		checkpoint = &MigrationCheckpoint{}
+		m.state.Collections[name] = checkpoint
+	}
+	return checkpoint
+}
+
+func findMigratedCollection(name string) *migratedCollection {
+	for i := range migratedCollections {
+		if migratedCollections[i].name == name {
+			return &migratedCollections[i]
+		}
+	}
+	return nil
+}
+
+func loadMigrationState(path string) (*MigrationState, error) {
+	state := &MigrationState{Collections: make(map[string]*MigrationCheckpoint)}
+	if path == "" {
+		return state, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("read migration state %s: %w", path, err)
+	}
+	if state.Collections == nil {
+		state.Collections = make(map[string]*MigrationCheckpoint)
+	}
+	return state, nil
+}
+
+func (m *Migrator) saveState() error {
+	if m.options.StatePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(m.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(m.options.StatePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	tmp := m.options.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.options.StatePath)
+}
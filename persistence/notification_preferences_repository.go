@@ -0,0 +1,51 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+const (
+	notificationPreferencesCollection = "notificationPreferences"
+	NotificationPreferencesNotFound   = "notification preferences not found"
+)
+
+type NotificationPreferencesRepositoryInterface interface {
+	Save(preferences *entity.NotificationPreferences) error
+	GetByUserID(userID string) (*entity.NotificationPreferences, error)
+}
+
+type NotificationPreferencesRepository struct{}
+
+func NewNotificationPreferencesRepository() NotificationPreferencesRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresNotificationPreferencesRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalNotificationPreferencesRepository(localStore)
+	}
+	return &NotificationPreferencesRepository{}
+}
+
+func (pr *NotificationPreferencesRepository) Save(preferences *entity.NotificationPreferences) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(notificationPreferencesCollection + "/" + preferences.UserID)
+	return ref.Set(ctx, preferences)
+}
+
+func (pr *NotificationPreferencesRepository) GetByUserID(userID string) (*entity.NotificationPreferences, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(notificationPreferencesCollection + "/" + userID)
+
+	var preferences entity.NotificationPreferences
+	if err := ref.Get(ctx, &preferences); err != nil {
+		return nil, err
+	}
+	if preferences.UserID == "" {
+		return nil, errors.New(NotificationPreferencesNotFound)
+	}
+	return &preferences, nil
+}
@@ -0,0 +1,145 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+const (
+	notificationsCollection = "notifications"
+	NotificationNotFound    = "notification not found"
+)
+
+type NotificationRepositoryInterface interface {
+	Create(notification *entity.Notification) error
+	GetByID(id string) (*entity.Notification, error)
+	// GetByUserID returns the notifications of a user, newest first
+	GetByUserID(userID string) ([]*entity.Notification, error)
+	Update(notification *entity.Notification) error
+	// CountUnread counts the unread notifications of a user by type, without loading the read ones
+	CountUnread(userID string) (map[entity.NotificationType]int, error)
+	// MarkAllRead marks every unread notification of the user as read at readAt and returns how many it marked
+	MarkAllRead(userID string, readAt time.Time) (int, error)
+}
+
+type NotificationRepository struct{}
+
+func NewNotificationRepository() NotificationRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresNotificationRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalNotificationRepository(localStore)
+	}
+	return &NotificationRepository{}
+}
+
+func (nr *NotificationRepository) Create(notification *entity.Notification) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(notificationsCollection + "/" + notification.ID)
+	return ref.Set(ctx, notification)
+}
+
+func (nr *NotificationRepository) GetByID(id string) (*entity.Notification, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(notificationsCollection + "/" + id)
+
+	var notification entity.Notification
+	if err := ref.Get(ctx, &notification); err != nil {
+		return nil, err
+	}
+	if notification.ID == "" {
+		return nil, errors.New(NotificationNotFound)
+	}
+	return &notification, nil
+}
+
+func (nr *NotificationRepository) GetByUserID(userID string) ([]*entity.Notification, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(notificationsCollection)
+
+	var notificationsMap map[string]*entity.Notification
+	if err := ref.OrderByChild("userId").EqualTo(userID).Get(ctx, &notificationsMap); err != nil {
+		return nil, err
+	}
+	notifications := make([]*entity.Notification, 0, len(notificationsMap))
+	for _, notification := range notificationsMap {
+		notifications = append(notifications, notification)
+	}
+	sortNotifications(notifications)
+	return notifications, nil
+}
+
+func (nr *NotificationRepository) Update(notification *entity.Notification) error {
+	return nr.Create(notification)
+}
+
+// CountUnread queries the user's notifications by their unreadBy child, which only unread ones have
+func (nr *NotificationRepository) CountUnread(userID string) (map[entity.NotificationType]int, error) {
+	unread, err := nr.getUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+	return countByType(unread), nil
+}
+
+// MarkAllRead sets readAt on every unread notification of the user in one multi-path update
+func (nr *NotificationRepository) MarkAllRead(userID string, readAt time.Time) (int, error) {
+	unread, err := nr.getUnread(userID)
+	if err != nil {
+		return 0, err
+	}
+	if len(unread) == 0 {
+		return 0, nil
+	}
+	updates := make(map[string]interface{})
+	for _, notification := range unread {
+		updates[notification.ID+"/readAt"] = readAt
+		updates[notification.ID+"/unreadBy"] = nil
+	}
+	ctx := context.Background()
+	if err := config.FirebaseDB.NewRef(notificationsCollection).Update(ctx, updates); err != nil {
+		return 0, err
+	}
+	return len(unread), nil
+}
+
+func (nr *NotificationRepository) getUnread(userID string) ([]*entity.Notification, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(notificationsCollection)
+
+	var notificationsMap map[string]*entity.Notification
+	if err := ref.OrderByChild("unreadBy").EqualTo(userID).Get(ctx, &notificationsMap); err != nil {
+		return nil, err
+	}
+	unread := make([]*entity.Notification, 0, len(notificationsMap))
+	for _, notification := range notificationsMap {
+		if !notification.IsRead() {
+			unread = append(unread, notification)
+		}
+	}
+	return unread, nil
+}
+
+func countByType(notifications []*entity.Notification) map[entity.NotificationType]int {
+	counts := make(map[entity.NotificationType]int)
+	for _, notification := range notifications {
+		counts[notification.Type]++
+	}
+	return counts
+}
+
+// sortNotifications orders notifications newest first, by ID among those created at the same time
+func sortNotifications(notifications []*entity.Notification) {
+	sort.Slice(notifications, func(i, j int) bool {
+		if !notifications[i].CreatedAt.Equal(notifications[j].CreatedAt) {
+			return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+		}
+		return notifications[i].ID > notifications[j].ID
+	})
+}
@@ -0,0 +1,219 @@
+package persistence
+
+import (
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	_ "github.com/lib/pq"
+)
+
+const (
+	schemaMigrationsTable = "schema_migrations"
+	migrationsDir         = "migrations"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// sqlDB is set when the postgres backend is active; the repository constructors then
+// return the Postgres implementations
+var sqlDB *sql.DB
+
+// OpenPostgres connects to databaseURL and applies any pending migrations
+func OpenPostgres(databaseURL string) (*sql.DB, error) {
+	if databaseURL == "" {
+		return nil, errors.New("DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect to postgres: %w", err)
+	}
+	if err := Migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migration is one schema change, applied once in version order
+type Migration struct {
+	Version string
+	SQL     string
+}
+
+// Migrations returns the embedded migrations ordered by version.
+// The version is the file name without extension, e.g. "0001_initial_schema".
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		content, err := fs.ReadFile(migrationFiles, migrationsDir+"/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{
+			Version: strings.TrimSuffix(entry.Name(), ".sql"),
+			SQL:     string(content),
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrate applies every migration not yet recorded in schema_migrations.
+// Each migration runs in its own transaction together with its bookkeeping row.
+func Migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + schemaMigrationsTable + ` (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return fmt.Errorf("create %s: %w", schemaMigrationsTable, err)
+	}
+
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		err := inTx(db, func(tx *sql.Tx) error {
+			// serializes concurrent instances starting at the same time
+			if _, err := tx.Exec(`LOCK TABLE ` + schemaMigrationsTable + ` IN EXCLUSIVE MODE`); err != nil {
+				return err
+			}
+
+			var applied bool
+			if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM `+schemaMigrationsTable+` WHERE version = $1)`,
+				migration.Version).Scan(&applied); err != nil {
+				return err
+			}
+			if applied {
+				return nil
+			}
+
+			if _, err := tx.Exec(migration.SQL); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`INSERT INTO `+schemaMigrationsTable+` (version) VALUES ($1)`, migration.Version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("apply migration %s: %w", migration.Version, err)
+		}
+	}
+	return nil
+}
+
+// sqlExecutor is implemented by both *sql.DB and *sql.Tx
+type sqlExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// inTx runs fn in a transaction, committing when it returns nil and rolling back otherwise
+func inTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// toJSON encodes value for a JSONB parameter; lib/pq would send a []byte as bytea
+func toJSON(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// getRow decodes the data column of a single row query into value and reports whether a row was found
+func getRow(db sqlExecutor, value interface{}, query string, args ...interface{}) (bool, error) {
+	var data []byte
+	if err := db.QueryRow(query, args...).Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, json.Unmarshal(data, value)
+}
+
+// listRows decodes the data column of every row returned by query
+func listRows[T any](db sqlExecutor, query string, args ...interface{}) ([]*T, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]*T, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		item := new(T)
+		if err := json.Unmarshal(data, item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// mergeRow applies a Firebase style update to the data column of the row with the given id.
+// The row is locked for the rest of the transaction and the merged document is returned in value.
+func mergeRow(tx *sql.Tx, table, id string, updates map[string]interface{}, value interface{}) (bool, error) {
+	var data []byte
+	err := tx.QueryRow(`SELECT data FROM `+table+` WHERE id = $1 FOR UPDATE`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	doc := make(map[string]interface{})
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return false, err
+	}
+	if err := applyUpdates(doc, updates); err != nil {
+		return false, err
+	}
+
+	merged, err := json.Marshal(doc)
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(merged, value)
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
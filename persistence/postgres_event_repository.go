@@ -0,0 +1,69 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// PostgresEventRepository is the PostgreSQL implementation of EventRepositoryInterface
+type PostgresEventRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresEventRepository(db *sql.DB) *PostgresEventRepository {
+	return &PostgresEventRepository{db: db}
+}
+
+func (er *PostgresEventRepository) Create(event *entity.Event) error {
+	return saveEvent(er.db, event)
+}
+
+func (er *PostgresEventRepository) GetByID(id string) (*entity.Event, error) {
+	var event entity.Event
+	found, err := getRow(er.db, &event, `SELECT data FROM events WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New(EventNotFound)
+	}
+	return &event, nil
+}
+
+func (er *PostgresEventRepository) GetByTeamID(teamId string) ([]*entity.Event, error) {
+	return listRows[entity.Event](er.db, `SELECT data FROM events WHERE team_id = $1 ORDER BY id`, teamId)
+}
+
+// Update merges the fields into the stored event inside a transaction, so two members
+// changing their status at the same time do not overwrite each other
+func (er *PostgresEventRepository) Update(id string, updates map[string]interface{}) error {
+	return inTx(er.db, func(tx *sql.Tx) error {
+		var event entity.Event
+		found, err := mergeRow(tx, "events", id, updates, &event)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.New(EventNotFound)
+		}
+		return saveEvent(tx, &event)
+	})
+}
+
+func (er *PostgresEventRepository) Delete(id string) error {
+	_, err := er.db.Exec(`DELETE FROM events WHERE id = $1`, id)
+	return err
+}
+
+func saveEvent(db sqlExecutor, event *entity.Event) error {
+	data, err := toJSON(event)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO events (id, team_id, data) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET team_id = EXCLUDED.team_id, data = EXCLUDED.data`,
+		event.ID, event.TeamID, data)
+	return err
+}
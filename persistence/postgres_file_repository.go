@@ -0,0 +1,88 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// PostgresFileRepository is the PostgreSQL implementation of FileRepositoryInterface
+type PostgresFileRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresFileRepository(db *sql.DB) *PostgresFileRepository {
+	return &PostgresFileRepository{db: db}
+}
+
+func (fr *PostgresFileRepository) Create(file *entity.File) error {
+	return fr.save(file)
+}
+
+func (fr *PostgresFileRepository) GetByID(id string) (*entity.File, error) {
+	var file entity.File
+	found, err := getRow(fr.db, &file, `SELECT data FROM files WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New(fileNotFound)
+	}
+	return &file, nil
+}
+
+func (fr *PostgresFileRepository) GetAll() ([]*entity.File, error) {
+	return listRows[entity.File](fr.db, `SELECT data FROM files ORDER BY id`)
+}
+
+func (fr *PostgresFileRepository) GetByContextID(contextType, contextID string) ([]*entity.File, error) {
+	return listRows[entity.File](fr.db, `SELECT data FROM files
+		WHERE context_type = $1 AND context_id = $2 ORDER BY created_at, id`, contextType, contextID)
+}
+
+// GetPageByContextID reads the count and the page in one transaction so they agree with each other
+func (fr *PostgresFileRepository) GetPageByContextID(contextType, contextID string, offset, limit int) ([]*entity.File, int, error) {
+	var files []*entity.File
+	var total int
+	err := inTx(fr.db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY`); err != nil {
+			return err
+		}
+		if err := tx.QueryRow(`SELECT count(*) FROM files WHERE context_type = $1 AND context_id = $2`,
+			contextType, contextID).Scan(&total); err != nil {
+			return err
+		}
+
+		var err error
+		files, err = listRows[entity.File](tx, `SELECT data FROM files
+			WHERE context_type = $1 AND context_id = $2 ORDER BY created_at, id OFFSET $3 LIMIT $4`,
+			contextType, contextID, offset, limit)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return files, total, nil
+}
+
+func (fr *PostgresFileRepository) Update(file *entity.File) error {
+	return fr.save(file)
+}
+
+func (fr *PostgresFileRepository) Delete(id string) error {
+	_, err := fr.db.Exec(`DELETE FROM files WHERE id = $1`, id)
+	return err
+}
+
+func (fr *PostgresFileRepository) save(file *entity.File) error {
+	data, err := toJSON(file)
+	if err != nil {
+		return err
+	}
+	_, err = fr.db.Exec(`INSERT INTO files (id, context_type, context_id, created_at, data) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET context_type = EXCLUDED.context_type, context_id = EXCLUDED.context_id,
+		created_at = EXCLUDED.created_at, data = EXCLUDED.data`,
+		file.ID, file.ContextType, file.ContextID, file.CreatedAt, data)
+	return err
+}
@@ -0,0 +1,89 @@
+package persistence
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// PostgresFriendRequestRepository is the PostgreSQL implementation of FriendRequestRepositoryInterface
+type PostgresFriendRequestRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresFriendRequestRepository(db *sql.DB) *PostgresFriendRequestRepository {
+	return &PostgresFriendRequestRepository{db: db}
+}
+
+func (fr *PostgresFriendRequestRepository) Create(request *entity.FriendRequest) error {
+	if err := fr.save(request); err != nil {
+		return fmt.Errorf("create friend request: %w", err)
+	}
+	return nil
+}
+
+func (fr *PostgresFriendRequestRepository) GetByUsers(fromUserID, toUserID string) (*entity.FriendRequest, error) {
+	var request entity.FriendRequest
+	found, err := getRow(fr.db, &request,
+		`SELECT data FROM friend_requests WHERE from_user_id = $1 AND to_user_id = $2`, fromUserID, toUserID)
+	if err != nil {
+		return nil, fmt.Errorf("get friend request %s:%s: %w", fromUserID, toUserID, err)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w", ErrFriendRequestNotFound)
+	}
+	return &request, nil
+}
+
+func (fr *PostgresFriendRequestRepository) Update(request *entity.FriendRequest) error {
+	if err := fr.save(request); err != nil {
+		return fmt.Errorf("update friend request: %w", err)
+	}
+	return nil
+}
+
+func (fr *PostgresFriendRequestRepository) GetPendingRequestsForUser(userID string) ([]*entity.FriendRequest, error) {
+	requests, err := listRows[entity.FriendRequest](fr.db, `SELECT data FROM friend_requests
+		WHERE to_user_id = $1 AND status = $2 ORDER BY created_at`, userID, string(entity.PENDING))
+	if err != nil {
+		return nil, fmt.Errorf("get pending friend requests for user %s: %w", userID, err)
+	}
+	return requests, nil
+}
+
+// GetFriendsForUser looks up both directions separately so each half can use its index
+func (fr *PostgresFriendRequestRepository) GetFriendsForUser(userID string) ([]string, error) {
+	rows, err := fr.db.Query(`
+		SELECT to_user_id FROM friend_requests WHERE from_user_id = $1 AND status = $2
+		UNION
+		SELECT from_user_id FROM friend_requests WHERE to_user_id = $1 AND status = $2`,
+		userID, string(entity.ACCEPTED))
+	if err != nil {
+		return nil, fmt.Errorf("get friends for user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	friends := make([]string, 0)
+	for rows.Next() {
+		var friendID string
+		if err := rows.Scan(&friendID); err != nil {
+			return nil, err
+		}
+		friends = append(friends, friendID)
+	}
+	return friends, rows.Err()
+}
+
+func (fr *PostgresFriendRequestRepository) save(request *entity.FriendRequest) error {
+	data, err := toJSON(request)
+	if err != nil {
+		return err
+	}
+	_, err = fr.db.Exec(`INSERT INTO friend_requests (from_user_id, to_user_id, status, created_at, data)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (from_user_id, to_user_id) DO UPDATE
+		SET status = EXCLUDED.status, created_at = EXCLUDED.created_at, data = EXCLUDED.data`,
+		request.FromUserID, request.ToUserID, string(request.Status), request.CreatedAt, data)
+	return err
+}
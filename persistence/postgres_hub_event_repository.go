@@ -0,0 +1,53 @@
+package persistence
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// PostgresHubEventRepository is the PostgreSQL implementation of HubEventRepositoryInterface
+type PostgresHubEventRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresHubEventRepository(db *sql.DB) *PostgresHubEventRepository {
+	return &PostgresHubEventRepository{db: db}
+}
+
+func (her *PostgresHubEventRepository) Append(userID string, data json.RawMessage) (int64, error) {
+	var seq int64
+	err := inTx(her.db, func(tx *sql.Tx) error {
+		// the upsert locks the user's counter row, so concurrent appends get consecutive numbers
+		err := tx.QueryRow(`INSERT INTO hub_event_seqs (user_id, seq) VALUES ($1, 1)
+			ON CONFLICT (user_id) DO UPDATE SET seq = hub_event_seqs.seq + 1
+			RETURNING seq`, userID).Scan(&seq)
+		if err != nil {
+			return err
+		}
+
+		event, err := toJSON(entity.NewHubEvent(userID, seq, data))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO hub_events (user_id, seq, data) VALUES ($1, $2, $3)`,
+			userID, seq, event); err != nil {
+			return err
+		}
+
+		if cutoff, ok := hubEventTrimCutoff(seq); ok {
+			_, err = tx.Exec(`DELETE FROM hub_events WHERE user_id = $1 AND seq <= $2`, userID, cutoff)
+		}
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return seq, nil
+}
+
+func (her *PostgresHubEventRepository) ListSince(userID string, seq int64, limit int) ([]*entity.HubEvent, error) {
+	return listRows[entity.HubEvent](her.db, `SELECT data FROM hub_events
+		WHERE user_id = $1 AND seq > $2 ORDER BY seq LIMIT $3`, userID, seq, limit)
+}
@@ -0,0 +1,157 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// PostgresMessageRepository is the PostgreSQL implementation of MessageRepositoryInterface
+type PostgresMessageRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresMessageRepository(db *sql.DB) *PostgresMessageRepository {
+	return &PostgresMessageRepository{db: db}
+}
+
+func (mr *PostgresMessageRepository) Create(message *entity.Message) error {
+	return saveMessage(mr.db, message)
+}
+
+func (mr *PostgresMessageRepository) GetByID(id string) (*entity.Message, error) {
+	var message entity.Message
+	found, err := getRow(mr.db, &message, `SELECT data FROM messages WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New(MessageNotFound)
+	}
+	return &message, nil
+}
+
+func (mr *PostgresMessageRepository) GetByConversation(user1Id, user2Id string) ([]*entity.Message, error) {
+	return listRows[entity.Message](mr.db, `SELECT data FROM messages
+		WHERE conv_key = $1 AND conv_key <> '' ORDER BY sent_at, id`, entity.GetConversationKey(user1Id, user2Id))
+}
+
+func (mr *PostgresMessageRepository) GetByTeamID(teamId string) ([]*entity.Message, error) {
+	return listRows[entity.Message](mr.db, `SELECT data FROM messages
+		WHERE team_id = $1 AND team_id <> '' ORDER BY sent_at, id`, teamId)
+}
+
+// conversationThread and teamThread leave out thread replies, which are paged by repliesThread
+const (
+	conversationThread = `conv_key = $1 AND conv_key <> '' AND parent_id = ''`
+	teamThread         = `team_id = $1 AND team_id <> '' AND parent_id = ''`
+	repliesThread      = `parent_id = $1 AND parent_id <> ''`
+)
+
+func (mr *PostgresMessageRepository) GetConversationPage(user1Id, user2Id string, query MessagePageQuery) ([]*entity.Message, error) {
+	return mr.getPage(conversationThread, entity.GetConversationKey(user1Id, user2Id), query)
+}
+
+func (mr *PostgresMessageRepository) GetTeamPage(teamId string, query MessagePageQuery) ([]*entity.Message, error) {
+	return mr.getPage(teamThread, teamId, query)
+}
+
+func (mr *PostgresMessageRepository) GetRepliesPage(parentId string, query MessagePageQuery) ([]*entity.Message, error) {
+	return mr.getPage(repliesThread, parentId, query)
+}
+
+// getPage walks the (thread, sent_at, id) index from the anchored side of the query.
+// Backward pages are read newest first and reversed so every page is returned oldest first.
+func (mr *PostgresMessageRepository) getPage(thread, key string, query MessagePageQuery) ([]*entity.Message, error) {
+	where := []string{thread}
+	args := []interface{}{key}
+	if query.After != nil {
+		args = append(args, query.After.SentAt, query.After.ID)
+		where = append(where, fmt.Sprintf(`(sent_at, id COLLATE "C") > ($%d, $%d)`, len(args)-1, len(args)))
+	}
+	if query.Before != nil {
+		args = append(args, query.Before.SentAt, query.Before.ID)
+		where = append(where, fmt.Sprintf(`(sent_at, id COLLATE "C") < ($%d, $%d)`, len(args)-1, len(args)))
+	}
+	order := "ASC"
+	if !query.forward() {
+		order = "DESC"
+	}
+	limit := sql.NullInt64{Int64: int64(query.Limit), Valid: query.Limit > 0}
+	args = append(args, limit)
+
+	messages, err := listRows[entity.Message](mr.db, fmt.Sprintf(`SELECT data FROM messages WHERE %s
+		ORDER BY sent_at %s, id COLLATE "C" %s LIMIT $%d`, strings.Join(where, " AND "), order, order, len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	if !query.forward() {
+		slices.Reverse(messages)
+	}
+	return messages, nil
+}
+
+func (mr *PostgresMessageRepository) CountConversation(user1Id, user2Id string, query MessageCountQuery) (int, error) {
+	return mr.count(conversationThread, entity.GetConversationKey(user1Id, user2Id), query)
+}
+
+func (mr *PostgresMessageRepository) CountTeam(teamId string, query MessageCountQuery) (int, error) {
+	return mr.count(teamThread, teamId, query)
+}
+
+// count stops scanning the thread after Limit matching rows
+func (mr *PostgresMessageRepository) count(thread, key string, query MessageCountQuery) (int, error) {
+	where := []string{thread, `data->>'deletedAt' IS NULL`}
+	args := []interface{}{key}
+	if query.ExcludeSender != "" {
+		args = append(args, query.ExcludeSender)
+		where = append(where, fmt.Sprintf(`data->>'senderId' <> $%d`, len(args)))
+	}
+	if query.After != nil {
+		args = append(args, query.After.SentAt, query.After.ID)
+		where = append(where, fmt.Sprintf(`(sent_at, id COLLATE "C") > ($%d, $%d)`, len(args)-1, len(args)))
+	}
+	args = append(args, query.Limit)
+
+	var count int
+	err := mr.db.QueryRow(fmt.Sprintf(`SELECT count(*) FROM (SELECT 1 FROM messages WHERE %s LIMIT $%d) AS counted`,
+		strings.Join(where, " AND "), len(args)), args...).Scan(&count)
+	return count, err
+}
+
+// Update merges the fields into the stored message inside a transaction, so concurrent updates do not overwrite each other
+func (mr *PostgresMessageRepository) Update(id string, updates map[string]interface{}) error {
+	return inTx(mr.db, func(tx *sql.Tx) error {
+		var message entity.Message
+		found, err := mergeRow(tx, "messages", id, updates, &message)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.New(MessageNotFound)
+		}
+		return saveMessage(tx, &message)
+	})
+}
+
+func (mr *PostgresMessageRepository) Delete(id string) error {
+	_, err := mr.db.Exec(`DELETE FROM messages WHERE id = $1`, id)
+	return err
+}
+
+func saveMessage(db sqlExecutor, message *entity.Message) error {
+	data, err := toJSON(message)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO messages (id, conv_key, team_id, parent_id, sent_at, data) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE
+		SET conv_key = EXCLUDED.conv_key, team_id = EXCLUDED.team_id, parent_id = EXCLUDED.parent_id,
+			sent_at = EXCLUDED.sent_at, data = EXCLUDED.data`,
+		message.ID, message.ConversationKey, message.TeamID, message.ParentID, message.SentAt, data)
+	return err
+}
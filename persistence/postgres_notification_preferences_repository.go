@@ -0,0 +1,39 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// PostgresNotificationPreferencesRepository is the PostgreSQL implementation of NotificationPreferencesRepositoryInterface
+type PostgresNotificationPreferencesRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresNotificationPreferencesRepository(db *sql.DB) *PostgresNotificationPreferencesRepository {
+	return &PostgresNotificationPreferencesRepository{db: db}
+}
+
+func (pr *PostgresNotificationPreferencesRepository) Save(preferences *entity.NotificationPreferences) error {
+	data, err := toJSON(preferences)
+	if err != nil {
+		return err
+	}
+	_, err = pr.db.Exec(`INSERT INTO notification_preferences (user_id, data) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET data = EXCLUDED.data`, preferences.UserID, data)
+	return err
+}
+
+func (pr *PostgresNotificationPreferencesRepository) GetByUserID(userID string) (*entity.NotificationPreferences, error) {
+	var preferences entity.NotificationPreferences
+	found, err := getRow(pr.db, &preferences, `SELECT data FROM notification_preferences WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New(NotificationPreferencesNotFound)
+	}
+	return &preferences, nil
+}
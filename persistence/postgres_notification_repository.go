@@ -0,0 +1,88 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// PostgresNotificationRepository is the PostgreSQL implementation of NotificationRepositoryInterface
+type PostgresNotificationRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresNotificationRepository(db *sql.DB) *PostgresNotificationRepository {
+	return &PostgresNotificationRepository{db: db}
+}
+
+func (nr *PostgresNotificationRepository) Create(notification *entity.Notification) error {
+	return saveNotification(nr.db, notification)
+}
+
+func (nr *PostgresNotificationRepository) GetByID(id string) (*entity.Notification, error) {
+	var notification entity.Notification
+	found, err := getRow(nr.db, &notification, `SELECT data FROM notifications WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New(NotificationNotFound)
+	}
+	return &notification, nil
+}
+
+func (nr *PostgresNotificationRepository) GetByUserID(userID string) ([]*entity.Notification, error) {
+	return listRows[entity.Notification](nr.db, `SELECT data FROM notifications WHERE user_id = $1
+		ORDER BY (data->>'createdAt')::timestamptz DESC, id DESC`, userID)
+}
+
+func (nr *PostgresNotificationRepository) Update(notification *entity.Notification) error {
+	return saveNotification(nr.db, notification)
+}
+
+func (nr *PostgresNotificationRepository) CountUnread(userID string) (map[entity.NotificationType]int, error) {
+	rows, err := nr.db.Query(`SELECT data->>'type', count(*) FROM notifications
+		WHERE user_id = $1 AND data->>'readAt' IS NULL GROUP BY data->>'type'`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[entity.NotificationType]int)
+	for rows.Next() {
+		var notificationType entity.NotificationType
+		var count int
+		if err := rows.Scan(&notificationType, &count); err != nil {
+			return nil, err
+		}
+		counts[notificationType] = count
+	}
+	return counts, rows.Err()
+}
+
+func (nr *PostgresNotificationRepository) MarkAllRead(userID string, readAt time.Time) (int, error) {
+	readAtJSON, err := toJSON(readAt)
+	if err != nil {
+		return 0, err
+	}
+	result, err := nr.db.Exec(`UPDATE notifications SET data = jsonb_set(data, '{readAt}', $2::jsonb) - 'unreadBy'
+		WHERE user_id = $1 AND data->>'readAt' IS NULL`, userID, readAtJSON)
+	if err != nil {
+		return 0, err
+	}
+	marked, err := result.RowsAffected()
+	return int(marked), err
+}
+
+func saveNotification(db sqlExecutor, notification *entity.Notification) error {
+	data, err := toJSON(notification)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO notifications (id, user_id, data) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET user_id = EXCLUDED.user_id, data = EXCLUDED.data`,
+		notification.ID, notification.UserID, data)
+	return err
+}
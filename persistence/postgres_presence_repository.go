@@ -0,0 +1,44 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/lib/pq"
+)
+
+// PostgresPresenceRepository is the PostgreSQL implementation of PresenceRepositoryInterface
+type PostgresPresenceRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresPresenceRepository(db *sql.DB) *PostgresPresenceRepository {
+	return &PostgresPresenceRepository{db: db}
+}
+
+func (pr *PostgresPresenceRepository) Save(presence *entity.Presence) error {
+	data, err := toJSON(presence)
+	if err != nil {
+		return err
+	}
+	_, err = pr.db.Exec(`INSERT INTO presence (user_id, data) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET data = EXCLUDED.data`, presence.UserID, data)
+	return err
+}
+
+func (pr *PostgresPresenceRepository) GetByUserID(userID string) (*entity.Presence, error) {
+	var presence entity.Presence
+	found, err := getRow(pr.db, &presence, `SELECT data FROM presence WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New(PresenceNotFound)
+	}
+	return &presence, nil
+}
+
+func (pr *PostgresPresenceRepository) GetByUserIDs(userIDs []string) ([]*entity.Presence, error) {
+	return listRows[entity.Presence](pr.db, `SELECT data FROM presence WHERE user_id = ANY($1)`, pq.Array(uniqueIDs(userIDs)))
+}
@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// PostgresQuizRepository is the PostgreSQL implementation of QuizRepositoryInterface
+type PostgresQuizRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresQuizRepository(db *sql.DB) *PostgresQuizRepository {
+	return &PostgresQuizRepository{db: db}
+}
+
+func (qr *PostgresQuizRepository) Create(quiz entity.Quiz) error {
+	return qr.save(quiz)
+}
+
+func (qr *PostgresQuizRepository) Update(quiz entity.Quiz) error {
+	return qr.save(quiz)
+}
+
+func (qr *PostgresQuizRepository) GetById(id string) (entity.Quiz, error) {
+	var quiz entity.Quiz
+	found, err := getRow(qr.db, &quiz, `SELECT data FROM quizzes WHERE id = $1`, id)
+	if err != nil {
+		return entity.Quiz{}, err
+	}
+	if !found {
+		return entity.Quiz{}, errors.New(quizNotFoundError)
+	}
+	return quiz, nil
+}
+
+func (qr *PostgresQuizRepository) GetByUser(id string, pageSize int, lastKey string) ([]entity.Quiz, string, error) {
+	return qr.page("user_id", id, pageSize, lastKey)
+}
+
+func (qr *PostgresQuizRepository) GetByTeam(id string, pageSize int, lastKey string) ([]entity.Quiz, string, error) {
+	return qr.page("team_id", id, pageSize, lastKey)
+}
+
+func (qr *PostgresQuizRepository) GetByUserAndTeam(userId string, teamId string, pageSize int, lastKey string) ([]entity.Quiz, string, error) {
+	return qr.page("user_team_id", userId+"_"+teamId, pageSize, lastKey)
+}
+
+// page returns up to pageSize quizzes where column equals value and the ID comes after lastKey,
+// in ID order, and the key to pass for the next page. column is always one of the indexed constants above.
+func (qr *PostgresQuizRepository) page(column, value string, pageSize int, lastKey string) ([]entity.Quiz, string, error) {
+	limit := sql.NullInt64{Int64: int64(pageSize), Valid: pageSize > 0}
+	matching, err := listRows[entity.Quiz](qr.db, `SELECT data FROM quizzes
+		WHERE `+column+` = $1 AND id > $2 ORDER BY id LIMIT $3`, value, lastKey, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	quizzes := make([]entity.Quiz, 0, len(matching))
+	for _, quiz := range matching {
+		quizzes = append(quizzes, *quiz)
+	}
+
+	var newLastKey string
+	if len(quizzes) > 0 {
+		newLastKey = quizzes[len(quizzes)-1].ID
+	}
+	return quizzes, newLastKey, nil
+}
+
+func (qr *PostgresQuizRepository) save(quiz entity.Quiz) error {
+	data, err := toJSON(quiz)
+	if err != nil {
+		return err
+	}
+	_, err = qr.db.Exec(`INSERT INTO quizzes (id, user_id, team_id, user_team_id, data) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE
+		SET user_id = EXCLUDED.user_id, team_id = EXCLUDED.team_id, user_team_id = EXCLUDED.user_team_id, data = EXCLUDED.data`,
+		quiz.ID, quiz.UserID, quiz.TeamID, quiz.UserTeamId, data)
+	return err
+}
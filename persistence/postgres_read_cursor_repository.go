@@ -0,0 +1,43 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// PostgresReadCursorRepository is the PostgreSQL implementation of ReadCursorRepositoryInterface
+type PostgresReadCursorRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresReadCursorRepository(db *sql.DB) *PostgresReadCursorRepository {
+	return &PostgresReadCursorRepository{db: db}
+}
+
+func (rr *PostgresReadCursorRepository) Save(cursor *entity.ReadCursor) error {
+	data, err := toJSON(cursor)
+	if err != nil {
+		return err
+	}
+	_, err = rr.db.Exec(`INSERT INTO read_cursors (user_id, thread, data) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, thread) DO UPDATE SET data = EXCLUDED.data`, cursor.UserID, cursor.Thread, data)
+	return err
+}
+
+func (rr *PostgresReadCursorRepository) Get(userID, thread string) (*entity.ReadCursor, error) {
+	var cursor entity.ReadCursor
+	found, err := getRow(rr.db, &cursor, `SELECT data FROM read_cursors WHERE user_id = $1 AND thread = $2`, userID, thread)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New(ReadCursorNotFound)
+	}
+	return &cursor, nil
+}
+
+func (rr *PostgresReadCursorRepository) GetByUser(userID string) ([]*entity.ReadCursor, error) {
+	return listRows[entity.ReadCursor](rr.db, `SELECT data FROM read_cursors WHERE user_id = $1 ORDER BY thread`, userID)
+}
@@ -0,0 +1,73 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// PostgresSessionRepository is the PostgreSQL implementation of SessionRepositoryInterface
+type PostgresSessionRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresSessionRepository(db *sql.DB) *PostgresSessionRepository {
+	return &PostgresSessionRepository{db: db}
+}
+
+func (sr *PostgresSessionRepository) Create(session *entity.Session) error {
+	return sr.save(session)
+}
+
+func (sr *PostgresSessionRepository) GetByID(id string) (*entity.Session, error) {
+	var session entity.Session
+	found, err := getRow(sr.db, &session, `SELECT data FROM sessions WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New(SessionNotFound)
+	}
+	return &session, nil
+}
+
+func (sr *PostgresSessionRepository) GetByUserID(userId string) ([]*entity.Session, error) {
+	return listRows[entity.Session](sr.db, `SELECT data FROM sessions WHERE user_id = $1 ORDER BY id`, userId)
+}
+
+func (sr *PostgresSessionRepository) Update(session *entity.Session) error {
+	return sr.save(session)
+}
+
+// RevokeToken also deletes the revoked tokens that have expired, since they fail validation on their own
+func (sr *PostgresSessionRepository) RevokeToken(token *entity.RevokedToken) error {
+	data, err := toJSON(token)
+	if err != nil {
+		return err
+	}
+	if _, err := sr.db.Exec(`DELETE FROM revoked_tokens WHERE expires_at < now()`); err != nil {
+		return err
+	}
+	_, err = sr.db.Exec(`INSERT INTO revoked_tokens (jti, expires_at, data) VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO UPDATE SET expires_at = EXCLUDED.expires_at, data = EXCLUDED.data`,
+		token.JTI, token.ExpiresAt, data)
+	return err
+}
+
+func (sr *PostgresSessionRepository) IsTokenRevoked(jti string) (bool, error) {
+	var revoked bool
+	err := sr.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`, jti).Scan(&revoked)
+	return revoked, err
+}
+
+func (sr *PostgresSessionRepository) save(session *entity.Session) error {
+	data, err := toJSON(session)
+	if err != nil {
+		return err
+	}
+	_, err = sr.db.Exec(`INSERT INTO sessions (id, user_id, data) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET user_id = EXCLUDED.user_id, data = EXCLUDED.data`,
+		session.ID, session.UserID, data)
+	return err
+}
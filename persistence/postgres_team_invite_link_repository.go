@@ -0,0 +1,84 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// PostgresTeamInviteLinkRepository is the PostgreSQL implementation of TeamInviteLinkRepositoryInterface
+type PostgresTeamInviteLinkRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTeamInviteLinkRepository(db *sql.DB) *PostgresTeamInviteLinkRepository {
+	return &PostgresTeamInviteLinkRepository{db: db}
+}
+
+func (lr *PostgresTeamInviteLinkRepository) Create(link *entity.TeamInviteLink) error {
+	return saveTeamInviteLink(lr.db, link)
+}
+
+func (lr *PostgresTeamInviteLinkRepository) GetByID(id string) (*entity.TeamInviteLink, error) {
+	var link entity.TeamInviteLink
+	found, err := getRow(lr.db, &link, `SELECT data FROM team_invite_links WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New(TeamInviteLinkNotFound)
+	}
+	return &link, nil
+}
+
+func (lr *PostgresTeamInviteLinkRepository) GetByTeamID(teamId string) ([]*entity.TeamInviteLink, error) {
+	return listRows[entity.TeamInviteLink](lr.db, `SELECT data FROM team_invite_links WHERE team_id = $1 ORDER BY id`, teamId)
+}
+
+func (lr *PostgresTeamInviteLinkRepository) Redeem(id string, now time.Time) (*entity.TeamInviteLink, error) {
+	var link entity.TeamInviteLink
+	err := inTx(lr.db, func(tx *sql.Tx) error {
+		// the row stays locked until the use is counted, so the last use goes to one caller
+		found, err := getRow(tx, &link, `SELECT data FROM team_invite_links WHERE id = $1 FOR UPDATE`, id)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.New(TeamInviteLinkNotFound)
+		}
+		if !link.IsUsable(now) {
+			return errors.New(TeamInviteLinkUnusable)
+		}
+		link.Uses++
+		return saveTeamInviteLink(tx, &link)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (lr *PostgresTeamInviteLinkRepository) Release(id string) error {
+	_, err := lr.db.Exec(`UPDATE team_invite_links
+		SET data = jsonb_set(data, '{uses}', to_jsonb(GREATEST((data->>'uses')::int - 1, 0)))
+		WHERE id = $1`, id)
+	return err
+}
+
+func (lr *PostgresTeamInviteLinkRepository) Delete(id string) error {
+	_, err := lr.db.Exec(`DELETE FROM team_invite_links WHERE id = $1`, id)
+	return err
+}
+
+func saveTeamInviteLink(db sqlExecutor, link *entity.TeamInviteLink) error {
+	data, err := toJSON(link)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO team_invite_links (id, team_id, data) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET team_id = EXCLUDED.team_id, data = EXCLUDED.data`,
+		link.ID, link.TeamID, data)
+	return err
+}
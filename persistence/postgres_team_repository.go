@@ -0,0 +1,67 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// PostgresTeamRepository is the PostgreSQL implementation of TeamRepositoryInterface
+type PostgresTeamRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTeamRepository(db *sql.DB) *PostgresTeamRepository {
+	return &PostgresTeamRepository{db: db}
+}
+
+func (tr *PostgresTeamRepository) Create(team *entity.Team) error {
+	return saveTeam(tr.db, team)
+}
+
+func (tr *PostgresTeamRepository) GetTeamById(id string) (*entity.Team, error) {
+	var team entity.Team
+	found, err := getRow(tr.db, &team, `SELECT data FROM teams WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New(teamNotFound)
+	}
+	return &team, nil
+}
+
+func (tr *PostgresTeamRepository) GetXTeamsByPrefix(prefix string, x int) ([]*entity.Team, error) {
+	return listRows[entity.Team](tr.db, `SELECT data FROM teams
+		WHERE name COLLATE "C" LIKE $1 ESCAPE '\'
+		ORDER BY name COLLATE "C", id LIMIT $2`, escapeLike(prefix)+"%", x)
+}
+
+func (tr *PostgresTeamRepository) GetTeamsByName(name string) ([]*entity.Team, error) {
+	return listRows[entity.Team](tr.db, `SELECT data FROM teams WHERE name = $1 ORDER BY id`, name)
+}
+
+func (tr *PostgresTeamRepository) GetAll() ([]*entity.Team, error) {
+	return listRows[entity.Team](tr.db, `SELECT data FROM teams ORDER BY id`)
+}
+
+func (tr *PostgresTeamRepository) Update(team *entity.Team) error {
+	return saveTeam(tr.db, team)
+}
+
+func (tr *PostgresTeamRepository) Delete(id string) error {
+	_, err := tr.db.Exec(`DELETE FROM teams WHERE id = $1`, id)
+	return err
+}
+
+func saveTeam(db sqlExecutor, team *entity.Team) error {
+	data, err := toJSON(team)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO teams (id, name, data) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, data = EXCLUDED.data`,
+		team.Id, team.Name, data)
+	return err
+}
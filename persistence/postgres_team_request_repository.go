@@ -0,0 +1,80 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// PostgresTeamRequestRepository is the PostgreSQL implementation of TeamRequestRepositoryInterface
+type PostgresTeamRequestRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTeamRequestRepository(db *sql.DB) *PostgresTeamRequestRepository {
+	return &PostgresTeamRequestRepository{db: db}
+}
+
+func (tr *PostgresTeamRequestRepository) Create(req *entity.TeamRequest) error {
+	return tr.save(req)
+}
+
+func (tr *PostgresTeamRequestRepository) Update(req *entity.TeamRequest) error {
+	return tr.save(req)
+}
+
+func (tr *PostgresTeamRequestRepository) UpdateIfStatus(req *entity.TeamRequest, status entity.TeamRequestStatus) (bool, error) {
+	data, err := toJSON(req)
+	if err != nil {
+		return false, err
+	}
+	result, err := tr.db.Exec(`UPDATE team_requests SET data = $2
+		WHERE id = $1 AND COALESCE(NULLIF(data->>'status', ''), $3) = $4`,
+		req.Id, data, string(entity.TeamRequestPending), string(status))
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
+
+func (tr *PostgresTeamRequestRepository) save(req *entity.TeamRequest) error {
+	data, err := toJSON(req)
+	if err != nil {
+		return err
+	}
+	_, err = tr.db.Exec(`INSERT INTO team_requests (id, user_id, team_id, data) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET user_id = EXCLUDED.user_id, team_id = EXCLUDED.team_id, data = EXCLUDED.data`,
+		req.Id, req.UserID, req.TeamID, data)
+	return err
+}
+
+func (tr *PostgresTeamRequestRepository) GetById(id string) (*entity.TeamRequest, error) {
+	var req entity.TeamRequest
+	found, err := getRow(tr.db, &req, `SELECT data FROM team_requests WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New(requestNotFound)
+	}
+	return &req, nil
+}
+
+func (tr *PostgresTeamRequestRepository) Delete(id string) error {
+	_, err := tr.db.Exec(`DELETE FROM team_requests WHERE id = $1`, id)
+	return err
+}
+
+func (tr *PostgresTeamRequestRepository) GetAll() ([]*entity.TeamRequest, error) {
+	return listRows[entity.TeamRequest](tr.db, `SELECT data FROM team_requests ORDER BY id`)
+}
+
+func (tr *PostgresTeamRequestRepository) GetByUserId(userId string) ([]*entity.TeamRequest, error) {
+	return listRows[entity.TeamRequest](tr.db, `SELECT data FROM team_requests WHERE user_id = $1 ORDER BY id`, userId)
+}
+
+func (tr *PostgresTeamRequestRepository) GetByTeamId(teamId string) ([]*entity.TeamRequest, error) {
+	return listRows[entity.TeamRequest](tr.db, `SELECT data FROM team_requests WHERE team_id = $1 ORDER BY id`, teamId)
+}
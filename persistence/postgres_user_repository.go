@@ -0,0 +1,89 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/lib/pq"
+)
+
+// PostgresUserRepository is the PostgreSQL implementation of UserRepositoryInterface
+type PostgresUserRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresUserRepository(db *sql.DB) *PostgresUserRepository {
+	return &PostgresUserRepository{db: db}
+}
+
+func (ur *PostgresUserRepository) Create(user *entity.User) error {
+	return saveUser(ur.db, user)
+}
+
+func (ur *PostgresUserRepository) GetByID(id string) (*entity.User, error) {
+	return ur.getOne(`SELECT data FROM users WHERE id = $1`, id)
+}
+
+func (ur *PostgresUserRepository) GetByEmail(email string) (*entity.User, error) {
+	return ur.getOne(`SELECT data FROM users WHERE email = $1 ORDER BY id LIMIT 1`, email)
+}
+
+func (ur *PostgresUserRepository) GetByUsername(username string) (*entity.User, error) {
+	return ur.getOne(`SELECT data FROM users WHERE username = $1 ORDER BY id LIMIT 1`, username)
+}
+
+func (ur *PostgresUserRepository) Update(user *entity.User) error {
+	return saveUser(ur.db, user)
+}
+
+func (ur *PostgresUserRepository) Delete(id string) error {
+	_, err := ur.db.Exec(`DELETE FROM users WHERE id = $1`, id)
+	return err
+}
+
+func (ur *PostgresUserRepository) GetAll() ([]*entity.User, error) {
+	return listRows[entity.User](ur.db, `SELECT data FROM users ORDER BY id`)
+}
+
+func (ur *PostgresUserRepository) GetByIDs(ids []string) ([]*entity.User, error) {
+	return listRows[entity.User](ur.db, `SELECT data FROM users WHERE id = ANY($1) ORDER BY id`, pq.Array(uniqueIDs(ids)))
+}
+
+func saveUser(db sqlExecutor, user *entity.User) error {
+	data, err := toJSON(user)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO users (id, email, username, data) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email, username = EXCLUDED.username, data = EXCLUDED.data`,
+		user.ID, user.Email, user.Username, data)
+	return userTakenError(err)
+}
+
+// userTakenError reports a save rejected by the unique email or username index the way the service reports it
+func userTakenError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23505" {
+		return err
+	}
+	switch pqErr.Constraint {
+	case "users_email_idx":
+		return errors.New(emailTaken)
+	case "users_username_idx":
+		return errors.New(usernameTaken)
+	}
+	return err
+}
+
+func (ur *PostgresUserRepository) getOne(query string, args ...interface{}) (*entity.User, error) {
+	var user entity.User
+	found, err := getRow(ur.db, &user, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New(userNotFound)
+	}
+	return &user, nil
+}
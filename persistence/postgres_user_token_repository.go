@@ -0,0 +1,63 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// PostgresUserTokenRepository is the PostgreSQL implementation of UserTokenRepositoryInterface
+type PostgresUserTokenRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresUserTokenRepository(db *sql.DB) *PostgresUserTokenRepository {
+	return &PostgresUserTokenRepository{db: db}
+}
+
+func (utr *PostgresUserTokenRepository) Create(token *entity.UserToken) error {
+	return utr.save(token)
+}
+
+func (utr *PostgresUserTokenRepository) GetByID(id string) (*entity.UserToken, error) {
+	var token entity.UserToken
+	found, err := getRow(utr.db, &token, `SELECT data FROM user_tokens WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New(UserTokenNotFound)
+	}
+	return &token, nil
+}
+
+func (utr *PostgresUserTokenRepository) Update(token *entity.UserToken) error {
+	return utr.save(token)
+}
+
+func (utr *PostgresUserTokenRepository) MarkUsed(id string) (bool, error) {
+	result, err := utr.db.Exec(`UPDATE user_tokens SET data = jsonb_set(data, '{used}', 'true')
+		WHERE id = $1 AND NOT (data->>'used')::boolean`, id)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
+
+func (utr *PostgresUserTokenRepository) InvalidateByUserID(userID string) error {
+	_, err := utr.db.Exec(`UPDATE user_tokens SET data = jsonb_set(data, '{used}', 'true')
+		WHERE data->>'userId' = $1 AND NOT (data->>'used')::boolean`, userID)
+	return err
+}
+
+func (utr *PostgresUserTokenRepository) save(token *entity.UserToken) error {
+	data, err := toJSON(token)
+	if err != nil {
+		return err
+	}
+	_, err = utr.db.Exec(`INSERT INTO user_tokens (id, data) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data`, token.ID, data)
+	return err
+}
@@ -0,0 +1,88 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+const (
+	presenceCollection = "presence"
+	PresenceNotFound   = "presence not found"
+)
+
+type PresenceRepositoryInterface interface {
+	Save(presence *entity.Presence) error
+	GetByUserID(userID string) (*entity.Presence, error)
+	// GetByUserIDs returns the presence of the users that have one, in no particular order
+	GetByUserIDs(userIDs []string) ([]*entity.Presence, error)
+}
+
+type PresenceRepository struct{}
+
+func NewPresenceRepository() PresenceRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresPresenceRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalPresenceRepository(localStore)
+	}
+	return &PresenceRepository{}
+}
+
+func (pr *PresenceRepository) Save(presence *entity.Presence) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(presenceCollection + "/" + presence.UserID)
+	return ref.Set(ctx, presence)
+}
+
+func (pr *PresenceRepository) GetByUserID(userID string) (*entity.Presence, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(presenceCollection + "/" + userID)
+
+	var presence entity.Presence
+	if err := ref.Get(ctx, &presence); err != nil {
+		return nil, err
+	}
+	if presence.UserID == "" {
+		return nil, errors.New(PresenceNotFound)
+	}
+	return &presence, nil
+}
+
+func (pr *PresenceRepository) GetByUserIDs(userIDs []string) ([]*entity.Presence, error) {
+	userIDs = uniqueIDs(userIDs)
+	presences := make([]*entity.Presence, len(userIDs))
+	errs := make([]error, len(userIDs))
+	sem := make(chan struct{}, maxConcurrentUserReads)
+	var wg sync.WaitGroup
+	for i, userID := range userIDs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			presence, err := pr.GetByUserID(userID)
+			if err != nil && err.Error() != PresenceNotFound {
+				errs[i] = err
+				return
+			}
+			presences[i] = presence
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	found := make([]*entity.Presence, 0, len(presences))
+	for _, presence := range presences {
+		if presence != nil {
+			found = append(found, presence)
+		}
+	}
+	return found, nil
+}
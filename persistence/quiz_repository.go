@@ -29,7 +29,13 @@ type QuizRepositoryInterface interface {
 
 type QuizRepository struct{}
 
-func NewQuizRepository() *QuizRepository {
+func NewQuizRepository() QuizRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresQuizRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalQuizRepository(localStore)
+	}
 	return &QuizRepository{}
 }
 
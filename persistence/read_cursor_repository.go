@@ -0,0 +1,72 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+const (
+	readCursorsCollection = "readCursors"
+	ReadCursorNotFound    = "read cursor not found"
+)
+
+type ReadCursorRepositoryInterface interface {
+	Save(cursor *entity.ReadCursor) error
+	Get(userID, thread string) (*entity.ReadCursor, error)
+	// GetByUser returns every cursor of the user, one per thread they take part in
+	GetByUser(userID string) ([]*entity.ReadCursor, error)
+}
+
+type ReadCursorRepository struct{}
+
+func NewReadCursorRepository() ReadCursorRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresReadCursorRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalReadCursorRepository(localStore)
+	}
+	return &ReadCursorRepository{}
+}
+
+func (rr *ReadCursorRepository) Save(cursor *entity.ReadCursor) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(readCursorsCollection + "/" + cursor.UserID + "/" + cursor.Thread)
+	return ref.Set(ctx, cursor)
+}
+
+func (rr *ReadCursorRepository) Get(userID, thread string) (*entity.ReadCursor, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(readCursorsCollection + "/" + userID + "/" + thread)
+
+	var cursor entity.ReadCursor
+	if err := ref.Get(ctx, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.UserID == "" {
+		return nil, errors.New(ReadCursorNotFound)
+	}
+	return &cursor, nil
+}
+
+func (rr *ReadCursorRepository) GetByUser(userID string) ([]*entity.ReadCursor, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(readCursorsCollection + "/" + userID)
+
+	results, err := ref.OrderByKey().GetOrdered(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cursors := make([]*entity.ReadCursor, 0, len(results))
+	for _, r := range results {
+		var cursor entity.ReadCursor
+		if err := r.Unmarshal(&cursor); err != nil {
+			return nil, err
+		}
+		cursors = append(cursors, &cursor)
+	}
+	return cursors, nil
+}
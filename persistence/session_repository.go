@@ -0,0 +1,101 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+const (
+	sessionsCollection      = "sessions"
+	revokedTokensCollection = "revokedTokens"
+	sessionUserIdField      = "userId"
+	SessionNotFound         = "session not found"
+)
+
+type SessionRepositoryInterface interface {
+	Create(session *entity.Session) error
+	GetByID(id string) (*entity.Session, error)
+	GetByUserID(userId string) ([]*entity.Session, error)
+	Update(session *entity.Session) error
+	RevokeToken(token *entity.RevokedToken) error
+	IsTokenRevoked(jti string) (bool, error)
+}
+
+type SessionRepository struct{}
+
+func NewSessionRepository() SessionRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresSessionRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalSessionRepository(localStore)
+	}
+	return &SessionRepository{}
+}
+
+func (sr *SessionRepository) Create(session *entity.Session) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(sessionsCollection + "/" + session.ID)
+	return ref.Set(ctx, session)
+}
+
+func (sr *SessionRepository) GetByID(id string) (*entity.Session, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(sessionsCollection + "/" + id)
+
+	var session entity.Session
+	if err := ref.Get(ctx, &session); err != nil {
+		return nil, err
+	}
+	if session.ID == "" {
+		return nil, errors.New(SessionNotFound)
+	}
+	return &session, nil
+}
+
+func (sr *SessionRepository) GetByUserID(userId string) ([]*entity.Session, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(sessionsCollection)
+
+	query := ref.OrderByChild(sessionUserIdField).EqualTo(userId)
+	results, err := query.GetOrdered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*entity.Session, 0, len(results))
+	for _, r := range results {
+		var session entity.Session
+		if err := r.Unmarshal(&session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+	return sessions, nil
+}
+
+func (sr *SessionRepository) Update(session *entity.Session) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(sessionsCollection + "/" + session.ID)
+	return ref.Set(ctx, session)
+}
+
+func (sr *SessionRepository) RevokeToken(token *entity.RevokedToken) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(revokedTokensCollection + "/" + token.JTI)
+	return ref.Set(ctx, token)
+}
+
+func (sr *SessionRepository) IsTokenRevoked(jti string) (bool, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(revokedTokensCollection + "/" + jti)
+
+	var token entity.RevokedToken
+	if err := ref.Get(ctx, &token); err != nil {
+		return false, err
+	}
+	return token.JTI != "", nil
+}
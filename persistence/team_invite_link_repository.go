@@ -0,0 +1,128 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"firebase.google.com/go/v4/db"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+const (
+	teamInviteLinksCollection = "teamInviteLinks"
+	TeamInviteLinkNotFound    = "invite link not found"
+	TeamInviteLinkUnusable    = "invite link has expired or reached its usage limit"
+)
+
+type TeamInviteLinkRepositoryInterface interface {
+	Create(link *entity.TeamInviteLink) error
+	GetByID(id string) (*entity.TeamInviteLink, error)
+	GetByTeamID(teamId string) ([]*entity.TeamInviteLink, error)
+	// Redeem counts one use of the link if it is still usable at now, and returns the updated link.
+	// Concurrent redeems of the last use cannot both succeed.
+	Redeem(id string, now time.Time) (*entity.TeamInviteLink, error)
+	// Release gives back a use counted by Redeem when the join it was for failed.
+	// A link that is gone or has no uses is left as it is.
+	Release(id string) error
+	Delete(id string) error
+}
+
+type TeamInviteLinkRepository struct{}
+
+func NewTeamInviteLinkRepository() TeamInviteLinkRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresTeamInviteLinkRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalTeamInviteLinkRepository(localStore)
+	}
+	return &TeamInviteLinkRepository{}
+}
+
+func (lr *TeamInviteLinkRepository) Create(link *entity.TeamInviteLink) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(teamInviteLinksCollection + "/" + link.ID)
+	return ref.Set(ctx, link)
+}
+
+func (lr *TeamInviteLinkRepository) GetByID(id string) (*entity.TeamInviteLink, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(teamInviteLinksCollection + "/" + id)
+
+	var link entity.TeamInviteLink
+	if err := ref.Get(ctx, &link); err != nil {
+		return nil, err
+	}
+	if link.ID == "" {
+		return nil, errors.New(TeamInviteLinkNotFound)
+	}
+	return &link, nil
+}
+
+func (lr *TeamInviteLinkRepository) GetByTeamID(teamId string) ([]*entity.TeamInviteLink, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(teamInviteLinksCollection)
+
+	var linksMap map[string]*entity.TeamInviteLink
+	if err := ref.OrderByChild("teamId").EqualTo(teamId).Get(ctx, &linksMap); err != nil {
+		return nil, err
+	}
+	links := make([]*entity.TeamInviteLink, 0, len(linksMap))
+	for _, link := range linksMap {
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+func (lr *TeamInviteLinkRepository) Redeem(id string, now time.Time) (*entity.TeamInviteLink, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(teamInviteLinksCollection + "/" + id)
+
+	var redeemed entity.TeamInviteLink
+	err := ref.Transaction(ctx, func(node db.TransactionNode) (interface{}, error) {
+		var link entity.TeamInviteLink
+		if err := node.Unmarshal(&link); err != nil {
+			return nil, err
+		}
+		if link.ID == "" {
+			return nil, errors.New(TeamInviteLinkNotFound)
+		}
+		if !link.IsUsable(now) {
+			return nil, errors.New(TeamInviteLinkUnusable)
+		}
+		link.Uses++
+		redeemed = link
+		return &link, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &redeemed, nil
+}
+
+func (lr *TeamInviteLinkRepository) Release(id string) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(teamInviteLinksCollection + "/" + id)
+
+	return ref.Transaction(ctx, func(node db.TransactionNode) (interface{}, error) {
+		var link entity.TeamInviteLink
+		if err := node.Unmarshal(&link); err != nil {
+			return nil, err
+		}
+		if link.ID == "" {
+			return nil, nil
+		}
+		if link.Uses > 0 {
+			link.Uses--
+		}
+		return &link, nil
+	})
+}
+
+func (lr *TeamInviteLinkRepository) Delete(id string) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(teamInviteLinksCollection + "/" + id)
+	return ref.Delete(ctx)
+}
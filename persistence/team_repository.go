@@ -14,10 +14,26 @@ const (
 	teamNotFound    = "team not found"
 )
 
+type TeamRepositoryInterface interface {
+	Create(team *entity.Team) error
+	GetTeamById(id string) (*entity.Team, error)
+	GetXTeamsByPrefix(prefix string, x int) ([]*entity.Team, error)
+	GetTeamsByName(name string) ([]*entity.Team, error)
+	GetAll() ([]*entity.Team, error)
+	Update(team *entity.Team) error
+	Delete(id string) error
+}
+
 type TeamRepository struct {
 }
 
-func NewTeamRepository() *TeamRepository {
+func NewTeamRepository() TeamRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresTeamRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalTeamRepository(localStore)
+	}
 	return &TeamRepository{}
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"firebase.google.com/go/v4/db"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
 )
@@ -13,9 +14,28 @@ const (
 	requestNotFound        = "team request not found"
 )
 
+type TeamRequestRepositoryInterface interface {
+	Create(req *entity.TeamRequest) error
+	GetById(id string) (*entity.TeamRequest, error)
+	GetAll() ([]*entity.TeamRequest, error)
+	GetByUserId(userId string) ([]*entity.TeamRequest, error)
+	GetByTeamId(teamId string) ([]*entity.TeamRequest, error)
+	Update(req *entity.TeamRequest) error
+	// UpdateIfStatus writes req only while the stored request has the given status, a missing status
+	// counting as pending, and reports whether it did
+	UpdateIfStatus(req *entity.TeamRequest, status entity.TeamRequestStatus) (bool, error)
+	Delete(id string) error
+}
+
 type TeamRequestRepository struct{}
 
-func NewTeamRequestRepository() *TeamRequestRepository {
+func NewTeamRequestRepository() TeamRequestRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresTeamRequestRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalTeamRequestRepository(localStore)
+	}
 	return &TeamRequestRepository{}
 }
 
@@ -39,6 +59,42 @@ func (tr *TeamRequestRepository) GetById(id string) (*entity.TeamRequest, error)
 	return &req, nil
 }
 
+func (tr *TeamRequestRepository) Update(req *entity.TeamRequest) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(teamRequestsCollection + "/" + req.Id)
+	return ref.Set(ctx, req)
+}
+
+// errTeamRequestStatusChanged aborts a conditional update of a request whose status is no longer the expected one
+var errTeamRequestStatusChanged = errors.New("team request status changed")
+
+func (tr *TeamRequestRepository) UpdateIfStatus(req *entity.TeamRequest, status entity.TeamRequestStatus) (bool, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(teamRequestsCollection + "/" + req.Id)
+	err := ref.Transaction(ctx, func(node db.TransactionNode) (interface{}, error) {
+		var stored entity.TeamRequest
+		if err := node.Unmarshal(&stored); err != nil {
+			return nil, err
+		}
+		if stored.Id == "" || storedTeamRequestStatus(&stored) != status {
+			return nil, errTeamRequestStatusChanged
+		}
+		return req, nil
+	})
+	if errors.Is(err, errTeamRequestStatusChanged) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// storedTeamRequestStatus is the status a request was stored with; requests from before statuses existed are pending
+func storedTeamRequestStatus(req *entity.TeamRequest) entity.TeamRequestStatus {
+	if req.Status == "" {
+		return entity.TeamRequestPending
+	}
+	return req.Status
+}
+
 func (tr *TeamRequestRepository) Delete(id string) error {
 	ctx := context.Background()
 	ref := config.FirebaseDB.NewRef(teamRequestsCollection + "/" + id)
@@ -74,3 +130,18 @@ func (tr *TeamRequestRepository) GetByUserId(userId string) ([]*entity.TeamReque
 	}
 	return filtered, nil
 }
+
+func (tr *TeamRequestRepository) GetByTeamId(teamId string) ([]*entity.TeamRequest, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(teamRequestsCollection)
+
+	var reqsMap map[string]*entity.TeamRequest
+	if err := ref.OrderByChild("teamid").EqualTo(teamId).Get(ctx, &reqsMap); err != nil {
+		return nil, err
+	}
+	reqs := make([]*entity.TeamRequest, 0, len(reqsMap))
+	for _, r := range reqsMap {
+		reqs = append(reqs, r)
+	}
+	return reqs, nil
+}
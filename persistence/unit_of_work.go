@@ -0,0 +1,366 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"firebase.google.com/go/v4/db"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// UnitOfWork collects changes to users and teams and writes them in one go, so a team and
+// its members never end up disagreeing because only some of the writes went through.
+// Nothing is written until Commit; a UnitOfWork is used for a single Commit.
+//
+// UpdateUser and UpdateTeam change a stored document: change runs at commit time on the document as it is
+// stored then, so changes other requests made since the caller read it are kept. An error from change
+// aborts the commit, and a missing document fails it.
+type UnitOfWork interface {
+	SaveUser(user *entity.User)
+	SaveTeam(team *entity.Team)
+	UpdateUser(id string, change func(user *entity.User) error)
+	UpdateTeam(id string, change func(team *entity.Team) error)
+	DeleteUser(id string)
+	DeleteTeam(id string)
+	Commit() error
+}
+
+type UnitOfWorkFactory interface {
+	Begin() UnitOfWork
+}
+
+// UnitOfWorkFunc adapts a function to UnitOfWorkFactory
+type UnitOfWorkFunc func() UnitOfWork
+
+func (f UnitOfWorkFunc) Begin() UnitOfWork {
+	return f()
+}
+
+// NewUnitOfWorkFactory returns the atomic implementation of the active backend
+func NewUnitOfWorkFactory() UnitOfWorkFactory {
+	if sqlDB != nil {
+		db := sqlDB
+		return UnitOfWorkFunc(func() UnitOfWork { return &PostgresUnitOfWork{db: db} })
+	}
+	if localStore != nil {
+		store := localStore
+		return UnitOfWorkFunc(func() UnitOfWork { return &LocalUnitOfWork{store: store} })
+	}
+	return UnitOfWorkFunc(func() UnitOfWork { return &FirebaseUnitOfWork{} })
+}
+
+// NewRepositoryUnitOfWorkFactory writes through the given repositories one call at a time.
+// It is not atomic; it exists for services built on injected repositories, such as in tests.
+func NewRepositoryUnitOfWorkFactory(users UserRepositoryInterface, teams TeamRepositoryInterface) UnitOfWorkFactory {
+	return UnitOfWorkFunc(func() UnitOfWork { return &RepositoryUnitOfWork{users: users, teams: teams} })
+}
+
+// FirebaseUnitOfWork applies each change to a stored document in a transaction on that document, so none
+// is lost to a concurrent write. RTDB cannot lock several documents at once: the steps run one after the
+// other in the order they were staged, consecutive plain writes going out as one multi-path update.
+// If a step fails, the steps before it stay written and MembershipRepairService reconciles them.
+type FirebaseUnitOfWork struct {
+	steps []firebaseStep
+}
+
+// firebaseStep is either a plain write of path or a transaction on it
+type firebaseStep struct {
+	path   string
+	value  interface{}
+	update db.UpdateFn
+}
+
+func (uow *FirebaseUnitOfWork) SaveUser(user *entity.User) {
+	uow.steps = append(uow.steps, firebaseStep{path: usersCollection + "/" + user.ID, value: user})
+}
+
+func (uow *FirebaseUnitOfWork) SaveTeam(team *entity.Team) {
+	uow.steps = append(uow.steps, firebaseStep{path: teamsCollection + "/" + team.Id, value: team})
+}
+
+func (uow *FirebaseUnitOfWork) UpdateUser(id string, change func(user *entity.User) error) {
+	uow.steps = append(uow.steps, firebaseStep{path: usersCollection + "/" + id, update: func(node db.TransactionNode) (interface{}, error) {
+		var user entity.User
+		if err := node.Unmarshal(&user); err != nil {
+			return nil, err
+		}
+		if user.ID == "" {
+			return nil, errors.New(userNotFound)
+		}
+		if err := change(&user); err != nil {
+			return nil, err
+		}
+		return &user, nil
+	}})
+}
+
+func (uow *FirebaseUnitOfWork) UpdateTeam(id string, change func(team *entity.Team) error) {
+	uow.steps = append(uow.steps, firebaseStep{path: teamsCollection + "/" + id, update: func(node db.TransactionNode) (interface{}, error) {
+		var team entity.Team
+		if err := node.Unmarshal(&team); err != nil {
+			return nil, err
+		}
+		if team.Id == "" {
+			return nil, errors.New(teamNotFound)
+		}
+		if err := change(&team); err != nil {
+			return nil, err
+		}
+		return &team, nil
+	}})
+}
+
+func (uow *FirebaseUnitOfWork) DeleteUser(id string) {
+	uow.steps = append(uow.steps, firebaseStep{path: usersCollection + "/" + id})
+}
+
+func (uow *FirebaseUnitOfWork) DeleteTeam(id string) {
+	uow.steps = append(uow.steps, firebaseStep{path: teamsCollection + "/" + id})
+}
+
+func (uow *FirebaseUnitOfWork) Commit() error {
+	ctx := context.Background()
+	root := config.FirebaseDB.NewRef("/")
+
+	writes := make(map[string]interface{})
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+		err := root.Update(ctx, writes)
+		writes = make(map[string]interface{})
+		return err
+	}
+
+	for _, step := range uow.steps {
+		if step.update == nil {
+			writes[step.path] = step.value
+			continue
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		if err := config.FirebaseDB.NewRef(step.path).Transaction(ctx, step.update); err != nil {
+			return err
+		}
+	}
+	return flush()
+}
+
+// LocalUnitOfWork commits as one LocalStore transaction
+type LocalUnitOfWork struct {
+	store *LocalStore
+	ops   []func(tx *LocalTx) error
+}
+
+func (uow *LocalUnitOfWork) SaveUser(user *entity.User) {
+	uow.ops = append(uow.ops, func(tx *LocalTx) error {
+		tx.Put(usersCollection, user.ID, user)
+		return nil
+	})
+}
+
+func (uow *LocalUnitOfWork) SaveTeam(team *entity.Team) {
+	uow.ops = append(uow.ops, func(tx *LocalTx) error {
+		tx.Put(teamsCollection, team.Id, team)
+		return nil
+	})
+}
+
+func (uow *LocalUnitOfWork) UpdateUser(id string, change func(user *entity.User) error) {
+	uow.ops = append(uow.ops, func(tx *LocalTx) error {
+		var user entity.User
+		found, err := tx.Get(usersCollection, id, &user)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.New(userNotFound)
+		}
+		if err := change(&user); err != nil {
+			return err
+		}
+		tx.Put(usersCollection, id, &user)
+		return nil
+	})
+}
+
+func (uow *LocalUnitOfWork) UpdateTeam(id string, change func(team *entity.Team) error) {
+	uow.ops = append(uow.ops, func(tx *LocalTx) error {
+		var team entity.Team
+		found, err := tx.Get(teamsCollection, id, &team)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.New(teamNotFound)
+		}
+		if err := change(&team); err != nil {
+			return err
+		}
+		tx.Put(teamsCollection, id, &team)
+		return nil
+	})
+}
+
+func (uow *LocalUnitOfWork) DeleteUser(id string) {
+	uow.ops = append(uow.ops, func(tx *LocalTx) error {
+		tx.Delete(usersCollection, id)
+		return nil
+	})
+}
+
+func (uow *LocalUnitOfWork) DeleteTeam(id string) {
+	uow.ops = append(uow.ops, func(tx *LocalTx) error {
+		tx.Delete(teamsCollection, id)
+		return nil
+	})
+}
+
+func (uow *LocalUnitOfWork) Commit() error {
+	return uow.store.Transact(func(tx *LocalTx) error {
+		for _, op := range uow.ops {
+			if err := op(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PostgresUnitOfWork commits all changes in one transaction
+type PostgresUnitOfWork struct {
+	db  *sql.DB
+	ops []func(tx *sql.Tx) error
+}
+
+func (uow *PostgresUnitOfWork) SaveUser(user *entity.User) {
+	uow.ops = append(uow.ops, func(tx *sql.Tx) error { return saveUser(tx, user) })
+}
+
+func (uow *PostgresUnitOfWork) SaveTeam(team *entity.Team) {
+	uow.ops = append(uow.ops, func(tx *sql.Tx) error { return saveTeam(tx, team) })
+}
+
+// UpdateUser locks the user's row until the transaction ends
+func (uow *PostgresUnitOfWork) UpdateUser(id string, change func(user *entity.User) error) {
+	uow.ops = append(uow.ops, func(tx *sql.Tx) error {
+		var user entity.User
+		found, err := getRow(tx, &user, `SELECT data FROM users WHERE id = $1 FOR UPDATE`, id)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.New(userNotFound)
+		}
+		if err := change(&user); err != nil {
+			return err
+		}
+		return saveUser(tx, &user)
+	})
+}
+
+// UpdateTeam locks the team's row until the transaction ends
+func (uow *PostgresUnitOfWork) UpdateTeam(id string, change func(team *entity.Team) error) {
+	uow.ops = append(uow.ops, func(tx *sql.Tx) error {
+		var team entity.Team
+		found, err := getRow(tx, &team, `SELECT data FROM teams WHERE id = $1 FOR UPDATE`, id)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.New(teamNotFound)
+		}
+		if err := change(&team); err != nil {
+			return err
+		}
+		return saveTeam(tx, &team)
+	})
+}
+
+func (uow *PostgresUnitOfWork) DeleteUser(id string) {
+	uow.ops = append(uow.ops, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DELETE FROM users WHERE id = $1`, id)
+		return err
+	})
+}
+
+func (uow *PostgresUnitOfWork) DeleteTeam(id string) {
+	uow.ops = append(uow.ops, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DELETE FROM teams WHERE id = $1`, id)
+		return err
+	})
+}
+
+func (uow *PostgresUnitOfWork) Commit() error {
+	if len(uow.ops) == 0 {
+		return nil
+	}
+	return inTx(uow.db, func(tx *sql.Tx) error {
+		for _, op := range uow.ops {
+			if err := op(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type RepositoryUnitOfWork struct {
+	users UserRepositoryInterface
+	teams TeamRepositoryInterface
+	ops   []func() error
+}
+
+func (uow *RepositoryUnitOfWork) SaveUser(user *entity.User) {
+	uow.ops = append(uow.ops, func() error { return uow.users.Update(user) })
+}
+
+func (uow *RepositoryUnitOfWork) SaveTeam(team *entity.Team) {
+	uow.ops = append(uow.ops, func() error { return uow.teams.Update(team) })
+}
+
+func (uow *RepositoryUnitOfWork) UpdateUser(id string, change func(user *entity.User) error) {
+	uow.ops = append(uow.ops, func() error {
+		user, err := uow.users.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if err := change(user); err != nil {
+			return err
+		}
+		return uow.users.Update(user)
+	})
+}
+
+func (uow *RepositoryUnitOfWork) UpdateTeam(id string, change func(team *entity.Team) error) {
+	uow.ops = append(uow.ops, func() error {
+		team, err := uow.teams.GetTeamById(id)
+		if err != nil {
+			return err
+		}
+		if err := change(team); err != nil {
+			return err
+		}
+		return uow.teams.Update(team)
+	})
+}
+
+func (uow *RepositoryUnitOfWork) DeleteUser(id string) {
+	uow.ops = append(uow.ops, func() error { return uow.users.Delete(id) })
+}
+
+func (uow *RepositoryUnitOfWork) DeleteTeam(id string) {
+	uow.ops = append(uow.ops, func() error { return uow.teams.Delete(id) })
+}
+
+func (uow *RepositoryUnitOfWork) Commit() error {
+	for _, op := range uow.ops {
+		if err := op(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package persistence
 import (
 	"context"
 	"errors"
+	"sync"
 
 	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
@@ -13,11 +14,30 @@ const (
 	emailField      = "email"
 	usernameField   = "username"
 	userNotFound    = "user not found"
+	usernameTaken   = "username already exists"
+	emailTaken      = "email already exists"
 )
 
+type UserRepositoryInterface interface {
+	Create(user *entity.User) error
+	GetByID(id string) (*entity.User, error)
+	GetByEmail(email string) (*entity.User, error)
+	GetByUsername(username string) (*entity.User, error)
+	Update(user *entity.User) error
+	Delete(id string) error
+	GetAll() ([]*entity.User, error)
+	GetByIDs(ids []string) ([]*entity.User, error)
+}
+
 type UserRepository struct{}
 
-func NewUserRepository() *UserRepository {
+func NewUserRepository() UserRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresUserRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalUserRepository(localStore)
+	}
 	return &UserRepository{}
 }
 
@@ -110,3 +130,53 @@ func (ur *UserRepository) GetAll() ([]*entity.User, error) {
 	}
 	return users, nil
 }
+
+// maxConcurrentUserReads bounds the parallel reads GetByIDs issues
+const maxConcurrentUserReads = 8
+
+// GetByIDs reads the users in parallel, RTDB has no multi-key get. Unknown ids are left out of the result.
+func (ur *UserRepository) GetByIDs(ids []string) ([]*entity.User, error) {
+	ids = uniqueIDs(ids)
+	users := make([]*entity.User, len(ids))
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, maxConcurrentUserReads)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			user, err := ur.GetByID(id)
+			if err != nil && err.Error() != userNotFound {
+				errs[i] = err
+				return
+			}
+			users[i] = user
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	found := make([]*entity.User, 0, len(users))
+	for _, user := range users {
+		if user != nil {
+			found = append(found, user)
+		}
+	}
+	return found, nil
+}
+
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,114 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"firebase.google.com/go/v4/db"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+const (
+	userTokensCollection = "userTokens"
+	UserTokenNotFound    = "token not found"
+)
+
+type UserTokenRepositoryInterface interface {
+	Create(token *entity.UserToken) error
+	GetByID(id string) (*entity.UserToken, error)
+	Update(token *entity.UserToken) error
+	// MarkUsed marks the token used unless it already is, reporting whether this call used it
+	MarkUsed(id string) (bool, error)
+	// InvalidateByUserID marks every unused token of the user as used
+	InvalidateByUserID(userID string) error
+}
+
+type UserTokenRepository struct{}
+
+func NewUserTokenRepository() UserTokenRepositoryInterface {
+	if sqlDB != nil {
+		return NewPostgresUserTokenRepository(sqlDB)
+	}
+	if localStore != nil {
+		return NewLocalUserTokenRepository(localStore)
+	}
+	return &UserTokenRepository{}
+}
+
+func (utr *UserTokenRepository) Create(token *entity.UserToken) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(userTokensCollection + "/" + token.ID)
+	return ref.Set(ctx, token)
+}
+
+func (utr *UserTokenRepository) GetByID(id string) (*entity.UserToken, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(userTokensCollection + "/" + id)
+
+	var token entity.UserToken
+	if err := ref.Get(ctx, &token); err != nil {
+		return nil, err
+	}
+	if token.ID == "" {
+		return nil, errors.New(UserTokenNotFound)
+	}
+	return &token, nil
+}
+
+func (utr *UserTokenRepository) Update(token *entity.UserToken) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(userTokensCollection + "/" + token.ID)
+	return ref.Set(ctx, token)
+}
+
+// errTokenUsed aborts marking a token used that is gone or already used
+var errTokenUsed = errors.New("token already used")
+
+// MarkUsed marks the token in a transaction, so of two concurrent calls only one finds it unused
+func (utr *UserTokenRepository) MarkUsed(id string) (bool, error) {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(userTokensCollection + "/" + id)
+
+	err := ref.Transaction(ctx, func(node db.TransactionNode) (interface{}, error) {
+		var token entity.UserToken
+		if err := node.Unmarshal(&token); err != nil {
+			return nil, err
+		}
+		if token.ID == "" || token.Used {
+			return nil, errTokenUsed
+		}
+		token.Used = true
+		return &token, nil
+	})
+	if errors.Is(err, errTokenUsed) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// InvalidateByUserID marks the user's unused tokens in one multi-path update.
+// Requires ".indexOn": "userId" on userTokens.
+func (utr *UserTokenRepository) InvalidateByUserID(userID string) error {
+	ctx := context.Background()
+	ref := config.FirebaseDB.NewRef(userTokensCollection)
+
+	results, err := ref.OrderByChild("userId").EqualTo(userID).GetOrdered(ctx)
+	if err != nil {
+		return err
+	}
+	updates := make(map[string]interface{})
+	for _, r := range results {
+		var token entity.UserToken
+		if err := r.Unmarshal(&token); err != nil {
+			return err
+		}
+		if !token.Used {
+			updates[r.Key()+"/used"] = true
+		}
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	return ref.Update(ctx, updates)
+}
@@ -17,4 +17,14 @@ func FileRoutes(router *gin.Engine) {
 		teams.GET("/:id/files/:fileId", fileController.GetFile)
 		teams.DELETE("/:id/files/:fileId", fileController.DeleteFile)
 	}
+
+	// Files shared in a direct conversation are under /conversations/:userId/files, userId being the other user
+	conversations := router.Group("/conversations")
+	conversations.Use(controller.JWTAuthMiddleware())
+	{
+		conversations.GET("/:userId/files", fileController.GetFilesByConversation)
+		conversations.POST("/:userId/files", fileController.UploadChatFile)
+		conversations.GET("/:userId/files/:fileId", fileController.GetChatFile)
+		conversations.DELETE("/:userId/files/:fileId", fileController.DeleteChatFile)
+	}
 }
@@ -16,7 +16,11 @@ func SetupMessageRoutes(r *gin.Engine) {
 		protected.GET("/messages", messageController.GetMessages)
 		protected.GET("/messages/:id", messageController.GetMessage)
 		protected.GET("/messages/connect", messageController.Connect)
-
-		// TODO: edit messages
+		protected.GET("/messages/unread", messageController.GetUnreadCounts)
+		protected.POST("/messages/:id/read", messageController.MarkRead)
+		protected.POST("/messages/:id/reactions", messageController.ToggleReaction)
+		protected.GET("/messages/:id/replies", messageController.GetReplies)
+		protected.PATCH("/messages/:id", messageController.EditMessage)
+		protected.DELETE("/messages/:id", messageController.DeleteMessage)
 	}
 }
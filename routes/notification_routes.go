@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupNotificationRoutes(r *gin.Engine) {
+	notificationController := controller.NewNotificationController()
+
+	protected := r.Group("/")
+	protected.Use(controller.JWTAuthMiddleware())
+	{
+		protected.GET("/notifications", notificationController.GetNotifications)
+		protected.GET("/notifications/unread", notificationController.GetUnreadCounts)
+		protected.GET("/notifications/preferences", notificationController.GetPreferences)
+		protected.PUT("/notifications/preferences", notificationController.UpdatePreferences)
+		protected.POST("/notifications/read", notificationController.MarkAllRead)
+		protected.POST("/notifications/:id/read", notificationController.MarkRead)
+	}
+}
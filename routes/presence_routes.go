@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupPresenceRoutes(r *gin.Engine) {
+	presenceController := controller.NewPresenceController()
+
+	protected := r.Group("/")
+	protected.Use(controller.JWTAuthMiddleware())
+	{
+		protected.GET("/users/:id/presence", presenceController.GetUserPresence)
+		protected.GET("/teams/:id/presence", presenceController.GetTeamPresence)
+	}
+}
@@ -52,6 +52,10 @@ func SetupRoutes() *gin.Engine {
 	SetupQuizRoutes(r)
 	SetupTeamRequestRoutes(r)
 	SetupEventRoutes(r)
+	SetupPresenceRoutes(r)
+	SetupSearchRoutes(r)
+	SetupTeamRecommendationRoutes(r)
+	SetupNotificationRoutes(r)
 
 	return r
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupSearchRoutes(r *gin.Engine) {
+	searchController := controller.NewSearchController()
+
+	protected := r.Group("/search")
+	protected.Use(controller.JWTAuthMiddleware())
+	{
+		protected.GET("", searchController.Search)
+	}
+}
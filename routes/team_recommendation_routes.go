@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupTeamRecommendationRoutes(r *gin.Engine) {
+	recommendationController := controller.NewTeamRecommendationController()
+
+	protected := r.Group("/")
+	protected.Use(controller.JWTAuthMiddleware())
+	{
+		protected.GET("/teams/recommended", recommendationController.GetRecommendedTeams)
+	}
+}
@@ -13,7 +13,19 @@ func SetupTeamRequestRoutes(r *gin.Engine) {
 		protected.POST("/teamRequests", trController.CreateTeamRequest)
 		protected.PUT("/teamRequests/:id/accept", trController.AcceptTeamRequest)
 		protected.DELETE("/teamRequests/:id/reject", trController.RejectTeamRequest)
+		protected.PUT("/teamRequests/:id/withdraw", trController.WithdrawTeamRequest)
 		protected.GET("/teamRequests", trController.GetAllTeamRequests)
 		protected.GET("/teamRequests/user/:userId", trController.GetTeamRequestsByUser)
+
+		protected.GET("/teams/:id/requests", trController.GetTeamRequests)
+		protected.POST("/teams/:id/invitations", trController.InviteToTeam)
+		protected.GET("/teamInvitations", trController.GetInvitations)
+		protected.PUT("/teamInvitations/:id/accept", trController.AcceptInvitation)
+		protected.DELETE("/teamInvitations/:id/decline", trController.DeclineInvitation)
+
+		protected.POST("/teams/:id/inviteLinks", trController.CreateInviteLink)
+		protected.GET("/teams/:id/inviteLinks", trController.GetInviteLinks)
+		protected.DELETE("/teams/:id/inviteLinks/:token", trController.RevokeInviteLink)
+		protected.POST("/inviteLinks/:token/join", trController.JoinByInviteLink)
 	}
 }
@@ -20,5 +20,8 @@ func SetupTeamRoutes(r *gin.Engine) {
 		protected.GET("/teams", teamController.GetAllTeams)       // Get all teams
 		protected.PUT("/teams/:id", teamController.UpdateTeam)    // Update a team
 		protected.DELETE("/teams/:id", teamController.DeleteTeam) // Delete a team
+
+		protected.PUT("/teams/:id/members/:userId/role", teamController.UpdateMemberRole) // Promote or demote a member
+		protected.PUT("/teams/:id/owner", teamController.TransferOwnership)               // Transfer ownership
 	}
 }
@@ -10,6 +10,13 @@ func SetupUserRoutes(r *gin.Engine) {
 
 	r.POST("/users/signup", userController.SignUp)
 	r.POST("/users/login", userController.Login)
+	r.POST("/users/refresh", userController.RefreshToken)
+	r.POST("/users/logout", controller.JWTAuthMiddleware(), userController.Logout)
+	r.POST("/users/logout-all", controller.JWTAuthMiddleware(), userController.LogoutAll)
+	r.POST("/users/verify-email", userController.VerifyEmail)
+	r.POST("/users/verify-email/resend", controller.JWTAuthMiddleware(), userController.ResendVerificationEmail)
+	r.POST("/users/password/forgot", userController.ForgotPassword)
+	r.POST("/users/password/reset", userController.ResetPassword)
 	r.GET("/users/:id", controller.JWTAuthMiddleware(), userController.GetUser)
 	r.GET("/users", controller.JWTAuthMiddleware(), userController.GetAllUsers)
 	r.PATCH("/users/:id", controller.JWTAuthMiddleware(), controller.RequireOwner("id"), userController.UpdateUser)
@@ -0,0 +1,83 @@
+package search
+
+import (
+	"strings"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// MessageDocument indexes the text of a message for its team or the two users of its conversation.
+// Deleted messages have no text left and return nil.
+func MessageDocument(message *entity.Message) *Document {
+	if message.IsDeleted() {
+		return nil
+	}
+	return &Document{
+		Kind:  KindMessage,
+		ID:    message.ID,
+		Text:  message.TextContent,
+		Scope: contextScope(message.TeamID, message.ConversationKey),
+		Time:  message.SentAt,
+	}
+}
+
+// FileDocument indexes the name of a file for the team or conversation it was uploaded to
+func FileDocument(file *entity.File) *Document {
+	scope := Scope{TeamID: file.ContextID}
+	if file.ContextType == entity.FileContextChat {
+		scope = contextScope("", file.ContextID)
+	}
+	return &Document{
+		Kind:  KindFile,
+		ID:    file.ID,
+		Title: file.Name,
+		Scope: scope,
+		Time:  time.Unix(file.CreatedAt, 0),
+	}
+}
+
+// QuizDocument indexes the name and the questions of a quiz for its team; answers and options are left out
+func QuizDocument(quiz *entity.Quiz) *Document {
+	questions := make([]string, 0, len(quiz.Questions))
+	for _, question := range quiz.Questions {
+		questions = append(questions, question.Question)
+	}
+	return &Document{
+		Kind:  KindQuiz,
+		ID:    quiz.ID,
+		Title: quiz.QuizName,
+		Text:  strings.Join(questions, "\n"),
+		Scope: Scope{TeamID: quiz.TeamID},
+	}
+}
+
+func EventDocument(event *entity.Event) *Document {
+	return &Document{
+		Kind:  KindEvent,
+		ID:    event.ID,
+		Title: event.Name,
+		Text:  event.Description,
+		Scope: Scope{TeamID: event.TeamID},
+		Time:  event.StartsAt,
+	}
+}
+
+// TeamDocument indexes the name and description of a team. Public teams can be found by every user,
+// private ones only by their members.
+func TeamDocument(team *entity.Team) *Document {
+	return &Document{
+		Kind:  KindTeam,
+		ID:    team.Id,
+		Title: team.Name,
+		Text:  team.Description,
+		Scope: Scope{Public: team.IsPublic, TeamID: team.Id},
+	}
+}
+
+func contextScope(teamID, conversationKey string) Scope {
+	if teamID != "" {
+		return Scope{TeamID: teamID}
+	}
+	return Scope{UserIDs: entity.ConversationUserIDs(conversationKey)}
+}
@@ -0,0 +1,345 @@
+package search
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Kind tells apart the types of records in the index
+type Kind string
+
+const (
+	KindMessage Kind = "message"
+	KindFile    Kind = "file"
+	KindQuiz    Kind = "quiz"
+	KindEvent   Kind = "event"
+	KindTeam    Kind = "team"
+)
+
+func (k Kind) IsValid() bool {
+	switch k {
+	case KindMessage, KindFile, KindQuiz, KindEvent, KindTeam:
+		return true
+	}
+	return false
+}
+
+// Scope says who can see a document: everyone when Public, otherwise the members of TeamID and the users in UserIDs
+type Scope struct {
+	Public  bool
+	TeamID  string
+	UserIDs []string
+}
+
+// Document is the searchable part of a record. Words in Title weigh more than words in Text.
+type Document struct {
+	Kind  Kind
+	ID    string
+	Title string
+	Text  string
+	Scope Scope
+	Time  time.Time
+}
+
+func (d *Document) key() string {
+	return string(d.Kind) + ":" + d.ID
+}
+
+// Writer is how services keep the index in step with their writes
+type Writer interface {
+	Put(doc *Document)
+	Remove(kind Kind, id string)
+}
+
+const (
+	titleWeight = 3
+	textWeight  = 1
+	// prefixFactor scales the score of a word that only starts with the query term
+	prefixFactor = 0.5
+)
+
+// Index is an in-memory inverted index from lowercase words to the documents containing them.
+// It is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*Document
+	postings map[string]map[string]float64
+	built    bool
+	// rebuilds are the rebuilds in progress, which record every write until they finish
+	rebuilds map[*Rebuild]struct{}
+}
+
+// Rebuild loads a fresh copy of the index without losing the writes made while it is read.
+// It is started before reading the records and finished with what was read.
+type Rebuild struct {
+	idx *Index
+	// writes holds the last document put under each key since the rebuild started, nil for a removal
+	writes map[string]*Document
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[string]*Document),
+		postings: make(map[string]map[string]float64),
+		rebuilds: make(map[*Rebuild]struct{}),
+	}
+}
+
+var defaultIndex = NewIndex()
+
+// Default returns the index shared by the services of this instance
+func Default() *Index {
+	return defaultIndex
+}
+
+// Tokenize splits text into lowercase words of letters and digits
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Put adds a document, replacing the one with the same kind and ID. A nil document is ignored.
+func (idx *Index) Put(doc *Document) {
+	if doc == nil {
+		return
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(doc.key())
+	idx.add(doc)
+	idx.record(doc.key(), doc)
+}
+
+func (idx *Index) Remove(kind Kind, id string) {
+	key := string(kind) + ":" + id
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(key)
+	idx.record(key, nil)
+}
+
+// StartRebuild starts recording the writes to the index, to be applied to what the rebuild read
+func (idx *Index) StartRebuild() *Rebuild {
+	rebuild := &Rebuild{idx: idx, writes: make(map[string]*Document)}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.rebuilds[rebuild] = struct{}{}
+	return rebuild
+}
+
+// Finish swaps the content of the index for docs, with the writes made since the rebuild started
+// applied on top, and marks it built
+func (r *Rebuild) Finish(docs []*Document) {
+	fresh := NewIndex()
+	for _, doc := range docs {
+		if doc != nil {
+			fresh.add(doc)
+		}
+	}
+
+	idx := r.idx
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if _, ok := idx.rebuilds[r]; !ok {
+		return
+	}
+	delete(idx.rebuilds, r)
+	for key, doc := range r.writes {
+		fresh.remove(key)
+		if doc != nil {
+			fresh.add(doc)
+		}
+	}
+	idx.docs = fresh.docs
+	idx.postings = fresh.postings
+	idx.built = true
+}
+
+// Cancel stops a rebuild that could not read the records; it does nothing once the rebuild finished
+func (r *Rebuild) Cancel() {
+	r.idx.mu.Lock()
+	defer r.idx.mu.Unlock()
+	delete(r.idx.rebuilds, r)
+}
+
+// Replace swaps the whole content of the index for docs and marks it built
+func (idx *Index) Replace(docs []*Document) {
+	idx.StartRebuild().Finish(docs)
+}
+
+// Built reports whether the index was loaded at least once
+func (idx *Index) Built() bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.built
+}
+
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// record notes a write for the rebuilds in progress; idx.mu must be held
+func (idx *Index) record(key string, doc *Document) {
+	for rebuild := range idx.rebuilds {
+		rebuild.writes[key] = doc
+	}
+}
+
+func (idx *Index) add(doc *Document) {
+	key := doc.key()
+	idx.docs[key] = doc
+	for token, weight := range weights(doc) {
+		if idx.postings[token] == nil {
+			idx.postings[token] = make(map[string]float64)
+		}
+		idx.postings[token][key] = weight
+	}
+}
+
+func (idx *Index) remove(key string) {
+	doc, ok := idx.docs[key]
+	if !ok {
+		return
+	}
+	delete(idx.docs, key)
+	for token := range weights(doc) {
+		delete(idx.postings[token], key)
+		if len(idx.postings[token]) == 0 {
+			delete(idx.postings, token)
+		}
+	}
+}
+
+func weights(doc *Document) map[string]float64 {
+	result := make(map[string]float64)
+	for _, token := range Tokenize(doc.Title) {
+		result[token] += titleWeight
+	}
+	for _, token := range Tokenize(doc.Text) {
+		result[token] += textWeight
+	}
+	return result
+}
+
+// Query selects documents containing every word of Text, each either whole or as the start of a longer word
+type Query struct {
+	Text string
+	// Kinds limits the results to these kinds; empty means all
+	Kinds []Kind
+	// TeamID limits the results to the documents of a team
+	TeamID string
+	// Visible filters out the documents the caller may not see
+	Visible func(doc *Document) bool
+	Offset  int
+	Limit   int
+}
+
+// Hit is a matching document and its score; higher scores match better
+type Hit struct {
+	Document *Document
+	Score    float64
+}
+
+// Search returns a page of the matching documents, best first and newest first among equal scores,
+// and how many match in total
+func (idx *Index) Search(query Query) ([]Hit, int) {
+	terms := uniqueTerms(query.Text)
+	if len(terms) == 0 {
+		return []Hit{}, 0
+	}
+
+	idx.mu.RLock()
+	var scores map[string]float64
+	for _, term := range terms {
+		termScores := idx.termScores(term)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for key, score := range scores {
+			if termScore, ok := termScores[key]; ok {
+				scores[key] = score + termScore
+			} else {
+				delete(scores, key)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for key, score := range scores {
+		doc := idx.docs[key]
+		if query.matches(doc) {
+			hits = append(hits, Hit{Document: doc, Score: score})
+		}
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if !hits[i].Document.Time.Equal(hits[j].Document.Time) {
+			return hits[i].Document.Time.After(hits[j].Document.Time)
+		}
+		return hits[i].Document.key() < hits[j].Document.key()
+	})
+
+	total := len(hits)
+	if query.Offset >= total {
+		return []Hit{}, total
+	}
+	end := total
+	if query.Limit > 0 && query.Offset+query.Limit < total {
+		end = query.Offset + query.Limit
+	}
+	return hits[query.Offset:end], total
+}
+
+// termScores scores each document with its best word for term: a whole match, or one starting with it
+func (idx *Index) termScores(term string) map[string]float64 {
+	scores := make(map[string]float64)
+	for token, docs := range idx.postings {
+		if !strings.HasPrefix(token, term) {
+			continue
+		}
+		factor := 1.0
+		if token != term {
+			factor = prefixFactor
+		}
+		for key, weight := range docs {
+			if score := weight * factor; score > scores[key] {
+				scores[key] = score
+			}
+		}
+	}
+	return scores
+}
+
+func (q *Query) matches(doc *Document) bool {
+	if len(q.Kinds) > 0 && !slices.Contains(q.Kinds, doc.Kind) {
+		return false
+	}
+	if q.TeamID != "" && doc.Scope.TeamID != q.TeamID {
+		return false
+	}
+	return q.Visible == nil || q.Visible(doc)
+}
+
+func uniqueTerms(text string) []string {
+	seen := make(map[string]bool)
+	terms := make([]string, 0)
+	for _, term := range Tokenize(text) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
@@ -2,11 +2,13 @@ package service
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/search"
 )
 
 const (
@@ -16,35 +18,50 @@ const (
 
 type EventServiceInterface interface {
 	CreateEvent(request *dto.CreateEventRequest) (*dto.EventDTO, error)
-	GetEventById(id string) (*dto.EventDTO, error)
-	GetEventsByTeamId(teamId string) ([]*dto.EventDTO, error)
-	UpdateEventDetails(id string, request *dto.UpdateEventRequest) (*dto.EventDTO, error)
+	GetEventById(userID, id string) (*dto.EventDTO, error)
+	GetEventsByTeamId(userID, teamId string) ([]*dto.EventDTO, error)
+	UpdateEventDetails(userID, id string, request *dto.UpdateEventRequest) (*dto.EventDTO, error)
 	UpdateUserStatus(id string, request *dto.UpdateEventStatusRequest) (*dto.EventDTO, error)
-	DeleteEvent(id string) error
+	DeleteEvent(id, userID string) error
 }
 
 type EventService struct {
-	userRepo  UserRepositoryInterface
-	teamRepo  TeamRepositoryInterface
-	eventRepo persistence.EventRepositoryInterface
+	userRepo    UserRepositoryInterface
+	teamRepo    TeamRepositoryInterface
+	eventRepo   persistence.EventRepositoryInterface
+	searchIndex search.Writer
+	notifier    Notifier
 }
 
 func NewEventService() *EventService {
 	return &EventService{
-		userRepo:  persistence.NewUserRepository(),
-		teamRepo:  persistence.NewTeamRepository(),
-		eventRepo: persistence.NewEventRepository(),
+		userRepo:    persistence.NewUserRepository(),
+		teamRepo:    persistence.NewTeamRepository(),
+		eventRepo:   persistence.NewEventRepository(),
+		searchIndex: search.Default(),
+		notifier:    NewNotificationService(),
 	}
 }
 
 func NewEventServiceWithRepo(eventRepo persistence.EventRepositoryInterface, teamRepo TeamRepositoryInterface, userRepo UserRepositoryInterface) *EventService {
 	return &EventService{
-		userRepo:  userRepo,
-		teamRepo:  teamRepo,
-		eventRepo: eventRepo,
+		userRepo:    userRepo,
+		teamRepo:    teamRepo,
+		eventRepo:   eventRepo,
+		searchIndex: search.NewIndex(),
+		notifier:    discardNotifier{},
 	}
 }
 
+func (es *EventService) SetSearchIndex(searchIndex search.Writer) {
+	es.searchIndex = searchIndex
+}
+
+func (es *EventService) SetNotifier(notifier Notifier) {
+	es.notifier = notifier
+}
+
+// CreateEvent creates an event in a team the initiator is a member of and invites all of its members
 func (es *EventService) CreateEvent(req *dto.CreateEventRequest) (*dto.EventDTO, error) {
 	if _, err := es.userRepo.GetByID(req.InitiatorID); err != nil {
 		return nil, err
@@ -53,6 +70,9 @@ func (es *EventService) CreateEvent(req *dto.CreateEventRequest) (*dto.EventDTO,
 	if err != nil {
 		return nil, err
 	}
+	if err := CheckTeamMember(team, req.InitiatorID); err != nil {
+		return nil, err
+	}
 
 	startsAt, err := time.Parse(time.RFC3339, req.StartsAt)
 	if err != nil {
@@ -77,14 +97,30 @@ func (es *EventService) CreateEvent(req *dto.CreateEventRequest) (*dto.EventDTO,
 	if err := es.eventRepo.Create(&event); err != nil {
 		return nil, err
 	}
+	es.searchIndex.Put(search.EventDocument(&event))
+	es.notifier.Notify(entity.Notification{
+		Type:       entity.NotificationEventInvite,
+		ActorID:    event.InitiatorID,
+		TeamID:     event.TeamID,
+		ResourceID: event.ID,
+		Text:       "You are invited to " + event.Name + " in " + team.Name,
+	}, team.UsersIds...)
 
 	eventDTO := dto.NewEventDTO(&event)
 	return eventDTO, nil
 }
 
-func (es *EventService) GetEventById(id string) (*dto.EventDTO, error) {
+// GetEventById returns an event to a member of its team
+func (es *EventService) GetEventById(userID, id string) (*dto.EventDTO, error) {
 	event, err := es.eventRepo.GetByID(id)
 	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, persistence.EventNotFound)
+	}
+	team, err := es.teamRepo.GetTeamById(event.TeamID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+	}
+	if err := CheckTeamMember(team, userID); err != nil {
 		return nil, err
 	}
 
@@ -92,7 +128,16 @@ func (es *EventService) GetEventById(id string) (*dto.EventDTO, error) {
 	return eventDTO, nil
 }
 
-func (es *EventService) GetEventsByTeamId(teamId string) ([]*dto.EventDTO, error) {
+// GetEventsByTeamId lists the events of a team to one of its members
+func (es *EventService) GetEventsByTeamId(userID, teamId string) ([]*dto.EventDTO, error) {
+	team, err := es.teamRepo.GetTeamById(teamId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+	}
+	if err := CheckTeamMember(team, userID); err != nil {
+		return nil, err
+	}
+
 	events, err := es.eventRepo.GetByTeamID(teamId)
 	if err != nil {
 		return nil, err
@@ -106,13 +151,18 @@ func (es *EventService) GetEventsByTeamId(teamId string) ([]*dto.EventDTO, error
 	return eventsDTO, nil
 }
 
-func (es *EventService) UpdateEventDetails(id string, req *dto.UpdateEventRequest) (*dto.EventDTO, error) {
+// UpdateEventDetails changes an event. Only its initiator or an admin of its team may change it.
+func (es *EventService) UpdateEventDetails(userID, id string, req *dto.UpdateEventRequest) (*dto.EventDTO, error) {
 	event, err := es.eventRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
+	if err := es.checkCanManage(event, userID); err != nil {
+		return nil, err
+	}
 
 	updates := map[string]interface{}{}
+	rescheduled := false
 
 	if req.Name != "" {
 		updates["name"] = req.Name
@@ -126,11 +176,13 @@ func (es *EventService) UpdateEventDetails(id string, req *dto.UpdateEventReques
 		if startsAt, err := time.Parse(time.RFC3339, req.StartsAt); err != nil {
 			return nil, err
 		} else {
+			rescheduled = rescheduled || !startsAt.Equal(event.StartsAt)
 			event.StartsAt = startsAt
 		}
 		updates["starts_at"] = req.StartsAt
 	}
 	if req.Duration != 0 {
+		rescheduled = rescheduled || req.Duration != event.Duration
 		event.Duration = req.Duration
 		updates["duration"] = req.Duration
 	}
@@ -138,15 +190,43 @@ func (es *EventService) UpdateEventDetails(id string, req *dto.UpdateEventReques
 	if err := es.eventRepo.Update(id, updates); err != nil {
 		return nil, err
 	}
+	es.searchIndex.Put(search.EventDocument(event))
+	if rescheduled {
+		es.notifier.Notify(entity.Notification{
+			Type:       entity.NotificationEventRescheduled,
+			ActorID:    userID,
+			TeamID:     event.TeamID,
+			ResourceID: event.ID,
+			Text:       event.Name + " was rescheduled to " + event.StartsAt.UTC().Format(time.RFC3339),
+		}, eventInvitees(event)...)
+	}
 
 	return dto.NewEventDTO(event), nil
 }
 
+// eventInvitees lists the users the event was sent to, in a stable order
+func eventInvitees(event *entity.Event) []string {
+	userIDs := make([]string, 0, len(event.Statuses))
+	for userID := range event.Statuses {
+		userIDs = append(userIDs, userID)
+	}
+	slices.Sort(userIDs)
+	return userIDs
+}
+
+// UpdateUserStatus sets a team member's answer to an event of their team
 func (es *EventService) UpdateUserStatus(id string, req *dto.UpdateEventStatusRequest) (*dto.EventDTO, error) {
 	event, err := es.eventRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
+	team, err := es.teamRepo.GetTeamById(event.TeamID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+	}
+	if err := CheckTeamMember(team, req.UserID); err != nil {
+		return nil, err
+	}
 
 	if _, err := es.userRepo.GetByID(req.UserID); err != nil {
 		return nil, err
@@ -173,10 +253,32 @@ func (es *EventService) UpdateUserStatus(id string, req *dto.UpdateEventStatusRe
 	return dto.NewEventDTO(event), nil
 }
 
-func (es *EventService) DeleteEvent(id string) error {
-	if _, err := es.eventRepo.GetByID(id); err != nil {
+// DeleteEvent deletes an event. Only its initiator or an admin of its team may delete it.
+func (es *EventService) DeleteEvent(id, userID string) error {
+	event, err := es.eventRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := es.checkCanManage(event, userID); err != nil {
 		return err
 	}
 
-	return es.eventRepo.Delete(id)
+	if err := es.eventRepo.Delete(id); err != nil {
+		return err
+	}
+	es.searchIndex.Remove(search.KindEvent, id)
+	return nil
+}
+
+// checkCanManage lets the event's initiator through, and otherwise requires the permission to moderate its team
+func (es *EventService) checkCanManage(event *entity.Event, userID string) error {
+	if event.InitiatorID == userID {
+		return nil
+	}
+	team, err := es.teamRepo.GetTeamById(event.TeamID)
+	if err != nil {
+		return err
+	}
+	return CheckTeamPermission(team, userID, PermissionModerateContent)
 }
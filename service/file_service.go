@@ -7,6 +7,7 @@ import (
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/search"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/validator"
 )
 
@@ -15,37 +16,52 @@ const (
 	userNotInTeamErr = "user is not a member of this team"
 	teamNotFoundErr  = "team not found"
 	fileNotInTeamErr = "file does not belong to this team"
+
+	notConversationParticipantErr = "user is not a participant of this conversation"
+	notFileOwnerErr               = "only the uploader can delete a chat file"
+	ownConversationErr            = "files can only be shared with another user"
+	fileNotInConversationErr      = "file not found in this conversation"
 )
 
 type FileServiceInterface interface {
 	CreateFile(request *dto.FileUploadRequest, userID string) (*dto.FileUploadResponse, error)
 	GetFileByID(id, userID string) (*entity.File, error)
 	GetFilesByTeam(teamID, userID string, page, limit int) (*dto.FileListResponse, error)
+	GetFilesByConversation(otherUserID, userID string, page, limit int) (*dto.FileListResponse, error)
 	DeleteFile(id, userID string) error
+	GetConversationFile(otherUserID, id, userID string) (*entity.File, error)
+	DeleteConversationFile(otherUserID, id, userID string) error
 }
 
 type FileService struct {
-	fileRepo persistence.FileRepositoryInterface
-	userRepo UserRepositoryInterface
-	teamRepo TeamRepositoryInterface
+	fileRepo    persistence.FileRepositoryInterface
+	userRepo    UserRepositoryInterface
+	teamRepo    TeamRepositoryInterface
+	searchIndex search.Writer
 }
 
 func NewFileService() *FileService {
 	return &FileService{
-		fileRepo: persistence.NewFileRepository(),
-		userRepo: persistence.NewUserRepository(),
-		teamRepo: persistence.NewTeamRepository(),
+		fileRepo:    persistence.NewFileRepository(),
+		userRepo:    persistence.NewUserRepository(),
+		teamRepo:    persistence.NewTeamRepository(),
+		searchIndex: search.Default(),
 	}
 }
 
 func NewFileServiceWithRepo(fileRepo persistence.FileRepositoryInterface, userRepo UserRepositoryInterface, teamRepo TeamRepositoryInterface) *FileService {
 	return &FileService{
-		fileRepo: fileRepo,
-		userRepo: userRepo,
-		teamRepo: teamRepo,
+		fileRepo:    fileRepo,
+		userRepo:    userRepo,
+		teamRepo:    teamRepo,
+		searchIndex: search.NewIndex(),
 	}
 }
 
+func (fs *FileService) SetSearchIndex(searchIndex search.Writer) {
+	fs.searchIndex = searchIndex
+}
+
 // isUserInTeam checks if user is a member of the specified team
 func (fs *FileService) isUserInTeam(userID, teamID string) error {
 	user, err := fs.userRepo.GetByID(userID)
@@ -65,16 +81,52 @@ func (fs *FileService) isUserInTeam(userID, teamID string) error {
 	return fmt.Errorf(userNotInTeamErr)
 }
 
+// isUserInConversation checks that the user is one of the two users of a conversation key
+func (fs *FileService) isUserInConversation(userID, conversationKey string) error {
+	if !entity.InConversation(conversationKey, userID) {
+		return fmt.Errorf("%w: %s", ErrForbidden, notConversationParticipantErr)
+	}
+	return nil
+}
+
+// checkContextAccess lets only members of a file's team, or participants of its conversation, access the file
+func (fs *FileService) checkContextAccess(file *entity.File, userID string) error {
+	switch file.ContextType {
+	case entity.FileContextTeam:
+		return fs.isUserInTeam(userID, file.ContextID)
+	case entity.FileContextChat:
+		return fs.isUserInConversation(userID, file.ContextID)
+	}
+	return nil
+}
+
 func (fs *FileService) CreateFile(request *dto.FileUploadRequest, userID string) (*dto.FileUploadResponse, error) {
+	// The uploader always owns the file, whatever the request says
+	request.OwnerID = userID
 	if err := validator.ValidateFileUpload(request); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Verify user is member of the team
-	if request.ContextType == entity.FileContextTeam {
+	// Verify user is member of the team, or part of the conversation
+	switch request.ContextType {
+	case entity.FileContextTeam:
 		if err := fs.isUserInTeam(userID, request.ContextID); err != nil {
 			return nil, err
 		}
+	case entity.FileContextChat:
+		if err := fs.isUserInConversation(userID, request.ContextID); err != nil {
+			return nil, err
+		}
+		otherUserID, err := entity.GetReceiverIdFromKey(userID, request.ContextID)
+		if err != nil {
+			return nil, err
+		}
+		if otherUserID == userID {
+			return nil, fmt.Errorf("validation failed: %s", ownConversationErr)
+		}
+		if _, err := fs.userRepo.GetByID(otherUserID); err != nil {
+			return nil, fmt.Errorf("%w: user not found", ErrResourceNotFound)
+		}
 	}
 
 	id, err := generateID()
@@ -88,6 +140,7 @@ func (fs *FileService) CreateFile(request *dto.FileUploadRequest, userID string)
 	if err := fs.fileRepo.Create(file); err != nil {
 		return nil, err
 	}
+	fs.searchIndex.Put(search.FileDocument(file))
 
 	resp := &dto.FileUploadResponse{
 		ID:          file.ID,
@@ -115,21 +168,62 @@ func (fs *FileService) GetFileByID(id, userID string) (*entity.File, error) {
 	}
 
 	// Verify user has access to this file's context
-	if file.ContextType == entity.FileContextTeam {
-		if err := fs.isUserInTeam(userID, file.ContextID); err != nil {
-			return nil, err
-		}
+	if err := fs.checkContextAccess(file, userID); err != nil {
+		return nil, err
 	}
 
 	return file, nil
 }
 
+// GetConversationFile returns a file shared in the conversation of the user with otherUserID.
+// A file of another conversation is not found, even when the user is in that one too.
+func (fs *FileService) GetConversationFile(otherUserID, id, userID string) (*entity.File, error) {
+	file, err := fs.GetFileByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSharedInConversation(file, userID, otherUserID); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// DeleteConversationFile deletes a file shared in the conversation of the user with otherUserID
+func (fs *FileService) DeleteConversationFile(otherUserID, id, userID string) error {
+	if id == "" {
+		return fmt.Errorf(fileIDEmpty)
+	}
+	file, err := fs.fileRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := checkSharedInConversation(file, userID, otherUserID); err != nil {
+		return err
+	}
+	return fs.DeleteFile(id, userID)
+}
+
+func checkSharedInConversation(file *entity.File, userID, otherUserID string) error {
+	if file.ContextType != entity.FileContextChat || file.ContextID != entity.GetConversationKey(userID, otherUserID) {
+		return fmt.Errorf("%w: %s", ErrResourceNotFound, fileNotInConversationErr)
+	}
+	return nil
+}
+
 func (fs *FileService) GetFilesByTeam(teamID, userID string, page, limit int) (*dto.FileListResponse, error) {
 	// Verify user is member of the team
 	if err := fs.isUserInTeam(userID, teamID); err != nil {
 		return nil, err
 	}
+	return fs.listFiles(entity.FileContextTeam, teamID, page, limit)
+}
 
+// GetFilesByConversation lists the files shared in the user's direct conversation with otherUserID
+func (fs *FileService) GetFilesByConversation(otherUserID, userID string, page, limit int) (*dto.FileListResponse, error) {
+	return fs.listFiles(entity.FileContextChat, entity.GetConversationKey(userID, otherUserID), page, limit)
+}
+
+func (fs *FileService) listFiles(contextType, contextID string, page, limit int) (*dto.FileListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -140,24 +234,12 @@ func (fs *FileService) GetFilesByTeam(teamID, userID string, page, limit int) (*
 		limit = 100
 	}
 
-	files, err := fs.fileRepo.GetByContextID(entity.FileContextTeam, teamID)
+	paginatedFiles, totalCount, err := fs.fileRepo.GetPageByContextID(contextType, contextID, (page-1)*limit, limit)
 	if err != nil {
 		return nil, err
 	}
-
-	totalCount := len(files)
 	totalPages := (totalCount + limit - 1) / limit
 
-	start := (page - 1) * limit
-	end := start + limit
-	if start > totalCount {
-		start = totalCount
-	}
-	if end > totalCount {
-		end = totalCount
-	}
-
-	paginatedFiles := files[start:end]
 	result := make([]*dto.FileUploadResponse, 0, len(paginatedFiles))
 	for _, f := range paginatedFiles {
 		result = append(result, &dto.FileUploadResponse{
@@ -198,7 +280,32 @@ func (fs *FileService) DeleteFile(id, userID string) error {
 		if err := fs.isUserInTeam(userID, file.ContextID); err != nil {
 			return err
 		}
+
+		// Other people's files can only be removed by team admins
+		if file.OwnerID != userID {
+			team, err := fs.teamRepo.GetTeamById(file.ContextID)
+			if err != nil {
+				return fmt.Errorf(teamNotFoundErr)
+			}
+			if err := CheckTeamPermission(team, userID, PermissionModerateContent); err != nil {
+				return err
+			}
+		}
+	}
+
+	// A conversation has no admins, so chat files can only be removed by whoever uploaded them
+	if file.ContextType == entity.FileContextChat {
+		if err := fs.isUserInConversation(userID, file.ContextID); err != nil {
+			return err
+		}
+		if file.OwnerID != userID {
+			return fmt.Errorf("%w: %s", ErrForbidden, notFileOwnerErr)
+		}
 	}
 
-	return fs.fileRepo.Delete(id)
+	if err := fs.fileRepo.Delete(id); err != nil {
+		return err
+	}
+	fs.searchIndex.Remove(search.KindFile, id)
+	return nil
 }
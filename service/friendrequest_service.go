@@ -10,6 +10,7 @@ import (
 type FriendRequestService struct {
 	friendRequestRepo FriendRequestRepositoryInterface
 	userService       UserServiceInterface
+	notifier          Notifier
 }
 
 type FriendRequestRepositoryInterface interface {
@@ -39,6 +40,7 @@ func NewFriendRequestService() *FriendRequestService {
 	return &FriendRequestService{
 		friendRequestRepo: persistence.NewFriendRequestRepository(),
 		userService:       NewUserService(),
+		notifier:          NewNotificationService(),
 	}
 }
 
@@ -79,6 +81,12 @@ func (fs *FriendRequestService) SendFriendRequest(fromUserID, toUserID string) e
 		return fmt.Errorf("create friend request: %w", err)
 	}
 
+	fs.notifier.Notify(entity.Notification{
+		Type:       entity.NotificationFriendRequest,
+		ActorID:    fromUserID,
+		ResourceID: request.Key(),
+		Text:       sender.Username + " sent you a friend request",
+	}, toUserID)
 	return nil
 }
 
@@ -111,6 +119,14 @@ func (fs *FriendRequestService) RespondToFriendRequest(fromUserID, toUserID stri
 		return fmt.Errorf("update friend request: %w", err)
 	}
 
+	if accept {
+		fs.notifier.Notify(entity.Notification{
+			Type:       entity.NotificationFriendRequestAccepted,
+			ActorID:    toUserID,
+			ResourceID: request.Key(),
+			Text:       fs.username(toUserID) + " accepted your friend request",
+		}, fromUserID)
+	}
 	return nil
 }
 
@@ -189,3 +205,16 @@ func (fs *FriendRequestService) SetFriendRequestRepo(repo FriendRequestRepositor
 func (fs *FriendRequestService) SetUserService(service UserServiceInterface) {
 	fs.userService = service
 }
+
+func (fs *FriendRequestService) SetNotifier(notifier Notifier) {
+	fs.notifier = notifier
+}
+
+// username names a user in notification texts, falling back to a neutral word when they cannot be loaded
+func (fs *FriendRequestService) username(userID string) string {
+	user, err := fs.userService.GetUserByID(userID)
+	if err != nil || user == nil {
+		return "Someone"
+	}
+	return user.Username
+}
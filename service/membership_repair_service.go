@@ -0,0 +1,296 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+)
+
+// MembershipRepairReport describes the drift a repair run found and how many records it rewrote
+type MembershipRepairReport struct {
+	Issues       []string `json:"issues"`
+	UsersChanged int      `json:"usersChanged"`
+	TeamsChanged int      `json:"teamsChanged"`
+	DryRun       bool     `json:"dryRun"`
+}
+
+// MembershipRepairService brings User.TeamsIds back in line with Team.UsersIds.
+// The team side is the source of truth: it also holds the roles, and every membership
+// change used to write the user first, so a half applied change always left the team as it was before.
+type MembershipRepairService struct {
+	userRepo UserRepositoryInterface
+	teamRepo TeamRepositoryInterface
+	patcher  persistence.DocumentPatcher
+}
+
+func NewMembershipRepairService() *MembershipRepairService {
+	return &MembershipRepairService{
+		userRepo: persistence.NewUserRepository(),
+		teamRepo: persistence.NewTeamRepository(),
+		patcher:  persistence.NewDocumentPatcher(),
+	}
+}
+
+func NewMembershipRepairServiceWithRepo(userRepo UserRepositoryInterface, teamRepo TeamRepositoryInterface) *MembershipRepairService {
+	return &MembershipRepairService{
+		userRepo: userRepo,
+		teamRepo: teamRepo,
+		patcher:  persistence.NewRepositoryPatcher(userRepo, teamRepo),
+	}
+}
+
+func (mr *MembershipRepairService) SetPatcher(patcher persistence.DocumentPatcher) {
+	mr.patcher = patcher
+}
+
+// Repair finds and fixes drift between users and teams:
+//   - teams listing a user twice or a user that no longer exists
+//   - roles held by users who are not members
+//   - users missing a team that lists them, or listing a team that does not list them or no longer exists
+//
+// With dryRun the report is built but nothing is written. Only the member, role and team lists are written,
+// each with the document re-read when it is written: a list that changed since it was read is left for the next run.
+func (mr *MembershipRepairService) Repair(dryRun bool) (*MembershipRepairReport, error) {
+	users, err := mr.userRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	teams, err := mr.teamRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Id < teams[j].Id })
+
+	report := &MembershipRepairReport{Issues: []string{}, DryRun: dryRun}
+	usersByID := make(map[string]*entity.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	teamPatches := make(map[string]persistence.PatchFunc)
+	memberships := make(map[string][]string)
+	for _, team := range teams {
+		read := entity.Team{UsersIds: append([]string(nil), team.UsersIds...), Roles: copyRoles(team.Roles)}
+		if mr.repairTeam(team, usersByID, report) {
+			teamPatches[team.Id] = patchTeamMembers(read, team)
+		}
+		for _, userID := range team.UsersIds {
+			memberships[userID] = append(memberships[userID], team.Id)
+		}
+	}
+
+	userPatches := make(map[string]persistence.PatchFunc)
+	for _, user := range users {
+		read := teamIDsOf(user)
+		if mr.repairUser(user, memberships[user.ID], report) {
+			userPatches[user.ID] = patchUserTeams(read, *user.TeamsIds)
+		}
+	}
+
+	if dryRun {
+		report.TeamsChanged = len(teamPatches)
+		report.UsersChanged = len(userPatches)
+		return report, nil
+	}
+
+	for _, team := range teams {
+		patch, ok := teamPatches[team.Id]
+		if !ok {
+			continue
+		}
+		patched, err := mr.patcher.Patch(persistence.TeamsCollection, team.Id, patch)
+		if err != nil {
+			return nil, err
+		}
+		if !patched {
+			report.Issues = append(report.Issues, fmt.Sprintf("team %s changed during the repair and was left for the next run", team.Id))
+			continue
+		}
+		report.TeamsChanged++
+	}
+	for _, user := range users {
+		patch, ok := userPatches[user.ID]
+		if !ok {
+			continue
+		}
+		patched, err := mr.patcher.Patch(persistence.UsersCollection, user.ID, patch)
+		if err != nil {
+			return nil, err
+		}
+		if !patched {
+			report.Issues = append(report.Issues, fmt.Sprintf("user %s changed during the repair and was left for the next run", user.ID))
+			continue
+		}
+		report.UsersChanged++
+	}
+	return report, nil
+}
+
+// StartJob runs Repair every interval in the background and logs what it fixed
+func (mr *MembershipRepairService) StartJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			report, err := mr.Repair(false)
+			if err != nil {
+				log.Printf("membership repair failed: %v", err)
+				continue
+			}
+			for _, issue := range report.Issues {
+				log.Printf("membership repair: %s", issue)
+			}
+		}
+	}()
+}
+
+// repairTeam removes duplicate and unknown members and the roles of non-members, reporting whether it changed the team
+func (mr *MembershipRepairService) repairTeam(team *entity.Team, usersByID map[string]*entity.User, report *MembershipRepairReport) bool {
+	changed := false
+	members := make([]string, 0, len(team.UsersIds))
+	seen := make(map[string]bool, len(team.UsersIds))
+	for _, userID := range team.UsersIds {
+		switch {
+		case seen[userID]:
+			report.Issues = append(report.Issues, fmt.Sprintf("team %s lists user %s more than once", team.Id, userID))
+			changed = true
+		case usersByID[userID] == nil:
+			report.Issues = append(report.Issues, fmt.Sprintf("team %s lists missing user %s", team.Id, userID))
+			changed = true
+		default:
+			members = append(members, userID)
+		}
+		seen[userID] = true
+	}
+	team.UsersIds = members
+
+	memberSet := make(map[string]bool, len(members))
+	for _, userID := range members {
+		memberSet[userID] = true
+	}
+	for userID := range team.Roles {
+		if !memberSet[userID] {
+			report.Issues = append(report.Issues, fmt.Sprintf("team %s has a role for non-member %s", team.Id, userID))
+			delete(team.Roles, userID)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// repairUser makes the user's team list match the teams that list the user, keeping the existing order
+func (mr *MembershipRepairService) repairUser(user *entity.User, teamIDs []string, report *MembershipRepairReport) bool {
+	want := make(map[string]bool, len(teamIDs))
+	for _, teamID := range teamIDs {
+		want[teamID] = true
+	}
+
+	current := []string{}
+	if user.TeamsIds != nil {
+		current = *user.TeamsIds
+	}
+
+	changed := false
+	kept := make([]string, 0, len(teamIDs))
+	seen := make(map[string]bool, len(current))
+	for _, teamID := range current {
+		switch {
+		case seen[teamID]:
+			report.Issues = append(report.Issues, fmt.Sprintf("user %s lists team %s more than once", user.ID, teamID))
+			changed = true
+		case !want[teamID]:
+			report.Issues = append(report.Issues, fmt.Sprintf("user %s lists team %s, which does not list the user", user.ID, teamID))
+			changed = true
+		default:
+			kept = append(kept, teamID)
+		}
+		seen[teamID] = true
+	}
+	for _, teamID := range teamIDs {
+		if !seen[teamID] {
+			report.Issues = append(report.Issues, fmt.Sprintf("user %s is missing team %s, which lists the user", user.ID, teamID))
+			kept = append(kept, teamID)
+			seen[teamID] = true
+			changed = true
+		}
+	}
+
+	if changed {
+		user.TeamsIds = &kept
+	}
+	return changed
+}
+
+// patchTeamMembers sets the repaired members and roles of a team, unless they are no longer the ones the repair read
+func patchTeamMembers(read entity.Team, repaired *entity.Team) persistence.PatchFunc {
+	return func(data json.RawMessage) (map[string]interface{}, error) {
+		var stored entity.Team
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, err
+		}
+		if !sameIDs(stored.UsersIds, read.UsersIds) || !sameRoles(stored.Roles, read.Roles) {
+			return nil, nil
+		}
+		return map[string]interface{}{"users": repaired.UsersIds, "roles": repaired.Roles}, nil
+	}
+}
+
+// patchUserTeams sets the repaired team list of a user, unless it is no longer the one the repair read
+func patchUserTeams(read, repaired []string) persistence.PatchFunc {
+	return func(data json.RawMessage) (map[string]interface{}, error) {
+		var stored entity.User
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, err
+		}
+		if !sameIDs(teamIDsOf(&stored), read) {
+			return nil, nil
+		}
+		return map[string]interface{}{"teams": repaired}, nil
+	}
+}
+
+func teamIDsOf(user *entity.User) []string {
+	if user.TeamsIds == nil {
+		return nil
+	}
+	return append([]string(nil), *user.TeamsIds...)
+}
+
+func copyRoles(roles map[string]entity.TeamRole) map[string]entity.TeamRole {
+	copied := make(map[string]entity.TeamRole, len(roles))
+	for userID, role := range roles {
+		copied[userID] = role
+	}
+	return copied
+}
+
+// sameIDs compares two ID lists in order, treating a missing list as empty like the stores do
+func sameIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameRoles(a, b map[string]entity.TeamRole) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for userID, role := range a {
+		if b[userID] != role {
+			return false
+		}
+	}
+	return true
+}
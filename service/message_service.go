@@ -1,42 +1,99 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/search"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/validator"
 )
 
+const (
+	defaultMessagePageSize = 50
+	maxMessagePageSize     = 100
+	// maxUnreadCount is where unread counting stops, clients show more as "99+" or similar
+	maxUnreadCount = 99
+	// maxReactionEmojis is how many different emojis one message can collect
+	maxReactionEmojis = 20
+)
+
+var (
+	ErrInvalidCursor     = errors.New("invalid cursor")
+	ErrInvalidPageLimit  = errors.New("limit must be positive")
+	ErrMessageDeleted    = errors.New("message has been deleted")
+	ErrInvalidReply      = errors.New("replies must stay in the same conversation or team")
+	ErrNestedThread      = errors.New("thread replies cannot have replies of their own")
+	ErrTooManyReactions  = errors.New("message has too many different reactions")
+	ErrInvalidAttachment = errors.New("attachments must be files of the same conversation or team")
+)
+
+const notMessageSenderError = "only the sender can change this message"
+
+// mentionPattern finds @username mentions; trailing dots and dashes are punctuation, not part of the name
+var mentionPattern = regexp.MustCompile(`@([\p{L}\p{N}_.\-]+)`)
+
 type MessageService struct {
-	userRepo    UserRepositoryInterface
-	teamRepo    TeamRepositoryInterface
-	messageRepo persistence.MessageRepositoryInterface
+	userRepo       UserRepositoryInterface
+	teamRepo       TeamRepositoryInterface
+	messageRepo    persistence.MessageRepositoryInterface
+	readCursorRepo persistence.ReadCursorRepositoryInterface
+	fileRepo       persistence.FileRepositoryInterface
+	searchIndex    search.Writer
+	notifier       Notifier
 }
 
 func NewMessageService() *MessageService {
 	return &MessageService{
-		userRepo:    persistence.NewUserRepository(),
-		teamRepo:    persistence.NewTeamRepository(),
-		messageRepo: persistence.NewMessageRepository(),
+		userRepo:       persistence.NewUserRepository(),
+		teamRepo:       persistence.NewTeamRepository(),
+		messageRepo:    persistence.NewMessageRepository(),
+		readCursorRepo: persistence.NewReadCursorRepository(),
+		fileRepo:       persistence.NewFileRepository(),
+		searchIndex:    search.Default(),
+		notifier:       NewNotificationService(),
 	}
 }
 
-func NewMessageServiceWithRepo(userRepo UserRepositoryInterface, teamRepo TeamRepositoryInterface, messageRepo persistence.MessageRepositoryInterface) *MessageService {
+func NewMessageServiceWithRepo(userRepo UserRepositoryInterface, teamRepo TeamRepositoryInterface, messageRepo persistence.MessageRepositoryInterface, readCursorRepo persistence.ReadCursorRepositoryInterface, fileRepo persistence.FileRepositoryInterface) *MessageService {
 	return &MessageService{
-		userRepo:    userRepo,
-		teamRepo:    teamRepo,
-		messageRepo: messageRepo,
+		userRepo:       userRepo,
+		teamRepo:       teamRepo,
+		messageRepo:    messageRepo,
+		readCursorRepo: readCursorRepo,
+		fileRepo:       fileRepo,
+		searchIndex:    search.NewIndex(),
+		notifier:       discardNotifier{},
 	}
 }
 
+func (ms *MessageService) SetSearchIndex(searchIndex search.Writer) {
+	ms.searchIndex = searchIndex
+}
+
+func (ms *MessageService) SetNotifier(notifier Notifier) {
+	ms.notifier = notifier
+}
+
 type MessageServiceInterface interface {
 	CreateDirectMessage(request *dto.DirectMessageRequest) (*dto.MessageDTO, error)
 	CreateTeamMessage(request *dto.TeamMessageRequest) (*dto.MessageDTO, error)
-	GetMessageByID(id string) (*dto.MessageDTO, error)
-	GetDirectMessages(user1Id, user2Id string) ([]*dto.MessageDTO, error)
-	GetTeamMessages(teamId string) ([]*dto.MessageDTO, error)
+	GetMessageByID(userID, id string) (*dto.MessageDTO, error)
+	GetDirectMessages(user1Id, user2Id string, page dto.MessagePageRequest) (*dto.MessagePageDTO, error)
+	GetTeamMessages(userID, teamId string, page dto.MessagePageRequest) (*dto.MessagePageDTO, error)
+	EditMessage(actorID, id string, request *dto.EditMessageRequest) (*dto.MessageDTO, []string, error)
+	DeleteMessage(actorID, id string) (*dto.MessageDTO, []string, error)
+	Typing(userID string, request *dto.TypingRequest) (*dto.TypingDTO, []string, error)
+	MarkRead(userID string, request *dto.ReadMessageRequest) (*dto.ReadReceiptDTO, []string, error)
+	GetUnreadCounts(userID string) (*dto.UnreadCountsDTO, error)
+	ToggleReaction(userID string, request *dto.ReactionRequest) (*dto.MessageDTO, []string, error)
+	GetReplies(userID, parentID string, page dto.MessagePageRequest) (*dto.MessagePageDTO, error)
 }
 
 func (ms *MessageService) CreateDirectMessage(request *dto.DirectMessageRequest) (*dto.MessageDTO, error) {
@@ -58,19 +115,28 @@ func (ms *MessageService) CreateDirectMessage(request *dto.DirectMessageRequest)
 		return nil, err
 	}
 
-	message := *entity.NewMessage(
+	message, err := ms.withReferences(entity.NewMessage(
 		id,
 		request.SenderID,
 		entity.GetConversationKey(request.SenderID, request.ReceiverID),
 		"",
 		request.TextContent,
-	)
-	if err := ms.messageRepo.Create(&message); err != nil {
+	), request.ReplyToID, request.ParentID)
+	if err != nil {
 		return nil, err
 	}
+	if err := ms.attach(message, request.AttachmentIDs); err != nil {
+		return nil, err
+	}
+	if err := ms.messageRepo.Create(message); err != nil {
+		return nil, err
+	}
+	ms.recordSent(message, request.ReceiverID)
+	ms.recordReply(message)
+	ms.searchIndex.Put(search.MessageDocument(message))
 
 	senderDTO := dto.NewSenderDTO(sender)
-	dtoMessage := dto.NewMessageDTO(message.ID, request.ReceiverID, "", message.TextContent, message.SentAt, *senderDTO)
+	dtoMessage := dto.NewMessageDTOFromEntity(message, request.ReceiverID, *senderDTO)
 	return dtoMessage, nil
 }
 
@@ -84,49 +150,582 @@ func (ms *MessageService) CreateTeamMessage(request *dto.TeamMessageRequest) (*d
 		return nil, fmt.Errorf("sender not found")
 	}
 
-	if _, err := ms.teamRepo.GetTeamById(request.TeamId); err != nil {
+	team, err := ms.teamRepo.GetTeamById(request.TeamId)
+	if err != nil {
 		return nil, fmt.Errorf("team not found")
 	}
+	if err := CheckTeamMember(team, request.SenderID); err != nil {
+		return nil, err
+	}
 
 	id, err := generateID()
 	if err != nil {
 		return nil, err
 	}
 
-	message := *entity.NewMessage(
+	message, err := ms.withReferences(entity.NewMessage(
 		id,
 		request.SenderID,
 		"",
 		request.TeamId,
 		request.TextContent,
-	)
-	if err := ms.messageRepo.Create(&message); err != nil {
+	), request.ReplyToID, request.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ms.attach(message, request.AttachmentIDs); err != nil {
+		return nil, err
+	}
+	if err := ms.messageRepo.Create(message); err != nil {
 		return nil, err
 	}
+	ms.recordSent(message, "")
+	ms.recordReply(message)
+	ms.searchIndex.Put(search.MessageDocument(message))
+	ms.notifyMentions(message, sender, team)
 
 	senderDTO := dto.NewSenderDTO(sender)
-	dtoMessage := dto.NewMessageDTO(message.ID, "", request.TeamId, message.TextContent, message.SentAt, *senderDTO)
+	dtoMessage := dto.NewMessageDTOFromEntity(message, "", *senderDTO)
 	return dtoMessage, nil
 }
 
-func (ms *MessageService) GetMessageByID(id string) (*dto.MessageDTO, error) {
+// notifyMentions tells the team members named as @username in a team message that they were mentioned.
+// Usernames match regardless of case; names that are not members of the team are ignored.
+func (ms *MessageService) notifyMentions(message *entity.Message, sender *entity.User, team *entity.Team) {
+	mentioned := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(message.TextContent, -1) {
+		mentioned[strings.ToLower(strings.TrimRight(match[1], ".-"))] = true
+	}
+	if len(mentioned) == 0 {
+		return
+	}
+
+	members, err := ms.userRepo.GetByIDs(team.UsersIds)
+	if err != nil {
+		log.Printf("failed to load the members of team %s for mentions: %v", team.Id, err)
+		return
+	}
+	recipients := make([]string, 0)
+	for _, member := range members {
+		if mentioned[strings.ToLower(member.Username)] {
+			recipients = append(recipients, member.ID)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+	ms.notifier.Notify(entity.Notification{
+		Type:       entity.NotificationMention,
+		ActorID:    sender.ID,
+		TeamID:     team.Id,
+		ResourceID: message.ID,
+		Text:       sender.Username + " mentioned you in " + team.Name,
+	}, recipients...)
+}
+
+// withReferences resolves the quote and the thread parent of a new message; both must be in its conversation
+// or team. It returns the message to store, which is a reply under the parent when parentID is set.
+func (ms *MessageService) withReferences(message *entity.Message, replyToID, parentID string) (*entity.Message, error) {
+	if parentID != "" {
+		parent, err := ms.referencedMessage(message, parentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.ParentID != "" {
+			return nil, ErrNestedThread
+		}
+		message = entity.NewReply(message.ID, message.SenderID, parent, message.TextContent)
+	}
+	if replyToID != "" {
+		quoted, err := ms.referencedMessage(message, replyToID)
+		if err != nil {
+			return nil, err
+		}
+		message.ReplyTo = entity.NewMessageQuote(quoted)
+	}
+	return message, nil
+}
+
+// attach adds the metadata of the files to the message. Only files uploaded to the message's
+// conversation or team can be attached, so everyone who sees the message can download them.
+func (ms *MessageService) attach(message *entity.Message, fileIDs []string) error {
+	contextType, contextID := message.FileContext()
+	for _, fileID := range fileIDs {
+		file, err := ms.fileRepo.GetByID(fileID)
+		if err != nil {
+			return fmt.Errorf("%w: file %s not found", ErrResourceNotFound, fileID)
+		}
+		if file.ContextType != contextType || file.ContextID != contextID {
+			return ErrInvalidAttachment
+		}
+		message.Attachments = append(message.Attachments, entity.NewMessageAttachment(file))
+	}
+	return nil
+}
+
+// referencedMessage loads a message that a new one refers to
+func (ms *MessageService) referencedMessage(message *entity.Message, id string) (*entity.Message, error) {
+	referenced, err := ms.messageRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, persistence.MessageNotFound)
+	}
+	if referenced.ConversationKey != message.ConversationKey || referenced.TeamID != message.TeamID {
+		return nil, ErrInvalidReply
+	}
+	if referenced.IsDeleted() {
+		return nil, ErrMessageDeleted
+	}
+	return referenced, nil
+}
+
+// GetMessageByID returns a message to a user who can see it: one of the two users of a direct message,
+// or a member of the team of a team message
+func (ms *MessageService) GetMessageByID(userID, id string) (*dto.MessageDTO, error) {
 	message, err := ms.messageRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, persistence.MessageNotFound)
+	}
+	if _, err := ms.participants(userID, message); err != nil {
+		return nil, err
+	}
 	receiverId, key_err := entity.GetReceiverIdFromKey(message.SenderID, message.ConversationKey)
 	if message.ConversationKey != "" && key_err != nil {
 		return nil, err
 	}
 
+	senderDTO, err := ms.senderOf(message)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewMessageDTOFromEntity(message, receiverId, *senderDTO), nil
+}
+
+// EditMessage replaces the text of a message, keeping the previous text in its edit history.
+// It returns the edited message and the users who can see it.
+func (ms *MessageService) EditMessage(actorID, id string, request *dto.EditMessageRequest) (*dto.MessageDTO, []string, error) {
+	if err := validator.ValidateEditMessageRequest(request); err != nil {
+		return nil, nil, err
+	}
+	message, recipients, err := ms.authorizeMessageChange(actorID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now().UTC()
+	message.EditHistory = append(message.EditHistory, entity.MessageEdit{
+		TextContent: message.TextContent,
+		EditedAt:    now,
+		EditedBy:    actorID,
+	})
+	message.TextContent = request.TextContent
+	message.EditedAt = &now
+
+	if err := ms.messageRepo.Update(id, map[string]interface{}{
+		"textContent": message.TextContent,
+		"editHistory": message.EditHistory,
+		"editedAt":    message.EditedAt,
+	}); err != nil {
+		return nil, nil, err
+	}
+	ms.searchIndex.Put(search.MessageDocument(message))
+
+	messageDTO, err := ms.toMessageDTO(message)
+	if err != nil {
+		return nil, nil, err
+	}
+	return messageDTO, recipients, nil
+}
+
+// DeleteMessage turns a message into a tombstone: the text, quote, reactions, attachments and edit history are dropped but the
+// message keeps its place in the thread, so clients can show that something was removed.
+// It returns the tombstone and the users who can see it.
+func (ms *MessageService) DeleteMessage(actorID, id string) (*dto.MessageDTO, []string, error) {
+	message, recipients, err := ms.authorizeMessageChange(actorID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now().UTC()
+	message.TextContent = ""
+	message.EditHistory = nil
+	message.DeletedAt = &now
+	message.DeletedBy = actorID
+
+	message.ReplyTo = nil
+	message.Reactions = nil
+	message.Attachments = nil
+
+	if err := ms.messageRepo.Update(id, map[string]interface{}{
+		"textContent": "",
+		"editHistory": nil,
+		"deletedAt":   message.DeletedAt,
+		"deletedBy":   actorID,
+		"replyTo":     nil,
+		"reactions":   nil,
+		"attachments": nil,
+	}); err != nil {
+		return nil, nil, err
+	}
+	ms.searchIndex.Remove(search.KindMessage, id)
+
+	messageDTO, err := ms.toMessageDTO(message)
+	if err != nil {
+		return nil, nil, err
+	}
+	return messageDTO, recipients, nil
+}
+
+// ToggleReaction adds the user's reaction with the emoji to a message they can see, or removes it when
+// it is already there. It returns the updated message and the users who can see it.
+func (ms *MessageService) ToggleReaction(userID string, request *dto.ReactionRequest) (*dto.MessageDTO, []string, error) {
+	if err := validator.ValidateReactionRequest(request); err != nil {
+		return nil, nil, err
+	}
+	message, err := ms.messageRepo.GetByID(request.MessageID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrResourceNotFound, persistence.MessageNotFound)
+	}
+	participants, err := ms.participants(userID, message)
+	if err != nil {
+		return nil, nil, err
+	}
+	if message.IsDeleted() {
+		return nil, nil, ErrMessageDeleted
+	}
+
+	// a path update touches only this user's reaction, so concurrent reactions do not overwrite each other
+	path := "reactions/" + request.Emoji + "/" + userID
+	if message.HasReacted(userID, request.Emoji) {
+		if err := ms.messageRepo.Update(message.ID, map[string]interface{}{path: nil}); err != nil {
+			return nil, nil, err
+		}
+		delete(message.Reactions[request.Emoji], userID)
+	} else {
+		if len(message.Reactions[request.Emoji]) == 0 && message.ReactionEmojiCount() >= maxReactionEmojis {
+			return nil, nil, ErrTooManyReactions
+		}
+		now := time.Now().UTC()
+		if err := ms.messageRepo.Update(message.ID, map[string]interface{}{path: now}); err != nil {
+			return nil, nil, err
+		}
+		if message.Reactions == nil {
+			message.Reactions = make(map[string]map[string]time.Time)
+		}
+		if message.Reactions[request.Emoji] == nil {
+			message.Reactions[request.Emoji] = make(map[string]time.Time)
+		}
+		message.Reactions[request.Emoji][userID] = now
+	}
+
+	messageDTO, err := ms.toMessageDTO(message)
+	if err != nil {
+		return nil, nil, err
+	}
+	return messageDTO, participants, nil
+}
+
+// GetReplies returns a page of the thread replies under a message, for a user who can see it
+func (ms *MessageService) GetReplies(userID, parentID string, page dto.MessagePageRequest) (*dto.MessagePageDTO, error) {
+	query, err := messagePageQuery(page)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := ms.messageRepo.GetByID(parentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, persistence.MessageNotFound)
+	}
+	if _, err := ms.participants(userID, parent); err != nil {
+		return nil, err
+	}
+
+	replies, err := ms.messageRepo.GetRepliesPage(parentID, query)
+	if err != nil {
+		return nil, err
+	}
+	return ms.toMessagePage(replies, query)
+}
+
+// Typing checks that the user may write to the conversation or team and returns the typing
+// notice with the users who should see it, everyone but the typing user
+func (ms *MessageService) Typing(userID string, request *dto.TypingRequest) (*dto.TypingDTO, []string, error) {
+	if err := validator.ValidateTypingRequest(request); err != nil {
+		return nil, nil, err
+	}
+	typing := &dto.TypingDTO{UserID: userID, ReceiverID: request.ReceiverID, TeamID: request.TeamID}
+
+	if request.ReceiverID != "" {
+		if _, err := ms.userRepo.GetByID(request.ReceiverID); err != nil {
+			return nil, nil, fmt.Errorf("%w: receiver not found", ErrResourceNotFound)
+		}
+		return typing, []string{request.ReceiverID}, nil
+	}
+
+	team, err := ms.teamRepo.GetTeamById(request.TeamID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+	}
+	if !team.IsMember(userID) {
+		return nil, nil, fmt.Errorf("%w: %s", ErrForbidden, notTeamMemberError)
+	}
+	recipients := make([]string, 0, len(team.UsersIds))
+	for _, memberID := range team.UsersIds {
+		if memberID != userID {
+			recipients = append(recipients, memberID)
+		}
+	}
+	return typing, recipients, nil
+}
+
+// MarkRead moves the user's read cursor of the message's conversation or team up to the message.
+// The receipt goes to the other participants, but only when the cursor moved: reading an older message changes nothing.
+func (ms *MessageService) MarkRead(userID string, request *dto.ReadMessageRequest) (*dto.ReadReceiptDTO, []string, error) {
+	if err := validator.ValidateReadMessageRequest(request); err != nil {
+		return nil, nil, err
+	}
+	message, err := ms.messageRepo.GetByID(request.MessageID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrResourceNotFound, persistence.MessageNotFound)
+	}
+	participants, err := ms.participants(userID, message)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cursor, err := ms.readCursor(userID, message.Thread())
+	if err != nil {
+		return nil, nil, err
+	}
+	receipt := &dto.ReadReceiptDTO{
+		MessageID: message.ID,
+		UserID:    userID,
+		TeamID:    message.TeamID,
+		ReadAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+	if !cursor.Advance(message) {
+		return receipt, []string{}, nil
+	}
+	if err := ms.readCursorRepo.Save(cursor); err != nil {
+		return nil, nil, err
+	}
+
+	recipients := make([]string, 0, len(participants))
+	for _, participantID := range participants {
+		if participantID != userID {
+			recipients = append(recipients, participantID)
+		}
+	}
+	return receipt, recipients, nil
+}
+
+// GetUnreadCounts counts the unread messages of every direct conversation the user has a read cursor for,
+// which is every conversation with a message sent since read cursors exist, and of every team they are in
+func (ms *MessageService) GetUnreadCounts(userID string) (*dto.UnreadCountsDTO, error) {
+	user, err := ms.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: user not found", ErrResourceNotFound)
+	}
+	cursors, err := ms.readCursorRepo.GetByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := &dto.UnreadCountsDTO{Conversations: []*dto.UnreadCountDTO{}, Teams: []*dto.UnreadCountDTO{}}
+	teamCursors := make(map[string]*entity.ReadCursor)
+	for _, cursor := range cursors {
+		convKey, ok := strings.CutPrefix(cursor.Thread, entity.ConversationThread(""))
+		if !ok {
+			teamCursors[cursor.Thread] = cursor
+			continue
+		}
+		otherID, err := entity.GetReceiverIdFromKey(userID, convKey)
+		if err != nil {
+			return nil, err
+		}
+		count, err := ms.messageRepo.CountConversation(userID, otherID, unreadQuery(userID, cursor))
+		if err != nil {
+			return nil, err
+		}
+		counts.Conversations = append(counts.Conversations, newUnreadCountDTO(count, cursor, otherID, ""))
+	}
+
+	if user.TeamsIds != nil {
+		for _, teamID := range *user.TeamsIds {
+			cursor := teamCursors[entity.TeamThread(teamID)]
+			count, err := ms.messageRepo.CountTeam(teamID, unreadQuery(userID, cursor))
+			if err != nil {
+				return nil, err
+			}
+			counts.Teams = append(counts.Teams, newUnreadCountDTO(count, cursor, "", teamID))
+		}
+	}
+	return counts, nil
+}
+
+// unreadQuery counts one message past maxUnreadCount, to tell whether counting stopped early
+func unreadQuery(userID string, cursor *entity.ReadCursor) persistence.MessageCountQuery {
+	query := persistence.MessageCountQuery{ExcludeSender: userID, Limit: maxUnreadCount + 1}
+	if cursor != nil {
+		query.After = cursor.Position()
+	}
+	return query
+}
+
+func newUnreadCountDTO(count int, cursor *entity.ReadCursor, userID, teamID string) *dto.UnreadCountDTO {
+	unread := &dto.UnreadCountDTO{
+		UserID:  userID,
+		TeamID:  teamID,
+		Count:   min(count, maxUnreadCount),
+		HasMore: count > maxUnreadCount,
+	}
+	if cursor != nil {
+		unread.LastReadMessageID = cursor.LastReadMessageID
+	}
+	return unread
+}
+
+// readCursor returns the user's cursor of the thread, a fresh one when they have none yet
+func (ms *MessageService) readCursor(userID, thread string) (*entity.ReadCursor, error) {
+	cursor, err := ms.readCursorRepo.Get(userID, thread)
+	if err != nil {
+		if err.Error() == persistence.ReadCursorNotFound {
+			return entity.NewReadCursor(userID, thread), nil
+		}
+		return nil, err
+	}
+	return cursor, nil
+}
+
+// recordSent moves the sender's read cursor past their own message and makes sure the receiver of a
+// direct message has a cursor, so the conversation is listed in their unread counts.
+// The message is stored by then, so failures are only logged.
+func (ms *MessageService) recordSent(message *entity.Message, receiverID string) {
+	thread := message.Thread()
+	cursor, err := ms.readCursor(message.SenderID, thread)
+	if err == nil && cursor.Advance(message) {
+		err = ms.readCursorRepo.Save(cursor)
+	}
+	if err != nil {
+		log.Printf("failed to move read cursor of %s in %s: %v", message.SenderID, thread, err)
+	}
+
+	if receiverID == "" {
+		return
+	}
+	if _, err := ms.readCursorRepo.Get(receiverID, thread); err != nil {
+		if err.Error() == persistence.ReadCursorNotFound {
+			err = ms.readCursorRepo.Save(entity.NewReadCursor(receiverID, thread))
+		}
+		if err != nil {
+			log.Printf("failed to create read cursor of %s in %s: %v", receiverID, thread, err)
+		}
+	}
+}
+
+// recordReply lists a thread reply on its parent. As with recordSent, the reply is stored by then.
+func (ms *MessageService) recordReply(message *entity.Message) {
+	if message.ParentID == "" {
+		return
+	}
+	if err := ms.messageRepo.Update(message.ParentID, map[string]interface{}{"replies/" + message.ID: message.SentAt}); err != nil {
+		log.Printf("failed to list reply %s under %s: %v", message.ID, message.ParentID, err)
+	}
+}
+
+// participants returns the users of the message's conversation or team, or ErrForbidden unless the user is one of them
+func (ms *MessageService) participants(userID string, message *entity.Message) ([]string, error) {
+	if message.TeamID == "" {
+		receiverId, err := entity.GetReceiverIdFromKey(message.SenderID, message.ConversationKey)
+		if err != nil {
+			return nil, err
+		}
+		if userID != message.SenderID && userID != receiverId {
+			return nil, fmt.Errorf("%w: not part of this conversation", ErrForbidden)
+		}
+		return []string{message.SenderID, receiverId}, nil
+	}
+
+	team, err := ms.teamRepo.GetTeamById(message.TeamID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+	}
+	if !team.IsMember(userID) {
+		return nil, fmt.Errorf("%w: %s", ErrForbidden, notTeamMemberError)
+	}
+	return team.UsersIds, nil
+}
+
+// authorizeMessageChange loads a message that the actor may edit or delete: their own message,
+// or any message of a team they moderate. It also returns the users who can see the message.
+func (ms *MessageService) authorizeMessageChange(actorID, id string) (*entity.Message, []string, error) {
+	message, err := ms.messageRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrResourceNotFound, persistence.MessageNotFound)
+	}
+	if message.IsDeleted() {
+		return nil, nil, ErrMessageDeleted
+	}
+
+	if message.TeamID == "" {
+		receiverId, err := entity.GetReceiverIdFromKey(message.SenderID, message.ConversationKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		if message.SenderID != actorID {
+			return nil, nil, fmt.Errorf("%w: %s", ErrForbidden, notMessageSenderError)
+		}
+		return message, []string{message.SenderID, receiverId}, nil
+	}
+
+	team, err := ms.teamRepo.GetTeamById(message.TeamID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+	}
+	if message.SenderID != actorID {
+		if err := CheckTeamPermission(team, actorID, PermissionModerateContent); err != nil {
+			return nil, nil, err
+		}
+	}
+	return message, team.UsersIds, nil
+}
+
+func (ms *MessageService) toMessageDTO(message *entity.Message) (*dto.MessageDTO, error) {
+	receiverId := ""
+	if message.ConversationKey != "" {
+		var err error
+		receiverId, err = entity.GetReceiverIdFromKey(message.SenderID, message.ConversationKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sender, err := ms.senderOf(message)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewMessageDTOFromEntity(message, receiverId, *sender), nil
+}
+
+// senderOf looks up the sender of a message, falling back to deletedSender when the account no longer exists
+func (ms *MessageService) senderOf(message *entity.Message) (*dto.SenderDTO, error) {
 	sender, err := ms.userRepo.GetByID(message.SenderID)
 	if err != nil {
+		if err.Error() == userNotFound {
+			return deletedSender(message.SenderID), nil
+		}
 		return nil, fmt.Errorf("sender not found")
 	}
+	return dto.NewSenderDTO(sender), nil
+}
 
-	senderDTO := dto.NewSenderDTO(sender)
-	dtoMessage := dto.NewMessageDTO(message.ID, receiverId, message.TeamID, message.TextContent, message.SentAt, *senderDTO)
-	return dtoMessage, err
+// deletedSender stands in for a sender who deleted their account, so their messages stay readable
+func deletedSender(id string) *dto.SenderDTO {
+	return &dto.SenderDTO{ID: id}
 }
 
-func (ms *MessageService) GetDirectMessages(user1Id, user2Id string) ([]*dto.MessageDTO, error) {
+func (ms *MessageService) GetDirectMessages(user1Id, user2Id string, page dto.MessagePageRequest) (*dto.MessagePageDTO, error) {
+	query, err := messagePageQuery(page)
+	if err != nil {
+		return nil, err
+	}
 	if _, err := ms.userRepo.GetByID(user1Id); err != nil {
 		return nil, fmt.Errorf("user1 not found")
 	}
@@ -134,43 +733,112 @@ func (ms *MessageService) GetDirectMessages(user1Id, user2Id string) ([]*dto.Mes
 		return nil, fmt.Errorf("user2 not found")
 	}
 
-	messages, err := ms.messageRepo.GetByConversation(user1Id, user2Id)
-	dtoMessages := []*dto.MessageDTO{}
-	for _, message := range messages {
-		receiverId, key_err := entity.GetReceiverIdFromKey(message.SenderID, message.ConversationKey)
-		if message.ConversationKey != "" && key_err != nil {
-			return nil, err
-		}
+	messages, err := ms.messageRepo.GetConversationPage(user1Id, user2Id, query)
+	if err != nil {
+		return nil, err
+	}
+	return ms.toMessagePage(messages, query)
+}
 
-		sender, err := ms.userRepo.GetByID(message.SenderID)
+// GetTeamMessages returns a page of a team's messages to one of its members
+func (ms *MessageService) GetTeamMessages(userID, teamId string, page dto.MessagePageRequest) (*dto.MessagePageDTO, error) {
+	query, err := messagePageQuery(page)
+	if err != nil {
+		return nil, err
+	}
+	team, err := ms.teamRepo.GetTeamById(teamId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+	}
+	if err := CheckTeamMember(team, userID); err != nil {
+		return nil, err
+	}
+
+	messages, err := ms.messageRepo.GetTeamPage(teamId, query)
+	if err != nil {
+		return nil, err
+	}
+	return ms.toMessagePage(messages, query)
+}
+
+// messagePageQuery validates the request and asks for one message more than the limit, to tell whether more remain
+func messagePageQuery(page dto.MessagePageRequest) (persistence.MessagePageQuery, error) {
+	query := persistence.MessagePageQuery{Limit: defaultMessagePageSize}
+	if page.Limit < 0 {
+		return query, ErrInvalidPageLimit
+	}
+	if page.Limit > 0 {
+		query.Limit = min(page.Limit, maxMessagePageSize)
+	}
+	if page.Before != "" && page.After != "" {
+		return query, ErrInvalidCursor
+	}
+	if page.Before != "" {
+		cursor, err := entity.ParseMessageCursor(page.Before)
 		if err != nil {
-			return nil, fmt.Errorf("sender not found")
+			return query, ErrInvalidCursor
 		}
-
-		senderDTO := dto.NewSenderDTO(sender)
-		dtoMessage := dto.NewMessageDTO(message.ID, receiverId, message.TeamID, message.TextContent, message.SentAt, *senderDTO)
-		dtoMessages = append(dtoMessages, dtoMessage)
+		query.Before = cursor
+	}
+	if page.After != "" {
+		cursor, err := entity.ParseMessageCursor(page.After)
+		if err != nil {
+			return query, ErrInvalidCursor
+		}
+		query.After = cursor
 	}
-	return dtoMessages, err
+	query.Limit++
+	return query, nil
 }
 
-func (ms *MessageService) GetTeamMessages(teamId string) ([]*dto.MessageDTO, error) {
-	if _, err := ms.teamRepo.GetTeamById(teamId); err != nil {
-		return nil, fmt.Errorf("team not found")
+// toMessagePage drops the extra message fetched by messagePageQuery and resolves all senders in one lookup
+func (ms *MessageService) toMessagePage(messages []*entity.Message, query persistence.MessagePageQuery) (*dto.MessagePageDTO, error) {
+	limit := query.Limit - 1
+	page := &dto.MessagePageDTO{Messages: []*dto.MessageDTO{}}
+	if len(messages) > limit {
+		page.HasMore = true
+		if query.After != nil {
+			messages = messages[:limit]
+		} else {
+			messages = messages[len(messages)-limit:]
+		}
 	}
 
-	messages, err := ms.messageRepo.GetByTeamID(teamId)
-	dtoMessages := []*dto.MessageDTO{}
+	senderIDs := make([]string, 0, len(messages))
 	for _, message := range messages {
+		senderIDs = append(senderIDs, message.SenderID)
+	}
+	senders, err := ms.userRepo.GetByIDs(senderIDs)
+	if err != nil {
+		return nil, err
+	}
+	sendersByID := make(map[string]*dto.SenderDTO, len(senders))
+	for _, sender := range senders {
+		sendersByID[sender.ID] = dto.NewSenderDTO(sender)
+	}
 
-		sender, err := ms.userRepo.GetByID(message.SenderID)
-		if err != nil {
-			return nil, fmt.Errorf("sender not found")
+	for _, message := range messages {
+		receiverId := ""
+		if message.ConversationKey != "" {
+			receiverId, err = entity.GetReceiverIdFromKey(message.SenderID, message.ConversationKey)
+			if err != nil {
+				return nil, err
+			}
+		}
+		sender, ok := sendersByID[message.SenderID]
+		if !ok {
+			sender = deletedSender(message.SenderID)
 		}
+		page.Messages = append(page.Messages, dto.NewMessageDTOFromEntity(message, receiverId, *sender))
+	}
 
-		senderDTO := dto.NewSenderDTO(sender)
-		dtoMessage := dto.NewMessageDTO(message.ID, "", message.TeamID, message.TextContent, message.SentAt, *senderDTO)
-		dtoMessages = append(dtoMessages, dtoMessage)
+	// the next page continues past the last message in the paging direction
+	if page.HasMore {
+		if query.After != nil {
+			page.NextCursor = messages[len(messages)-1].Cursor().Encode()
+		} else {
+			page.NextCursor = messages[0].Cursor().Encode()
+		}
 	}
-	return dtoMessages, err
+	return page, nil
 }
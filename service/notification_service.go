@@ -0,0 +1,368 @@
+package service
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/hub"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/mailer"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/validator"
+)
+
+const (
+	defaultNotificationLimit = 20
+	maxNotificationLimit     = 100
+	notificationNotFound     = "notification not found"
+	notificationEmailSubject = "New StudyWithMe notification"
+	notificationsPath        = "/notifications"
+	notificationWorkers      = 4
+	notificationQueueSize    = 256
+)
+
+// Notifier is how services tell users that something happened. Notify queues a copy of notification for every
+// recipient except its actor, which is delivered in the background through the channels their preferences allow.
+// A notification is a side effect of the change that caused it, so failures are logged and never fail that change.
+type Notifier interface {
+	Notify(notification entity.Notification, recipients ...string)
+}
+
+// NotificationSender pushes events to the sockets of a user, like hub.Hub
+type NotificationSender interface {
+	Send(clientID string, msg hub.Message)
+}
+
+// PushSender delivers notifications to the user's devices through a push provider
+type PushSender interface {
+	Push(userID string, notification *entity.Notification) error
+}
+
+type NotificationServiceInterface interface {
+	Notifier
+	GetNotifications(userID string, unreadOnly bool, page, limit int) (*dto.NotificationsPageDTO, error)
+	GetUnreadCounts(userID string) (*dto.UnreadNotificationsDTO, error)
+	MarkRead(userID, id string) (*entity.Notification, error)
+	MarkAllRead(userID string) (*dto.NotificationsReadDTO, error)
+	GetPreferences(userID string) (*dto.NotificationPreferencesDTO, error)
+	UpdatePreferences(userID string, request *dto.NotificationPreferencesRequest) (*dto.NotificationPreferencesDTO, error)
+}
+
+type NotificationService struct {
+	notificationRepo persistence.NotificationRepositoryInterface
+	preferencesRepo  persistence.NotificationPreferencesRepositoryInterface
+	userRepo         UserRepositoryInterface
+	teamRepo         TeamRepositoryInterface
+	sender           NotificationSender
+	mailer           mailer.Mailer
+	// push is nil until a push provider is set, and push notifications are then skipped
+	push PushSender
+	// queues hold the notifications waiting for delivery, one per worker. All notifications of a user
+	// go through the same queue, so they reach the user in the order they were sent.
+	queues  []chan *entity.Notification
+	pending sync.WaitGroup
+}
+
+func NewNotificationService() *NotificationService {
+	return startNotificationWorkers(&NotificationService{
+		notificationRepo: persistence.NewNotificationRepository(),
+		preferencesRepo:  persistence.NewNotificationPreferencesRepository(),
+		userRepo:         persistence.NewUserRepository(),
+		teamRepo:         persistence.NewTeamRepository(),
+		sender:           hub.Messages(),
+		mailer:           mailer.NewMailer(),
+	})
+}
+
+func NewNotificationServiceWithRepo(notificationRepo persistence.NotificationRepositoryInterface, preferencesRepo persistence.NotificationPreferencesRepositoryInterface, userRepo UserRepositoryInterface, teamRepo TeamRepositoryInterface, sender NotificationSender, m mailer.Mailer) *NotificationService {
+	return startNotificationWorkers(&NotificationService{
+		notificationRepo: notificationRepo,
+		preferencesRepo:  preferencesRepo,
+		userRepo:         userRepo,
+		teamRepo:         teamRepo,
+		sender:           sender,
+		mailer:           m,
+	})
+}
+
+func startNotificationWorkers(ns *NotificationService) *NotificationService {
+	ns.queues = make([]chan *entity.Notification, notificationWorkers)
+	for i := range ns.queues {
+		ns.queues[i] = make(chan *entity.Notification, notificationQueueSize)
+		go ns.work(ns.queues[i])
+	}
+	return ns
+}
+
+func (ns *NotificationService) SetPushSender(push PushSender) {
+	ns.push = push
+}
+
+// Notify queues a notification for every recipient and returns without waiting for them to be delivered.
+// It only blocks when the queue of a recipient is full.
+func (ns *NotificationService) Notify(notification entity.Notification, recipients ...string) {
+	seen := make(map[string]bool)
+	for _, userID := range recipients {
+		if userID == "" || userID == notification.ActorID || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		id, err := generateID()
+		if err != nil {
+			log.Printf("failed to create %s notification for %s: %v", notification.Type, userID, err)
+			continue
+		}
+		ns.pending.Add(1)
+		ns.queueOf(userID) <- entity.NewNotification(id, userID, notification)
+	}
+}
+
+// Wait blocks until every notification queued so far has been delivered
+func (ns *NotificationService) Wait() {
+	ns.pending.Wait()
+}
+
+func (ns *NotificationService) queueOf(userID string) chan<- *entity.Notification {
+	hash := fnv.New32a()
+	hash.Write([]byte(userID))
+	return ns.queues[hash.Sum32()%uint32(len(ns.queues))]
+}
+
+func (ns *NotificationService) work(queue <-chan *entity.Notification) {
+	for notification := range queue {
+		ns.deliver(notification)
+		ns.pending.Done()
+	}
+}
+
+// deliver skips recipients who muted the notification's team. Otherwise the notification is kept for the in-app
+// list when the user wants it in-app, and sent to their sockets, by email and by push as their settings allow,
+// unless they are in their quiet hours. Quiet hours suppress rather than delay: what would have been sent then
+// is dropped, while in-app notifications are still kept, listed and counted.
+func (ns *NotificationService) deliver(delivered *entity.Notification) {
+	userID := delivered.UserID
+	preferences := ns.preferences(userID)
+	if preferences.IsTeamMuted(delivered.TeamID) {
+		return
+	}
+	quiet := preferences.InQuietHours(time.Now().UTC())
+
+	if preferences.Enabled(delivered.Type, entity.ChannelInApp) {
+		if err := ns.notificationRepo.Create(delivered); err != nil {
+			log.Printf("failed to create %s notification for %s: %v", delivered.Type, userID, err)
+		} else if !quiet {
+			ns.sendReceived(delivered)
+		}
+	}
+	if quiet {
+		return
+	}
+	if preferences.Enabled(delivered.Type, entity.ChannelEmail) {
+		ns.sendEmail(delivered)
+	}
+	if ns.push != nil && preferences.Enabled(delivered.Type, entity.ChannelPush) {
+		if err := ns.push.Push(userID, delivered); err != nil {
+			log.Printf("failed to push %s notification to %s: %v", delivered.Type, userID, err)
+		}
+	}
+}
+
+func (ns *NotificationService) sendReceived(notification *entity.Notification) {
+	ns.sender.Send(notification.UserID, *hub.NewMessage(hub.NotificationReceived, dto.NotificationEventDTO{
+		Notification: notification,
+		UnreadCount:  ns.unreadCount(notification.UserID),
+	}))
+}
+
+// sendEmail mails the notification to the user's address, once it is verified
+func (ns *NotificationService) sendEmail(notification *entity.Notification) {
+	user, err := ns.userRepo.GetByID(notification.UserID)
+	if err != nil {
+		log.Printf("failed to load %s to email a notification: %v", notification.UserID, err)
+		return
+	}
+	if !user.EmailVerified || user.Email == "" {
+		return
+	}
+	body := fmt.Sprintf("Hi %s,\n\n%s.\n\nSee your notifications at %s\n",
+		user.FirstName, notification.Text, config.GetAppURL()+notificationsPath)
+	if err := ns.mailer.Send(user.Email, notificationEmailSubject, body); err != nil {
+		log.Printf("failed to email %s notification to %s: %v", notification.Type, user.ID, err)
+	}
+}
+
+// preferences returns the user's settings, or the defaults when they saved none or they cannot be read
+func (ns *NotificationService) preferences(userID string) *entity.NotificationPreferences {
+	preferences, err := ns.preferencesRepo.GetByUserID(userID)
+	if err != nil {
+		if err.Error() != persistence.NotificationPreferencesNotFound {
+			log.Printf("failed to load the notification preferences of %s, using the defaults: %v", userID, err)
+		}
+		return entity.NewNotificationPreferences(userID)
+	}
+	return preferences
+}
+
+// GetPreferences returns the user's notification settings, with the default of every channel filled in
+func (ns *NotificationService) GetPreferences(userID string) (*dto.NotificationPreferencesDTO, error) {
+	preferences, err := ns.preferencesRepo.GetByUserID(userID)
+	if err != nil {
+		if err.Error() != persistence.NotificationPreferencesNotFound {
+			return nil, err
+		}
+		preferences = entity.NewNotificationPreferences(userID)
+	}
+	return dto.NewNotificationPreferencesDTO(preferences, time.Now()), nil
+}
+
+// UpdatePreferences replaces the user's notification settings. Only teams the user is a member of can be muted.
+func (ns *NotificationService) UpdatePreferences(userID string, request *dto.NotificationPreferencesRequest) (*dto.NotificationPreferencesDTO, error) {
+	if err := validator.ValidateNotificationPreferencesRequest(request); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	mutedTeamIDs := make([]string, 0, len(request.MutedTeamIDs))
+	for _, teamID := range request.MutedTeamIDs {
+		if slices.Contains(mutedTeamIDs, teamID) {
+			continue
+		}
+		team, err := ns.teamRepo.GetTeamById(teamID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+		}
+		if err := CheckTeamMember(team, userID); err != nil {
+			return nil, err
+		}
+		mutedTeamIDs = append(mutedTeamIDs, teamID)
+	}
+
+	preferences := entity.NewNotificationPreferences(userID)
+	for notificationType, channels := range request.Channels {
+		preferences.Channels[notificationType] = channels
+	}
+	preferences.MutedTeamIDs = mutedTeamIDs
+	preferences.QuietHours = request.QuietHours
+	preferences.Timezone = request.Timezone
+	preferences.UpdatedAt = time.Now().UTC()
+	if err := ns.preferencesRepo.Save(preferences); err != nil {
+		return nil, err
+	}
+	return dto.NewNotificationPreferencesDTO(preferences, time.Now()), nil
+}
+
+// GetNotifications returns a page of the user's notifications, newest first, optionally only the unread ones
+func (ns *NotificationService) GetNotifications(userID string, unreadOnly bool, page, limit int) (*dto.NotificationsPageDTO, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultNotificationLimit
+	}
+	if limit > maxNotificationLimit {
+		limit = maxNotificationLimit
+	}
+
+	notifications, err := ns.notificationRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	unread := filterUnread(notifications)
+	if unreadOnly {
+		notifications = unread
+	}
+
+	total := len(notifications)
+	start := min((page-1)*limit, total)
+	end := min(start+limit, total)
+	return &dto.NotificationsPageDTO{
+		Notifications: notifications[start:end],
+		UnreadCount:   len(unread),
+		Page:          page,
+		Limit:         limit,
+		TotalCount:    total,
+		TotalPages:    (total + limit - 1) / limit,
+	}, nil
+}
+
+// GetUnreadCounts counts the user's unread notifications, in total and by type
+func (ns *NotificationService) GetUnreadCounts(userID string) (*dto.UnreadNotificationsDTO, error) {
+	byType, err := ns.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+	counts := &dto.UnreadNotificationsDTO{ByType: byType}
+	for _, count := range byType {
+		counts.Total += count
+	}
+	return counts, nil
+}
+
+// MarkRead marks one of the user's notifications as read. Notifications of other users are not found.
+func (ns *NotificationService) MarkRead(userID, id string) (*entity.Notification, error) {
+	notification, err := ns.notificationRepo.GetByID(id)
+	if err != nil || notification.UserID != userID {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, notificationNotFound)
+	}
+	if !notification.MarkRead(time.Now().UTC()) {
+		return notification, nil
+	}
+	if err := ns.notificationRepo.Update(notification); err != nil {
+		return nil, err
+	}
+	ns.sendRead(userID, &dto.NotificationsReadDTO{IDs: []string{id}, Marked: 1})
+	return notification, nil
+}
+
+// MarkAllRead marks every unread notification of the user as read
+func (ns *NotificationService) MarkAllRead(userID string) (*dto.NotificationsReadDTO, error) {
+	marked, err := ns.notificationRepo.MarkAllRead(userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	read := &dto.NotificationsReadDTO{All: true, Marked: marked}
+	if marked > 0 {
+		ns.sendRead(userID, read)
+	}
+	return read, nil
+}
+
+// sendRead tells the user's other connections about notifications read on one of them, with the new unread count
+func (ns *NotificationService) sendRead(userID string, read *dto.NotificationsReadDTO) {
+	read.UnreadCount = ns.unreadCount(userID)
+	ns.sender.Send(userID, *hub.NewMessage(hub.NotificationsRead, read))
+}
+
+// unreadCount counts the user's unread notifications for socket events, which still go out, with 0, when it fails
+func (ns *NotificationService) unreadCount(userID string) int {
+	byType, err := ns.notificationRepo.CountUnread(userID)
+	if err != nil {
+		log.Printf("failed to count notifications of %s: %v", userID, err)
+	}
+	total := 0
+	for _, count := range byType {
+		total += count
+	}
+	return total
+}
+
+func filterUnread(notifications []*entity.Notification) []*entity.Notification {
+	unread := make([]*entity.Notification, 0, len(notifications))
+	for _, notification := range notifications {
+		if !notification.IsRead() {
+			unread = append(unread, notification)
+		}
+	}
+	return unread
+}
+
+// discardNotifier drops every notification; services built from explicit repositories start with it
+type discardNotifier struct{}
+
+func (discardNotifier) Notify(entity.Notification, ...string) {}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/validator"
+)
+
+const presenceHiddenError = "presence is only visible to friends and teammates"
+
+type PresenceService struct {
+	presenceRepo      persistence.PresenceRepositoryInterface
+	userRepo          UserRepositoryInterface
+	teamRepo          TeamRepositoryInterface
+	friendRequestRepo FriendRequestRepositoryInterface
+}
+
+func NewPresenceService() *PresenceService {
+	return &PresenceService{
+		presenceRepo:      persistence.NewPresenceRepository(),
+		userRepo:          persistence.NewUserRepository(),
+		teamRepo:          persistence.NewTeamRepository(),
+		friendRequestRepo: persistence.NewFriendRequestRepository(),
+	}
+}
+
+func NewPresenceServiceWithRepo(presenceRepo persistence.PresenceRepositoryInterface, userRepo UserRepositoryInterface, teamRepo TeamRepositoryInterface, friendRequestRepo FriendRequestRepositoryInterface) *PresenceService {
+	return &PresenceService{
+		presenceRepo:      presenceRepo,
+		userRepo:          userRepo,
+		teamRepo:          teamRepo,
+		friendRequestRepo: friendRequestRepo,
+	}
+}
+
+type PresenceServiceInterface interface {
+	SetStatus(userID string, status entity.PresenceStatus) (*dto.PresenceDTO, []string, error)
+	UpdatePresence(userID string, request *dto.SetPresenceRequest) (*dto.PresenceDTO, []string, error)
+	GetPresence(requesterID, userID string) (*dto.PresenceDTO, error)
+	GetTeamPresence(requesterID, teamID string) ([]*dto.PresenceDTO, error)
+}
+
+// SetStatus records the user's status. When it changed, the users to notify are returned too:
+// the user's friends and teammates.
+func (ps *PresenceService) SetStatus(userID string, status entity.PresenceStatus) (*dto.PresenceDTO, []string, error) {
+	current, err := ps.presenceRepo.GetByUserID(userID)
+	if err != nil && err.Error() != persistence.PresenceNotFound {
+		return nil, nil, err
+	}
+	if current != nil && current.Status == status {
+		return dto.NewPresenceDTO(current), nil, nil
+	}
+
+	presence := entity.NewPresence(userID, status)
+	if err := ps.presenceRepo.Save(presence); err != nil {
+		return nil, nil, err
+	}
+	audience, err := ps.audience(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dto.NewPresenceDTO(presence), audience, nil
+}
+
+// UpdatePresence is SetStatus for a status reported by the user's client, which may only be online or idle
+func (ps *PresenceService) UpdatePresence(userID string, request *dto.SetPresenceRequest) (*dto.PresenceDTO, []string, error) {
+	if err := validator.ValidateSetPresenceRequest(request); err != nil {
+		return nil, nil, err
+	}
+	return ps.SetStatus(userID, request.Status)
+}
+
+// GetPresence returns the presence of a user to themselves, their friends and their teammates
+func (ps *PresenceService) GetPresence(requesterID, userID string) (*dto.PresenceDTO, error) {
+	if requesterID != userID {
+		audience, err := ps.audience(userID)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(audience, requesterID) {
+			return nil, fmt.Errorf("%w: %s", ErrForbidden, presenceHiddenError)
+		}
+	}
+
+	presence, err := ps.presenceRepo.GetByUserID(userID)
+	if err != nil {
+		if err.Error() == persistence.PresenceNotFound {
+			return dto.NewOfflinePresenceDTO(userID), nil
+		}
+		return nil, err
+	}
+	return dto.NewPresenceDTO(presence), nil
+}
+
+// GetTeamPresence returns the presence of every member of a team, in member order. Only members may ask.
+func (ps *PresenceService) GetTeamPresence(requesterID, teamID string) ([]*dto.PresenceDTO, error) {
+	team, err := ps.teamRepo.GetTeamById(teamID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+	}
+	if err := CheckTeamMember(team, requesterID); err != nil {
+		return nil, err
+	}
+
+	presences, err := ps.presenceRepo.GetByUserIDs(team.UsersIds)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[string]*entity.Presence, len(presences))
+	for _, presence := range presences {
+		byUser[presence.UserID] = presence
+	}
+
+	roster := make([]*dto.PresenceDTO, 0, len(team.UsersIds))
+	for _, memberID := range team.UsersIds {
+		if presence, ok := byUser[memberID]; ok {
+			roster = append(roster, dto.NewPresenceDTO(presence))
+		} else {
+			roster = append(roster, dto.NewOfflinePresenceDTO(memberID))
+		}
+	}
+	return roster, nil
+}
+
+// audience returns the friends and teammates of a user, without the user
+func (ps *PresenceService) audience(userID string) ([]string, error) {
+	user, err := ps.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: user not found", ErrResourceNotFound)
+	}
+	friends, err := ps.friendRequestRepo.GetFriendsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{userID: true}
+	audience := make([]string, 0, len(friends))
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			audience = append(audience, id)
+		}
+	}
+	for _, friendID := range friends {
+		add(friendID)
+	}
+	if user.TeamsIds != nil {
+		for _, teamID := range *user.TeamsIds {
+			team, err := ps.teamRepo.GetTeamById(teamID)
+			if err != nil {
+				// a team the user still lists but that is gone has no one left to tell
+				continue
+			}
+			for _, memberID := range team.UsersIds {
+				add(memberID)
+			}
+		}
+	}
+	return audience, nil
+}
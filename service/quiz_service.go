@@ -11,6 +11,7 @@ import (
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/search"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/utils"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/validator"
 )
@@ -38,27 +39,34 @@ type QuizServiceInterface interface {
 }
 
 type QuizService struct {
-	teamRepo TeamRepositoryInterface
-	userRepo UserRepositoryInterface
-	quizRepo persistence.QuizRepositoryInterface
+	teamRepo    TeamRepositoryInterface
+	userRepo    UserRepositoryInterface
+	quizRepo    persistence.QuizRepositoryInterface
+	searchIndex search.Writer
 }
 
 func NewQuizService() *QuizService {
 	return &QuizService{
-		teamRepo: persistence.NewTeamRepository(),
-		userRepo: persistence.NewUserRepository(),
-		quizRepo: persistence.NewQuizRepository(),
+		teamRepo:    persistence.NewTeamRepository(),
+		userRepo:    persistence.NewUserRepository(),
+		quizRepo:    persistence.NewQuizRepository(),
+		searchIndex: search.Default(),
 	}
 }
 
 func NewQuizServiceWithRepo(teamRepo TeamRepositoryInterface, userRepo UserRepositoryInterface, quizRepo persistence.QuizRepositoryInterface) *QuizService {
 	return &QuizService{
-		teamRepo: teamRepo,
-		userRepo: userRepo,
-		quizRepo: quizRepo,
+		teamRepo:    teamRepo,
+		userRepo:    userRepo,
+		quizRepo:    quizRepo,
+		searchIndex: search.NewIndex(),
 	}
 }
 
+func (qs *QuizService) SetSearchIndex(searchIndex search.Writer) {
+	qs.searchIndex = searchIndex
+}
+
 func (qs *QuizService) isUserInTeam(userId string, teamId string) (bool, error) {
 	user, err := qs.userRepo.GetByID(userId)
 	if err != nil {
@@ -120,6 +128,7 @@ func (qs *QuizService) CreateQuiz(request entity.Quiz) (dto.CreateQuizResponse,
 	if err != nil {
 		return dto.CreateQuizResponse{}, err
 	}
+	qs.searchIndex.Put(search.QuizDocument(&request))
 
 	return dto.NewCreateQuizResponse(id), nil
 }
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/search"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/validator"
+)
+
+const (
+	searchBatchSize    = 500
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+type SearchServiceInterface interface {
+	Search(userID string, request *dto.SearchRequest) (*dto.SearchResponse, error)
+}
+
+// SearchService answers searches from the in-process index. The index is loaded from the database on
+// the first search and kept up to date by the services that write the searchable records.
+type SearchService struct {
+	index    *search.Index
+	reader   persistence.CollectionReader
+	userRepo UserRepositoryInterface
+	// buildMu makes concurrent first searches load the index once
+	buildMu sync.Mutex
+}
+
+func NewSearchService() *SearchService {
+	return &SearchService{
+		index:    search.Default(),
+		reader:   persistence.NewCollectionReader(),
+		userRepo: persistence.NewUserRepository(),
+	}
+}
+
+func NewSearchServiceWithRepo(index *search.Index, reader persistence.CollectionReader, userRepo UserRepositoryInterface) *SearchService {
+	return &SearchService{
+		index:    index,
+		reader:   reader,
+		userRepo: userRepo,
+	}
+}
+
+// Rebuild reloads the whole index from the database. Writes made to the index while it reads are
+// applied to what it read, so they are not lost when the new content is swapped in.
+func (ss *SearchService) Rebuild() error {
+	rebuild := ss.index.StartRebuild()
+	defer rebuild.Cancel()
+
+	docs := make([]*search.Document, 0)
+	add := func(doc *search.Document) { docs = append(docs, doc) }
+
+	if err := persistence.ReadCollection(ss.reader, persistence.MessagesCollection, searchBatchSize, func(m *entity.Message) {
+		add(search.MessageDocument(m))
+	}); err != nil {
+		return err
+	}
+	if err := persistence.ReadCollection(ss.reader, persistence.FilesCollection, searchBatchSize, func(f *entity.File) {
+		add(search.FileDocument(f))
+	}); err != nil {
+		return err
+	}
+	if err := persistence.ReadCollection(ss.reader, persistence.QuizzesCollection, searchBatchSize, func(q *entity.Quiz) {
+		add(search.QuizDocument(q))
+	}); err != nil {
+		return err
+	}
+	if err := persistence.ReadCollection(ss.reader, persistence.EventsCollection, searchBatchSize, func(e *entity.Event) {
+		add(search.EventDocument(e))
+	}); err != nil {
+		return err
+	}
+	if err := persistence.ReadCollection(ss.reader, persistence.TeamsCollection, searchBatchSize, func(t *entity.Team) {
+		add(search.TeamDocument(t))
+	}); err != nil {
+		return err
+	}
+
+	rebuild.Finish(docs)
+	return nil
+}
+
+// StartRebuildJob runs Rebuild every interval in the background. Each instance keeps its own index,
+// so with several instances this is how one picks up the writes made through the others.
+func (ss *SearchService) StartRebuildJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := ss.Rebuild(); err != nil {
+				log.Printf("search index rebuild failed: %v", err)
+			}
+		}
+	}()
+}
+
+func (ss *SearchService) ensureBuilt() error {
+	if ss.index.Built() {
+		return nil
+	}
+	ss.buildMu.Lock()
+	defer ss.buildMu.Unlock()
+	if ss.index.Built() {
+		return nil
+	}
+	return ss.Rebuild()
+}
+
+// Search finds the messages, files, quizzes, events and teams matching the query that the user can see:
+// everything of their teams and direct conversations, and every public team.
+func (ss *SearchService) Search(userID string, request *dto.SearchRequest) (*dto.SearchResponse, error) {
+	if err := validator.ValidateSearchRequest(request); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := ss.ensureBuilt(); err != nil {
+		return nil, err
+	}
+
+	user, err := ss.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: user not found", ErrResourceNotFound)
+	}
+	teams := make(map[string]bool)
+	if user.TeamsIds != nil {
+		for _, teamID := range *user.TeamsIds {
+			teams[teamID] = true
+		}
+	}
+
+	page, limit := request.Page, request.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	kinds := make([]search.Kind, 0, len(request.Types))
+	for _, kind := range request.Types {
+		kinds = append(kinds, search.Kind(kind))
+	}
+
+	hits, total := ss.index.Search(search.Query{
+		Text:   request.Query,
+		Kinds:  kinds,
+		TeamID: request.TeamID,
+		Visible: func(doc *search.Document) bool {
+			return doc.Scope.Public || teams[doc.Scope.TeamID] || slices.Contains(doc.Scope.UserIDs, userID)
+		},
+		Offset: (page - 1) * limit,
+		Limit:  limit,
+	})
+
+	results := make([]*dto.SearchResultDTO, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, dto.NewSearchResultDTO(hit))
+	}
+	return &dto.SearchResponse{
+		Results:    results,
+		Page:       page,
+		Limit:      limit,
+		TotalCount: total,
+		TotalPages: (total + limit - 1) / limit,
+	}, nil
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	accessTokenTTL        = 15 * time.Minute
+	accessTokenExpiresIn  = "15m"
+	refreshTokenTTL       = 30 * 24 * time.Hour
+	refreshTokenExpiresIn = "720h"
+
+	refreshTokenSeparator = "."
+)
+
+// Errors returned by session service
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+	ErrTokenRevoked        = errors.New("token has been revoked")
+)
+
+type SessionServiceInterface interface {
+	StartSession(user *entity.User) (*dto.LoginResponse, error)
+	RefreshSession(refreshToken string) (*dto.LoginResponse, error)
+	Logout(claims jwt.MapClaims) error
+	LogoutAll(userID string) error
+	LogoutOthers(userID, keepSessionID string) error
+	ValidateAccessToken(claims jwt.MapClaims) error
+}
+
+type SessionService struct {
+	sessionRepo persistence.SessionRepositoryInterface
+	userRepo    UserRepositoryInterface
+}
+
+func NewSessionService() *SessionService {
+	return &SessionService{
+		sessionRepo: persistence.NewSessionRepository(),
+		userRepo:    persistence.NewUserRepository(),
+	}
+}
+
+func NewSessionServiceWithRepo(sessionRepo persistence.SessionRepositoryInterface, userRepo UserRepositoryInterface) *SessionService {
+	return &SessionService{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// StartSession creates a new server-side session for the user and returns the first token pair
+func (ss *SessionService) StartSession(user *entity.User) (*dto.LoginResponse, error) {
+	sessionID, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := entity.NewSession(sessionID, user.ID, hashToken(secret), time.Now().UTC().Add(refreshTokenTTL))
+	if err := ss.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	return ss.issueTokens(user, session, secret)
+}
+
+// RefreshSession rotates the refresh token of a session. Presenting a refresh token that
+// was already rotated away revokes the whole session, since it means the token leaked.
+func (ss *SessionService) RefreshSession(refreshToken string) (*dto.LoginResponse, error) {
+	sessionID, secret, ok := strings.Cut(refreshToken, refreshTokenSeparator)
+	if !ok || sessionID == "" || secret == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := ss.sessionRepo.GetByID(sessionID)
+	if err != nil || session == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if !session.IsActive() {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(session.RefreshTokenHash), []byte(hashToken(secret))) != 1 {
+		session.Revoked = true
+		if err := ss.sessionRepo.Update(session); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := ss.userRepo.GetByID(session.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	newSecret, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	session.RefreshTokenHash = hashToken(newSecret)
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(refreshTokenTTL)
+	if err := ss.sessionRepo.Update(session); err != nil {
+		return nil, err
+	}
+
+	return ss.issueTokens(user, session, newSecret)
+}
+
+// Logout revokes the session the access token belongs to, and the access token itself
+func (ss *SessionService) Logout(claims jwt.MapClaims) error {
+	sessionID, jti, err := sessionClaims(claims)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().UTC().Add(accessTokenTTL)
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		expiresAt = exp.Time
+	}
+	if err := ss.sessionRepo.RevokeToken(entity.NewRevokedToken(jti, expiresAt)); err != nil {
+		return err
+	}
+
+	session, err := ss.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.Revoked {
+		return nil
+	}
+	session.Revoked = true
+	return ss.sessionRepo.Update(session)
+}
+
+// LogoutAll revokes every session of the user, logging them out on all devices
+func (ss *SessionService) LogoutAll(userID string) error {
+	return ss.LogoutOthers(userID, "")
+}
+
+// LogoutOthers revokes every session of the user except keepSessionID, logging them out on their other devices
+func (ss *SessionService) LogoutOthers(userID, keepSessionID string) error {
+	if userID == "" {
+		return fmt.Errorf("user id cannot be empty")
+	}
+
+	sessions, err := ss.sessionRepo.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.Revoked || session.ID == keepSessionID {
+			continue
+		}
+		session.Revoked = true
+		if err := ss.sessionRepo.Update(session); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateAccessToken checks that an already signature-verified access token still
+// belongs to a live session and was not individually revoked
+func (ss *SessionService) ValidateAccessToken(claims jwt.MapClaims) error {
+	sessionID, jti, err := sessionClaims(claims)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := ss.sessionRepo.IsTokenRevoked(jti)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+
+	session, err := ss.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		return ErrSessionRevoked
+	}
+	if session.Revoked {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+func (ss *SessionService) issueTokens(user *entity.User, session *entity.Session, secret string) (*dto.LoginResponse, error) {
+	jti, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":      user.ID,
+		"username": user.Username,
+		"email":    user.Email,
+		"sid":      session.ID,
+		"jti":      jti,
+		"exp":      now.Add(accessTokenTTL).Unix(),
+		"iat":      now.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(config.GetJWTSecret()))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := dto.NewLoginResponse(signed, accessTokenExpiresIn, user)
+	resp.RefreshToken = session.ID + refreshTokenSeparator + secret
+	resp.RefreshExpiresIn = refreshTokenExpiresIn
+	return resp, nil
+}
+
+func sessionClaims(claims jwt.MapClaims) (string, string, error) {
+	sessionID, ok := claims["sid"].(string)
+	if !ok || sessionID == "" {
+		return "", "", ErrSessionRevoked
+	}
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return "", "", ErrTokenRevoked
+	}
+	return sessionID, jti, nil
+}
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+)
+
+type TeamPermission string
+
+const (
+	PermissionUpdateTeam        TeamPermission = "team:update"
+	PermissionDeleteTeam        TeamPermission = "team:delete"
+	PermissionManageMembers     TeamPermission = "team:members"
+	PermissionManageRoles       TeamPermission = "team:roles"
+	PermissionTransferOwnership TeamPermission = "team:transfer"
+	PermissionReviewRequests    TeamPermission = "team:requests"
+	PermissionModerateContent   TeamPermission = "team:moderate"
+)
+
+const (
+	notTeamMemberError      = "user is not a member of this team"
+	missingPermissionError  = "missing team permission"
+	ownerCannotLeaveError   = "the owner must transfer ownership before leaving the team"
+	ownerCannotDeleteError  = "the owner of a team must transfer ownership or delete the team before deleting their account"
+	invalidTeamRoleError    = "invalid team role"
+	ownerRoleViaTransferErr = "ownership can only be given through a transfer"
+	privateTeamJoinError    = "this team is private: send a join request or use an invitation"
+)
+
+// rolePermissions lists what each team role may do. Owners can do everything admins can.
+var rolePermissions = map[entity.TeamRole][]TeamPermission{
+	entity.TeamRoleOwner: {
+		PermissionUpdateTeam,
+		PermissionDeleteTeam,
+		PermissionManageMembers,
+		PermissionManageRoles,
+		PermissionTransferOwnership,
+		PermissionReviewRequests,
+		PermissionModerateContent,
+	},
+	entity.TeamRoleAdmin: {
+		PermissionUpdateTeam,
+		PermissionManageMembers,
+		PermissionReviewRequests,
+		PermissionModerateContent,
+	},
+	entity.TeamRoleMember: {},
+}
+
+// HasTeamPermission reports whether the user's role in an already loaded team grants the permission
+func HasTeamPermission(team *entity.Team, userID string, permission TeamPermission) bool {
+	for _, p := range rolePermissions[team.RoleOf(userID)] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTeamPermission is HasTeamPermission returning an ErrForbidden-wrapped error
+func CheckTeamPermission(team *entity.Team, userID string, permission TeamPermission) error {
+	if !team.IsMember(userID) {
+		return fmt.Errorf("%w: %s", ErrForbidden, notTeamMemberError)
+	}
+	if !HasTeamPermission(team, userID, permission) {
+		return fmt.Errorf("%w: %s %s", ErrForbidden, missingPermissionError, permission)
+	}
+	return nil
+}
+
+// CheckTeamMember returns an error unless the user is a member of the team. Private teams are
+// reported as not found, so that non-members cannot tell they exist.
+func CheckTeamMember(team *entity.Team, userID string) error {
+	if !team.VisibleTo(userID) {
+		return fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+	}
+	if !team.IsMember(userID) {
+		return fmt.Errorf("%w: %s", ErrForbidden, notTeamMemberError)
+	}
+	return nil
+}
+
+type TeamAuthorizerInterface interface {
+	Authorize(userID, teamID string, permission TeamPermission) (*entity.Team, error)
+}
+
+// TeamAuthorizer loads a team and checks a member's permission on it, for services that only have IDs
+type TeamAuthorizer struct {
+	teamRepo TeamRepositoryInterface
+}
+
+func NewTeamAuthorizer() *TeamAuthorizer {
+	return &TeamAuthorizer{teamRepo: persistence.NewTeamRepository()}
+}
+
+func NewTeamAuthorizerWithRepo(teamRepo TeamRepositoryInterface) *TeamAuthorizer {
+	return &TeamAuthorizer{teamRepo: teamRepo}
+}
+
+// Authorize returns the team when the user holds the permission on it
+func (ta *TeamAuthorizer) Authorize(userID, teamID string, permission TeamPermission) (*entity.Team, error) {
+	team, err := ta.teamRepo.GetTeamById(teamID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+	}
+	if err := CheckTeamPermission(team, userID, permission); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
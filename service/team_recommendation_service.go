@@ -0,0 +1,220 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+)
+
+// A recommendation score adds up four parts, each between 0 and its weight
+const (
+	topicWeight    = 0.4
+	friendsWeight  = 0.3
+	activityWeight = 0.2
+	sizeWeight     = 0.1
+
+	// friendsForFullScore, activeMessagesForFullScore and membersForFullScore are where each part stops growing
+	friendsForFullScore        = 3
+	activeMessagesForFullScore = 50
+	membersForFullScore        = 20
+
+	// activityWindow is how far back team messages count as activity
+	activityWindow = 14 * 24 * time.Hour
+
+	defaultRecommendationLimit = 10
+	maxRecommendationLimit     = 50
+	// maxCountedTeams bounds the message counts of one request; teams past it are ranked without activity
+	maxCountedTeams = 100
+)
+
+type TeamRecommendationServiceInterface interface {
+	GetRecommendedTeams(userID string, page, limit int) (*dto.TeamRecommendationsDTO, error)
+}
+
+// TeamRecommendationService suggests public teams a user could join
+type TeamRecommendationService struct {
+	userRepo          UserRepositoryInterface
+	teamRepo          TeamRepositoryInterface
+	friendRequestRepo FriendRequestRepositoryInterface
+	teamRequestRepo   TeamRequestRepositoryInterface
+	messageRepo       persistence.MessageRepositoryInterface
+}
+
+func NewTeamRecommendationService() *TeamRecommendationService {
+	return &TeamRecommendationService{
+		userRepo:          persistence.NewUserRepository(),
+		teamRepo:          persistence.NewTeamRepository(),
+		friendRequestRepo: persistence.NewFriendRequestRepository(),
+		teamRequestRepo:   persistence.NewTeamRequestRepository(),
+		messageRepo:       persistence.NewMessageRepository(),
+	}
+}
+
+func NewTeamRecommendationServiceWithRepo(userRepo UserRepositoryInterface, teamRepo TeamRepositoryInterface, friendRequestRepo FriendRequestRepositoryInterface, teamRequestRepo TeamRequestRepositoryInterface, messageRepo persistence.MessageRepositoryInterface) *TeamRecommendationService {
+	return &TeamRecommendationService{
+		userRepo:          userRepo,
+		teamRepo:          teamRepo,
+		friendRequestRepo: friendRequestRepo,
+		teamRequestRepo:   teamRequestRepo,
+		messageRepo:       messageRepo,
+	}
+}
+
+// GetRecommendedTeams ranks the public teams the user is not in and has no pending request for by
+// whether their topic is one of the user's, how many of the user's friends are members,
+// how many messages were sent in them lately and how many members they have.
+func (rs *TeamRecommendationService) GetRecommendedTeams(userID string, page, limit int) (*dto.TeamRecommendationsDTO, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultRecommendationLimit
+	}
+	if limit > maxRecommendationLimit {
+		limit = maxRecommendationLimit
+	}
+
+	user, err := rs.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: user not found", ErrResourceNotFound)
+	}
+	friendIDs, err := rs.friendRequestRepo.GetFriendsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := rs.teamRequestRepo.GetByUserId(userID)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := rs.teamRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[string]bool)
+	if user.TeamsIds != nil {
+		for _, teamID := range *user.TeamsIds {
+			excluded[teamID] = true
+		}
+	}
+	now := time.Now()
+	for _, request := range requests {
+		if request.IsPending(now) {
+			excluded[request.TeamID] = true
+		}
+	}
+
+	recommendations := make([]*dto.TeamRecommendationDTO, 0)
+	for _, team := range teams {
+		if !team.IsPublic || excluded[team.Id] || slices.Contains(team.UsersIds, userID) {
+			continue
+		}
+		recommendations = append(recommendations, newTeamRecommendation(team, user, friendIDs))
+	}
+
+	sortRecommendations(recommendations)
+	if err := rs.countActivity(recommendations, page*limit, now); err != nil {
+		return nil, err
+	}
+	sortRecommendations(recommendations)
+
+	total := len(recommendations)
+	start := min((page-1)*limit, total)
+	end := min(start+limit, total)
+	return &dto.TeamRecommendationsDTO{
+		Teams:      recommendations[start:end],
+		Page:       page,
+		Limit:      limit,
+		TotalCount: total,
+		TotalPages: (total + limit - 1) / limit,
+	}, nil
+}
+
+// countActivity counts the recent messages of recommendations, sorted best first without activity, while they can
+// still reach the first wanted places. Once even full activity would leave a team below the wanted-th score so far,
+// it would leave every team after it there too, so those are ranked without activity, as are teams past maxCountedTeams.
+func (rs *TeamRecommendationService) countActivity(recommendations []*dto.TeamRecommendationDTO, wanted int, now time.Time) error {
+	since := entity.MessageCursor{SentAt: now.Add(-activityWindow)}
+	for i, recommendation := range recommendations {
+		if i == maxCountedTeams {
+			return nil
+		}
+		if i >= wanted && recommendationScore(recommendation, activeMessagesForFullScore) < nthBestScore(recommendations[:i], wanted) {
+			return nil
+		}
+		recentMessages, err := rs.messageRepo.CountTeam(recommendation.Team.Id, persistence.MessageCountQuery{
+			After: &since,
+			Limit: activeMessagesForFullScore,
+		})
+		if err != nil {
+			return err
+		}
+		recommendation.RecentMessages = recentMessages
+		recommendation.Score = recommendationScore(recommendation, recentMessages)
+	}
+	return nil
+}
+
+// sortRecommendations orders recommendations best first, by team ID among equal scores
+func sortRecommendations(recommendations []*dto.TeamRecommendationDTO) {
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		if recommendations[i].Score != recommendations[j].Score {
+			return recommendations[i].Score > recommendations[j].Score
+		}
+		return recommendations[i].Team.Id < recommendations[j].Team.Id
+	})
+}
+
+// nthBestScore returns the n-th highest score of recommendations, which has at least n entries
+func nthBestScore(recommendations []*dto.TeamRecommendationDTO, n int) float64 {
+	scores := make([]float64, len(recommendations))
+	for i, recommendation := range recommendations {
+		scores[i] = recommendation.Score
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(scores)))
+	return scores[n-1]
+}
+
+// newTeamRecommendation scores a team without its activity, which countActivity adds
+func newTeamRecommendation(team *entity.Team, user *entity.User, friendIDs []string) *dto.TeamRecommendationDTO {
+	recommendation := &dto.TeamRecommendationDTO{
+		Team:        team,
+		FriendIDs:   []string{},
+		MemberCount: len(team.UsersIds),
+	}
+	if user.TopicsOfInterest != nil && slices.Contains(*user.TopicsOfInterest, team.TeamTopic) {
+		recommendation.MatchesTopic = true
+	}
+	for _, memberID := range team.UsersIds {
+		if slices.Contains(friendIDs, memberID) {
+			recommendation.FriendIDs = append(recommendation.FriendIDs, memberID)
+		}
+	}
+
+	recommendation.Score = recommendationScore(recommendation, 0)
+	return recommendation
+}
+
+// recommendationScore is the score of a recommendation with recentMessages messages sent in the team lately
+func recommendationScore(recommendation *dto.TeamRecommendationDTO, recentMessages int) float64 {
+	score := 0.0
+	if recommendation.MatchesTopic {
+		score += topicWeight
+	}
+	score += friendsWeight * capped(len(recommendation.FriendIDs), friendsForFullScore)
+	score += activityWeight * capped(recentMessages, activeMessagesForFullScore)
+	score += sizeWeight * capped(recommendation.MemberCount, membersForFullScore)
+	// rounded so that tiny float differences do not hide the ID tie-break
+	return math.Round(score*1000) / 1000
+}
+
+// capped returns value/full, at most 1
+func capped(value, full int) float64 {
+	return math.Min(float64(value)/float64(full), 1)
+}
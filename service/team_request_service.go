@@ -2,6 +2,11 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
@@ -14,39 +19,71 @@ type TeamRequestRepositoryInterface interface {
 	GetById(id string) (*entity.TeamRequest, error)
 	GetAll() ([]*entity.TeamRequest, error)
 	GetByUserId(userId string) ([]*entity.TeamRequest, error)
+	GetByTeamId(teamId string) ([]*entity.TeamRequest, error)
+	Update(req *entity.TeamRequest) error
+	// UpdateIfStatus writes req only while the stored request has the given status, a missing status
+	// counting as pending, and reports whether it did
+	UpdateIfStatus(req *entity.TeamRequest, status entity.TeamRequestStatus) (bool, error)
 	Delete(id string) error
 }
 
+const (
+	teamRequestNotFoundError = "team request not found"
+	invitationNotFoundError  = "invitation not found"
+	notInviteeError          = "only the invited user can answer an invitation"
+	acceptInvitationError    = "invitations are accepted by the invited user"
+	alreadyMemberError       = "user is already a member of this team"
+	notRequesterError        = "only the requester can withdraw a team request"
+	alreadyAnsweredError     = "team request was already answered"
+	inviteLinkPath           = "/join"
+	// defaultInviteLinkHours is how long an invite link works when the request does not say
+	defaultInviteLinkHours = 7 * 24
+)
+
 type TeamRequestService struct {
 	teamRequestRepository TeamRequestRepositoryInterface
+	inviteLinkRepository  persistence.TeamInviteLinkRepositoryInterface
 	userRepository        UserRepositoryInterface
 	teamRepository        TeamRepositoryInterface
 	teamService           TeamServiceInterface
+	teamAuthorizer        TeamAuthorizerInterface
+	notifier              Notifier
 }
 
 func NewTeamRequestService() *TeamRequestService {
 	return &TeamRequestService{
 		teamRequestRepository: persistence.NewTeamRequestRepository(),
+		inviteLinkRepository:  persistence.NewTeamInviteLinkRepository(),
 		userRepository:        persistence.NewUserRepository(),
 		teamRepository:        persistence.NewTeamRepository(),
 		teamService:           NewTeamService(),
+		teamAuthorizer:        NewTeamAuthorizer(),
+		notifier:              NewNotificationService(),
 	}
 }
 
 func NewTeamRequestServiceWithRepo(
 	trRepo TeamRequestRepositoryInterface,
+	inviteLinkRepo persistence.TeamInviteLinkRepositoryInterface,
 	userRepo UserRepositoryInterface,
 	teamRepo TeamRepositoryInterface,
 	teamService TeamServiceInterface,
 ) *TeamRequestService {
 	return &TeamRequestService{
 		teamRequestRepository: trRepo,
+		inviteLinkRepository:  inviteLinkRepo,
 		userRepository:        userRepo,
 		teamRepository:        teamRepo,
 		teamService:           teamService,
+		teamAuthorizer:        NewTeamAuthorizerWithRepo(teamRepo),
+		notifier:              discardNotifier{},
 	}
 }
 
+func (trs *TeamRequestService) SetNotifier(notifier Notifier) {
+	trs.notifier = notifier
+}
+
 type TeamServiceInterface interface {
 	AddUserToTeam(idUser string, idTeam string) (*entity.User, *entity.Team, error)
 	DeleteUserFromTeam(idUser string, idTeam string) (*entity.User, *entity.Team, error)
@@ -75,16 +112,21 @@ func (trs *TeamRequestService) CreateTeamRequest(req *dto.TeamRequestCreateDTO)
 
 	for _, uid := range team.UsersIds {
 		if uid == req.UserID {
-			return nil, errors.New("user is already a member of this team")
+			return nil, errors.New(alreadyMemberError)
 		}
 	}
 
+	now := time.Now().UTC()
 	existingRequests, err := trs.teamRequestRepository.GetByUserId(req.UserID)
 	if err == nil {
 		for _, r := range existingRequests {
-			if r.TeamID == req.TeamID {
-				return nil, errors.New("a pending request already exists for this user and team")
+			if r.TeamID != req.TeamID || !r.IsPending(now) {
+				continue
+			}
+			if r.IsInvite() {
+				return nil, errors.New("the user is already invited to this team, accept the invitation instead")
 			}
+			return nil, errors.New("a pending request already exists for this user and team")
 		}
 	}
 
@@ -93,41 +135,432 @@ func (trs *TeamRequestService) CreateTeamRequest(req *dto.TeamRequestCreateDTO)
 		return nil, err
 	}
 
-	newReq := entity.NewTeamRequest(id, user.ID, team.Id)
+	newReq := entity.NewTeamRequest(id, user.ID, team.Id, strings.TrimSpace(req.Message), now.Add(entity.TeamRequestTTL))
 	if err := trs.teamRequestRepository.Create(newReq); err != nil {
 		return nil, err
 	}
 
+	reviewers := make([]string, 0)
+	for _, memberID := range team.UsersIds {
+		if HasTeamPermission(team, memberID, PermissionReviewRequests) {
+			reviewers = append(reviewers, memberID)
+		}
+	}
+	trs.notifier.Notify(entity.Notification{
+		Type:       entity.NotificationTeamRequest,
+		ActorID:    user.ID,
+		TeamID:     team.Id,
+		ResourceID: newReq.Id,
+		Text:       user.Username + " asked to join " + team.Name,
+	}, reviewers...)
 	return newReq, nil
 }
 
-func (trs *TeamRequestService) AcceptTeamRequest(id string) (*entity.User, *entity.Team, error) {
+// AcceptTeamRequest adds the requester to the team; the reviewer must be an admin or the owner of the team
+func (trs *TeamRequestService) AcceptTeamRequest(id, reviewerID string) (*entity.User, *entity.Team, error) {
+	req, err := trs.teamRequestRepository.GetById(id)
+	if err != nil {
+		return nil, nil, errors.New(teamRequestNotFoundError)
+	}
+	if req.IsInvite() {
+		return nil, nil, fmt.Errorf("%w: %s", ErrForbidden, acceptInvitationError)
+	}
+
+	if _, err := trs.teamAuthorizer.Authorize(reviewerID, req.TeamID, PermissionReviewRequests); err != nil {
+		return nil, nil, err
+	}
+	if err := trs.checkPending(req); err != nil {
+		return nil, nil, err
+	}
+	return trs.accept(req, reviewerID)
+}
+
+// RejectTeamRequest rejects the request, or takes back an invitation; the reviewer must be an admin or the owner of the team
+func (trs *TeamRequestService) RejectTeamRequest(id, reviewerID string) error {
+	req, err := trs.teamRequestRepository.GetById(id)
+	if err != nil {
+		return errors.New(teamRequestNotFoundError)
+	}
+
+	permission, status := PermissionReviewRequests, entity.TeamRequestRejected
+	if req.IsInvite() {
+		permission, status = PermissionManageMembers, entity.TeamRequestWithdrawn
+	}
+	if _, err := trs.teamAuthorizer.Authorize(reviewerID, req.TeamID, permission); err != nil {
+		return err
+	}
+	if err := trs.checkPending(req); err != nil {
+		return err
+	}
+	return trs.resolve(req, status, reviewerID)
+}
+
+// WithdrawTeamRequest lets the requester take back a join request that was not answered yet
+func (trs *TeamRequestService) WithdrawTeamRequest(id, userID string) (*entity.TeamRequest, error) {
 	req, err := trs.teamRequestRepository.GetById(id)
 	if err != nil {
-		return nil, nil, errors.New("team request not found")
+		return nil, errors.New(teamRequestNotFoundError)
+	}
+	if req.IsInvite() || req.UserID != userID {
+		return nil, fmt.Errorf("%w: %s", ErrForbidden, notRequesterError)
+	}
+	if err := trs.checkPending(req); err != nil {
+		return nil, err
+	}
+	if err := trs.resolve(req, entity.TeamRequestWithdrawn, userID); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// GetTeamRequests lists the join requests and invitations of a team, newest first, optionally only those
+// with the given status. The caller must be allowed to review the requests of the team.
+func (trs *TeamRequestService) GetTeamRequests(actorID, teamID string, status entity.TeamRequestStatus) ([]*entity.TeamRequest, error) {
+	if status != "" && !status.IsValid() {
+		return nil, fmt.Errorf("validation failed: unknown status %q, expected one of pending, accepted, rejected, withdrawn, expired", status)
+	}
+	if _, err := trs.teamAuthorizer.Authorize(actorID, teamID, PermissionReviewRequests); err != nil {
+		return nil, err
+	}
+
+	requests, err := trs.teamRequestRepository.GetByTeamId(teamID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	trs.expireStale(requests, now)
+
+	result := make([]*entity.TeamRequest, 0, len(requests))
+	for _, r := range requests {
+		if status == "" || r.CurrentStatus(now) == status {
+			result = append(result, r)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// GetAll lists the team requests the actor may see, newest first: their own join requests and invitations,
+// and the requests of the teams whose requests they review
+func (trs *TeamRequestService) GetAll(actorID string) ([]*entity.TeamRequest, error) {
+	actor, err := trs.userRepository.GetByID(actorID)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := trs.teamRequestRepository.GetByUserId(actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, teamID := range teamIDsOf(actor) {
+		team, err := trs.teamRepository.GetTeamById(teamID)
+		if err != nil || CheckTeamPermission(team, actorID, PermissionReviewRequests) != nil {
+			continue
+		}
+		teamRequests, err := trs.teamRequestRepository.GetByTeamId(teamID)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range teamRequests {
+			if r.UserID != actorID {
+				requests = append(requests, r)
+			}
+		}
+	}
+	trs.expireStale(requests, time.Now().UTC())
+
+	sort.SliceStable(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.After(requests[j].CreatedAt)
+	})
+	return requests, nil
+}
+
+func (trs *TeamRequestService) GetByUserId(userId string) ([]*entity.TeamRequest, error) {
+	requests, err := trs.teamRequestRepository.GetByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	trs.expireStale(requests, time.Now().UTC())
+	return requests, nil
+}
+
+// checkPending fails for a request that was already answered or has expired,
+// storing the expired status when the request has only just timed out
+func (trs *TeamRequestService) checkPending(req *entity.TeamRequest) error {
+	now := time.Now().UTC()
+	trs.expireStale([]*entity.TeamRequest{req}, now)
+	if status := req.CurrentStatus(now); status != entity.TeamRequestPending {
+		return fmt.Errorf("team request is already %s", status)
+	}
+	return nil
+}
+
+// resolve answers a pending request. The answer is stored only while the request is still pending,
+// so of two concurrent answers the later one fails instead of overwriting the first.
+func (trs *TeamRequestService) resolve(req *entity.TeamRequest, status entity.TeamRequestStatus, actorID string) error {
+	req.Resolve(status, actorID, time.Now().UTC())
+	updated, err := trs.teamRequestRepository.UpdateIfStatus(req, entity.TeamRequestPending)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return errors.New(alreadyAnsweredError)
+	}
+	return nil
+}
+
+// expireStale stores the expired status of the pending requests past their expiry, unless they were answered
+// in the meantime. Reads go through CurrentStatus anyway, so a failed write is only logged.
+func (trs *TeamRequestService) expireStale(requests []*entity.TeamRequest, now time.Time) {
+	for _, r := range requests {
+		if !r.Expire(now) {
+			continue
+		}
+		if _, err := trs.teamRequestRepository.UpdateIfStatus(r, entity.TeamRequestPending); err != nil {
+			log.Printf("failed to expire team request %s: %v", r.Id, err)
+		}
+	}
+}
+
+// accept records the request as accepted before adding the user, so a failed write cannot leave a new member
+// whose request still looks pending and only one of two concurrent answers adds the user. When adding the user
+// fails, the request is pending again, unless the user is already a member of the team.
+func (trs *TeamRequestService) accept(req *entity.TeamRequest, reviewerID string) (*entity.User, *entity.Team, error) {
+	if err := trs.resolve(req, entity.TeamRequestAccepted, reviewerID); err != nil {
+		return nil, nil, err
 	}
 
 	user, team, err := trs.teamService.AddUserToTeam(req.UserID, req.TeamID)
+	if errors.Is(err, ErrAlreadyTeamMember) {
+		return nil, nil, err
+	}
 	if err != nil {
+		req.Resolve(entity.TeamRequestPending, "", time.Now().UTC())
+		if _, restoreErr := trs.teamRequestRepository.UpdateIfStatus(req, entity.TeamRequestAccepted); restoreErr != nil {
+			log.Printf("failed to restore team request %s to pending: %v", req.Id, restoreErr)
+		}
 		return nil, nil, err
 	}
 
-	_ = trs.teamRequestRepository.Delete(id)
+	// an accepted join request is news to the requester, an accepted invitation to the inviter
+	recipient, text := req.UserID, "Your request to join "+team.Name+" was accepted"
+	if req.IsInvite() {
+		recipient, text = req.InvitedBy, user.Username+" accepted your invitation to "+team.Name
+	}
+	trs.notifier.Notify(entity.Notification{
+		Type:       entity.NotificationTeamRequestAccepted,
+		ActorID:    reviewerID,
+		TeamID:     team.Id,
+		ResourceID: req.Id,
+		Text:       text,
+	}, recipient)
 	return user, team, nil
 }
 
-func (trs *TeamRequestService) RejectTeamRequest(id string) error {
-	req, err := trs.teamRequestRepository.GetById(id)
+// InviteToTeam invites the user named by ID, username or email to the team. The inviter must be allowed to manage
+// its members, and the invitation waits for the invited user to accept or decline it.
+func (trs *TeamRequestService) InviteToTeam(inviterID, teamID string, request *dto.TeamInviteRequest) (*entity.TeamRequest, error) {
+	if err := validator.ValidateTeamInviteRequest(request); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	team, err := trs.teamAuthorizer.Authorize(inviterID, teamID, PermissionManageMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	invitee, err := trs.findInvitee(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: user not found", ErrResourceNotFound)
+	}
+	if team.IsMember(invitee.ID) {
+		return nil, errors.New(alreadyMemberError)
+	}
+
+	now := time.Now().UTC()
+	existing, err := trs.teamRequestRepository.GetByUserId(invitee.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range existing {
+		if r.TeamID != teamID || !r.IsPending(now) {
+			continue
+		}
+		if r.IsInvite() {
+			return nil, errors.New("the user is already invited to this team")
+		}
+		return nil, errors.New("the user has already asked to join this team, accept their request instead")
+	}
+
+	id, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+	invite := entity.NewTeamInvite(id, invitee.ID, teamID, inviterID, now.Add(entity.TeamRequestTTL))
+	if err := trs.teamRequestRepository.Create(invite); err != nil {
+		return nil, err
+	}
+
+	trs.notifier.Notify(entity.Notification{
+		Type:       entity.NotificationTeamInvite,
+		ActorID:    inviterID,
+		TeamID:     teamID,
+		ResourceID: invite.Id,
+		Text:       "You were invited to join " + team.Name,
+	}, invitee.ID)
+	return invite, nil
+}
+
+func (trs *TeamRequestService) findInvitee(request *dto.TeamInviteRequest) (*entity.User, error) {
+	switch {
+	case request.UserID != "":
+		return trs.userRepository.GetByID(request.UserID)
+	case request.Username != "":
+		return trs.userRepository.GetByUsername(request.Username)
+	default:
+		return trs.userRepository.GetByEmail(request.Email)
+	}
+}
+
+// GetInvitations lists the invitations waiting for the user to answer
+func (trs *TeamRequestService) GetInvitations(userID string) ([]*entity.TeamRequest, error) {
+	requests, err := trs.teamRequestRepository.GetByUserId(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	trs.expireStale(requests, now)
+
+	invitations := make([]*entity.TeamRequest, 0)
+	for _, r := range requests {
+		if r.IsInvite() && r.IsPending(now) {
+			invitations = append(invitations, r)
+		}
+	}
+	return invitations, nil
+}
+
+// AcceptInvitation adds the invited user to the team
+func (trs *TeamRequestService) AcceptInvitation(id, userID string) (*entity.User, *entity.Team, error) {
+	invite, err := trs.getInvitation(id, userID)
 	if err != nil {
-		return errors.New("team request not found")
+		return nil, nil, err
+	}
+	if err := trs.checkPending(invite); err != nil {
+		return nil, nil, err
 	}
-	return trs.teamRequestRepository.Delete(req.Id)
+	return trs.accept(invite, userID)
 }
 
-func (trs *TeamRequestService) GetAll() ([]*entity.TeamRequest, error) {
-	return trs.teamRequestRepository.GetAll()
+func (trs *TeamRequestService) DeclineInvitation(id, userID string) error {
+	invite, err := trs.getInvitation(id, userID)
+	if err != nil {
+		return err
+	}
+	if err := trs.checkPending(invite); err != nil {
+		return err
+	}
+	return trs.resolve(invite, entity.TeamRequestRejected, userID)
 }
 
-func (trs *TeamRequestService) GetByUserId(userId string) ([]*entity.TeamRequest, error) {
-	return trs.teamRequestRepository.GetByUserId(userId)
+func (trs *TeamRequestService) getInvitation(id, userID string) (*entity.TeamRequest, error) {
+	invite, err := trs.teamRequestRepository.GetById(id)
+	if err != nil || !invite.IsInvite() {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, invitationNotFoundError)
+	}
+	if invite.UserID != userID {
+		return nil, fmt.Errorf("%w: %s", ErrForbidden, notInviteeError)
+	}
+	return invite, nil
+}
+
+// CreateInviteLink creates a link anyone can join the team through until it expires or runs out of uses.
+// The creator must be allowed to manage the members of the team.
+func (trs *TeamRequestService) CreateInviteLink(actorID, teamID string, request *dto.TeamInviteLinkRequest) (*entity.TeamInviteLink, error) {
+	if err := validator.ValidateTeamInviteLinkRequest(request); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if _, err := trs.teamAuthorizer.Authorize(actorID, teamID, PermissionManageMembers); err != nil {
+		return nil, err
+	}
+
+	hours := request.ExpiresInHours
+	if hours == 0 {
+		hours = defaultInviteLinkHours
+	}
+	id, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().UTC().Add(time.Duration(hours) * time.Hour)
+	link := entity.NewTeamInviteLink(id, teamID, actorID, expiresAt, request.MaxUses)
+	if err := trs.inviteLinkRepository.Create(link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// GetInviteLinks lists the invite links of the team, newest first, including the expired and used up ones
+func (trs *TeamRequestService) GetInviteLinks(actorID, teamID string) ([]*entity.TeamInviteLink, error) {
+	if _, err := trs.teamAuthorizer.Authorize(actorID, teamID, PermissionManageMembers); err != nil {
+		return nil, err
+	}
+	links, err := trs.inviteLinkRepository.GetByTeamID(teamID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].CreatedAt.After(links[j].CreatedAt)
+	})
+	return links, nil
+}
+
+func (trs *TeamRequestService) RevokeInviteLink(actorID, teamID, token string) error {
+	if _, err := trs.teamAuthorizer.Authorize(actorID, teamID, PermissionManageMembers); err != nil {
+		return err
+	}
+	link, err := trs.inviteLinkRepository.GetByID(token)
+	if err != nil || link.TeamID != teamID {
+		return fmt.Errorf("%w: %s", ErrResourceNotFound, persistence.TeamInviteLinkNotFound)
+	}
+	return trs.inviteLinkRepository.Delete(token)
+}
+
+// JoinByInviteLink adds the user to the team of the link and counts one use of it
+func (trs *TeamRequestService) JoinByInviteLink(userID, token string) (*entity.User, *entity.Team, error) {
+	link, err := trs.inviteLinkRepository.GetByID(token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrResourceNotFound, persistence.TeamInviteLinkNotFound)
+	}
+	team, err := trs.teamRepository.GetTeamById(link.TeamID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+	}
+	// checked before redeeming, so members opening the link again do not use it up
+	if team.IsMember(userID) {
+		return nil, nil, errors.New(alreadyMemberError)
+	}
+
+	if _, err := trs.inviteLinkRepository.Redeem(token, time.Now().UTC()); err != nil {
+		if err.Error() == persistence.TeamInviteLinkNotFound {
+			return nil, nil, fmt.Errorf("%w: %s", ErrResourceNotFound, persistence.TeamInviteLinkNotFound)
+		}
+		return nil, nil, err
+	}
+	user, team, err := trs.teamService.AddUserToTeam(userID, link.TeamID)
+	if err != nil {
+		// the user did not join, so the use goes back to the link
+		if releaseErr := trs.inviteLinkRepository.Release(token); releaseErr != nil {
+			log.Printf("failed to give back a use of invite link %s: %v", token, releaseErr)
+		}
+		return nil, nil, err
+	}
+	return user, team, nil
+}
+
+// InviteLinkURL is the frontend page that joins the team of an invite link
+func InviteLinkURL(token string) string {
+	return buildAppLink(inviteLinkPath, token)
 }
@@ -2,17 +2,34 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/search"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/validator"
 )
 
+const (
+	notPartOfTeamError = "the user is not a part of this team"
+	// membershipCommitAttempts bounds how often a commit is rebuilt after the members it read changed
+	membershipCommitAttempts = 3
+)
+
+// ErrAlreadyTeamMember is returned when adding a user who is already a member of the team
+var ErrAlreadyTeamMember = errors.New("user is already part of the team")
+
+// errMembershipChanged aborts a commit whose members or teams changed after they were read
+var errMembershipChanged = errors.New("the membership changed while it was being updated")
+
 type TeamService struct {
 	userRepository UserRepositoryInterface
 	teamRepository TeamRepositoryInterface
+	teamAuthorizer TeamAuthorizerInterface
+	unitOfWork     persistence.UnitOfWorkFactory
+	searchIndex    search.Writer
 }
 
 type TeamRepositoryInterface interface {
@@ -29,6 +46,9 @@ func NewTeamService() *TeamService {
 	return &TeamService{
 		userRepository: persistence.NewUserRepository(),
 		teamRepository: persistence.NewTeamRepository(),
+		teamAuthorizer: NewTeamAuthorizer(),
+		unitOfWork:     persistence.NewUnitOfWorkFactory(),
+		searchIndex:    search.Default(),
 	}
 }
 
@@ -36,103 +56,124 @@ func NewTeamServiceWithRepo(userRepo UserRepositoryInterface, teamRepo TeamRepos
 	return &TeamService{
 		userRepository: userRepo,
 		teamRepository: teamRepo,
+		teamAuthorizer: NewTeamAuthorizerWithRepo(teamRepo),
+		unitOfWork:     persistence.NewRepositoryUnitOfWorkFactory(userRepo, teamRepo),
+		searchIndex:    search.NewIndex(),
 	}
 }
 
+func (ts *TeamService) SetUnitOfWork(unitOfWork persistence.UnitOfWorkFactory) {
+	ts.unitOfWork = unitOfWork
+}
+
+func (ts *TeamService) SetSearchIndex(searchIndex search.Writer) {
+	ts.searchIndex = searchIndex
+}
+
 func (ts *TeamService) CreateTeam(request *dto.TeamRequest) (*entity.Team, error) {
 	if err := validator.ValidateTeamRequest(request); err != nil {
 		return nil, err
 	}
-	_, err := ts.userRepository.GetByID(request.UserId)
+	user, err := ts.userRepository.GetByID(request.UserId)
 	if err != nil {
 		return nil, err
 	}
+	if !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
 	id, err := generateID()
 	if err != nil {
 		return nil, err
 	}
-	team := *entity.NewTeam(
+	team := entity.NewTeam(
 		id,
 		request.Name,
 		request.Description,
 		request.IsPublic,
-		nil,
+		[]string{user.ID},
 		request.TeamTopic,
 	)
-	if err := ts.teamRepository.Create(&team); err != nil {
-		return nil, err
-	}
-	_, _, err = ts.AddUserToTeam(request.UserId, id)
-	if err != nil {
+	team.SetRole(user.ID, entity.TeamRoleOwner)
+
+	// the team and its owner's membership are written together
+	uow := ts.unitOfWork.Begin()
+	uow.SaveTeam(team)
+	uow.UpdateUser(user.ID, func(user *entity.User) error {
+		addTeamID(user, id)
+		return nil
+	})
+	if err := uow.Commit(); err != nil {
 		return nil, err
 	}
-	return ts.teamRepository.GetTeamById(id)
+	ts.searchIndex.Put(search.TeamDocument(team))
+	return team, nil
 }
 
+// AddUserToTeam adds the user to the team's members and the team to the user's teams. Both are changed as
+// stored when the change is committed, so members who join or leave at the same time are not overwritten.
 func (ts *TeamService) AddUserToTeam(idUser string, idTeam string) (*entity.User, *entity.Team, error) {
-	user, err := ts.userRepository.GetByID(idUser)
-	if err != nil {
-		return nil, nil, err
-	}
-	team, err := ts.teamRepository.GetTeamById(idTeam)
-	if err != nil {
+	if _, err := ts.userRepository.GetByID(idUser); err != nil {
 		return nil, nil, err
 	}
-	for _, u := range team.UsersIds {
-		if idUser == u {
-			return nil, nil, errors.New("user is already part of the team")
-		}
-	}
-	team.UsersIds = append(team.UsersIds, idUser)
-	if user.TeamsIds == nil {
-		user.TeamsIds = &[]string{}
-	}
-	*user.TeamsIds = append(*user.TeamsIds, idTeam)
 
-	if err := ts.userRepository.Update(user); err != nil {
-		return nil, nil, err
-	}
-	if err := ts.teamRepository.Update(team); err != nil {
+	var user *entity.User
+	var team *entity.Team
+	uow := ts.unitOfWork.Begin()
+	uow.UpdateTeam(idTeam, func(stored *entity.Team) error {
+		if stored.IsMember(idUser) {
+			return ErrAlreadyTeamMember
+		}
+		stored.UsersIds = append(stored.UsersIds, idUser)
+		if _, ok := stored.Roles[idUser]; !ok {
+			stored.SetRole(idUser, entity.TeamRoleMember)
+		}
+		team = stored
+		return nil
+	})
+	uow.UpdateUser(idUser, func(stored *entity.User) error {
+		addTeamID(stored, idTeam)
+		user = stored
+		return nil
+	})
+	if err := uow.Commit(); err != nil {
 		return nil, nil, err
 	}
 	return user, team, nil
 }
 
+// DeleteUserFromTeam removes the user from the team's members and the team from the user's teams,
+// changing both as stored when the change is committed
 func (ts *TeamService) DeleteUserFromTeam(idUser string, idTeam string) (*entity.User, *entity.Team, error) {
-	user, err := ts.userRepository.GetByID(idUser)
-	if err != nil {
+	if _, err := ts.userRepository.GetByID(idUser); err != nil {
 		return nil, nil, err
 	}
-	team, err := ts.teamRepository.GetTeamById(idTeam)
-	if err != nil {
-		return nil, nil, err
-	}
-	var ok bool = false
-	for _, u := range team.UsersIds {
-		if idUser == u {
-			ok = true
-			break
-		}
-	}
-	if !ok {
-		return nil, nil, errors.New("the user is not a part of this team")
-	}
-	usersIds := removeString(team.UsersIds, user.ID)
-	teamsIds := removeString(*user.TeamsIds, team.Id)
-
-	team.UsersIds = usersIds
-	user.TeamsIds = &teamsIds
 
-	if err := ts.userRepository.Update(user); err != nil {
-		return nil, nil, err
-	}
-	if err := ts.teamRepository.Update(team); err != nil {
+	var user *entity.User
+	var team *entity.Team
+	uow := ts.unitOfWork.Begin()
+	uow.UpdateTeam(idTeam, func(stored *entity.Team) error {
+		if !stored.IsMember(idUser) {
+			return errors.New(notPartOfTeamError)
+		}
+		stored.UsersIds = removeString(stored.UsersIds, idUser)
+		delete(stored.Roles, idUser)
+		team = stored
+		return nil
+	})
+	uow.UpdateUser(idUser, func(stored *entity.User) error {
+		teamsIds := removeString(teamIDsOf(stored), idTeam)
+		stored.TeamsIds = &teamsIds
+		user = stored
+		return nil
+	})
+	if err := uow.Commit(); err != nil {
 		return nil, nil, err
 	}
 	return user, team, nil
 }
 
-func (ts *TeamService) GetUsersByTeam(idTeam string) ([]*dto.UserResponse, error) {
+// GetUsersByTeam lists the members of a team to one of its members
+func (ts *TeamService) GetUsersByTeam(actorID, idTeam string) ([]*dto.UserResponse, error) {
 	if strings.TrimSpace(idTeam) == "" {
 		return nil, errors.New("team ID is required")
 	}
@@ -141,6 +182,9 @@ func (ts *TeamService) GetUsersByTeam(idTeam string) ([]*dto.UserResponse, error
 	if err != nil {
 		return nil, errors.New("team not found")
 	}
+	if err := CheckTeamMember(team, actorID); err != nil {
+		return nil, err
+	}
 
 	var users []*dto.UserResponse
 	for _, userId := range team.UsersIds {
@@ -170,30 +214,270 @@ func (ts *TeamService) GetAll() ([]*entity.Team, error) {
 	return ts.teamRepository.GetAll()
 }
 
+// GetVisibleTeam returns a team the user may see. Private teams are not found for non-members.
+func (ts *TeamService) GetVisibleTeam(actorID, id string) (*entity.Team, error) {
+	team, err := ts.teamRepository.GetTeamById(id)
+	if err != nil || !team.VisibleTo(actorID) {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+	}
+	return team, nil
+}
+
+// GetVisibleTeams returns the public teams and the private teams the user is a member of
+func (ts *TeamService) GetVisibleTeams(actorID string) ([]*entity.Team, error) {
+	teams, err := ts.teamRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return visibleTeams(teams, actorID), nil
+}
+
+func (ts *TeamService) GetVisibleTeamsByName(actorID, name string) ([]*entity.Team, error) {
+	teams, err := ts.teamRepository.GetTeamsByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return visibleTeams(teams, actorID), nil
+}
+
+// GetXVisibleTeamsByPrefix returns up to x teams the user may see whose name starts with prefix.
+// Hidden teams count against the repository limit, so it asks for more until it has x or runs out.
+func (ts *TeamService) GetXVisibleTeamsByPrefix(actorID, prefix string, x int) ([]*entity.Team, error) {
+	if x <= 0 {
+		return []*entity.Team{}, nil
+	}
+	for limit := x; ; limit *= 2 {
+		teams, err := ts.teamRepository.GetXTeamsByPrefix(prefix, limit)
+		if err != nil {
+			return nil, err
+		}
+		visible := visibleTeams(teams, actorID)
+		if len(visible) >= x {
+			return visible[:x], nil
+		}
+		if len(teams) < limit {
+			return visible, nil
+		}
+	}
+}
+
+func visibleTeams(teams []*entity.Team, userID string) []*entity.Team {
+	visible := make([]*entity.Team, 0, len(teams))
+	for _, team := range teams {
+		if team.VisibleTo(userID) {
+			visible = append(visible, team)
+		}
+	}
+	return visible
+}
+
 func (ts *TeamService) Update(team *entity.Team) error {
-	return ts.teamRepository.Update(team)
+	if err := ts.teamRepository.Update(team); err != nil {
+		return err
+	}
+	ts.searchIndex.Put(search.TeamDocument(team))
+	return nil
 }
 
 // also deletes all references to the team in the Users' saved teams
 func (ts *TeamService) Delete(id string) error {
-	team, err := ts.teamRepository.GetTeamById(id)
+	err := commitMembership(ts.unitOfWork, func(uow persistence.UnitOfWork) error {
+		team, err := ts.teamRepository.GetTeamById(id)
+		if err != nil {
+			return err
+		}
+		// a member who joined after the team was read would keep a reference to it
+		uow.UpdateTeam(id, func(stored *entity.Team) error {
+			if !sameIDs(stored.UsersIds, team.UsersIds) {
+				return errMembershipChanged
+			}
+			return nil
+		})
+		for _, userID := range team.UsersIds {
+			uow.UpdateUser(userID, func(user *entity.User) error {
+				if user.TeamsIds != nil {
+					updatedTeams := removeString(*user.TeamsIds, id)
+					user.TeamsIds = &updatedTeams
+				}
+				return nil
+			})
+		}
+		uow.DeleteTeam(id)
+		return nil
+	})
 	if err != nil {
 		return err
 	}
-	for _, user := range team.UsersIds {
-		user, err := ts.userRepository.GetByID(user)
-		if err != nil {
+	ts.searchIndex.Remove(search.KindTeam, id)
+	return nil
+}
+
+// AddMember adds a user to the team on behalf of an admin or the owner. Anyone may join a
+// public team on their own; private teams are only joined through an approved request or an invitation.
+func (ts *TeamService) AddMember(actorID, idUser, idTeam string) (*entity.User, *entity.Team, error) {
+	if actorID == idUser {
+		team, err := ts.teamRepository.GetTeamById(idTeam)
+		if err != nil || !team.VisibleTo(actorID) {
+			return nil, nil, fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+		}
+		if !team.IsPublic {
+			return nil, nil, fmt.Errorf("%w: %s", ErrForbidden, privateTeamJoinError)
+		}
+		return ts.AddUserToTeam(idUser, idTeam)
+	}
+	if _, err := ts.teamAuthorizer.Authorize(actorID, idTeam, PermissionManageMembers); err != nil {
+		return nil, nil, err
+	}
+	return ts.AddUserToTeam(idUser, idTeam)
+}
+
+// RemoveMember removes a user from the team. Members may always leave on their own,
+// admins may remove members, and only the owner may remove admins. The owner cannot
+// be removed until ownership has been transferred.
+func (ts *TeamService) RemoveMember(actorID, idUser, idTeam string) (*entity.User, *entity.Team, error) {
+	team, err := ts.teamRepository.GetTeamById(idTeam)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrResourceNotFound, teamNotFoundError)
+	}
+
+	targetRole := team.RoleOf(idUser)
+	if targetRole == entity.TeamRoleOwner {
+		return nil, nil, fmt.Errorf("%w: %s", ErrForbidden, ownerCannotLeaveError)
+	}
+
+	if actorID != idUser {
+		permission := PermissionManageMembers
+		if targetRole == entity.TeamRoleAdmin {
+			permission = PermissionManageRoles
+		}
+		if err := CheckTeamPermission(team, actorID, permission); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return ts.DeleteUserFromTeam(idUser, idTeam)
+}
+
+// UpdateTeam changes the editable details of a team. Membership and roles are never
+// taken from the request; they change only through the member and role operations.
+func (ts *TeamService) UpdateTeam(actorID, idTeam string, changes *dto.UpdateTeamRequest) (*entity.Team, error) {
+	team, err := ts.changeTeam(actorID, idTeam, PermissionUpdateTeam, func(team *entity.Team) error {
+		if changes.Name != nil && *changes.Name != "" {
+			team.Name = *changes.Name
+		}
+		if changes.Description != nil {
+			team.Description = *changes.Description
+		}
+		if changes.IsPublic != nil {
+			team.IsPublic = *changes.IsPublic
+		}
+		if changes.TeamTopic != nil && *changes.TeamTopic != "" {
+			team.TeamTopic = *changes.TeamTopic
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ts.searchIndex.Put(search.TeamDocument(team))
+	return team, nil
+}
+
+// DeleteTeam deletes a team on behalf of its owner
+func (ts *TeamService) DeleteTeam(actorID, idTeam string) error {
+	if _, err := ts.teamAuthorizer.Authorize(actorID, idTeam, PermissionDeleteTeam); err != nil {
+		return err
+	}
+	return ts.Delete(idTeam)
+}
+
+// SetMemberRole promotes a member to admin or demotes an admin to member
+func (ts *TeamService) SetMemberRole(actorID, idTeam, idUser string, role entity.TeamRole) (*entity.Team, error) {
+	if !role.IsValid() {
+		return nil, errors.New(invalidTeamRoleError)
+	}
+	if role == entity.TeamRoleOwner {
+		return nil, errors.New(ownerRoleViaTransferErr)
+	}
+
+	return ts.changeTeam(actorID, idTeam, PermissionManageRoles, func(team *entity.Team) error {
+		if !team.IsMember(idUser) {
+			return errors.New(notPartOfTeamError)
+		}
+		if team.RoleOf(idUser) == entity.TeamRoleOwner {
+			return fmt.Errorf("%w: %s", ErrForbidden, ownerRoleViaTransferErr)
+		}
+		team.SetRole(idUser, role)
+		return nil
+	})
+}
+
+// TransferOwnership hands the team over to another member; the previous owner stays on as admin
+func (ts *TeamService) TransferOwnership(actorID, idTeam, newOwnerID string) (*entity.Team, error) {
+	if actorID == newOwnerID {
+		return ts.teamAuthorizer.Authorize(actorID, idTeam, PermissionTransferOwnership)
+	}
+	return ts.changeTeam(actorID, idTeam, PermissionTransferOwnership, func(team *entity.Team) error {
+		if !team.IsMember(newOwnerID) {
+			return errors.New("the new owner must be a member of the team")
+		}
+		team.SetRole(actorID, entity.TeamRoleAdmin)
+		team.SetRole(newOwnerID, entity.TeamRoleOwner)
+		return nil
+	})
+}
+
+// changeTeam applies change to the team as stored when it is committed, so changes other requests made
+// since it was read are kept. The actor's permission is checked again on the stored team.
+func (ts *TeamService) changeTeam(actorID, idTeam string, permission TeamPermission, change func(team *entity.Team) error) (*entity.Team, error) {
+	if _, err := ts.teamAuthorizer.Authorize(actorID, idTeam, permission); err != nil {
+		return nil, err
+	}
+
+	var team *entity.Team
+	uow := ts.unitOfWork.Begin()
+	uow.UpdateTeam(idTeam, func(stored *entity.Team) error {
+		if err := CheckTeamPermission(stored, actorID, permission); err != nil {
 			return err
 		}
-		if user.TeamsIds != nil {
-			updatedTeams := removeString(*user.TeamsIds, team.Id)
-			user.TeamsIds = &updatedTeams
+		if err := change(stored); err != nil {
+			return err
 		}
-		if err := ts.userRepository.Update(user); err != nil {
+		team = stored
+		return nil
+	})
+	if err := uow.Commit(); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// commitMembership builds a unit of work and commits it, building it again from fresh reads when a check
+// staged by build finds the members or teams it read changed before the commit
+func commitMembership(factory persistence.UnitOfWorkFactory, build func(uow persistence.UnitOfWork) error) error {
+	for attempt := 1; ; attempt++ {
+		uow := factory.Begin()
+		if err := build(uow); err != nil {
 			return err
 		}
+		err := uow.Commit()
+		if !errors.Is(err, errMembershipChanged) || attempt == membershipCommitAttempts {
+			return err
+		}
+	}
+}
+
+// addTeamID adds the team to the user's teams unless it is there already
+func addTeamID(user *entity.User, teamID string) {
+	if user.TeamsIds == nil {
+		user.TeamsIds = &[]string{}
+	}
+	for _, id := range *user.TeamsIds {
+		if id == teamID {
+			return
+		}
 	}
-	return ts.teamRepository.Delete(id)
+	*user.TeamsIds = append(*user.TeamsIds, teamID)
 }
 
 func removeString(slice []string, value string) []string {
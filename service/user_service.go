@@ -5,15 +5,13 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"time"
+	"log"
 
-	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/validator"
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -24,24 +22,44 @@ const (
 )
 
 type UserService struct {
-	userRepo UserRepositoryInterface
-	teamRepo TeamRepositoryInterface
+	userRepo            UserRepositoryInterface
+	teamRepo            TeamRepositoryInterface
+	sessionService      SessionServiceInterface
+	verificationService VerificationServiceInterface
+	unitOfWork          persistence.UnitOfWorkFactory
 }
 
 func NewUserService() *UserService {
 	return &UserService{
-		userRepo: persistence.NewUserRepository(),
-		teamRepo: persistence.NewTeamRepository(),
+		userRepo:            persistence.NewUserRepository(),
+		teamRepo:            persistence.NewTeamRepository(),
+		sessionService:      NewSessionService(),
+		verificationService: NewVerificationService(),
+		unitOfWork:          persistence.NewUnitOfWorkFactory(),
 	}
 }
 
-func NewUserServiceWithRepo(userRepo interface{}, teamRepo interface{}) *UserService {
+func NewUserServiceWithRepo(userRepo interface{}, teamRepo interface{}, sessionRepo persistence.SessionRepositoryInterface) *UserService {
 	return &UserService{
-		userRepo: userRepo.(UserRepositoryInterface),
-		teamRepo: teamRepo.(TeamRepositoryInterface),
+		userRepo:       userRepo.(UserRepositoryInterface),
+		teamRepo:       teamRepo.(TeamRepositoryInterface),
+		sessionService: NewSessionServiceWithRepo(sessionRepo, userRepo.(UserRepositoryInterface)),
+		unitOfWork:     persistence.NewRepositoryUnitOfWorkFactory(userRepo.(UserRepositoryInterface), teamRepo.(TeamRepositoryInterface)),
 	}
 }
 
+func (us *UserService) SetSessionService(sessionService SessionServiceInterface) {
+	us.sessionService = sessionService
+}
+
+func (us *UserService) SetUnitOfWork(unitOfWork persistence.UnitOfWorkFactory) {
+	us.unitOfWork = unitOfWork
+}
+
+func (us *UserService) SetVerificationService(verificationService VerificationServiceInterface) {
+	us.verificationService = verificationService
+}
+
 type UserRepositoryInterface interface {
 	Create(user *entity.User) error
 	GetByID(id string) (*entity.User, error)
@@ -50,6 +68,7 @@ type UserRepositoryInterface interface {
 	Update(user *entity.User) error
 	Delete(id string) error
 	GetAll() ([]*entity.User, error)
+	GetByIDs(ids []string) ([]*entity.User, error)
 }
 
 func (us *UserService) SignUp(request *dto.SignUpUserRequest) (*dto.SignUpUserResponse, error) {
@@ -89,6 +108,9 @@ func (us *UserService) SignUp(request *dto.SignUpUserRequest) (*dto.SignUpUserRe
 		return nil, err
 	}
 
+	// the account exists at this point, a failed email can be retried through the resend endpoint
+	us.sendEmailVerification(user)
+
 	return dto.NewSignUpUserResponse(user.FirstName, user.LastName, user.Username), nil
 }
 
@@ -126,8 +148,11 @@ func (us *UserService) UpdateUserProfile(userID string, req *dto.UserUpdateReque
 	if req.Username != "" {
 		user.Username = req.Username
 	}
-	if req.Email != "" {
+	emailChanged := req.Email != "" && req.Email != user.Email
+	if emailChanged {
 		user.Email = req.Email
+		user.EmailVerified = false
+		user.EmailVerificationRequired = true
 	}
 	if req.TopicsOfInterest != nil {
 		user.TopicsOfInterest = req.TopicsOfInterest
@@ -137,11 +162,17 @@ func (us *UserService) UpdateUserProfile(userID string, req *dto.UserUpdateReque
 		return nil, err
 	}
 
+	if emailChanged {
+		us.invalidateTokens(user.ID)
+		us.sendEmailVerification(user)
+	}
+
 	return dto.NewUserUpdateResponseDTO(user), nil
 }
 
-// UpdateUserPassword updates the user's password (requires old password verification)
-func (us *UserService) UpdateUserPassword(userID string, req *dto.UserPasswordRequestDTO) error {
+// UpdateUserPassword updates the user's password (requires old password verification) and logs the user out
+// of every session but sessionID, the one the change was made from
+func (us *UserService) UpdateUserPassword(userID, sessionID string, req *dto.UserPasswordRequestDTO) error {
 	if userID != req.ID {
 		return fmt.Errorf("user id mismatch")
 	}
@@ -164,29 +195,53 @@ func (us *UserService) UpdateUserPassword(userID string, req *dto.UserPasswordRe
 	}
 
 	user.Password = string(hashedPassword)
-	return us.userRepo.Update(user)
-}
-
-// also deletes all references to the user in the Teams' saved users
-func (us *UserService) DeleteUser(id string) error {
-	user, err := us.userRepo.GetByID(id)
-	if err != nil {
+	if err := us.userRepo.Update(user); err != nil {
 		return err
 	}
-	if user.TeamsIds == nil {
-		return us.userRepo.Delete(id)
+
+	if err := us.sessionService.LogoutOthers(userID, sessionID); err != nil {
+		log.Printf("failed to revoke sessions after password change for user %s: %v", userID, err)
 	}
-	for _, teamId := range *user.TeamsIds {
-		team, err := us.teamRepo.GetTeamById(teamId)
+	return nil
+}
+
+// also deletes all references to the user in the Teams' saved users, and logs the user out everywhere.
+// The owner of a team cannot be deleted, since the team would be left without one.
+func (us *UserService) DeleteUser(id string) error {
+	err := commitMembership(us.unitOfWork, func(uow persistence.UnitOfWork) error {
+		user, err := us.userRepo.GetByID(id)
 		if err != nil {
 			return err
 		}
-		team.UsersIds = removeString(team.UsersIds, user.ID)
-		if err := us.teamRepo.Update(team); err != nil {
-			return err
+		teamIDs := teamIDsOf(user)
+		for _, teamId := range teamIDs {
+			uow.UpdateTeam(teamId, func(team *entity.Team) error {
+				if team.RoleOf(id) == entity.TeamRoleOwner {
+					return fmt.Errorf("%w: %s", ErrForbidden, ownerCannotDeleteError)
+				}
+				team.UsersIds = removeString(team.UsersIds, id)
+				delete(team.Roles, id)
+				return nil
+			})
 		}
+		// a team joined after the user was read would keep them as a member
+		uow.UpdateUser(id, func(stored *entity.User) error {
+			if !sameIDs(teamIDsOf(stored), teamIDs) {
+				return errMembershipChanged
+			}
+			return nil
+		})
+		uow.DeleteUser(id)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-	return us.userRepo.Delete(id)
+
+	if err := us.sessionService.LogoutAll(id); err != nil {
+		log.Printf("failed to revoke sessions of deleted user %s: %v", id, err)
+	}
+	return nil
 }
 
 func (us *UserService) GetAllUsers() ([]*entity.User, error) {
@@ -240,6 +295,25 @@ func (us *UserService) UpdateUserStatistics(id string, timeSpentOnApp int64, tim
 	return user, nil
 }
 
+func (us *UserService) sendEmailVerification(user *entity.User) {
+	if us.verificationService == nil {
+		return
+	}
+	if err := us.verificationService.SendEmailVerification(user); err != nil {
+		log.Printf("failed to send verification email to user %s: %v", user.ID, err)
+	}
+}
+
+// invalidateTokens drops the verification and reset links mailed to the user's previous email
+func (us *UserService) invalidateTokens(userID string) {
+	if us.verificationService == nil {
+		return
+	}
+	if err := us.verificationService.InvalidateTokens(userID); err != nil {
+		log.Printf("failed to invalidate the tokens of user %s: %v", userID, err)
+	}
+}
+
 func generateID() (string, error) {
 	bytes := make([]byte, 16)
 	if _, err := rand.Read(bytes); err != nil {
@@ -253,8 +327,6 @@ var (
 	ErrInvalidCredentials = errors.New("invalid email or password")
 )
 
-const jwtExpiresHours = 24
-
 // Login performs authentication by email or username and returns a LoginResponse
 func (us *UserService) Login(request *dto.LoginRequest) (*dto.LoginResponse, error) {
 	if request == nil {
@@ -279,23 +351,5 @@ func (us *UserService) Login(request *dto.LoginRequest) (*dto.LoginResponse, err
 		return nil, ErrInvalidCredentials
 	}
 
-	secret := config.GetJWTSecret()
-	expiration := time.Now().Add(time.Hour * jwtExpiresHours)
-
-	claims := jwt.MapClaims{
-		"sub":      user.ID,
-		"username": user.Username,
-		"email":    user.Email,
-		"exp":      expiration.Unix(),
-		"iat":      time.Now().Unix(),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := token.SignedString([]byte(secret))
-	if err != nil {
-		return nil, err
-	}
-
-	resp := dto.NewLoginResponse(signed, "24h", user)
-	return resp, nil
+	return us.sessionService.StartSession(user)
 }
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/mailer"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/validator"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	passwordResetTTL     = time.Hour
+
+	verifyEmailSubject   = "Confirm your StudyWithMe email"
+	resetPasswordSubject = "Reset your StudyWithMe password"
+)
+
+// Errors returned by verification service
+var (
+	ErrInvalidUserToken     = errors.New("invalid or expired token")
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	ErrEmailNotVerified     = errors.New("email is not verified")
+)
+
+type VerificationServiceInterface interface {
+	SendEmailVerification(user *entity.User) error
+	ResendEmailVerification(userID string) error
+	VerifyEmail(token string) error
+	// InvalidateTokens makes every link mailed to the user so far unusable, such as when their email changes
+	InvalidateTokens(userID string) error
+	RequestPasswordReset(email string) error
+	ResetPassword(request *dto.ResetPasswordRequest) error
+}
+
+type VerificationService struct {
+	tokenRepo      persistence.UserTokenRepositoryInterface
+	userRepo       UserRepositoryInterface
+	sessionService SessionServiceInterface
+	mailer         mailer.Mailer
+}
+
+func NewVerificationService() *VerificationService {
+	return &VerificationService{
+		tokenRepo:      persistence.NewUserTokenRepository(),
+		userRepo:       persistence.NewUserRepository(),
+		sessionService: NewSessionService(),
+		mailer:         mailer.NewMailer(),
+	}
+}
+
+func NewVerificationServiceWithRepo(tokenRepo persistence.UserTokenRepositoryInterface, userRepo UserRepositoryInterface, sessionService SessionServiceInterface, m mailer.Mailer) *VerificationService {
+	return &VerificationService{
+		tokenRepo:      tokenRepo,
+		userRepo:       userRepo,
+		sessionService: sessionService,
+		mailer:         m,
+	}
+}
+
+// SendEmailVerification mails a fresh verification link to the user's current email
+func (vs *VerificationService) SendEmailVerification(user *entity.User) error {
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := vs.issueToken(user, entity.PurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Hi %s,\n\nConfirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+		user.FirstName, buildAppLink("/verify-email", token), emailVerificationTTL,
+	)
+	return vs.mailer.Send(user.Email, verifyEmailSubject, body)
+}
+
+func (vs *VerificationService) ResendEmailVerification(userID string) error {
+	user, err := vs.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	return vs.SendEmailVerification(user)
+}
+
+// VerifyEmail consumes an email verification token and marks the user as verified,
+// as long as the token was mailed to the email the user has now
+func (vs *VerificationService) VerifyEmail(token string) error {
+	userToken, err := vs.consumeToken(token, entity.PurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	user, err := vs.userRepo.GetByID(userToken.UserID)
+	if err != nil || !userToken.IsFor(user) {
+		return ErrInvalidUserToken
+	}
+	user.EmailVerified = true
+	return vs.userRepo.Update(user)
+}
+
+// RequestPasswordReset mails a reset link if an account with that email exists.
+// It reports success either way so the endpoint cannot be used to probe for accounts.
+func (vs *VerificationService) RequestPasswordReset(email string) error {
+	if !validator.IsValidEmail(email) {
+		return fmt.Errorf("invalid email format")
+	}
+
+	user, err := vs.userRepo.GetByEmail(email)
+	if err != nil || user == nil {
+		return nil
+	}
+
+	token, err := vs.issueToken(user, entity.PurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Hi %s,\n\nSomeone asked to reset the password of your account. If it was you, open the link below:\n\n%s\n\nThe link expires in %s. If you did not ask for this, you can ignore this email.\n",
+		user.FirstName, buildAppLink("/reset-password", token), passwordResetTTL,
+	)
+	return vs.mailer.Send(user.Email, resetPasswordSubject, body)
+}
+
+// ResetPassword consumes a password reset token, sets the new password and logs the user out everywhere.
+// Receiving the token by email also proves ownership of the address, so the email is marked verified;
+// tokens mailed to an address the user no longer has are rejected.
+func (vs *VerificationService) ResetPassword(request *dto.ResetPasswordRequest) error {
+	if err := validator.ValidateResetPasswordRequest(request); err != nil {
+		return err
+	}
+
+	userToken, err := vs.consumeToken(request.Token, entity.PurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	user, err := vs.userRepo.GetByID(userToken.UserID)
+	if err != nil || !userToken.IsFor(user) {
+		return ErrInvalidUserToken
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.Password = string(hashedPassword)
+	user.EmailVerified = true
+	if err := vs.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	if err := vs.sessionService.LogoutAll(user.ID); err != nil {
+		log.Printf("failed to revoke sessions after password reset for user %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+func (vs *VerificationService) InvalidateTokens(userID string) error {
+	return vs.tokenRepo.InvalidateByUserID(userID)
+}
+
+// issueToken creates a token for the user's current email
+func (vs *VerificationService) issueToken(user *entity.User, purpose entity.TokenPurpose, ttl time.Duration) (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(bytes)
+
+	userToken := entity.NewUserToken(hashToken(token), user.ID, user.Email, purpose, time.Now().UTC().Add(ttl))
+	if err := vs.tokenRepo.Create(userToken); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeToken looks up a token by its hash and marks it used, so it cannot be replayed. Marking it is
+// conditional, so of two concurrent requests with the same token only one gets it.
+func (vs *VerificationService) consumeToken(token string, purpose entity.TokenPurpose) (*entity.UserToken, error) {
+	if token == "" {
+		return nil, ErrInvalidUserToken
+	}
+
+	userToken, err := vs.tokenRepo.GetByID(hashToken(token))
+	if err != nil || !userToken.IsUsable(purpose) {
+		return nil, ErrInvalidUserToken
+	}
+
+	marked, err := vs.tokenRepo.MarkUsed(userToken.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		return nil, ErrInvalidUserToken
+	}
+	userToken.Used = true
+	return userToken, nil
+}
+
+func buildAppLink(path, token string) string {
+	return config.GetAppURL() + path + "?token=" + url.QueryEscape(token)
+}
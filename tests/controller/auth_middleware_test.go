@@ -0,0 +1,55 @@
+package controller_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestJWTAuthMiddleware_ConcurrentRequestsShareValidator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "middleware-test-secret")
+	validator := new(tests.MockSessionService)
+	validator.On("ValidateAccessToken", mock.MatchedBy(func(claims jwt.MapClaims) bool { return claims["sub"] == authenticatedUser })).Return(nil)
+	validator.On("ValidateAccessToken", mock.Anything).Return(errors.New("session revoked"))
+	controller.SetSessionValidator(validator)
+	defer controller.SetSessionValidator(nil)
+
+	r := gin.New()
+	r.GET("/me", controller.JWTAuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	sign := func(sub string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": sub}).SignedString([]byte("middleware-test-secret"))
+		assert.NoError(t, err)
+		return token
+	}
+	valid, revoked := sign(authenticatedUser), sign(impersonatedUser)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+valid)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+		}()
+	}
+	wg.Wait()
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+revoked)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
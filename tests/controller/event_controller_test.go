@@ -10,10 +10,10 @@ import (
 
 	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
-	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,6 +39,7 @@ func TestEventController_NewEvent_Success(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Set("userClaims", jwt.MapClaims{"sub": tests.TestUserID})
 
 	jsonData, _ := json.Marshal(request)
 	c.Request, _ = http.NewRequest(tests.HTTPMethodPOST, tests.PathEvents, bytes.NewBuffer(jsonData))
@@ -91,6 +92,7 @@ func TestEventController_NewEvent_ServiceError(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Set("userClaims", jwt.MapClaims{"sub": tests.TestUserID})
 
 	jsonData, _ := json.Marshal(request)
 	c.Request, _ = http.NewRequest(tests.HTTPMethodPOST, tests.PathEvents, bytes.NewBuffer(jsonData))
@@ -121,10 +123,9 @@ func TestEventController_GetEvent_Success(t *testing.T) {
 		TeamID:      tests.TestTeamID,
 	}
 
-	mockService.On("GetEventById", tests.TestEventID).Return(expectedEvent, nil)
+	mockService.On("GetEventById", authenticatedUser, tests.TestEventID).Return(expectedEvent, nil)
 
-	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c, w := newAuthenticatedContext(http.MethodGet, tests.PathEvents+"/"+tests.TestEventID, nil)
 	c.Params = []gin.Param{{Key: "id", Value: tests.TestEventID}}
 
 	ec.GetEvent(c)
@@ -146,15 +147,15 @@ func TestEventController_GetEvent_NotFound(t *testing.T) {
 	ec := controller.NewEventController()
 	ec.SetEventService(mockService)
 
-	mockService.On("GetEventById", "invalid-id").Return(nil, fmt.Errorf(tests.ErrEventNotFound))
+	mockService.On("GetEventById", authenticatedUser, "invalid-id").
+		Return(nil, fmt.Errorf("%w: %s", service.ErrResourceNotFound, tests.ErrEventNotFound))
 
-	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c, w := newAuthenticatedContext(http.MethodGet, tests.PathEvents+"/invalid-id", nil)
 	c.Params = []gin.Param{{Key: "id", Value: "invalid-id"}}
 
 	ec.GetEvent(c)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusNotFound, w.Code)
 
 	var response map[string]string
 	json.Unmarshal(w.Body.Bytes(), &response)
@@ -164,26 +165,18 @@ func TestEventController_GetEvent_NotFound(t *testing.T) {
 }
 
 func TestEventController_GetEvents_Success(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-
 	mockEventService := new(tests.MockEventService)
-	mockTeamService := new(tests.MockTeamService)
 	ec := controller.NewEventController()
 	ec.SetEventService(mockEventService)
-	ec.SetTeamService(mockTeamService)
 
 	events := []*dto.EventDTO{
 		{ID: tests.TestEventID, Name: tests.TestEventName, TeamID: tests.TestTeamID},
 		{ID: "event456", Name: "Another Event", TeamID: tests.TestTeamID},
 	}
 
-	team := &entity.Team{Id: tests.TestTeamID}
-	mockTeamService.On("GetTeamById", tests.TestTeamID).Return(team, nil)
-	mockEventService.On("GetEventsByTeamId", tests.TestTeamID).Return(events, nil)
+	mockEventService.On("GetEventsByTeamId", authenticatedUser, tests.TestTeamID).Return(events, nil)
 
-	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest(http.MethodGet, tests.PathEvents+"?teamId="+tests.TestTeamID, nil)
+	c, w := newAuthenticatedContext(http.MethodGet, tests.PathEvents+"?teamId="+tests.TestTeamID, nil)
 
 	ec.GetEvents(c)
 
@@ -196,7 +189,6 @@ func TestEventController_GetEvents_Success(t *testing.T) {
 	assert.Equal(t, events[1].ID, resp[1].ID)
 
 	mockEventService.AssertExpectations(t)
-	mockTeamService.AssertExpectations(t)
 }
 
 func TestEventController_GetEvents_MissingTeamID(t *testing.T) {
@@ -220,55 +212,56 @@ func TestEventController_GetEvents_MissingTeamID(t *testing.T) {
 }
 
 func TestEventController_GetEvents_TeamNotFound(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-
 	mockEventService := new(tests.MockEventService)
-	mockTeamService := new(tests.MockTeamService)
 	ec := controller.NewEventController()
 	ec.SetEventService(mockEventService)
-	ec.SetTeamService(mockTeamService)
 
-	mockTeamService.On("GetTeamById", "invalid-team").Return(nil, fmt.Errorf(tests.ErrTeamNotFound))
+	mockEventService.On("GetEventsByTeamId", authenticatedUser, "invalid-team").
+		Return(nil, fmt.Errorf("%w: %s", service.ErrResourceNotFound, tests.ErrTeamNotFound))
 
-	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest(http.MethodGet, tests.PathEvents+"?teamId=invalid-team", nil)
+	c, w := newAuthenticatedContext(http.MethodGet, tests.PathEvents+"?teamId=invalid-team", nil)
 
 	ec.GetEvents(c)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusNotFound, w.Code)
 
 	var response map[string]string
 	json.Unmarshal(w.Body.Bytes(), &response)
 	assert.Contains(t, response[tests.JSONKeyError], tests.ErrTeamNotFound)
 
-	mockTeamService.AssertExpectations(t)
-	mockEventService.AssertNotCalled(t, "GetEventsByTeamId")
+	mockEventService.AssertExpectations(t)
 }
 
-func TestEventController_GetEvents_ServiceError(t *testing.T) {
-	gin.SetMode(gin.TestMode)
+func TestEventController_GetEvents_NotMember(t *testing.T) {
+	mockEventService := new(tests.MockEventService)
+	ec := controller.NewEventController()
+	ec.SetEventService(mockEventService)
+
+	mockEventService.On("GetEventsByTeamId", authenticatedUser, tests.TestTeamID).
+		Return(nil, fmt.Errorf("%w: not a member", service.ErrForbidden))
 
+	c, w := newAuthenticatedContext(http.MethodGet, tests.PathEvents+"?teamId="+tests.TestTeamID, nil)
+
+	ec.GetEvents(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockEventService.AssertExpectations(t)
+}
+
+func TestEventController_GetEvents_ServiceError(t *testing.T) {
 	mockEventService := new(tests.MockEventService)
-	mockTeamService := new(tests.MockTeamService)
 	ec := controller.NewEventController()
 	ec.SetEventService(mockEventService)
-	ec.SetTeamService(mockTeamService)
 
-	team := &entity.Team{Id: tests.TestTeamID}
-	mockTeamService.On("GetTeamById", tests.TestTeamID).Return(team, nil)
-	mockEventService.On("GetEventsByTeamId", tests.TestTeamID).Return(nil, fmt.Errorf("service error"))
+	mockEventService.On("GetEventsByTeamId", authenticatedUser, tests.TestTeamID).Return(nil, fmt.Errorf("service error"))
 
-	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest(http.MethodGet, tests.PathEvents+"?teamId="+tests.TestTeamID, nil)
+	c, w := newAuthenticatedContext(http.MethodGet, tests.PathEvents+"?teamId="+tests.TestTeamID, nil)
 
 	ec.GetEvents(c)
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
 	mockEventService.AssertExpectations(t)
-	mockTeamService.AssertExpectations(t)
 }
 
 func TestEventController_UpdateEventDetails_Success(t *testing.T) {
@@ -287,10 +280,11 @@ func TestEventController_UpdateEventDetails_Success(t *testing.T) {
 		Duration:    request.Duration,
 	}
 
-	mockService.On("UpdateEventDetails", tests.TestEventID, &request).Return(expectedResp, nil)
+	mockService.On("UpdateEventDetails", tests.TestUserID, tests.TestEventID, &request).Return(expectedResp, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Set("userClaims", jwt.MapClaims{"sub": tests.TestUserID})
 	c.Params = []gin.Param{{Key: "id", Value: tests.TestEventID}}
 
 	jsonData, _ := json.Marshal(request)
@@ -337,10 +331,11 @@ func TestEventController_UpdateEventDetails_ServiceError(t *testing.T) {
 
 	request := tests.GetValidUpdateEventRequest()
 
-	mockService.On("UpdateEventDetails", tests.TestEventID, &request).Return(nil, fmt.Errorf(tests.ErrEventNotFound))
+	mockService.On("UpdateEventDetails", tests.TestUserID, tests.TestEventID, &request).Return(nil, fmt.Errorf(tests.ErrEventNotFound))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Set("userClaims", jwt.MapClaims{"sub": tests.TestUserID})
 	c.Params = []gin.Param{{Key: "id", Value: tests.TestEventID}}
 
 	jsonData, _ := json.Marshal(request)
@@ -377,6 +372,7 @@ func TestEventController_UpdateUserStatus_Success(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Set("userClaims", jwt.MapClaims{"sub": tests.TestUserID1})
 	c.Params = []gin.Param{{Key: "id", Value: tests.TestEventID}}
 
 	jsonData, _ := json.Marshal(request)
@@ -426,6 +422,7 @@ func TestEventController_UpdateUserStatus_SameStatus_Conflict(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Set("userClaims", jwt.MapClaims{"sub": tests.TestUserID1})
 	c.Params = []gin.Param{{Key: "id", Value: tests.TestEventID}}
 
 	jsonData, _ := json.Marshal(request)
@@ -459,6 +456,7 @@ func TestEventController_UpdateUserStatus_InvalidStatus(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Set("userClaims", jwt.MapClaims{"sub": tests.TestUserID1})
 	c.Params = []gin.Param{{Key: "id", Value: tests.TestEventID}}
 
 	jsonData, _ := json.Marshal(request)
@@ -483,11 +481,12 @@ func TestEventController_DeleteEvent_Success(t *testing.T) {
 	ec := controller.NewEventController()
 	ec.SetEventService(mockService)
 
-	mockService.On("DeleteEvent", tests.TestEventID).Return(nil)
+	mockService.On("DeleteEvent", tests.TestEventID, tests.TestUserID).Return(nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Params = []gin.Param{{Key: "id", Value: tests.TestEventID}}
+	c.Set("userClaims", jwt.MapClaims{"sub": tests.TestUserID})
 
 	ec.DeleteEvent(c)
 
@@ -507,11 +506,12 @@ func TestEventController_DeleteEvent_NotFound(t *testing.T) {
 	ec := controller.NewEventController()
 	ec.SetEventService(mockService)
 
-	mockService.On("DeleteEvent", "invalid-id").Return(fmt.Errorf(tests.ErrEventNotFound))
+	mockService.On("DeleteEvent", "invalid-id", tests.TestUserID).Return(fmt.Errorf(tests.ErrEventNotFound))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Params = []gin.Param{{Key: "id", Value: "invalid-id"}}
+	c.Set("userClaims", jwt.MapClaims{"sub": tests.TestUserID})
 
 	ec.DeleteEvent(c)
 
@@ -523,3 +523,19 @@ func TestEventController_DeleteEvent_NotFound(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestEventController_GetEvent_NotTeamMember(t *testing.T) {
+	mockService := new(tests.MockEventService)
+	ec := controller.NewEventController()
+	ec.SetEventService(mockService)
+
+	mockService.On("GetEventById", authenticatedUser, tests.TestEventID).
+		Return(nil, fmt.Errorf("%w: you are not a member of this team", service.ErrForbidden))
+
+	c, w := newAuthenticatedContext(http.MethodGet, tests.PathEvents+"/"+tests.TestEventID, nil)
+	c.Params = []gin.Param{{Key: "id", Value: tests.TestEventID}}
+
+	ec.GetEvent(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -23,6 +24,7 @@ func TestFriendRequestController_SendFriendRequest_Success(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Set("userClaims", jwt.MapClaims{"sub": "user1"})
 
 	c.Params = []gin.Param{
 		{Key: "fromUserId", Value: "user1"},
@@ -46,6 +48,7 @@ func TestFriendRequestController_RespondToRequest_Success(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Set("userClaims", jwt.MapClaims{"sub": "user2"})
 
 	c.Params = []gin.Param{
 		{Key: "fromUserId", Value: "user1"},
@@ -73,6 +76,7 @@ func TestFriendRequestController_SendFriendRequest_Error(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Set("userClaims", jwt.MapClaims{"sub": "invalidUser"})
 
 	c.Params = []gin.Param{
 		{Key: "fromUserId", Value: "invalidUser"},
@@ -98,6 +102,7 @@ func TestFriendRequestController_RespondToRequest_InvalidBody(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Set("userClaims", jwt.MapClaims{"sub": "user2"})
 
 	c.Params = []gin.Param{
 		{Key: "fromUserId", Value: "user1"},
@@ -166,6 +171,7 @@ func TestFriendRequestController_GetPendingRequests_Error(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Set("userClaims", jwt.MapClaims{"sub": "invalidUser"})
 
 	c.Params = []gin.Param{
 		{Key: "userId", Value: "invalidUser"},
@@ -0,0 +1,285 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	authenticatedUser = "user1"
+	impersonatedUser  = "user2"
+)
+
+// newAuthenticatedContext builds a request context for authenticatedUser with an optional JSON body
+func newAuthenticatedContext(method, target string, body interface{}) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userClaims", jwt.MapClaims{"sub": authenticatedUser})
+
+	var reader *bytes.Buffer
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewBuffer(data)
+	} else {
+		reader = bytes.NewBuffer(nil)
+	}
+	c.Request, _ = http.NewRequest(method, target, reader)
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c, w
+}
+
+func assertImpersonationRejected(t *testing.T, w *httptest.ResponseRecorder) {
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	var response map[string]string
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, controller.ImpersonationError, response["error"])
+}
+
+func TestImpersonation_DirectMessageSender(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/messages?type=direct", dto.NewDirectMessageRequest(impersonatedUser, "user3", "hi"))
+	mc.NewMessage(c)
+
+	assertImpersonationRejected(t, w)
+	mockService.AssertNotCalled(t, "CreateDirectMessage", mock.Anything)
+}
+
+func TestImpersonation_TeamMessageSender(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/messages?type=team", dto.NewTeamMessageRequest(impersonatedUser, tests.TestTeamID, "hi"))
+	mc.NewMessage(c)
+
+	assertImpersonationRejected(t, w)
+	mockService.AssertNotCalled(t, "CreateTeamMessage", mock.Anything)
+}
+
+func TestImpersonation_ReadOthersDirectMessages(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/messages?type=direct&user1Id="+impersonatedUser+"&user2Id=user3", nil)
+	mc.GetMessages(c)
+
+	assertImpersonationRejected(t, w)
+	mockService.AssertNotCalled(t, "GetDirectMessages", mock.Anything, mock.Anything)
+}
+
+func TestImpersonation_EventInitiator(t *testing.T) {
+	mockService := new(tests.MockEventService)
+	ec := controller.NewEventController()
+	ec.SetEventService(mockService)
+
+	request := tests.GetValidCreateEventRequest()
+	request.InitiatorID = impersonatedUser
+	c, w := newAuthenticatedContext(http.MethodPost, tests.PathEvents, request)
+	ec.NewEvent(c)
+
+	assertImpersonationRejected(t, w)
+	mockService.AssertNotCalled(t, "CreateEvent", mock.Anything)
+}
+
+func TestImpersonation_EventInitiatorFilledFromToken(t *testing.T) {
+	mockService := new(tests.MockEventService)
+	ec := controller.NewEventController()
+	ec.SetEventService(mockService)
+
+	request := tests.GetValidCreateEventRequest()
+	request.InitiatorID = ""
+	mockService.On("CreateEvent", mock.MatchedBy(func(req *dto.CreateEventRequest) bool {
+		return req.InitiatorID == authenticatedUser
+	})).Return(&dto.EventDTO{ID: tests.TestEventID}, nil)
+
+	c, w := newAuthenticatedContext(http.MethodPost, tests.PathEvents, request)
+	ec.NewEvent(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestImpersonation_EventStatusUser(t *testing.T) {
+	mockService := new(tests.MockEventService)
+	ec := controller.NewEventController()
+	ec.SetEventService(mockService)
+
+	c, w := newAuthenticatedContext(http.MethodPatch, "/events/"+tests.TestEventID+"/status", dto.NewUpdateEventStatusRequest(impersonatedUser, "accepted"))
+	c.Params = gin.Params{{Key: "id", Value: tests.TestEventID}}
+	ec.UpdateUserStatus(c)
+
+	assertImpersonationRejected(t, w)
+	mockService.AssertNotCalled(t, "UpdateUserStatus", mock.Anything, mock.Anything)
+}
+
+func TestImpersonation_FileOwner(t *testing.T) {
+	mockService := new(tests.MockFileService)
+	fc := controller.NewFileControllerWithService(mockService)
+
+	request := dto.FileUploadRequest{Name: "notes", Type: "document", Extension: "pdf", Content: "aGk=", OwnerID: impersonatedUser, Size: 2}
+	c, w := newAuthenticatedContext(http.MethodPost, "/teams/"+tests.TestTeamID+"/files", request)
+	c.Params = gin.Params{{Key: "id", Value: tests.TestTeamID}}
+	fc.UploadFile(c)
+
+	assertImpersonationRejected(t, w)
+	mockService.AssertNotCalled(t, "CreateFile", mock.Anything, mock.Anything)
+}
+
+func TestImpersonation_FileOwnerFilledFromToken(t *testing.T) {
+	mockService := new(tests.MockFileService)
+	fc := controller.NewFileControllerWithService(mockService)
+
+	request := dto.FileUploadRequest{Name: "notes", Type: "document", Extension: "pdf", Content: "aGk=", Size: 2}
+	mockService.On("CreateFile", mock.MatchedBy(func(req *dto.FileUploadRequest) bool {
+		return req.OwnerID == authenticatedUser
+	}), authenticatedUser).Return(&dto.FileUploadResponse{ID: "file1", OwnerID: authenticatedUser}, nil)
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/teams/"+tests.TestTeamID+"/files", request)
+	c.Params = gin.Params{{Key: "id", Value: tests.TestTeamID}}
+	fc.UploadFile(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestImpersonation_FriendRequestSender(t *testing.T) {
+	mockService := new(tests.MockFriendRequestService)
+	fc := controller.NewFriendRequestController()
+	fc.SetFriendRequestService(mockService)
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/friend-requests/"+impersonatedUser+"/user3", nil)
+	c.Params = gin.Params{{Key: "fromUserId", Value: impersonatedUser}, {Key: "toUserId", Value: "user3"}}
+	fc.SendFriendRequest(c)
+
+	assertImpersonationRejected(t, w)
+	mockService.AssertNotCalled(t, "SendFriendRequest", mock.Anything, mock.Anything)
+}
+
+func TestImpersonation_FriendRequestResponder(t *testing.T) {
+	mockService := new(tests.MockFriendRequestService)
+	fc := controller.NewFriendRequestController()
+	fc.SetFriendRequestService(mockService)
+
+	// the sender of a request cannot accept it on behalf of the recipient
+	c, w := newAuthenticatedContext(http.MethodPut, "/friend-requests/"+authenticatedUser+"/"+impersonatedUser, map[string]bool{"accept": true})
+	c.Params = gin.Params{{Key: "fromUserId", Value: authenticatedUser}, {Key: "toUserId", Value: impersonatedUser}}
+	fc.RespondToFriendRequest(c)
+
+	assertImpersonationRejected(t, w)
+	mockService.AssertNotCalled(t, "RespondToFriendRequest", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImpersonation_FriendRequestPendingList(t *testing.T) {
+	mockService := new(tests.MockFriendRequestService)
+	fc := controller.NewFriendRequestController()
+	fc.SetFriendRequestService(mockService)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/friend-requests/"+impersonatedUser, nil)
+	c.Params = gin.Params{{Key: "userId", Value: impersonatedUser}}
+	fc.GetPendingRequests(c)
+
+	assertImpersonationRejected(t, w)
+	mockService.AssertNotCalled(t, "GetPendingRequests", mock.Anything)
+}
+
+func TestImpersonation_TeamRequestUser(t *testing.T) {
+	mockService := new(tests.MockTeamRequestService)
+	ctrl := controller.NewTeamRequestControllerWithService(mockService)
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/teamRequests", dto.TeamRequestCreateDTO{UserID: impersonatedUser, TeamID: tests.TestTeamID})
+	ctrl.CreateTeamRequest(c)
+
+	assertImpersonationRejected(t, w)
+	mockService.AssertNotCalled(t, "CreateTeamRequest", mock.Anything)
+}
+
+func TestImpersonation_TeamRequestsByUser(t *testing.T) {
+	mockService := new(tests.MockTeamRequestService)
+	ctrl := controller.NewTeamRequestControllerWithService(mockService)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/teamRequests/user/"+impersonatedUser, nil)
+	c.Params = gin.Params{{Key: "userId", Value: impersonatedUser}}
+	ctrl.GetTeamRequestsByUser(c)
+
+	assertImpersonationRejected(t, w)
+	mockService.AssertNotCalled(t, "GetByUserId", mock.Anything)
+}
+
+func TestImpersonation_VoiceCaller(t *testing.T) {
+	vc := controller.NewVoiceController()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/voice/private/call?callerId="+impersonatedUser+"&targetId=user3", nil)
+	vc.StartPrivateCall(c)
+
+	assertImpersonationRejected(t, w)
+}
+
+func TestImpersonation_VoiceCallerFilledFromToken(t *testing.T) {
+	vc := controller.NewVoiceController()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/voice/private/call?targetId=user3", nil)
+	vc.StartPrivateCall(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var room map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &room)
+	assert.Equal(t, authenticatedUser, room["createdBy"])
+}
+
+func TestImpersonation_VoiceJoinableRooms(t *testing.T) {
+	vc := controller.NewVoiceController()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/voice/joinable?userId="+impersonatedUser, nil)
+	vc.GetJoinableRooms(c)
+
+	assertImpersonationRejected(t, w)
+}
+
+func TestImpersonation_VoiceRoomCreator(t *testing.T) {
+	vc := controller.NewVoiceController()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/voice/rooms/"+tests.TestTeamID+"?userId="+impersonatedUser, nil)
+	c.Params = gin.Params{{Key: "teamId", Value: tests.TestTeamID}}
+	vc.CreateVoiceRoom(c)
+
+	assertImpersonationRejected(t, w)
+}
+
+func TestImpersonation_VoiceJoin(t *testing.T) {
+	vc := controller.NewVoiceController()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/voice/join/room1?userId="+impersonatedUser, nil)
+	c.Params = gin.Params{{Key: "roomId", Value: "room1"}}
+	vc.JoinVoiceRoom(c)
+
+	assertImpersonationRejected(t, w)
+}
+
+func TestImpersonation_MissingClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(tests.MockEventService)
+	ec := controller.NewEventController()
+	ec.SetEventService(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	data, _ := json.Marshal(tests.GetValidCreateEventRequest())
+	c.Request, _ = http.NewRequest(http.MethodPost, tests.PathEvents, bytes.NewBuffer(data))
+	ec.NewEvent(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertNotCalled(t, "CreateEvent", mock.Anything)
+}
@@ -0,0 +1,186 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMessageController_GetMessages_InvalidCursor(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	page := dto.MessagePageRequest{Before: "bad", Limit: 20}
+	mockService.On("GetTeamMessages", authenticatedUser, tests.TestTeamID, page).Return(nil, service.ErrInvalidCursor)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/messages?type=team&teamId="+tests.TestTeamID+"&before=bad&limit=20", nil)
+	mc.GetMessages(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestMessageController_EditMessage_Success(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	request := &dto.EditMessageRequest{TextContent: "fixed"}
+	edited := &dto.MessageDTO{ID: "m1", TextContent: "fixed", EditedAt: "2025-01-01T12:00:00Z"}
+	mockService.On("EditMessage", authenticatedUser, "m1", request).Return(edited, []string{authenticatedUser, "user3"}, nil)
+
+	c, w := newAuthenticatedContext(http.MethodPatch, "/messages/m1", request)
+	c.Params = gin.Params{{Key: "id", Value: "m1"}}
+	mc.EditMessage(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.MessageDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "fixed", response.TextContent)
+	assert.Equal(t, "2025-01-01T12:00:00Z", response.EditedAt)
+}
+
+func TestMessageController_EditMessage_ErrorStatus(t *testing.T) {
+	cases := map[error]int{
+		fmt.Errorf("%w: not yours", service.ErrForbidden):      http.StatusForbidden,
+		fmt.Errorf("%w: missing", service.ErrResourceNotFound): http.StatusNotFound,
+		service.ErrMessageDeleted:                              http.StatusConflict,
+	}
+	for err, status := range cases {
+		mockService := new(tests.MockMessageService)
+		mc := controller.NewMessageControllerWithService(mockService)
+		mockService.On("EditMessage", authenticatedUser, "m1", mock.Anything).Return(nil, nil, err)
+
+		c, w := newAuthenticatedContext(http.MethodPatch, "/messages/m1", dto.EditMessageRequest{TextContent: "x"})
+		c.Params = gin.Params{{Key: "id", Value: "m1"}}
+		mc.EditMessage(c)
+
+		assert.Equal(t, status, w.Code, err.Error())
+	}
+}
+
+func TestMessageController_DeleteMessage_ReturnsTombstone(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	tombstone := &dto.MessageDTO{ID: "m1", Deleted: true, DeletedAt: "2025-01-01T12:00:00Z"}
+	mockService.On("DeleteMessage", authenticatedUser, "m1").Return(tombstone, []string{authenticatedUser}, nil)
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "/messages/m1", nil)
+	c.Params = gin.Params{{Key: "id", Value: "m1"}}
+	mc.DeleteMessage(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.MessageDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Deleted)
+	assert.Empty(t, response.TextContent)
+}
+
+func TestMessageController_MarkRead(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	receipt := &dto.ReadReceiptDTO{MessageID: "m1", UserID: authenticatedUser, ReadAt: "2025-01-01T12:00:00Z"}
+	mockService.On("MarkRead", authenticatedUser, &dto.ReadMessageRequest{MessageID: "m1"}).Return(receipt, []string{"other"}, nil)
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/messages/m1/read", nil)
+	c.Params = gin.Params{{Key: "id", Value: "m1"}}
+	mc.MarkRead(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.ReadReceiptDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "m1", response.MessageID)
+}
+
+func TestMessageController_GetUnreadCounts(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	counts := &dto.UnreadCountsDTO{
+		Conversations: []*dto.UnreadCountDTO{{UserID: "other", Count: 2}},
+		Teams:         []*dto.UnreadCountDTO{},
+	}
+	mockService.On("GetUnreadCounts", authenticatedUser).Return(counts, nil)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/messages/unread", nil)
+	mc.GetUnreadCounts(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.UnreadCountsDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Conversations[0].Count)
+	assert.Empty(t, response.Teams)
+}
+
+func TestMessageController_ToggleReaction(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	updated := &dto.MessageDTO{ID: "m1", Reactions: []dto.ReactionDTO{{Emoji: "👍", Count: 1, UserIDs: []string{authenticatedUser}}}}
+	mockService.On("ToggleReaction", authenticatedUser, &dto.ReactionRequest{MessageID: "m1", Emoji: "👍"}).
+		Return(updated, []string{authenticatedUser}, nil)
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/messages/m1/reactions", map[string]string{"emoji": "👍"})
+	c.Params = gin.Params{{Key: "id", Value: "m1"}}
+	mc.ToggleReaction(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.MessageDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Reactions[0].Count)
+}
+
+func TestMessageController_GetReplies(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	page := &dto.MessagePageDTO{Messages: []*dto.MessageDTO{{ID: "r1", ParentID: "m1"}}}
+	mockService.On("GetReplies", authenticatedUser, "m1", dto.MessagePageRequest{Limit: 10}).Return(page, nil)
+	mockService.On("GetReplies", authenticatedUser, "hidden", dto.MessagePageRequest{}).
+		Return(nil, fmt.Errorf("%w: not part of this conversation", service.ErrForbidden))
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/messages/m1/replies?limit=10", nil)
+	c.Params = gin.Params{{Key: "id", Value: "m1"}}
+	mc.GetReplies(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	c, w = newAuthenticatedContext(http.MethodGet, "/messages/hidden/replies", nil)
+	c.Params = gin.Params{{Key: "id", Value: "hidden"}}
+	mc.GetReplies(c)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestMessageController_GetMessage(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	mockService.On("GetMessageByID", authenticatedUser, "m1").Return(&dto.MessageDTO{ID: "m1", TeamID: tests.TestTeamID}, nil)
+	mockService.On("GetMessageByID", authenticatedUser, "private").
+		Return(nil, fmt.Errorf("%w: you are not a member of this team", service.ErrForbidden))
+	mockService.On("GetMessageByID", authenticatedUser, "missing").
+		Return(nil, fmt.Errorf("%w: message not found", service.ErrResourceNotFound))
+
+	for id, status := range map[string]int{"m1": http.StatusOK, "private": http.StatusForbidden, "missing": http.StatusNotFound} {
+		c, w := newAuthenticatedContext(http.MethodGet, "/messages/"+id, nil)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		mc.GetMessage(c)
+		assert.Equal(t, status, w.Code, id)
+	}
+}
+
+func TestMessageController_NewMessage_InvalidAttachment(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	request := dto.TeamMessageRequest{TeamId: tests.TestTeamID, TextContent: "see attached", AttachmentIDs: []string{"f1"}}
+	mockService.On("CreateTeamMessage", mock.MatchedBy(func(r *dto.TeamMessageRequest) bool {
+		return r.SenderID == authenticatedUser && len(r.AttachmentIDs) == 1
+	})).Return(nil, service.ErrInvalidAttachment)
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/messages?type=team", request)
+	mc.NewMessage(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,263 @@
+package controller_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/hub"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// frame is an outbound hub message with the payload left as generic JSON
+type frame struct {
+	Seq       int64                  `json:"seq"`
+	Type      hub.MessageType        `json:"type"`
+	RequestID string                 `json:"requestId"`
+	Payload   map[string]interface{} `json:"payload"`
+}
+
+// dialMessages serves mc.Connect and connects to it as userID
+func dialMessages(t *testing.T, mc *controller.MessageController, userID string) *websocket.Conn {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/messages/connect", func(c *gin.Context) {
+		c.Set("userClaims", jwt.MapClaims{"sub": c.Query("as")})
+		mc.Connect(c)
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/messages/connect?as=" + userID
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readFrame returns the next frame of the given type, skipping others
+func readFrame(t *testing.T, conn *websocket.Conn, frameType hub.MessageType) frame {
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for {
+		var f frame
+		if err := conn.ReadJSON(&f); err != nil {
+			t.Fatalf("waiting for %s frame: %v", frameType, err)
+		}
+		if f.Type == frameType {
+			return f
+		}
+	}
+}
+
+func TestMessageSocket_SendDirectMessage(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	created := &dto.MessageDTO{ID: "m1", ReceiverID: "user3", TextContent: "hi"}
+	mockService.On("CreateDirectMessage", mock.MatchedBy(func(r *dto.DirectMessageRequest) bool {
+		return r.SenderID == authenticatedUser && r.ReceiverID == "user3" && r.TextContent == "hi"
+	})).Return(created, nil)
+
+	receiver := dialMessages(t, mc, "user3")
+	sender := dialMessages(t, mc, authenticatedUser)
+	assert.NoError(t, sender.WriteJSON(map[string]interface{}{
+		"requestId": "r1",
+		"type":      hub.SendDirectMessage,
+		"payload":   map[string]string{"receiverId": "user3", "textContent": "hi"},
+	}))
+
+	ack := readFrame(t, sender, hub.Ack)
+	assert.Equal(t, "r1", ack.RequestID)
+	assert.Equal(t, "m1", ack.Payload["id"])
+	delivered := readFrame(t, receiver, hub.DirectMessage)
+	assert.Equal(t, "hi", delivered.Payload["textContent"])
+}
+
+func TestMessageSocket_SendTeamMessage(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mockTeamService := new(tests.MockTeamService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	mc.SetTeamService(mockTeamService)
+	mockService.On("CreateTeamMessage", mock.Anything).Return(&dto.MessageDTO{ID: "m1", TeamID: tests.TestTeamID}, nil)
+	mockTeamService.On("GetTeamById", tests.TestTeamID).
+		Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{authenticatedUser, "user3"}}, nil)
+
+	member := dialMessages(t, mc, "user3")
+	sender := dialMessages(t, mc, authenticatedUser)
+	assert.NoError(t, sender.WriteJSON(map[string]interface{}{
+		"requestId": "r1",
+		"type":      hub.SendTeamMessage,
+		"payload":   map[string]string{"teamId": tests.TestTeamID, "textContent": "hello team"},
+	}))
+
+	assert.Equal(t, "r1", readFrame(t, sender, hub.Ack).RequestID)
+	assert.Equal(t, "m1", readFrame(t, member, hub.TeamBroadcast).Payload["id"])
+}
+
+func TestMessageSocket_ThreadReplyUpdatesParent(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mockTeamService := new(tests.MockTeamService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	mc.SetTeamService(mockTeamService)
+	mockService.On("CreateTeamMessage", mock.Anything).Return(&dto.MessageDTO{ID: "r1", TeamID: tests.TestTeamID, ParentID: "m1"}, nil)
+	mockService.On("GetMessageByID", authenticatedUser, "m1").Return(&dto.MessageDTO{ID: "m1", TeamID: tests.TestTeamID, ReplyCount: 1}, nil)
+	mockTeamService.On("GetTeamById", tests.TestTeamID).
+		Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{authenticatedUser, "user3"}}, nil)
+
+	member := dialMessages(t, mc, "user3")
+	sender := dialMessages(t, mc, authenticatedUser)
+	assert.NoError(t, sender.WriteJSON(map[string]interface{}{
+		"requestId": "r1",
+		"type":      hub.SendTeamMessage,
+		"payload":   map[string]string{"teamId": tests.TestTeamID, "textContent": "in thread", "parentId": "m1"},
+	}))
+
+	assert.Equal(t, "m1", readFrame(t, member, hub.TeamBroadcast).Payload["parentId"])
+	parent := readFrame(t, member, hub.MessageEdited)
+	assert.Equal(t, "m1", parent.Payload["id"])
+	assert.Equal(t, float64(1), parent.Payload["replyCount"])
+}
+
+func TestMessageSocket_ReactionReachesParticipants(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	updated := &dto.MessageDTO{ID: "m1", Reactions: []dto.ReactionDTO{{Emoji: "🎉", Count: 1, UserIDs: []string{authenticatedUser}}}}
+	mockService.On("ToggleReaction", authenticatedUser, &dto.ReactionRequest{MessageID: "m1", Emoji: "🎉"}).
+		Return(updated, []string{authenticatedUser, "user3"}, nil)
+
+	other := dialMessages(t, mc, "user3")
+	reactor := dialMessages(t, mc, authenticatedUser)
+	assert.NoError(t, reactor.WriteJSON(map[string]interface{}{
+		"requestId": "r1",
+		"type":      hub.SendReaction,
+		"payload":   map[string]string{"messageId": "m1", "emoji": "🎉"},
+	}))
+
+	assert.Equal(t, "r1", readFrame(t, reactor, hub.Ack).RequestID)
+	assert.Equal(t, "m1", readFrame(t, other, hub.MessageEdited).Payload["id"])
+}
+
+func TestMessageSocket_TypingReachesRecipients(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	request := &dto.TypingRequest{TeamID: tests.TestTeamID}
+	mockService.On("Typing", authenticatedUser, request).
+		Return(&dto.TypingDTO{UserID: authenticatedUser, TeamID: tests.TestTeamID}, []string{"user3"}, nil)
+
+	other := dialMessages(t, mc, "user3")
+	sender := dialMessages(t, mc, authenticatedUser)
+	assert.NoError(t, sender.WriteJSON(map[string]interface{}{"requestId": "r2", "type": hub.SendTyping, "payload": request}))
+
+	assert.Equal(t, "r2", readFrame(t, sender, hub.Ack).RequestID)
+	assert.Equal(t, authenticatedUser, readFrame(t, other, hub.Typing).Payload["userId"])
+}
+
+func TestMessageSocket_ErrorFrames(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	mockService.On("MarkRead", authenticatedUser, &dto.ReadMessageRequest{MessageID: "m1"}).
+		Return(nil, nil, errors.New("message not found"))
+	conn := dialMessages(t, mc, authenticatedUser)
+
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("not json")))
+	assert.Equal(t, controller.InvalidFrameError, readFrame(t, conn, hub.Error).Payload["error"])
+
+	assert.NoError(t, conn.WriteJSON(map[string]interface{}{"type": hub.SendTyping, "payload": map[string]string{}}))
+	assert.Equal(t, controller.MissingRequestIDError, readFrame(t, conn, hub.Error).Payload["error"])
+
+	assert.NoError(t, conn.WriteJSON(map[string]interface{}{"requestId": "r1", "type": "shout"}))
+	unknown := readFrame(t, conn, hub.Error)
+	assert.Equal(t, "r1", unknown.RequestID)
+	assert.Equal(t, controller.UnknownFrameTypeError, unknown.Payload["error"])
+
+	assert.NoError(t, conn.WriteJSON(map[string]interface{}{
+		"requestId": "r2",
+		"type":      hub.SendDirectMessage,
+		"payload":   map[string]string{"senderId": impersonatedUser, "receiverId": "user3", "textContent": "hi"},
+	}))
+	assert.Equal(t, controller.ImpersonationError, readFrame(t, conn, hub.Error).Payload["error"])
+
+	assert.NoError(t, conn.WriteJSON(map[string]interface{}{"requestId": "r3", "type": hub.SendRead, "payload": map[string]string{"messageId": "m1"}}))
+	failed := readFrame(t, conn, hub.Error)
+	assert.Equal(t, "r3", failed.RequestID)
+	assert.Equal(t, "message not found", failed.Payload["error"])
+	mockService.AssertNotCalled(t, "CreateDirectMessage", mock.Anything)
+}
+
+func TestMessageSocket_ReplaysMissedEventsSince(t *testing.T) {
+	mockService := new(tests.MockMessageService)
+	mc := controller.NewMessageControllerWithService(mockService)
+	store, err := persistence.NewLocalStore("")
+	assert.NoError(t, err)
+	mc.SetJournal(hub.NewMessageJournal(persistence.NewLocalHubEventRepository(store)))
+	mockService.On("CreateDirectMessage", mock.Anything).
+		Return(&dto.MessageDTO{ID: "m1", ReceiverID: "user3", TextContent: "hi"}, nil)
+
+	sender := dialMessages(t, mc, authenticatedUser)
+	assert.NoError(t, sender.WriteJSON(map[string]interface{}{
+		"requestId": "r1",
+		"type":      hub.SendDirectMessage,
+		"payload":   map[string]string{"receiverId": "user3", "textContent": "hi"},
+	}))
+	readFrame(t, sender, hub.Ack)
+
+	// user3 was offline when the message was sent
+	receiver := dialMessages(t, mc, "user3&since=0")
+	missed := readFrame(t, receiver, hub.DirectMessage)
+	assert.Equal(t, int64(1), missed.Seq)
+	assert.Equal(t, "m1", missed.Payload["id"])
+}
+
+func TestMessageSocket_RejectsInvalidSince(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mc := controller.NewMessageControllerWithService(new(tests.MockMessageService))
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/messages/connect?since=-1", nil)
+	c.Set("userClaims", jwt.MapClaims{"sub": authenticatedUser})
+
+	mc.Connect(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), controller.InvalidSinceError)
+}
+
+func TestMessageSocket_PresenceReachesFriendsAndTeammates(t *testing.T) {
+	mc := controller.NewMessageControllerWithService(new(tests.MockMessageService))
+	mockPresence := new(tests.MockPresenceService)
+	mc.SetPresenceService(mockPresence)
+	online := &dto.PresenceDTO{UserID: authenticatedUser, Status: entity.PresenceOnline}
+	idle := &dto.PresenceDTO{UserID: authenticatedUser, Status: entity.PresenceIdle}
+	mockPresence.On("SetStatus", "user3", entity.PresenceOnline).Return(&dto.PresenceDTO{UserID: "user3"}, []string{}, nil)
+	mockPresence.On("SetStatus", authenticatedUser, entity.PresenceOnline).Return(online, []string{"user3"}, nil)
+	mockPresence.On("UpdatePresence", authenticatedUser, &dto.SetPresenceRequest{Status: entity.PresenceIdle}).
+		Return(idle, []string{"user3"}, nil)
+	// the connections close when the test ends
+	mockPresence.On("SetStatus", mock.Anything, entity.PresenceOffline).Return(&dto.PresenceDTO{}, []string{}, nil).Maybe()
+
+	friend := dialMessages(t, mc, "user3")
+	// an answered frame means the friend's connection is registered
+	assert.NoError(t, friend.WriteJSON(map[string]interface{}{"requestId": "ping", "type": "ping"}))
+	readFrame(t, friend, hub.Error)
+	user := dialMessages(t, mc, authenticatedUser)
+	assert.Equal(t, string(entity.PresenceOnline), readFrame(t, friend, hub.PresenceChanged).Payload["status"])
+
+	assert.NoError(t, user.WriteJSON(map[string]interface{}{
+		"requestId": "r1",
+		"type":      hub.SetPresence,
+		"payload":   map[string]string{"status": "idle"},
+	}))
+	assert.Equal(t, "r1", readFrame(t, user, hub.Ack).RequestID)
+	assert.Equal(t, string(entity.PresenceIdle), readFrame(t, friend, hub.PresenceChanged).Payload["status"])
+}
@@ -0,0 +1,146 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationController_GetNotifications(t *testing.T) {
+	mockService := new(tests.MockNotificationService)
+	nc := controller.NewNotificationControllerWithService(mockService)
+	mockService.On("GetNotifications", authenticatedUser, true, 2, 5).Return(&dto.NotificationsPageDTO{
+		Notifications: []*entity.Notification{{ID: "n1", UserID: authenticatedUser, Type: entity.NotificationMention}},
+		UnreadCount:   6,
+		Page:          2,
+		Limit:         5,
+		TotalCount:    6,
+		TotalPages:    2,
+	}, nil)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/notifications?unread=true&page=2&limit=5", nil)
+	nc.GetNotifications(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.NotificationsPageDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "n1", response.Notifications[0].ID)
+	assert.Equal(t, 6, response.UnreadCount)
+}
+
+func TestNotificationController_GetUnreadCounts(t *testing.T) {
+	mockService := new(tests.MockNotificationService)
+	nc := controller.NewNotificationControllerWithService(mockService)
+	mockService.On("GetUnreadCounts", authenticatedUser).Return(&dto.UnreadNotificationsDTO{
+		Total:  2,
+		ByType: map[entity.NotificationType]int{entity.NotificationMention: 2},
+	}, nil)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/notifications/unread", nil)
+	nc.GetUnreadCounts(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"total":2,"byType":{"mention":2}}`, w.Body.String())
+}
+
+func TestNotificationController_MarkRead_NotFound(t *testing.T) {
+	mockService := new(tests.MockNotificationService)
+	nc := controller.NewNotificationControllerWithService(mockService)
+	mockService.On("MarkRead", authenticatedUser, "n1").Return(nil, fmt.Errorf("%w: notification not found", service.ErrResourceNotFound))
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/notifications/n1/read", nil)
+	c.Params = []gin.Param{{Key: "id", Value: "n1"}}
+	nc.MarkRead(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestNotificationController_MarkAllRead(t *testing.T) {
+	mockService := new(tests.MockNotificationService)
+	nc := controller.NewNotificationControllerWithService(mockService)
+	mockService.On("MarkAllRead", authenticatedUser).Return(&dto.NotificationsReadDTO{All: true, Marked: 3}, nil)
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/notifications/read", nil)
+	nc.MarkAllRead(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"all":true,"marked":3,"unreadCount":0}`, w.Body.String())
+}
+
+func TestNotificationController_GetPreferences(t *testing.T) {
+	mockService := new(tests.MockNotificationService)
+	nc := controller.NewNotificationControllerWithService(mockService)
+	mockService.On("GetPreferences", authenticatedUser).Return(&dto.NotificationPreferencesDTO{
+		Channels: map[entity.NotificationType]map[entity.NotificationChannel]bool{
+			entity.NotificationMention: {entity.ChannelInApp: true, entity.ChannelEmail: false, entity.ChannelPush: true},
+		},
+		MutedTeamIDs: []string{},
+		Timezone:     "UTC",
+	}, nil)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/notifications/preferences", nil)
+	nc.GetPreferences(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.NotificationPreferencesDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Channels[entity.NotificationMention][entity.ChannelEmail])
+	assert.Equal(t, "UTC", response.Timezone)
+}
+
+func TestNotificationController_UpdatePreferences_Success(t *testing.T) {
+	mockService := new(tests.MockNotificationService)
+	nc := controller.NewNotificationControllerWithService(mockService)
+	request := &dto.NotificationPreferencesRequest{
+		MutedTeamIDs: []string{tests.TestTeamID},
+		QuietHours:   &entity.QuietHours{Start: "22:00", End: "07:00"},
+		Timezone:     "Europe/Bucharest",
+	}
+	mockService.On("UpdatePreferences", authenticatedUser, request).Return(&dto.NotificationPreferencesDTO{
+		MutedTeamIDs: []string{tests.TestTeamID},
+		QuietHours:   request.QuietHours,
+		Timezone:     "Europe/Bucharest",
+	}, nil)
+
+	c, w := newAuthenticatedContext(http.MethodPut, "/notifications/preferences", request)
+	nc.UpdatePreferences(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestNotificationController_UpdatePreferences_ValidationError(t *testing.T) {
+	mockService := new(tests.MockNotificationService)
+	nc := controller.NewNotificationControllerWithService(mockService)
+	request := &dto.NotificationPreferencesRequest{Timezone: "Mars/Olympus"}
+	mockService.On("UpdatePreferences", authenticatedUser, request).
+		Return(nil, errors.New(`validation failed: unknown timezone "Mars/Olympus"`))
+
+	c, w := newAuthenticatedContext(http.MethodPut, "/notifications/preferences", request)
+	nc.UpdatePreferences(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestNotificationController_UpdatePreferences_NotTeamMember(t *testing.T) {
+	mockService := new(tests.MockNotificationService)
+	nc := controller.NewNotificationControllerWithService(mockService)
+	request := &dto.NotificationPreferencesRequest{MutedTeamIDs: []string{tests.TestTeamID}}
+	mockService.On("UpdatePreferences", authenticatedUser, request).
+		Return(nil, fmt.Errorf("%w: you are not a member of this team", service.ErrForbidden))
+
+	c, w := newAuthenticatedContext(http.MethodPut, "/notifications/preferences", request)
+	nc.UpdatePreferences(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,65 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresenceController_GetUserPresence(t *testing.T) {
+	mockService := new(tests.MockPresenceService)
+	pc := controller.NewPresenceControllerWithService(mockService)
+	mockService.On("GetPresence", authenticatedUser, "user3").
+		Return(&dto.PresenceDTO{UserID: "user3", Status: entity.PresenceIdle, LastSeenAt: "2025-01-01T12:00:00Z"}, nil)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/users/user3/presence", nil)
+	c.Params = gin.Params{{Key: "id", Value: "user3"}}
+	pc.GetUserPresence(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.PresenceDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, entity.PresenceIdle, response.Status)
+}
+
+func TestPresenceController_GetUserPresence_Forbidden(t *testing.T) {
+	mockService := new(tests.MockPresenceService)
+	pc := controller.NewPresenceControllerWithService(mockService)
+	mockService.On("GetPresence", authenticatedUser, "stranger").
+		Return(nil, fmt.Errorf("%w: hidden", service.ErrForbidden))
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/users/stranger/presence", nil)
+	c.Params = gin.Params{{Key: "id", Value: "stranger"}}
+	pc.GetUserPresence(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestPresenceController_GetTeamPresence(t *testing.T) {
+	mockService := new(tests.MockPresenceService)
+	pc := controller.NewPresenceControllerWithService(mockService)
+	roster := []*dto.PresenceDTO{
+		{UserID: authenticatedUser, Status: entity.PresenceOnline},
+		{UserID: "user3", Status: entity.PresenceOffline},
+	}
+	mockService.On("GetTeamPresence", authenticatedUser, tests.TestTeamID).Return(roster, nil)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/teams/"+tests.TestTeamID+"/presence", nil)
+	c.Params = gin.Params{{Key: "id", Value: tests.TestTeamID}}
+	pc.GetTeamPresence(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []dto.PresenceDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response, 2)
+	assert.Equal(t, "user3", response[1].UserID)
+}
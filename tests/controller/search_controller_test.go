@@ -0,0 +1,49 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/search"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchController_Search(t *testing.T) {
+	mockService := new(tests.MockSearchService)
+	sc := controller.NewSearchControllerWithService(mockService)
+	request := &dto.SearchRequest{Query: "graphs", Types: []string{"message", "file"}, TeamID: tests.TestTeamID, Page: 2, Limit: 5}
+	mockService.On("Search", authenticatedUser, request).Return(&dto.SearchResponse{
+		Results:    []*dto.SearchResultDTO{{Type: search.KindMessage, ID: "m1", Snippet: "graphs lecture"}},
+		Page:       2,
+		Limit:      5,
+		TotalCount: 6,
+		TotalPages: 2,
+	}, nil)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/search?q=graphs&types=message,%20file&teamId="+tests.TestTeamID+"&page=2&limit=5", nil)
+	sc.Search(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.SearchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "m1", response.Results[0].ID)
+	assert.Equal(t, 6, response.TotalCount)
+	mockService.AssertExpectations(t)
+}
+
+func TestSearchController_Search_InvalidQuery(t *testing.T) {
+	mockService := new(tests.MockSearchService)
+	sc := controller.NewSearchControllerWithService(mockService)
+	mockService.On("Search", authenticatedUser, &dto.SearchRequest{}).
+		Return(nil, errors.New("validation failed: query must contain at least one letter or digit"))
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/search", nil)
+	sc.Search(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -3,6 +3,7 @@ package controller_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,8 +11,10 @@ import (
 	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -33,6 +36,7 @@ func TestNewTeamController_NewTeam(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 	c.Request, _ = http.NewRequest("POST", "/teams", bytes.NewBuffer(body))
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userClaims", jwt.MapClaims{"sub": reqBody.UserId})
 
 	fakeTeam := &entity.Team{
 		Id:          "team123",
@@ -59,6 +63,7 @@ func TestGetTeam_Success(t *testing.T) {
 	rec := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(rec)
 	c.Params = gin.Params{{Key: "id", Value: "team123"}}
+	c.Set("userClaims", jwt.MapClaims{"sub": "user2"})
 
 	fakeTeam := &entity.Team{
 		Id:          "team123",
@@ -69,13 +74,59 @@ func TestGetTeam_Success(t *testing.T) {
 		TeamTopic:   "Topic1",
 	}
 
-	mockService.On("GetTeamById", "team123").Return(fakeTeam, nil)
+	mockService.On("GetVisibleTeam", "user2", "team123").Return(fakeTeam, nil)
 
 	ctrl.GetTeam(c)
 	assert.Equal(t, http.StatusOK, rec.Code)
 	mockService.AssertExpectations(t)
 }
 
+func TestGetTeam_PrivateTeamNotFound(t *testing.T) {
+	mockService := &tests.MockTeamService{}
+	ctrl := controller.NewTeamControllerWithService(mockService)
+	mockService.On("GetVisibleTeam", authenticatedUser, "team123").
+		Return(nil, fmt.Errorf("%w: team not found", service.ErrResourceNotFound))
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/teams/team123", nil)
+	c.Params = gin.Params{{Key: "id", Value: "team123"}}
+	ctrl.GetTeam(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAllTeams_OnlyVisibleTeams(t *testing.T) {
+	mockService := &tests.MockTeamService{}
+	ctrl := controller.NewTeamControllerWithService(mockService)
+	visible := []*entity.Team{{Id: "team123", IsPublic: true}}
+	mockService.On("GetVisibleTeams", authenticatedUser).Return(visible, nil)
+	mockService.On("GetXVisibleTeamsByPrefix", authenticatedUser, "Te", 5).Return(visible, nil)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/teams", nil)
+	ctrl.GetAllTeams(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	c, w = newAuthenticatedContext(http.MethodGet, "/teams?prefix=Te&limit=5", nil)
+	ctrl.GetAllTeams(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetUsersByTeam_NotMember(t *testing.T) {
+	mockService := &tests.MockTeamService{}
+	ctrl := controller.NewTeamControllerWithService(mockService)
+	mockService.On("GetUsersByTeam", authenticatedUser, "team123").
+		Return(nil, fmt.Errorf("%w: user is not a member of this team", service.ErrForbidden))
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/teams/team123/users", nil)
+	c.Params = gin.Params{{Key: "id", Value: "team123"}}
+	ctrl.GetUsersByTeam(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
 func TestAddUserToTeam_Success(t *testing.T) {
 	mockService := &tests.MockTeamService{}
 	ctrl := controller.NewTeamControllerWithService(mockService)
@@ -91,6 +142,7 @@ func TestAddUserToTeam_Success(t *testing.T) {
 	body, _ := json.Marshal(req)
 	c.Request, _ = http.NewRequest("PUT", "/teams/users", bytes.NewBuffer(body))
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userClaims", jwt.MapClaims{"sub": "user1"})
 
 	fakeUser := &entity.User{
 		ID:        req.UserID,
@@ -102,7 +154,7 @@ func TestAddUserToTeam_Success(t *testing.T) {
 		UsersIds: []string{"user1", req.UserID},
 	}
 
-	mockService.On("AddUserToTeam", req.UserID, req.TeamID).Return(fakeUser, fakeTeam, nil)
+	mockService.On("AddMember", "user1", req.UserID, req.TeamID).Return(fakeUser, fakeTeam, nil)
 
 	ctrl.AddUserToTeam(c)
 	assert.Equal(t, http.StatusOK, rec.Code)
@@ -124,6 +176,7 @@ func TestDeleteUserFromTeam_Success(t *testing.T) {
 	body, _ := json.Marshal(req)
 	c.Request, _ = http.NewRequest("DELETE", "/teams/users", bytes.NewBuffer(body))
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userClaims", jwt.MapClaims{"sub": "user1"})
 
 	fakeUser := &entity.User{
 		ID:        req.UserID,
@@ -135,9 +188,52 @@ func TestDeleteUserFromTeam_Success(t *testing.T) {
 		UsersIds: []string{"user1"},
 	}
 
-	mockService.On("DeleteUserFromTeam", req.UserID, req.TeamID).Return(fakeUser, fakeTeam, nil)
+	mockService.On("RemoveMember", "user1", req.UserID, req.TeamID).Return(fakeUser, fakeTeam, nil)
 
 	ctrl.DeleteUserFromTeam(c)
 	assert.Equal(t, http.StatusOK, rec.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestDeleteTeam_Forbidden(t *testing.T) {
+	mockService := &tests.MockTeamService{}
+	ctrl := controller.NewTeamControllerWithService(mockService)
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "team123"}}
+	c.Set("userClaims", jwt.MapClaims{"sub": "user2"})
+
+	mockService.On("DeleteTeam", "user2", "team123").Return(fmt.Errorf("%w: %s", service.ErrForbidden, "missing team permission"))
+
+	ctrl.DeleteTeam(c)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestTransferOwnership_Success(t *testing.T) {
+	mockService := &tests.MockTeamService{}
+	ctrl := controller.NewTeamControllerWithService(mockService)
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "team123"}}
+	c.Set("userClaims", jwt.MapClaims{"sub": "user1"})
+
+	body, _ := json.Marshal(dto.TransferOwnershipRequest{NewOwnerID: "user2"})
+	c.Request, _ = http.NewRequest("PUT", "/teams/team123/owner", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	fakeTeam := &entity.Team{
+		Id:       "team123",
+		UsersIds: []string{"user1", "user2"},
+		Roles:    map[string]entity.TeamRole{"user1": entity.TeamRoleAdmin, "user2": entity.TeamRoleOwner},
+	}
+	mockService.On("TransferOwnership", "user1", "team123", "user2").Return(fakeTeam, nil)
+
+	ctrl.TransferOwnership(c)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,47 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamRecommendationController_GetRecommendedTeams(t *testing.T) {
+	mockService := new(tests.MockTeamRecommendationService)
+	rc := controller.NewTeamRecommendationControllerWithService(mockService)
+	mockService.On("GetRecommendedTeams", authenticatedUser, 2, 5).Return(&dto.TeamRecommendationsDTO{
+		Teams:      []*dto.TeamRecommendationDTO{{Team: &entity.Team{Id: tests.TestTeamID}, Score: 0.4, MatchesTopic: true}},
+		Page:       2,
+		Limit:      5,
+		TotalCount: 6,
+		TotalPages: 2,
+	}, nil)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/teams/recommended?page=2&limit=5", nil)
+	rc.GetRecommendedTeams(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.TeamRecommendationsDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, tests.TestTeamID, response.Teams[0].Team.Id)
+	assert.True(t, response.Teams[0].MatchesTopic)
+}
+
+func TestTeamRecommendationController_UnknownUser(t *testing.T) {
+	mockService := new(tests.MockTeamRecommendationService)
+	rc := controller.NewTeamRecommendationControllerWithService(mockService)
+	mockService.On("GetRecommendedTeams", authenticatedUser, 1, 0).Return(nil, fmt.Errorf("%w: user not found", service.ErrResourceNotFound))
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/teams/recommended", nil)
+	rc.GetRecommendedTeams(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -3,15 +3,19 @@ package controller_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/SerbanEduard/ProiectColectivBackEnd/controller"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,6 +26,7 @@ func TestCreateTeamRequest(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(rec)
+	c.Set("userClaims", jwt.MapClaims{"sub": "user1"})
 
 	req := dto.TeamRequestCreateDTO{
 		UserID: "user1",
@@ -63,7 +68,8 @@ func TestAcceptTeamRequest(t *testing.T) {
 		UsersIds: []string{"user1"},
 	}
 
-	mockService.On("AcceptTeamRequest", "req123").Return(fakeUser, fakeTeam, nil)
+	c.Set("userClaims", jwt.MapClaims{"sub": "user1"})
+	mockService.On("AcceptTeamRequest", "req123", "user1").Return(fakeUser, fakeTeam, nil)
 
 	ctrl.AcceptTeamRequest(c)
 	assert.Equal(t, http.StatusOK, rec.Code)
@@ -79,7 +85,8 @@ func TestRejectTeamRequest(t *testing.T) {
 	c, _ := gin.CreateTestContext(rec)
 	c.Params = gin.Params{{Key: "id", Value: "req123"}}
 
-	mockService.On("RejectTeamRequest", "req123").Return(nil)
+	c.Set("userClaims", jwt.MapClaims{"sub": "user1"})
+	mockService.On("RejectTeamRequest", "req123", "user1").Return(nil)
 
 	ctrl.RejectTeamRequest(c)
 	assert.Equal(t, http.StatusOK, rec.Code)
@@ -93,13 +100,14 @@ func TestGetAllTeamRequests(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(rec)
+	c.Set("userClaims", jwt.MapClaims{"sub": "user1"})
 
 	fakeRequests := []*entity.TeamRequest{
 		{Id: "req1", UserID: "user1", TeamID: "team123"},
 		{Id: "req2", UserID: "user2", TeamID: "team123"},
 	}
 
-	mockService.On("GetAll").Return(fakeRequests, nil)
+	mockService.On("GetAll", "user1").Return(fakeRequests, nil)
 
 	ctrl.GetAllTeamRequests(c)
 	assert.Equal(t, http.StatusOK, rec.Code)
@@ -113,6 +121,7 @@ func TestGetTeamRequestsByUser(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(rec)
+	c.Set("userClaims", jwt.MapClaims{"sub": "user1"})
 	c.Params = gin.Params{{Key: "userId", Value: "user1"}}
 
 	fakeRequests := []*entity.TeamRequest{
@@ -125,3 +134,117 @@ func TestGetTeamRequestsByUser(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestInviteToTeam(t *testing.T) {
+	mockService := &tests.MockTeamRequestService{}
+	ctrl := controller.NewTeamRequestControllerWithService(mockService)
+
+	body := dto.TeamInviteRequest{Username: tests.TestUsername}
+	c, w := newAuthenticatedContext(http.MethodPost, "/teams/"+tests.TestTeamID+"/invitations", body)
+	c.Params = gin.Params{{Key: "id", Value: tests.TestTeamID}}
+
+	invite := entity.NewTeamInvite("invite1", tests.TestUserID2, tests.TestTeamID, authenticatedUser, time.Now().Add(time.Hour))
+	mockService.On("InviteToTeam", authenticatedUser, tests.TestTeamID, &body).Return(invite, nil)
+
+	ctrl.InviteToTeam(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response dto.TeamRequestItemDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, entity.TeamRequestInvite, response.Type)
+	assert.Equal(t, authenticatedUser, response.InvitedBy)
+}
+
+func TestAcceptInvitation_NotInvitee(t *testing.T) {
+	mockService := &tests.MockTeamRequestService{}
+	ctrl := controller.NewTeamRequestControllerWithService(mockService)
+
+	c, w := newAuthenticatedContext(http.MethodPut, "/teamInvitations/invite1/accept", nil)
+	c.Params = gin.Params{{Key: "id", Value: "invite1"}}
+	mockService.On("AcceptInvitation", "invite1", authenticatedUser).Return(nil, nil, fmt.Errorf("%w: not yours", service.ErrForbidden))
+
+	ctrl.AcceptInvitation(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCreateInviteLink_EmptyBody(t *testing.T) {
+	mockService := &tests.MockTeamRequestService{}
+	ctrl := controller.NewTeamRequestControllerWithService(mockService)
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/teams/"+tests.TestTeamID+"/inviteLinks", nil)
+	c.Params = gin.Params{{Key: "id", Value: tests.TestTeamID}}
+
+	link := entity.NewTeamInviteLink("token1", tests.TestTeamID, authenticatedUser, time.Now().Add(time.Hour), 0)
+	mockService.On("CreateInviteLink", authenticatedUser, tests.TestTeamID, &dto.TeamInviteLinkRequest{}).Return(link, nil)
+
+	ctrl.CreateInviteLink(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response dto.TeamInviteLinkDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "token1", response.Token)
+	assert.Contains(t, response.URL, "token=token1")
+}
+
+func TestJoinByInviteLink_NotFound(t *testing.T) {
+	mockService := &tests.MockTeamRequestService{}
+	ctrl := controller.NewTeamRequestControllerWithService(mockService)
+
+	c, w := newAuthenticatedContext(http.MethodPost, "/inviteLinks/missing/join", nil)
+	c.Params = gin.Params{{Key: "token", Value: "missing"}}
+	mockService.On("JoinByInviteLink", authenticatedUser, "missing").Return(nil, nil, fmt.Errorf("%w: invite link not found", service.ErrResourceNotFound))
+
+	ctrl.JoinByInviteLink(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWithdrawTeamRequest(t *testing.T) {
+	mockService := &tests.MockTeamRequestService{}
+	ctrl := controller.NewTeamRequestControllerWithService(mockService)
+
+	c, w := newAuthenticatedContext(http.MethodPut, "/teamRequests/req1/withdraw", nil)
+	c.Params = gin.Params{{Key: "id", Value: "req1"}}
+	withdrawn := entity.NewTeamRequest("req1", authenticatedUser, tests.TestTeamID, "", time.Now().Add(time.Hour))
+	withdrawn.Resolve(entity.TeamRequestWithdrawn, authenticatedUser, time.Now())
+	mockService.On("WithdrawTeamRequest", "req1", authenticatedUser).Return(withdrawn, nil)
+
+	ctrl.WithdrawTeamRequest(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.TeamRequestItemDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, entity.TeamRequestWithdrawn, response.Status)
+}
+
+func TestGetTeamRequests_PassesStatus(t *testing.T) {
+	mockService := &tests.MockTeamRequestService{}
+	ctrl := controller.NewTeamRequestControllerWithService(mockService)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/teams/"+tests.TestTeamID+"/requests?status=pending", nil)
+	c.Params = gin.Params{{Key: "id", Value: tests.TestTeamID}}
+	mockService.On("GetTeamRequests", authenticatedUser, tests.TestTeamID, entity.TeamRequestPending).Return([]*entity.TeamRequest{&tests.ValidTeamRequest}, nil)
+
+	ctrl.GetTeamRequests(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.TeamRequestsResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Requests, 1)
+	// requests stored before statuses existed are pending
+	assert.Equal(t, entity.TeamRequestPending, response.Requests[0].Status)
+}
+
+func TestGetTeamRequests_Forbidden(t *testing.T) {
+	mockService := &tests.MockTeamRequestService{}
+	ctrl := controller.NewTeamRequestControllerWithService(mockService)
+
+	c, w := newAuthenticatedContext(http.MethodGet, "/teams/"+tests.TestTeamID+"/requests", nil)
+	c.Params = gin.Params{{Key: "id", Value: tests.TestTeamID}}
+	mockService.On("GetTeamRequests", authenticatedUser, tests.TestTeamID, entity.TeamRequestStatus("")).Return(nil, fmt.Errorf("%w: missing team permission", service.ErrForbidden))
+
+	ctrl.GetTeamRequests(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
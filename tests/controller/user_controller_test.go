@@ -131,3 +131,24 @@ func TestUserController_UpdateUserStatistics_Success(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestUserController_VerifyEmail_UsesTheInjectedService(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockVerification := new(tests.MockVerificationService)
+	userController := controller.NewUserControllerWithService(new(tests.MockUserService))
+	userController.SetVerificationService(mockVerification)
+
+	mockVerification.On("VerifyEmail", "token1").Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	jsonData, _ := json.Marshal(dto.VerifyEmailRequest{Token: "token1"})
+	c.Request, _ = http.NewRequest(HTTPMethodPOST, "/users/verify-email", bytes.NewBuffer(jsonData))
+	c.Request.Header.Set(ContentTypeJSON, ContentTypeJSON)
+
+	userController.VerifyEmail(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockVerification.AssertExpectations(t)
+}
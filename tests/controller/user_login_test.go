@@ -15,6 +15,7 @@ import (
 	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -81,3 +82,44 @@ func TestUserController_Login_InvalidCredentials(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestUserController_RefreshToken_Invalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSessionService := new(tests.MockSessionService)
+	userController := controller.NewUserControllerWithService(new(tests.MockUserService))
+	userController.SetSessionService(mockSessionService)
+
+	mockSessionService.On("RefreshSession", "stale").Return(nil, service.ErrInvalidRefreshToken)
+
+	jsonData, _ := json.Marshal(dto.RefreshTokenRequest{RefreshToken: "stale"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/users/refresh", bytes.NewBuffer(jsonData))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	userController.RefreshToken(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockSessionService.AssertExpectations(t)
+}
+
+func TestUserController_LogoutAll_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSessionService := new(tests.MockSessionService)
+	userController := controller.NewUserControllerWithService(new(tests.MockUserService))
+	userController.SetSessionService(mockSessionService)
+
+	mockSessionService.On("LogoutAll", tests.TestUserID).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/users/logout-all", nil)
+	c.Set("userClaims", jwt.MapClaims{"sub": tests.TestUserID})
+
+	userController.LogoutAll(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSessionService.AssertExpectations(t)
+}
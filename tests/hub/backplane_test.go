@@ -0,0 +1,100 @@
+package hub_test
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/hub"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRedisBackplane connects a backplane to server, as one API instance would
+func newRedisBackplane(t *testing.T, server *miniredis.Miniredis) *hub.RedisBackplane {
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return hub.NewRedisBackplane(client)
+}
+
+// newNode starts a hub joined to b as node, serving WebSocket connections
+func newNode(t *testing.T, b hub.Backplane, node string) (*hub.Hub[hub.Message], string) {
+	h := hub.NewHub[hub.Message]()
+	assert.NoError(t, h.SetBackplane(b, node))
+	t.Cleanup(func() { b.Leave(node) })
+	return h, serveHub(t, h)
+}
+
+func testFanOutAcrossNodes(t *testing.T, backplaneA, backplaneB hub.Backplane) {
+	hubA, urlA := newNode(t, backplaneA, "a")
+	hubB, urlB := newNode(t, backplaneB, "b")
+
+	onA := dial(t, urlA, "user1")
+	onB := dial(t, urlB, "user1")
+	other := dial(t, urlB, "user2")
+	waitFor(t, func() bool {
+		return hubA.ConnectionCount("user1") == 1 && hubB.ConnectionCount("user1") == 1 && hubB.ConnectionCount("user2") == 1
+	})
+
+	hubA.SendMany([]string{"user1", "user2", "offline"}, *hub.NewMessage(hub.TeamBroadcast, "hello"))
+
+	assert.Equal(t, "hello", readMessage(t, onA).Payload)
+	assert.Equal(t, "hello", readMessage(t, onB).Payload)
+	assert.Equal(t, "hello", readMessage(t, other).Payload)
+	assert.True(t, hubA.IsOnline("user2"))
+	assert.False(t, hubA.IsOnline("offline"))
+
+	assert.NoError(t, other.Close())
+	waitFor(t, func() bool { return !hubA.IsOnline("user2") })
+}
+
+func TestLocalBackplane_FansOutAcrossNodes(t *testing.T) {
+	b := hub.NewLocalBackplane()
+	testFanOutAcrossNodes(t, b, b)
+}
+
+func TestRedisBackplane_FansOutAcrossNodes(t *testing.T) {
+	server := miniredis.RunT(t)
+	testFanOutAcrossNodes(t, newRedisBackplane(t, server), newRedisBackplane(t, server))
+}
+
+func TestRedisBackplane_RoutesSkipNodesThatStopped(t *testing.T) {
+	server := miniredis.RunT(t)
+	live, crashed := newRedisBackplane(t, server), newRedisBackplane(t, server)
+	assert.NoError(t, live.Join("live", func(hub.Delivery) {}))
+	assert.NoError(t, crashed.Join("crashed", func(hub.Delivery) {}))
+	t.Cleanup(func() { live.Leave("live") })
+	assert.NoError(t, live.SetRoute("user1", "live", true))
+	assert.NoError(t, crashed.SetRoute("user1", "crashed", true))
+
+	routes, err := live.Routes([]string{"user1"})
+	assert.NoError(t, err)
+	sort.Strings(routes["user1"])
+	assert.Equal(t, []string{"crashed", "live"}, routes["user1"])
+
+	// the crashed node never refreshes its liveness key again, while the live one does
+	server.FastForward(20 * time.Second)
+	assert.NoError(t, server.Set("hub:alive:live", "1"))
+	server.FastForward(20 * time.Second)
+
+	routes, err = live.Routes([]string{"user1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"live"}, routes["user1"])
+	members, _ := server.SMembers("hub:routes:user1")
+	assert.Equal(t, []string{"live"}, members)
+}
+
+func TestRedisBackplane_LeaveRemovesRoutes(t *testing.T) {
+	server := miniredis.RunT(t)
+	b := newRedisBackplane(t, server)
+	assert.NoError(t, b.Join("a", func(hub.Delivery) {}))
+	assert.NoError(t, b.SetRoute("user1", "a", true))
+
+	assert.NoError(t, b.Leave("a"))
+
+	routes, err := b.Routes([]string{"user1"})
+	assert.NoError(t, err)
+	assert.Empty(t, routes)
+	assert.False(t, server.Exists("hub:alive:a"))
+}
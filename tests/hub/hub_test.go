@@ -0,0 +1,121 @@
+package hub_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/hub"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// presenceLog records presence events in the order the hub reports them
+type presenceLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (p *presenceLog) handle(clientID string, online bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := "offline"
+	if online {
+		state = "online"
+	}
+	p.events = append(p.events, clientID+" "+state)
+}
+
+func (p *presenceLog) list() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.events...)
+}
+
+// serveHub registers every connection to /connect?as=<id> with the hub, replaying from since when it is given
+func serveHub(t *testing.T, h *hub.Hub[hub.Message]) string {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/connect", func(c *gin.Context) {
+		conn, err := hub.AcceptConnection(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		client := hub.NewClient[hub.Message](c.Query("as"), conn)
+		if since := c.Query("since"); since != "" {
+			seq, _ := strconv.ParseInt(since, 10, 64)
+			h.RegisterSince(client, seq)
+			return
+		}
+		h.Register(client)
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/connect?as="
+}
+
+func dial(t *testing.T, url, clientID string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(url+clientID, nil)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn) hub.Message {
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var msg hub.Message
+	assert.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func TestHub_DeliversToEveryConnectionOfAUser(t *testing.T) {
+	h := hub.NewHub[hub.Message]()
+	url := serveHub(t, h)
+	laptop := dial(t, url, "user1")
+	phone := dial(t, url, "user1")
+	waitFor(t, func() bool { return h.ConnectionCount("user1") == 2 })
+
+	h.Send("user1", *hub.NewMessage(hub.DirectMessage, "hi"))
+
+	assert.Equal(t, "hi", readMessage(t, laptop).Payload)
+	assert.Equal(t, "hi", readMessage(t, phone).Payload)
+}
+
+func TestHub_ClosingOneConnectionKeepsTheOthers(t *testing.T) {
+	h := hub.NewHub[hub.Message]()
+	presence := &presenceLog{}
+	h.SetPresenceHandler(presence.handle)
+	url := serveHub(t, h)
+
+	laptop := dial(t, url, "user1")
+	phone := dial(t, url, "user1")
+	waitFor(t, func() bool { return h.ConnectionCount("user1") == 2 })
+
+	assert.NoError(t, laptop.Close())
+	waitFor(t, func() bool { return h.ConnectionCount("user1") == 1 })
+	assert.True(t, h.IsOnline("user1"))
+
+	h.Send("user1", *hub.NewMessage(hub.DirectMessage, "still here"))
+	assert.Equal(t, "still here", readMessage(t, phone).Payload)
+
+	assert.NoError(t, phone.Close())
+	waitFor(t, func() bool { return !h.IsOnline("user1") })
+	waitFor(t, func() bool { return len(presence.list()) == 2 })
+	assert.Equal(t, []string{"user1 online", "user1 offline"}, presence.list())
+}
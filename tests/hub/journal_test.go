@@ -0,0 +1,117 @@
+package hub_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/hub"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func newJournaledHub(t *testing.T) *hub.Hub[hub.Message] {
+	store, err := persistence.NewLocalStore("")
+	assert.NoError(t, err)
+	h := hub.NewHub[hub.Message]()
+	h.SetJournal(hub.NewMessageJournal(persistence.NewLocalHubEventRepository(store)))
+	return h
+}
+
+// trimmedStore has lost every event before seq 5
+type trimmedStore struct{}
+
+func (trimmedStore) Append(string, json.RawMessage) (int64, error) { return 6, nil }
+
+func (trimmedStore) ListSince(clientID string, seq int64, limit int) ([]*entity.HubEvent, error) {
+	return []*entity.HubEvent{entity.NewHubEvent(clientID, 5, []byte(`{"type":"direct_message"}`))}, nil
+}
+
+func readCloseCode(t *testing.T, conn *websocket.Conn) int {
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		_, _, err := conn.ReadMessage()
+		if closeErr, ok := err.(*websocket.CloseError); ok {
+			return closeErr.Code
+		}
+		if err != nil {
+			return 0
+		}
+	}
+}
+
+func TestHub_ReplaysMissedMessagesOnReconnect(t *testing.T) {
+	h := newJournaledHub(t)
+	url := serveHub(t, h)
+
+	for _, text := range []string{"one", "two", "three"} {
+		h.Send("user1", *hub.NewMessage(hub.DirectMessage, text))
+	}
+
+	conn := dial(t, url, "user1&since=1")
+	first := readMessage(t, conn)
+	second := readMessage(t, conn)
+	assert.Equal(t, int64(2), first.Seq)
+	assert.Equal(t, "two", first.Payload)
+	assert.Equal(t, int64(3), second.Seq)
+	assert.Equal(t, "three", second.Payload)
+
+	h.Send("user1", *hub.NewMessage(hub.DirectMessage, "live"))
+	live := readMessage(t, conn)
+	assert.Equal(t, int64(4), live.Seq)
+	assert.Equal(t, "live", live.Payload)
+}
+
+func TestHub_DoesNotJournalTyping(t *testing.T) {
+	h := newJournaledHub(t)
+	url := serveHub(t, h)
+
+	h.Send("user1", *hub.NewMessage(hub.Typing, "typing"))
+	h.Send("user1", *hub.NewMessage(hub.DirectMessage, "hi"))
+
+	conn := dial(t, url, "user1&since=0")
+	msg := readMessage(t, conn)
+	assert.Equal(t, int64(1), msg.Seq)
+	assert.Equal(t, hub.DirectMessage, msg.Type)
+}
+
+func TestHub_ClosesWhenMissedMessagesExpired(t *testing.T) {
+	h := hub.NewHub[hub.Message]()
+	h.SetJournal(hub.NewMessageJournal(trimmedStore{}))
+	url := serveHub(t, h)
+
+	conn := dial(t, url, "user1&since=2")
+
+	assert.Equal(t, hub.CloseBacklogExpired, readCloseCode(t, conn))
+	waitFor(t, func() bool { return !h.IsOnline("user1") })
+}
+
+func TestHub_DisconnectsSlowConsumerWithoutLosingMessages(t *testing.T) {
+	h := newJournaledHub(t)
+	url := serveHub(t, h)
+	stalled := dial(t, url, "user1")
+	waitFor(t, func() bool { return h.IsOnline("user1") })
+
+	// the client never reads, so once the socket buffers fill the outbound buffer overflows
+	payload := strings.Repeat("x", 64*1024)
+	sent := 0
+	for h.IsOnline("user1") && sent < 2000 {
+		h.Send("user1", *hub.NewMessage(hub.DirectMessage, payload))
+		sent++
+	}
+	waitFor(t, func() bool { return !h.IsOnline("user1") })
+	assert.NoError(t, stalled.Close())
+
+	// the replay is several megabytes, so allow more than readMessage does
+	conn := dial(t, url, "user1&since=0")
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(30*time.Second)))
+	for seq := 1; seq <= sent; seq++ {
+		var msg hub.Message
+		if !assert.NoError(t, conn.ReadJSON(&msg)) || !assert.Equal(t, int64(seq), msg.Seq) {
+			return
+		}
+	}
+}
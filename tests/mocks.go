@@ -1,9 +1,14 @@
 package tests
 
 import (
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/hub"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -58,6 +63,14 @@ func (m *MockUserRepository) GetAll() ([]*entity.User, error) {
 	return args.Get(0).([]*entity.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetByIDs(ids []string) ([]*entity.User, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.User), args.Error(1)
+}
+
 type MockUserService struct {
 	mock.Mock
 }
@@ -144,8 +157,8 @@ func (m *MockUserService) UpdateUserProfile(userID string, req *dto.UserUpdateRe
 	return args.Get(0).(*dto.UserUpdateResponseDTO), args.Error(1)
 }
 
-func (m *MockUserService) UpdateUserPassword(userID string, req *dto.UserPasswordRequestDTO) error {
-	args := m.Called(userID, req)
+func (m *MockUserService) UpdateUserPassword(userID, sessionID string, req *dto.UserPasswordRequestDTO) error {
+	args := m.Called(userID, sessionID, req)
 	return args.Error(0)
 }
 
@@ -222,6 +235,59 @@ func (m *MockFriendRequestService) GetMutualFriends(userA, userB string) ([]*ent
 	return args.Get(0).([]*entity.User), args.Error(1)
 }
 
+// MockUnitOfWork records the staged writes; Factory hands out the same instance for every Begin.
+// UpdateUser and UpdateTeam apply the change to the document given to Return, as a commit would to the
+// stored one, and Commit returns the first error a change returned.
+type MockUnitOfWork struct {
+	mock.Mock
+	changeErr error
+}
+
+func (m *MockUnitOfWork) Factory() persistence.UnitOfWorkFactory {
+	return persistence.UnitOfWorkFunc(func() persistence.UnitOfWork {
+		m.changeErr = nil
+		return m
+	})
+}
+
+func (m *MockUnitOfWork) SaveUser(user *entity.User) {
+	m.Called(user)
+}
+
+func (m *MockUnitOfWork) SaveTeam(team *entity.Team) {
+	m.Called(team)
+}
+
+func (m *MockUnitOfWork) UpdateUser(id string, change func(user *entity.User) error) {
+	args := m.Called(id)
+	if user, ok := args.Get(0).(*entity.User); ok && m.changeErr == nil {
+		m.changeErr = change(user)
+	}
+}
+
+func (m *MockUnitOfWork) UpdateTeam(id string, change func(team *entity.Team) error) {
+	args := m.Called(id)
+	if team, ok := args.Get(0).(*entity.Team); ok && m.changeErr == nil {
+		m.changeErr = change(team)
+	}
+}
+
+func (m *MockUnitOfWork) DeleteUser(id string) {
+	m.Called(id)
+}
+
+func (m *MockUnitOfWork) DeleteTeam(id string) {
+	m.Called(id)
+}
+
+func (m *MockUnitOfWork) Commit() error {
+	args := m.Called()
+	if m.changeErr != nil {
+		return m.changeErr
+	}
+	return args.Error(0)
+}
+
 type MockTeamRepository struct {
 	mock.Mock
 }
@@ -364,6 +430,14 @@ func (m *MockFileRepository) GetByContextID(contextType, contextID string) ([]*e
 	return args.Get(0).([]*entity.File), args.Error(1)
 }
 
+func (m *MockFileRepository) GetPageByContextID(contextType, contextID string, offset, limit int) ([]*entity.File, int, error) {
+	args := m.Called(contextType, contextID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*entity.File), args.Int(1), args.Error(2)
+}
+
 // MockQuizService is used by controller tests to mock service layer behavior.
 type MockQuizService struct {
 	mock.Mock
@@ -467,24 +541,24 @@ func (m *MockEventService) CreateEvent(request *dto.CreateEventRequest) (*dto.Ev
 	return args.Get(0).(*dto.EventDTO), args.Error(1)
 }
 
-func (m *MockEventService) GetEventById(id string) (*dto.EventDTO, error) {
-	args := m.Called(id)
+func (m *MockEventService) GetEventById(userID, id string) (*dto.EventDTO, error) {
+	args := m.Called(userID, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*dto.EventDTO), args.Error(1)
 }
 
-func (m *MockEventService) GetEventsByTeamId(teamID string) ([]*dto.EventDTO, error) {
-	args := m.Called(teamID)
+func (m *MockEventService) GetEventsByTeamId(userID, teamID string) ([]*dto.EventDTO, error) {
+	args := m.Called(userID, teamID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*dto.EventDTO), args.Error(1)
 }
 
-func (m *MockEventService) UpdateEventDetails(id string, request *dto.UpdateEventRequest) (*dto.EventDTO, error) {
-	args := m.Called(id, request)
+func (m *MockEventService) UpdateEventDetails(userID, id string, request *dto.UpdateEventRequest) (*dto.EventDTO, error) {
+	args := m.Called(userID, id, request)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -499,8 +573,8 @@ func (m *MockEventService) UpdateUserStatus(id string, request *dto.UpdateEventS
 	return args.Get(0).(*dto.EventDTO), args.Error(1)
 }
 
-func (m *MockEventService) DeleteEvent(id string) error {
-	args := m.Called(id)
+func (m *MockEventService) DeleteEvent(id, userID string) error {
+	args := m.Called(id, userID)
 	return args.Error(0)
 }
 
@@ -574,14 +648,91 @@ func (m *MockTeamService) GetXTeamsByPrefix(prefix string, x int) ([]*entity.Tea
 	return args.Get(0).([]*entity.Team), args.Error(1)
 }
 
-func (m *MockTeamService) GetUsersByTeam(idTeam string) ([]*dto.UserResponse, error) {
-	args := m.Called(idTeam)
+func (m *MockTeamService) AddMember(actorID, idUser, idTeam string) (*entity.User, *entity.Team, error) {
+	args := m.Called(actorID, idUser, idTeam)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*entity.User), args.Get(1).(*entity.Team), args.Error(2)
+}
+
+func (m *MockTeamService) RemoveMember(actorID, idUser, idTeam string) (*entity.User, *entity.Team, error) {
+	args := m.Called(actorID, idUser, idTeam)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*entity.User), args.Get(1).(*entity.Team), args.Error(2)
+}
+
+func (m *MockTeamService) UpdateTeam(actorID, idTeam string, changes *dto.UpdateTeamRequest) (*entity.Team, error) {
+	args := m.Called(actorID, idTeam, changes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Team), args.Error(1)
+}
+
+func (m *MockTeamService) DeleteTeam(actorID, idTeam string) error {
+	args := m.Called(actorID, idTeam)
+	return args.Error(0)
+}
+
+func (m *MockTeamService) SetMemberRole(actorID, idTeam, idUser string, role entity.TeamRole) (*entity.Team, error) {
+	args := m.Called(actorID, idTeam, idUser, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Team), args.Error(1)
+}
+
+func (m *MockTeamService) TransferOwnership(actorID, idTeam, newOwnerID string) (*entity.Team, error) {
+	args := m.Called(actorID, idTeam, newOwnerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Team), args.Error(1)
+}
+
+func (m *MockTeamService) GetUsersByTeam(actorID, idTeam string) ([]*dto.UserResponse, error) {
+	args := m.Called(actorID, idTeam)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*dto.UserResponse), args.Error(1)
 }
 
+func (m *MockTeamService) GetVisibleTeam(actorID, id string) (*entity.Team, error) {
+	args := m.Called(actorID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Team), args.Error(1)
+}
+
+func (m *MockTeamService) GetVisibleTeams(actorID string) ([]*entity.Team, error) {
+	args := m.Called(actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Team), args.Error(1)
+}
+
+func (m *MockTeamService) GetVisibleTeamsByName(actorID, name string) ([]*entity.Team, error) {
+	args := m.Called(actorID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Team), args.Error(1)
+}
+
+func (m *MockTeamService) GetXVisibleTeamsByPrefix(actorID, prefix string, x int) ([]*entity.Team, error) {
+	args := m.Called(actorID, prefix, x)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Team), args.Error(1)
+}
+
 // --- TeamRequest mocks ---
 
 type MockTeamRequestRepository struct {
@@ -617,6 +768,24 @@ func (m *MockTeamRequestRepository) GetByUserId(userID string) ([]*entity.TeamRe
 	return args.Get(0).([]*entity.TeamRequest), args.Error(1)
 }
 
+func (m *MockTeamRequestRepository) GetByTeamId(teamID string) ([]*entity.TeamRequest, error) {
+	args := m.Called(teamID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.TeamRequest), args.Error(1)
+}
+
+func (m *MockTeamRequestRepository) Update(req *entity.TeamRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockTeamRequestRepository) UpdateIfStatus(req *entity.TeamRequest, status entity.TeamRequestStatus) (bool, error) {
+	args := m.Called(req, status)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockTeamRequestRepository) Delete(id string) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -634,21 +803,37 @@ func (m *MockTeamRequestService) CreateTeamRequest(req *dto.TeamRequestCreateDTO
 	return args.Get(0).(*entity.TeamRequest), args.Error(1)
 }
 
-func (m *MockTeamRequestService) AcceptTeamRequest(id string) (*entity.User, *entity.Team, error) {
-	args := m.Called(id)
+func (m *MockTeamRequestService) AcceptTeamRequest(id, reviewerID string) (*entity.User, *entity.Team, error) {
+	args := m.Called(id, reviewerID)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(2)
 	}
 	return args.Get(0).(*entity.User), args.Get(1).(*entity.Team), args.Error(2)
 }
 
-func (m *MockTeamRequestService) RejectTeamRequest(id string) error {
-	args := m.Called(id)
+func (m *MockTeamRequestService) RejectTeamRequest(id, reviewerID string) error {
+	args := m.Called(id, reviewerID)
 	return args.Error(0)
 }
 
-func (m *MockTeamRequestService) GetAll() ([]*entity.TeamRequest, error) {
-	args := m.Called()
+func (m *MockTeamRequestService) WithdrawTeamRequest(id, userID string) (*entity.TeamRequest, error) {
+	args := m.Called(id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TeamRequest), args.Error(1)
+}
+
+func (m *MockTeamRequestService) GetTeamRequests(actorID, teamID string, status entity.TeamRequestStatus) ([]*entity.TeamRequest, error) {
+	args := m.Called(actorID, teamID, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.TeamRequest), args.Error(1)
+}
+
+func (m *MockTeamRequestService) GetAll(actorID string) ([]*entity.TeamRequest, error) {
+	args := m.Called(actorID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -662,3 +847,744 @@ func (m *MockTeamRequestService) GetByUserId(userID string) ([]*entity.TeamReque
 	}
 	return args.Get(0).([]*entity.TeamRequest), args.Error(1)
 }
+
+func (m *MockTeamRequestService) InviteToTeam(inviterID, teamID string, request *dto.TeamInviteRequest) (*entity.TeamRequest, error) {
+	args := m.Called(inviterID, teamID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TeamRequest), args.Error(1)
+}
+
+func (m *MockTeamRequestService) GetInvitations(userID string) ([]*entity.TeamRequest, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.TeamRequest), args.Error(1)
+}
+
+func (m *MockTeamRequestService) AcceptInvitation(id, userID string) (*entity.User, *entity.Team, error) {
+	args := m.Called(id, userID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*entity.User), args.Get(1).(*entity.Team), args.Error(2)
+}
+
+func (m *MockTeamRequestService) DeclineInvitation(id, userID string) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *MockTeamRequestService) CreateInviteLink(actorID, teamID string, request *dto.TeamInviteLinkRequest) (*entity.TeamInviteLink, error) {
+	args := m.Called(actorID, teamID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TeamInviteLink), args.Error(1)
+}
+
+func (m *MockTeamRequestService) GetInviteLinks(actorID, teamID string) ([]*entity.TeamInviteLink, error) {
+	args := m.Called(actorID, teamID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.TeamInviteLink), args.Error(1)
+}
+
+func (m *MockTeamRequestService) RevokeInviteLink(actorID, teamID, token string) error {
+	args := m.Called(actorID, teamID, token)
+	return args.Error(0)
+}
+
+func (m *MockTeamRequestService) JoinByInviteLink(userID, token string) (*entity.User, *entity.Team, error) {
+	args := m.Called(userID, token)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*entity.User), args.Get(1).(*entity.Team), args.Error(2)
+}
+
+type MockTeamInviteLinkRepository struct {
+	mock.Mock
+}
+
+func (m *MockTeamInviteLinkRepository) Create(link *entity.TeamInviteLink) error {
+	args := m.Called(link)
+	return args.Error(0)
+}
+
+func (m *MockTeamInviteLinkRepository) GetByID(id string) (*entity.TeamInviteLink, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TeamInviteLink), args.Error(1)
+}
+
+func (m *MockTeamInviteLinkRepository) GetByTeamID(teamID string) ([]*entity.TeamInviteLink, error) {
+	args := m.Called(teamID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.TeamInviteLink), args.Error(1)
+}
+
+func (m *MockTeamInviteLinkRepository) Redeem(id string, now time.Time) (*entity.TeamInviteLink, error) {
+	args := m.Called(id, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TeamInviteLink), args.Error(1)
+}
+
+func (m *MockTeamInviteLinkRepository) Release(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockTeamInviteLinkRepository) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// --- Session mocks ---
+
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(session *entity.Session) error {
+	args := m.Called(session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) GetByID(id string) (*entity.Session, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) GetByUserID(userId string) ([]*entity.Session, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Update(session *entity.Session) error {
+	args := m.Called(session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) RevokeToken(token *entity.RevokedToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) IsTokenRevoked(jti string) (bool, error) {
+	args := m.Called(jti)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) StartSession(user *entity.User) (*dto.LoginResponse, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.LoginResponse), args.Error(1)
+}
+
+func (m *MockSessionService) RefreshSession(refreshToken string) (*dto.LoginResponse, error) {
+	args := m.Called(refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.LoginResponse), args.Error(1)
+}
+
+func (m *MockSessionService) Logout(claims jwt.MapClaims) error {
+	args := m.Called(claims)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) LogoutAll(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) LogoutOthers(userID, keepSessionID string) error {
+	args := m.Called(userID, keepSessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) ValidateAccessToken(claims jwt.MapClaims) error {
+	args := m.Called(claims)
+	return args.Error(0)
+}
+
+// --- Verification mocks ---
+
+type MockUserTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserTokenRepository) Create(token *entity.UserToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockUserTokenRepository) GetByID(id string) (*entity.UserToken, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserToken), args.Error(1)
+}
+
+func (m *MockUserTokenRepository) Update(token *entity.UserToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockUserTokenRepository) MarkUsed(id string) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserTokenRepository) InvalidateByUserID(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(to, subject, body string) error {
+	args := m.Called(to, subject, body)
+	return args.Error(0)
+}
+
+type MockVerificationService struct {
+	mock.Mock
+}
+
+func (m *MockVerificationService) SendEmailVerification(user *entity.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockVerificationService) ResendEmailVerification(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockVerificationService) VerifyEmail(token string) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockVerificationService) InvalidateTokens(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockVerificationService) RequestPasswordReset(email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockVerificationService) ResetPassword(request *dto.ResetPasswordRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+type MockMessageRepository struct {
+	mock.Mock
+}
+
+func (m *MockMessageRepository) Create(message *entity.Message) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) GetByID(id string) (*entity.Message, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetByConversation(user1Id, user2Id string) ([]*entity.Message, error) {
+	args := m.Called(user1Id, user2Id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetByTeamID(teamId string) ([]*entity.Message, error) {
+	args := m.Called(teamId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetConversationPage(user1Id, user2Id string, query persistence.MessagePageQuery) ([]*entity.Message, error) {
+	args := m.Called(user1Id, user2Id, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetTeamPage(teamId string, query persistence.MessagePageQuery) ([]*entity.Message, error) {
+	args := m.Called(teamId, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetRepliesPage(parentId string, query persistence.MessagePageQuery) ([]*entity.Message, error) {
+	args := m.Called(parentId, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountConversation(user1Id, user2Id string, query persistence.MessageCountQuery) (int, error) {
+	args := m.Called(user1Id, user2Id, query)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountTeam(teamId string, query persistence.MessageCountQuery) (int, error) {
+	args := m.Called(teamId, query)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMessageRepository) Update(id string, updates map[string]interface{}) error {
+	args := m.Called(id, updates)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+type MockMessageService struct {
+	mock.Mock
+}
+
+func (m *MockMessageService) CreateDirectMessage(request *dto.DirectMessageRequest) (*dto.MessageDTO, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.MessageDTO), args.Error(1)
+}
+
+func (m *MockMessageService) CreateTeamMessage(request *dto.TeamMessageRequest) (*dto.MessageDTO, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.MessageDTO), args.Error(1)
+}
+
+func (m *MockMessageService) GetMessageByID(userID, id string) (*dto.MessageDTO, error) {
+	args := m.Called(userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.MessageDTO), args.Error(1)
+}
+
+func (m *MockMessageService) GetDirectMessages(user1Id, user2Id string, page dto.MessagePageRequest) (*dto.MessagePageDTO, error) {
+	args := m.Called(user1Id, user2Id, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.MessagePageDTO), args.Error(1)
+}
+
+func (m *MockMessageService) GetTeamMessages(userID, teamId string, page dto.MessagePageRequest) (*dto.MessagePageDTO, error) {
+	args := m.Called(userID, teamId, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.MessagePageDTO), args.Error(1)
+}
+
+func (m *MockMessageService) EditMessage(actorID, id string, request *dto.EditMessageRequest) (*dto.MessageDTO, []string, error) {
+	args := m.Called(actorID, id, request)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*dto.MessageDTO), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockMessageService) DeleteMessage(actorID, id string) (*dto.MessageDTO, []string, error) {
+	args := m.Called(actorID, id)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*dto.MessageDTO), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockMessageService) Typing(userID string, request *dto.TypingRequest) (*dto.TypingDTO, []string, error) {
+	args := m.Called(userID, request)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*dto.TypingDTO), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockMessageService) MarkRead(userID string, request *dto.ReadMessageRequest) (*dto.ReadReceiptDTO, []string, error) {
+	args := m.Called(userID, request)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*dto.ReadReceiptDTO), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockMessageService) ToggleReaction(userID string, request *dto.ReactionRequest) (*dto.MessageDTO, []string, error) {
+	args := m.Called(userID, request)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*dto.MessageDTO), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockMessageService) GetReplies(userID, parentID string, page dto.MessagePageRequest) (*dto.MessagePageDTO, error) {
+	args := m.Called(userID, parentID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.MessagePageDTO), args.Error(1)
+}
+
+func (m *MockMessageService) GetUnreadCounts(userID string) (*dto.UnreadCountsDTO, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UnreadCountsDTO), args.Error(1)
+}
+
+type MockFileService struct {
+	mock.Mock
+}
+
+func (m *MockFileService) CreateFile(request *dto.FileUploadRequest, userID string) (*dto.FileUploadResponse, error) {
+	args := m.Called(request, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.FileUploadResponse), args.Error(1)
+}
+
+func (m *MockFileService) GetFileByID(id, userID string) (*entity.File, error) {
+	args := m.Called(id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.File), args.Error(1)
+}
+
+func (m *MockFileService) GetFilesByTeam(teamID, userID string, page, limit int) (*dto.FileListResponse, error) {
+	args := m.Called(teamID, userID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.FileListResponse), args.Error(1)
+}
+
+func (m *MockFileService) GetFilesByConversation(otherUserID, userID string, page, limit int) (*dto.FileListResponse, error) {
+	args := m.Called(otherUserID, userID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.FileListResponse), args.Error(1)
+}
+
+func (m *MockFileService) GetConversationFile(otherUserID, id, userID string) (*entity.File, error) {
+	args := m.Called(otherUserID, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.File), args.Error(1)
+}
+
+func (m *MockFileService) DeleteConversationFile(otherUserID, id, userID string) error {
+	args := m.Called(otherUserID, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockFileService) DeleteFile(id, userID string) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+type MockPresenceRepository struct {
+	mock.Mock
+}
+
+func (m *MockPresenceRepository) Save(presence *entity.Presence) error {
+	args := m.Called(presence)
+	return args.Error(0)
+}
+
+func (m *MockPresenceRepository) GetByUserID(userID string) (*entity.Presence, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Presence), args.Error(1)
+}
+
+func (m *MockPresenceRepository) GetByUserIDs(userIDs []string) ([]*entity.Presence, error) {
+	args := m.Called(userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Presence), args.Error(1)
+}
+
+type MockReadCursorRepository struct {
+	mock.Mock
+}
+
+func (m *MockReadCursorRepository) Save(cursor *entity.ReadCursor) error {
+	args := m.Called(cursor)
+	return args.Error(0)
+}
+
+func (m *MockReadCursorRepository) Get(userID, thread string) (*entity.ReadCursor, error) {
+	args := m.Called(userID, thread)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ReadCursor), args.Error(1)
+}
+
+func (m *MockReadCursorRepository) GetByUser(userID string) ([]*entity.ReadCursor, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.ReadCursor), args.Error(1)
+}
+
+type MockPresenceService struct {
+	mock.Mock
+}
+
+func (m *MockPresenceService) SetStatus(userID string, status entity.PresenceStatus) (*dto.PresenceDTO, []string, error) {
+	args := m.Called(userID, status)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*dto.PresenceDTO), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockPresenceService) UpdatePresence(userID string, request *dto.SetPresenceRequest) (*dto.PresenceDTO, []string, error) {
+	args := m.Called(userID, request)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*dto.PresenceDTO), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockPresenceService) GetPresence(requesterID, userID string) (*dto.PresenceDTO, error) {
+	args := m.Called(requesterID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PresenceDTO), args.Error(1)
+}
+
+func (m *MockPresenceService) GetTeamPresence(requesterID, teamID string) ([]*dto.PresenceDTO, error) {
+	args := m.Called(requesterID, teamID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.PresenceDTO), args.Error(1)
+}
+
+// MockSearchService is used for search controller tests
+type MockSearchService struct {
+	mock.Mock
+}
+
+func (m *MockSearchService) Search(userID string, request *dto.SearchRequest) (*dto.SearchResponse, error) {
+	args := m.Called(userID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.SearchResponse), args.Error(1)
+}
+
+// MockTeamRecommendationService is used for team recommendation controller tests
+type MockTeamRecommendationService struct {
+	mock.Mock
+}
+
+func (m *MockTeamRecommendationService) GetRecommendedTeams(userID string, page, limit int) (*dto.TeamRecommendationsDTO, error) {
+	args := m.Called(userID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TeamRecommendationsDTO), args.Error(1)
+}
+
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) Create(notification *entity.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetByID(id string) (*entity.Notification, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetByUserID(userID string) ([]*entity.Notification, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) Update(notification *entity.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) CountUnread(userID string) (map[entity.NotificationType]int, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[entity.NotificationType]int), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkAllRead(userID string, readAt time.Time) (int, error) {
+	args := m.Called(userID, readAt)
+	return args.Int(0), args.Error(1)
+}
+
+// MockNotifier records notifications; recipients are passed to Called as one []string
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(notification entity.Notification, recipients ...string) {
+	m.Called(notification, recipients)
+}
+
+type MockNotificationSender struct {
+	mock.Mock
+}
+
+func (m *MockNotificationSender) Send(clientID string, msg hub.Message) {
+	m.Called(clientID, msg)
+}
+
+type MockNotificationService struct {
+	MockNotifier
+}
+
+func (m *MockNotificationService) GetNotifications(userID string, unreadOnly bool, page, limit int) (*dto.NotificationsPageDTO, error) {
+	args := m.Called(userID, unreadOnly, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.NotificationsPageDTO), args.Error(1)
+}
+
+func (m *MockNotificationService) GetUnreadCounts(userID string) (*dto.UnreadNotificationsDTO, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UnreadNotificationsDTO), args.Error(1)
+}
+
+func (m *MockNotificationService) MarkRead(userID, id string) (*entity.Notification, error) {
+	args := m.Called(userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Notification), args.Error(1)
+}
+
+func (m *MockNotificationService) MarkAllRead(userID string) (*dto.NotificationsReadDTO, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.NotificationsReadDTO), args.Error(1)
+}
+
+func (m *MockNotificationService) GetPreferences(userID string) (*dto.NotificationPreferencesDTO, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.NotificationPreferencesDTO), args.Error(1)
+}
+
+func (m *MockNotificationService) UpdatePreferences(userID string, request *dto.NotificationPreferencesRequest) (*dto.NotificationPreferencesDTO, error) {
+	args := m.Called(userID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.NotificationPreferencesDTO), args.Error(1)
+}
+
+type MockNotificationPreferencesRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationPreferencesRepository) Save(preferences *entity.NotificationPreferences) error {
+	args := m.Called(preferences)
+	return args.Error(0)
+}
+
+func (m *MockNotificationPreferencesRepository) GetByUserID(userID string) (*entity.NotificationPreferences, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.NotificationPreferences), args.Error(1)
+}
+
+type MockPushSender struct {
+	mock.Mock
+}
+
+func (m *MockPushSender) Push(userID string, notification *entity.Notification) error {
+	args := m.Called(userID, notification)
+	return args.Error(0)
+}
@@ -0,0 +1,109 @@
+package persistence_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackfill_MessageThreadKeys(t *testing.T) {
+	store := newMemoryStore(t)
+	// written before history was paged by threadKey
+	assert.NoError(t, store.Put("messages", "m1", map[string]interface{}{
+		"id": "m1", "senderId": "u1", "teamId": tests.TestTeamID, "textContent": "hi", "timestamp": "2024-05-01T10:00:00Z",
+	}))
+	assert.NoError(t, persistence.NewLocalMessageRepository(store).Create(entity.NewMessage("m2", "u1", "", tests.TestTeamID, "hey")))
+	backend := persistence.NewLocalBackend(store)
+
+	reports, err := persistence.Backfill(backend, persistence.BackfillOptions{BatchSize: 1, DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, persistence.BackfillReport{Collection: "messages", Name: "message thread keys", Checked: 2, Patched: 1}, reports[0])
+	var message entity.Message
+	_, err = store.Get("messages", "m1", &message)
+	assert.NoError(t, err)
+	assert.Empty(t, message.ThreadKey)
+
+	reports, err = persistence.Backfill(backend, persistence.BackfillOptions{BatchSize: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reports[0].Patched)
+	_, err = store.Get("messages", "m1", &message)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.MessageThreadKey(message.Thread(), message.Cursor()), message.ThreadKey)
+	assert.Equal(t, "hi", message.TextContent)
+
+	reports, err = persistence.Backfill(backend, persistence.BackfillOptions{})
+	assert.NoError(t, err)
+	assert.Zero(t, reports[0].Patched)
+}
+
+func TestBackfill_LegacyUsersVerified(t *testing.T) {
+	store := newMemoryStore(t)
+	// signed up before emails were verified
+	assert.NoError(t, store.Put("users", "u1", map[string]interface{}{"id": "u1", "username": "old", "email": "old@example.com"}))
+	// and saved since by a version that stores emailVerified
+	assert.NoError(t, store.Put("users", "u3", map[string]interface{}{"id": "u3", "username": "saved", "emailVerified": false}))
+	assert.NoError(t, persistence.NewLocalUserRepository(store).Create(entity.NewUser("u2", "", "", "new", "new@example.com", "", nil)))
+
+	_, err := persistence.Backfill(persistence.NewLocalBackend(store), persistence.BackfillOptions{})
+	assert.NoError(t, err)
+
+	users := persistence.NewLocalUserRepository(store)
+	legacy, err := users.GetByID("u1")
+	assert.NoError(t, err)
+	assert.True(t, legacy.EmailVerified)
+	assert.Equal(t, "old", legacy.Username)
+	saved, err := users.GetByID("u3")
+	assert.NoError(t, err)
+	assert.True(t, saved.EmailVerified)
+	recent, err := users.GetByID("u2")
+	assert.NoError(t, err)
+	assert.False(t, recent.EmailVerified)
+}
+
+func TestBackfill_UnreadNotificationKeys(t *testing.T) {
+	store := newMemoryStore(t)
+	// stored before unread notifications were queried by unreadBy
+	assert.NoError(t, store.Put("notifications", "n1", map[string]interface{}{
+		"id": "n1", "userId": "u1", "type": "mention", "text": "hi", "createdAt": "2024-05-01T10:00:00Z",
+	}))
+	assert.NoError(t, store.Put("notifications", "n2", map[string]interface{}{
+		"id": "n2", "userId": "u1", "type": "mention", "text": "hi", "createdAt": "2024-05-01T10:00:00Z", "readAt": "2024-05-01T11:00:00Z",
+	}))
+
+	_, err := persistence.Backfill(persistence.NewLocalBackend(store), persistence.BackfillOptions{})
+	assert.NoError(t, err)
+
+	notifications := persistence.NewLocalNotificationRepository(store)
+	unread, err := notifications.GetByID("n1")
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", unread.UnreadBy)
+	read, err := notifications.GetByID("n2")
+	assert.NoError(t, err)
+	assert.Empty(t, read.UnreadBy)
+}
+
+func TestBackfill_LegacyTeamRequestExpiry(t *testing.T) {
+	store := newMemoryStore(t)
+	// stored before requests had a status or expired
+	assert.NoError(t, store.Put("teamRequests", "r1", map[string]interface{}{"id": "r1", "userid": "u1", "teamid": tests.TestTeamID}))
+	answered := &entity.TeamRequest{Id: "r2", UserID: "u2", TeamID: tests.TestTeamID}
+	answered.Resolve(entity.TeamRequestRejected, "u3", time.Now())
+	requests := persistence.NewLocalTeamRequestRepository(store)
+	assert.NoError(t, requests.Create(answered))
+
+	before := time.Now()
+	_, err := persistence.Backfill(persistence.NewLocalBackend(store), persistence.BackfillOptions{})
+	assert.NoError(t, err)
+
+	legacy, err := requests.GetById("r1")
+	assert.NoError(t, err)
+	assert.False(t, legacy.ExpiresAt.Before(before.Add(entity.TeamRequestTTL)))
+	assert.True(t, legacy.IsPending(time.Now()))
+	rejected, err := requests.GetById("r2")
+	assert.NoError(t, err)
+	assert.True(t, rejected.ExpiresAt.IsZero())
+}
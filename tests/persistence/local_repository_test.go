@@ -0,0 +1,615 @@
+package persistence_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMemoryStore(t *testing.T) *persistence.LocalStore {
+	store, err := persistence.NewLocalStore("")
+	assert.NoError(t, err)
+	return store
+}
+
+func TestLocalUserRepository_CRUD(t *testing.T) {
+	repo := persistence.NewLocalUserRepository(newMemoryStore(t))
+
+	user := &entity.User{ID: tests.TestUserID, Username: tests.TestUsername, Email: tests.TestEmail}
+	assert.NoError(t, repo.Create(user))
+
+	byEmail, err := repo.GetByEmail(tests.TestEmail)
+	assert.NoError(t, err)
+	assert.Equal(t, tests.TestUserID, byEmail.ID)
+
+	byUsername, err := repo.GetByUsername(tests.TestUsername)
+	assert.NoError(t, err)
+	assert.Equal(t, tests.TestUserID, byUsername.ID)
+
+	// returned values are copies, changing them does not touch the store
+	byEmail.Username = "changed"
+	stored, _ := repo.GetByID(tests.TestUserID)
+	assert.Equal(t, tests.TestUsername, stored.Username)
+
+	assert.NoError(t, repo.Delete(tests.TestUserID))
+	_, err = repo.GetByID(tests.TestUserID)
+	assert.EqualError(t, err, "user not found")
+	_, err = repo.GetByEmail(tests.TestEmail)
+	assert.Error(t, err)
+}
+
+func TestLocalTeamRepository_GetXTeamsByPrefix(t *testing.T) {
+	repo := persistence.NewLocalTeamRepository(newMemoryStore(t))
+	for id, name := range map[string]string{"t1": "Math B", "t2": "Math A", "t3": "Physics", "t4": "Math C"} {
+		assert.NoError(t, repo.Create(&entity.Team{Id: id, Name: name}))
+	}
+
+	teams, err := repo.GetXTeamsByPrefix("Math", 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, teams, 2)
+	assert.Equal(t, "Math A", teams[0].Name)
+	assert.Equal(t, "Math B", teams[1].Name)
+}
+
+func TestLocalEventRepository_UpdateMergesFields(t *testing.T) {
+	repo := persistence.NewLocalEventRepository(newMemoryStore(t))
+	event := entity.NewEvent(tests.TestEventID, tests.TestUserID, tests.TestTeamID, "Study", "desc", time.Now().UTC(), 60, []string{tests.TestUserID})
+	assert.NoError(t, repo.Create(event))
+
+	err := repo.Update(tests.TestEventID, map[string]interface{}{
+		"name":     "Renamed",
+		"statuses": map[string]entity.EventStatus{tests.TestUserID: entity.StatusAccepted},
+	})
+
+	assert.NoError(t, err)
+	stored, err := repo.GetByID(tests.TestEventID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed", stored.Name)
+	assert.Equal(t, "desc", stored.Description)
+	assert.Equal(t, entity.StatusAccepted, stored.Statuses[tests.TestUserID])
+}
+
+func TestLocalQuizRepository_Pagination(t *testing.T) {
+	repo := persistence.NewLocalQuizRepository(newMemoryStore(t))
+	for _, id := range []string{"q1", "q2", "q3", "q4", "q5"} {
+		assert.NoError(t, repo.Create(*entity.NewQuiz(id, "quiz "+id, tests.TestUserID, tests.TestTeamID, nil)))
+	}
+	assert.NoError(t, repo.Create(*entity.NewQuiz("q6", "other", "someone-else", tests.TestTeamID, nil)))
+
+	first, lastKey, err := repo.GetByUserAndTeam(tests.TestUserID, tests.TestTeamID, 2, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"q1", "q2"}, []string{first[0].ID, first[1].ID})
+	assert.Equal(t, "q2", lastKey)
+
+	second, lastKey, err := repo.GetByUserAndTeam(tests.TestUserID, tests.TestTeamID, 2, lastKey)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"q3", "q4"}, []string{second[0].ID, second[1].ID})
+
+	last, _, err := repo.GetByUserAndTeam(tests.TestUserID, tests.TestTeamID, 2, lastKey)
+	assert.NoError(t, err)
+	assert.Len(t, last, 1)
+
+	byTeam, _, err := repo.GetByTeam(tests.TestTeamID, 10, "")
+	assert.NoError(t, err)
+	assert.Len(t, byTeam, 6)
+}
+
+func TestLocalFriendRequestRepository_Friends(t *testing.T) {
+	repo := persistence.NewLocalFriendRequestRepository(newMemoryStore(t))
+
+	_, err := repo.GetByUsers("a", "b")
+	assert.ErrorIs(t, err, persistence.ErrFriendRequestNotFound)
+
+	accepted := entity.NewFriendRequest("a", "b")
+	accepted.Status = entity.ACCEPTED
+	assert.NoError(t, repo.Create(accepted))
+	assert.NoError(t, repo.Create(entity.NewFriendRequest("c", "a")))
+
+	friends, err := repo.GetFriendsForUser("a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, friends)
+
+	pending, err := repo.GetPendingRequestsForUser("a")
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, "c", pending[0].FromUserID)
+}
+
+// seedThread stores m1..m5 one second apart in the team thread, plus a message of another team
+func seedThread(t *testing.T, repo persistence.MessageRepositoryInterface) []*entity.Message {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	messages := make([]*entity.Message, 0, 5)
+	for i, id := range []string{"m1", "m2", "m3", "m4", "m5"} {
+		message := entity.NewMessage(id, tests.TestUserID, "", tests.TestTeamID, "text "+id)
+		message.SentAt = start.Add(time.Duration(i) * time.Second)
+		assert.NoError(t, repo.Create(message))
+		messages = append(messages, message)
+	}
+	assert.NoError(t, repo.Create(entity.NewMessage("other", tests.TestUserID, "", "other-team", "elsewhere")))
+	return messages
+}
+
+func messageIDs(messages []*entity.Message) []string {
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	return ids
+}
+
+func TestLocalMessageRepository_GetTeamPage(t *testing.T) {
+	repo := persistence.NewLocalMessageRepository(newMemoryStore(t))
+	messages := seedThread(t, repo)
+
+	newest, err := repo.GetTeamPage(tests.TestTeamID, persistence.MessagePageQuery{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m4", "m5"}, messageIDs(newest))
+
+	before := messages[3].Cursor()
+	older, err := repo.GetTeamPage(tests.TestTeamID, persistence.MessagePageQuery{Before: &before, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m2", "m3"}, messageIDs(older))
+
+	after := messages[1].Cursor()
+	newer, err := repo.GetTeamPage(tests.TestTeamID, persistence.MessagePageQuery{After: &after, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m3", "m4"}, messageIDs(newer))
+
+	between, err := repo.GetTeamPage(tests.TestTeamID, persistence.MessagePageQuery{After: &after, Before: &before, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m3"}, messageIDs(between))
+}
+
+// seedUnread adds a reply from another user after the seeded thread and deletes m2
+func seedUnread(t *testing.T, repo persistence.MessageRepositoryInterface, messages []*entity.Message) {
+	reply := entity.NewMessage("m6", "replier", "", tests.TestTeamID, "reply")
+	reply.SentAt = messages[4].SentAt.Add(time.Second)
+	assert.NoError(t, repo.Create(reply))
+	assert.NoError(t, repo.Update("m2", map[string]interface{}{"deletedAt": time.Now().UTC()}))
+}
+
+func TestLocalMessageRepository_CountTeam(t *testing.T) {
+	repo := persistence.NewLocalMessageRepository(newMemoryStore(t))
+	messages := seedThread(t, repo)
+	seedUnread(t, repo, messages)
+
+	after := messages[0].Cursor()
+	count, err := repo.CountTeam(tests.TestTeamID, persistence.MessageCountQuery{After: &after, ExcludeSender: "nobody", Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	capped, err := repo.CountTeam(tests.TestTeamID, persistence.MessageCountQuery{After: &after, ExcludeSender: "nobody", Limit: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, capped)
+
+	fromOthers, err := repo.CountTeam(tests.TestTeamID, persistence.MessageCountQuery{ExcludeSender: tests.TestUserID, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fromOthers)
+}
+
+func TestLocalMessageRepository_RepliesLeaveTeamPage(t *testing.T) {
+	repo := persistence.NewLocalMessageRepository(newMemoryStore(t))
+	messages := seedThread(t, repo)
+	reply := entity.NewReply("r1", "replier", messages[0], "in thread")
+	assert.NoError(t, repo.Create(reply))
+	assert.NoError(t, repo.Update("m1", map[string]interface{}{
+		"replies/r1":     reply.SentAt,
+		"reactions/👍/u1": time.Now().UTC(),
+	}))
+
+	page, err := repo.GetTeamPage(tests.TestTeamID, persistence.MessagePageQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m1", "m2", "m3", "m4", "m5"}, messageIDs(page))
+
+	replies, err := repo.GetRepliesPage("m1", persistence.MessagePageQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"r1"}, messageIDs(replies))
+
+	parent, err := repo.GetByID("m1")
+	assert.NoError(t, err)
+	assert.Len(t, parent.Replies, 1)
+	assert.True(t, parent.HasReacted("u1", "👍"))
+}
+
+func TestLocalReadCursorRepository_GetByUser(t *testing.T) {
+	repo := persistence.NewLocalReadCursorRepository(newMemoryStore(t))
+	assert.NoError(t, repo.Save(entity.NewReadCursor("u1", entity.TeamThread("t1"))))
+	assert.NoError(t, repo.Save(entity.NewReadCursor("u1", entity.ConversationThread("u1_u2"))))
+	assert.NoError(t, repo.Save(entity.NewReadCursor("u10", entity.TeamThread("t1"))))
+
+	cursors, err := repo.GetByUser("u1")
+	assert.NoError(t, err)
+	assert.Len(t, cursors, 2)
+
+	_, err = repo.Get("u2", entity.TeamThread("t1"))
+	assert.EqualError(t, err, persistence.ReadCursorNotFound)
+}
+
+func TestLocalMessageRepository_PageBreaksTimestampTiesByID(t *testing.T) {
+	repo := persistence.NewLocalMessageRepository(newMemoryStore(t))
+	sentAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, id := range []string{"b", "c", "a"} {
+		message := entity.NewMessage(id, "u1", entity.GetConversationKey("u1", "u2"), "", id)
+		message.SentAt = sentAt
+		assert.NoError(t, repo.Create(message))
+	}
+
+	first, err := repo.GetConversationPage("u2", "u1", persistence.MessagePageQuery{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, messageIDs(first))
+
+	before := first[0].Cursor()
+	rest, err := repo.GetConversationPage("u1", "u2", persistence.MessagePageQuery{Before: &before, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, messageIDs(rest))
+}
+
+func TestLocalUserRepository_GetByIDs(t *testing.T) {
+	repo := persistence.NewLocalUserRepository(newMemoryStore(t))
+	for _, id := range []string{"u1", "u2", "u3"} {
+		assert.NoError(t, repo.Create(&entity.User{ID: id}))
+	}
+
+	users, err := repo.GetByIDs([]string{"u3", "u1", "missing", "u1"})
+
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, "u3", users[0].ID)
+	assert.Equal(t, "u1", users[1].ID)
+}
+
+func eventSeqs(events []*entity.HubEvent) []int64 {
+	seqs := make([]int64, 0, len(events))
+	for _, event := range events {
+		seqs = append(seqs, event.Seq)
+	}
+	return seqs
+}
+
+func TestLocalHubEventRepository_NumbersEventsPerUser(t *testing.T) {
+	repo := persistence.NewLocalHubEventRepository(newMemoryStore(t))
+	for _, userID := range []string{"u1", "u2", "u1", "u1"} {
+		_, err := repo.Append(userID, []byte(`{"type":"direct_message"}`))
+		assert.NoError(t, err)
+	}
+
+	u1, err := repo.ListSince("u1", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, eventSeqs(u1))
+	assert.JSONEq(t, `{"type":"direct_message"}`, string(u1[0].Data))
+
+	u2, err := repo.ListSince("u2", 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1}, eventSeqs(u2))
+
+	limited, err := repo.ListSince("u1", 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, eventSeqs(limited))
+}
+
+func TestLocalHubEventRepository_TrimsOldEvents(t *testing.T) {
+	repo := persistence.NewLocalHubEventRepository(newMemoryStore(t))
+	var last int64
+	for i := 0; i < persistence.HubEventRetention+100; i++ {
+		seq, err := repo.Append("u1", []byte(`{}`))
+		assert.NoError(t, err)
+		last = seq
+	}
+
+	events, err := repo.ListSince("u1", 0, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(persistence.HubEventRetention+100), last)
+	assert.Equal(t, []int64{101}, eventSeqs(events))
+}
+
+func TestLocalPresenceRepository_GetByUserIDs(t *testing.T) {
+	repo := persistence.NewLocalPresenceRepository(newMemoryStore(t))
+	assert.NoError(t, repo.Save(entity.NewPresence("u1", entity.PresenceOnline)))
+	assert.NoError(t, repo.Save(entity.NewPresence("u2", entity.PresenceIdle)))
+	assert.NoError(t, repo.Save(entity.NewPresence("u1", entity.PresenceOffline)))
+
+	presences, err := repo.GetByUserIDs([]string{"u1", "missing", "u1"})
+
+	assert.NoError(t, err)
+	assert.Len(t, presences, 1)
+	assert.Equal(t, entity.PresenceOffline, presences[0].Status)
+	_, err = repo.GetByUserID("missing")
+	assert.EqualError(t, err, persistence.PresenceNotFound)
+}
+
+func TestLocalStore_PersistsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db", "local.json")
+
+	store, err := persistence.NewLocalStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, persistence.NewLocalTeamRepository(store).Create(&entity.Team{Id: tests.TestTeamID, Name: tests.TestTeamName}))
+	assert.NoError(t, persistence.NewLocalMessageRepository(store).Create(entity.NewMessage("m1", tests.TestUserID, "", tests.TestTeamID, "hello")))
+
+	reopened, err := persistence.NewLocalStore(path)
+	assert.NoError(t, err)
+
+	team, err := persistence.NewLocalTeamRepository(reopened).GetTeamById(tests.TestTeamID)
+	assert.NoError(t, err)
+	assert.Equal(t, tests.TestTeamName, team.Name)
+	messages, err := persistence.NewLocalMessageRepository(reopened).GetByTeamID(tests.TestTeamID)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, []string{"messages", "teams"}, reopened.Collections())
+}
+
+func TestNewRepository_UsesLocalStoreWhenSelected(t *testing.T) {
+	persistence.UseLocalStore(newMemoryStore(t))
+	defer persistence.UseLocalStore(nil)
+
+	assert.IsType(t, &persistence.LocalUserRepository{}, persistence.NewUserRepository())
+	assert.IsType(t, &persistence.LocalTeamRepository{}, persistence.NewTeamRepository())
+	assert.IsType(t, &persistence.LocalMessageRepository{}, persistence.NewMessageRepository())
+	assert.IsType(t, &persistence.LocalQuizRepository{}, persistence.NewQuizRepository())
+	assert.IsType(t, &persistence.LocalEventRepository{}, persistence.NewEventRepository())
+	assert.IsType(t, &persistence.LocalFileRepository{}, persistence.NewFileRepository())
+	assert.IsType(t, &persistence.LocalFriendRequestRepository{}, persistence.NewFriendRequestRepository())
+	assert.IsType(t, &persistence.LocalTeamRequestRepository{}, persistence.NewTeamRequestRepository())
+}
+
+func TestLocalFileRepository_GetPageByContextID(t *testing.T) {
+	repo := persistence.NewLocalFileRepository(newMemoryStore(t))
+	for i, id := range []string{"f3", "f1", "f4", "f2"} {
+		assert.NoError(t, repo.Create(&entity.File{ID: id, ContextType: entity.FileContextTeam, ContextID: tests.TestTeamID, CreatedAt: int64(i)}))
+	}
+	assert.NoError(t, repo.Create(&entity.File{ID: "other", ContextType: entity.FileContextTeam, ContextID: "other-team"}))
+
+	page, total, err := repo.GetPageByContextID(entity.FileContextTeam, tests.TestTeamID, 1, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, []string{"f1", "f4"}, []string{page[0].ID, page[1].ID})
+
+	past, total, err := repo.GetPageByContextID(entity.FileContextTeam, tests.TestTeamID, 10, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, total)
+	assert.Empty(t, past)
+}
+
+func TestLocalUnitOfWork_CommitsTogether(t *testing.T) {
+	store := newMemoryStore(t)
+	persistence.UseLocalStore(store)
+	defer persistence.UseLocalStore(nil)
+	assert.NoError(t, persistence.NewLocalTeamRepository(store).Create(&entity.Team{Id: "old", Name: "Old"}))
+
+	uow := persistence.NewUnitOfWorkFactory().Begin()
+	uow.SaveUser(&entity.User{ID: tests.TestUserID, TeamsIds: &[]string{tests.TestTeamID}})
+	uow.SaveTeam(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID}})
+	uow.DeleteTeam("old")
+
+	// nothing is visible before the commit
+	_, err := persistence.NewLocalUserRepository(store).GetByID(tests.TestUserID)
+	assert.Error(t, err)
+
+	assert.NoError(t, uow.Commit())
+	team, err := persistence.NewLocalTeamRepository(store).GetTeamById(tests.TestTeamID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{tests.TestUserID}, team.UsersIds)
+	assert.Equal(t, []string{tests.TestTeamID}, store.Keys("teams"))
+}
+
+func TestLocalUnitOfWork_UpdateChangesTheStoredDocument(t *testing.T) {
+	store := newMemoryStore(t)
+	persistence.UseLocalStore(store)
+	defer persistence.UseLocalStore(nil)
+	teams := persistence.NewLocalTeamRepository(store)
+	assert.NoError(t, teams.Create(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{"u1"}}))
+
+	uow := persistence.NewUnitOfWorkFactory().Begin()
+	uow.UpdateTeam(tests.TestTeamID, func(team *entity.Team) error {
+		team.UsersIds = append(team.UsersIds, "u3")
+		return nil
+	})
+	// another request adds a member before the commit
+	assert.NoError(t, teams.Update(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{"u1", "u2"}}))
+
+	assert.NoError(t, uow.Commit())
+	team, err := teams.GetTeamById(tests.TestTeamID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2", "u3"}, team.UsersIds)
+}
+
+func TestLocalUnitOfWork_FailedChangeWritesNothing(t *testing.T) {
+	store := newMemoryStore(t)
+	persistence.UseLocalStore(store)
+	defer persistence.UseLocalStore(nil)
+
+	uow := persistence.NewUnitOfWorkFactory().Begin()
+	uow.SaveTeam(&entity.Team{Id: tests.TestTeamID})
+	uow.UpdateUser("missing", func(user *entity.User) error { return nil })
+
+	assert.EqualError(t, uow.Commit(), "user not found")
+	assert.Empty(t, store.Keys("teams"))
+}
+
+func TestLocalStore_BatchRollsBackWhenFileWriteFails(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "local.json")
+	store, err := persistence.NewLocalStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Put("teams", "t1", &entity.Team{Id: "t1", Name: "Before"}))
+
+	// a directory in place of the temporary file makes the write fail
+	assert.NoError(t, os.Mkdir(path+".tmp", 0o755))
+	err = store.Batch([]persistence.LocalWrite{
+		{Collection: "teams", ID: "t1", Value: &entity.Team{Id: "t1", Name: "After"}},
+		{Collection: "users", ID: "u1", Value: &entity.User{ID: "u1"}},
+	})
+
+	assert.Error(t, err)
+	var team entity.Team
+	found, _ := store.Get("teams", "t1", &team)
+	assert.True(t, found)
+	assert.Equal(t, "Before", team.Name)
+	assert.Empty(t, store.Keys("users"))
+}
+
+func TestLocalTeamInviteLinkRepository_RedeemStopsAtMaxUses(t *testing.T) {
+	repo := persistence.NewLocalTeamInviteLinkRepository(newMemoryStore(t))
+	now := time.Now().UTC()
+	assert.NoError(t, repo.Create(entity.NewTeamInviteLink("token1", tests.TestTeamID, tests.TestUserID, now.Add(time.Hour), 2)))
+	assert.NoError(t, repo.Create(entity.NewTeamInviteLink("token2", tests.TestTeamID2, tests.TestUserID, now.Add(time.Hour), 0)))
+
+	for uses := 1; uses <= 2; uses++ {
+		link, err := repo.Redeem("token1", now)
+		assert.NoError(t, err)
+		assert.Equal(t, uses, link.Uses)
+	}
+	_, err := repo.Redeem("token1", now)
+	assert.EqualError(t, err, persistence.TeamInviteLinkUnusable)
+
+	_, err = repo.Redeem("token2", now.Add(2*time.Hour))
+	assert.EqualError(t, err, persistence.TeamInviteLinkUnusable)
+	_, err = repo.Redeem("missing", now)
+	assert.EqualError(t, err, persistence.TeamInviteLinkNotFound)
+
+	// a failed join gives its use back, so the link can be used once more
+	assert.NoError(t, repo.Release("token1"))
+	link, err := repo.Redeem("token1", now)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, link.Uses)
+	assert.NoError(t, repo.Release("missing"))
+
+	links, err := repo.GetByTeamID(tests.TestTeamID)
+	assert.NoError(t, err)
+	assert.Len(t, links, 1)
+	assert.Equal(t, 2, links[0].Uses)
+}
+
+func TestLocalTeamRequestRepository_UpdateAndGetByTeamId(t *testing.T) {
+	repo := persistence.NewLocalTeamRequestRepository(newMemoryStore(t))
+	expiresAt := time.Now().Add(time.Hour)
+	assert.NoError(t, repo.Create(entity.NewTeamRequest("r1", tests.TestUserID1, tests.TestTeamID, "hi", expiresAt)))
+	assert.NoError(t, repo.Create(entity.NewTeamRequest("r2", tests.TestUserID2, tests.TestTeamID2, "", expiresAt)))
+
+	req, err := repo.GetById("r1")
+	assert.NoError(t, err)
+	req.Resolve(entity.TeamRequestRejected, tests.TestUserID, time.Now())
+	assert.NoError(t, repo.Update(req))
+
+	byTeam, err := repo.GetByTeamId(tests.TestTeamID)
+	assert.NoError(t, err)
+	assert.Len(t, byTeam, 1)
+	assert.Equal(t, entity.TeamRequestRejected, byTeam[0].Status)
+	assert.Equal(t, tests.TestUserID, byTeam[0].ReviewerID)
+	assert.Equal(t, "hi", byTeam[0].Message)
+}
+
+func TestLocalTeamRequestRepository_UpdateIfStatus(t *testing.T) {
+	repo := persistence.NewLocalTeamRequestRepository(newMemoryStore(t))
+	assert.NoError(t, repo.Create(entity.NewTeamRequest("r1", tests.TestUserID1, tests.TestTeamID, "", time.Now().Add(time.Hour))))
+
+	accepted, _ := repo.GetById("r1")
+	accepted.Resolve(entity.TeamRequestAccepted, tests.TestUserID, time.Now())
+	updated, err := repo.UpdateIfStatus(accepted, entity.TeamRequestPending)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	rejected, _ := repo.GetById("r1")
+	rejected.Resolve(entity.TeamRequestRejected, tests.TestUserID2, time.Now())
+	updated, err = repo.UpdateIfStatus(rejected, entity.TeamRequestPending)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+
+	stored, _ := repo.GetById("r1")
+	assert.Equal(t, entity.TeamRequestAccepted, stored.Status)
+	assert.Equal(t, tests.TestUserID, stored.ReviewerID)
+}
+
+func TestLocalNotificationRepository_MarkAllRead(t *testing.T) {
+	repo := persistence.NewLocalNotificationRepository(newMemoryStore(t))
+	template := entity.Notification{Type: entity.NotificationMention, Text: "hi"}
+	first := entity.NewNotification("n1", tests.TestUserID1, template)
+	first.CreatedAt = first.CreatedAt.Add(-time.Minute)
+	assert.NoError(t, repo.Create(first))
+	assert.NoError(t, repo.Create(entity.NewNotification("n2", tests.TestUserID1, template)))
+	assert.NoError(t, repo.Create(entity.NewNotification("n3", tests.TestUserID2, template)))
+
+	notifications, err := repo.GetByUserID(tests.TestUserID1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"n2", "n1"}, []string{notifications[0].ID, notifications[1].ID})
+	counts, err := repo.CountUnread(tests.TestUserID1)
+	assert.NoError(t, err)
+	assert.Equal(t, map[entity.NotificationType]int{entity.NotificationMention: 2}, counts)
+
+	marked, err := repo.MarkAllRead(tests.TestUserID1, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, marked)
+	counts, err = repo.CountUnread(tests.TestUserID1)
+	assert.NoError(t, err)
+	assert.Empty(t, counts)
+	marked, err = repo.MarkAllRead(tests.TestUserID1, time.Now())
+	assert.NoError(t, err)
+	assert.Zero(t, marked)
+
+	other, err := repo.GetByID("n3")
+	assert.NoError(t, err)
+	assert.False(t, other.IsRead())
+	_, err = repo.GetByID("missing")
+	assert.EqualError(t, err, persistence.NotificationNotFound)
+}
+
+func TestLocalNotificationPreferencesRepository_SaveAndGet(t *testing.T) {
+	repo := persistence.NewLocalNotificationPreferencesRepository(newMemoryStore(t))
+	_, err := repo.GetByUserID(tests.TestUserID1)
+	assert.EqualError(t, err, persistence.NotificationPreferencesNotFound)
+
+	preferences := entity.NewNotificationPreferences(tests.TestUserID1)
+	preferences.Channels[entity.NotificationMention] = map[entity.NotificationChannel]bool{entity.ChannelEmail: true}
+	preferences.MutedTeamIDs = []string{tests.TestTeamID}
+	preferences.QuietHours = &entity.QuietHours{Start: "22:00", End: "07:00"}
+	assert.NoError(t, repo.Save(preferences))
+
+	saved, err := repo.GetByUserID(tests.TestUserID1)
+	assert.NoError(t, err)
+	assert.True(t, saved.Enabled(entity.NotificationMention, entity.ChannelEmail))
+	assert.True(t, saved.IsTeamMuted(tests.TestTeamID))
+	assert.Equal(t, "07:00", saved.QuietHours.End)
+}
+
+func TestLocalUserTokenRepository_InvalidateByUserID(t *testing.T) {
+	repo := persistence.NewLocalUserTokenRepository(newMemoryStore(t))
+	expiresAt := time.Now().Add(time.Hour)
+	assert.NoError(t, repo.Create(entity.NewUserToken("t1", tests.TestUserID1, tests.TestEmail, entity.PurposeEmailVerification, expiresAt)))
+	assert.NoError(t, repo.Create(entity.NewUserToken("t2", tests.TestUserID1, tests.TestEmail, entity.PurposePasswordReset, expiresAt)))
+	assert.NoError(t, repo.Create(entity.NewUserToken("t3", tests.TestUserID2, tests.TestEmail, entity.PurposePasswordReset, expiresAt)))
+
+	assert.NoError(t, repo.InvalidateByUserID(tests.TestUserID1))
+
+	for id, used := range map[string]bool{"t1": true, "t2": true, "t3": false} {
+		token, err := repo.GetByID(id)
+		assert.NoError(t, err)
+		assert.Equal(t, used, token.Used, id)
+	}
+}
+
+func TestLocalUserTokenRepository_MarkUsedOnce(t *testing.T) {
+	repo := persistence.NewLocalUserTokenRepository(newMemoryStore(t))
+	assert.NoError(t, repo.Create(entity.NewUserToken("t1", tests.TestUserID1, tests.TestEmail, entity.PurposePasswordReset, time.Now().Add(time.Hour))))
+
+	marked, err := repo.MarkUsed("t1")
+	assert.NoError(t, err)
+	assert.True(t, marked)
+	marked, err = repo.MarkUsed("t1")
+	assert.NoError(t, err)
+	assert.False(t, marked)
+	marked, err = repo.MarkUsed("missing")
+	assert.NoError(t, err)
+	assert.False(t, marked)
+
+	token, err := repo.GetByID("t1")
+	assert.NoError(t, err)
+	assert.True(t, token.Used)
+}
@@ -0,0 +1,132 @@
+package persistence_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func seedSource(t *testing.T, store *persistence.LocalStore) {
+	users := persistence.NewLocalUserRepository(store)
+	for _, id := range []string{"u1", "u2", "u3", "u4", "u5"} {
+		assert.NoError(t, users.Create(&entity.User{ID: id, Username: "name-" + id, Email: id + "@example.com"}))
+	}
+	assert.NoError(t, persistence.NewLocalTeamRepository(store).Create(&entity.Team{Id: tests.TestTeamID, Name: tests.TestTeamName}))
+	assert.NoError(t, persistence.NewLocalMessageRepository(store).Create(entity.NewMessage("m1", "u1", "u2", "", "hi")))
+
+	accepted := entity.NewFriendRequest("u1", "u2")
+	accepted.Status = entity.ACCEPTED
+	assert.NoError(t, persistence.NewLocalFriendRequestRepository(store).Create(accepted))
+
+	// written before quizzes had the composite key
+	assert.NoError(t, store.Put("quizzes", "q1", map[string]interface{}{
+		"id": "q1", "quiz_name": "Old", "user_id": "u1", "team_id": tests.TestTeamID,
+	}))
+}
+
+func TestMigrator_CopiesAndVerifies(t *testing.T) {
+	source, target := newMemoryStore(t), newMemoryStore(t)
+	seedSource(t, source)
+	migrator, err := persistence.NewMigrator(persistence.NewLocalBackend(source), persistence.NewLocalBackend(target),
+		persistence.MigrationOptions{BatchSize: 2})
+	assert.NoError(t, err)
+
+	reports, err := migrator.Run()
+
+	assert.NoError(t, err)
+	assert.Len(t, reports, len(persistence.MigratedCollections()))
+	for _, report := range reports {
+		assert.True(t, report.Verified, report.Collection)
+		if report.Collection == "users" {
+			assert.Equal(t, 5, report.Copied)
+			assert.Equal(t, 5, report.Target.Count)
+		}
+	}
+
+	friends, err := persistence.NewLocalFriendRequestRepository(target).GetFriendsForUser("u1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, friends)
+
+	quizzes, _, err := persistence.NewLocalQuizRepository(target).GetByUserAndTeam("u1", tests.TestTeamID, 10, "")
+	assert.NoError(t, err)
+	assert.Len(t, quizzes, 1)
+	assert.Equal(t, "u1_"+tests.TestTeamID, quizzes[0].UserTeamId)
+}
+
+func TestMigrator_DryRunWritesNothing(t *testing.T) {
+	source, target := newMemoryStore(t), newMemoryStore(t)
+	seedSource(t, source)
+	migrator, err := persistence.NewMigrator(persistence.NewLocalBackend(source), persistence.NewLocalBackend(target),
+		persistence.MigrationOptions{DryRun: true, StatePath: filepath.Join(t.TempDir(), "state.json")})
+	assert.NoError(t, err)
+
+	reports, err := migrator.Run()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, reports[0].Copied)
+	assert.False(t, reports[0].Verified)
+	assert.Empty(t, target.Collections())
+}
+
+func TestMigrator_ResumesFromCheckpoint(t *testing.T) {
+	source, target := newMemoryStore(t), newMemoryStore(t)
+	seedSource(t, source)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	// a previous run finished the first batch of users before stopping
+	state, _ := json.Marshal(persistence.MigrationState{Collections: map[string]*persistence.MigrationCheckpoint{
+		"users": {LastKey: "u2", Copied: 2},
+	}})
+	assert.NoError(t, os.WriteFile(statePath, state, 0o600))
+
+	migrator, err := persistence.NewMigrator(persistence.NewLocalBackend(source), persistence.NewLocalBackend(target),
+		persistence.MigrationOptions{BatchSize: 2, StatePath: statePath, Collections: []string{"users"}})
+	assert.NoError(t, err)
+
+	reports, err := migrator.Run()
+
+	// only u3..u5 were copied this time, so the target is missing the first batch
+	assert.ErrorIs(t, err, persistence.ErrVerificationFailed)
+	assert.Equal(t, 5, reports[0].Copied)
+	assert.Equal(t, 3, reports[0].Target.Count)
+	assert.Equal(t, []string{"u3", "u4", "u5"}, target.Keys("users"))
+
+	saved, err := os.ReadFile(statePath)
+	assert.NoError(t, err)
+	var resumed persistence.MigrationState
+	assert.NoError(t, json.Unmarshal(saved, &resumed))
+	assert.True(t, resumed.Collections["users"].Done)
+	assert.Equal(t, "u5", resumed.Collections["users"].LastKey)
+}
+
+func TestMigrator_VerifyDetectsDifferences(t *testing.T) {
+	source, target := newMemoryStore(t), newMemoryStore(t)
+	seedSource(t, source)
+	seedSource(t, target)
+	assert.NoError(t, persistence.NewLocalUserRepository(target).Update(&entity.User{ID: "u1", Username: "changed"}))
+	migrator, err := persistence.NewMigrator(persistence.NewLocalBackend(source), persistence.NewLocalBackend(target),
+		persistence.MigrationOptions{Collections: []string{"users", "teams"}})
+	assert.NoError(t, err)
+
+	reports, err := migrator.Verify()
+
+	assert.ErrorIs(t, err, persistence.ErrVerificationFailed)
+	assert.Len(t, reports, 2)
+	assert.False(t, reports[0].Verified)
+	assert.Equal(t, reports[0].Source.Count, reports[0].Target.Count)
+	assert.NotEqual(t, reports[0].Source.Checksum, reports[0].Target.Checksum)
+	assert.True(t, reports[1].Verified)
+}
+
+func TestNewMigrator_UnknownCollection(t *testing.T) {
+	_, err := persistence.NewMigrator(persistence.NewLocalBackend(newMemoryStore(t)), persistence.NewLocalBackend(newMemoryStore(t)),
+		persistence.MigrationOptions{Collections: []string{"nope"}})
+
+	assert.EqualError(t, err, `unknown collection "nope"`)
+}
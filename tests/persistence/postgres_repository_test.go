@@ -0,0 +1,470 @@
+package persistence_test
+
+import (
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// newPostgresDB connects to TEST_DATABASE_URL and skips the test when it is not set.
+// The database is wiped before every test, so never point it at real data.
+func newPostgresDB(t *testing.T) *sql.DB {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("postgres", url)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`DROP SCHEMA public CASCADE; CREATE SCHEMA public`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, persistence.Migrate(db)) {
+		t.FailNow()
+	}
+	return db
+}
+
+func TestMigrations_OrderedByVersion(t *testing.T) {
+	migrations, err := persistence.Migrations()
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	assert.Equal(t, "0001_initial_schema", migrations[0].Version)
+	for i := 1; i < len(migrations); i++ {
+		assert.Less(t, migrations[i-1].Version, migrations[i].Version)
+	}
+	assert.True(t, strings.Contains(migrations[0].SQL, "CREATE TABLE users"))
+}
+
+func TestPostgres_MigrateIsIdempotent(t *testing.T) {
+	db := newPostgresDB(t)
+
+	assert.NoError(t, persistence.Migrate(db))
+
+	migrations, _ := persistence.Migrations()
+	var applied int
+	assert.NoError(t, db.QueryRow(`SELECT count(*) FROM schema_migrations`).Scan(&applied))
+	assert.Equal(t, len(migrations), applied)
+}
+
+func TestPostgresUserRepository_CRUD(t *testing.T) {
+	repo := persistence.NewPostgresUserRepository(newPostgresDB(t))
+
+	user := &entity.User{ID: tests.TestUserID, Username: tests.TestUsername, Email: tests.TestEmail}
+	assert.NoError(t, repo.Create(user))
+
+	byEmail, err := repo.GetByEmail(tests.TestEmail)
+	assert.NoError(t, err)
+	assert.Equal(t, tests.TestUserID, byEmail.ID)
+
+	user.Username = "renamed"
+	assert.NoError(t, repo.Update(user))
+	byUsername, err := repo.GetByUsername("renamed")
+	assert.NoError(t, err)
+	assert.Equal(t, tests.TestUserID, byUsername.ID)
+
+	assert.NoError(t, repo.Delete(tests.TestUserID))
+	_, err = repo.GetByID(tests.TestUserID)
+	assert.EqualError(t, err, "user not found")
+}
+
+func TestPostgresUserRepository_RejectsTakenEmailAndUsername(t *testing.T) {
+	repo := persistence.NewPostgresUserRepository(newPostgresDB(t))
+	assert.NoError(t, repo.Create(&entity.User{ID: "u1", Email: tests.TestEmail, Username: tests.TestUsername}))
+
+	err := repo.Create(&entity.User{ID: "u2", Email: tests.TestEmail, Username: "other"})
+	assert.EqualError(t, err, "email already exists")
+	err = repo.Create(&entity.User{ID: "u3", Email: "other@example.com", Username: tests.TestUsername})
+	assert.EqualError(t, err, "username already exists")
+	assert.NoError(t, repo.Create(&entity.User{ID: "u4"}))
+	assert.NoError(t, repo.Create(&entity.User{ID: "u5"}))
+}
+
+func TestPostgresTeamRepository_GetXTeamsByPrefix(t *testing.T) {
+	repo := persistence.NewPostgresTeamRepository(newPostgresDB(t))
+	for id, name := range map[string]string{"t1": "Math B", "t2": "Math A", "t3": "Physics", "t4": "Math C", "t5": "Math_%"} {
+		assert.NoError(t, repo.Create(&entity.Team{Id: id, Name: name}))
+	}
+
+	teams, err := repo.GetXTeamsByPrefix("Math", 2)
+	assert.NoError(t, err)
+	assert.Len(t, teams, 2)
+	assert.Equal(t, "Math A", teams[0].Name)
+	assert.Equal(t, "Math B", teams[1].Name)
+
+	// wildcards in the prefix are matched literally
+	literal, err := repo.GetXTeamsByPrefix("Math_", 10)
+	assert.NoError(t, err)
+	assert.Len(t, literal, 1)
+}
+
+func TestPostgresEventRepository_UpdateMergesFields(t *testing.T) {
+	repo := persistence.NewPostgresEventRepository(newPostgresDB(t))
+	event := entity.NewEvent(tests.TestEventID, tests.TestUserID, tests.TestTeamID, "Study", "desc", time.Now().UTC(), 60, []string{tests.TestUserID})
+	assert.NoError(t, repo.Create(event))
+
+	err := repo.Update(tests.TestEventID, map[string]interface{}{
+		"name":                         "Renamed",
+		"statuses/" + tests.TestUserID: entity.StatusAccepted,
+	})
+
+	assert.NoError(t, err)
+	stored, err := repo.GetByID(tests.TestEventID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed", stored.Name)
+	assert.Equal(t, "desc", stored.Description)
+	assert.Equal(t, entity.StatusAccepted, stored.Statuses[tests.TestUserID])
+
+	assert.EqualError(t, repo.Update("missing", map[string]interface{}{"name": "x"}), persistence.EventNotFound)
+}
+
+func TestPostgresMessageRepository_ConversationOrder(t *testing.T) {
+	repo := persistence.NewPostgresMessageRepository(newPostgresDB(t))
+	older := entity.NewMessage("m2", "a", "b", "", "first")
+	older.SentAt = time.Now().Add(-time.Minute).UTC()
+	newer := entity.NewMessage("m1", "b", "a", "", "second")
+	assert.NoError(t, repo.Create(newer))
+	assert.NoError(t, repo.Create(older))
+	assert.NoError(t, repo.Create(entity.NewMessage("m3", "a", "", tests.TestTeamID, "team")))
+
+	messages, err := repo.GetByConversation("b", "a")
+
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "m2", messages[0].ID)
+	assert.Equal(t, "m1", messages[1].ID)
+}
+
+func TestPostgresMessageRepository_GetTeamPage(t *testing.T) {
+	repo := persistence.NewPostgresMessageRepository(newPostgresDB(t))
+	messages := seedThread(t, repo)
+
+	newest, err := repo.GetTeamPage(tests.TestTeamID, persistence.MessagePageQuery{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m4", "m5"}, messageIDs(newest))
+
+	before := messages[3].Cursor()
+	older, err := repo.GetTeamPage(tests.TestTeamID, persistence.MessagePageQuery{Before: &before, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m2", "m3"}, messageIDs(older))
+
+	after := messages[1].Cursor()
+	newer, err := repo.GetTeamPage(tests.TestTeamID, persistence.MessagePageQuery{After: &after, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m3", "m4"}, messageIDs(newer))
+}
+
+func TestPostgresMessageRepository_CountTeam(t *testing.T) {
+	repo := persistence.NewPostgresMessageRepository(newPostgresDB(t))
+	messages := seedThread(t, repo)
+	seedUnread(t, repo, messages)
+
+	after := messages[0].Cursor()
+	count, err := repo.CountTeam(tests.TestTeamID, persistence.MessageCountQuery{After: &after, ExcludeSender: "nobody", Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	capped, err := repo.CountTeam(tests.TestTeamID, persistence.MessageCountQuery{After: &after, ExcludeSender: "nobody", Limit: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, capped)
+
+	fromOthers, err := repo.CountTeam(tests.TestTeamID, persistence.MessageCountQuery{ExcludeSender: tests.TestUserID, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fromOthers)
+}
+
+func TestPostgresMessageRepository_RepliesLeaveTeamPage(t *testing.T) {
+	repo := persistence.NewPostgresMessageRepository(newPostgresDB(t))
+	messages := seedThread(t, repo)
+	reply := entity.NewReply("r1", "replier", messages[0], "in thread")
+	assert.NoError(t, repo.Create(reply))
+	assert.NoError(t, repo.Update("m1", map[string]interface{}{"replies/r1": reply.SentAt}))
+
+	page, err := repo.GetTeamPage(tests.TestTeamID, persistence.MessagePageQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m1", "m2", "m3", "m4", "m5"}, messageIDs(page))
+
+	replies, err := repo.GetRepliesPage("m1", persistence.MessagePageQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"r1"}, messageIDs(replies))
+
+	count, err := repo.CountTeam(tests.TestTeamID, persistence.MessageCountQuery{ExcludeSender: tests.TestUserID, Limit: 10})
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestPostgresReadCursorRepository_SaveAndGetByUser(t *testing.T) {
+	repo := persistence.NewPostgresReadCursorRepository(newPostgresDB(t))
+	cursor := entity.NewReadCursor("u1", entity.TeamThread("t1"))
+	assert.NoError(t, repo.Save(cursor))
+	cursor.Advance(entity.NewMessage("m1", "u2", "", "t1", "hi"))
+	assert.NoError(t, repo.Save(cursor))
+	assert.NoError(t, repo.Save(entity.NewReadCursor("u2", entity.TeamThread("t1"))))
+
+	cursors, err := repo.GetByUser("u1")
+	assert.NoError(t, err)
+	assert.Len(t, cursors, 1)
+	assert.Equal(t, "m1", cursors[0].LastReadMessageID)
+
+	_, err = repo.Get("u1", entity.TeamThread("t2"))
+	assert.EqualError(t, err, persistence.ReadCursorNotFound)
+}
+
+func TestPostgresNotificationRepository_MarkAllRead(t *testing.T) {
+	repo := persistence.NewPostgresNotificationRepository(newPostgresDB(t))
+	template := entity.Notification{Type: entity.NotificationMention, Text: "hi"}
+	first := entity.NewNotification("n1", "u1", template)
+	first.CreatedAt = first.CreatedAt.Add(-time.Minute)
+	assert.NoError(t, repo.Create(first))
+	assert.NoError(t, repo.Create(entity.NewNotification("n2", "u1", template)))
+	assert.NoError(t, repo.Create(entity.NewNotification("n3", "u2", template)))
+	counts, err := repo.CountUnread("u1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[entity.NotificationType]int{entity.NotificationMention: 2}, counts)
+
+	marked, err := repo.MarkAllRead("u1", time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, marked)
+	counts, err = repo.CountUnread("u1")
+	assert.NoError(t, err)
+	assert.Empty(t, counts)
+
+	notifications, err := repo.GetByUserID("u1")
+	assert.NoError(t, err)
+	assert.Equal(t, "n2", notifications[0].ID)
+	assert.True(t, notifications[1].IsRead())
+	assert.Empty(t, notifications[1].UnreadBy)
+
+	other, err := repo.GetByID("n3")
+	assert.NoError(t, err)
+	assert.False(t, other.IsRead())
+}
+
+func TestPostgresUserRepository_GetByIDs(t *testing.T) {
+	repo := persistence.NewPostgresUserRepository(newPostgresDB(t))
+	for _, id := range []string{"u1", "u2", "u3"} {
+		assert.NoError(t, repo.Create(&entity.User{ID: id, Email: id + "@example.com", Username: id}))
+	}
+
+	users, err := repo.GetByIDs([]string{"u3", "u1", "missing"})
+
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, "u1", users[0].ID)
+	assert.Equal(t, "u3", users[1].ID)
+}
+
+func TestPostgresHubEventRepository_NumbersEventsPerUser(t *testing.T) {
+	repo := persistence.NewPostgresHubEventRepository(newPostgresDB(t))
+	for _, userID := range []string{"u1", "u2", "u1", "u1"} {
+		_, err := repo.Append(userID, []byte(`{"type":"direct_message"}`))
+		assert.NoError(t, err)
+	}
+
+	u1, err := repo.ListSince("u1", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, eventSeqs(u1))
+	assert.JSONEq(t, `{"type":"direct_message"}`, string(u1[0].Data))
+
+	u2, err := repo.ListSince("u2", 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1}, eventSeqs(u2))
+}
+
+func TestPostgresPresenceRepository_GetByUserIDs(t *testing.T) {
+	repo := persistence.NewPostgresPresenceRepository(newPostgresDB(t))
+	assert.NoError(t, repo.Save(entity.NewPresence("u1", entity.PresenceOnline)))
+	assert.NoError(t, repo.Save(entity.NewPresence("u2", entity.PresenceIdle)))
+	assert.NoError(t, repo.Save(entity.NewPresence("u1", entity.PresenceOffline)))
+
+	presences, err := repo.GetByUserIDs([]string{"u1", "missing"})
+
+	assert.NoError(t, err)
+	assert.Len(t, presences, 1)
+	assert.Equal(t, entity.PresenceOffline, presences[0].Status)
+}
+
+func TestPostgresQuizRepository_Pagination(t *testing.T) {
+	repo := persistence.NewPostgresQuizRepository(newPostgresDB(t))
+	for _, id := range []string{"q1", "q2", "q3", "q4", "q5"} {
+		assert.NoError(t, repo.Create(*entity.NewQuiz(id, "quiz "+id, tests.TestUserID, tests.TestTeamID, nil)))
+	}
+
+	first, lastKey, err := repo.GetByUserAndTeam(tests.TestUserID, tests.TestTeamID, 2, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"q1", "q2"}, []string{first[0].ID, first[1].ID})
+
+	rest, _, err := repo.GetByUser(tests.TestUserID, 0, lastKey)
+	assert.NoError(t, err)
+	assert.Len(t, rest, 3)
+}
+
+func TestPostgresFriendRequestRepository_Friends(t *testing.T) {
+	repo := persistence.NewPostgresFriendRequestRepository(newPostgresDB(t))
+
+	_, err := repo.GetByUsers("a", "b")
+	assert.ErrorIs(t, err, persistence.ErrFriendRequestNotFound)
+
+	accepted := entity.NewFriendRequest("a", "b")
+	accepted.Status = entity.ACCEPTED
+	assert.NoError(t, repo.Create(accepted))
+	reverse := entity.NewFriendRequest("d", "a")
+	reverse.Status = entity.ACCEPTED
+	assert.NoError(t, repo.Create(reverse))
+	assert.NoError(t, repo.Create(entity.NewFriendRequest("c", "a")))
+
+	friends, err := repo.GetFriendsForUser("a")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"b", "d"}, friends)
+
+	pending, err := repo.GetPendingRequestsForUser("a")
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, "c", pending[0].FromUserID)
+}
+
+func TestPostgresFileRepository_GetPageByContextID(t *testing.T) {
+	repo := persistence.NewPostgresFileRepository(newPostgresDB(t))
+	for i, id := range []string{"f3", "f1", "f4", "f2"} {
+		assert.NoError(t, repo.Create(&entity.File{ID: id, ContextType: entity.FileContextTeam, ContextID: tests.TestTeamID, CreatedAt: int64(i)}))
+	}
+
+	page, total, err := repo.GetPageByContextID(entity.FileContextTeam, tests.TestTeamID, 1, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, []string{"f1", "f4"}, []string{page[0].ID, page[1].ID})
+}
+
+func TestPostgresSessionRepository_RevokedTokens(t *testing.T) {
+	repo := persistence.NewPostgresSessionRepository(newPostgresDB(t))
+
+	revoked, err := repo.IsTokenRevoked("jti-1")
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	assert.NoError(t, repo.RevokeToken(&entity.RevokedToken{JTI: "jti-1", ExpiresAt: time.Now().Add(time.Hour)}))
+	revoked, err = repo.IsTokenRevoked("jti-1")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestPostgresSessionRepository_PurgesExpiredRevokedTokens(t *testing.T) {
+	repo := persistence.NewPostgresSessionRepository(newPostgresDB(t))
+	assert.NoError(t, repo.RevokeToken(&entity.RevokedToken{JTI: "expired", ExpiresAt: time.Now().Add(-time.Minute)}))
+
+	assert.NoError(t, repo.RevokeToken(&entity.RevokedToken{JTI: "jti-1", ExpiresAt: time.Now().Add(time.Hour)}))
+
+	revoked, err := repo.IsTokenRevoked("expired")
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+	revoked, err = repo.IsTokenRevoked("jti-1")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestPostgresTeamRequestRepository_UpdateIfStatus(t *testing.T) {
+	repo := persistence.NewPostgresTeamRequestRepository(newPostgresDB(t))
+	assert.NoError(t, repo.Create(entity.NewTeamRequest("r1", tests.TestUserID1, tests.TestTeamID, "", time.Now().Add(time.Hour))))
+
+	accepted, _ := repo.GetById("r1")
+	accepted.Resolve(entity.TeamRequestAccepted, tests.TestUserID, time.Now())
+	updated, err := repo.UpdateIfStatus(accepted, entity.TeamRequestPending)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	accepted.Resolve(entity.TeamRequestRejected, tests.TestUserID2, time.Now())
+	updated, err = repo.UpdateIfStatus(accepted, entity.TeamRequestPending)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+
+	stored, _ := repo.GetById("r1")
+	assert.Equal(t, entity.TeamRequestAccepted, stored.Status)
+}
+
+func TestNewRepository_UsesPostgresWhenSelected(t *testing.T) {
+	db, err := sql.Open("postgres", "postgres://localhost/unused")
+	assert.NoError(t, err)
+	defer db.Close()
+	persistence.UsePostgres(db)
+	defer persistence.UsePostgres(nil)
+
+	assert.IsType(t, &persistence.PostgresUserRepository{}, persistence.NewUserRepository())
+	assert.IsType(t, &persistence.PostgresTeamRepository{}, persistence.NewTeamRepository())
+	assert.IsType(t, &persistence.PostgresMessageRepository{}, persistence.NewMessageRepository())
+	assert.IsType(t, &persistence.PostgresQuizRepository{}, persistence.NewQuizRepository())
+	assert.IsType(t, &persistence.PostgresEventRepository{}, persistence.NewEventRepository())
+	assert.IsType(t, &persistence.PostgresFileRepository{}, persistence.NewFileRepository())
+	assert.IsType(t, &persistence.PostgresFriendRequestRepository{}, persistence.NewFriendRequestRepository())
+	assert.IsType(t, &persistence.PostgresTeamRequestRepository{}, persistence.NewTeamRequestRepository())
+	assert.IsType(t, &persistence.PostgresSessionRepository{}, persistence.NewSessionRepository())
+	assert.IsType(t, &persistence.PostgresUserTokenRepository{}, persistence.NewUserTokenRepository())
+}
+
+func TestMigrator_MemoryToPostgres(t *testing.T) {
+	db := newPostgresDB(t)
+	source := newMemoryStore(t)
+	seedSource(t, source)
+	migrator, err := persistence.NewMigrator(persistence.NewLocalBackend(source), persistence.NewPostgresBackend(db),
+		persistence.MigrationOptions{BatchSize: 2})
+	assert.NoError(t, err)
+
+	reports, err := migrator.Run()
+
+	assert.NoError(t, err)
+	for _, report := range reports {
+		assert.True(t, report.Verified, report.Collection)
+	}
+	quizzes, _, err := persistence.NewPostgresQuizRepository(db).GetByUserAndTeam("u1", tests.TestTeamID, 10, "")
+	assert.NoError(t, err)
+	assert.Len(t, quizzes, 1)
+}
+
+func TestPostgresUnitOfWork_UpdateChangesTheStoredDocument(t *testing.T) {
+	db := newPostgresDB(t)
+	persistence.UsePostgres(db)
+	defer persistence.UsePostgres(nil)
+	teams := persistence.NewPostgresTeamRepository(db)
+	assert.NoError(t, teams.Create(&entity.Team{Id: tests.TestTeamID, Name: "Team", UsersIds: []string{"u1"}}))
+
+	uow := persistence.NewUnitOfWorkFactory().Begin()
+	uow.UpdateTeam(tests.TestTeamID, func(team *entity.Team) error {
+		team.UsersIds = append(team.UsersIds, "u3")
+		return nil
+	})
+	assert.NoError(t, teams.Update(&entity.Team{Id: tests.TestTeamID, Name: "Team", UsersIds: []string{"u1", "u2"}}))
+
+	assert.NoError(t, uow.Commit())
+	team, err := teams.GetTeamById(tests.TestTeamID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2", "u3"}, team.UsersIds)
+}
+
+func TestPostgresUnitOfWork_RollsBackOnFailure(t *testing.T) {
+	db := newPostgresDB(t)
+	persistence.UsePostgres(db)
+	defer persistence.UsePostgres(nil)
+	_, err := db.Exec(`ALTER TABLE teams ADD CONSTRAINT teams_name_not_empty CHECK (name <> '')`)
+	assert.NoError(t, err)
+
+	uow := persistence.NewUnitOfWorkFactory().Begin()
+	uow.SaveUser(&entity.User{ID: tests.TestUserID, TeamsIds: &[]string{tests.TestTeamID}})
+	uow.SaveTeam(&entity.Team{Id: tests.TestTeamID})
+
+	assert.Error(t, uow.Commit())
+	_, err = persistence.NewPostgresUserRepository(db).GetByID(tests.TestUserID)
+	assert.EqualError(t, err, "user not found")
+}
@@ -0,0 +1,118 @@
+package search_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func hitIDs(hits []search.Hit) []string {
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.Document.ID)
+	}
+	return ids
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"grafuri", "orientate", "curs", "3"}, search.Tokenize("Grafuri orientate — curs #3!"))
+	assert.Empty(t, search.Tokenize(" ?! "))
+}
+
+func TestIndex_MatchesEveryWordIgnoringCaseAndByPrefix(t *testing.T) {
+	idx := search.NewIndex()
+	idx.Put(&search.Document{Kind: search.KindMessage, ID: "m1", Text: "Meet for the Algebra exam"})
+	idx.Put(&search.Document{Kind: search.KindMessage, ID: "m2", Text: "algebra homework"})
+	idx.Put(&search.Document{Kind: search.KindFile, ID: "f1", Title: "exam-notes.pdf"})
+
+	hits, total := idx.Search(search.Query{Text: "ALG"})
+	assert.Equal(t, 2, total)
+	assert.ElementsMatch(t, []string{"m1", "m2"}, hitIDs(hits))
+
+	hits, _ = idx.Search(search.Query{Text: "algebra exam"})
+	assert.Equal(t, []string{"m1"}, hitIDs(hits))
+
+	hits, _ = idx.Search(search.Query{Text: "exam", Kinds: []search.Kind{search.KindFile}})
+	assert.Equal(t, []string{"f1"}, hitIDs(hits))
+}
+
+func TestIndex_RanksTitlesAndWholeWordsFirst(t *testing.T) {
+	idx := search.NewIndex()
+	now := time.Now()
+	idx.Put(&search.Document{Kind: search.KindEvent, ID: "text", Text: "graph theory", Time: now})
+	idx.Put(&search.Document{Kind: search.KindEvent, ID: "title", Title: "Graph theory", Time: now.Add(-time.Hour)})
+	idx.Put(&search.Document{Kind: search.KindEvent, ID: "prefix", Title: "Graphs", Time: now})
+	idx.Put(&search.Document{Kind: search.KindEvent, ID: "older", Text: "graph", Time: now.Add(-time.Hour)})
+
+	hits, _ := idx.Search(search.Query{Text: "graph"})
+	assert.Equal(t, []string{"title", "prefix", "text", "older"}, hitIDs(hits))
+}
+
+func TestIndex_PutReplacesAndRemoveForgets(t *testing.T) {
+	idx := search.NewIndex()
+	idx.Put(&search.Document{Kind: search.KindMessage, ID: "m1", Text: "first draft"})
+	idx.Put(&search.Document{Kind: search.KindMessage, ID: "m1", Text: "final version"})
+
+	_, total := idx.Search(search.Query{Text: "draft"})
+	assert.Equal(t, 0, total)
+	_, total = idx.Search(search.Query{Text: "final"})
+	assert.Equal(t, 1, total)
+
+	idx.Remove(search.KindMessage, "m1")
+	_, total = idx.Search(search.Query{Text: "final"})
+	assert.Equal(t, 0, total)
+	assert.Equal(t, 0, idx.Len())
+}
+
+func TestIndex_FiltersAndPages(t *testing.T) {
+	idx := search.NewIndex()
+	idx.Replace([]*search.Document{
+		{Kind: search.KindQuiz, ID: "q1", Title: "Quiz one", Scope: search.Scope{TeamID: "t1"}},
+		{Kind: search.KindQuiz, ID: "q2", Title: "Quiz two", Scope: search.Scope{TeamID: "t1"}},
+		{Kind: search.KindQuiz, ID: "q3", Title: "Quiz three", Scope: search.Scope{TeamID: "t2"}},
+		nil,
+	})
+	assert.True(t, idx.Built())
+
+	visible := func(doc *search.Document) bool { return doc.Scope.TeamID == "t1" }
+	hits, total := idx.Search(search.Query{Text: "quiz", Visible: visible, Limit: 1})
+	assert.Equal(t, 2, total)
+	assert.Len(t, hits, 1)
+
+	hits, _ = idx.Search(search.Query{Text: "quiz", Visible: visible, Offset: 1, Limit: 1})
+	assert.Len(t, hits, 1)
+	hits, _ = idx.Search(search.Query{Text: "quiz", Visible: visible, Offset: 2, Limit: 1})
+	assert.Empty(t, hits)
+
+	_, total = idx.Search(search.Query{Text: "quiz", TeamID: "t2"})
+	assert.Equal(t, 1, total)
+}
+
+func TestIndex_RebuildKeepsWritesMadeWhileReading(t *testing.T) {
+	idx := search.NewIndex()
+	idx.Put(&search.Document{Kind: search.KindMessage, ID: "deleted", Text: "secret plan"})
+
+	rebuild := idx.StartRebuild()
+	// read before these writes
+	read := []*search.Document{
+		{Kind: search.KindMessage, ID: "deleted", Text: "secret plan"},
+		{Kind: search.KindMessage, ID: "edited", Text: "old text"},
+	}
+	idx.Remove(search.KindMessage, "deleted")
+	idx.Put(&search.Document{Kind: search.KindMessage, ID: "edited", Text: "new text"})
+	idx.Put(&search.Document{Kind: search.KindMessage, ID: "sent", Text: "new message"})
+	rebuild.Finish(read)
+
+	_, total := idx.Search(search.Query{Text: "secret"})
+	assert.Equal(t, 0, total)
+	hits, _ := idx.Search(search.Query{Text: "new"})
+	assert.ElementsMatch(t, []string{"edited", "sent"}, hitIDs(hits))
+	assert.True(t, idx.Built())
+
+	// writes after the rebuild finished are not recorded for it any more
+	idx.Put(&search.Document{Kind: search.KindMessage, ID: "later", Text: "later"})
+	rebuild.Finish(nil)
+	assert.Equal(t, 3, idx.Len())
+}
@@ -19,7 +19,7 @@ func TestEventService_CreateEvent_Success(t *testing.T) {
 
 	es := service.NewEventServiceWithRepo(mockEventRepo, mockTeamRepo, mockUserRepo)
 
-	teamMembers := []string{tests.TestUserID1, tests.TestUserID2}
+	teamMembers := []string{tests.TestUserID, tests.TestUserID1, tests.TestUserID2}
 	team := &entity.Team{
 		Id:       tests.TestTeamID,
 		UsersIds: teamMembers,
@@ -71,6 +71,26 @@ func TestEventService_CreateEvent_TeamNotFound(t *testing.T) {
 	mockEventRepo.AssertNotCalled(t, "Create", mock.Anything)
 }
 
+func TestEventService_CreateEvent_NotMember(t *testing.T) {
+	mockEventRepo := new(tests.MockEventRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	mockNotifier := new(tests.MockNotifier)
+	es := service.NewEventServiceWithRepo(mockEventRepo, mockTeamRepo, mockUserRepo)
+	es.SetNotifier(mockNotifier)
+
+	request := tests.GetValidCreateEventRequest()
+	mockUserRepo.On("GetByID", tests.TestUserID).Return(&entity.User{ID: tests.TestUserID}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1}}, nil)
+
+	resp, err := es.CreateEvent(&request)
+
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+	assert.Nil(t, resp)
+	mockEventRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockNotifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
 func TestEventService_CreateEvent_UserNotFound(t *testing.T) {
 	mockEventRepo := new(tests.MockEventRepository)
 	mockTeamRepo := new(tests.MockTeamRepository)
@@ -100,8 +120,9 @@ func TestEventService_GetEventById_Success(t *testing.T) {
 	event := tests.GetValidEvent() // Use function
 
 	mockEventRepo.On("GetByID", tests.TestEventID).Return(&event, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1}}, nil)
 
-	resp, err := es.GetEventById(tests.TestEventID)
+	resp, err := es.GetEventById(tests.TestUserID1, tests.TestEventID)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
@@ -111,6 +132,39 @@ func TestEventService_GetEventById_Success(t *testing.T) {
 	mockEventRepo.AssertExpectations(t)
 }
 
+func TestEventService_GetEventById_PrivateTeamHidden(t *testing.T) {
+	mockEventRepo := new(tests.MockEventRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	es := service.NewEventServiceWithRepo(mockEventRepo, mockTeamRepo, mockUserRepo)
+
+	event := tests.GetValidEvent()
+	mockEventRepo.On("GetByID", tests.TestEventID).Return(&event, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1}}, nil)
+
+	resp, err := es.GetEventById(tests.TestUserID2, tests.TestEventID)
+
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+	assert.Nil(t, resp)
+}
+
+func TestEventService_GetEventById_NotMemberOfPublicTeam(t *testing.T) {
+	mockEventRepo := new(tests.MockEventRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	es := service.NewEventServiceWithRepo(mockEventRepo, mockTeamRepo, mockUserRepo)
+
+	event := tests.GetValidEvent()
+	mockEventRepo.On("GetByID", tests.TestEventID).Return(&event, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).
+		Return(&entity.Team{Id: tests.TestTeamID, IsPublic: true, UsersIds: []string{tests.TestUserID1}}, nil)
+
+	resp, err := es.GetEventById(tests.TestUserID2, tests.TestEventID)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	assert.Nil(t, resp)
+}
+
 func TestEventService_GetEventById_NotFound(t *testing.T) {
 	mockEventRepo := new(tests.MockEventRepository)
 	mockTeamRepo := new(tests.MockTeamRepository)
@@ -119,13 +173,14 @@ func TestEventService_GetEventById_NotFound(t *testing.T) {
 
 	mockEventRepo.On("GetByID", "invalid-id").Return(nil, fmt.Errorf(tests.ErrEventNotFound))
 
-	resp, err := es.GetEventById("invalid-id")
+	resp, err := es.GetEventById(tests.TestUserID1, "invalid-id")
 
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
 	assert.Nil(t, resp)
 	assert.Contains(t, err.Error(), tests.ErrEventNotFound)
 
 	mockEventRepo.AssertExpectations(t)
+	mockTeamRepo.AssertNotCalled(t, "GetTeamById", mock.Anything)
 }
 
 func TestEventService_GetEventsByTeamId_Success(t *testing.T) {
@@ -139,9 +194,10 @@ func TestEventService_GetEventsByTeamId_Success(t *testing.T) {
 	event2.ID = "event456"
 	events := []*entity.Event{&event1, &event2}
 
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID}}, nil)
 	mockEventRepo.On("GetByTeamID", tests.TestTeamID).Return(events, nil)
 
-	resp, err := es.GetEventsByTeamId(tests.TestTeamID)
+	resp, err := es.GetEventsByTeamId(tests.TestUserID, tests.TestTeamID)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
@@ -150,6 +206,35 @@ func TestEventService_GetEventsByTeamId_Success(t *testing.T) {
 	mockEventRepo.AssertExpectations(t)
 }
 
+func TestEventService_GetEventsByTeamId_NotMember(t *testing.T) {
+	mockEventRepo := new(tests.MockEventRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	es := service.NewEventServiceWithRepo(mockEventRepo, mockTeamRepo, mockUserRepo)
+
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, IsPublic: true, UsersIds: []string{tests.TestUserID1}}, nil)
+
+	resp, err := es.GetEventsByTeamId(tests.TestUserID2, tests.TestTeamID)
+
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	mockEventRepo.AssertNotCalled(t, "GetByTeamID", mock.Anything)
+}
+
+func TestEventService_GetEventsByTeamId_PrivateTeamHidden(t *testing.T) {
+	mockEventRepo := new(tests.MockEventRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	es := service.NewEventServiceWithRepo(mockEventRepo, mockTeamRepo, mockUserRepo)
+
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1}}, nil)
+
+	_, err := es.GetEventsByTeamId(tests.TestUserID2, tests.TestTeamID)
+
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+	mockEventRepo.AssertNotCalled(t, "GetByTeamID", mock.Anything)
+}
+
 func TestEventService_UpdateEventDetails_Success(t *testing.T) {
 	mockEventRepo := new(tests.MockEventRepository)
 	mockTeamRepo := new(tests.MockTeamRepository)
@@ -165,7 +250,7 @@ func TestEventService_UpdateEventDetails_Success(t *testing.T) {
 			updates["description"] == request.Description
 	})).Return(nil)
 
-	resp, err := es.UpdateEventDetails(tests.TestEventID, &request)
+	resp, err := es.UpdateEventDetails(tests.TestUserID, tests.TestEventID, &request)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
@@ -175,6 +260,113 @@ func TestEventService_UpdateEventDetails_Success(t *testing.T) {
 	mockEventRepo.AssertExpectations(t)
 }
 
+func TestEventService_CreateEvent_NotifiesMembers(t *testing.T) {
+	mockEventRepo := new(tests.MockEventRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	mockNotifier := new(tests.MockNotifier)
+	es := service.NewEventServiceWithRepo(mockEventRepo, mockTeamRepo, mockUserRepo)
+	es.SetNotifier(mockNotifier)
+
+	members := []string{tests.TestUserID, tests.TestUserID1}
+	request := tests.GetValidCreateEventRequest()
+	mockUserRepo.On("GetByID", tests.TestUserID).Return(&entity.User{ID: tests.TestUserID}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: members}, nil)
+	mockEventRepo.On("Create", mock.AnythingOfType("*entity.Event")).Return(nil)
+	// the notifier leaves out the actor, so every member is passed on
+	mockNotifier.On("Notify", mock.MatchedBy(func(n entity.Notification) bool {
+		return n.Type == entity.NotificationEventInvite && n.ActorID == tests.TestUserID && n.TeamID == tests.TestTeamID
+	}), members).Return()
+
+	_, err := es.CreateEvent(&request)
+
+	assert.NoError(t, err)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestEventService_UpdateEventDetails_NotifiesWhenRescheduled(t *testing.T) {
+	mockEventRepo := new(tests.MockEventRepository)
+	mockNotifier := new(tests.MockNotifier)
+	es := service.NewEventServiceWithRepo(mockEventRepo, nil, nil)
+	es.SetNotifier(mockNotifier)
+
+	existingEvent := tests.GetValidEvent()
+	request := tests.GetValidUpdateEventRequest()
+	mockEventRepo.On("GetByID", tests.TestEventID).Return(&existingEvent, nil)
+	mockEventRepo.On("Update", tests.TestEventID, mock.Anything).Return(nil)
+	mockNotifier.On("Notify", mock.MatchedBy(func(n entity.Notification) bool {
+		return n.Type == entity.NotificationEventRescheduled && n.ResourceID == tests.TestEventID
+	}), []string{tests.TestUserID1, tests.TestUserID2}).Return()
+
+	_, err := es.UpdateEventDetails(tests.TestUserID, tests.TestEventID, &request)
+
+	assert.NoError(t, err)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestEventService_UpdateEventDetails_RenameDoesNotNotify(t *testing.T) {
+	mockEventRepo := new(tests.MockEventRepository)
+	mockNotifier := new(tests.MockNotifier)
+	es := service.NewEventServiceWithRepo(mockEventRepo, nil, nil)
+	es.SetNotifier(mockNotifier)
+
+	existingEvent := tests.GetValidEvent()
+	mockEventRepo.On("GetByID", tests.TestEventID).Return(&existingEvent, nil)
+	mockEventRepo.On("Update", tests.TestEventID, mock.Anything).Return(nil)
+
+	_, err := es.UpdateEventDetails(tests.TestUserID, tests.TestEventID, &dto.UpdateEventRequest{Name: "Renamed", Duration: tests.TestEventDuration})
+
+	assert.NoError(t, err)
+	mockNotifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestEventService_UpdateEventDetails_RejectsOtherMembers(t *testing.T) {
+	mockEventRepo := new(tests.MockEventRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	mockNotifier := new(tests.MockNotifier)
+	es := service.NewEventServiceWithRepo(mockEventRepo, mockTeamRepo, nil)
+	es.SetNotifier(mockNotifier)
+
+	existingEvent := tests.GetValidEvent()
+	request := tests.GetValidUpdateEventRequest()
+	mockEventRepo.On("GetByID", tests.TestEventID).Return(&existingEvent, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).
+		Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID, tests.TestUserID1}}, nil)
+
+	resp, err := es.UpdateEventDetails(tests.TestUserID1, tests.TestEventID, &request)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	assert.Nil(t, resp)
+	mockEventRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	mockNotifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestEventService_UpdateEventDetails_AdminIsTheActor(t *testing.T) {
+	mockEventRepo := new(tests.MockEventRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	mockNotifier := new(tests.MockNotifier)
+	es := service.NewEventServiceWithRepo(mockEventRepo, mockTeamRepo, nil)
+	es.SetNotifier(mockNotifier)
+
+	existingEvent := tests.GetValidEvent()
+	request := tests.GetValidUpdateEventRequest()
+	mockEventRepo.On("GetByID", tests.TestEventID).Return(&existingEvent, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{
+		Id:       tests.TestTeamID,
+		UsersIds: []string{tests.TestUserID, tests.TestUserID1},
+		Roles:    map[string]entity.TeamRole{tests.TestUserID1: entity.TeamRoleAdmin},
+	}, nil)
+	mockEventRepo.On("Update", tests.TestEventID, mock.Anything).Return(nil)
+	mockNotifier.On("Notify", mock.MatchedBy(func(n entity.Notification) bool {
+		return n.Type == entity.NotificationEventRescheduled && n.ActorID == tests.TestUserID1
+	}), mock.Anything).Return()
+
+	_, err := es.UpdateEventDetails(tests.TestUserID1, tests.TestEventID, &request)
+
+	assert.NoError(t, err)
+	mockNotifier.AssertExpectations(t)
+}
+
 func TestEventService_UpdateEventDetails_EventNotFound(t *testing.T) {
 	mockEventRepo := new(tests.MockEventRepository)
 	mockTeamRepo := new(tests.MockTeamRepository)
@@ -185,7 +377,7 @@ func TestEventService_UpdateEventDetails_EventNotFound(t *testing.T) {
 
 	mockEventRepo.On("GetByID", "invalid-id").Return(nil, fmt.Errorf(tests.ErrEventNotFound))
 
-	resp, err := es.UpdateEventDetails("invalid-id", &request)
+	resp, err := es.UpdateEventDetails(tests.TestUserID, "invalid-id", &request)
 
 	assert.Error(t, err)
 	assert.Nil(t, resp)
@@ -204,6 +396,7 @@ func TestEventService_UpdateUserStatus_Success(t *testing.T) {
 	user := &entity.User{ID: request.UserID}
 
 	mockEventRepo.On("GetByID", tests.TestEventID).Return(&existingEvent, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1, tests.TestUserID2}}, nil)
 	mockUserRepo.On("GetByID", request.UserID).Return(user, nil)
 	mockEventRepo.On("Update", tests.TestEventID, mock.MatchedBy(func(updates map[string]interface{}) bool {
 		if statuses, ok := updates["statuses"].(map[string]entity.EventStatus); ok {
@@ -237,6 +430,7 @@ func TestEventService_UpdateUserStatus_SameStatusConflict(t *testing.T) {
 	user := &entity.User{ID: request.UserID}
 
 	mockEventRepo.On("GetByID", tests.TestEventID).Return(&existingEvent, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1, tests.TestUserID2}}, nil)
 	mockUserRepo.On("GetByID", request.UserID).Return(user, nil)
 
 	resp, err := es.UpdateUserStatus(tests.TestEventID, request)
@@ -263,6 +457,7 @@ func TestEventService_UpdateUserStatus_InvalidStatus(t *testing.T) {
 	user := &entity.User{ID: request.UserID}
 
 	mockEventRepo.On("GetByID", tests.TestEventID).Return(&existingEvent, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1, tests.TestUserID2}}, nil)
 	mockUserRepo.On("GetByID", request.UserID).Return(user, nil)
 
 	resp, err := es.UpdateUserStatus(tests.TestEventID, request)
@@ -284,6 +479,7 @@ func TestEventService_UpdateUserStatus_UserNotFound(t *testing.T) {
 	request := &tests.ValidUpdateEventStatusRequest
 
 	mockEventRepo.On("GetByID", tests.TestEventID).Return(&existingEvent, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1, tests.TestUserID2}}, nil)
 	mockUserRepo.On("GetByID", request.UserID).Return(nil, fmt.Errorf("user not found"))
 
 	resp, err := es.UpdateUserStatus(tests.TestEventID, request)
@@ -294,6 +490,24 @@ func TestEventService_UpdateUserStatus_UserNotFound(t *testing.T) {
 	mockEventRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
 }
 
+func TestEventService_UpdateUserStatus_NotMember(t *testing.T) {
+	mockEventRepo := new(tests.MockEventRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	es := service.NewEventServiceWithRepo(mockEventRepo, mockTeamRepo, mockUserRepo)
+
+	existingEvent := tests.GetValidEvent()
+	mockEventRepo.On("GetByID", tests.TestEventID).Return(&existingEvent, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).
+		Return(&entity.Team{Id: tests.TestTeamID, IsPublic: true, UsersIds: []string{tests.TestUserID1}}, nil)
+
+	resp, err := es.UpdateUserStatus(tests.TestEventID, &dto.UpdateEventStatusRequest{UserID: tests.TestUserID2, Status: string(entity.StatusAccepted)})
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	assert.Nil(t, resp)
+	mockEventRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
 func TestEventService_DeleteEvent_Success(t *testing.T) {
 	mockEventRepo := new(tests.MockEventRepository)
 	mockTeamRepo := new(tests.MockTeamRepository)
@@ -305,7 +519,7 @@ func TestEventService_DeleteEvent_Success(t *testing.T) {
 	mockEventRepo.On("GetByID", tests.TestEventID).Return(&event, nil)
 	mockEventRepo.On("Delete", tests.TestEventID).Return(nil)
 
-	err := es.DeleteEvent(tests.TestEventID)
+	err := es.DeleteEvent(tests.TestEventID, tests.TestUserID)
 
 	assert.NoError(t, err)
 
@@ -320,7 +534,7 @@ func TestEventService_DeleteEvent_NotFound(t *testing.T) {
 
 	mockEventRepo.On("GetByID", "invalid-id").Return(nil, fmt.Errorf(tests.ErrEventNotFound))
 
-	err := es.DeleteEvent("invalid-id")
+	err := es.DeleteEvent("invalid-id", tests.TestUserID)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), tests.ErrEventNotFound)
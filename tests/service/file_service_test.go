@@ -97,7 +97,7 @@ func TestFileService_GetFilesByTeam_Success(t *testing.T) {
 	}}
 
 	mockUserRepo.On("GetByID", userID).Return(&entity.User{ID: userID, TeamsIds: &teamIDs}, nil)
-	mockFileRepo.On("GetByContextID", entity.FileContextTeam, teamID).Return(files, nil)
+	mockFileRepo.On("GetPageByContextID", entity.FileContextTeam, teamID, 0, 10).Return(files, 1, nil)
 
 	got, err := fs.GetFilesByTeam(teamID, userID, 1, 10)
 	assert.NoError(t, err)
@@ -109,3 +109,101 @@ func TestFileService_GetFilesByTeam_Success(t *testing.T) {
 	mockFileRepo.AssertExpectations(t)
 	mockUserRepo.AssertExpectations(t)
 }
+
+func TestFileService_GetFilesByTeam_PagesInRepository(t *testing.T) {
+	mockFileRepo := new(tests.MockFileRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	fs := service.NewFileServiceWithRepo(mockFileRepo, mockUserRepo, mockTeamRepo)
+
+	userID := "user1"
+	teamID := "team1"
+	teamIDs := []string{teamID}
+	files := []*entity.File{{ID: "6", ContextType: entity.FileContextTeam, ContextID: teamID}}
+
+	mockUserRepo.On("GetByID", userID).Return(&entity.User{ID: userID, TeamsIds: &teamIDs}, nil)
+	mockFileRepo.On("GetPageByContextID", entity.FileContextTeam, teamID, 5, 5).Return(files, 12, nil)
+
+	got, err := fs.GetFilesByTeam(teamID, userID, 2, 5)
+	assert.NoError(t, err)
+	assert.Len(t, got.Files, 1)
+	assert.Equal(t, 12, got.TotalCount)
+	assert.Equal(t, 3, got.TotalPages)
+	assert.Equal(t, 2, got.Page)
+
+	mockFileRepo.AssertExpectations(t)
+}
+
+func TestFileService_CreateFile_ChatParticipantsOnly(t *testing.T) {
+	mockFileRepo := new(tests.MockFileRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	fs := service.NewFileServiceWithRepo(mockFileRepo, mockUserRepo, mockTeamRepo)
+
+	conversationKey := entity.GetConversationKey("user1", "user2")
+	req := &dto.FileUploadRequest{
+		Name:        "test.txt",
+		Type:        "text/plain",
+		Extension:   "txt",
+		Content:     "dGVzdA==",
+		Size:        4,
+		ContextType: entity.FileContextChat,
+		ContextID:   conversationKey,
+	}
+
+	mockUserRepo.On("GetByID", "user2").Return(&entity.User{ID: "user2"}, nil)
+	mockFileRepo.On("Create", mock.MatchedBy(func(f *entity.File) bool {
+		return f.OwnerID == "user1" && f.ContextType == entity.FileContextChat && f.ContextID == conversationKey
+	})).Return(nil).Once()
+
+	resp, err := fs.CreateFile(req, "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, conversationKey, resp.ContextID)
+
+	_, err = fs.CreateFile(req, "user3")
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	mockFileRepo.AssertExpectations(t)
+}
+
+func TestFileService_ChatFileAccess(t *testing.T) {
+	mockFileRepo := new(tests.MockFileRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	fs := service.NewFileServiceWithRepo(mockFileRepo, mockUserRepo, mockTeamRepo)
+
+	file := &entity.File{ID: "1", OwnerID: "user1", ContextType: entity.FileContextChat, ContextID: entity.GetConversationKey("user1", "user2")}
+	mockFileRepo.On("GetByID", "1").Return(file, nil)
+	mockFileRepo.On("Delete", "1").Return(nil).Once()
+
+	got, err := fs.GetFileByID("1", "user2")
+	assert.NoError(t, err)
+	assert.Equal(t, file, got)
+
+	_, err = fs.GetFileByID("1", "user3")
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	// the other participant can download the file but only the uploader can delete it
+	assert.ErrorIs(t, fs.DeleteFile("1", "user2"), service.ErrForbidden)
+	assert.NoError(t, fs.DeleteFile("1", "user1"))
+
+	mockFileRepo.AssertExpectations(t)
+}
+
+func TestFileService_ConversationFileOnlyFromItsConversation(t *testing.T) {
+	mockFileRepo := new(tests.MockFileRepository)
+	fs := service.NewFileServiceWithRepo(mockFileRepo, new(tests.MockUserRepository), new(tests.MockTeamRepository))
+
+	// shared by user1 with user3, and asked for through the conversation of user1 with user2
+	file := &entity.File{ID: "1", OwnerID: "user1", ContextType: entity.FileContextChat, ContextID: entity.GetConversationKey("user1", "user3")}
+	mockFileRepo.On("GetByID", "1").Return(file, nil)
+
+	_, err := fs.GetConversationFile("user2", "1", "user1")
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+	assert.ErrorIs(t, fs.DeleteConversationFile("user2", "1", "user1"), service.ErrResourceNotFound)
+	mockFileRepo.AssertNotCalled(t, "Delete", mock.Anything)
+
+	got, err := fs.GetConversationFile("user1", "1", "user3")
+	assert.NoError(t, err)
+	assert.Equal(t, file, got)
+}
@@ -14,38 +14,53 @@ import (
 func TestFriendRequestService_SendFriendRequest_Success(t *testing.T) {
 	mockRepo := new(tests.MockFriendRequestRepository)
 	mockUserService := new(tests.MockUserService)
+	mockNotifier := new(tests.MockNotifier)
 
 	service := service.NewFriendRequestService()
 	service.SetFriendRequestRepo(mockRepo)
 	service.SetUserService(mockUserService)
+	service.SetNotifier(mockNotifier)
 
-	mockUserService.On("GetUserByID", "user1").Return(&entity.User{ID: "user1"}, nil)
+	mockUserService.On("GetUserByID", "user1").Return(&entity.User{ID: "user1", Username: "alice"}, nil)
 	mockUserService.On("GetUserByID", "user2").Return(&entity.User{ID: "user2"}, nil)
 	mockRepo.On("GetByUsers", "user1", "user2").Return(nil, fmt.Errorf("not found"))
 	mockRepo.On("Create", mock.AnythingOfType("*entity.FriendRequest")).Return(nil)
+	mockNotifier.On("Notify", mock.MatchedBy(func(n entity.Notification) bool {
+		return n.Type == entity.NotificationFriendRequest && n.ActorID == "user1" && n.Text == "alice sent you a friend request"
+	}), []string{"user2"}).Return()
 
 	err := service.SendFriendRequest("user1", "user2")
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 	mockUserService.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
 }
 
 func TestFriendRequestService_RespondToRequest_Accept(t *testing.T) {
 	mockRepo := new(tests.MockFriendRequestRepository)
+	mockUserService := new(tests.MockUserService)
+	mockNotifier := new(tests.MockNotifier)
 	service := service.NewFriendRequestService()
 	service.SetFriendRequestRepo(mockRepo)
+	service.SetUserService(mockUserService)
+	service.SetNotifier(mockNotifier)
 
 	request := tests.ValidFriendRequest
 	mockRepo.On("GetByUsers", "user1", "user2").Return(&request, nil)
 	mockRepo.On("Update", mock.MatchedBy(func(r *entity.FriendRequest) bool {
 		return r.Status == entity.ACCEPTED
 	})).Return(nil)
+	mockUserService.On("GetUserByID", "user2").Return(&entity.User{ID: "user2", Username: "bob"}, nil)
+	mockNotifier.On("Notify", mock.MatchedBy(func(n entity.Notification) bool {
+		return n.Type == entity.NotificationFriendRequestAccepted && n.ActorID == "user2" && n.Text == "bob accepted your friend request"
+	}), []string{"user1"}).Return()
 
 	err := service.RespondToFriendRequest("user1", "user2", true)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
 }
 
 func TestFriendRequestService_SendFriendRequest_InvalidSenderID(t *testing.T) {
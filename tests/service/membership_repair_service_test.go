@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func driftedData() ([]*entity.User, []*entity.Team) {
+	users := []*entity.User{
+		// lists a team that does not list them, and a team that does not exist
+		{ID: "u1", TeamsIds: &[]string{"t1", "t2", "gone"}},
+		// missing t1, which lists them
+		{ID: "u2", TeamsIds: &[]string{}},
+		// consistent
+		{ID: "u3", TeamsIds: &[]string{"t2"}},
+	}
+	teams := []*entity.Team{
+		{Id: "t1", UsersIds: []string{"u1", "u2", "u2", "deleted-user"},
+			Roles: map[string]entity.TeamRole{"u1": entity.TeamRoleOwner, "left": entity.TeamRoleAdmin}},
+		{Id: "t2", UsersIds: []string{"u3"}, Roles: map[string]entity.TeamRole{"u3": entity.TeamRoleOwner}},
+	}
+	return users, teams
+}
+
+func TestMembershipRepair_FixesDrift(t *testing.T) {
+	mockUserRepo := &tests.MockUserRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	repair := service.NewMembershipRepairServiceWithRepo(mockUserRepo, mockTeamRepo)
+
+	users, teams := driftedData()
+	stored, storedTeams := driftedData()
+	mockUserRepo.On("GetAll").Return(users, nil)
+	mockTeamRepo.On("GetAll").Return(teams, nil)
+	mockTeamRepo.On("GetTeamById", "t1").Return(storedTeams[0], nil)
+	mockUserRepo.On("GetByID", "u1").Return(stored[0], nil)
+	mockUserRepo.On("GetByID", "u2").Return(stored[1], nil)
+	mockTeamRepo.On("Update", mock.MatchedBy(func(team *entity.Team) bool {
+		return team.Id == "t1" && assert.ObjectsAreEqual([]string{"u1", "u2"}, team.UsersIds) &&
+			assert.ObjectsAreEqual(map[string]entity.TeamRole{"u1": entity.TeamRoleOwner}, team.Roles)
+	})).Return(nil).Once()
+	mockUserRepo.On("Update", mock.MatchedBy(func(user *entity.User) bool {
+		return (user.ID == "u1" || user.ID == "u2") && assert.ObjectsAreEqual([]string{"t1"}, *user.TeamsIds)
+	})).Return(nil).Twice()
+
+	report, err := repair.Repair(false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.TeamsChanged)
+	assert.Equal(t, 2, report.UsersChanged)
+	assert.Len(t, report.Issues, 6)
+	mockTeamRepo.AssertExpectations(t)
+	mockUserRepo.AssertExpectations(t)
+	mockUserRepo.AssertNotCalled(t, "GetByID", "u3")
+	mockTeamRepo.AssertNotCalled(t, "GetTeamById", "t2")
+}
+
+func TestMembershipRepair_LeavesConcurrentChangesAlone(t *testing.T) {
+	mockUserRepo := &tests.MockUserRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	repair := service.NewMembershipRepairServiceWithRepo(mockUserRepo, mockTeamRepo)
+
+	users, teams := driftedData()
+	stored, storedTeams := driftedData()
+	// after the repair read them, u4 joined t1 and u2 left t1
+	storedTeams[0].UsersIds = append(storedTeams[0].UsersIds, "u4")
+	stored[1].TeamsIds = &[]string{"t3"}
+	mockUserRepo.On("GetAll").Return(users, nil)
+	mockTeamRepo.On("GetAll").Return(teams, nil)
+	mockTeamRepo.On("GetTeamById", "t1").Return(storedTeams[0], nil)
+	mockUserRepo.On("GetByID", "u1").Return(stored[0], nil)
+	mockUserRepo.On("GetByID", "u2").Return(stored[1], nil)
+	mockUserRepo.On("Update", mock.MatchedBy(func(user *entity.User) bool { return user.ID == "u1" })).Return(nil).Once()
+
+	report, err := repair.Repair(false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.TeamsChanged)
+	assert.Equal(t, 1, report.UsersChanged)
+	assert.Contains(t, report.Issues, "team t1 changed during the repair and was left for the next run")
+	assert.Contains(t, report.Issues, "user u2 changed during the repair and was left for the next run")
+	mockTeamRepo.AssertNotCalled(t, "Update", mock.Anything)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestMembershipRepair_DryRunWritesNothing(t *testing.T) {
+	mockUserRepo := &tests.MockUserRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	repair := service.NewMembershipRepairServiceWithRepo(mockUserRepo, mockTeamRepo)
+
+	users, teams := driftedData()
+	mockUserRepo.On("GetAll").Return(users, nil)
+	mockTeamRepo.On("GetAll").Return(teams, nil)
+
+	report, err := repair.Repair(true)
+
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.NotEmpty(t, report.Issues)
+	mockUserRepo.AssertNotCalled(t, "Update", mock.Anything)
+	mockTeamRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestMembershipRepair_ConsistentDataIsUntouched(t *testing.T) {
+	mockUserRepo := &tests.MockUserRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	repair := service.NewMembershipRepairServiceWithRepo(mockUserRepo, mockTeamRepo)
+
+	mockUserRepo.On("GetAll").Return([]*entity.User{{ID: "u1", TeamsIds: &[]string{"t1"}}, {ID: "u2"}}, nil)
+	mockTeamRepo.On("GetAll").Return([]*entity.Team{{Id: "t1", UsersIds: []string{"u1"}}}, nil)
+
+	report, err := repair.Repair(false)
+
+	assert.NoError(t, err)
+	assert.Empty(t, report.Issues)
+	mockUserRepo.AssertNotCalled(t, "Update", mock.Anything)
+	mockTeamRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
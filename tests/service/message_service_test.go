@@ -0,0 +1,529 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func teamMessages(senders ...string) []*entity.Message {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	messages := make([]*entity.Message, 0, len(senders))
+	for i, sender := range senders {
+		message := entity.NewMessage(string(rune('a'+i)), sender, "", tests.TestTeamID, "text")
+		message.SentAt = start.Add(time.Duration(i) * time.Second)
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func newMessageServiceMocks() (*service.MessageService, *tests.MockUserRepository, *tests.MockTeamRepository, *tests.MockMessageRepository) {
+	ms, mockUserRepo, mockTeamRepo, mockMessageRepo, _ := newMessageServiceReadMocks()
+	return ms, mockUserRepo, mockTeamRepo, mockMessageRepo
+}
+
+func newMessageServiceReadMocks() (*service.MessageService, *tests.MockUserRepository, *tests.MockTeamRepository, *tests.MockMessageRepository, *tests.MockReadCursorRepository) {
+	ms, mockUserRepo, mockTeamRepo, mockMessageRepo, mockReadCursorRepo, _ := newMessageServiceFileMocks()
+	return ms, mockUserRepo, mockTeamRepo, mockMessageRepo, mockReadCursorRepo
+}
+
+func newMessageServiceFileMocks() (*service.MessageService, *tests.MockUserRepository, *tests.MockTeamRepository, *tests.MockMessageRepository, *tests.MockReadCursorRepository, *tests.MockFileRepository) {
+	mockUserRepo := new(tests.MockUserRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	mockMessageRepo := new(tests.MockMessageRepository)
+	mockReadCursorRepo := new(tests.MockReadCursorRepository)
+	mockFileRepo := new(tests.MockFileRepository)
+	ms := service.NewMessageServiceWithRepo(mockUserRepo, mockTeamRepo, mockMessageRepo, mockReadCursorRepo, mockFileRepo)
+	return ms, mockUserRepo, mockTeamRepo, mockMessageRepo, mockReadCursorRepo, mockFileRepo
+}
+
+func TestMessageService_GetTeamMessages_NewestPage(t *testing.T) {
+	ms, mockUserRepo, mockTeamRepo, mockMessageRepo := newMessageServiceMocks()
+	messages := teamMessages(tests.TestUserID1, tests.TestUserID2, tests.TestUserID1)
+
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1}}, nil)
+	// one more than the limit is requested to find out whether older messages remain
+	mockMessageRepo.On("GetTeamPage", tests.TestTeamID, persistence.MessagePageQuery{Limit: 3}).Return(messages, nil)
+	mockUserRepo.On("GetByIDs", []string{tests.TestUserID2, tests.TestUserID1}).
+		Return([]*entity.User{{ID: tests.TestUserID1}, {ID: tests.TestUserID2}}, nil).Once()
+
+	page, err := ms.GetTeamMessages(tests.TestUserID1, tests.TestTeamID, dto.MessagePageRequest{Limit: 2})
+
+	assert.NoError(t, err)
+	assert.True(t, page.HasMore)
+	assert.Len(t, page.Messages, 2)
+	assert.Equal(t, "b", page.Messages[0].ID)
+	assert.Equal(t, tests.TestUserID2, page.Messages[0].Sender.ID)
+	assert.Equal(t, "c", page.Messages[1].ID)
+	assert.Equal(t, messages[1].Cursor().Encode(), page.NextCursor)
+	mockUserRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestMessageService_GetTeamMessages_ForwardPage(t *testing.T) {
+	ms, mockUserRepo, mockTeamRepo, mockMessageRepo := newMessageServiceMocks()
+	messages := teamMessages(tests.TestUserID1, tests.TestUserID1)
+	after := entity.MessageCursor{SentAt: messages[0].SentAt.Add(-time.Second), ID: "start"}
+
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1}}, nil)
+	mockMessageRepo.On("GetTeamPage", tests.TestTeamID, mock.MatchedBy(func(q persistence.MessagePageQuery) bool {
+		return q.After != nil && q.After.ID == "start" && q.After.SentAt.Equal(after.SentAt) && q.Before == nil && q.Limit == 51
+	})).Return(messages, nil)
+	mockUserRepo.On("GetByIDs", []string{tests.TestUserID1, tests.TestUserID1}).Return([]*entity.User{{ID: tests.TestUserID1}}, nil)
+
+	page, err := ms.GetTeamMessages(tests.TestUserID1, tests.TestTeamID, dto.MessagePageRequest{After: after.Encode()})
+
+	assert.NoError(t, err)
+	assert.False(t, page.HasMore)
+	assert.Empty(t, page.NextCursor)
+	assert.Len(t, page.Messages, 2)
+}
+
+func TestMessageService_GetTeamMessages_InvalidPage(t *testing.T) {
+	ms, _, _, mockMessageRepo := newMessageServiceMocks()
+	cursor := entity.MessageCursor{SentAt: time.Now(), ID: "m1"}.Encode()
+
+	_, err := ms.GetTeamMessages(tests.TestUserID1, tests.TestTeamID, dto.MessagePageRequest{Before: "not a cursor"})
+	assert.ErrorIs(t, err, service.ErrInvalidCursor)
+
+	_, err = ms.GetTeamMessages(tests.TestUserID1, tests.TestTeamID, dto.MessagePageRequest{Before: cursor, After: cursor})
+	assert.ErrorIs(t, err, service.ErrInvalidCursor)
+
+	_, err = ms.GetTeamMessages(tests.TestUserID1, tests.TestTeamID, dto.MessagePageRequest{Limit: -1})
+	assert.ErrorIs(t, err, service.ErrInvalidPageLimit)
+
+	mockMessageRepo.AssertNotCalled(t, "GetTeamPage", mock.Anything, mock.Anything)
+}
+
+func TestMessageService_GetTeamMessages_NotMember(t *testing.T) {
+	ms, _, mockTeamRepo, mockMessageRepo := newMessageServiceMocks()
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, IsPublic: true, UsersIds: []string{tests.TestUserID1}}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID2).Return(&entity.Team{Id: tests.TestTeamID2, UsersIds: []string{tests.TestUserID1}}, nil)
+
+	_, err := ms.GetTeamMessages(tests.TestUserID2, tests.TestTeamID, dto.MessagePageRequest{})
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	// private teams are not found at all for non-members
+	_, err = ms.GetTeamMessages(tests.TestUserID2, tests.TestTeamID2, dto.MessagePageRequest{})
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+
+	mockMessageRepo.AssertNotCalled(t, "GetTeamPage", mock.Anything, mock.Anything)
+}
+
+func TestMessageService_GetDirectMessages_ClampsLimitAndKeepsDeletedSenders(t *testing.T) {
+	ms, mockUserRepo, _, mockMessageRepo := newMessageServiceMocks()
+	message := entity.NewMessage("m1", tests.TestUserID1, entity.GetConversationKey(tests.TestUserID1, tests.TestUserID2), "", "hi")
+
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1}, nil)
+	mockUserRepo.On("GetByID", tests.TestUserID2).Return(&entity.User{ID: tests.TestUserID2}, nil)
+	mockMessageRepo.On("GetConversationPage", tests.TestUserID1, tests.TestUserID2, persistence.MessagePageQuery{Limit: 101}).
+		Return([]*entity.Message{message}, nil)
+	mockUserRepo.On("GetByIDs", []string{tests.TestUserID1}).Return([]*entity.User{}, nil)
+
+	page, err := ms.GetDirectMessages(tests.TestUserID1, tests.TestUserID2, dto.MessagePageRequest{Limit: 1000})
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Messages, 1)
+	assert.Equal(t, dto.SenderDTO{ID: tests.TestUserID1}, page.Messages[0].Sender)
+}
+
+func TestMessageCursor_EncodeRoundTrip(t *testing.T) {
+	cursor := entity.MessageCursor{SentAt: time.Date(2025, 1, 1, 12, 0, 0, 123456789, time.UTC), ID: "a:b"}
+
+	parsed, err := entity.ParseMessageCursor(cursor.Encode())
+
+	assert.NoError(t, err)
+	assert.True(t, parsed.SentAt.Equal(cursor.SentAt))
+	assert.Equal(t, cursor.ID, parsed.ID)
+	_, err = entity.ParseMessageCursor("bm9wZQ")
+	assert.EqualError(t, err, entity.BadMessageCursor)
+}
+
+func TestMessageService_EditMessage_KeepsHistory(t *testing.T) {
+	ms, mockUserRepo, _, mockMessageRepo := newMessageServiceMocks()
+	message := entity.NewMessage("m1", tests.TestUserID1, entity.GetConversationKey(tests.TestUserID1, tests.TestUserID2), "", "helo")
+
+	mockMessageRepo.On("GetByID", "m1").Return(message, nil)
+	mockMessageRepo.On("Update", "m1", mock.MatchedBy(func(updates map[string]interface{}) bool {
+		history := updates["editHistory"].([]entity.MessageEdit)
+		return updates["textContent"] == "hello" && len(history) == 1 && history[0].TextContent == "helo" && updates["editedAt"] != nil
+	})).Return(nil)
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1}, nil)
+
+	resp, recipients, err := ms.EditMessage(tests.TestUserID1, "m1", &dto.EditMessageRequest{TextContent: "hello"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", resp.TextContent)
+	assert.NotEmpty(t, resp.EditedAt)
+	assert.Len(t, resp.EditHistory, 1)
+	assert.Equal(t, tests.TestUserID2, resp.ReceiverID)
+	assert.ElementsMatch(t, []string{tests.TestUserID1, tests.TestUserID2}, recipients)
+}
+
+func TestMessageService_EditMessage_OnlySenderInDirectMessages(t *testing.T) {
+	ms, _, _, mockMessageRepo := newMessageServiceMocks()
+	message := entity.NewMessage("m1", tests.TestUserID1, entity.GetConversationKey(tests.TestUserID1, tests.TestUserID2), "", "hi")
+	mockMessageRepo.On("GetByID", "m1").Return(message, nil)
+
+	_, _, err := ms.EditMessage(tests.TestUserID2, "m1", &dto.EditMessageRequest{TextContent: "changed"})
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	mockMessageRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestMessageService_DeleteMessage_TeamAdminLeavesTombstone(t *testing.T) {
+	ms, mockUserRepo, mockTeamRepo, mockMessageRepo := newMessageServiceMocks()
+	message := entity.NewMessage("m1", tests.TestUserID1, "", tests.TestTeamID, "spam")
+	team := &entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID, tests.TestUserID1}}
+	team.SetRole(tests.TestUserID, entity.TeamRoleAdmin)
+
+	mockMessageRepo.On("GetByID", "m1").Return(message, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(team, nil)
+	mockMessageRepo.On("Update", "m1", mock.MatchedBy(func(updates map[string]interface{}) bool {
+		return updates["textContent"] == "" && updates["deletedBy"] == tests.TestUserID && updates["deletedAt"] != nil
+	})).Return(nil)
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1}, nil)
+
+	resp, recipients, err := ms.DeleteMessage(tests.TestUserID, "m1")
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Deleted)
+	assert.Empty(t, resp.TextContent)
+	assert.Equal(t, team.UsersIds, recipients)
+}
+
+func TestMessageService_DeleteMessage_PlainMemberCannotDeleteOthers(t *testing.T) {
+	ms, _, mockTeamRepo, mockMessageRepo := newMessageServiceMocks()
+	message := entity.NewMessage("m1", tests.TestUserID1, "", tests.TestTeamID, "hi")
+	mockMessageRepo.On("GetByID", "m1").Return(message, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1, tests.TestUserID2}}, nil)
+
+	_, _, err := ms.DeleteMessage(tests.TestUserID2, "m1")
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+}
+
+func TestMessageService_EditMessage_DeletedMessage(t *testing.T) {
+	ms, _, _, mockMessageRepo := newMessageServiceMocks()
+	message := entity.NewMessage("m1", tests.TestUserID1, "", tests.TestTeamID, "")
+	deletedAt := time.Now()
+	message.DeletedAt = &deletedAt
+	mockMessageRepo.On("GetByID", "m1").Return(message, nil)
+
+	_, _, err := ms.EditMessage(tests.TestUserID1, "m1", &dto.EditMessageRequest{TextContent: "back"})
+
+	assert.ErrorIs(t, err, service.ErrMessageDeleted)
+}
+
+func TestMessageService_Typing_TeamMembersOnly(t *testing.T) {
+	ms, _, mockTeamRepo, _ := newMessageServiceMocks()
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).
+		Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1, tests.TestUserID2, tests.TestUserID}}, nil)
+
+	typing, recipients, err := ms.Typing(tests.TestUserID1, &dto.TypingRequest{TeamID: tests.TestTeamID})
+	assert.NoError(t, err)
+	assert.Equal(t, tests.TestUserID1, typing.UserID)
+	assert.Equal(t, []string{tests.TestUserID2, tests.TestUserID}, recipients)
+
+	_, _, err = ms.Typing("outsider", &dto.TypingRequest{TeamID: tests.TestTeamID})
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	_, _, err = ms.Typing(tests.TestUserID1, &dto.TypingRequest{TeamID: tests.TestTeamID, ReceiverID: tests.TestUserID2})
+	assert.Error(t, err)
+}
+
+func TestMessageService_MarkRead(t *testing.T) {
+	ms, _, _, mockMessageRepo, mockReadCursorRepo := newMessageServiceReadMocks()
+	message := entity.NewMessage("m1", tests.TestUserID1, entity.GetConversationKey(tests.TestUserID1, tests.TestUserID2), "", "hi")
+	mockMessageRepo.On("GetByID", "m1").Return(message, nil)
+	mockReadCursorRepo.On("Get", tests.TestUserID2, message.Thread()).Return(nil, errors.New(persistence.ReadCursorNotFound))
+	mockReadCursorRepo.On("Save", mock.MatchedBy(func(cursor *entity.ReadCursor) bool {
+		return cursor.UserID == tests.TestUserID2 && cursor.LastReadMessageID == "m1"
+	})).Return(nil).Once()
+
+	receipt, recipients, err := ms.MarkRead(tests.TestUserID2, &dto.ReadMessageRequest{MessageID: "m1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "m1", receipt.MessageID)
+	assert.Equal(t, tests.TestUserID2, receipt.UserID)
+	assert.Equal(t, []string{tests.TestUserID1}, recipients)
+	mockReadCursorRepo.AssertExpectations(t)
+
+	_, _, err = ms.MarkRead("outsider", &dto.ReadMessageRequest{MessageID: "m1"})
+	assert.ErrorIs(t, err, service.ErrForbidden)
+}
+
+func TestMessageService_MarkRead_TeamCursorOnlyMovesForward(t *testing.T) {
+	ms, _, mockTeamRepo, mockMessageRepo, mockReadCursorRepo := newMessageServiceReadMocks()
+	messages := teamMessages(tests.TestUserID1, tests.TestUserID2)
+	team := &entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1, tests.TestUserID2, "user3"}}
+	cursor := entity.NewReadCursor("user3", messages[0].Thread())
+	cursor.Advance(messages[1])
+
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(team, nil)
+	mockMessageRepo.On("GetByID", "a").Return(messages[0], nil)
+	mockReadCursorRepo.On("Get", "user3", messages[0].Thread()).Return(cursor, nil)
+
+	receipt, recipients, err := ms.MarkRead("user3", &dto.ReadMessageRequest{MessageID: "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, tests.TestTeamID, receipt.TeamID)
+	assert.Empty(t, recipients)
+	mockReadCursorRepo.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestMessageService_CreateDirectMessage_TracksReadCursors(t *testing.T) {
+	ms, mockUserRepo, _, mockMessageRepo, mockReadCursorRepo := newMessageServiceReadMocks()
+	thread := entity.ConversationThread(entity.GetConversationKey(tests.TestUserID1, tests.TestUserID2))
+
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1}, nil)
+	mockUserRepo.On("GetByID", tests.TestUserID2).Return(&entity.User{ID: tests.TestUserID2}, nil)
+	mockMessageRepo.On("Create", mock.Anything).Return(nil)
+	mockReadCursorRepo.On("Get", mock.Anything, thread).Return(nil, errors.New(persistence.ReadCursorNotFound))
+	// the sender has read their own message, the receiver has read nothing yet
+	mockReadCursorRepo.On("Save", mock.MatchedBy(func(cursor *entity.ReadCursor) bool {
+		return cursor.UserID == tests.TestUserID1 && cursor.LastReadMessageID != ""
+	})).Return(nil).Once()
+	mockReadCursorRepo.On("Save", mock.MatchedBy(func(cursor *entity.ReadCursor) bool {
+		return cursor.UserID == tests.TestUserID2 && cursor.Position() == nil
+	})).Return(nil).Once()
+
+	_, err := ms.CreateDirectMessage(&dto.DirectMessageRequest{SenderID: tests.TestUserID1, ReceiverID: tests.TestUserID2, TextContent: "hi"})
+	assert.NoError(t, err)
+	mockReadCursorRepo.AssertExpectations(t)
+}
+
+func TestMessageService_GetUnreadCounts(t *testing.T) {
+	ms, mockUserRepo, _, mockMessageRepo, mockReadCursorRepo := newMessageServiceReadMocks()
+	messages := teamMessages(tests.TestUserID2)
+	teams := []string{tests.TestTeamID}
+	conversation := entity.NewReadCursor(tests.TestUserID1, entity.ConversationThread(entity.GetConversationKey(tests.TestUserID1, tests.TestUserID2)))
+	team := entity.NewReadCursor(tests.TestUserID1, entity.TeamThread(tests.TestTeamID))
+	team.Advance(messages[0])
+
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1, TeamsIds: &teams}, nil)
+	mockReadCursorRepo.On("GetByUser", tests.TestUserID1).Return([]*entity.ReadCursor{conversation, team}, nil)
+	mockMessageRepo.On("CountConversation", tests.TestUserID1, tests.TestUserID2,
+		persistence.MessageCountQuery{ExcludeSender: tests.TestUserID1, Limit: 100}).Return(100, nil)
+	mockMessageRepo.On("CountTeam", tests.TestTeamID, persistence.MessageCountQuery{
+		After: team.Position(), ExcludeSender: tests.TestUserID1, Limit: 100,
+	}).Return(3, nil)
+
+	counts, err := ms.GetUnreadCounts(tests.TestUserID1)
+	assert.NoError(t, err)
+	assert.Len(t, counts.Conversations, 1)
+	assert.Equal(t, tests.TestUserID2, counts.Conversations[0].UserID)
+	assert.Equal(t, 99, counts.Conversations[0].Count)
+	assert.True(t, counts.Conversations[0].HasMore)
+	assert.Len(t, counts.Teams, 1)
+	assert.Equal(t, tests.TestTeamID, counts.Teams[0].TeamID)
+	assert.Equal(t, 3, counts.Teams[0].Count)
+	assert.False(t, counts.Teams[0].HasMore)
+	assert.Equal(t, "a", counts.Teams[0].LastReadMessageID)
+}
+
+func TestMessageService_ToggleReaction(t *testing.T) {
+	ms, mockUserRepo, _, mockMessageRepo := newMessageServiceMocks()
+	message := entity.NewMessage("m1", tests.TestUserID1, entity.GetConversationKey(tests.TestUserID1, tests.TestUserID2), "", "hi")
+	mockMessageRepo.On("GetByID", "m1").Return(message, nil)
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1}, nil)
+	mockMessageRepo.On("Update", "m1", mock.MatchedBy(func(updates map[string]interface{}) bool {
+		value, ok := updates["reactions/👍/"+tests.TestUserID2]
+		return ok && value != nil
+	})).Return(nil).Once()
+
+	resp, recipients, err := ms.ToggleReaction(tests.TestUserID2, &dto.ReactionRequest{MessageID: "m1", Emoji: "👍"})
+	assert.NoError(t, err)
+	assert.Equal(t, []dto.ReactionDTO{{Emoji: "👍", Count: 1, UserIDs: []string{tests.TestUserID2}}}, resp.Reactions)
+	assert.ElementsMatch(t, []string{tests.TestUserID1, tests.TestUserID2}, recipients)
+
+	// the same emoji again takes the reaction back
+	mockMessageRepo.On("Update", "m1", map[string]interface{}{"reactions/👍/" + tests.TestUserID2: nil}).Return(nil).Once()
+	resp, _, err = ms.ToggleReaction(tests.TestUserID2, &dto.ReactionRequest{MessageID: "m1", Emoji: "👍"})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Reactions)
+	mockMessageRepo.AssertExpectations(t)
+
+	_, _, err = ms.ToggleReaction("outsider", &dto.ReactionRequest{MessageID: "m1", Emoji: "👍"})
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	_, _, err = ms.ToggleReaction(tests.TestUserID2, &dto.ReactionRequest{MessageID: "m1", Emoji: "a/b"})
+	assert.Error(t, err)
+}
+
+func TestMessageService_ToggleReaction_LimitsEmojis(t *testing.T) {
+	ms, _, _, mockMessageRepo := newMessageServiceMocks()
+	message := entity.NewMessage("m1", tests.TestUserID1, entity.GetConversationKey(tests.TestUserID1, tests.TestUserID2), "", "hi")
+	message.Reactions = make(map[string]map[string]time.Time)
+	for i := 0; i < 20; i++ {
+		message.Reactions[string(rune('a'+i))] = map[string]time.Time{tests.TestUserID1: time.Now()}
+	}
+	mockMessageRepo.On("GetByID", "m1").Return(message, nil)
+
+	_, _, err := ms.ToggleReaction(tests.TestUserID2, &dto.ReactionRequest{MessageID: "m1", Emoji: "🎉"})
+	assert.ErrorIs(t, err, service.ErrTooManyReactions)
+	mockMessageRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestMessageService_CreateTeamMessage_ThreadReplyWithQuote(t *testing.T) {
+	ms, mockUserRepo, mockTeamRepo, mockMessageRepo, mockReadCursorRepo := newMessageServiceReadMocks()
+	parent := teamMessages(tests.TestUserID2)[0]
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1}}, nil)
+	mockMessageRepo.On("GetByID", "a").Return(parent, nil)
+	mockMessageRepo.On("Create", mock.MatchedBy(func(message *entity.Message) bool {
+		return message.ParentID == "a" && message.Thread() == entity.ReplyThread("a")
+	})).Return(nil)
+	mockMessageRepo.On("Update", "a", mock.MatchedBy(func(updates map[string]interface{}) bool {
+		return len(updates) == 1
+	})).Return(nil).Once()
+	mockReadCursorRepo.On("Get", tests.TestUserID1, entity.ReplyThread("a")).Return(nil, errors.New(persistence.ReadCursorNotFound))
+	mockReadCursorRepo.On("Save", mock.Anything).Return(nil)
+
+	resp, err := ms.CreateTeamMessage(&dto.TeamMessageRequest{
+		SenderID: tests.TestUserID1, TeamId: tests.TestTeamID, TextContent: "agreed", ParentID: "a", ReplyToID: "a",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a", resp.ParentID)
+	assert.Equal(t, &dto.MessageQuoteDTO{MessageID: "a", SenderID: tests.TestUserID2, Preview: "text"}, resp.ReplyTo)
+	mockMessageRepo.AssertExpectations(t)
+}
+
+func TestMessageService_CreateTeamMessage_NotifiesMentionedMembers(t *testing.T) {
+	ms, mockUserRepo, mockTeamRepo, mockMessageRepo, mockReadCursorRepo := newMessageServiceReadMocks()
+	mockNotifier := new(tests.MockNotifier)
+	ms.SetNotifier(mockNotifier)
+	members := []string{tests.TestUserID1, tests.TestUserID2}
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1, Username: "alice"}, nil)
+	mockUserRepo.On("GetByIDs", members).Return([]*entity.User{
+		{ID: tests.TestUserID1, Username: "alice"},
+		{ID: tests.TestUserID2, Username: "Bob"},
+	}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, Name: tests.TestTeamName, UsersIds: members}, nil)
+	mockMessageRepo.On("Create", mock.AnythingOfType("*entity.Message")).Return(nil)
+	mockReadCursorRepo.On("Get", tests.TestUserID1, entity.TeamThread(tests.TestTeamID)).Return(nil, errors.New(persistence.ReadCursorNotFound))
+	mockReadCursorRepo.On("Save", mock.Anything).Return(nil)
+	mockNotifier.On("Notify", mock.MatchedBy(func(n entity.Notification) bool {
+		return n.Type == entity.NotificationMention && n.ActorID == tests.TestUserID1 && n.Text == "alice mentioned you in "+tests.TestTeamName
+	}), []string{tests.TestUserID2}).Return()
+
+	_, err := ms.CreateTeamMessage(&dto.TeamMessageRequest{
+		SenderID: tests.TestUserID1, TeamId: tests.TestTeamID, TextContent: "thanks @bob. and @carol",
+	})
+
+	assert.NoError(t, err)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestMessageService_CreateTeamMessage_InvalidReferences(t *testing.T) {
+	ms, mockUserRepo, mockTeamRepo, mockMessageRepo := newMessageServiceMocks()
+	reply := entity.NewReply("r1", tests.TestUserID2, teamMessages(tests.TestUserID2)[0], "nested")
+	elsewhere := entity.NewMessage("x1", tests.TestUserID2, "", "other-team", "elsewhere")
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1}}, nil)
+	mockMessageRepo.On("GetByID", "r1").Return(reply, nil)
+	mockMessageRepo.On("GetByID", "x1").Return(elsewhere, nil)
+
+	_, err := ms.CreateTeamMessage(&dto.TeamMessageRequest{SenderID: tests.TestUserID1, TeamId: tests.TestTeamID, TextContent: "x", ParentID: "r1"})
+	assert.ErrorIs(t, err, service.ErrNestedThread)
+
+	_, err = ms.CreateTeamMessage(&dto.TeamMessageRequest{SenderID: tests.TestUserID1, TeamId: tests.TestTeamID, TextContent: "x", ReplyToID: "x1"})
+	assert.ErrorIs(t, err, service.ErrInvalidReply)
+	mockMessageRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestMessageService_GetReplies(t *testing.T) {
+	ms, mockUserRepo, mockTeamRepo, mockMessageRepo := newMessageServiceMocks()
+	parent := teamMessages(tests.TestUserID2)[0]
+	reply := entity.NewReply("r1", tests.TestUserID1, parent, "reply")
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1, tests.TestUserID2}}, nil)
+	mockMessageRepo.On("GetByID", "a").Return(parent, nil)
+	mockMessageRepo.On("GetRepliesPage", "a", persistence.MessagePageQuery{Limit: 51}).Return([]*entity.Message{reply}, nil)
+	mockUserRepo.On("GetByIDs", []string{tests.TestUserID1}).Return([]*entity.User{{ID: tests.TestUserID1}}, nil)
+
+	page, err := ms.GetReplies(tests.TestUserID2, "a", dto.MessagePageRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Messages, 1)
+	assert.Equal(t, "a", page.Messages[0].ParentID)
+
+	_, err = ms.GetReplies("outsider", "a", dto.MessagePageRequest{})
+	assert.ErrorIs(t, err, service.ErrForbidden)
+}
+
+func TestMessageService_GetMessageByID_OnlyParticipants(t *testing.T) {
+	ms, mockUserRepo, mockTeamRepo, mockMessageRepo := newMessageServiceMocks()
+	teamMessage := teamMessages(tests.TestUserID2)[0]
+	direct := entity.NewMessage("d1", tests.TestUserID1, entity.GetConversationKey(tests.TestUserID1, tests.TestUserID2), "", "hi")
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID2}}, nil)
+	mockMessageRepo.On("GetByID", "a").Return(teamMessage, nil)
+	mockMessageRepo.On("GetByID", "d1").Return(direct, nil)
+	mockMessageRepo.On("GetByID", "missing").Return(nil, errors.New(persistence.MessageNotFound))
+	mockUserRepo.On("GetByID", tests.TestUserID2).Return(&entity.User{ID: tests.TestUserID2}, nil)
+
+	message, err := ms.GetMessageByID(tests.TestUserID2, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", message.ID)
+
+	_, err = ms.GetMessageByID("outsider", "a")
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	_, err = ms.GetMessageByID("outsider", "d1")
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	_, err = ms.GetMessageByID(tests.TestUserID2, "missing")
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+}
+
+func TestMessageService_CreateDirectMessage_WithAttachments(t *testing.T) {
+	ms, mockUserRepo, _, mockMessageRepo, mockReadCursorRepo, mockFileRepo := newMessageServiceFileMocks()
+	conversationKey := entity.GetConversationKey(tests.TestUserID1, tests.TestUserID2)
+	file := &entity.File{ID: "f1", Name: "notes", Extension: "pdf", Type: "application/pdf", Size: 2048,
+		ContextType: entity.FileContextChat, ContextID: conversationKey}
+
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1}, nil)
+	mockUserRepo.On("GetByID", tests.TestUserID2).Return(&entity.User{ID: tests.TestUserID2}, nil)
+	mockFileRepo.On("GetByID", "f1").Return(file, nil)
+	mockMessageRepo.On("Create", mock.MatchedBy(func(message *entity.Message) bool {
+		return len(message.Attachments) == 1 && message.Attachments[0].FileID == "f1"
+	})).Return(nil)
+	mockReadCursorRepo.On("Get", mock.Anything, mock.Anything).Return(nil, errors.New(persistence.ReadCursorNotFound))
+	mockReadCursorRepo.On("Save", mock.Anything).Return(nil)
+
+	resp, err := ms.CreateDirectMessage(&dto.DirectMessageRequest{
+		SenderID: tests.TestUserID1, ReceiverID: tests.TestUserID2, TextContent: "see attached", AttachmentIDs: []string{"f1"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []dto.AttachmentDTO{{FileID: "f1", Name: "notes", Type: "application/pdf", Extension: "pdf", Size: 2048}}, resp.Attachments)
+	mockMessageRepo.AssertExpectations(t)
+}
+
+func TestMessageService_CreateTeamMessage_NotMember(t *testing.T) {
+	ms, mockUserRepo, mockTeamRepo, mockMessageRepo := newMessageServiceMocks()
+	mockUserRepo.On("GetByID", tests.TestUserID2).Return(&entity.User{ID: tests.TestUserID2}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, IsPublic: true, UsersIds: []string{tests.TestUserID1}}, nil)
+
+	_, err := ms.CreateTeamMessage(&dto.TeamMessageRequest{SenderID: tests.TestUserID2, TeamId: tests.TestTeamID, TextContent: "hi"})
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	mockMessageRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestMessageService_CreateTeamMessage_InvalidAttachments(t *testing.T) {
+	ms, mockUserRepo, mockTeamRepo, mockMessageRepo, _, mockFileRepo := newMessageServiceFileMocks()
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1}}, nil)
+	mockFileRepo.On("GetByID", "chat-file").Return(&entity.File{ID: "chat-file", ContextType: entity.FileContextChat,
+		ContextID: entity.GetConversationKey(tests.TestUserID1, tests.TestUserID2)}, nil)
+	mockFileRepo.On("GetByID", "missing").Return(nil, errors.New("file not found"))
+
+	_, err := ms.CreateTeamMessage(&dto.TeamMessageRequest{SenderID: tests.TestUserID1, TeamId: tests.TestTeamID, TextContent: "x", AttachmentIDs: []string{"chat-file"}})
+	assert.ErrorIs(t, err, service.ErrInvalidAttachment)
+
+	_, err = ms.CreateTeamMessage(&dto.TeamMessageRequest{SenderID: tests.TestUserID1, TeamId: tests.TestTeamID, TextContent: "x", AttachmentIDs: []string{"missing"}})
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+	mockMessageRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
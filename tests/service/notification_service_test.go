@@ -0,0 +1,343 @@
+package service_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/hub"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newNotificationServiceMocks() (*service.NotificationService, *tests.MockNotificationRepository, *tests.MockNotificationSender) {
+	ns, mockRepo, mockPreferencesRepo, _, _, mockSender, _ := newNotificationServicePreferenceMocks()
+	mockPreferencesRepo.On("GetByUserID", mock.Anything).
+		Return(nil, errors.New(persistence.NotificationPreferencesNotFound)).Maybe()
+	return ns, mockRepo, mockSender
+}
+
+func newNotificationServicePreferenceMocks() (*service.NotificationService, *tests.MockNotificationRepository, *tests.MockNotificationPreferencesRepository, *tests.MockUserRepository, *tests.MockTeamRepository, *tests.MockNotificationSender, *tests.MockMailer) {
+	mockRepo := new(tests.MockNotificationRepository)
+	mockPreferencesRepo := new(tests.MockNotificationPreferencesRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	mockSender := new(tests.MockNotificationSender)
+	mockMailer := new(tests.MockMailer)
+	ns := service.NewNotificationServiceWithRepo(mockRepo, mockPreferencesRepo, mockUserRepo, mockTeamRepo, mockSender, mockMailer)
+	return ns, mockRepo, mockPreferencesRepo, mockUserRepo, mockTeamRepo, mockSender, mockMailer
+}
+
+func testNotification(id string, notificationType entity.NotificationType, read bool) *entity.Notification {
+	notification := entity.NewNotification(id, tests.TestUserID1, entity.Notification{Type: notificationType})
+	if read {
+		notification.MarkRead(time.Now())
+	}
+	return notification
+}
+
+func TestNotificationService_Notify_SkipsActorAndDuplicates(t *testing.T) {
+	ns, mockRepo, mockSender := newNotificationServiceMocks()
+	mockRepo.On("Create", mock.MatchedBy(func(n *entity.Notification) bool {
+		return n.UserID == tests.TestUserID2 && n.Type == entity.NotificationMention && n.ID != ""
+	})).Return(nil).Once()
+	mockRepo.On("CountUnread", tests.TestUserID2).Return(map[entity.NotificationType]int{entity.NotificationMention: 1}, nil)
+	mockSender.On("Send", tests.TestUserID2, mock.MatchedBy(func(msg hub.Message) bool {
+		event, ok := msg.Payload.(dto.NotificationEventDTO)
+		return msg.Type == hub.NotificationReceived && ok && event.UnreadCount == 1
+	})).Return().Once()
+
+	ns.Notify(entity.Notification{Type: entity.NotificationMention, ActorID: tests.TestUserID1},
+		tests.TestUserID1, tests.TestUserID2, tests.TestUserID2, "")
+	ns.Wait()
+
+	mockRepo.AssertExpectations(t)
+	mockSender.AssertExpectations(t)
+}
+
+func TestNotificationService_Notify_StoreFailureSendsNothing(t *testing.T) {
+	ns, mockRepo, mockSender := newNotificationServiceMocks()
+	mockRepo.On("Create", mock.Anything).Return(errors.New("db down"))
+
+	ns.Notify(entity.Notification{Type: entity.NotificationMention}, tests.TestUserID2)
+	ns.Wait()
+
+	mockSender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+}
+
+func TestNotificationService_GetNotifications_UnreadOnlyAndPaging(t *testing.T) {
+	ns, mockRepo, _ := newNotificationServiceMocks()
+	mockRepo.On("GetByUserID", tests.TestUserID1).Return([]*entity.Notification{
+		testNotification("n1", entity.NotificationMention, false),
+		testNotification("n2", entity.NotificationMention, true),
+		testNotification("n3", entity.NotificationEventInvite, false),
+	}, nil)
+
+	page, err := ns.GetNotifications(tests.TestUserID1, true, 2, 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Notifications, 1)
+	assert.Equal(t, "n3", page.Notifications[0].ID)
+	assert.Equal(t, 2, page.UnreadCount)
+	assert.Equal(t, 2, page.TotalCount)
+	assert.Equal(t, 2, page.TotalPages)
+}
+
+func TestNotificationService_GetUnreadCounts(t *testing.T) {
+	ns, mockRepo, _ := newNotificationServiceMocks()
+	mockRepo.On("CountUnread", tests.TestUserID1).Return(map[entity.NotificationType]int{entity.NotificationMention: 2}, nil)
+
+	counts, err := ns.GetUnreadCounts(tests.TestUserID1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, counts.Total)
+	assert.Equal(t, map[entity.NotificationType]int{entity.NotificationMention: 2}, counts.ByType)
+}
+
+func TestNotificationService_MarkRead_Success(t *testing.T) {
+	ns, mockRepo, mockSender := newNotificationServiceMocks()
+	mockRepo.On("GetByID", "n1").Return(testNotification("n1", entity.NotificationMention, false), nil)
+	mockRepo.On("Update", mock.MatchedBy(func(n *entity.Notification) bool { return n.IsRead() })).Return(nil)
+	mockRepo.On("CountUnread", tests.TestUserID1).Return(map[entity.NotificationType]int{}, nil)
+	mockSender.On("Send", tests.TestUserID1, mock.MatchedBy(func(msg hub.Message) bool {
+		return msg.Type == hub.NotificationsRead
+	})).Return()
+
+	notification, err := ns.MarkRead(tests.TestUserID1, "n1")
+
+	assert.NoError(t, err)
+	assert.True(t, notification.IsRead())
+	mockRepo.AssertExpectations(t)
+	mockSender.AssertExpectations(t)
+}
+
+func TestNotificationService_MarkRead_OtherUsersNotificationNotFound(t *testing.T) {
+	ns, mockRepo, _ := newNotificationServiceMocks()
+	mockRepo.On("GetByID", "n1").Return(testNotification("n1", entity.NotificationMention, false), nil)
+	mockRepo.On("GetByID", "missing").Return(nil, errors.New(persistence.NotificationNotFound))
+
+	_, err := ns.MarkRead(tests.TestUserID2, "n1")
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+	_, err = ns.MarkRead(tests.TestUserID1, "missing")
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestNotificationService_MarkAllRead(t *testing.T) {
+	ns, mockRepo, mockSender := newNotificationServiceMocks()
+	mockRepo.On("MarkAllRead", tests.TestUserID1, mock.AnythingOfType("time.Time")).Return(3, nil)
+	mockRepo.On("CountUnread", tests.TestUserID1).Return(map[entity.NotificationType]int{}, nil)
+	mockSender.On("Send", tests.TestUserID1, mock.Anything).Return()
+
+	read, err := ns.MarkAllRead(tests.TestUserID1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &dto.NotificationsReadDTO{All: true, Marked: 3}, read)
+	mockSender.AssertExpectations(t)
+}
+
+func TestNotificationService_Notify_MutedTeamSkipsRecipient(t *testing.T) {
+	ns, mockRepo, mockPreferencesRepo, _, _, mockSender, _ := newNotificationServicePreferenceMocks()
+	preferences := entity.NewNotificationPreferences(tests.TestUserID2)
+	preferences.MutedTeamIDs = []string{tests.TestTeamID}
+	mockPreferencesRepo.On("GetByUserID", tests.TestUserID2).Return(preferences, nil)
+
+	ns.Notify(entity.Notification{Type: entity.NotificationEventInvite, TeamID: tests.TestTeamID}, tests.TestUserID2)
+	ns.Wait()
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockSender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+}
+
+func TestNotificationService_Notify_InAppDisabledEmailsVerifiedUser(t *testing.T) {
+	ns, mockRepo, mockPreferencesRepo, mockUserRepo, _, mockSender, mockMailer := newNotificationServicePreferenceMocks()
+	push := new(tests.MockPushSender)
+	ns.SetPushSender(push)
+	preferences := entity.NewNotificationPreferences(tests.TestUserID2)
+	preferences.Channels[entity.NotificationMention] = map[entity.NotificationChannel]bool{
+		entity.ChannelInApp: false,
+		entity.ChannelEmail: true,
+	}
+	mockPreferencesRepo.On("GetByUserID", tests.TestUserID2).Return(preferences, nil)
+	mockUserRepo.On("GetByID", tests.TestUserID2).Return(&entity.User{
+		ID: tests.TestUserID2, FirstName: "Jane", Email: "jane@example.com", EmailVerified: true,
+	}, nil)
+	mockMailer.On("Send", "jane@example.com", mock.Anything, mock.MatchedBy(func(body string) bool {
+		return strings.Contains(body, "john mentioned you")
+	})).Return(nil).Once()
+	push.On("Push", tests.TestUserID2, mock.Anything).Return(nil).Once()
+
+	ns.Notify(entity.Notification{Type: entity.NotificationMention, Text: "john mentioned you"}, tests.TestUserID2)
+	ns.Wait()
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockSender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	mockMailer.AssertExpectations(t)
+	push.AssertExpectations(t)
+}
+
+func TestNotificationService_Notify_DoesNotWaitForDelivery(t *testing.T) {
+	ns, _, mockPreferencesRepo, mockUserRepo, _, _, mockMailer := newNotificationServicePreferenceMocks()
+	preferences := entity.NewNotificationPreferences(tests.TestUserID2)
+	preferences.Channels[entity.NotificationMention] = map[entity.NotificationChannel]bool{
+		entity.ChannelInApp: false,
+		entity.ChannelEmail: true,
+	}
+	mockPreferencesRepo.On("GetByUserID", tests.TestUserID2).Return(preferences, nil)
+	mockUserRepo.On("GetByID", tests.TestUserID2).Return(&entity.User{
+		ID: tests.TestUserID2, Email: "jane@example.com", EmailVerified: true,
+	}, nil)
+	release := make(chan time.Time)
+	mockMailer.On("Send", "jane@example.com", mock.Anything, mock.Anything).WaitUntil(release).Return(nil).Once()
+
+	notified := make(chan struct{})
+	go func() {
+		ns.Notify(entity.Notification{Type: entity.NotificationMention}, tests.TestUserID2)
+		close(notified)
+	}()
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("Notify waited for the email to be sent")
+	}
+	close(release)
+	ns.Wait()
+
+	mockMailer.AssertExpectations(t)
+}
+
+func TestNotificationService_Notify_UnverifiedEmailNotMailed(t *testing.T) {
+	ns, mockRepo, mockPreferencesRepo, mockUserRepo, _, mockSender, mockMailer := newNotificationServicePreferenceMocks()
+	preferences := entity.NewNotificationPreferences(tests.TestUserID2)
+	preferences.Channels[entity.NotificationMention] = map[entity.NotificationChannel]bool{entity.ChannelEmail: true}
+	mockPreferencesRepo.On("GetByUserID", tests.TestUserID2).Return(preferences, nil)
+	mockUserRepo.On("GetByID", tests.TestUserID2).Return(&entity.User{ID: tests.TestUserID2, Email: "jane@example.com"}, nil)
+	mockRepo.On("Create", mock.Anything).Return(nil)
+	mockRepo.On("CountUnread", tests.TestUserID2).Return(map[entity.NotificationType]int{}, nil)
+	mockSender.On("Send", tests.TestUserID2, mock.Anything).Return()
+
+	ns.Notify(entity.Notification{Type: entity.NotificationMention}, tests.TestUserID2)
+	ns.Wait()
+
+	mockMailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNotificationService_Notify_QuietHoursStoresWithoutSending(t *testing.T) {
+	ns, mockRepo, mockPreferencesRepo, _, _, mockSender, mockMailer := newNotificationServicePreferenceMocks()
+	push := new(tests.MockPushSender)
+	ns.SetPushSender(push)
+	now := time.Now().UTC()
+	preferences := entity.NewNotificationPreferences(tests.TestUserID2)
+	preferences.Channels[entity.NotificationMention] = map[entity.NotificationChannel]bool{entity.ChannelEmail: true}
+	preferences.QuietHours = &entity.QuietHours{
+		Start: now.Add(-time.Hour).Format("15:04"),
+		End:   now.Add(time.Hour).Format("15:04"),
+	}
+	mockPreferencesRepo.On("GetByUserID", tests.TestUserID2).Return(preferences, nil)
+	mockRepo.On("Create", mock.Anything).Return(nil).Once()
+
+	ns.Notify(entity.Notification{Type: entity.NotificationMention}, tests.TestUserID2)
+	ns.Wait()
+
+	mockRepo.AssertExpectations(t)
+	mockSender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	mockMailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+	push.AssertNotCalled(t, "Push", mock.Anything, mock.Anything)
+}
+
+func TestNotificationPreferences_InQuietHours(t *testing.T) {
+	preferences := entity.NewNotificationPreferences(tests.TestUserID1)
+	preferences.QuietHours = &entity.QuietHours{Start: "22:00", End: "07:00"}
+	preferences.Timezone = "Europe/Bucharest"
+
+	// 21:30 UTC is 00:30 in Bucharest during summer time
+	assert.True(t, preferences.InQuietHours(time.Date(2025, time.July, 1, 21, 30, 0, 0, time.UTC)))
+	// 05:00 UTC is 08:00 in Bucharest
+	assert.False(t, preferences.InQuietHours(time.Date(2025, time.July, 1, 5, 0, 0, 0, time.UTC)))
+
+	preferences.Timezone = ""
+	assert.True(t, preferences.InQuietHours(time.Date(2025, time.July, 1, 5, 0, 0, 0, time.UTC)))
+	assert.False(t, preferences.InQuietHours(time.Date(2025, time.July, 1, 21, 30, 0, 0, time.UTC)))
+
+	// saved before Local was rejected, and read as UTC whatever the server's timezone
+	preferences.Timezone = "Local"
+	assert.True(t, preferences.InQuietHours(time.Date(2025, time.July, 1, 5, 0, 0, 0, time.UTC)))
+}
+
+func TestNotificationService_GetPreferences_Defaults(t *testing.T) {
+	ns, _, _ := newNotificationServiceMocks()
+
+	preferences, err := ns.GetPreferences(tests.TestUserID1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "UTC", preferences.Timezone)
+	assert.Empty(t, preferences.MutedTeamIDs)
+	assert.True(t, preferences.Channels[entity.NotificationMention][entity.ChannelInApp])
+	assert.False(t, preferences.Channels[entity.NotificationMention][entity.ChannelEmail])
+	assert.True(t, preferences.Channels[entity.NotificationMention][entity.ChannelPush])
+}
+
+func TestNotificationService_UpdatePreferences_Success(t *testing.T) {
+	ns, _, mockPreferencesRepo, _, mockTeamRepo, _, _ := newNotificationServicePreferenceMocks()
+	team := &entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1}}
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(team, nil).Once()
+	mockPreferencesRepo.On("Save", mock.MatchedBy(func(p *entity.NotificationPreferences) bool {
+		return p.UserID == tests.TestUserID1 && len(p.MutedTeamIDs) == 1 && p.Timezone == "Europe/Bucharest" &&
+			!p.Enabled(entity.NotificationMention, entity.ChannelPush)
+	})).Return(nil).Once()
+
+	preferences, err := ns.UpdatePreferences(tests.TestUserID1, &dto.NotificationPreferencesRequest{
+		Channels: map[entity.NotificationType]map[entity.NotificationChannel]bool{
+			entity.NotificationMention: {entity.ChannelPush: false},
+		},
+		MutedTeamIDs: []string{tests.TestTeamID, tests.TestTeamID},
+		QuietHours:   &entity.QuietHours{Start: "22:00", End: "07:00"},
+		Timezone:     "Europe/Bucharest",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{tests.TestTeamID}, preferences.MutedTeamIDs)
+	assert.False(t, preferences.Channels[entity.NotificationMention][entity.ChannelPush])
+	assert.True(t, preferences.Channels[entity.NotificationTeamInvite][entity.ChannelPush])
+	mockTeamRepo.AssertExpectations(t)
+	mockPreferencesRepo.AssertExpectations(t)
+}
+
+func TestNotificationService_UpdatePreferences_ValidationError(t *testing.T) {
+	ns, _, mockPreferencesRepo, _, _, _, _ := newNotificationServicePreferenceMocks()
+
+	_, err := ns.UpdatePreferences(tests.TestUserID1, &dto.NotificationPreferencesRequest{
+		QuietHours: &entity.QuietHours{Start: "22:00", End: "22:00"},
+	})
+
+	assert.ErrorContains(t, err, "validation failed")
+	mockPreferencesRepo.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestNotificationService_UpdatePreferences_RejectsServerTimezone(t *testing.T) {
+	ns, _, mockPreferencesRepo, _, _, _, _ := newNotificationServicePreferenceMocks()
+
+	_, err := ns.UpdatePreferences(tests.TestUserID1, &dto.NotificationPreferencesRequest{Timezone: "Local"})
+
+	assert.ErrorContains(t, err, `unknown timezone "Local"`)
+	mockPreferencesRepo.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestNotificationService_UpdatePreferences_NotMemberOfMutedTeam(t *testing.T) {
+	ns, _, mockPreferencesRepo, _, mockTeamRepo, _, _ := newNotificationServicePreferenceMocks()
+	team := &entity.Team{Id: tests.TestTeamID, IsPublic: true, UsersIds: []string{tests.TestUserID2}}
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(team, nil)
+
+	_, err := ns.UpdatePreferences(tests.TestUserID1, &dto.NotificationPreferencesRequest{
+		MutedTeamIDs: []string{tests.TestTeamID},
+	})
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	mockPreferencesRepo.AssertNotCalled(t, "Save", mock.Anything)
+}
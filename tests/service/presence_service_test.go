@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type presenceMocks struct {
+	presenceRepo *tests.MockPresenceRepository
+	userRepo     *tests.MockUserRepository
+	teamRepo     *tests.MockTeamRepository
+	friendRepo   *tests.MockFriendRequestRepository
+}
+
+func newPresenceServiceMocks() (*service.PresenceService, presenceMocks) {
+	m := presenceMocks{
+		presenceRepo: new(tests.MockPresenceRepository),
+		userRepo:     new(tests.MockUserRepository),
+		teamRepo:     new(tests.MockTeamRepository),
+		friendRepo:   new(tests.MockFriendRequestRepository),
+	}
+	return service.NewPresenceServiceWithRepo(m.presenceRepo, m.userRepo, m.teamRepo, m.friendRepo), m
+}
+
+// expectAudience makes user1 a friend of user2 and a member of the test team with TestUserID
+func (m presenceMocks) expectAudience() {
+	m.userRepo.On("GetByID", tests.TestUserID1).
+		Return(&entity.User{ID: tests.TestUserID1, TeamsIds: &[]string{tests.TestTeamID, "gone"}}, nil)
+	m.friendRepo.On("GetFriendsForUser", tests.TestUserID1).Return([]string{tests.TestUserID2}, nil)
+	m.teamRepo.On("GetTeamById", tests.TestTeamID).
+		Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1, tests.TestUserID, tests.TestUserID2}}, nil)
+	m.teamRepo.On("GetTeamById", "gone").Return(nil, errors.New("team not found"))
+}
+
+func TestPresenceService_SetStatus_NotifiesFriendsAndTeammates(t *testing.T) {
+	ps, m := newPresenceServiceMocks()
+	m.expectAudience()
+	m.presenceRepo.On("GetByUserID", tests.TestUserID1).Return(nil, errors.New(persistence.PresenceNotFound))
+	m.presenceRepo.On("Save", mock.MatchedBy(func(p *entity.Presence) bool {
+		return p.UserID == tests.TestUserID1 && p.Status == entity.PresenceOnline && !p.LastSeenAt.IsZero()
+	})).Return(nil)
+
+	presence, recipients, err := ps.SetStatus(tests.TestUserID1, entity.PresenceOnline)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.PresenceOnline, presence.Status)
+	assert.NotEmpty(t, presence.LastSeenAt)
+	assert.Equal(t, []string{tests.TestUserID2, tests.TestUserID}, recipients)
+}
+
+func TestPresenceService_SetStatus_UnchangedNotifiesNobody(t *testing.T) {
+	ps, m := newPresenceServiceMocks()
+	m.presenceRepo.On("GetByUserID", tests.TestUserID1).Return(entity.NewPresence(tests.TestUserID1, entity.PresenceIdle), nil)
+
+	presence, recipients, err := ps.SetStatus(tests.TestUserID1, entity.PresenceIdle)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.PresenceIdle, presence.Status)
+	assert.Empty(t, recipients)
+	m.presenceRepo.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestPresenceService_UpdatePresence_RejectsOffline(t *testing.T) {
+	ps, m := newPresenceServiceMocks()
+
+	_, _, err := ps.UpdatePresence(tests.TestUserID1, &dto.SetPresenceRequest{Status: entity.PresenceOffline})
+
+	assert.Error(t, err)
+	m.presenceRepo.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestPresenceService_GetPresence(t *testing.T) {
+	ps, m := newPresenceServiceMocks()
+	m.expectAudience()
+	m.presenceRepo.On("GetByUserID", tests.TestUserID1).Return(nil, errors.New(persistence.PresenceNotFound))
+
+	presence, err := ps.GetPresence(tests.TestUserID2, tests.TestUserID1)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.PresenceOffline, presence.Status)
+	assert.Empty(t, presence.LastSeenAt)
+
+	_, err = ps.GetPresence("stranger", tests.TestUserID1)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+}
+
+func TestPresenceService_GetTeamPresence(t *testing.T) {
+	ps, m := newPresenceServiceMocks()
+	members := []string{tests.TestUserID1, tests.TestUserID2}
+	m.teamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, IsPublic: true, UsersIds: members}, nil)
+	m.teamRepo.On("GetTeamById", tests.TestTeamID2).Return(&entity.Team{Id: tests.TestTeamID2, UsersIds: members}, nil)
+	m.presenceRepo.On("GetByUserIDs", members).
+		Return([]*entity.Presence{entity.NewPresence(tests.TestUserID2, entity.PresenceIdle)}, nil)
+
+	roster, err := ps.GetTeamPresence(tests.TestUserID1, tests.TestTeamID)
+
+	assert.NoError(t, err)
+	assert.Len(t, roster, 2)
+	assert.Equal(t, entity.PresenceOffline, roster[0].Status)
+	assert.Equal(t, tests.TestUserID2, roster[1].UserID)
+	assert.Equal(t, entity.PresenceIdle, roster[1].Status)
+
+	_, err = ps.GetTeamPresence("stranger", tests.TestTeamID)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	_, err = ps.GetTeamPresence("stranger", tests.TestTeamID2)
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+}
@@ -0,0 +1,139 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/search"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newSearchService seeds a memory store with one searchable record of each kind, all mentioning "graphs"
+func newSearchService(t *testing.T) (*service.SearchService, *search.Index, *tests.MockUserRepository) {
+	store, err := persistence.NewLocalStore("")
+	assert.NoError(t, err)
+
+	conversationKey := entity.GetConversationKey(tests.TestUserID1, tests.TestUserID2)
+	messages := persistence.NewLocalMessageRepository(store)
+	assert.NoError(t, messages.Create(entity.NewMessage("m1", tests.TestUserID2, "", tests.TestTeamID, "Graphs lecture moved")))
+	assert.NoError(t, messages.Create(entity.NewMessage("m2", tests.TestUserID2, conversationKey, "", "notes on graphs")))
+	deleted := entity.NewMessage("m3", tests.TestUserID2, "", tests.TestTeamID, "graphs")
+	deleted.DeletedAt = &time.Time{}
+	assert.NoError(t, messages.Create(deleted))
+	assert.NoError(t, persistence.NewLocalFileRepository(store).Create(
+		entity.NewFile("f1", "graphs.pdf", "application/pdf", "pdf", "", tests.TestUserID2, entity.FileContextTeam, tests.TestTeamID2, 1, 0, 0)))
+	assert.NoError(t, persistence.NewLocalQuizRepository(store).Create(
+		*entity.NewQuiz("q1", "Week 3", tests.TestUserID2, tests.TestTeamID, []entity.Question{{Question: "Which graphs are trees?"}})))
+	assert.NoError(t, persistence.NewLocalEventRepository(store).Create(
+		entity.NewEvent("e1", tests.TestUserID2, tests.TestTeamID2, "Review", "graphs and trees", time.Now(), 60, nil)))
+	assert.NoError(t, persistence.NewLocalTeamRepository(store).Create(
+		entity.NewTeam(tests.TestTeamID2, "Graph theory", "We study graphs", true, nil, "")))
+
+	index := search.NewIndex()
+	mockUserRepo := new(tests.MockUserRepository)
+	ss := service.NewSearchServiceWithRepo(index, persistence.NewLocalCollectionReader(store), mockUserRepo)
+	return ss, index, mockUserRepo
+}
+
+func resultIDs(response *dto.SearchResponse) []string {
+	ids := make([]string, 0, len(response.Results))
+	for _, result := range response.Results {
+		ids = append(ids, result.ID)
+	}
+	return ids
+}
+
+func TestSearchService_Search_OnlyWhatTheUserCanSee(t *testing.T) {
+	ss, index, mockUserRepo := newSearchService(t)
+	teamIDs := []string{tests.TestTeamID}
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1, TeamsIds: &teamIDs}, nil)
+	mockUserRepo.On("GetByID", "outsider").Return(&entity.User{ID: "outsider"}, nil)
+
+	response, err := ss.Search(tests.TestUserID1, &dto.SearchRequest{Query: "GRAPH"})
+	assert.NoError(t, err)
+	assert.True(t, index.Built())
+	// the file and event of TestTeamID2 are hidden, its team record is public, the deleted message is gone
+	assert.ElementsMatch(t, []string{"m1", "m2", "q1", tests.TestTeamID2}, resultIDs(response))
+	assert.Equal(t, 4, response.TotalCount)
+
+	response, err = ss.Search("outsider", &dto.SearchRequest{Query: "graphs"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{tests.TestTeamID2}, resultIDs(response))
+}
+
+func TestSearchService_Search_PrivateTeamOnlyForMembers(t *testing.T) {
+	ss, index, mockUserRepo := newSearchService(t)
+	teamIDs := []string{tests.TestTeamID}
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1, TeamsIds: &teamIDs}, nil)
+	mockUserRepo.On("GetByID", "outsider").Return(&entity.User{ID: "outsider"}, nil)
+	assert.NoError(t, ss.Rebuild())
+	index.Put(search.TeamDocument(entity.NewTeam(tests.TestTeamID, "Secret graphs", "", false, []string{tests.TestUserID1}, "")))
+
+	response, err := ss.Search("outsider", &dto.SearchRequest{Query: "graphs", Types: []string{"team"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{tests.TestTeamID2}, resultIDs(response))
+
+	response, err = ss.Search(tests.TestUserID1, &dto.SearchRequest{Query: "graphs", Types: []string{"team"}})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{tests.TestTeamID, tests.TestTeamID2}, resultIDs(response))
+}
+
+func TestSearchService_Search_TypesAndPaging(t *testing.T) {
+	ss, _, mockUserRepo := newSearchService(t)
+	teamIDs := []string{tests.TestTeamID, tests.TestTeamID2}
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1, TeamsIds: &teamIDs}, nil)
+
+	response, err := ss.Search(tests.TestUserID1, &dto.SearchRequest{Query: "graphs", Types: []string{"file", "event"}, Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, response.Results, 1)
+	assert.Equal(t, 2, response.TotalCount)
+	assert.Equal(t, 2, response.TotalPages)
+
+	_, err = ss.Search(tests.TestUserID1, &dto.SearchRequest{Query: "graphs", Types: []string{"users"}})
+	assert.ErrorContains(t, err, "validation failed")
+	_, err = ss.Search(tests.TestUserID1, &dto.SearchRequest{Query: " - "})
+	assert.ErrorContains(t, err, "validation failed")
+}
+
+func TestSearchService_FollowsMessageWrites(t *testing.T) {
+	ss, index, mockUserRepo := newSearchService(t)
+	teamIDs := []string{tests.TestTeamID}
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1, TeamsIds: &teamIDs}, nil)
+	_, err := ss.Search(tests.TestUserID1, &dto.SearchRequest{Query: "graphs"})
+	assert.NoError(t, err)
+
+	ms, msUserRepo, mockTeamRepo, mockMessageRepo := newMessageServiceMocks()
+	ms.SetSearchIndex(index)
+	message := entity.NewMessage("m1", tests.TestUserID1, "", tests.TestTeamID, "Graphs lecture moved")
+	mockMessageRepo.On("GetByID", "m1").Return(message, nil)
+	mockMessageRepo.On("Update", "m1", mock.Anything).Return(nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID1}}, nil)
+	msUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1}, nil)
+
+	_, _, err = ms.EditMessage(tests.TestUserID1, "m1", &dto.EditMessageRequest{TextContent: "Lecture moved to Friday"})
+	assert.NoError(t, err)
+	response, err := ss.Search(tests.TestUserID1, &dto.SearchRequest{Query: "friday"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m1"}, resultIDs(response))
+
+	_, _, err = ms.DeleteMessage(tests.TestUserID1, "m1")
+	assert.NoError(t, err)
+	response, err = ss.Search(tests.TestUserID1, &dto.SearchRequest{Query: "friday"})
+	assert.NoError(t, err)
+	assert.Empty(t, response.Results)
+}
+
+func TestSearchService_Search_UnknownUser(t *testing.T) {
+	ss, _, mockUserRepo := newSearchService(t)
+	mockUserRepo.On("GetByID", "ghost").Return(nil, errors.New(tests.ErrUserNotFound))
+
+	_, err := ss.Search("ghost", &dto.SearchRequest{Query: "graphs"})
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+}
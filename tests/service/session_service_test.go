@@ -0,0 +1,227 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/config"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testJWTSecret = "test-secret"
+
+func newSessionTestUser() *entity.User {
+	return &entity.User{
+		ID:       tests.TestUserID,
+		Username: tests.TestUsername,
+		Email:    tests.TestEmail,
+	}
+}
+
+// startTestSession runs StartSession against the mock and returns the stored session and the issued refresh token
+func startTestSession(t *testing.T, sessionService *service.SessionService, mockSessionRepo *tests.MockSessionRepository) (*entity.Session, string, string) {
+	var stored *entity.Session
+	mockSessionRepo.On("Create", mock.AnythingOfType("*entity.Session")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*entity.Session)
+	}).Return(nil).Once()
+
+	resp, err := sessionService.StartSession(newSessionTestUser())
+	assert.NoError(t, err)
+	assert.NotNil(t, stored)
+	return stored, resp.AccessToken, resp.RefreshToken
+}
+
+func TestSessionService_StartSession_Success(t *testing.T) {
+	t.Setenv("JWT_SECRET", testJWTSecret)
+	mockSessionRepo := new(tests.MockSessionRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	sessionService := service.NewSessionServiceWithRepo(mockSessionRepo, mockUserRepo)
+
+	stored, accessToken, refreshToken := startTestSession(t, sessionService, mockSessionRepo)
+
+	assert.Equal(t, tests.TestUserID, stored.UserID)
+	assert.NotContains(t, stored.RefreshTokenHash, refreshToken)
+	assert.Contains(t, refreshToken, stored.ID+".")
+
+	claims, err := config.ValidateJWT(accessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, stored.ID, claims["sid"])
+	assert.NotEmpty(t, claims["jti"])
+	mockSessionRepo.AssertExpectations(t)
+}
+
+func TestSessionService_RefreshSession_RotatesToken(t *testing.T) {
+	t.Setenv("JWT_SECRET", testJWTSecret)
+	mockSessionRepo := new(tests.MockSessionRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	sessionService := service.NewSessionServiceWithRepo(mockSessionRepo, mockUserRepo)
+
+	stored, _, refreshToken := startTestSession(t, sessionService, mockSessionRepo)
+	oldHash := stored.RefreshTokenHash
+
+	mockSessionRepo.On("GetByID", stored.ID).Return(stored, nil)
+	mockSessionRepo.On("Update", stored).Return(nil)
+	mockUserRepo.On("GetByID", tests.TestUserID).Return(newSessionTestUser(), nil)
+
+	resp, err := sessionService.RefreshSession(refreshToken)
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, refreshToken, resp.RefreshToken)
+	assert.NotEqual(t, oldHash, stored.RefreshTokenHash)
+	assert.False(t, stored.Revoked)
+	mockSessionRepo.AssertExpectations(t)
+}
+
+func TestSessionService_RefreshSession_ReuseRevokesSession(t *testing.T) {
+	t.Setenv("JWT_SECRET", testJWTSecret)
+	mockSessionRepo := new(tests.MockSessionRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	sessionService := service.NewSessionServiceWithRepo(mockSessionRepo, mockUserRepo)
+
+	stored, _, refreshToken := startTestSession(t, sessionService, mockSessionRepo)
+
+	mockSessionRepo.On("GetByID", stored.ID).Return(stored, nil)
+	mockSessionRepo.On("Update", stored).Return(nil)
+	mockUserRepo.On("GetByID", tests.TestUserID).Return(newSessionTestUser(), nil)
+
+	_, err := sessionService.RefreshSession(refreshToken)
+	assert.NoError(t, err)
+
+	// presenting the rotated-away token again must kill the session
+	_, err = sessionService.RefreshSession(refreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	assert.True(t, stored.Revoked)
+}
+
+func TestSessionService_RefreshSession_Malformed(t *testing.T) {
+	mockSessionRepo := new(tests.MockSessionRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	sessionService := service.NewSessionServiceWithRepo(mockSessionRepo, mockUserRepo)
+
+	_, err := sessionService.RefreshSession("not-a-refresh-token")
+
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	mockSessionRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestSessionService_RefreshSession_Expired(t *testing.T) {
+	mockSessionRepo := new(tests.MockSessionRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	sessionService := service.NewSessionServiceWithRepo(mockSessionRepo, mockUserRepo)
+
+	expired := entity.NewSession("session1", tests.TestUserID, "hash", time.Now().Add(-time.Hour))
+	mockSessionRepo.On("GetByID", "session1").Return(expired, nil)
+
+	_, err := sessionService.RefreshSession("session1.secret")
+
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	mockSessionRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestSessionService_ValidateAccessToken(t *testing.T) {
+	claims := jwt.MapClaims{"sub": tests.TestUserID, "sid": "session1", "jti": "jti1"}
+	active := entity.NewSession("session1", tests.TestUserID, "hash", time.Now().Add(time.Hour))
+	revoked := entity.NewSession("session1", tests.TestUserID, "hash", time.Now().Add(time.Hour))
+	revoked.Revoked = true
+
+	cases := []struct {
+		name       string
+		claims     jwt.MapClaims
+		jtiRevoked bool
+		session    *entity.Session
+		expected   error
+	}{
+		{"active session", claims, false, active, nil},
+		{"revoked session", claims, false, revoked, service.ErrSessionRevoked},
+		{"revoked jti", claims, true, active, service.ErrTokenRevoked},
+		{"missing sid", jwt.MapClaims{"sub": tests.TestUserID, "jti": "jti1"}, false, active, service.ErrSessionRevoked},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSessionRepo := new(tests.MockSessionRepository)
+			sessionService := service.NewSessionServiceWithRepo(mockSessionRepo, new(tests.MockUserRepository))
+			mockSessionRepo.On("IsTokenRevoked", "jti1").Return(tc.jtiRevoked, nil)
+			mockSessionRepo.On("GetByID", "session1").Return(tc.session, nil)
+
+			err := sessionService.ValidateAccessToken(tc.claims)
+
+			if tc.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSessionService_Logout_RevokesSessionAndToken(t *testing.T) {
+	mockSessionRepo := new(tests.MockSessionRepository)
+	sessionService := service.NewSessionServiceWithRepo(mockSessionRepo, new(tests.MockUserRepository))
+
+	session := entity.NewSession("session1", tests.TestUserID, "hash", time.Now().Add(time.Hour))
+	claims := jwt.MapClaims{"sub": tests.TestUserID, "sid": "session1", "jti": "jti1", "exp": float64(time.Now().Add(time.Minute).Unix())}
+
+	mockSessionRepo.On("RevokeToken", mock.MatchedBy(func(token *entity.RevokedToken) bool {
+		return token.JTI == "jti1"
+	})).Return(nil)
+	mockSessionRepo.On("GetByID", "session1").Return(session, nil)
+	mockSessionRepo.On("Update", session).Return(nil)
+
+	err := sessionService.Logout(claims)
+
+	assert.NoError(t, err)
+	assert.True(t, session.Revoked)
+	mockSessionRepo.AssertExpectations(t)
+}
+
+func TestSessionService_LogoutAll_RevokesEverySession(t *testing.T) {
+	mockSessionRepo := new(tests.MockSessionRepository)
+	sessionService := service.NewSessionServiceWithRepo(mockSessionRepo, new(tests.MockUserRepository))
+
+	first := entity.NewSession("session1", tests.TestUserID, "hash", time.Now().Add(time.Hour))
+	second := entity.NewSession("session2", tests.TestUserID, "hash", time.Now().Add(time.Hour))
+	mockSessionRepo.On("GetByUserID", tests.TestUserID).Return([]*entity.Session{first, second}, nil)
+	mockSessionRepo.On("Update", mock.AnythingOfType("*entity.Session")).Return(nil)
+
+	err := sessionService.LogoutAll(tests.TestUserID)
+
+	assert.NoError(t, err)
+	assert.True(t, first.Revoked)
+	assert.True(t, second.Revoked)
+	mockSessionRepo.AssertNumberOfCalls(t, "Update", 2)
+}
+
+func TestSessionService_LogoutOthers_KeepsTheCurrentSession(t *testing.T) {
+	mockSessionRepo := new(tests.MockSessionRepository)
+	sessionService := service.NewSessionServiceWithRepo(mockSessionRepo, new(tests.MockUserRepository))
+
+	current := entity.NewSession("session1", tests.TestUserID, "hash", time.Now().Add(time.Hour))
+	other := entity.NewSession("session2", tests.TestUserID, "hash", time.Now().Add(time.Hour))
+	mockSessionRepo.On("GetByUserID", tests.TestUserID).Return([]*entity.Session{current, other}, nil)
+	mockSessionRepo.On("Update", other).Return(nil)
+
+	err := sessionService.LogoutOthers(tests.TestUserID, "session1")
+
+	assert.NoError(t, err)
+	assert.False(t, current.Revoked)
+	assert.True(t, other.Revoked)
+	mockSessionRepo.AssertNumberOfCalls(t, "Update", 1)
+}
+
+func TestSessionService_LogoutAll_RepositoryError(t *testing.T) {
+	mockSessionRepo := new(tests.MockSessionRepository)
+	sessionService := service.NewSessionServiceWithRepo(mockSessionRepo, new(tests.MockUserRepository))
+
+	mockSessionRepo.On("GetByUserID", tests.TestUserID).Return(nil, errors.New("database error"))
+
+	err := sessionService.LogoutAll(tests.TestUserID)
+
+	assert.Error(t, err)
+}
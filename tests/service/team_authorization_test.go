@@ -0,0 +1,385 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	testOwnerID  = "owner1"
+	testAdminID  = "admin1"
+	testMemberID = "member1"
+	testOtherID  = "member2"
+)
+
+func newRolesTeam() *entity.Team {
+	return &entity.Team{
+		Id:       tests.TestTeamID,
+		Name:     tests.TestTeamName,
+		UsersIds: []string{testOwnerID, testAdminID, testMemberID, testOtherID},
+		Roles: map[string]entity.TeamRole{
+			testOwnerID:  entity.TeamRoleOwner,
+			testAdminID:  entity.TeamRoleAdmin,
+			testMemberID: entity.TeamRoleMember,
+			testOtherID:  entity.TeamRoleMember,
+		},
+	}
+}
+
+func TestTeam_RoleOf_LegacyTeamFirstMemberIsOwner(t *testing.T) {
+	team := &entity.Team{Id: tests.TestTeamID, UsersIds: []string{testOwnerID, testMemberID}}
+
+	assert.Equal(t, entity.TeamRoleOwner, team.RoleOf(testOwnerID))
+	assert.Equal(t, entity.TeamRoleMember, team.RoleOf(testMemberID))
+	assert.Equal(t, entity.TeamRole(""), team.RoleOf("stranger"))
+}
+
+func TestHasTeamPermission(t *testing.T) {
+	team := newRolesTeam()
+
+	assert.True(t, service.HasTeamPermission(team, testOwnerID, service.PermissionDeleteTeam))
+	assert.False(t, service.HasTeamPermission(team, testAdminID, service.PermissionDeleteTeam))
+	assert.True(t, service.HasTeamPermission(team, testAdminID, service.PermissionReviewRequests))
+	assert.False(t, service.HasTeamPermission(team, testMemberID, service.PermissionUpdateTeam))
+	assert.False(t, service.HasTeamPermission(team, "stranger", service.PermissionModerateContent))
+}
+
+func TestUpdateTeam_MemberForbidden(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	ts := service.NewTeamServiceWithRepo(&tests.MockUserRepository{}, mockRepo)
+
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(newRolesTeam(), nil)
+
+	name := "Renamed"
+	_, err := ts.UpdateTeam(testMemberID, tests.TestTeamID, &dto.UpdateTeamRequest{Name: &name})
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestUpdateTeam_AdminKeepsMembership(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	ts := service.NewTeamServiceWithRepo(&tests.MockUserRepository{}, mockRepo)
+
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(newRolesTeam(), nil)
+	mockRepo.On("Update", mock.AnythingOfType("*entity.Team")).Return(nil)
+
+	name := "Renamed"
+	team, err := ts.UpdateTeam(testAdminID, tests.TestTeamID, &dto.UpdateTeamRequest{Name: &name})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed", team.Name)
+	assert.Len(t, team.UsersIds, 4)
+	assert.Equal(t, entity.TeamRoleAdmin, team.RoleOf(testAdminID))
+}
+
+func TestUpdateTeam_KeepsFieldsLeftOut(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	ts := service.NewTeamServiceWithRepo(&tests.MockUserRepository{}, mockRepo)
+
+	original := newRolesTeam()
+	original.Description = "study group"
+	original.IsPublic = true
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(original, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*entity.Team")).Return(nil)
+
+	name := "Renamed"
+	team, err := ts.UpdateTeam(testAdminID, tests.TestTeamID, &dto.UpdateTeamRequest{Name: &name})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "study group", team.Description)
+	assert.True(t, team.IsPublic)
+
+	private, description := false, ""
+	team, err = ts.UpdateTeam(testAdminID, tests.TestTeamID, &dto.UpdateTeamRequest{IsPublic: &private, Description: &description})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed", team.Name)
+	assert.Empty(t, team.Description)
+	assert.False(t, team.IsPublic)
+}
+
+func TestUpdateTeam_KeepsMembershipChangedSinceTheRead(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	uow := &tests.MockUnitOfWork{}
+	ts := service.NewTeamServiceWithRepo(&tests.MockUserRepository{}, mockRepo)
+	ts.SetUnitOfWork(uow.Factory())
+
+	stored := newRolesTeam()
+	stored.UsersIds = append(stored.UsersIds, "joined")
+	stored.SetRole("joined", entity.TeamRoleMember)
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(newRolesTeam(), nil)
+	uow.On("UpdateTeam", tests.TestTeamID).Return(stored)
+	uow.On("Commit").Return(nil)
+
+	name := "Renamed"
+	team, err := ts.UpdateTeam(testAdminID, tests.TestTeamID, &dto.UpdateTeamRequest{Name: &name})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed", team.Name)
+	assert.Contains(t, team.UsersIds, "joined")
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestSetMemberRole_ChecksThePermissionOnTheStoredTeam(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	uow := &tests.MockUnitOfWork{}
+	ts := service.NewTeamServiceWithRepo(&tests.MockUserRepository{}, mockRepo)
+	ts.SetUnitOfWork(uow.Factory())
+
+	// ownership was handed over after the team was read
+	stored := newRolesTeam()
+	stored.SetRole(testOwnerID, entity.TeamRoleAdmin)
+	stored.SetRole(testMemberID, entity.TeamRoleOwner)
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(newRolesTeam(), nil)
+	uow.On("UpdateTeam", tests.TestTeamID).Return(stored)
+	uow.On("Commit").Return(nil)
+
+	_, err := ts.SetMemberRole(testOwnerID, tests.TestTeamID, testOtherID, entity.TeamRoleAdmin)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	assert.Equal(t, entity.TeamRoleMember, stored.RoleOf(testOtherID))
+}
+
+func TestDeleteTeam_AdminForbidden(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	ts := service.NewTeamServiceWithRepo(&tests.MockUserRepository{}, mockRepo)
+
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(newRolesTeam(), nil)
+
+	err := ts.DeleteTeam(testAdminID, tests.TestTeamID)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestRemoveMember_Rules(t *testing.T) {
+	cases := []struct {
+		name    string
+		actor   string
+		target  string
+		allowed bool
+	}{
+		{"member leaves", testMemberID, testMemberID, true},
+		{"member removes member", testMemberID, testOtherID, false},
+		{"admin removes member", testAdminID, testMemberID, true},
+		{"admin removes admin", testAdminID, testAdminID, true},
+		{"owner removes admin", testOwnerID, testAdminID, true},
+		{"owner leaves", testOwnerID, testOwnerID, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := &tests.MockTeamRepository{}
+			mockUserRepo := &tests.MockUserRepository{}
+			ts := service.NewTeamServiceWithRepo(mockUserRepo, mockRepo)
+
+			team := newRolesTeam()
+			mockRepo.On("GetTeamById", tests.TestTeamID).Return(team, nil)
+			mockRepo.On("Update", team).Return(nil)
+			mockUserRepo.On("GetByID", tc.target).Return(&entity.User{ID: tc.target, TeamsIds: &[]string{tests.TestTeamID}}, nil)
+			mockUserRepo.On("Update", mock.AnythingOfType("*entity.User")).Return(nil)
+
+			_, _, err := ts.RemoveMember(tc.actor, tc.target, tests.TestTeamID)
+
+			if tc.allowed {
+				assert.NoError(t, err)
+				assert.False(t, team.IsMember(tc.target))
+				assert.NotContains(t, team.Roles, tc.target)
+			} else {
+				assert.ErrorIs(t, err, service.ErrForbidden)
+				assert.True(t, team.IsMember(tc.target))
+			}
+		})
+	}
+}
+
+func TestAdminCannotRemoveOtherAdmin(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	ts := service.NewTeamServiceWithRepo(&tests.MockUserRepository{}, mockRepo)
+
+	team := newRolesTeam()
+	team.SetRole(testOtherID, entity.TeamRoleAdmin)
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(team, nil)
+
+	_, _, err := ts.RemoveMember(testAdminID, testOtherID, tests.TestTeamID)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+}
+
+func TestSetMemberRole(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	ts := service.NewTeamServiceWithRepo(&tests.MockUserRepository{}, mockRepo)
+
+	team := newRolesTeam()
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(team, nil)
+	mockRepo.On("Update", team).Return(nil)
+
+	_, err := ts.SetMemberRole(testAdminID, tests.TestTeamID, testMemberID, entity.TeamRoleAdmin)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	_, err = ts.SetMemberRole(testOwnerID, tests.TestTeamID, testMemberID, entity.TeamRoleOwner)
+	assert.Error(t, err)
+
+	updated, err := ts.SetMemberRole(testOwnerID, tests.TestTeamID, testMemberID, entity.TeamRoleAdmin)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.TeamRoleAdmin, updated.RoleOf(testMemberID))
+}
+
+func TestTransferOwnership(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	ts := service.NewTeamServiceWithRepo(&tests.MockUserRepository{}, mockRepo)
+
+	team := newRolesTeam()
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(team, nil)
+	mockRepo.On("Update", team).Return(nil)
+
+	_, err := ts.TransferOwnership(testAdminID, tests.TestTeamID, testAdminID)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	_, err = ts.TransferOwnership(testOwnerID, tests.TestTeamID, "stranger")
+	assert.Error(t, err)
+
+	updated, err := ts.TransferOwnership(testOwnerID, tests.TestTeamID, testMemberID)
+	assert.NoError(t, err)
+	assert.Equal(t, testMemberID, updated.OwnerID())
+	assert.Equal(t, entity.TeamRoleAdmin, updated.RoleOf(testOwnerID))
+}
+
+func TestAddMember_SelfJoin(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	mockUserRepo := &tests.MockUserRepository{}
+	ts := service.NewTeamServiceWithRepo(mockUserRepo, mockRepo)
+
+	publicTeam := newRolesTeam()
+	publicTeam.IsPublic = true
+	privateTeam := newRolesTeam()
+	privateTeam.Id = tests.TestTeamID2
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(publicTeam, nil)
+	mockRepo.On("GetTeamById", tests.TestTeamID2).Return(privateTeam, nil)
+	mockRepo.On("Update", publicTeam).Return(nil)
+	mockUserRepo.On("GetByID", "stranger").Return(&entity.User{ID: "stranger"}, nil)
+	mockUserRepo.On("Update", mock.AnythingOfType("*entity.User")).Return(nil)
+
+	// private teams are joined only through a request or an invitation, and stay hidden from non-members
+	_, _, err := ts.AddMember("stranger", "stranger", tests.TestTeamID2)
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+	_, _, err = ts.AddMember(testMemberID, testMemberID, tests.TestTeamID2)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	_, team, err := ts.AddMember("stranger", "stranger", tests.TestTeamID)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.TeamRoleMember, team.RoleOf("stranger"))
+	assert.False(t, privateTeam.IsMember("stranger"))
+}
+
+func TestAddMember_OthersNeedManageMembers(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	ts := service.NewTeamServiceWithRepo(&tests.MockUserRepository{}, mockRepo)
+
+	team := newRolesTeam()
+	team.IsPublic = true
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(team, nil)
+
+	_, _, err := ts.AddMember(testMemberID, "stranger", tests.TestTeamID)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	assert.False(t, team.IsMember("stranger"))
+}
+
+func TestGetVisibleTeams_HidesPrivateTeamsFromNonMembers(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	ts := service.NewTeamServiceWithRepo(&tests.MockUserRepository{}, mockRepo)
+
+	public := &entity.Team{Id: "public", IsPublic: true}
+	private := &entity.Team{Id: "private", UsersIds: []string{testMemberID}}
+	mockRepo.On("GetAll").Return([]*entity.Team{public, private}, nil)
+	mockRepo.On("GetTeamById", "private").Return(private, nil)
+
+	teams, err := ts.GetVisibleTeams("stranger")
+	assert.NoError(t, err)
+	assert.Equal(t, []*entity.Team{public}, teams)
+
+	teams, err = ts.GetVisibleTeams(testMemberID)
+	assert.NoError(t, err)
+	assert.Equal(t, []*entity.Team{public, private}, teams)
+
+	_, err = ts.GetVisibleTeam("stranger", "private")
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+	team, err := ts.GetVisibleTeam(testMemberID, "private")
+	assert.NoError(t, err)
+	assert.Equal(t, private, team)
+}
+
+func TestGetXVisibleTeamsByPrefix_FetchesMoreWhenTeamsAreHidden(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	ts := service.NewTeamServiceWithRepo(&tests.MockUserRepository{}, mockRepo)
+
+	hidden1 := &entity.Team{Id: "a1"}
+	hidden2 := &entity.Team{Id: "a2"}
+	public1 := &entity.Team{Id: "a3", IsPublic: true}
+	public2 := &entity.Team{Id: "a4", IsPublic: true}
+	mockRepo.On("GetXTeamsByPrefix", "a", 2).Return([]*entity.Team{hidden1, hidden2}, nil).Once()
+	mockRepo.On("GetXTeamsByPrefix", "a", 4).Return([]*entity.Team{hidden1, hidden2, public1, public2}, nil).Once()
+
+	teams, err := ts.GetXVisibleTeamsByPrefix("stranger", "a", 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*entity.Team{public1, public2}, teams)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEventService_DeleteEvent_OtherMemberForbidden(t *testing.T) {
+	mockEventRepo := new(tests.MockEventRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	es := service.NewEventServiceWithRepo(mockEventRepo, mockTeamRepo, new(tests.MockUserRepository))
+
+	event := tests.GetValidEvent()
+	event.InitiatorID = testOtherID
+	mockEventRepo.On("GetByID", tests.TestEventID).Return(&event, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(newRolesTeam(), nil)
+
+	err := es.DeleteEvent(tests.TestEventID, testMemberID)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	mockEventRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestEventService_DeleteEvent_AdminModerates(t *testing.T) {
+	mockEventRepo := new(tests.MockEventRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	es := service.NewEventServiceWithRepo(mockEventRepo, mockTeamRepo, new(tests.MockUserRepository))
+
+	event := tests.GetValidEvent()
+	event.InitiatorID = testOtherID
+	mockEventRepo.On("GetByID", tests.TestEventID).Return(&event, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(newRolesTeam(), nil)
+	mockEventRepo.On("Delete", tests.TestEventID).Return(nil)
+
+	err := es.DeleteEvent(tests.TestEventID, testAdminID)
+
+	assert.NoError(t, err)
+}
+
+func TestFileService_DeleteFile_OtherMemberForbidden(t *testing.T) {
+	mockFileRepo := new(tests.MockFileRepository)
+	mockUserRepo := new(tests.MockUserRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	fs := service.NewFileServiceWithRepo(mockFileRepo, mockUserRepo, mockTeamRepo)
+
+	file := &entity.File{ID: "file1", OwnerID: testOtherID, ContextType: entity.FileContextTeam, ContextID: tests.TestTeamID}
+	mockFileRepo.On("GetByID", "file1").Return(file, nil)
+	mockUserRepo.On("GetByID", testMemberID).Return(&entity.User{ID: testMemberID, TeamsIds: &[]string{tests.TestTeamID}}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(newRolesTeam(), nil)
+
+	err := fs.DeleteFile("file1", testMemberID)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	mockFileRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
@@ -0,0 +1,121 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newRecommendationMocks() (*service.TeamRecommendationService, *tests.MockUserRepository, *tests.MockTeamRepository, *tests.MockFriendRequestRepository, *tests.MockTeamRequestRepository, *tests.MockMessageRepository) {
+	mockUserRepo := new(tests.MockUserRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	mockFriendRequestRepo := new(tests.MockFriendRequestRepository)
+	mockTeamRequestRepo := new(tests.MockTeamRequestRepository)
+	mockMessageRepo := new(tests.MockMessageRepository)
+	rs := service.NewTeamRecommendationServiceWithRepo(mockUserRepo, mockTeamRepo, mockFriendRequestRepo, mockTeamRequestRepo, mockMessageRepo)
+	return rs, mockUserRepo, mockTeamRepo, mockFriendRequestRepo, mockTeamRequestRepo, mockMessageRepo
+}
+
+func TestTeamRecommendationService_RanksAndExcludes(t *testing.T) {
+	rs, mockUserRepo, mockTeamRepo, mockFriendRequestRepo, mockTeamRequestRepo, mockMessageRepo := newRecommendationMocks()
+	topics := []model.TopicOfInterest{model.Mathematics}
+	teamIDs := []string{"joined"}
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1, TeamsIds: &teamIDs, TopicsOfInterest: &topics}, nil)
+	mockFriendRequestRepo.On("GetFriendsForUser", tests.TestUserID1).Return([]string{"friend"}, nil)
+	mockTeamRequestRepo.On("GetByUserId", tests.TestUserID1).Return([]*entity.TeamRequest{entity.NewTeamRequest("r1", tests.TestUserID1, "requested", "", time.Now().Add(time.Hour))}, nil)
+	mockTeamRepo.On("GetAll").Return([]*entity.Team{
+		{Id: "joined", IsPublic: true, TeamTopic: model.Mathematics, UsersIds: []string{tests.TestUserID1}},
+		{Id: "requested", IsPublic: true, TeamTopic: model.Mathematics},
+		{Id: "private", IsPublic: false, TeamTopic: model.Mathematics},
+		{Id: "topic", IsPublic: true, TeamTopic: model.Mathematics, UsersIds: []string{"a"}},
+		{Id: "friends", IsPublic: true, TeamTopic: model.Art, UsersIds: []string{"friend", "b"}},
+		{Id: "busy", IsPublic: true, TeamTopic: model.Music, UsersIds: []string{"c"}},
+	}, nil)
+	mockMessageRepo.On("CountTeam", "busy", mock.Anything).Return(50, nil)
+	mockMessageRepo.On("CountTeam", mock.Anything, mock.Anything).Return(0, nil)
+
+	got, err := rs.GetRecommendedTeams(tests.TestUserID1, 1, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, got.TotalCount)
+	ids := []string{}
+	for _, recommendation := range got.Teams {
+		ids = append(ids, recommendation.Team.Id)
+	}
+	assert.Equal(t, []string{"topic", "busy", "friends"}, ids)
+	assert.True(t, got.Teams[0].MatchesTopic)
+	assert.Equal(t, 50, got.Teams[1].RecentMessages)
+	assert.Equal(t, []string{"friend"}, got.Teams[2].FriendIDs)
+	assert.Equal(t, 0.405, got.Teams[0].Score)
+}
+
+func TestTeamRecommendationService_Pages(t *testing.T) {
+	rs, mockUserRepo, mockTeamRepo, mockFriendRequestRepo, mockTeamRequestRepo, mockMessageRepo := newRecommendationMocks()
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1}, nil)
+	mockFriendRequestRepo.On("GetFriendsForUser", tests.TestUserID1).Return([]string{}, nil)
+	mockTeamRequestRepo.On("GetByUserId", tests.TestUserID1).Return([]*entity.TeamRequest{}, nil)
+	mockTeamRepo.On("GetAll").Return([]*entity.Team{
+		{Id: "a", IsPublic: true}, {Id: "b", IsPublic: true}, {Id: "c", IsPublic: true},
+	}, nil)
+	mockMessageRepo.On("CountTeam", mock.Anything, mock.Anything).Return(0, nil)
+
+	got, err := rs.GetRecommendedTeams(tests.TestUserID1, 2, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, got.Teams, 1)
+	assert.Equal(t, "c", got.Teams[0].Team.Id)
+	assert.Equal(t, 2, got.TotalPages)
+
+	got, err = rs.GetRecommendedTeams(tests.TestUserID1, 5, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, got.Teams)
+}
+
+func TestTeamRecommendationService_CountsOnlyTeamsThatCanReachThePage(t *testing.T) {
+	rs, mockUserRepo, mockTeamRepo, mockFriendRequestRepo, mockTeamRequestRepo, mockMessageRepo := newRecommendationMocks()
+	topics := []model.TopicOfInterest{model.Mathematics}
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1, TopicsOfInterest: &topics}, nil)
+	mockFriendRequestRepo.On("GetFriendsForUser", tests.TestUserID1).Return([]string{}, nil)
+	mockTeamRequestRepo.On("GetByUserId", tests.TestUserID1).Return([]*entity.TeamRequest{}, nil)
+	mockTeamRepo.On("GetAll").Return([]*entity.Team{
+		{Id: "quiet", IsPublic: true, TeamTopic: model.Art},
+		{Id: "topic", IsPublic: true, TeamTopic: model.Mathematics},
+		{Id: "other", IsPublic: true, TeamTopic: model.Music},
+	}, nil)
+	mockMessageRepo.On("CountTeam", "topic", mock.Anything).Return(10, nil).Once()
+
+	got, err := rs.GetRecommendedTeams(tests.TestUserID1, 1, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, got.TotalCount)
+	assert.Equal(t, "topic", got.Teams[0].Team.Id)
+	assert.Equal(t, 10, got.Teams[0].RecentMessages)
+	// full activity would give the other teams 0.2, below the 0.44 of the only team on the page
+	mockMessageRepo.AssertNumberOfCalls(t, "CountTeam", 1)
+}
+
+func TestTeamRecommendationService_AnsweredRequestsDoNotExclude(t *testing.T) {
+	rs, mockUserRepo, mockTeamRepo, mockFriendRequestRepo, mockTeamRequestRepo, mockMessageRepo := newRecommendationMocks()
+	rejected := entity.NewTeamRequest("r1", tests.TestUserID1, "rejected", "", time.Now().Add(time.Hour))
+	rejected.Resolve(entity.TeamRequestRejected, tests.TestUserID2, time.Now())
+	expired := entity.NewTeamRequest("r2", tests.TestUserID1, "expired", "", time.Now().Add(-time.Hour))
+	mockUserRepo.On("GetByID", tests.TestUserID1).Return(&entity.User{ID: tests.TestUserID1}, nil)
+	mockFriendRequestRepo.On("GetFriendsForUser", tests.TestUserID1).Return([]string{}, nil)
+	mockTeamRequestRepo.On("GetByUserId", tests.TestUserID1).Return([]*entity.TeamRequest{rejected, expired}, nil)
+	mockTeamRepo.On("GetAll").Return([]*entity.Team{
+		{Id: "rejected", IsPublic: true},
+		{Id: "expired", IsPublic: true},
+	}, nil)
+	mockMessageRepo.On("CountTeam", mock.Anything, mock.Anything).Return(0, nil)
+
+	got, err := rs.GetRecommendedTeams(tests.TestUserID1, 1, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, got.TotalCount)
+}
@@ -1,21 +1,31 @@
 package service_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/persistence"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
 	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// pendingTeamRequest copies tests.ValidTeamRequest, since answering a request changes it
+func pendingTeamRequest() *entity.TeamRequest {
+	req := tests.ValidTeamRequest
+	return &req
+}
+
 func TestCreateTeamRequest_Success(t *testing.T) {
 	mockTRRepo := &tests.MockTeamRequestRepository{}
 	mockUserRepo := &tests.MockUserRepository{}
 	mockTeamRepo := &tests.MockTeamRepository{}
-	service := service.NewTeamRequestServiceWithRepo(mockTRRepo, mockUserRepo, mockTeamRepo, nil)
+	service := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, mockUserRepo, mockTeamRepo, nil)
 
 	req := &dto.TeamRequestCreateDTO{
 		UserID: tests.TestUserID1,
@@ -37,7 +47,7 @@ func TestCreateTeamRequest_UserAlreadyMember(t *testing.T) {
 	mockTRRepo := &tests.MockTeamRequestRepository{}
 	mockUserRepo := &tests.MockUserRepository{}
 	mockTeamRepo := &tests.MockTeamRepository{}
-	service := service.NewTeamRequestServiceWithRepo(mockTRRepo, mockUserRepo, mockTeamRepo, nil)
+	service := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, mockUserRepo, mockTeamRepo, nil)
 
 	req := &dto.TeamRequestCreateDTO{
 		UserID: tests.TestUserID,
@@ -57,43 +67,89 @@ func TestAcceptTeamRequest_Success(t *testing.T) {
 	mockUserRepo := &tests.MockUserRepository{}
 	mockTeamRepo := &tests.MockTeamRepository{}
 	mockTeamService := &tests.MockTeamService{}
-	service := service.NewTeamRequestServiceWithRepo(mockTRRepo, mockUserRepo, mockTeamRepo, mockTeamService)
+	service := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, mockUserRepo, mockTeamRepo, mockTeamService)
 
-	mockTRRepo.On("GetById", tests.TestTeamRequestID).Return(&tests.ValidTeamRequest, nil)
+	mockTRRepo.On("GetById", tests.TestTeamRequestID).Return(pendingTeamRequest(), nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
 	mockTeamService.On("AddUserToTeam", tests.TestUserID1, tests.TestTeamID).Return(&entity.User{ID: tests.TestUserID1}, &tests.ValidTeam, nil)
-	mockTRRepo.On("Delete", tests.TestTeamRequestID).Return(nil)
+	mockTRRepo.On("UpdateIfStatus", mock.AnythingOfType("*entity.TeamRequest"), entity.TeamRequestPending).Return(true, nil)
 
-	user, team, err := service.AcceptTeamRequest(tests.TestTeamRequestID)
+	user, team, err := service.AcceptTeamRequest(tests.TestTeamRequestID, tests.TestUserID)
 	assert.NoError(t, err)
 	assert.Equal(t, tests.TestUserID1, user.ID)
 	assert.Equal(t, tests.ValidTeam.Id, team.Id)
 }
 
+func TestAcceptTeamRequest_ReviewerNotAdmin(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	mockTeamService := &tests.MockTeamService{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, mockTeamRepo, mockTeamService)
+
+	team := &entity.Team{
+		Id:       tests.TestTeamID,
+		UsersIds: []string{tests.TestUserID, tests.TestUserID2},
+		Roles:    map[string]entity.TeamRole{tests.TestUserID: entity.TeamRoleOwner, tests.TestUserID2: entity.TeamRoleMember},
+	}
+	mockTRRepo.On("GetById", tests.TestTeamRequestID).Return(pendingTeamRequest(), nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(team, nil)
+
+	_, _, err := trs.AcceptTeamRequest(tests.TestTeamRequestID, tests.TestUserID2)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	mockTeamService.AssertNotCalled(t, "AddUserToTeam", mock.Anything, mock.Anything)
+	mockTRRepo.AssertNotCalled(t, "UpdateIfStatus", mock.Anything, mock.Anything)
+}
+
 func TestRejectTeamRequest_Success(t *testing.T) {
 	mockTRRepo := &tests.MockTeamRequestRepository{}
-	service := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, nil)
+	mockTeamRepo := &tests.MockTeamRepository{}
+	service := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, mockTeamRepo, nil)
 
-	mockTRRepo.On("GetById", tests.TestTeamRequestID).Return(&tests.ValidTeamRequest, nil)
-	mockTRRepo.On("Delete", tests.TestTeamRequestID).Return(nil)
+	mockTRRepo.On("GetById", tests.TestTeamRequestID).Return(pendingTeamRequest(), nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockTRRepo.On("UpdateIfStatus", mock.AnythingOfType("*entity.TeamRequest"), entity.TeamRequestPending).Return(true, nil)
 
-	err := service.RejectTeamRequest(tests.TestTeamRequestID)
+	err := service.RejectTeamRequest(tests.TestTeamRequestID, tests.TestUserID)
 	assert.NoError(t, err)
 }
 
-func TestGetAllTeamRequests(t *testing.T) {
+func TestTeamRequest_LegacyRequestsDoNotExpire(t *testing.T) {
+	// stored before statuses and expiry existed, and not backfilled yet
+	legacy := &entity.TeamRequest{Id: "old", UserID: tests.TestUserID1, TeamID: tests.TestTeamID}
+	later := time.Now().Add(10 * entity.TeamRequestTTL)
+
+	assert.Equal(t, entity.TeamRequestPending, legacy.CurrentStatus(later))
+	assert.False(t, legacy.Expire(later))
+	assert.Empty(t, legacy.Status)
+}
+
+func TestGetAllTeamRequests_OnlyWhatTheActorMaySee(t *testing.T) {
 	mockTRRepo := &tests.MockTeamRequestRepository{}
-	service := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, nil)
+	mockUserRepo := &tests.MockUserRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, mockUserRepo, mockTeamRepo, nil)
+
+	expiresAt := time.Now().Add(time.Hour)
+	own := entity.NewTeamRequest("own", testAdminID, tests.TestTeamID2, "", expiresAt)
+	reviewed := entity.NewTeamRequest("reviewed", tests.TestUserID1, tests.TestTeamID, "", expiresAt)
+	memberTeam := &entity.Team{Id: "team3", UsersIds: []string{testAdminID}, Roles: map[string]entity.TeamRole{testAdminID: entity.TeamRoleMember}}
+	mockUserRepo.On("GetByID", testAdminID).Return(&entity.User{ID: testAdminID, TeamsIds: &[]string{tests.TestTeamID, "team3"}}, nil)
+	mockTRRepo.On("GetByUserId", testAdminID).Return([]*entity.TeamRequest{own}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(newRolesTeam(), nil)
+	mockTeamRepo.On("GetTeamById", "team3").Return(memberTeam, nil)
+	mockTRRepo.On("GetByTeamId", tests.TestTeamID).Return([]*entity.TeamRequest{reviewed}, nil)
 
-	mockTRRepo.On("GetAll").Return([]*entity.TeamRequest{&tests.ValidTeamRequest}, nil)
+	requests, err := trs.GetAll(testAdminID)
 
-	reqs, err := service.GetAll()
 	assert.NoError(t, err)
-	assert.Len(t, reqs, 1)
+	assert.ElementsMatch(t, []*entity.TeamRequest{own, reviewed}, requests)
+	mockTRRepo.AssertNotCalled(t, "GetByTeamId", "team3")
+	mockTRRepo.AssertNotCalled(t, "GetAll")
 }
 
 func TestGetByUserId(t *testing.T) {
 	mockTRRepo := &tests.MockTeamRequestRepository{}
-	service := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, nil)
+	service := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, nil, nil)
 
 	mockTRRepo.On("GetByUserId", tests.TestUserID1).Return([]*entity.TeamRequest{&tests.ValidTeamRequest}, nil)
 
@@ -102,3 +158,550 @@ func TestGetByUserId(t *testing.T) {
 	assert.Len(t, reqs, 1)
 	assert.Equal(t, tests.TestUserID1, reqs[0].UserID)
 }
+
+func TestInviteToTeam_ByUsername(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockUserRepo := &tests.MockUserRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, mockUserRepo, mockTeamRepo, nil)
+
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockUserRepo.On("GetByUsername", tests.TestUsername).Return(&entity.User{ID: tests.TestUserID2}, nil)
+	mockTRRepo.On("GetByUserId", tests.TestUserID2).Return([]*entity.TeamRequest{}, nil)
+	mockTRRepo.On("Create", mock.AnythingOfType("*entity.TeamRequest")).Return(nil)
+
+	invite, err := trs.InviteToTeam(tests.TestUserID, tests.TestTeamID, &dto.TeamInviteRequest{Username: tests.TestUsername})
+
+	assert.NoError(t, err)
+	assert.True(t, invite.IsInvite())
+	assert.Equal(t, tests.TestUserID2, invite.UserID)
+	assert.Equal(t, tests.TestTeamID, invite.TeamID)
+	assert.Equal(t, tests.TestUserID, invite.InvitedBy)
+}
+
+func TestInviteToTeam_RequiresExactlyOneInvitee(t *testing.T) {
+	trs := service.NewTeamRequestServiceWithRepo(nil, nil, nil, nil, nil)
+
+	_, err := trs.InviteToTeam(tests.TestUserID, tests.TestTeamID, &dto.TeamInviteRequest{UserID: tests.TestUserID2, Email: tests.TestEmail})
+	assert.ErrorContains(t, err, "validation failed")
+
+	_, err = trs.InviteToTeam(tests.TestUserID, tests.TestTeamID, &dto.TeamInviteRequest{})
+	assert.ErrorContains(t, err, "validation failed")
+}
+
+func TestInviteToTeam_MemberCannotInvite(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, mockTeamRepo, nil)
+
+	team := &entity.Team{
+		Id:       tests.TestTeamID,
+		UsersIds: []string{tests.TestUserID, tests.TestUserID2},
+		Roles:    map[string]entity.TeamRole{tests.TestUserID: entity.TeamRoleOwner, tests.TestUserID2: entity.TeamRoleMember},
+	}
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(team, nil)
+
+	_, err := trs.InviteToTeam(tests.TestUserID2, tests.TestTeamID, &dto.TeamInviteRequest{UserID: tests.TestUserID1})
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	mockTRRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestInviteToTeam_UnknownUser(t *testing.T) {
+	mockUserRepo := &tests.MockUserRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(nil, nil, mockUserRepo, mockTeamRepo, nil)
+
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockUserRepo.On("GetByEmail", tests.TestEmail).Return(nil, errors.New(tests.ErrUserNotFound))
+
+	_, err := trs.InviteToTeam(tests.TestUserID, tests.TestTeamID, &dto.TeamInviteRequest{Email: tests.TestEmail})
+
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+}
+
+func TestInviteToTeam_AlreadyInvited(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockUserRepo := &tests.MockUserRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, mockUserRepo, mockTeamRepo, nil)
+
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockUserRepo.On("GetByID", tests.TestUserID2).Return(&entity.User{ID: tests.TestUserID2}, nil)
+	mockTRRepo.On("GetByUserId", tests.TestUserID2).Return([]*entity.TeamRequest{
+		entity.NewTeamInvite("invite1", tests.TestUserID2, tests.TestTeamID, tests.TestUserID, time.Now().Add(time.Hour)),
+	}, nil)
+
+	_, err := trs.InviteToTeam(tests.TestUserID, tests.TestTeamID, &dto.TeamInviteRequest{UserID: tests.TestUserID2})
+
+	assert.EqualError(t, err, "the user is already invited to this team")
+	mockTRRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCreateTeamRequest_AlreadyInvited(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockUserRepo := &tests.MockUserRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, mockUserRepo, mockTeamRepo, nil)
+
+	mockUserRepo.On("GetByID", tests.TestUserID2).Return(&entity.User{ID: tests.TestUserID2}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockTRRepo.On("GetByUserId", tests.TestUserID2).Return([]*entity.TeamRequest{
+		entity.NewTeamInvite("invite1", tests.TestUserID2, tests.TestTeamID, tests.TestUserID, time.Now().Add(time.Hour)),
+	}, nil)
+
+	_, err := trs.CreateTeamRequest(&dto.TeamRequestCreateDTO{UserID: tests.TestUserID2, TeamID: tests.TestTeamID})
+
+	assert.EqualError(t, err, "the user is already invited to this team, accept the invitation instead")
+}
+
+func TestAcceptTeamRequest_RejectsInvitation(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockTeamService := &tests.MockTeamService{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, nil, mockTeamService)
+
+	mockTRRepo.On("GetById", "invite1").Return(entity.NewTeamInvite("invite1", tests.TestUserID2, tests.TestTeamID, tests.TestUserID, time.Now().Add(time.Hour)), nil)
+
+	_, _, err := trs.AcceptTeamRequest("invite1", tests.TestUserID)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	mockTeamService.AssertNotCalled(t, "AddUserToTeam", mock.Anything, mock.Anything)
+}
+
+func TestGetInvitations_OnlyInvites(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, nil, nil)
+
+	mockTRRepo.On("GetByUserId", tests.TestUserID2).Return([]*entity.TeamRequest{
+		entity.NewTeamRequest("req1", tests.TestUserID2, tests.TestTeamID2, "", time.Now().Add(time.Hour)),
+		entity.NewTeamInvite("invite1", tests.TestUserID2, tests.TestTeamID, tests.TestUserID, time.Now().Add(time.Hour)),
+	}, nil)
+
+	invitations, err := trs.GetInvitations(tests.TestUserID2)
+
+	assert.NoError(t, err)
+	assert.Len(t, invitations, 1)
+	assert.Equal(t, "invite1", invitations[0].Id)
+}
+
+func TestAcceptInvitation_Success(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockTeamService := &tests.MockTeamService{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, nil, mockTeamService)
+
+	mockTRRepo.On("GetById", "invite1").Return(entity.NewTeamInvite("invite1", tests.TestUserID2, tests.TestTeamID, tests.TestUserID, time.Now().Add(time.Hour)), nil)
+	mockTeamService.On("AddUserToTeam", tests.TestUserID2, tests.TestTeamID).Return(&entity.User{ID: tests.TestUserID2}, &tests.ValidTeam, nil)
+	mockTRRepo.On("UpdateIfStatus", mock.MatchedBy(func(r *entity.TeamRequest) bool {
+		return r.Status == entity.TeamRequestAccepted && r.ReviewerID == tests.TestUserID2
+	}), entity.TeamRequestPending).Return(true, nil)
+
+	user, team, err := trs.AcceptInvitation("invite1", tests.TestUserID2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, tests.TestUserID2, user.ID)
+	assert.Equal(t, tests.TestTeamID, team.Id)
+	mockTRRepo.AssertExpectations(t)
+}
+
+func TestAcceptInvitation_NotInvitee(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockTeamService := &tests.MockTeamService{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, nil, mockTeamService)
+
+	mockTRRepo.On("GetById", "invite1").Return(entity.NewTeamInvite("invite1", tests.TestUserID2, tests.TestTeamID, tests.TestUserID, time.Now().Add(time.Hour)), nil)
+
+	_, _, err := trs.AcceptInvitation("invite1", tests.TestUserID1)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	mockTeamService.AssertNotCalled(t, "AddUserToTeam", mock.Anything, mock.Anything)
+}
+
+func TestDeclineInvitation_JoinRequestIsNotAnInvitation(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, nil, nil)
+
+	mockTRRepo.On("GetById", tests.TestTeamRequestID).Return(pendingTeamRequest(), nil)
+
+	err := trs.DeclineInvitation(tests.TestTeamRequestID, tests.TestUserID1)
+
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+	mockTRRepo.AssertNotCalled(t, "UpdateIfStatus", mock.Anything, mock.Anything)
+}
+
+func TestCreateInviteLink_DefaultExpiry(t *testing.T) {
+	mockLinkRepo := &tests.MockTeamInviteLinkRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(nil, mockLinkRepo, nil, mockTeamRepo, nil)
+
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockLinkRepo.On("Create", mock.AnythingOfType("*entity.TeamInviteLink")).Return(nil)
+
+	link, err := trs.CreateInviteLink(tests.TestUserID, tests.TestTeamID, &dto.TeamInviteLinkRequest{MaxUses: 5})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, link.ID)
+	assert.Equal(t, 5, link.MaxUses)
+	assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), link.ExpiresAt, time.Minute)
+}
+
+func TestCreateInviteLink_InvalidLimits(t *testing.T) {
+	trs := service.NewTeamRequestServiceWithRepo(nil, nil, nil, nil, nil)
+
+	_, err := trs.CreateInviteLink(tests.TestUserID, tests.TestTeamID, &dto.TeamInviteLinkRequest{ExpiresInHours: 31 * 24})
+	assert.ErrorContains(t, err, "validation failed")
+
+	_, err = trs.CreateInviteLink(tests.TestUserID, tests.TestTeamID, &dto.TeamInviteLinkRequest{MaxUses: -1})
+	assert.ErrorContains(t, err, "validation failed")
+}
+
+func TestRevokeInviteLink_OtherTeam(t *testing.T) {
+	mockLinkRepo := &tests.MockTeamInviteLinkRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(nil, mockLinkRepo, nil, mockTeamRepo, nil)
+
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockLinkRepo.On("GetByID", "token1").Return(&entity.TeamInviteLink{ID: "token1", TeamID: tests.TestTeamID2}, nil)
+
+	err := trs.RevokeInviteLink(tests.TestUserID, tests.TestTeamID, "token1")
+
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+	mockLinkRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestJoinByInviteLink_Success(t *testing.T) {
+	mockLinkRepo := &tests.MockTeamInviteLinkRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	mockTeamService := &tests.MockTeamService{}
+	trs := service.NewTeamRequestServiceWithRepo(nil, mockLinkRepo, nil, mockTeamRepo, mockTeamService)
+
+	link := &entity.TeamInviteLink{ID: "token1", TeamID: tests.TestTeamID, ExpiresAt: time.Now().Add(time.Hour)}
+	mockLinkRepo.On("GetByID", "token1").Return(link, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockLinkRepo.On("Redeem", "token1", mock.AnythingOfType("time.Time")).Return(link, nil)
+	mockTeamService.On("AddUserToTeam", tests.TestUserID2, tests.TestTeamID).Return(&entity.User{ID: tests.TestUserID2}, &tests.ValidTeam, nil)
+
+	user, _, err := trs.JoinByInviteLink(tests.TestUserID2, "token1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, tests.TestUserID2, user.ID)
+}
+
+func TestJoinByInviteLink_JoinFailureGivesUseBack(t *testing.T) {
+	mockLinkRepo := &tests.MockTeamInviteLinkRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	mockTeamService := &tests.MockTeamService{}
+	trs := service.NewTeamRequestServiceWithRepo(nil, mockLinkRepo, nil, mockTeamRepo, mockTeamService)
+
+	link := &entity.TeamInviteLink{ID: "token1", TeamID: tests.TestTeamID, ExpiresAt: time.Now().Add(time.Hour), MaxUses: 1}
+	mockLinkRepo.On("GetByID", "token1").Return(link, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockLinkRepo.On("Redeem", "token1", mock.AnythingOfType("time.Time")).Return(link, nil)
+	mockTeamService.On("AddUserToTeam", tests.TestUserID2, tests.TestTeamID).Return(nil, nil, errors.New("db down"))
+	mockLinkRepo.On("Release", "token1").Return(nil).Once()
+
+	_, _, err := trs.JoinByInviteLink(tests.TestUserID2, "token1")
+
+	assert.EqualError(t, err, "db down")
+	mockLinkRepo.AssertExpectations(t)
+}
+
+func TestJoinByInviteLink_MemberDoesNotUseLink(t *testing.T) {
+	mockLinkRepo := &tests.MockTeamInviteLinkRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(nil, mockLinkRepo, nil, mockTeamRepo, nil)
+
+	mockLinkRepo.On("GetByID", "token1").Return(&entity.TeamInviteLink{ID: "token1", TeamID: tests.TestTeamID}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+
+	_, _, err := trs.JoinByInviteLink(tests.TestUserID, "token1")
+
+	assert.EqualError(t, err, "user is already a member of this team")
+	mockLinkRepo.AssertNotCalled(t, "Redeem", mock.Anything, mock.Anything)
+}
+
+func TestJoinByInviteLink_UsedUp(t *testing.T) {
+	mockLinkRepo := &tests.MockTeamInviteLinkRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	mockTeamService := &tests.MockTeamService{}
+	trs := service.NewTeamRequestServiceWithRepo(nil, mockLinkRepo, nil, mockTeamRepo, mockTeamService)
+
+	mockLinkRepo.On("GetByID", "token1").Return(&entity.TeamInviteLink{ID: "token1", TeamID: tests.TestTeamID}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockLinkRepo.On("Redeem", "token1", mock.AnythingOfType("time.Time")).Return(nil, errors.New(persistence.TeamInviteLinkUnusable))
+
+	_, _, err := trs.JoinByInviteLink(tests.TestUserID2, "token1")
+
+	assert.EqualError(t, err, persistence.TeamInviteLinkUnusable)
+	mockTeamService.AssertNotCalled(t, "AddUserToTeam", mock.Anything, mock.Anything)
+}
+
+func TestCreateTeamRequest_AfterRejectedRequest(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockUserRepo := &tests.MockUserRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, mockUserRepo, mockTeamRepo, nil)
+
+	rejected := entity.NewTeamRequest("req1", tests.TestUserID2, tests.TestTeamID, "", time.Now().Add(time.Hour))
+	rejected.Resolve(entity.TeamRequestRejected, tests.TestUserID, time.Now())
+	mockUserRepo.On("GetByID", tests.TestUserID2).Return(&entity.User{ID: tests.TestUserID2}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockTRRepo.On("GetByUserId", tests.TestUserID2).Return([]*entity.TeamRequest{rejected}, nil)
+	mockTRRepo.On("Create", mock.AnythingOfType("*entity.TeamRequest")).Return(nil)
+
+	req, err := trs.CreateTeamRequest(&dto.TeamRequestCreateDTO{UserID: tests.TestUserID2, TeamID: tests.TestTeamID, Message: "  I'd like to help  "})
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.TeamRequestPending, req.Status)
+	assert.Equal(t, "I'd like to help", req.Message)
+	assert.False(t, req.CreatedAt.IsZero())
+	assert.True(t, req.ExpiresAt.After(time.Now().Add(29*24*time.Hour)))
+}
+
+func TestCreateTeamRequest_MessageTooLong(t *testing.T) {
+	trs := service.NewTeamRequestServiceWithRepo(nil, nil, nil, nil, nil)
+
+	_, err := trs.CreateTeamRequest(&dto.TeamRequestCreateDTO{UserID: tests.TestUserID2, TeamID: tests.TestTeamID, Message: strings.Repeat("a", 501)})
+
+	assert.EqualError(t, err, "message cannot be longer than 500 characters")
+}
+
+func TestAcceptTeamRequest_UpdateFails(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	mockTeamService := &tests.MockTeamService{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, mockTeamRepo, mockTeamService)
+
+	mockTRRepo.On("GetById", tests.TestTeamRequestID).Return(pendingTeamRequest(), nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockTRRepo.On("UpdateIfStatus", mock.AnythingOfType("*entity.TeamRequest"), entity.TeamRequestPending).Return(false, errors.New("write failed"))
+
+	_, _, err := trs.AcceptTeamRequest(tests.TestTeamRequestID, tests.TestUserID)
+
+	assert.EqualError(t, err, "write failed")
+	mockTeamService.AssertNotCalled(t, "AddUserToTeam", mock.Anything, mock.Anything)
+}
+
+func TestAcceptTeamRequest_RestoresPendingWhenJoinFails(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	mockTeamService := &tests.MockTeamService{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, mockTeamRepo, mockTeamService)
+
+	req := pendingTeamRequest()
+	mockTRRepo.On("GetById", tests.TestTeamRequestID).Return(req, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockTRRepo.On("UpdateIfStatus", req, entity.TeamRequestPending).Return(true, nil).Once()
+	mockTRRepo.On("UpdateIfStatus", req, entity.TeamRequestAccepted).Return(true, nil).Once()
+	mockTeamService.On("AddUserToTeam", tests.TestUserID1, tests.TestTeamID).Return(nil, nil, errors.New("write failed"))
+
+	_, _, err := trs.AcceptTeamRequest(tests.TestTeamRequestID, tests.TestUserID)
+
+	assert.EqualError(t, err, "write failed")
+	assert.Equal(t, entity.TeamRequestPending, req.Status)
+	assert.Empty(t, req.ReviewerID)
+	mockTRRepo.AssertExpectations(t)
+}
+
+func TestAcceptTeamRequest_StaysAcceptedWhenUserAlreadyJoined(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	mockTeamService := &tests.MockTeamService{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, mockTeamRepo, mockTeamService)
+
+	req := pendingTeamRequest()
+	mockTRRepo.On("GetById", tests.TestTeamRequestID).Return(req, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockTRRepo.On("UpdateIfStatus", req, entity.TeamRequestPending).Return(true, nil)
+	mockTeamService.On("AddUserToTeam", tests.TestUserID1, tests.TestTeamID).Return(nil, nil, service.ErrAlreadyTeamMember)
+
+	_, _, err := trs.AcceptTeamRequest(tests.TestTeamRequestID, tests.TestUserID)
+
+	assert.ErrorIs(t, err, service.ErrAlreadyTeamMember)
+	assert.Equal(t, entity.TeamRequestAccepted, req.Status)
+	mockTRRepo.AssertNotCalled(t, "UpdateIfStatus", req, entity.TeamRequestAccepted)
+}
+
+func TestAcceptTeamRequest_AnsweredConcurrently(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	mockTeamService := &tests.MockTeamService{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, mockTeamRepo, mockTeamService)
+
+	mockTRRepo.On("GetById", tests.TestTeamRequestID).Return(pendingTeamRequest(), nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockTRRepo.On("UpdateIfStatus", mock.AnythingOfType("*entity.TeamRequest"), entity.TeamRequestPending).Return(false, nil)
+
+	_, _, err := trs.AcceptTeamRequest(tests.TestTeamRequestID, tests.TestUserID)
+
+	assert.EqualError(t, err, "team request was already answered")
+	mockTeamService.AssertNotCalled(t, "AddUserToTeam", mock.Anything, mock.Anything)
+}
+
+func TestAcceptTeamRequest_Expired(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	mockTeamService := &tests.MockTeamService{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, mockTeamRepo, mockTeamService)
+
+	req := entity.NewTeamRequest(tests.TestTeamRequestID, tests.TestUserID1, tests.TestTeamID, "", time.Now().Add(-time.Minute))
+	mockTRRepo.On("GetById", tests.TestTeamRequestID).Return(req, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockTRRepo.On("UpdateIfStatus", mock.MatchedBy(func(r *entity.TeamRequest) bool {
+		return r.Status == entity.TeamRequestExpired
+	}), entity.TeamRequestPending).Return(true, nil)
+
+	_, _, err := trs.AcceptTeamRequest(tests.TestTeamRequestID, tests.TestUserID)
+
+	assert.EqualError(t, err, "team request is already expired")
+	mockTRRepo.AssertExpectations(t)
+	mockTeamService.AssertNotCalled(t, "AddUserToTeam", mock.Anything, mock.Anything)
+}
+
+func TestRejectTeamRequest_AlreadyAnswered(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, mockTeamRepo, nil)
+
+	req := pendingTeamRequest()
+	req.Resolve(entity.TeamRequestAccepted, tests.TestUserID, time.Now())
+	mockTRRepo.On("GetById", tests.TestTeamRequestID).Return(req, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+
+	err := trs.RejectTeamRequest(tests.TestTeamRequestID, tests.TestUserID)
+
+	assert.EqualError(t, err, "team request is already accepted")
+	mockTRRepo.AssertNotCalled(t, "UpdateIfStatus", mock.Anything, mock.Anything)
+}
+
+func TestWithdrawTeamRequest_Success(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, nil, nil)
+
+	mockTRRepo.On("GetById", tests.TestTeamRequestID).Return(pendingTeamRequest(), nil)
+	mockTRRepo.On("UpdateIfStatus", mock.AnythingOfType("*entity.TeamRequest"), entity.TeamRequestPending).Return(true, nil)
+
+	req, err := trs.WithdrawTeamRequest(tests.TestTeamRequestID, tests.TestUserID1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.TeamRequestWithdrawn, req.Status)
+	assert.Equal(t, tests.TestUserID1, req.ReviewerID)
+}
+
+func TestWithdrawTeamRequest_NotRequester(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, nil, nil)
+
+	mockTRRepo.On("GetById", tests.TestTeamRequestID).Return(pendingTeamRequest(), nil)
+
+	_, err := trs.WithdrawTeamRequest(tests.TestTeamRequestID, tests.TestUserID2)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	mockTRRepo.AssertNotCalled(t, "UpdateIfStatus", mock.Anything, mock.Anything)
+}
+
+func TestGetTeamRequests_FiltersByStatus(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, mockTeamRepo, nil)
+
+	pending := entity.NewTeamRequest("pending", tests.TestUserID1, tests.TestTeamID, "", time.Now().Add(time.Hour))
+	stale := entity.NewTeamRequest("stale", tests.TestUserID2, tests.TestTeamID, "", time.Now().Add(-time.Hour))
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockTRRepo.On("GetByTeamId", tests.TestTeamID).Return([]*entity.TeamRequest{pending, stale}, nil)
+	mockTRRepo.On("UpdateIfStatus", stale, entity.TeamRequestPending).Return(true, nil)
+
+	requests, err := trs.GetTeamRequests(tests.TestUserID, tests.TestTeamID, entity.TeamRequestPending)
+
+	assert.NoError(t, err)
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "pending", requests[0].Id)
+	assert.Equal(t, entity.TeamRequestExpired, stale.Status)
+}
+
+func TestGetTeamRequests_InvalidStatus(t *testing.T) {
+	trs := service.NewTeamRequestServiceWithRepo(nil, nil, nil, nil, nil)
+
+	_, err := trs.GetTeamRequests(tests.TestUserID, tests.TestTeamID, "done")
+
+	assert.ErrorContains(t, err, "validation failed")
+}
+
+func TestGetTeamRequests_MemberForbidden(t *testing.T) {
+	mockTeamRepo := &tests.MockTeamRepository{}
+	trs := service.NewTeamRequestServiceWithRepo(nil, nil, nil, mockTeamRepo, nil)
+
+	team := &entity.Team{
+		Id:       tests.TestTeamID,
+		UsersIds: []string{tests.TestUserID, tests.TestUserID2},
+		Roles:    map[string]entity.TeamRole{tests.TestUserID: entity.TeamRoleOwner, tests.TestUserID2: entity.TeamRoleMember},
+	}
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(team, nil)
+
+	_, err := trs.GetTeamRequests(tests.TestUserID2, tests.TestTeamID, "")
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+}
+
+func TestCreateTeamRequest_NotifiesReviewers(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockUserRepo := &tests.MockUserRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	mockNotifier := &tests.MockNotifier{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, mockUserRepo, mockTeamRepo, nil)
+	trs.SetNotifier(mockNotifier)
+
+	mockUserRepo.On("GetByID", "newcomer").Return(&entity.User{ID: "newcomer", Username: "newbie"}, nil)
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(newRolesTeam(), nil)
+	mockTRRepo.On("GetByUserId", "newcomer").Return([]*entity.TeamRequest{}, nil)
+	mockTRRepo.On("Create", mock.AnythingOfType("*entity.TeamRequest")).Return(nil)
+	mockNotifier.On("Notify", mock.MatchedBy(func(n entity.Notification) bool {
+		return n.Type == entity.NotificationTeamRequest && n.ActorID == "newcomer" && n.TeamID == tests.TestTeamID
+	}), []string{testOwnerID, testAdminID}).Return()
+
+	_, err := trs.CreateTeamRequest(&dto.TeamRequestCreateDTO{UserID: "newcomer", TeamID: tests.TestTeamID})
+
+	assert.NoError(t, err)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestAcceptInvitation_NotifiesInviter(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockTeamService := &tests.MockTeamService{}
+	mockNotifier := &tests.MockNotifier{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, nil, nil, mockTeamService)
+	trs.SetNotifier(mockNotifier)
+
+	mockTRRepo.On("GetById", "invite1").Return(entity.NewTeamInvite("invite1", tests.TestUserID2, tests.TestTeamID, tests.TestUserID, time.Now().Add(time.Hour)), nil)
+	mockTeamService.On("AddUserToTeam", tests.TestUserID2, tests.TestTeamID).Return(&entity.User{ID: tests.TestUserID2, Username: "bob"}, &tests.ValidTeam, nil)
+	mockTRRepo.On("UpdateIfStatus", mock.AnythingOfType("*entity.TeamRequest"), entity.TeamRequestPending).Return(true, nil)
+	mockNotifier.On("Notify", mock.MatchedBy(func(n entity.Notification) bool {
+		return n.Type == entity.NotificationTeamRequestAccepted && n.ActorID == tests.TestUserID2 && n.ResourceID == "invite1"
+	}), []string{tests.TestUserID}).Return()
+
+	_, _, err := trs.AcceptInvitation("invite1", tests.TestUserID2)
+
+	assert.NoError(t, err)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestInviteToTeam_NotifiesInvitee(t *testing.T) {
+	mockTRRepo := &tests.MockTeamRequestRepository{}
+	mockUserRepo := &tests.MockUserRepository{}
+	mockTeamRepo := &tests.MockTeamRepository{}
+	mockNotifier := &tests.MockNotifier{}
+	trs := service.NewTeamRequestServiceWithRepo(mockTRRepo, nil, mockUserRepo, mockTeamRepo, nil)
+	trs.SetNotifier(mockNotifier)
+
+	mockTeamRepo.On("GetTeamById", tests.TestTeamID).Return(&tests.ValidTeam, nil)
+	mockUserRepo.On("GetByID", tests.TestUserID2).Return(&entity.User{ID: tests.TestUserID2}, nil)
+	mockTRRepo.On("GetByUserId", tests.TestUserID2).Return([]*entity.TeamRequest{}, nil)
+	mockTRRepo.On("Create", mock.AnythingOfType("*entity.TeamRequest")).Return(nil)
+	mockNotifier.On("Notify", mock.MatchedBy(func(n entity.Notification) bool {
+		return n.Type == entity.NotificationTeamInvite && n.ActorID == tests.TestUserID && n.TeamID == tests.TestTeamID
+	}), []string{tests.TestUserID2}).Return()
+
+	_, err := trs.InviteToTeam(tests.TestUserID, tests.TestTeamID, &dto.TeamInviteRequest{UserID: tests.TestUserID2})
+
+	assert.NoError(t, err)
+	mockNotifier.AssertExpectations(t)
+}
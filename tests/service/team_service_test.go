@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
@@ -25,8 +26,9 @@ func TestCreateTeam_Success(t *testing.T) {
 	}
 
 	testUser := &entity.User{
-		ID:       tests.TestUserID,
-		TeamsIds: &[]string{},
+		ID:            tests.TestUserID,
+		TeamsIds:      &[]string{},
+		EmailVerified: true,
 	}
 
 	emptyTeam := &entity.Team{
@@ -61,6 +63,27 @@ func TestCreateTeam_Success(t *testing.T) {
 	assert.Equal(t, tests.TestTeamName, team.Name)
 }
 
+func TestCreateTeam_EmailNotVerified(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	mockUserRepo := &tests.MockUserRepository{}
+	ts := service.NewTeamServiceWithRepo(mockUserRepo, mockRepo)
+
+	req := &dto.TeamRequest{
+		Name:        tests.TestTeamName,
+		Description: tests.TestTeamDescription,
+		IsPublic:    tests.TestTeamIsPublic,
+		UserId:      tests.TestUserID,
+		TeamTopic:   tests.TestTeamTopic,
+	}
+
+	mockUserRepo.On("GetByID", tests.TestUserID).Return(&entity.User{ID: tests.TestUserID}, nil)
+
+	team, err := ts.CreateTeam(req)
+	assert.ErrorIs(t, err, service.ErrEmailNotVerified)
+	assert.Nil(t, team)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
 func TestAddUserToTeam_Success(t *testing.T) {
 	mockRepo := &tests.MockTeamRepository{}
 	mockUserRepo := &tests.MockUserRepository{}
@@ -96,6 +119,111 @@ func TestAddUserToTeam_AlreadyMember(t *testing.T) {
 	assert.Equal(t, "user is already part of the team", err.Error())
 }
 
+func TestAddUserToTeam_CommitsUserAndTeamTogether(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	mockUserRepo := &tests.MockUserRepository{}
+	uow := &tests.MockUnitOfWork{}
+	ts := service.NewTeamServiceWithRepo(mockUserRepo, mockRepo)
+	ts.SetUnitOfWork(uow.Factory())
+
+	mockUser := &entity.User{ID: tests.TestUserID}
+	mockTeam := &entity.Team{Id: tests.TestTeamID, UsersIds: []string{}}
+	mockUserRepo.On("GetByID", tests.TestUserID).Return(mockUser, nil)
+	uow.On("UpdateTeam", tests.TestTeamID).Return(mockTeam)
+	uow.On("UpdateUser", tests.TestUserID).Return(mockUser)
+	uow.On("Commit").Return(errors.New("write failed"))
+
+	_, _, err := ts.AddUserToTeam(tests.TestUserID, tests.TestTeamID)
+
+	assert.EqualError(t, err, "write failed")
+	uow.AssertExpectations(t)
+	mockUserRepo.AssertNotCalled(t, "Update", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestAddUserToTeam_KeepsMembersWhoJoinedSinceTheRead(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	mockUserRepo := &tests.MockUserRepository{}
+	uow := &tests.MockUnitOfWork{}
+	ts := service.NewTeamServiceWithRepo(mockUserRepo, mockRepo)
+	ts.SetUnitOfWork(uow.Factory())
+
+	// the stored documents already hold a join committed by another request
+	storedTeam := &entity.Team{Id: tests.TestTeamID, UsersIds: []string{"other"}}
+	storedUser := &entity.User{ID: tests.TestUserID, TeamsIds: &[]string{"joined"}}
+	mockUserRepo.On("GetByID", tests.TestUserID).Return(&entity.User{ID: tests.TestUserID}, nil)
+	uow.On("UpdateTeam", tests.TestTeamID).Return(storedTeam)
+	uow.On("UpdateUser", tests.TestUserID).Return(storedUser)
+	uow.On("Commit").Return(nil)
+
+	user, team, err := ts.AddUserToTeam(tests.TestUserID, tests.TestTeamID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"other", tests.TestUserID}, team.UsersIds)
+	assert.Equal(t, []string{"joined", tests.TestTeamID}, *user.TeamsIds)
+}
+
+func TestAddUserToTeam_AlreadyMemberWhenCommitted(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	mockUserRepo := &tests.MockUserRepository{}
+	uow := &tests.MockUnitOfWork{}
+	ts := service.NewTeamServiceWithRepo(mockUserRepo, mockRepo)
+	ts.SetUnitOfWork(uow.Factory())
+
+	mockUserRepo.On("GetByID", tests.TestUserID).Return(&entity.User{ID: tests.TestUserID}, nil)
+	uow.On("UpdateTeam", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{tests.TestUserID}})
+	uow.On("UpdateUser", tests.TestUserID).Return(&entity.User{ID: tests.TestUserID})
+	uow.On("Commit").Return(nil)
+
+	_, _, err := ts.AddUserToTeam(tests.TestUserID, tests.TestTeamID)
+
+	assert.EqualError(t, err, "user is already part of the team")
+}
+
+func TestDeleteTeam_RemovesTeamFromMembersInOneCommit(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	mockUserRepo := &tests.MockUserRepository{}
+	uow := &tests.MockUnitOfWork{}
+	ts := service.NewTeamServiceWithRepo(mockUserRepo, mockRepo)
+	ts.SetUnitOfWork(uow.Factory())
+
+	u1 := &entity.User{ID: "u1", TeamsIds: &[]string{tests.TestTeamID, "other"}}
+	u2 := &entity.User{ID: "u2", TeamsIds: &[]string{tests.TestTeamID}}
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{"u1", "u2"}}, nil)
+	uow.On("UpdateTeam", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{"u1", "u2"}})
+	uow.On("UpdateUser", "u1").Return(u1)
+	uow.On("UpdateUser", "u2").Return(u2)
+	uow.On("DeleteTeam", tests.TestTeamID).Return()
+	uow.On("Commit").Return(nil).Once()
+
+	assert.NoError(t, ts.Delete(tests.TestTeamID))
+	uow.AssertExpectations(t)
+	assert.Equal(t, []string{"other"}, *u1.TeamsIds)
+	assert.Empty(t, *u2.TeamsIds)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestDeleteTeam_StartsOverWhenAMemberJoinsMeanwhile(t *testing.T) {
+	mockRepo := &tests.MockTeamRepository{}
+	mockUserRepo := &tests.MockUserRepository{}
+	uow := &tests.MockUnitOfWork{}
+	ts := service.NewTeamServiceWithRepo(mockUserRepo, mockRepo)
+	ts.SetUnitOfWork(uow.Factory())
+
+	joined := &entity.User{ID: "u2", TeamsIds: &[]string{tests.TestTeamID}}
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{"u1"}}, nil).Once()
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{"u1", "u2"}}, nil).Once()
+	uow.On("UpdateTeam", tests.TestTeamID).Return(&entity.Team{Id: tests.TestTeamID, UsersIds: []string{"u1", "u2"}})
+	uow.On("UpdateUser", "u1").Return(&entity.User{ID: "u1", TeamsIds: &[]string{tests.TestTeamID}})
+	uow.On("UpdateUser", "u2").Return(joined)
+	uow.On("DeleteTeam", tests.TestTeamID).Return()
+	uow.On("Commit").Return(nil)
+
+	assert.NoError(t, ts.Delete(tests.TestTeamID))
+	assert.Empty(t, *joined.TeamsIds)
+	uow.AssertNumberOfCalls(t, "Commit", 2)
+}
+
 func TestGetUsersByTeam_Success(t *testing.T) {
 	mockRepo := &tests.MockTeamRepository{}
 	mockUserRepo := &tests.MockUserRepository{}
@@ -126,7 +254,7 @@ func TestGetUsersByTeam_Success(t *testing.T) {
 	mockUserRepo.On("GetByID", "user1").Return(user1, nil)
 	mockUserRepo.On("GetByID", "user2").Return(user2, nil)
 
-	users, err := ts.GetUsersByTeam(tests.TestTeamID)
+	users, err := ts.GetUsersByTeam("user1", tests.TestTeamID)
 	assert.NoError(t, err)
 	assert.Len(t, users, 2)
 	assert.Equal(t, "johndoe", users[0].Username)
@@ -138,12 +266,12 @@ func TestGetUsersByTeam_EmptyTeamID(t *testing.T) {
 	mockUserRepo := &tests.MockUserRepository{}
 	ts := service.NewTeamServiceWithRepo(mockUserRepo, mockRepo)
 
-	users, err := ts.GetUsersByTeam("")
+	users, err := ts.GetUsersByTeam("user1", "")
 	assert.Error(t, err)
 	assert.Nil(t, users)
 	assert.Equal(t, "team ID is required", err.Error())
 
-	users, err = ts.GetUsersByTeam("   ")
+	users, err = ts.GetUsersByTeam("user1", "   ")
 	assert.Error(t, err)
 	assert.Nil(t, users)
 	assert.Equal(t, "team ID is required", err.Error())
@@ -156,29 +284,31 @@ func TestGetUsersByTeam_TeamNotFound(t *testing.T) {
 
 	mockRepo.On("GetTeamById", "non-existent-team").Return(nil, assert.AnError)
 
-	users, err := ts.GetUsersByTeam("non-existent-team")
+	users, err := ts.GetUsersByTeam("user1", "non-existent-team")
 	assert.Error(t, err)
 	assert.Nil(t, users)
 	assert.Equal(t, "team not found", err.Error())
 }
 
-func TestGetUsersByTeam_EmptyTeam(t *testing.T) {
+func TestGetUsersByTeam_NotMember(t *testing.T) {
 	mockRepo := &tests.MockTeamRepository{}
 	mockUserRepo := &tests.MockUserRepository{}
 	ts := service.NewTeamServiceWithRepo(mockUserRepo, mockRepo)
 
-	// Create test team with no users
-	testTeam := &entity.Team{
-		Id:       tests.TestTeamID,
-		Name:     tests.TestTeamName,
-		UsersIds: []string{},
-	}
+	publicTeam := &entity.Team{Id: tests.TestTeamID, IsPublic: true, UsersIds: []string{"user1"}}
+	privateTeam := &entity.Team{Id: tests.TestTeamID2, UsersIds: []string{"user1"}}
+	mockRepo.On("GetTeamById", tests.TestTeamID).Return(publicTeam, nil)
+	mockRepo.On("GetTeamById", tests.TestTeamID2).Return(privateTeam, nil)
 
-	mockRepo.On("GetTeamById", tests.TestTeamID).Return(testTeam, nil)
+	users, err := ts.GetUsersByTeam("user2", tests.TestTeamID)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	assert.Nil(t, users)
 
-	users, err := ts.GetUsersByTeam(tests.TestTeamID)
-	assert.NoError(t, err)
-	assert.Len(t, users, 0)
+	// a private team is not found at all by non-members
+	users, err = ts.GetUsersByTeam("user2", tests.TestTeamID2)
+	assert.ErrorIs(t, err, service.ErrResourceNotFound)
+	assert.Nil(t, users)
+	mockUserRepo.AssertNotCalled(t, "GetByID", mock.Anything)
 }
 
 func TestGetUsersByTeam_WithInvalidUser(t *testing.T) {
@@ -204,7 +334,7 @@ func TestGetUsersByTeam_WithInvalidUser(t *testing.T) {
 	mockUserRepo.On("GetByID", "user1").Return(validUser, nil)
 	mockUserRepo.On("GetByID", "invalid-user").Return(nil, assert.AnError)
 
-	users, err := ts.GetUsersByTeam(tests.TestTeamID)
+	users, err := ts.GetUsersByTeam("user1", tests.TestTeamID)
 	assert.NoError(t, err)  // Should not error, but skip invalid user
 	assert.Len(t, users, 1) // Only valid user should be returned
 	assert.Equal(t, "johndoe", users[0].Username)
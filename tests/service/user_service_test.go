@@ -12,6 +12,7 @@ import (
 	. "github.com/SerbanEduard/ProiectColectivBackEnd/tests"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
@@ -27,7 +28,7 @@ const (
 func TestUserService_SignUp_Success(t *testing.T) {
 	mockRepo := new(tests.MockUserRepository)
 	mockTeamRepo := new(tests.MockTeamRepository)
-	userService := service.NewUserServiceWithRepo(mockRepo, mockTeamRepo)
+	userService := service.NewUserServiceWithRepo(mockRepo, mockTeamRepo, new(tests.MockSessionRepository))
 
 	request := &tests.ValidSignUpRequest
 
@@ -57,7 +58,7 @@ func TestUserService_SignUp_Success(t *testing.T) {
 func TestUserService_SignUp_UsernameExists(t *testing.T) {
 	mockRepo := new(tests.MockUserRepository)
 	mockTeamRepo := new(tests.MockTeamRepository)
-	userService := service.NewUserServiceWithRepo(mockRepo, mockTeamRepo)
+	userService := service.NewUserServiceWithRepo(mockRepo, mockTeamRepo, new(tests.MockSessionRepository))
 
 	request := &tests.ExistingUsernameRequest
 
@@ -75,7 +76,7 @@ func TestUserService_SignUp_UsernameExists(t *testing.T) {
 func TestUserService_SignUp_EmailExists(t *testing.T) {
 	mockRepo := new(tests.MockUserRepository)
 	mockTeamRepo := new(tests.MockTeamRepository)
-	userService := service.NewUserServiceWithRepo(mockRepo, mockTeamRepo)
+	userService := service.NewUserServiceWithRepo(mockRepo, mockTeamRepo, new(tests.MockSessionRepository))
 
 	request := &tests.ExistingEmailRequest
 
@@ -93,7 +94,7 @@ func TestUserService_SignUp_EmailExists(t *testing.T) {
 func TestUserService_GetUserStatistics_Success(t *testing.T) {
 	mockRepo := new(tests.MockUserRepository)
 	mockTeamRepo := new(tests.MockTeamRepository)
-	userService := service.NewUserServiceWithRepo(mockRepo, mockTeamRepo)
+	userService := service.NewUserServiceWithRepo(mockRepo, mockTeamRepo, new(tests.MockSessionRepository))
 
 	user := &entity.User{
 		ID: TestUserID,
@@ -120,7 +121,7 @@ func TestUserService_GetUserStatistics_Success(t *testing.T) {
 func TestUserService_UpdateUserStatistics_Success(t *testing.T) {
 	mockRepo := new(tests.MockUserRepository)
 	mockTeamRepo := new(tests.MockTeamRepository)
-	userService := service.NewUserServiceWithRepo(mockRepo, mockTeamRepo)
+	userService := service.NewUserServiceWithRepo(mockRepo, mockTeamRepo, new(tests.MockSessionRepository))
 
 	user := &entity.User{
 		ID: TestUserID,
@@ -154,7 +155,7 @@ func TestUserService_UpdateUserStatistics_Success(t *testing.T) {
 func TestUserService_UpdateUserStatistics_NewTeam(t *testing.T) {
 	mockRepo := new(tests.MockUserRepository)
 	mockTeamRepo := new(tests.MockTeamRepository)
-	userService := service.NewUserServiceWithRepo(mockRepo, mockTeamRepo)
+	userService := service.NewUserServiceWithRepo(mockRepo, mockTeamRepo, new(tests.MockSessionRepository))
 
 	user := &entity.User{
 		ID:         TestUserID,
@@ -180,3 +181,102 @@ func TestUserService_UpdateUserStatistics_NewTeam(t *testing.T) {
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestUserService_DeleteUser_LeavesTeamsInSameCommit(t *testing.T) {
+	mockUserRepo := &MockUserRepository{}
+	mockTeamRepo := &MockTeamRepository{}
+	uow := &MockUnitOfWork{}
+	userService := service.NewUserServiceWithRepo(mockUserRepo, mockTeamRepo, new(tests.MockSessionRepository))
+	userService.SetUnitOfWork(uow.Factory())
+	mockSessionService := &MockSessionService{}
+	userService.SetSessionService(mockSessionService)
+
+	team := &entity.Team{
+		Id:       TestTeamID,
+		UsersIds: []string{TestUserID, "other"},
+		Roles:    map[string]entity.TeamRole{TestUserID: entity.TeamRoleAdmin, "other": entity.TeamRoleOwner},
+	}
+	user := &entity.User{ID: TestUserID, TeamsIds: &[]string{TestTeamID}}
+	mockUserRepo.On("GetByID", TestUserID).Return(user, nil)
+	uow.On("UpdateTeam", TestTeamID).Return(team)
+	uow.On("UpdateUser", TestUserID).Return(user)
+	uow.On("DeleteUser", TestUserID).Return()
+	uow.On("Commit").Return(nil)
+	mockSessionService.On("LogoutAll", TestUserID).Return(nil)
+
+	err := userService.DeleteUser(TestUserID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"other"}, team.UsersIds)
+	assert.NotContains(t, team.Roles, TestUserID)
+	uow.AssertExpectations(t)
+	mockUserRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	mockSessionService.AssertExpectations(t)
+}
+
+func TestUserService_DeleteUser_RefusesTeamOwner(t *testing.T) {
+	mockUserRepo := &MockUserRepository{}
+	uow := &MockUnitOfWork{}
+	userService := service.NewUserServiceWithRepo(mockUserRepo, &MockTeamRepository{}, new(tests.MockSessionRepository))
+	userService.SetUnitOfWork(uow.Factory())
+	mockSessionService := &MockSessionService{}
+	userService.SetSessionService(mockSessionService)
+
+	team := &entity.Team{
+		Id:       TestTeamID,
+		UsersIds: []string{TestUserID, "other"},
+		Roles:    map[string]entity.TeamRole{TestUserID: entity.TeamRoleOwner, "other": entity.TeamRoleMember},
+	}
+	user := &entity.User{ID: TestUserID, TeamsIds: &[]string{TestTeamID}}
+	mockUserRepo.On("GetByID", TestUserID).Return(user, nil)
+	uow.On("UpdateTeam", TestTeamID).Return(team)
+	uow.On("UpdateUser", TestUserID).Return(user)
+	uow.On("DeleteUser", TestUserID).Return()
+	uow.On("Commit").Return(nil)
+
+	err := userService.DeleteUser(TestUserID)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	assert.Equal(t, []string{TestUserID, "other"}, team.UsersIds)
+	mockSessionService.AssertNotCalled(t, "LogoutAll", mock.Anything)
+}
+
+func TestUserService_UpdateUserPassword_LogsOutOtherSessions(t *testing.T) {
+	mockRepo := new(tests.MockUserRepository)
+	mockSessionService := new(tests.MockSessionService)
+	userService := service.NewUserServiceWithRepo(mockRepo, new(tests.MockTeamRepository), new(tests.MockSessionRepository))
+	userService.SetSessionService(mockSessionService)
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("oldpassword"), bcrypt.MinCost)
+	user := &entity.User{ID: TestUserID, Password: string(hashed)}
+	mockRepo.On("GetByID", TestUserID).Return(user, nil)
+	mockRepo.On("Update", user).Return(nil)
+	mockSessionService.On("LogoutOthers", TestUserID, "current").Return(nil)
+
+	err := userService.UpdateUserPassword(TestUserID, "current", &dto.UserPasswordRequestDTO{ID: TestUserID, OldPassword: "oldpassword", NewPassword: "newpassword"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("newpassword")))
+	mockSessionService.AssertExpectations(t)
+}
+
+func TestUserService_UpdateUserProfile_EmailChangeInvalidatesTokens(t *testing.T) {
+	mockRepo := new(tests.MockUserRepository)
+	mockTeamRepo := new(tests.MockTeamRepository)
+	mockVerification := new(tests.MockVerificationService)
+	userService := service.NewUserServiceWithRepo(mockRepo, mockTeamRepo, new(tests.MockSessionRepository))
+	userService.SetVerificationService(mockVerification)
+
+	user := &entity.User{ID: tests.TestUserID, Email: tests.TestEmail, EmailVerified: true}
+	mockRepo.On("GetByID", tests.TestUserID).Return(user, nil)
+	mockRepo.On("Update", user).Return(nil)
+	mockVerification.On("InvalidateTokens", tests.TestUserID).Return(nil).Once()
+	mockVerification.On("SendEmailVerification", user).Return(nil).Once()
+
+	_, err := userService.UpdateUserProfile(tests.TestUserID, &dto.UserUpdateRequestDTO{Email: "new@example.com"})
+
+	assert.NoError(t, err)
+	assert.False(t, user.EmailVerified)
+	assert.True(t, user.EmailVerificationRequired)
+	mockVerification.AssertExpectations(t)
+}
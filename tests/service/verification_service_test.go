@@ -0,0 +1,200 @@
+package service_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/service"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var mailedTokenRegex = regexp.MustCompile(`token=([0-9a-f]+)`)
+
+type verificationMocks struct {
+	tokenRepo      *tests.MockUserTokenRepository
+	userRepo       *tests.MockUserRepository
+	sessionService *tests.MockSessionService
+	mailer         *tests.MockMailer
+}
+
+func newVerificationService() (*service.VerificationService, *verificationMocks) {
+	m := &verificationMocks{
+		tokenRepo:      new(tests.MockUserTokenRepository),
+		userRepo:       new(tests.MockUserRepository),
+		sessionService: new(tests.MockSessionService),
+		mailer:         new(tests.MockMailer),
+	}
+	return service.NewVerificationServiceWithRepo(m.tokenRepo, m.userRepo, m.sessionService, m.mailer), m
+}
+
+// captureMailedToken records the stored token and the raw token from the mail body
+func captureMailedToken(m *verificationMocks, stored **entity.UserToken, raw *string) {
+	m.tokenRepo.On("Create", mock.AnythingOfType("*entity.UserToken")).Run(func(args mock.Arguments) {
+		*stored = args.Get(0).(*entity.UserToken)
+	}).Return(nil).Once()
+	m.mailer.On("Send", tests.TestEmail, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if match := mailedTokenRegex.FindStringSubmatch(args.String(2)); match != nil {
+			*raw = match[1]
+		}
+	}).Return(nil).Once()
+}
+
+func TestVerificationService_VerifyEmail_Success(t *testing.T) {
+	vs, m := newVerificationService()
+	user := &entity.User{ID: tests.TestUserID, Email: tests.TestEmail}
+
+	var stored *entity.UserToken
+	var raw string
+	captureMailedToken(m, &stored, &raw)
+
+	assert.NoError(t, vs.SendEmailVerification(user))
+	assert.NotEmpty(t, raw)
+	assert.NotEqual(t, raw, stored.ID)
+	assert.Equal(t, entity.PurposeEmailVerification, stored.Purpose)
+	assert.Equal(t, tests.TestEmail, stored.Email)
+
+	m.tokenRepo.On("GetByID", stored.ID).Return(stored, nil)
+	m.tokenRepo.On("MarkUsed", stored.ID).Return(true, nil)
+	m.userRepo.On("GetByID", tests.TestUserID).Return(user, nil)
+	m.userRepo.On("Update", user).Return(nil)
+
+	assert.NoError(t, vs.VerifyEmail(raw))
+	assert.True(t, user.EmailVerified)
+	assert.True(t, stored.Used)
+
+	// tokens are single-use
+	assert.ErrorIs(t, vs.VerifyEmail(raw), service.ErrInvalidUserToken)
+}
+
+func TestVerificationService_VerifyEmail_Expired(t *testing.T) {
+	vs, m := newVerificationService()
+
+	expired := entity.NewUserToken("hash", tests.TestUserID, tests.TestEmail, entity.PurposeEmailVerification, time.Now().Add(-time.Minute))
+	m.tokenRepo.On("GetByID", mock.Anything).Return(expired, nil)
+
+	err := vs.VerifyEmail("sometoken")
+
+	assert.ErrorIs(t, err, service.ErrInvalidUserToken)
+	m.userRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestVerificationService_VerifyEmail_UsedConcurrently(t *testing.T) {
+	vs, m := newVerificationService()
+
+	token := entity.NewUserToken("hash", tests.TestUserID, tests.TestEmail, entity.PurposeEmailVerification, time.Now().Add(time.Hour))
+	m.tokenRepo.On("GetByID", mock.Anything).Return(token, nil)
+	// another request marked it used after it was read
+	m.tokenRepo.On("MarkUsed", token.ID).Return(false, nil)
+
+	err := vs.VerifyEmail("sometoken")
+
+	assert.ErrorIs(t, err, service.ErrInvalidUserToken)
+	m.userRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestVerificationService_VerifyEmail_WrongPurpose(t *testing.T) {
+	vs, m := newVerificationService()
+
+	reset := entity.NewUserToken("hash", tests.TestUserID, tests.TestEmail, entity.PurposePasswordReset, time.Now().Add(time.Hour))
+	m.tokenRepo.On("GetByID", mock.Anything).Return(reset, nil)
+
+	err := vs.VerifyEmail("sometoken")
+
+	assert.ErrorIs(t, err, service.ErrInvalidUserToken)
+	assert.False(t, reset.Used)
+}
+
+func TestVerificationService_SendEmailVerification_AlreadyVerified(t *testing.T) {
+	vs, m := newVerificationService()
+
+	err := vs.SendEmailVerification(&entity.User{ID: tests.TestUserID, EmailVerified: true})
+
+	assert.ErrorIs(t, err, service.ErrEmailAlreadyVerified)
+	m.mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVerificationService_RequestPasswordReset_UnknownEmail(t *testing.T) {
+	vs, m := newVerificationService()
+
+	m.userRepo.On("GetByEmail", tests.TestEmail).Return(nil, errors.New(tests.ErrUserNotFound))
+
+	err := vs.RequestPasswordReset(tests.TestEmail)
+
+	assert.NoError(t, err)
+	m.tokenRepo.AssertNotCalled(t, "Create", mock.Anything)
+	m.mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVerificationService_ResetPassword_Success(t *testing.T) {
+	vs, m := newVerificationService()
+	user := &entity.User{ID: tests.TestUserID, Email: tests.TestEmail, Password: "old-hash"}
+
+	var stored *entity.UserToken
+	var raw string
+	captureMailedToken(m, &stored, &raw)
+	m.userRepo.On("GetByEmail", tests.TestEmail).Return(user, nil)
+
+	assert.NoError(t, vs.RequestPasswordReset(tests.TestEmail))
+	assert.Equal(t, entity.PurposePasswordReset, stored.Purpose)
+
+	m.tokenRepo.On("GetByID", stored.ID).Return(stored, nil)
+	m.tokenRepo.On("MarkUsed", stored.ID).Return(true, nil)
+	m.userRepo.On("GetByID", tests.TestUserID).Return(user, nil)
+	m.userRepo.On("Update", user).Return(nil)
+	m.sessionService.On("LogoutAll", tests.TestUserID).Return(nil)
+
+	err := vs.ResetPassword(&dto.ResetPasswordRequest{Token: raw, NewPassword: "newpassword"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("newpassword")))
+	assert.True(t, user.EmailVerified)
+	m.sessionService.AssertExpectations(t)
+}
+
+func TestVerificationService_ResetPassword_ShortPassword(t *testing.T) {
+	vs, m := newVerificationService()
+
+	err := vs.ResetPassword(&dto.ResetPasswordRequest{Token: "sometoken", NewPassword: "123"})
+
+	assert.Error(t, err)
+	m.tokenRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestVerificationService_VerifyEmail_EmailChangedSinceMailed(t *testing.T) {
+	vs, m := newVerificationService()
+	user := &entity.User{ID: tests.TestUserID, Email: "new@example.com"}
+
+	token := entity.NewUserToken("hash", tests.TestUserID, tests.TestEmail, entity.PurposeEmailVerification, time.Now().Add(time.Hour))
+	m.tokenRepo.On("GetByID", mock.Anything).Return(token, nil)
+	m.tokenRepo.On("MarkUsed", token.ID).Return(true, nil)
+	m.userRepo.On("GetByID", tests.TestUserID).Return(user, nil)
+
+	err := vs.VerifyEmail("sometoken")
+
+	assert.ErrorIs(t, err, service.ErrInvalidUserToken)
+	assert.False(t, user.EmailVerified)
+	m.userRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestVerificationService_ResetPassword_EmailChangedSinceMailed(t *testing.T) {
+	vs, m := newVerificationService()
+	user := &entity.User{ID: tests.TestUserID, Email: "new@example.com", Password: "old-hash"}
+
+	token := entity.NewUserToken("hash", tests.TestUserID, tests.TestEmail, entity.PurposePasswordReset, time.Now().Add(time.Hour))
+	m.tokenRepo.On("GetByID", mock.Anything).Return(token, nil)
+	m.tokenRepo.On("MarkUsed", token.ID).Return(true, nil)
+	m.userRepo.On("GetByID", tests.TestUserID).Return(user, nil)
+
+	err := vs.ResetPassword(&dto.ResetPasswordRequest{Token: "sometoken", NewPassword: "newpassword"})
+
+	assert.ErrorIs(t, err, service.ErrInvalidUserToken)
+	assert.Equal(t, "old-hash", user.Password)
+	assert.False(t, user.EmailVerified)
+}
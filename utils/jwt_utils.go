@@ -10,20 +10,29 @@ import (
 )
 
 func GetUserIDFromContext(c *gin.Context) (string, error) {
+	claims, err := GetClaimsFromContext(c)
+	if err != nil {
+		return "", err
+	}
+
+	userID, err := claims.GetSubject()
+	if err != nil {
+		return "", err
+	}
+
+	return userID, nil
+}
+
+// GetClaimsFromContext returns the JWT claims stored by the auth middleware
+func GetClaimsFromContext(c *gin.Context) (jwt.MapClaims, error) {
 	if value, exists := c.Get("userClaims"); exists {
 		claims, ok := value.(jwt.MapClaims)
 		if !ok {
-			return "", fmt.Errorf("userClaims is not of type jwt.MapClaims")
+			return nil, fmt.Errorf("userClaims is not of type jwt.MapClaims")
 		}
-
-		userID, err := claims.GetSubject()
-		if err != nil {
-			return "", err
-		}
-
-		return userID, nil
+		return claims, nil
 	}
-	return "", fmt.Errorf("userClaims not found in context")
+	return nil, fmt.Errorf("userClaims not found in context")
 }
 
 // GenerateID generates a random ID for entities
@@ -1,6 +1,10 @@
 package validator
 
 import (
+	"errors"
+	"strings"
+	"unicode"
+
 	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
 )
 
@@ -8,6 +12,7 @@ func ValidateDirectMessageRequest(request *dto.DirectMessageRequest) error {
 	validations := []func() error{
 		func() error { return validateRequired(request.SenderID, "sender id is required") },
 		func() error { return validateRequired(request.ReceiverID, "receiver(user) id is required") },
+		func() error { return validateAttachmentIDs(request.AttachmentIDs) },
 	}
 
 	for _, validate := range validations {
@@ -22,6 +27,7 @@ func ValidateTeamMessageRequest(request *dto.TeamMessageRequest) error {
 	validations := []func() error{
 		func() error { return validateRequired(request.SenderID, "sender id is required") },
 		func() error { return validateRequired(request.TeamId, "team id is required") },
+		func() error { return validateAttachmentIDs(request.AttachmentIDs) },
 	}
 
 	for _, validate := range validations {
@@ -31,3 +37,61 @@ func ValidateTeamMessageRequest(request *dto.TeamMessageRequest) error {
 	}
 	return nil
 }
+
+// MaxMessageAttachments is how many files one message can carry
+const MaxMessageAttachments = 10
+
+func validateAttachmentIDs(ids []string) error {
+	if len(ids) > MaxMessageAttachments {
+		return errors.New("a message can have at most 10 attachments")
+	}
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			return errors.New("attachment ids cannot be empty")
+		}
+		if seen[id] {
+			return errors.New("attachment ids must be unique")
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+func ValidateEditMessageRequest(request *dto.EditMessageRequest) error {
+	return validateRequired(request.TextContent, "text content is required")
+}
+
+func ValidateTypingRequest(request *dto.TypingRequest) error {
+	if (request.ReceiverID == "") == (request.TeamID == "") {
+		return errors.New("exactly one of receiver id and team id is required")
+	}
+	return nil
+}
+
+func ValidateReadMessageRequest(request *dto.ReadMessageRequest) error {
+	return validateRequired(request.MessageID, "message id is required")
+}
+
+// maxEmojiLength bounds an emoji in bytes; sequences joined with zero width joiners run to about 30
+const maxEmojiLength = 64
+
+// ValidateReactionRequest accepts a single emoji-like token. The emoji becomes a key of the stored
+// message, so path separators and the characters Firebase forbids in keys are rejected.
+func ValidateReactionRequest(request *dto.ReactionRequest) error {
+	if err := validateRequired(request.MessageID, "message id is required"); err != nil {
+		return err
+	}
+	if err := validateRequired(request.Emoji, "emoji is required"); err != nil {
+		return err
+	}
+	if len(request.Emoji) > maxEmojiLength {
+		return errors.New("emoji is too long")
+	}
+	if strings.ContainsAny(request.Emoji, ".$#[]/") || strings.IndexFunc(request.Emoji, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}) >= 0 {
+		return errors.New("emoji contains invalid characters")
+	}
+	return nil
+}
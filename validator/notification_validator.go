@@ -0,0 +1,53 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+)
+
+// MaxMutedTeams bounds the teams a user can mute, which is at most the teams they are in
+const MaxMutedTeams = 200
+
+func ValidateNotificationPreferencesRequest(request *dto.NotificationPreferencesRequest) error {
+	for notificationType, channels := range request.Channels {
+		if !notificationType.IsValid() {
+			return fmt.Errorf("unknown notification type %q", notificationType)
+		}
+		for channel := range channels {
+			if !channel.IsValid() {
+				return fmt.Errorf("unknown notification channel %q, use in_app, email or push", channel)
+			}
+		}
+	}
+
+	if len(request.MutedTeamIDs) > MaxMutedTeams {
+		return fmt.Errorf("at most %d teams can be muted", MaxMutedTeams)
+	}
+	for _, teamID := range request.MutedTeamIDs {
+		if strings.TrimSpace(teamID) == "" {
+			return errors.New("mutedTeamIds cannot contain empty IDs")
+		}
+	}
+
+	if request.QuietHours != nil {
+		start, errStart := time.Parse("15:04", request.QuietHours.Start)
+		end, errEnd := time.Parse("15:04", request.QuietHours.End)
+		if errStart != nil || errEnd != nil {
+			return errors.New("quiet hours start and end must be times like 22:00")
+		}
+		if start.Equal(end) {
+			return errors.New("quiet hours cannot start and end at the same time")
+		}
+	}
+	if request.Timezone != "" {
+		// "Local" is the timezone of the server, which users cannot know and which changes with the deployment
+		if _, err := time.LoadLocation(request.Timezone); err != nil || request.Timezone == "Local" {
+			return fmt.Errorf("unknown timezone %q", request.Timezone)
+		}
+	}
+	return nil
+}
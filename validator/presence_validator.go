@@ -0,0 +1,16 @@
+package validator
+
+import (
+	"errors"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/entity"
+)
+
+// ValidateSetPresenceRequest accepts online and idle; going offline is decided by the connections closing
+func ValidateSetPresenceRequest(request *dto.SetPresenceRequest) error {
+	if request.Status != entity.PresenceOnline && request.Status != entity.PresenceIdle {
+		return errors.New("status must be online or idle")
+	}
+	return nil
+}
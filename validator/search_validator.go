@@ -0,0 +1,28 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+	"github.com/SerbanEduard/ProiectColectivBackEnd/search"
+)
+
+// MaxSearchQueryLength bounds the query, which is matched word by word against the whole index
+const MaxSearchQueryLength = 200
+
+func ValidateSearchRequest(request *dto.SearchRequest) error {
+	if len(search.Tokenize(request.Query)) == 0 {
+		return errors.New("query must contain at least one letter or digit")
+	}
+	if len(request.Query) > MaxSearchQueryLength {
+		return fmt.Errorf("query cannot be longer than %d characters", MaxSearchQueryLength)
+	}
+	for _, kind := range request.Types {
+		if !search.Kind(kind).IsValid() {
+			return fmt.Errorf("unknown type %q, expected one of message, file, quiz, event, team", strings.TrimSpace(kind))
+		}
+	}
+	return nil
+}
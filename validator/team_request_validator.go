@@ -1,11 +1,18 @@
 package validator
 
-import "github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SerbanEduard/ProiectColectivBackEnd/model/dto"
+)
 
 func ValidateTeamRequestCreateDTO(request *dto.TeamRequestCreateDTO) error {
 	validations := []func() error{
 		func() error { return validateRequired(request.UserID, "userId is required") },
 		func() error { return validateRequired(request.TeamID, "teamId is required") },
+		func() error { return validateTeamRequestMessage(request.Message) },
 	}
 
 	for _, validate := range validations {
@@ -15,3 +22,45 @@ func ValidateTeamRequestCreateDTO(request *dto.TeamRequestCreateDTO) error {
 	}
 	return nil
 }
+
+// MaxTeamRequestMessageLength bounds the note a user sends along with a join request
+const MaxTeamRequestMessageLength = 500
+
+func validateTeamRequestMessage(message string) error {
+	if len([]rune(message)) > MaxTeamRequestMessageLength {
+		return fmt.Errorf("message cannot be longer than %d characters", MaxTeamRequestMessageLength)
+	}
+	return nil
+}
+
+// MaxInviteLinkHours and MaxInviteLinkUses bound invite links, so a leaked link stops working eventually
+const (
+	MaxInviteLinkHours = 30 * 24
+	MaxInviteLinkUses  = 1000
+)
+
+func ValidateTeamInviteRequest(request *dto.TeamInviteRequest) error {
+	given := 0
+	for _, value := range []string{request.UserID, request.Username, request.Email} {
+		if strings.TrimSpace(value) != "" {
+			given++
+		}
+	}
+	if given != 1 {
+		return errors.New("exactly one of userId, username or email is required")
+	}
+	if request.Email != "" {
+		return validateEmail(request.Email)
+	}
+	return nil
+}
+
+func ValidateTeamInviteLinkRequest(request *dto.TeamInviteLinkRequest) error {
+	if request.ExpiresInHours < 0 || request.ExpiresInHours > MaxInviteLinkHours {
+		return fmt.Errorf("expiresInHours must be between 1 and %d", MaxInviteLinkHours)
+	}
+	if request.MaxUses < 0 || request.MaxUses > MaxInviteLinkUses {
+		return fmt.Errorf("maxUses must be between 0 and %d", MaxInviteLinkUses)
+	}
+	return nil
+}
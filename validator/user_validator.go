@@ -27,6 +27,22 @@ func ValidateSignUpRequest(request *dto.SignUpUserRequest) error {
 	return nil
 }
 
+func ValidateResetPasswordRequest(request *dto.ResetPasswordRequest) error {
+	validations := []func() error{
+		func() error { return validateRequired(request.Token, "token is required") },
+		func() error {
+			return validateMinLength(request.NewPassword, 6, "password must be at least 6 characters")
+		},
+	}
+
+	for _, validate := range validations {
+		if err := validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func validateRequired(value, message string) error {
 	if strings.TrimSpace(value) == "" {
 		return errors.New(message)